@@ -0,0 +1,137 @@
+// Package main implements the feature flag manifest tool.
+// Usage: go run ./cmd/flags <check|sync> [options]
+// Example: go run ./cmd/flags sync -file feature_flags.yaml -env production -dry-run
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/golid-ai/golid/backend/internal/config"
+	"github.com/golid-ai/golid/backend/internal/db"
+	"github.com/golid-ai/golid/backend/internal/logger"
+	"github.com/golid-ai/golid/backend/internal/service/feature"
+	"github.com/golid-ai/golid/backend/internal/wire"
+)
+
+const defaultManifest = "feature_flags.yaml"
+
+const usage = `Usage: go run ./cmd/flags <command> [options]
+
+Commands:
+  check   Parse and validate the manifest (no database needed)
+  sync    Reconcile feature_flags with the manifest
+
+Options:
+`
+
+type options struct {
+	command string
+	file    string
+	sync    feature.SyncOptions
+}
+
+func main() {
+	opts, err := parseArgs(os.Args[1:], os.Getenv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(2)
+	}
+
+	m, err := feature.LoadManifest(opts.file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		os.Exit(1)
+	}
+	if opts.command == "check" {
+		fmt.Printf("%s: %d flags OK\n", opts.file, len(m.Flags))
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load config: %s\n", err)
+		os.Exit(1)
+	}
+	logger.Init(cfg.Environment)
+	if opts.sync.Environment == "" {
+		opts.sync.Environment = cfg.Environment
+	}
+
+	ctx := context.Background()
+	if err := db.Init(ctx, db.DefaultConfig(cfg.DatabaseURL)); err != nil {
+		logger.Error("failed to initialize database", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	// Build the full service graph so writes are broadcast to running API
+	// instances through the same invalidator they listen on.
+	svcs := wire.BuildServices(ctx, cfg, db.Pool())
+	result, err := svcs.Feature.Sync(ctx, m, opts.sync)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+		db.Close()
+		os.Exit(1)
+	}
+	printResult(os.Stdout, opts.sync.Environment, result)
+}
+
+// parseArgs reads the command and its options. -file defaults to
+// FEATURE_MANIFEST, then feature_flags.yaml.
+func parseArgs(args []string, getenv func(string) string) (options, error) {
+	var opts options
+	fs := flag.NewFlagSet("flags", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	fs.StringVar(&opts.file, "file", "", "manifest path (default $FEATURE_MANIFEST or "+defaultManifest+")")
+	fs.StringVar(&opts.sync.Environment, "env", "", "environment whose overrides apply (default $ENVIRONMENT)")
+	fs.BoolVar(&opts.sync.DryRun, "dry-run", false, "show the changes without writing them")
+	fs.BoolVar(&opts.sync.Force, "force", false, "overwrite flags changed at runtime or created outside the manifest")
+	fs.BoolVar(&opts.sync.Prune, "prune", false, "delete flags removed from the manifest")
+	fs.StringVar(&opts.sync.Reason, "reason", "", "reason recorded in flag history")
+
+	if len(args) == 0 || (args[0] != "check" && args[0] != "sync") {
+		var b strings.Builder
+		fs.SetOutput(&b)
+		fs.PrintDefaults()
+		return options{}, fmt.Errorf("a command is required\n\n%s%s", usage, b.String())
+	}
+	opts.command = args[0]
+	if err := fs.Parse(args[1:]); err != nil {
+		return options{}, err
+	}
+	if fs.NArg() > 0 {
+		return options{}, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if opts.file == "" {
+		opts.file = getenv("FEATURE_MANIFEST")
+	}
+	if opts.file == "" {
+		opts.file = defaultManifest
+	}
+	return opts, nil
+}
+
+// printResult writes one line per change, then a summary.
+func printResult(w io.Writer, env string, r *feature.SyncResult) {
+	for _, c := range r.Changes {
+		line := fmt.Sprintf("%-7s %s", c.Action, c.Key)
+		if len(c.Fields) > 0 {
+			line += " (" + strings.Join(c.Fields, ", ") + ")"
+		}
+		if c.Reason != "" {
+			line += ": " + c.Reason
+		}
+		fmt.Fprintln(w, line)
+	}
+	summary := fmt.Sprintf("%s: %d changes, %d unchanged", env, len(r.Changes), r.Unchanged)
+	if r.DryRun {
+		summary += " (dry run, nothing written)"
+	}
+	fmt.Fprintln(w, summary)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golid-ai/golid/backend/internal/service/feature"
+)
+
+func TestParseArgs(t *testing.T) {
+	t.Parallel()
+
+	noEnv := func(string) string { return "" }
+	opts, err := parseArgs([]string{"sync", "-env", "staging", "-dry-run", "-prune"}, noEnv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.command != "sync" || opts.file != defaultManifest || opts.sync.Environment != "staging" ||
+		!opts.sync.DryRun || !opts.sync.Prune || opts.sync.Force {
+		t.Errorf("opts = %+v", opts)
+	}
+
+	withEnv := func(key string) string {
+		if key == "FEATURE_MANIFEST" {
+			return "config/flags.yaml"
+		}
+		return ""
+	}
+	if opts, _ := parseArgs([]string{"check"}, withEnv); opts.file != "config/flags.yaml" {
+		t.Errorf("file = %q, want FEATURE_MANIFEST", opts.file)
+	}
+	if opts, _ := parseArgs([]string{"check", "-file", "other.yaml"}, withEnv); opts.file != "other.yaml" {
+		t.Errorf("file = %q, want -file to win", opts.file)
+	}
+
+	for _, args := range [][]string{nil, {"apply"}, {"sync", "-bogus"}, {"sync", "extra"}} {
+		if _, err := parseArgs(args, noEnv); err == nil {
+			t.Errorf("parseArgs(%q) succeeded", args)
+		}
+	}
+}
+
+func TestPrintResult(t *testing.T) {
+	t.Parallel()
+
+	var b strings.Builder
+	printResult(&b, "production", &feature.SyncResult{
+		Changes: []feature.SyncChange{
+			{Key: "new_checkout", Action: feature.SyncCreate},
+			{Key: "dark_mode", Action: feature.SyncUpdate, Fields: []string{"enabled", "rules"}},
+			{Key: "promo", Action: feature.SyncSkip, Fields: []string{"value"}, Reason: "Changed at runtime"},
+		},
+		Unchanged: 4,
+		DryRun:    true,
+	})
+	want := `create  new_checkout
+update  dark_mode (enabled, rules)
+skip    promo (value): Changed at runtime
+production: 3 changes, 4 unchanged (dry run, nothing written)
+`
+	if b.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", b.String(), want)
+	}
+}
@@ -1,10 +1,15 @@
 // Package main implements the module scaffolding tool.
-// Usage: go run ./cmd/scaffold <module_name>
+// Usage: go run ./cmd/scaffold [-ungated] <module_name>
 // Example: go run ./cmd/scaffold notes
 //          go run ./cmd/scaffold blog_posts
+//
+// Generated modules are gated by a feature flag named after the module
+// (added to feature_flags.yaml, on in development only) unless -ungated
+// is given.
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -22,17 +27,20 @@ type Module struct {
 	Camel       string // "note", "blogPost"
 	MigrationNo string // "000004"
 	ModulePath  string // "github.com/.../backend"
+	Gated       bool   // routes behind middleware.RequireFeature(Plural)
 }
 
 func main() {
-	if len(os.Args) < 2 {
-		fmt.Fprintf(os.Stderr, "Usage: go run ./cmd/scaffold <module_name>\n")
+	ungated := flag.Bool("ungated", false, "don't gate the module's routes behind a feature flag")
+	flag.Parse()
+	if flag.NArg() < 1 {
+		fmt.Fprintf(os.Stderr, "Usage: go run ./cmd/scaffold [-ungated] <module_name>\n")
 		fmt.Fprintf(os.Stderr, "  e.g. go run ./cmd/scaffold notes\n")
-		fmt.Fprintf(os.Stderr, "  e.g. go run ./cmd/scaffold blog_posts\n")
+		fmt.Fprintf(os.Stderr, "  e.g. go run ./cmd/scaffold -ungated blog_posts\n")
 		os.Exit(1)
 	}
 
-	raw := strings.ToLower(strings.ReplaceAll(os.Args[1], "-", "_"))
+	raw := strings.ToLower(strings.ReplaceAll(flag.Arg(0), "-", "_"))
 	singular := singularize(raw)
 	plural := raw
 
@@ -44,13 +52,18 @@ func main() {
 		Camel:       toCamel(toPascal(singular)),
 		MigrationNo: nextMigration(),
 		ModulePath:  readModulePath(),
+		Gated:       !*ungated,
 	}
 
 	fmt.Printf("=== Scaffolding module: %s ===\n", mod.Plural)
 	fmt.Printf("  Table:      %s\n", mod.Plural)
 	fmt.Printf("  Singular:   %s\n", mod.Singular)
 	fmt.Printf("  PascalCase: %s\n", mod.Pascal)
-	fmt.Printf("  Migration:  %s\n\n", mod.MigrationNo)
+	fmt.Printf("  Migration:  %s\n", mod.MigrationNo)
+	if mod.Gated {
+		fmt.Printf("  Flag:       %s\n", mod.Plural)
+	}
+	fmt.Println()
 
 	root := repoRoot()
 	writeTemplate(root+"backend/migrations/"+mod.MigrationNo+"_"+mod.Plural+".up.sql", migrationUp, mod)
@@ -64,6 +77,10 @@ func main() {
 	writeTemplate(root+"backend/internal/handler/"+mod.Singular+".go", handlerTemplate, mod)
 	writeTemplate(root+"backend/internal/handler/"+mod.Singular+"_test.go", handlerTestTemplate, mod)
 	appendInterface(root+"backend/internal/handler/interfaces.go", mod)
+	if mod.Gated {
+		appendFlag(root+"backend/feature_flags.yaml", mod)
+	}
+	appendMessages(root+"backend/internal/i18n/locales", mod)
 
 	frontendDir := root + "frontend/src/routes/(private)/" + toKebab(mod.Plural)
 	if err := os.MkdirAll(frontendDir, 0o755); err != nil {
@@ -219,6 +236,84 @@ func appendInterface(path string, mod Module) {
 	fmt.Printf("  Updated: %s (added %sServicer interface)\n", path, mod.Camel)
 }
 
+// appendFlag declares the module's feature flag in the manifest, off
+// everywhere but development so the module ships dark.
+func appendFlag(path string, mod Module) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	content := string(data)
+	if strings.Contains(content, "\n  "+mod.Plural+":\n") {
+		fmt.Printf("  Skipped: %s (flag %s already declared)\n", path, mod.Plural)
+		return
+	}
+
+	t := template.Must(template.New("").Delims("[[", "]]").Parse(flagTemplate))
+	var buf strings.Builder
+	if err := t.Execute(&buf, mod); err != nil {
+		fmt.Fprintf(os.Stderr, "Error generating flag: %v\n", err)
+		os.Exit(1)
+	}
+	content = strings.TrimRight(content, "\n") + "\n" + buf.String()
+
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", path, err)
+		os.Exit(1)
+	}
+	fmt.Printf("  Updated: %s (added %s flag)\n", path, mod.Plural)
+}
+
+// appendMessages adds the module's error message IDs to every catalog. The
+// text is English everywhere; translators replace it in the other locales.
+func appendMessages(dir string, mod Module) {
+	messages := [][2]string{
+		{"error.not_found." + strings.ToLower(mod.Pascal), mod.Pascal + " not found"},
+		{"error." + mod.Singular + ".title_required", "Title is required"},
+		{"error." + mod.Singular + ".title_too_long", "Title must be %[1]d characters or fewer"},
+		{"error." + mod.Singular + ".content_too_long", "Content must be %[1]d characters or fewer"},
+	}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil || len(paths) == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no message catalogs in %s\n", dir)
+		os.Exit(1)
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		content := string(data)
+		var lines []string
+		for _, m := range messages {
+			if !strings.Contains(content, "\n  \""+m[0]+"\":") {
+				lines = append(lines, fmt.Sprintf("  %q: %q,", m[0], m[1]))
+			}
+		}
+		if len(lines) == 0 {
+			fmt.Printf("  Skipped: %s (messages already present)\n", path)
+			continue
+		}
+		// Insert after the last error message so the error section stays
+		// together; every catalog has entries after it.
+		last := strings.LastIndex(content, "\n  \"error.")
+		if last == -1 {
+			fmt.Fprintf(os.Stderr, "Error: could not find error messages in %s\n", path)
+			os.Exit(1)
+		}
+		end := last + 1 + strings.Index(content[last+1:], "\n")
+		content = content[:end] + "\n" + strings.Join(lines, "\n") + content[end:]
+
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", path, err)
+			os.Exit(1)
+		}
+		fmt.Printf("  Updated: %s (added %d messages)\n", path, len(lines))
+	}
+}
+
 func toKebab(s string) string {
 	return strings.ReplaceAll(s, "_", "-")
 }
@@ -229,20 +324,17 @@ func printWiring(m Module) {
 
    %[1]sService := %[1]s.New%[2]sService(pool, cfg.PaginationDefault, cfg.PaginationMax)
    // Add field to Services struct: %[2]s *%[1]s.%[2]sService
+   // The service implements export.Exporter, so wiring it in also adds
+   // %[3]s to personal data exports.
 
 2. Add to backend/internal/wire/handlers.go (BuildHandlers):
 
    %[1]sHandler := handler.New%[2]sHandler(svcs.%[2]s, cfg.PaginationDefault, cfg.PaginationMax)
    // Add field to Handlers struct: %[2]s *handler.%[2]sHandler
 
-3. Add to backend/internal/wire/routes.go (registerProtectedRoutes or appropriate group):
-
-   protected.POST("/%[3]s", h.%[2]s.Create)
-   protected.GET("/%[3]s", h.%[2]s.List)
-   protected.GET("/%[3]s/:id", h.%[2]s.GetByID)
-   protected.PUT("/%[3]s/:id", h.%[2]s.Update)
-   protected.DELETE("/%[3]s/:id", h.%[2]s.Delete)
+3. Add to backend/internal/wire/routes.go:
 
+%[4]s
 4. Add to frontend/src/lib/api.ts:
 
    export interface %[2]s {
@@ -283,18 +375,59 @@ func printWiring(m Module) {
 5. Run migration:
    migrate -path backend/migrations -database "$DATABASE_URL" up
 
-6. Verify:
+6. Translate the messages added to backend/internal/i18n/locales/*.json
+   (they are English in every catalog).
+
+7. Verify:
    cd backend && go build ./...
    cd frontend && npm run build
 
 === Done! See docs/example-module.md for the full pattern reference. ===
-`, m.Camel, m.Pascal, m.Plural)
+`, m.Camel, m.Pascal, m.Plural, routeWiring(m))
+}
+
+// routeWiring is step 3 of printWiring: plain protected routes, or a
+// group behind the module's feature flag.
+func routeWiring(m Module) string {
+	if !m.Gated {
+		return fmt.Sprintf(`   // In registerProtectedRoutes (or the appropriate group):
+   protected.POST("/%[2]s", h.%[1]s.Create)
+   protected.GET("/%[2]s", h.%[1]s.List)
+   protected.GET("/%[2]s/:id", h.%[1]s.GetByID)
+   protected.PUT("/%[2]s/:id", h.%[1]s.Update)
+   protected.DELETE("/%[2]s/:id", h.%[1]s.Delete)
+`, m.Pascal, m.Plural)
+	}
+	return fmt.Sprintf(`   // In RegisterRoutes, after registerProtectedRoutes:
+   register%[3]sRoutes(protected, h, svcs)
+
+   // The routes 404 unless the "%[2]s" flag is on for the caller. It is
+   // declared in backend/feature_flags.yaml, on in development only; turn
+   // it on elsewhere with the manifest or PUT /api/v1/admin/features/%[2]s.
+   func register%[3]sRoutes(protected *echo.Group, h *Handlers, svcs *Services) {
+   	g := protected.Group("/%[2]s", middleware.RequireFeature(svcs.Feature, "%[2]s"))
+   	g.POST("", h.%[1]s.Create)
+   	g.GET("", h.%[1]s.List)
+   	g.GET("/:id", h.%[1]s.GetByID)
+   	g.PUT("/:id", h.%[1]s.Update)
+   	g.DELETE("/:id", h.%[1]s.Delete)
+   }
+`, m.Pascal, m.Plural, m.PascalPlur)
 }
 
 // ============================================================================
 // TEMPLATES
 // ============================================================================
 
+var flagTemplate = `
+  [[.Plural]]:
+    description: [[.PascalPlur]] module
+    enabled: false
+    environments:
+      development:
+        enabled: true
+`
+
 var migrationUp = `CREATE TABLE [[.Plural]] (
     id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
@@ -473,7 +606,7 @@ func (s *[[.Pascal]]Service) GetByID(ctx context.Context, [[.Camel]]ID, userID s
 	).Scan(&item.ID, &item.Title, &item.Content, &createdAt, &updatedAt)
 
 	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, apperror.NotFound("[[.Pascal]] not found")
+		return nil, apperror.NotFound("[[.Pascal]]")
 	}
 	if err != nil {
 		return nil, apperror.Internal(fmt.Errorf("get [[.Singular]]: %w", err))
@@ -496,7 +629,7 @@ func (s *[[.Pascal]]Service) Update(ctx context.Context, input *Update[[.Pascal]
 	).Scan(&item.ID, &item.Title, &item.Content, &createdAt, &updatedAt)
 
 	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, apperror.NotFound("[[.Pascal]] not found")
+		return nil, apperror.NotFound("[[.Pascal]]")
 	}
 	if err != nil {
 		return nil, apperror.Internal(fmt.Errorf("update [[.Singular]]: %w", err))
@@ -516,10 +649,42 @@ func (s *[[.Pascal]]Service) Delete(ctx context.Context, [[.Camel]]ID, userID st
 		return apperror.Internal(fmt.Errorf("delete [[.Singular]]: %w", err))
 	}
 	if result.RowsAffected() == 0 {
-		return apperror.NotFound("[[.Pascal]] not found")
+		return apperror.NotFound("[[.Pascal]]")
 	}
 	return nil
 }
+
+// ExportName implements export.Exporter. Services wired into
+// wire.Services are included in personal data exports automatically.
+func (s *[[.Pascal]]Service) ExportName() string { return "[[.Plural]]" }
+
+// ExportUserData implements export.Exporter.
+func (s *[[.Pascal]]Service) ExportUserData(ctx context.Context, userID string) (any, error) {
+	rows, err := s.pool.Query(ctx,
+		` + "`" + `SELECT id, title, content, created_at, updated_at
+		 FROM [[.Plural]] WHERE user_id = $1 ORDER BY created_at` + "`" + `, userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("export [[.Plural]]: %w", err)
+	}
+	defer rows.Close()
+
+	items := [][[.Pascal]]Detail{}
+	for rows.Next() {
+		var item [[.Pascal]]Detail
+		var createdAt, updatedAt time.Time
+		if err := rows.Scan(&item.ID, &item.Title, &item.Content, &createdAt, &updatedAt); err != nil {
+			return nil, fmt.Errorf("scan [[.Singular]]: %w", err)
+		}
+		item.CreatedAt = createdAt.Format(time.RFC3339)
+		item.UpdatedAt = updatedAt.Format(time.RFC3339)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate [[.Plural]]: %w", err)
+	}
+	return items, nil
+}
 `
 
 var handlerTemplate = `package handler
@@ -562,7 +727,7 @@ func (h *[[.Pascal]]Handler) Create(c echo.Context) error {
 
 	var req Create[[.Pascal]]Request
 	if err := c.Bind(&req); err != nil {
-		return apperror.BadRequest("Invalid request body")
+		return apperror.InvalidBody()
 	}
 
 	if err := validate[[.Pascal]](req.Title, req.Content); err != nil {
@@ -620,7 +785,7 @@ func (h *[[.Pascal]]Handler) Update(c echo.Context) error {
 
 	var req Update[[.Pascal]]Request
 	if err := c.Bind(&req); err != nil {
-		return apperror.BadRequest("Invalid request body")
+		return apperror.InvalidBody()
 	}
 
 	if err := validate[[.Pascal]](req.Title, req.Content); err != nil {
@@ -654,21 +819,21 @@ func (h *[[.Pascal]]Handler) Delete(c echo.Context) error {
 }
 
 func validate[[.Pascal]](title, content string) error {
-	details := make(map[string]string)
+	verr := apperror.Validation()
 
 	title = strings.TrimSpace(title)
 	if title == "" {
-		details["title"] = "Title is required"
+		verr.WithDetail("title", "error.[[.Singular]].title_required")
 	} else if len(title) > 200 {
-		details["title"] = "Title must be 200 characters or fewer"
+		verr.WithDetail("title", "error.[[.Singular]].title_too_long", 200)
 	}
 
 	if len(content) > 50000 {
-		details["content"] = "Content must be 50,000 characters or fewer"
+		verr.WithDetail("content", "error.[[.Singular]].content_too_long", 50000)
 	}
 
-	if len(details) > 0 {
-		return apperror.Validation("Validation failed", details)
+	if len(verr.Details) > 0 {
+		return verr
 	}
 	return nil
 }
@@ -814,7 +979,7 @@ func TestList[[.PascalPlur]]_Success(t *testing.T) {
 func TestGetByID[[.Pascal]]_NotFound(t *testing.T) {
 	mock := &mock[[.Pascal]]Service{
 		getByIDFn: func(ctx context.Context, [[.Camel]]ID, userID string) (*[[.Singular]].[[.Pascal]]Detail, error) {
-			return nil, apperror.NotFound("[[.Pascal]] not found")
+			return nil, apperror.NotFound("[[.Pascal]]")
 		},
 	}
 	h := &[[.Pascal]]Handler{[[.Camel]]Service: mock, paginationDefault: 20, paginationMax: 100}
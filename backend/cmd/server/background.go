@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/golid-ai/golid/backend/internal/logger"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
 	"github.com/golid-ai/golid/backend/internal/wire"
 )
 
@@ -20,6 +21,115 @@ func startTokenCleanup(svcs *wire.Services) chan struct{} {
 	})
 }
 
+// staleUploadAge is how long a pending upload may sit unfinished before
+// its row is removed. Comfortably longer than any signed upload URL.
+const staleUploadAge = 24 * time.Hour
+
+// startUploadCleanup runs an hourly sweep that deletes pending uploads
+// whose signed URL was never used.
+func startUploadCleanup(svcs *wire.Services) chan struct{} {
+	return runEvery(1*time.Hour, func(ctx context.Context) {
+		n, err := svcs.Files.CleanupPendingUploads(ctx, staleUploadAge)
+		if err != nil {
+			logger.Error("failed to clean stale uploads", slog.String("error", err.Error()))
+			return
+		}
+		if n > 0 {
+			logger.Info("cleaned stale uploads", slog.Int("count", n))
+		}
+	})
+}
+
+// startExportCleanup runs an hourly sweep that deletes expired data
+// export archives and exports whose build never finished.
+func startExportCleanup(svcs *wire.Services) chan struct{} {
+	return runEvery(1*time.Hour, func(ctx context.Context) {
+		n, err := svcs.Exports.CleanupExpired(ctx)
+		if err != nil {
+			logger.Error("failed to clean expired exports", slog.String("error", err.Error()))
+			return
+		}
+		if n > 0 {
+			logger.Info("cleaned expired exports", slog.Int("count", n))
+		}
+	})
+}
+
+// startOutboxRelay delivers outbox messages every OUTBOX_POLL_INTERVAL.
+// Every instance runs it; messages are claimed with SKIP LOCKED so each
+// is delivered by one relay at a time.
+func startOutboxRelay(relay *outbox.Relay, interval time.Duration) chan struct{} {
+	return runEvery(interval, func(ctx context.Context) {
+		if _, err := relay.Dispatch(ctx); err != nil {
+			logger.Error("failed to dispatch outbox messages", slog.String("error", err.Error()))
+		}
+	})
+}
+
+// startOutboxCleanup runs an hourly sweep that deletes outbox messages
+// delivered or failed more than retention ago.
+func startOutboxCleanup(relay *outbox.Relay, retention time.Duration) chan struct{} {
+	return runEvery(1*time.Hour, func(ctx context.Context) {
+		n, err := relay.Cleanup(ctx, retention)
+		if err != nil {
+			logger.Error("failed to clean outbox", slog.String("error", err.Error()))
+			return
+		}
+		if n > 0 {
+			logger.Info("cleaned outbox", slog.Int("count", n))
+		}
+	})
+}
+
+// startFeatureWatch keeps the feature flag cache in sync with other
+// instances. Returns a cancel func that main calls during shutdown.
+func startFeatureWatch(svcs *wire.Services) context.CancelFunc {
+	ctx, cancel := context.WithCancel(context.Background())
+	go svcs.Feature.Watch(ctx)
+	return cancel
+}
+
+// startSSEFanout delivers SSE events published by other instances to
+// this instance's clients. Returns a cancel func that main calls during
+// shutdown.
+func startSSEFanout(svcs *wire.Services) context.CancelFunc {
+	ctx, cancel := context.WithCancel(context.Background())
+	go svcs.SSEHub.Run(ctx)
+	return cancel
+}
+
+// featureScheduleInterval is how often due feature flag schedules are
+// applied, and so how late a scheduled change can land.
+const featureScheduleInterval = 15 * time.Second
+
+// startFeatureScheduler applies due feature flag schedules. Every instance
+// runs it; schedules are claimed with SKIP LOCKED so each applies once.
+func startFeatureScheduler(svcs *wire.Services) chan struct{} {
+	return runEvery(featureScheduleInterval, func(ctx context.Context) {
+		n, err := svcs.Feature.ApplyDue(ctx)
+		if err != nil {
+			logger.Error("failed to apply feature flag schedules", slog.String("error", err.Error()))
+		}
+		if n > 0 {
+			logger.Info("applied feature flag schedules", slog.Int("count", n))
+		}
+	})
+}
+
+// featureUsageInterval is how often in-memory flag evaluation counts are
+// written to feature_flag_usage.
+const featureUsageInterval = time.Minute
+
+// startFeatureUsageFlush periodically persists flag evaluation counts for
+// the stale flag report. main flushes once more on shutdown.
+func startFeatureUsageFlush(svcs *wire.Services) chan struct{} {
+	return runEvery(featureUsageInterval, func(ctx context.Context) {
+		if _, err := svcs.Feature.FlushUsage(ctx); err != nil {
+			logger.Error("failed to flush feature flag usage", slog.String("error", err.Error()))
+		}
+	})
+}
+
 // runEvery launches fn on the given interval until the returned
 // channel is closed. fn receives a fresh background context on each
 // tick so individual sweeps cannot be cancelled by the bootstrap ctx
@@ -12,6 +12,8 @@ import (
 	"github.com/golid-ai/golid/backend/internal/config"
 	"github.com/golid-ai/golid/backend/internal/logger"
 	"github.com/golid-ai/golid/backend/internal/middleware"
+	"github.com/golid-ai/golid/backend/internal/service/feature"
+	"github.com/golid-ai/golid/backend/internal/wire"
 )
 
 // initRateLimiterRedis wires a Redis client into the rate-limiter
@@ -72,3 +74,38 @@ func newEcho(cfg *config.Config) *echo.Echo {
 
 	return e
 }
+
+// syncFeatureManifest reconciles feature flags with FEATURE_MANIFEST when
+// it is set. It never forces or prunes, so admin changes made at runtime
+// survive restarts; failures are logged and the server starts with the
+// flags already in the database.
+func syncFeatureManifest(ctx context.Context, cfg *config.Config, svcs *wire.Services) {
+	if cfg.FeatureManifest == "" {
+		return
+	}
+	m, err := feature.LoadManifest(cfg.FeatureManifest)
+	if err != nil {
+		logger.Error("feature manifest sync failed", slog.String("error", err.Error()))
+		return
+	}
+	result, err := svcs.Feature.Sync(ctx, m, feature.SyncOptions{Environment: cfg.Environment})
+	if err != nil {
+		logger.Error("feature manifest sync failed", slog.String("error", err.Error()))
+		return
+	}
+	for _, c := range result.Changes {
+		attrs := []any{slog.String("key", c.Key), slog.String("action", c.Action)}
+		if c.Reason != "" {
+			attrs = append(attrs, slog.String("reason", c.Reason))
+		}
+		if c.Action == feature.SyncSkip {
+			logger.Warn("feature manifest sync skipped a flag", attrs...)
+		} else {
+			logger.Info("feature manifest sync changed a flag", attrs...)
+		}
+	}
+	logger.Info("feature manifest synced",
+		slog.String("manifest", cfg.FeatureManifest),
+		slog.Int("changes", len(result.Changes)),
+		slog.Int("unchanged", result.Unchanged))
+}
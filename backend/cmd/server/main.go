@@ -58,12 +58,22 @@ func main() {
 	}
 
 	svcs := wire.BuildServices(ctx, cfg, db.Pool())
+	syncFeatureManifest(ctx, cfg, svcs)
 	handlers := wire.BuildHandlers(svcs, cfg, jobQueue)
+	relay := wire.BuildOutboxRelay(svcs, cfg, db.Pool(), jobQueue)
 
 	tokenCleanupDone := startTokenCleanup(svcs)
+	uploadCleanupDone := startUploadCleanup(svcs)
+	exportCleanupDone := startExportCleanup(svcs)
+	stopFeatureWatch := startFeatureWatch(svcs)
+	stopSSEFanout := startSSEFanout(svcs)
+	featureSchedulerDone := startFeatureScheduler(svcs)
+	featureUsageDone := startFeatureUsageFlush(svcs)
+	outboxRelayDone := startOutboxRelay(relay, cfg.OutboxPollInterval)
+	outboxCleanupDone := startOutboxCleanup(relay, cfg.OutboxRetention)
 
 	e := newEcho(cfg)
-	wire.RegisterRoutes(e, handlers, svcs, cfg, middleware.JWTAuth(cfg.JWTSecret))
+	wire.RegisterRoutes(e, handlers, svcs, cfg, middleware.JWTAuth(cfg.JWTSecret, middleware.WithAccountStatus(svcs.Users)))
 
 	go func() {
 		logger.Info("server listening", slog.String("port", cfg.Port))
@@ -86,8 +96,20 @@ func main() {
 		logger.Error("shutdown error", slog.String("error", err.Error()))
 	}
 
+	// Stop the relay before the SSE hub it pushes to.
+	close(outboxRelayDone)
+	close(outboxCleanupDone)
+	stopSSEFanout()
 	svcs.SSEHub.Shutdown()
 	close(tokenCleanupDone)
+	close(uploadCleanupDone)
+	close(exportCleanupDone)
+	stopFeatureWatch()
+	close(featureSchedulerDone)
+	close(featureUsageDone)
+	if _, err := svcs.Feature.FlushUsage(shutdownCtx); err != nil {
+		logger.Error("failed to flush feature flag usage", slog.String("error", err.Error()))
+	}
 
 	logger.Info("server stopped")
 }
@@ -1,15 +1,18 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 	"os"
 
 	"github.com/hibiken/asynq"
 
 	"github.com/golid-ai/golid/backend/internal/config"
+	"github.com/golid-ai/golid/backend/internal/db"
 	"github.com/golid-ai/golid/backend/internal/logger"
 	"github.com/golid-ai/golid/backend/internal/queue"
-	"github.com/golid-ai/golid/backend/internal/service/email"
+	"github.com/golid-ai/golid/backend/internal/storage"
+	"github.com/golid-ai/golid/backend/internal/wire"
 )
 
 func main() {
@@ -26,21 +29,26 @@ func main() {
 		os.Exit(1)
 	}
 
-	emailService := email.NewEmailService(email.EmailConfig{
-		APIKey:           cfg.MailgunAPIKey,
-		Domain:           cfg.MailgunDomain,
-		BaseURL:          cfg.MailgunBaseURL,
-		FrontendURL:      cfg.FrontendURL,
-		DevEmailOverride: cfg.DevEmailOverride,
-		AppName:          cfg.AppName,
-		Timeout:          cfg.EmailTimeout,
-		PasswordResetTTL: cfg.PasswordResetTTL,
-	})
+	// Avatar processing and data exports read and write the database and
+	// blob storage. The worker builds the same services as the API so every
+	// registered exporter is included in data export archives.
+	ctx := context.Background()
+	dbCfg := db.DefaultConfig(cfg.DatabaseURL)
+	dbCfg.MaxConns = cfg.DBMaxConns
+	dbCfg.MinConns = cfg.DBMinConns
+	if err := db.Init(ctx, dbCfg); err != nil {
+		logger.Error("failed to initialize database", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	if _, err := storage.FromConfig(cfg); err != nil {
+		logger.Error("failed to initialize storage", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	svcs := wire.BuildServices(ctx, cfg, db.Pool())
 
-	emailHandler := queue.NewEmailHandler(emailService)
-	mux := asynq.NewServeMux()
-	mux.HandleFunc(queue.TypeSendVerificationEmail, emailHandler.HandleVerification)
-	mux.HandleFunc(queue.TypeSendPasswordReset, emailHandler.HandlePasswordReset)
+	mux := queue.NewServeMux(svcs.Email, svcs.Avatars, svcs.Exports)
 
 	opt, err := asynq.ParseRedisURI(cfg.RedisURL)
 	if err != nil {
@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
+
+	"github.com/golid-ai/golid/backend/internal/i18n"
 )
 
 // Code represents an application error code.
@@ -20,17 +23,36 @@ const (
 	CodeBadRequest     Code = "BAD_REQUEST"
 	CodeTimeout        Code = "REQUEST_TIMEOUT"
 	CodeServiceUnavail Code = "SERVICE_UNAVAILABLE"
+
+	CodeChallengeRequired Code = "CHALLENGE_REQUIRED"
+	CodeAccountSuspended  Code = "ACCOUNT_SUSPENDED"
+	CodeFeatureDisabled   Code = "FEATURE_DISABLED"
 )
 
 // AppError is a structured application error.
+//
+// MessageID, when set, names the message in the i18n catalogs; the error
+// handler replaces Message with the translation for the request's locale
+// and Args. Message stays the English text for logs and for IDs no catalog
+// has. Details added with WithDetail are translated the same way, from
+// DetailIDs.
 type AppError struct {
 	Code       Code              `json:"code"`
 	Message    string            `json:"message"`
+	MessageID  string            `json:"message_id,omitempty"`
+	Args       []any             `json:"-"`
 	Details    map[string]string `json:"details,omitempty"`
+	DetailIDs  map[string]Detail `json:"-"`
 	HTTPStatus int               `json:"-"`
 	Err        error             `json:"-"`
 }
 
+// Detail names the catalog message behind one Details entry.
+type Detail struct {
+	ID   string
+	Args []any
+}
+
 func (e *AppError) Error() string {
 	if e.Err != nil {
 		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
@@ -42,6 +64,29 @@ func (e *AppError) Unwrap() error {
 	return e.Err
 }
 
+// WithMessageID sets the catalog message ID and its format arguments.
+// Returns e for chaining onto a constructor.
+func (e *AppError) WithMessageID(id string, args ...any) *AppError {
+	e.MessageID = id
+	e.Args = args
+	return e
+}
+
+// WithDetail adds a message for one field: Details[field] is the
+// i18n.DefaultLocale text of catalog message id formatted with args, and
+// the error handler translates it for the request. Returns e for chaining.
+func (e *AppError) WithDetail(field, id string, args ...any) *AppError {
+	if e.Details == nil {
+		e.Details = make(map[string]string)
+	}
+	if e.DetailIDs == nil {
+		e.DetailIDs = make(map[string]Detail)
+	}
+	e.Details[field] = i18n.T(i18n.DefaultLocale, id, args...)
+	e.DetailIDs[field] = Detail{ID: id, Args: args}
+	return e
+}
+
 // --- Constructors ---
 
 // Internal creates an internal server error.
@@ -49,50 +94,68 @@ func Internal(err error) *AppError {
 	return &AppError{
 		Code:       CodeInternal,
 		Message:    "An internal error occurred",
+		MessageID:  "error.internal",
 		HTTPStatus: http.StatusInternalServerError,
 		Err:        err,
 	}
 }
 
-// NotFound creates a not found error.
+// NotFound creates a not found error. The message ID is derived from the
+// resource: NotFound("User") is "error.not_found.user".
 func NotFound(resource string) *AppError {
 	return &AppError{
 		Code:       CodeNotFound,
 		Message:    fmt.Sprintf("%s not found", resource),
+		MessageID:  "error.not_found." + strings.ToLower(strings.ReplaceAll(resource, " ", "_")),
 		HTTPStatus: http.StatusNotFound,
 	}
 }
 
-// Validation creates a validation error with field details.
-func Validation(message string, details map[string]string) *AppError {
+// Validation creates a validation error. Field messages are added with
+// WithDetail; check len(Details) when collecting them before returning.
+func Validation() *AppError {
 	return &AppError{
 		Code:       CodeValidation,
-		Message:    message,
-		Details:    details,
+		Message:    "Validation failed",
+		MessageID:  "error.validation",
+		HTTPStatus: http.StatusBadRequest,
+	}
+}
+
+// InvalidBody creates a bad request error for a body that does not bind.
+func InvalidBody() *AppError {
+	return &AppError{
+		Code:       CodeBadRequest,
+		Message:    "Invalid request body",
+		MessageID:  "error.invalid_body",
 		HTTPStatus: http.StatusBadRequest,
 	}
 }
 
 // Unauthorized creates an unauthorized error.
 func Unauthorized(message string) *AppError {
+	id := ""
 	if message == "" {
-		message = "Authentication required"
+		message, id = "Authentication required", "error.unauthorized"
 	}
 	return &AppError{
 		Code:       CodeUnauthorized,
 		Message:    message,
+		MessageID:  id,
 		HTTPStatus: http.StatusUnauthorized,
 	}
 }
 
 // Forbidden creates a forbidden error.
 func Forbidden(message string) *AppError {
+	id := ""
 	if message == "" {
-		message = "Access denied"
+		message, id = "Access denied", "error.forbidden"
 	}
 	return &AppError{
 		Code:       CodeForbidden,
 		Message:    message,
+		MessageID:  id,
 		HTTPStatus: http.StatusForbidden,
 	}
 }
@@ -111,10 +174,50 @@ func RateLimited() *AppError {
 	return &AppError{
 		Code:       CodeRateLimited,
 		Message:    "Too many requests, please try again later",
+		MessageID:  "error.rate_limited",
 		HTTPStatus: http.StatusTooManyRequests,
 	}
 }
 
+// ChallengeRequired creates a 428 telling the client to solve a
+// proof-of-work challenge (GET /api/v1/auth/challenge) and retry.
+func ChallengeRequired(message string) *AppError {
+	id := ""
+	if message == "" {
+		message, id = "Proof-of-work challenge required", "error.challenge_required"
+	}
+	return &AppError{
+		Code:       CodeChallengeRequired,
+		Message:    message,
+		MessageID:  id,
+		HTTPStatus: http.StatusPreconditionRequired,
+	}
+}
+
+// AccountSuspended creates a 403 for a suspended or banned account.
+// Distinct from Unauthorized so clients don't try to refresh their way
+// past it; details carry the status and, for suspensions, the end time.
+func AccountSuspended(message string, details map[string]string) *AppError {
+	return &AppError{
+		Code:       CodeAccountSuspended,
+		Message:    message,
+		Details:    details,
+		HTTPStatus: http.StatusForbidden,
+	}
+}
+
+// FeatureDisabled creates a 404 for a route whose feature flag is off for
+// the caller. The status matches an unknown route; the code lets clients
+// tell the two apart.
+func FeatureDisabled() *AppError {
+	return &AppError{
+		Code:       CodeFeatureDisabled,
+		Message:    "This feature is not available",
+		MessageID:  "error.feature_disabled",
+		HTTPStatus: http.StatusNotFound,
+	}
+}
+
 // RequestTimeout creates a request timeout error.
 func RequestTimeout(message string) *AppError {
 	return &AppError{
@@ -25,11 +25,9 @@ func TestNotFound(t *testing.T) {
 }
 
 func TestValidation(t *testing.T) {
-	details := map[string]string{
-		"email":    "invalid email format",
-		"password": "must be at least 8 characters",
-	}
-	err := apperror.Validation("Validation failed", details)
+	err := apperror.Validation().
+		WithDetail("email", "error.auth.email_invalid").
+		WithDetail("password", "error.auth.password_too_short")
 
 	if err.Code != apperror.CodeValidation {
 		t.Errorf("expected code %s, got %s", apperror.CodeValidation, err.Code)
@@ -38,6 +36,12 @@ func TestValidation(t *testing.T) {
 	if len(err.Details) != 2 {
 		t.Errorf("expected 2 details, got %d", len(err.Details))
 	}
+	if err.Details["email"] != "Invalid email format" {
+		t.Errorf("email detail = %q, want the English catalog text", err.Details["email"])
+	}
+	if err.DetailIDs["password"].ID != "error.auth.password_too_short" {
+		t.Errorf("password detail ID = %q", err.DetailIDs["password"].ID)
+	}
 }
 
 func TestInternal(t *testing.T) {
@@ -133,6 +137,9 @@ func TestHTTPStatus(t *testing.T) {
 		{"Unauthorized", apperror.Unauthorized(""), http.StatusUnauthorized},
 		{"Forbidden", apperror.Forbidden(""), http.StatusForbidden},
 		{"RateLimited", apperror.RateLimited(), http.StatusTooManyRequests},
+		{"ChallengeRequired", apperror.ChallengeRequired(""), http.StatusPreconditionRequired},
+		{"AccountSuspended", apperror.AccountSuspended("Account suspended", nil), http.StatusForbidden},
+		{"FeatureDisabled", apperror.FeatureDisabled(), http.StatusNotFound},
 		{"Unknown", errors.New("unknown"), http.StatusInternalServerError},
 	}
 
@@ -145,3 +152,33 @@ func TestHTTPStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestMessageIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		err  *apperror.AppError
+		want string
+	}{
+		{"Internal", apperror.Internal(errors.New("x")), "error.internal"},
+		{"NotFound", apperror.NotFound("Data Export"), "error.not_found.data_export"},
+		{"Validation", apperror.Validation(), "error.validation"},
+		{"InvalidBody", apperror.InvalidBody(), "error.invalid_body"},
+		{"BadRequest", apperror.BadRequest("Invalid request body"), ""},
+		{"Unauthorized default", apperror.Unauthorized(""), "error.unauthorized"},
+		{"Unauthorized custom", apperror.Unauthorized("Invalid token"), ""},
+		{"Forbidden default", apperror.Forbidden(""), "error.forbidden"},
+		{"RateLimited", apperror.RateLimited(), "error.rate_limited"},
+		{"FeatureDisabled", apperror.FeatureDisabled(), "error.feature_disabled"},
+		{"WithMessageID", apperror.Conflict("Taken").WithMessageID("error.auth.email_taken"), "error.auth.email_taken"},
+	}
+	for _, tt := range tests {
+		if tt.err.MessageID != tt.want {
+			t.Errorf("%s: MessageID = %q, want %q", tt.name, tt.err.MessageID, tt.want)
+		}
+	}
+
+	err := apperror.BadRequest("Too late").WithMessageID("error.too_late", 3)
+	if len(err.Args) != 1 || err.Args[0] != 3 {
+		t.Errorf("Args = %v, want [3]", err.Args)
+	}
+}
@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
 	"strings"
@@ -29,13 +30,50 @@ type Config struct {
 	AuthRateLimitRequests int           // auth endpoint requests per minute (login, register, etc.)
 	CSRFEnforce           bool          // reject state-changing API requests without X-Requested-With header
 
+	// Proof-of-Work (adaptive challenge on auth endpoints)
+	PoWSecret           string        // HMAC key for challenges (default: JWT_SECRET)
+	PoWDifficulty       int           // leading zero bits required (default: 18)
+	PoWChallengeTTL     time.Duration // how long an issued challenge stays solvable
+	PoWFailureThreshold int           // auth failures per IP/account before a challenge is required (0 = disabled)
+	PoWFailureWindow    time.Duration // window over which failures are counted
+
+	// Storage (uploads)
+	StorageBackend       string        // "local" (default) or "s3"
+	StorageLocalDir      string        // root directory for the local backend
+	StorageS3Endpoint    string        // e.g. https://s3.us-east-1.amazonaws.com or http://minio:9000
+	StorageS3Region      string        // default: us-east-1
+	StorageS3Bucket      string
+	StorageS3AccessKey   string
+	StorageS3SecretKey   string
+	StorageS3PathStyle   bool          // path-style addressing (required by MinIO)
+	StorageSigningSecret string        // HMAC key for signed upload/download URLs (default: JWT_SECRET)
+	StorageURLTTL        time.Duration // lifetime of signed URLs
+	StorageMaxUploadSize int64         // bytes
+	StorageAllowedTypes  []string      // sniffed media types accepted for upload ("image/*" wildcards allowed)
+	StorageBaseURL       string        // absolute API origin for signed URLs; empty = relative paths
+
+	// Avatars
+	AvatarMaxUploadSize int64 // bytes accepted by POST /me/avatar
+	AvatarMaxPixels     int   // decoded width × height limit (decompression-bomb guard)
+
+	// Data exports
+	ExportLinkTTL time.Duration // lifetime of an export archive and its signed download link
+
+	// Transactional outbox
+	OutboxPollInterval   time.Duration // how often the relay looks for due messages
+	OutboxMaxAttempts    int           // delivery attempts before a message is marked failed
+	OutboxRetention      time.Duration // how long delivered and failed messages are kept
+	OutboxWebhookSecret  string        // HMAC key for X-Outbox-Signature; empty: unsigned
+	OutboxWebhookTimeout time.Duration
+
 	// CORS
 	AllowedOrigins []string
 
 	// Application
 	AppName string // Used in emails, branding (default: "Golid")
 
-	// Redis (optional — enables job queue + persistent rate limiting)
+	// Redis (optional — enables job queue, persistent rate limiting, and
+	// feature flag invalidation over pub/sub instead of Postgres NOTIFY)
 	RedisURL string
 
 	// Observability (optional)
@@ -54,6 +92,10 @@ type Config struct {
 
 	// Feature Flags
 	FeatureCacheTTL time.Duration
+	FeatureManifest string // flags-as-code manifest synced on startup (empty: no startup sync)
+
+	// Account status (suspension/ban) cache used by JWTAuth
+	AccountStatusCacheTTL time.Duration
 
 	// Email (Mailgun)
 	MailgunAPIKey    string
@@ -77,6 +119,14 @@ type Config struct {
 	EmailTimeout         time.Duration
 	SSETicketTTL         time.Duration
 	SSEKeepaliveInterval time.Duration
+	SSEReplaySize        int           // events per user kept for Last-Event-ID replay
+	SSEReplayTTL         time.Duration // how long a disconnected client can still be caught up
+	SSEPresenceTTL       time.Duration // how long a crashed instance's users stay online
+	SSEPresenceGrace     time.Duration // reconnect window before a user is reported offline
+	SSEClientBuffer      int           // events a connection can fall behind before SSEOverflowPolicy applies
+	SSEMaxConnsPerUser   int
+	SSEOverflowPolicy    string        // drop_newest, drop_oldest, or disconnect, for event types without their own
+	SSEEvictRetry        time.Duration // reconnect delay hinted to clients disconnected for falling behind
 	RetryAttempts        int
 	RetryDelay           time.Duration
 }
@@ -94,6 +144,11 @@ func Load() (*Config, error) {
 		RateLimitWindow:      getDuration("RATE_LIMIT_WINDOW", time.Minute),
 		AuthRateLimitRequests: getInt("AUTH_RATE_LIMIT", 5),
 		CSRFEnforce:           getBool("CSRF_ENFORCE", false),
+		PoWSecret:             os.Getenv("POW_SECRET"),
+		PoWDifficulty:         getInt("POW_DIFFICULTY", 18),
+		PoWChallengeTTL:       getDuration("POW_CHALLENGE_TTL", 2*time.Minute),
+		PoWFailureThreshold:   getInt("POW_FAILURE_THRESHOLD", 5),
+		PoWFailureWindow:      getDuration("POW_FAILURE_WINDOW", 15*time.Minute),
 		RequestTimeout:    getDuration("REQUEST_TIMEOUT", 30*time.Second),
 		// Default CSP allows 'unsafe-inline' for scripts/styles because the SPA inlines
 		// critical CSS and some libraries inject script tags. Override via CSP_POLICY env
@@ -102,6 +157,8 @@ func Load() (*Config, error) {
 		PaginationDefault: getInt("PAGINATION_DEFAULT", 20),
 		PaginationMax:     getInt("PAGINATION_MAX", 100),
 		FeatureCacheTTL:    getDuration("FEATURE_CACHE_TTL", 30*time.Second),
+		FeatureManifest:    os.Getenv("FEATURE_MANIFEST"),
+		AccountStatusCacheTTL: getDuration("ACCOUNT_STATUS_CACHE_TTL", 30*time.Second),
 		AppName:            getEnv("APP_NAME", "Golid"),
 		RedisURL:           os.Getenv("REDIS_URL"),
 		OTELEndpoint:       os.Getenv("OTEL_ENDPOINT"),
@@ -122,8 +179,44 @@ func Load() (*Config, error) {
 		EmailTimeout:         getDuration("EMAIL_TIMEOUT", 30*time.Second),
 		SSETicketTTL:         getDuration("SSE_TICKET_TTL", 30*time.Second),
 		SSEKeepaliveInterval: getDuration("SSE_KEEPALIVE_INTERVAL", 30*time.Second),
+		SSEReplaySize:        getInt("SSE_REPLAY_SIZE", 100),
+		SSEReplayTTL:         getDuration("SSE_REPLAY_TTL", 5*time.Minute),
+		SSEPresenceTTL:       getDuration("SSE_PRESENCE_TTL", 90*time.Second),
+		SSEPresenceGrace:     getDuration("SSE_PRESENCE_GRACE", 10*time.Second),
+		SSEClientBuffer:      getInt("SSE_CLIENT_BUFFER", 16),
+		SSEMaxConnsPerUser:   getInt("SSE_MAX_CONNS_PER_USER", 5),
+		SSEOverflowPolicy:    getEnv("SSE_OVERFLOW_POLICY", "drop_newest"),
+		SSEEvictRetry:        getDuration("SSE_EVICT_RETRY", 5*time.Second),
 		RetryAttempts:        getInt("RETRY_ATTEMPTS", 3),
 		RetryDelay:           getDuration("RETRY_DELAY", time.Second),
+		StorageBackend:       getEnv("STORAGE_BACKEND", "local"),
+		StorageLocalDir:      getEnv("STORAGE_LOCAL_DIR", "./data/uploads"),
+		StorageS3Endpoint:    os.Getenv("STORAGE_S3_ENDPOINT"),
+		StorageS3Region:      getEnv("STORAGE_S3_REGION", "us-east-1"),
+		StorageS3Bucket:      os.Getenv("STORAGE_S3_BUCKET"),
+		StorageS3AccessKey:   os.Getenv("STORAGE_S3_ACCESS_KEY"),
+		StorageS3SecretKey:   os.Getenv("STORAGE_S3_SECRET_KEY"),
+		StorageS3PathStyle:   getBool("STORAGE_S3_PATH_STYLE", true),
+		StorageSigningSecret: os.Getenv("STORAGE_SIGNING_SECRET"),
+		StorageURLTTL:        getDuration("STORAGE_URL_TTL", 15*time.Minute),
+		StorageMaxUploadSize: int64(getInt("STORAGE_MAX_UPLOAD_SIZE", 10<<20)),
+		StorageAllowedTypes:  getList("STORAGE_ALLOWED_TYPES", []string{"image/jpeg", "image/png", "image/gif", "image/webp", "application/pdf", "text/plain"}),
+		StorageBaseURL:       strings.TrimSuffix(os.Getenv("STORAGE_BASE_URL"), "/"),
+		AvatarMaxUploadSize:  int64(getInt("AVATAR_MAX_UPLOAD_SIZE", 5<<20)),
+		AvatarMaxPixels:      getInt("AVATAR_MAX_PIXELS", 25_000_000),
+		ExportLinkTTL:        getDuration("EXPORT_LINK_TTL", 72*time.Hour),
+		OutboxPollInterval:   getDuration("OUTBOX_POLL_INTERVAL", time.Second),
+		OutboxMaxAttempts:    getInt("OUTBOX_MAX_ATTEMPTS", 10),
+		OutboxRetention:      getDuration("OUTBOX_RETENTION", 24*time.Hour),
+		OutboxWebhookSecret:  os.Getenv("OUTBOX_WEBHOOK_SECRET"),
+		OutboxWebhookTimeout: getDuration("OUTBOX_WEBHOOK_TIMEOUT", 10*time.Second),
+	}
+
+	if cfg.PoWSecret == "" {
+		cfg.PoWSecret = cfg.JWTSecret
+	}
+	if cfg.StorageSigningSecret == "" {
+		cfg.StorageSigningSecret = cfg.JWTSecret
 	}
 
 	if err := cfg.validate(); err != nil {
@@ -146,6 +239,29 @@ func (c *Config) validate() error {
 	if strings.HasPrefix(c.JWTSecret, "CHANGE_ME") {
 		return fmt.Errorf("JWT_SECRET contains the placeholder value — generate a real secret with: openssl rand -hex 32")
 	}
+	switch c.SSEOverflowPolicy {
+	case "drop_newest", "drop_oldest", "disconnect":
+	default:
+		return fmt.Errorf("SSE_OVERFLOW_POLICY must be \"drop_newest\", \"drop_oldest\", or \"disconnect\", got %q", c.SSEOverflowPolicy)
+	}
+	if c.OutboxPollInterval <= 0 {
+		return fmt.Errorf("OUTBOX_POLL_INTERVAL must be positive, got %s", c.OutboxPollInterval)
+	}
+	if c.OutboxMaxAttempts < 1 {
+		return fmt.Errorf("OUTBOX_MAX_ATTEMPTS must be at least 1, got %d", c.OutboxMaxAttempts)
+	}
+	switch c.StorageBackend {
+	case "local":
+	case "s3":
+		if c.StorageS3Endpoint == "" || c.StorageS3Bucket == "" {
+			return fmt.Errorf("STORAGE_S3_ENDPOINT and STORAGE_S3_BUCKET are required when STORAGE_BACKEND=s3")
+		}
+		if u, err := url.Parse(c.StorageS3Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("STORAGE_S3_ENDPOINT must be an absolute URL, got %q", c.StorageS3Endpoint)
+		}
+	default:
+		return fmt.Errorf("STORAGE_BACKEND must be \"local\" or \"s3\", got %q", c.StorageBackend)
+	}
 	return nil
 }
 
@@ -202,6 +318,21 @@ func getFloat(key string, fallback float64) float64 {
 	return fallback
 }
 
+// getList reads a comma-separated list, dropping empty entries.
+func getList(key string, fallback []string) []string {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+	var result []string
+	for _, v := range strings.Split(value, ",") {
+		if trimmed := strings.TrimSpace(v); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
+
 func getAllowedOrigins() []string {
 	env := getEnv("ENVIRONMENT", "development")
 	if env == "development" {
@@ -3,6 +3,7 @@ package config_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/golid-ai/golid/backend/internal/config"
 )
@@ -304,3 +305,143 @@ func TestLoad_OptionalFields(t *testing.T) {
 		t.Errorf("expected FrontendURL, got %s", cfg.FrontendURL)
 	}
 }
+
+func TestLoad_PoWSecretFallsBackToJWTSecret(t *testing.T) {
+	os.Clearenv()
+	if err := os.Setenv("DATABASE_URL", "postgres://localhost/test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("JWT_SECRET", "this-is-a-very-long-secret-key-for-testing-purposes"); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.PoWSecret != cfg.JWTSecret {
+		t.Errorf("expected PoWSecret to default to JWTSecret, got %q", cfg.PoWSecret)
+	}
+	if cfg.PoWDifficulty != 18 || cfg.PoWFailureThreshold != 5 {
+		t.Errorf("unexpected PoW defaults: difficulty=%d threshold=%d", cfg.PoWDifficulty, cfg.PoWFailureThreshold)
+	}
+
+	if err := os.Setenv("POW_SECRET", "separate-pow-secret"); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.PoWSecret != "separate-pow-secret" {
+		t.Errorf("expected POW_SECRET to be used, got %q", cfg.PoWSecret)
+	}
+}
+
+func TestLoad_StorageDefaultsAndValidation(t *testing.T) {
+	os.Clearenv()
+	if err := os.Setenv("DATABASE_URL", "postgres://localhost/test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("JWT_SECRET", "this-is-a-very-long-secret-key-for-testing-purposes"); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.StorageBackend != "local" || cfg.StorageSigningSecret != cfg.JWTSecret {
+		t.Errorf("unexpected storage defaults: backend=%q", cfg.StorageBackend)
+	}
+	if cfg.StorageMaxUploadSize != 10<<20 || len(cfg.StorageAllowedTypes) == 0 {
+		t.Errorf("unexpected upload defaults: max=%d types=%v", cfg.StorageMaxUploadSize, cfg.StorageAllowedTypes)
+	}
+
+	if err := os.Setenv("STORAGE_ALLOWED_TYPES", "image/*, ,application/pdf"); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = config.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(cfg.StorageAllowedTypes) != 2 || cfg.StorageAllowedTypes[0] != "image/*" {
+		t.Errorf("StorageAllowedTypes = %v", cfg.StorageAllowedTypes)
+	}
+
+	if err := os.Setenv("STORAGE_BACKEND", "s3"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.Load(); err == nil {
+		t.Error("expected error for s3 backend without endpoint/bucket")
+	}
+
+	if err := os.Setenv("STORAGE_BACKEND", "gcs"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.Load(); err == nil {
+		t.Error("expected error for unknown storage backend")
+	}
+}
+
+func TestLoad_SSEOverflowPolicy(t *testing.T) {
+	os.Clearenv()
+	if err := os.Setenv("DATABASE_URL", "postgres://localhost/test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("JWT_SECRET", "this-is-a-very-long-secret-key-for-testing-purposes"); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.SSEOverflowPolicy != "drop_newest" || cfg.SSEClientBuffer != 16 || cfg.SSEMaxConnsPerUser != 5 {
+		t.Errorf("unexpected SSE defaults: policy=%q buffer=%d conns=%d", cfg.SSEOverflowPolicy, cfg.SSEClientBuffer, cfg.SSEMaxConnsPerUser)
+	}
+
+	if err := os.Setenv("SSE_OVERFLOW_POLICY", "disconnect"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.Load(); err != nil {
+		t.Errorf("unexpected error for disconnect policy: %v", err)
+	}
+
+	// Coalesce needs a key per event type, so it can't be the default.
+	if err := os.Setenv("SSE_OVERFLOW_POLICY", "coalesce"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.Load(); err == nil {
+		t.Error("expected error for coalesce as the default policy")
+	}
+}
+
+func TestLoad_OutboxDefaultsAndValidation(t *testing.T) {
+	os.Clearenv()
+	if err := os.Setenv("DATABASE_URL", "postgres://localhost/test"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Setenv("JWT_SECRET", "this-is-a-very-long-secret-key-for-testing-purposes"); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.OutboxPollInterval != time.Second || cfg.OutboxMaxAttempts != 10 || cfg.OutboxRetention != 24*time.Hour {
+		t.Errorf("unexpected outbox defaults: poll=%s attempts=%d retention=%s",
+			cfg.OutboxPollInterval, cfg.OutboxMaxAttempts, cfg.OutboxRetention)
+	}
+	if cfg.OutboxWebhookSecret != "" || cfg.OutboxWebhookTimeout != 10*time.Second {
+		t.Errorf("unexpected webhook defaults: secret=%q timeout=%s", cfg.OutboxWebhookSecret, cfg.OutboxWebhookTimeout)
+	}
+
+	if err := os.Setenv("OUTBOX_MAX_ATTEMPTS", "0"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := config.Load(); err == nil {
+		t.Error("expected error for OUTBOX_MAX_ATTEMPTS=0")
+	}
+}
@@ -0,0 +1,323 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/logger"
+	"github.com/golid-ai/golid/backend/internal/models"
+	"github.com/golid-ai/golid/backend/internal/queue"
+	"github.com/golid-ai/golid/backend/internal/service/auth"
+	"github.com/golid-ai/golid/backend/internal/service/email"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
+	"github.com/golid-ai/golid/backend/internal/service/preference"
+	"github.com/golid-ai/golid/backend/internal/service/sse"
+	"github.com/golid-ai/golid/backend/internal/service/user"
+	"github.com/golid-ai/golid/backend/internal/validate"
+)
+
+// AdminUserHandler handles admin user management endpoints.
+type AdminUserHandler struct {
+	userService       adminUserServicer
+	authService       authServicer
+	emailService      emailServicer
+	prefs             regionalReader
+	hub               sseDisconnecter
+	paginationDefault int
+	paginationMax     int
+}
+
+// NewAdminUserHandler creates a new admin user handler.
+func NewAdminUserHandler(userService *user.UserService, authService *auth.AuthService, emailService *email.EmailService, prefs *preference.PreferenceService, hub *sse.SSEHub, paginationDefault, paginationMax int) *AdminUserHandler {
+	return &AdminUserHandler{
+		userService:       userService,
+		authService:       authService,
+		emailService:      emailService,
+		prefs:             prefs,
+		hub:               hub,
+		paginationDefault: paginationDefault,
+		paginationMax:     paginationMax,
+	}
+}
+
+// List handles GET /api/v1/admin/users
+//
+// Query params: page, per_page, search, type (user|admin),
+// status (active|suspended|banned), verified (true|false), created_after, created_before (RFC 3339 or
+// YYYY-MM-DD; created_before is exclusive).
+func (h *AdminUserHandler) List(c echo.Context) error {
+	page, perPage := ParsePagination(c, h.paginationDefault, h.paginationMax)
+
+	input := &user.ListUsersInput{
+		Page:    page,
+		PerPage: perPage,
+		Search:  strings.TrimSpace(c.QueryParam("search")),
+		Type:    c.QueryParam("type"),
+		Status:  c.QueryParam("status"),
+	}
+
+	verr := apperror.Validation()
+	if input.Type != "" && !user.ValidUserTypes[input.Type] {
+		verr.WithDetail("type", "error.user.type")
+	}
+	if input.Status != "" && !models.UserStatus(input.Status).Valid() {
+		verr.WithDetail("status", "error.user.status")
+	}
+	if v := c.QueryParam("verified"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			verr.WithDetail("verified", "error.user.verified_bool")
+		} else {
+			input.EmailVerified = &b
+		}
+	}
+	if v := c.QueryParam("created_after"); v != "" {
+		t, err := parseDateParam(v)
+		if err != nil {
+			verr.WithDetail("created_after", "error.date_param")
+		} else {
+			input.CreatedAfter = &t
+		}
+	}
+	if v := c.QueryParam("created_before"); v != "" {
+		t, err := parseDateParam(v)
+		if err != nil {
+			verr.WithDetail("created_before", "error.date_param")
+		} else {
+			input.CreatedBefore = &t
+		}
+	}
+	if len(verr.Details) > 0 {
+		return verr
+	}
+
+	result, err := h.userService.ListUsers(c.Request().Context(), input)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, result)
+}
+
+// Get handles GET /api/v1/admin/users/:id
+func (h *AdminUserHandler) Get(c echo.Context) error {
+	id := c.Param("id")
+	if err := validate.UUID(id, "id"); err != nil {
+		return err
+	}
+
+	profile, err := h.userService.GetByID(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, profile)
+}
+
+// AdminUpdateUserRequest is the request body for PATCH /admin/users/:id.
+// Omitted fields are left unchanged.
+type AdminUpdateUserRequest struct {
+	Type              *string `json:"type"`
+	EmailVerified     *bool   `json:"email_verified"`
+	SendPasswordReset bool    `json:"send_password_reset"`
+}
+
+// Update handles PATCH /api/v1/admin/users/:id
+func (h *AdminUserHandler) Update(c echo.Context) error {
+	adminID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id := c.Param("id")
+	if err := validate.UUID(id, "id"); err != nil {
+		return err
+	}
+
+	var req AdminUpdateUserRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.InvalidBody()
+	}
+
+	if req.Type == nil && req.EmailVerified == nil && !req.SendPasswordReset {
+		return apperror.BadRequest("No changes requested").WithMessageID("error.user.no_changes")
+	}
+	if req.Type != nil && !user.ValidUserTypes[*req.Type] {
+		return apperror.Validation().WithDetail("type", "error.user.type")
+	}
+	// Demoting yourself would lock the last admin out of this endpoint.
+	if req.Type != nil && id == adminID {
+		return apperror.BadRequest("You cannot change your own account type").WithMessageID("error.user.own_type")
+	}
+	// Checked before any change so the request is not half applied.
+	if req.SendPasswordReset && !h.emailService.IsConfigured() {
+		return apperror.BadRequest("Email delivery is not configured").WithMessageID("error.email_not_configured")
+	}
+
+	ctx := c.Request().Context()
+	var profile *user.UserProfile
+	if req.Type != nil || req.EmailVerified != nil {
+		profile, err = h.userService.AdminUpdate(ctx, id, &user.AdminUpdate{
+			Type:          req.Type,
+			EmailVerified: req.EmailVerified,
+		})
+	} else {
+		profile, err = h.userService.GetByID(ctx, id)
+	}
+	if err != nil {
+		return err
+	}
+
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	logger.Info("admin updated user",
+		slog.String("request_id", requestID),
+		slog.String("admin_id", adminID),
+		slog.String("user_id", id),
+		slog.Bool("type_changed", req.Type != nil),
+		slog.Bool("verification_changed", req.EmailVerified != nil),
+		slog.Bool("password_reset", req.SendPasswordReset),
+	)
+
+	if req.SendPasswordReset {
+		if err := h.sendPasswordReset(ctx, h.recipient(ctx, id, profile.Email)); err != nil {
+			return err
+		}
+	}
+
+	return c.JSON(http.StatusOK, profile)
+}
+
+// sendPasswordReset issues a reset token and writes the email to the
+// outbox in the same transaction. Unlike the public endpoint, failures are
+// surfaced — the admin needs to know whether the email will go out.
+func (h *AdminUserHandler) sendPasswordReset(ctx context.Context, to email.Recipient) error {
+	token, err := h.authService.ForgotPassword(ctx, &auth.ForgotPasswordInput{
+		Email: to.Email,
+		ResetEmail: func(token string) (outbox.Message, error) {
+			task, err := queue.NewSendPasswordReset(to, token)
+			if err != nil {
+				return outbox.Message{}, err
+			}
+			return outbox.Task(task), nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return apperror.NotFound("User")
+	}
+	return nil
+}
+
+// SetUserStatusRequest is the request body for PUT /admin/users/:id/status.
+type SetUserStatusRequest struct {
+	Status         string     `json:"status"`
+	SuspendedUntil *time.Time `json:"suspended_until"`
+	Reason         string     `json:"reason"`
+}
+
+// SetStatus handles PUT /api/v1/admin/users/:id/status
+//
+// Suspending or banning revokes the user's refresh tokens, closes their
+// open SSE connections (after an "account_suspended" event), and emails
+// them. Setting status back to active reinstates the account. The email is
+// written to the outbox with the change.
+func (h *AdminUserHandler) SetStatus(c echo.Context) error {
+	adminID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id := c.Param("id")
+	if err := validate.UUID(id, "id"); err != nil {
+		return err
+	}
+
+	var req SetUserStatusRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.InvalidBody()
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+
+	if id == adminID {
+		return apperror.BadRequest("You cannot change your own account status").WithMessageID("error.user.own_status")
+	}
+
+	ctx := c.Request().Context()
+	status := models.UserStatus(req.Status)
+	input := &user.SetStatusInput{
+		Status:         status,
+		SuspendedUntil: req.SuspendedUntil,
+		Reason:         req.Reason,
+		ActorID:        adminID,
+	}
+	if h.emailService.IsConfigured() {
+		// Preferences are read up front, not while the status
+		// transaction holds a connection.
+		to := h.recipient(ctx, id, "")
+		input.StatusEmail = func(address string, until *time.Time) (outbox.Message, error) {
+			to.Email = address
+			task, err := queue.NewSendAccountStatus(to, string(status), until, req.Reason)
+			if err != nil {
+				return outbox.Message{}, err
+			}
+			return outbox.Task(task), nil
+		}
+	}
+
+	profile, err := h.userService.SetStatus(ctx, id, input)
+	if err != nil {
+		return err
+	}
+
+	requestID := c.Response().Header().Get(echo.HeaderXRequestID)
+	logger.Info("admin changed user status",
+		slog.String("request_id", requestID),
+		slog.String("admin_id", adminID),
+		slog.String("user_id", id),
+		slog.String("status", string(status)),
+	)
+
+	if status != models.UserStatusActive {
+		data := map[string]any{"status": string(status), "reason": req.Reason}
+		if profile.SuspendedUntil != nil {
+			data["suspended_until"] = profile.SuspendedUntil.UTC().Format(time.RFC3339)
+		}
+		h.hub.Send(id, sse.SSEEvent{Event: "account_suspended", Data: data})
+		h.hub.Disconnect(id)
+	}
+
+	return c.JSON(http.StatusOK, profile)
+}
+
+// recipient addresses an email to the affected user in their own locale
+// and time zone; the admin's Accept-Language is deliberately ignored. A
+// preference read failure falls back to the defaults.
+func (h *AdminUserHandler) recipient(ctx context.Context, userID, toEmail string) email.Recipient {
+	r, err := h.prefs.Regional(ctx, userID)
+	if err != nil {
+		logger.Warn("failed to read regional preferences",
+			slog.String("user_id", userID),
+			slog.String("error", err.Error()),
+		)
+		return email.Recipient{Email: toEmail}
+	}
+	return email.Recipient{Email: toEmail, Locale: r.Locale, Location: r.Location}
+}
+
+// parseDateParam accepts an RFC 3339 timestamp or a bare YYYY-MM-DD date
+// (midnight UTC).
+func parseDateParam(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.DateOnly, v)
+}
@@ -0,0 +1,399 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/queue"
+	"github.com/golid-ai/golid/backend/internal/service/auth"
+	"github.com/golid-ai/golid/backend/internal/service/email"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
+	"github.com/golid-ai/golid/backend/internal/service/preference"
+	"github.com/golid-ai/golid/backend/internal/service/sse"
+	"github.com/golid-ai/golid/backend/internal/service/user"
+)
+
+// =============================================================================
+// MOCK ADMIN USER SERVICE
+// =============================================================================
+
+type mockAdminUserService struct {
+	getByIDFn     func(ctx context.Context, userID string) (*user.UserProfile, error)
+	listUsersFn   func(ctx context.Context, input *user.ListUsersInput) (*user.UserListResult, error)
+	adminUpdateFn func(ctx context.Context, userID string, update *user.AdminUpdate) (*user.UserProfile, error)
+	setStatusFn   func(ctx context.Context, userID string, input *user.SetStatusInput) (*user.UserProfile, error)
+}
+
+func (m *mockAdminUserService) GetByID(ctx context.Context, userID string) (*user.UserProfile, error) {
+	if m.getByIDFn != nil {
+		return m.getByIDFn(ctx, userID)
+	}
+	panic("unexpected GetByID")
+}
+func (m *mockAdminUserService) ListUsers(ctx context.Context, input *user.ListUsersInput) (*user.UserListResult, error) {
+	if m.listUsersFn != nil {
+		return m.listUsersFn(ctx, input)
+	}
+	panic("unexpected ListUsers")
+}
+func (m *mockAdminUserService) AdminUpdate(ctx context.Context, userID string, update *user.AdminUpdate) (*user.UserProfile, error) {
+	if m.adminUpdateFn != nil {
+		return m.adminUpdateFn(ctx, userID, update)
+	}
+	panic("unexpected AdminUpdate")
+}
+func (m *mockAdminUserService) SetStatus(ctx context.Context, userID string, input *user.SetStatusInput) (*user.UserProfile, error) {
+	if m.setStatusFn != nil {
+		return m.setStatusFn(ctx, userID, input)
+	}
+	panic("unexpected SetStatus")
+}
+
+type mockRegionalReader struct {
+	regional preference.Regional
+	err      error
+}
+
+func (m *mockRegionalReader) Regional(_ context.Context, _ string) (preference.Regional, error) {
+	return m.regional, m.err
+}
+
+const adminTestUserID = "11111111-1111-1111-1111-111111111111"
+
+func newAdminUserHandler(svc *mockAdminUserService, authSvc *mockAuthService, emailSvc *mockEmailService) *AdminUserHandler {
+	return &AdminUserHandler{
+		userService:       svc,
+		authService:       authSvc,
+		emailService:      emailSvc,
+		prefs:             &mockRegionalReader{regional: preference.Regional{Location: time.UTC}},
+		hub:               &mockSSEHub{},
+		paginationDefault: 20,
+		paginationMax:     100,
+	}
+}
+
+func adminContext(method, target, body, id string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	var req *http.Request
+	if body != "" {
+		req = httptest.NewRequest(method, target, strings.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	} else {
+		req = httptest.NewRequest(method, target, nil)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", "admin-1")
+	c.Set("user_type", "admin")
+	if id != "" {
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+	}
+	return c, rec
+}
+
+// =============================================================================
+// LIST
+// =============================================================================
+
+func TestAdminUsersList_PassesFilters(t *testing.T) {
+	var got *user.ListUsersInput
+	h := newAdminUserHandler(&mockAdminUserService{
+		listUsersFn: func(_ context.Context, input *user.ListUsersInput) (*user.UserListResult, error) {
+			got = input
+			return &user.UserListResult{Users: []user.UserProfile{}, Page: input.Page, PerPage: input.PerPage}, nil
+		},
+	}, nil, nil)
+
+	c, rec := adminContext(http.MethodGet,
+		"/api/v1/admin/users?page=2&per_page=10&search=+ali+&type=admin&verified=false&created_after=2026-01-01&created_before=2026-02-01T00:00:00Z",
+		"", "")
+	if err := h.List(c); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+	if got.Page != 2 || got.PerPage != 10 || got.Search != "ali" || got.Type != "admin" {
+		t.Errorf("unexpected input: %+v", got)
+	}
+	if got.EmailVerified == nil || *got.EmailVerified {
+		t.Error("EmailVerified should be false")
+	}
+	if got.CreatedAfter == nil || !got.CreatedAfter.Equal(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("CreatedAfter = %v", got.CreatedAfter)
+	}
+	if got.CreatedBefore == nil || !got.CreatedBefore.Equal(time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("CreatedBefore = %v", got.CreatedBefore)
+	}
+}
+
+func TestAdminUsersList_InvalidFilters(t *testing.T) {
+	h := newAdminUserHandler(&mockAdminUserService{}, nil, nil)
+
+	c, _ := adminContext(http.MethodGet, "/api/v1/admin/users?type=root&status=deleted&verified=maybe&created_after=yesterday", "", "")
+	err := h.List(c)
+
+	var appErr *apperror.AppError
+	if !errors.As(err, &appErr) || appErr.Code != apperror.CodeValidation {
+		t.Fatalf("err = %v, want Validation", err)
+	}
+	for _, field := range []string{"type", "status", "verified", "created_after"} {
+		if _, ok := appErr.Details[field]; !ok {
+			t.Errorf("missing detail for %q", field)
+		}
+	}
+}
+
+// =============================================================================
+// GET
+// =============================================================================
+
+func TestAdminUsersGet_InvalidID(t *testing.T) {
+	h := newAdminUserHandler(&mockAdminUserService{}, nil, nil)
+	c, _ := adminContext(http.MethodGet, "/api/v1/admin/users/nope", "", "nope")
+	if err := h.Get(c); !apperror.Is(err, apperror.CodeBadRequest) {
+		t.Errorf("err = %v, want BadRequest", err)
+	}
+}
+
+func TestAdminUsersGet_Success(t *testing.T) {
+	h := newAdminUserHandler(&mockAdminUserService{
+		getByIDFn: func(_ context.Context, id string) (*user.UserProfile, error) {
+			p := testUserProfile("Jane")
+			p.ID = id
+			return p, nil
+		},
+	}, nil, nil)
+
+	c, rec := adminContext(http.MethodGet, "/api/v1/admin/users/"+adminTestUserID, "", adminTestUserID)
+	if err := h.Get(c); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	var body user.UserProfile
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if body.ID != adminTestUserID {
+		t.Errorf("ID = %q, want %q", body.ID, adminTestUserID)
+	}
+}
+
+// =============================================================================
+// UPDATE
+// =============================================================================
+
+func TestAdminUsersUpdate_NoChanges(t *testing.T) {
+	h := newAdminUserHandler(&mockAdminUserService{}, nil, nil)
+	c, _ := adminContext(http.MethodPatch, "/", `{}`, adminTestUserID)
+	if err := h.Update(c); !apperror.Is(err, apperror.CodeBadRequest) {
+		t.Errorf("err = %v, want BadRequest", err)
+	}
+}
+
+func TestAdminUsersUpdate_InvalidType(t *testing.T) {
+	h := newAdminUserHandler(&mockAdminUserService{}, nil, nil)
+	c, _ := adminContext(http.MethodPatch, "/", `{"type":"root"}`, adminTestUserID)
+	if err := h.Update(c); !apperror.Is(err, apperror.CodeValidation) {
+		t.Errorf("err = %v, want Validation", err)
+	}
+}
+
+func TestAdminUsersUpdate_CannotChangeOwnType(t *testing.T) {
+	h := newAdminUserHandler(&mockAdminUserService{}, nil, nil)
+	c, _ := adminContext(http.MethodPatch, "/", `{"type":"user"}`, adminTestUserID)
+	c.Set("user_id", adminTestUserID)
+	if err := h.Update(c); !apperror.Is(err, apperror.CodeBadRequest) {
+		t.Errorf("err = %v, want BadRequest", err)
+	}
+}
+
+func TestAdminUsersUpdate_TypeAndVerification(t *testing.T) {
+	var got *user.AdminUpdate
+	h := newAdminUserHandler(&mockAdminUserService{
+		adminUpdateFn: func(_ context.Context, _ string, update *user.AdminUpdate) (*user.UserProfile, error) {
+			got = update
+			p := testUserProfile("Jane")
+			p.Type = *update.Type
+			return p, nil
+		},
+	}, nil, nil)
+
+	c, rec := adminContext(http.MethodPatch, "/", `{"type":"admin","email_verified":true}`, adminTestUserID)
+	if err := h.Update(c); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+	if got == nil || *got.Type != "admin" || got.EmailVerified == nil || !*got.EmailVerified {
+		t.Errorf("unexpected update: %+v", got)
+	}
+}
+
+func TestAdminUsersUpdate_PasswordResetWrittenToOutbox(t *testing.T) {
+	var input *auth.ForgotPasswordInput
+	h := newAdminUserHandler(&mockAdminUserService{
+		getByIDFn: func(_ context.Context, _ string) (*user.UserProfile, error) {
+			return testUserProfile("Jane"), nil
+		},
+	}, &mockAuthService{
+		forgotPasswordFn: func(_ context.Context, in *auth.ForgotPasswordInput) (string, error) {
+			input = in
+			return "selector.verifier", nil
+		},
+	}, &mockEmailService{configured: true})
+	h.prefs = &mockRegionalReader{regional: preference.Regional{Locale: "es", Location: time.UTC}}
+
+	c, _ := adminContext(http.MethodPatch, "/", `{"send_password_reset":true}`, adminTestUserID)
+	if err := h.Update(c); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if input == nil || input.Email != "test@example.com" || input.ResetEmail == nil {
+		t.Fatalf("unexpected input: %+v", input)
+	}
+	msg, err := input.ResetEmail("selector.verifier")
+	if err != nil {
+		t.Fatalf("ResetEmail() error = %v", err)
+	}
+	to := email.Recipient{Email: "test@example.com", Locale: "es", Location: time.UTC}
+	task, _ := queue.NewSendPasswordReset(to, "selector.verifier")
+	if want := outbox.Task(task); !reflect.DeepEqual(msg, want) {
+		t.Errorf("ResetEmail() = %+v, want %+v", msg, want)
+	}
+}
+
+func TestAdminUsersUpdate_PasswordResetUnknownUser(t *testing.T) {
+	h := newAdminUserHandler(&mockAdminUserService{
+		getByIDFn: func(_ context.Context, _ string) (*user.UserProfile, error) {
+			return testUserProfile("Jane"), nil
+		},
+	}, &mockAuthService{
+		forgotPasswordFn: func(_ context.Context, _ *auth.ForgotPasswordInput) (string, error) {
+			return "", nil
+		},
+	}, &mockEmailService{configured: true})
+
+	c, _ := adminContext(http.MethodPatch, "/", `{"send_password_reset":true}`, adminTestUserID)
+	if err := h.Update(c); !apperror.Is(err, apperror.CodeNotFound) {
+		t.Errorf("err = %v, want NotFound", err)
+	}
+}
+
+func TestAdminUsersUpdate_PasswordResetEmailNotConfigured(t *testing.T) {
+	// Rejected before the type change is applied.
+	h := newAdminUserHandler(&mockAdminUserService{}, &mockAuthService{}, &mockEmailService{configured: false})
+
+	c, _ := adminContext(http.MethodPatch, "/", `{"type":"admin","send_password_reset":true}`, adminTestUserID)
+	if err := h.Update(c); !apperror.Is(err, apperror.CodeBadRequest) {
+		t.Errorf("err = %v, want BadRequest", err)
+	}
+}
+
+// =============================================================================
+// STATUS
+// =============================================================================
+
+func TestAdminUsersSetStatus_CannotChangeOwnStatus(t *testing.T) {
+	h := newAdminUserHandler(&mockAdminUserService{}, nil, nil)
+	c, _ := adminContext(http.MethodPut, "/", `{"status":"banned","reason":"x"}`, adminTestUserID)
+	c.Set("user_id", adminTestUserID)
+	if err := h.SetStatus(c); !apperror.Is(err, apperror.CodeBadRequest) {
+		t.Errorf("err = %v, want BadRequest", err)
+	}
+}
+
+func TestAdminUsersSetStatus_SuspendDisconnectsAndEmails(t *testing.T) {
+	until := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	var got *user.SetStatusInput
+	svc := &mockAdminUserService{
+		setStatusFn: func(_ context.Context, _ string, input *user.SetStatusInput) (*user.UserProfile, error) {
+			got = input
+			p := testUserProfile("Jane")
+			p.Status = "suspended"
+			p.SuspendedUntil = input.SuspendedUntil
+			return p, nil
+		},
+	}
+	h := newAdminUserHandler(svc, nil, &mockEmailService{configured: true})
+
+	var sent []string
+	var disconnected string
+	h.hub = &mockSSEHub{
+		sendFn:       func(_ string, event sse.SSEEvent) { sent = append(sent, event.Event) },
+		disconnectFn: func(userID string) { disconnected = userID },
+	}
+
+	body := `{"status":"suspended","suspended_until":"` + until.Format(time.RFC3339) + `","reason":"  spam  "}`
+	c, rec := adminContext(http.MethodPut, "/", body, adminTestUserID)
+	if err := h.SetStatus(c); err != nil {
+		t.Fatalf("SetStatus() error = %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+	if got == nil || got.Status != "suspended" || got.Reason != "spam" || got.ActorID != "admin-1" ||
+		got.SuspendedUntil == nil || !got.SuspendedUntil.Equal(until) {
+		t.Errorf("unexpected input: %+v", got)
+	}
+	if len(sent) != 1 || sent[0] != "account_suspended" {
+		t.Errorf("sent events = %v, want [account_suspended]", sent)
+	}
+	if disconnected != adminTestUserID {
+		t.Errorf("disconnected = %q, want %q", disconnected, adminTestUserID)
+	}
+	if got.StatusEmail == nil {
+		t.Fatal("expected SetStatusInput.StatusEmail to be set")
+	}
+	msg, err := got.StatusEmail("jane@example.com", &until)
+	if err != nil {
+		t.Fatalf("StatusEmail() error = %v", err)
+	}
+	task, _ := queue.NewSendAccountStatus(email.Recipient{Email: "jane@example.com", Location: time.UTC}, "suspended", &until, "spam")
+	if want := outbox.Task(task); !reflect.DeepEqual(msg, want) {
+		t.Errorf("StatusEmail() = %+v, want %+v", msg, want)
+	}
+}
+
+func TestAdminUsersSetStatus_ReactivateKeepsConnections(t *testing.T) {
+	h := newAdminUserHandler(&mockAdminUserService{
+		setStatusFn: func(_ context.Context, _ string, _ *user.SetStatusInput) (*user.UserProfile, error) {
+			return testUserProfile("Jane"), nil
+		},
+	}, nil, &mockEmailService{configured: false})
+	h.hub = &mockSSEHub{
+		disconnectFn: func(string) { t.Error("Disconnect should not be called on reactivation") },
+	}
+
+	c, _ := adminContext(http.MethodPut, "/", `{"status":"active"}`, adminTestUserID)
+	if err := h.SetStatus(c); err != nil {
+		t.Fatalf("SetStatus() error = %v", err)
+	}
+}
+
+func TestAdminUsersRecipient_UsesTargetPreferences(t *testing.T) {
+	h := newAdminUserHandler(&mockAdminUserService{}, nil, nil)
+	tokyo := time.FixedZone("JST", 9*60*60)
+	h.prefs = &mockRegionalReader{regional: preference.Regional{Locale: "es", Location: tokyo}}
+
+	to := h.recipient(context.Background(), adminTestUserID, "jane@example.com")
+	if to.Email != "jane@example.com" || to.Locale != "es" || to.Location != tokyo {
+		t.Errorf("recipient = %+v", to)
+	}
+
+	h.prefs = &mockRegionalReader{err: errors.New("db down")}
+	to = h.recipient(context.Background(), adminTestUserID, "jane@example.com")
+	if to.Email != "jane@example.com" || to.Locale != "" || to.Location != nil {
+		t.Errorf("fallback recipient = %+v", to)
+	}
+}
@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/service/announcement"
+	"github.com/golid-ai/golid/backend/internal/validate"
+)
+
+// AnnouncementHandler handles announcement endpoints.
+type AnnouncementHandler struct {
+	announcementService announcementServicer
+	paginationDefault   int
+	paginationMax       int
+}
+
+func NewAnnouncementHandler(as announcementServicer, paginationDefault, paginationMax int) *AnnouncementHandler {
+	return &AnnouncementHandler{
+		announcementService: as,
+		paginationDefault:   paginationDefault,
+		paginationMax:       paginationMax,
+	}
+}
+
+// List handles GET /api/v1/announcements. Public; with a token, the list
+// is for the caller's audience and leaves out what they dismissed.
+func (h *AnnouncementHandler) List(c echo.Context) error {
+	var viewer announcement.Viewer
+	viewer.UserID, _ = contextString(c, "user_id")
+	viewer.UserType, _ = contextString(c, "user_type")
+
+	active, err := h.announcementService.Active(c.Request().Context(), viewer)
+	if err != nil {
+		return err
+	}
+	if active == nil {
+		active = []announcement.Announcement{}
+	}
+	return c.JSON(http.StatusOK, map[string]any{"announcements": active})
+}
+
+// Dismiss handles POST /api/v1/announcements/:id/dismiss.
+func (h *AnnouncementHandler) Dismiss(c echo.Context) error {
+	userID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+	id := c.Param("id")
+	if err := validate.UUID(id, "id"); err != nil {
+		return err
+	}
+	if err := h.announcementService.Dismiss(c.Request().Context(), userID, id); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// AdminList handles GET /api/v1/admin/announcements, including scheduled
+// and ended ones. Admin-only.
+func (h *AnnouncementHandler) AdminList(c echo.Context) error {
+	if err := requireAdmin(c); err != nil {
+		return err
+	}
+	page, perPage := ParsePagination(c, h.paginationDefault, h.paginationMax)
+	result, err := h.announcementService.List(c.Request().Context(), page, perPage)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// AdminCreate handles POST /api/v1/admin/announcements. Admin-only.
+func (h *AnnouncementHandler) AdminCreate(c echo.Context) error {
+	if err := requireAdmin(c); err != nil {
+		return err
+	}
+	adminID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+	var req announcement.NewAnnouncement
+	if err := c.Bind(&req); err != nil {
+		return apperror.InvalidBody()
+	}
+	created, err := h.announcementService.Create(c.Request().Context(), req, adminID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, created)
+}
+
+// AdminGet handles GET /api/v1/admin/announcements/:id. Admin-only.
+func (h *AnnouncementHandler) AdminGet(c echo.Context) error {
+	id, err := adminAnnouncementID(c)
+	if err != nil {
+		return err
+	}
+	a, err := h.announcementService.Get(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, a)
+}
+
+// UpdateAnnouncementRequest changes an announcement. Omitted fields are
+// left alone; a null ends_at makes it open-ended.
+type UpdateAnnouncementRequest struct {
+	Title       *string         `json:"title"`
+	Body        *string         `json:"body"`
+	Severity    *string         `json:"severity"`
+	Audience    *string         `json:"audience"`
+	StartsAt    *time.Time      `json:"starts_at"`
+	EndsAt      json.RawMessage `json:"ends_at"`
+	Dismissible *bool           `json:"dismissible"`
+}
+
+// AdminUpdate handles PATCH /api/v1/admin/announcements/:id. Admin-only.
+func (h *AnnouncementHandler) AdminUpdate(c echo.Context) error {
+	id, err := adminAnnouncementID(c)
+	if err != nil {
+		return err
+	}
+	var req UpdateAnnouncementRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return apperror.InvalidBody()
+	}
+	upd := announcement.Update{
+		Title:       req.Title,
+		Body:        req.Body,
+		Severity:    req.Severity,
+		Audience:    req.Audience,
+		StartsAt:    req.StartsAt,
+		Dismissible: req.Dismissible,
+	}
+	switch {
+	case req.EndsAt == nil:
+	case string(req.EndsAt) == "null":
+		upd.ClearEndsAt = true
+	default:
+		var t time.Time
+		if err := json.Unmarshal(req.EndsAt, &t); err != nil {
+			return apperror.Validation().WithDetail("ends_at", "error.timestamp_or_null")
+		}
+		upd.EndsAt = &t
+	}
+	a, err := h.announcementService.Update(c.Request().Context(), id, upd)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, a)
+}
+
+// AdminDelete handles DELETE /api/v1/admin/announcements/:id. Admin-only.
+func (h *AnnouncementHandler) AdminDelete(c echo.Context) error {
+	id, err := adminAnnouncementID(c)
+	if err != nil {
+		return err
+	}
+	if err := h.announcementService.Delete(c.Request().Context(), id); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// adminAnnouncementID checks that the caller is an admin and returns the
+// :id path parameter.
+func adminAnnouncementID(c echo.Context) (string, error) {
+	if err := requireAdmin(c); err != nil {
+		return "", err
+	}
+	id := c.Param("id")
+	if err := validate.UUID(id, "id"); err != nil {
+		return "", err
+	}
+	return id, nil
+}
@@ -0,0 +1,344 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/service/announcement"
+)
+
+const testAnnouncementID = "6f1c2d3e-4a5b-4c6d-8e7f-901234567890"
+
+type mockAnnouncementService struct {
+	activeFn  func(ctx context.Context, viewer announcement.Viewer) ([]announcement.Announcement, error)
+	dismissFn func(ctx context.Context, userID, id string) error
+	listFn    func(ctx context.Context, page, perPage int) (*announcement.ListResult, error)
+	getFn     func(ctx context.Context, id string) (*announcement.Announcement, error)
+	createFn  func(ctx context.Context, in announcement.NewAnnouncement, actorID string) (*announcement.Announcement, error)
+	updateFn  func(ctx context.Context, id string, upd announcement.Update) (*announcement.Announcement, error)
+	deleteFn  func(ctx context.Context, id string) error
+}
+
+func (m *mockAnnouncementService) Active(ctx context.Context, viewer announcement.Viewer) ([]announcement.Announcement, error) {
+	if m.activeFn != nil {
+		return m.activeFn(ctx, viewer)
+	}
+	panic("unexpected Active")
+}
+
+func (m *mockAnnouncementService) Dismiss(ctx context.Context, userID, id string) error {
+	if m.dismissFn != nil {
+		return m.dismissFn(ctx, userID, id)
+	}
+	panic("unexpected Dismiss")
+}
+
+func (m *mockAnnouncementService) List(ctx context.Context, page, perPage int) (*announcement.ListResult, error) {
+	if m.listFn != nil {
+		return m.listFn(ctx, page, perPage)
+	}
+	panic("unexpected List")
+}
+
+func (m *mockAnnouncementService) Get(ctx context.Context, id string) (*announcement.Announcement, error) {
+	if m.getFn != nil {
+		return m.getFn(ctx, id)
+	}
+	panic("unexpected Get")
+}
+
+func (m *mockAnnouncementService) Create(ctx context.Context, in announcement.NewAnnouncement, actorID string) (*announcement.Announcement, error) {
+	if m.createFn != nil {
+		return m.createFn(ctx, in, actorID)
+	}
+	panic("unexpected Create")
+}
+
+func (m *mockAnnouncementService) Update(ctx context.Context, id string, upd announcement.Update) (*announcement.Announcement, error) {
+	if m.updateFn != nil {
+		return m.updateFn(ctx, id, upd)
+	}
+	panic("unexpected Update")
+}
+
+func (m *mockAnnouncementService) Delete(ctx context.Context, id string) error {
+	if m.deleteFn != nil {
+		return m.deleteFn(ctx, id)
+	}
+	panic("unexpected Delete")
+}
+
+func TestAnnouncement_List(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   string
+		userType string
+	}{
+		{"anonymous", "", ""},
+		{"signed in", "user-1", "user"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got announcement.Viewer
+			mock := &mockAnnouncementService{
+				activeFn: func(_ context.Context, viewer announcement.Viewer) ([]announcement.Announcement, error) {
+					got = viewer
+					return nil, nil
+				},
+			}
+			h := NewAnnouncementHandler(mock, 20, 100)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/announcements", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			if tt.userID != "" {
+				c.Set("user_id", tt.userID)
+				c.Set("user_type", tt.userType)
+			}
+
+			if err := h.List(c); err != nil {
+				t.Fatalf("List() error = %v", err)
+			}
+			if got.UserID != tt.userID || got.UserType != tt.userType {
+				t.Errorf("viewer = %+v", got)
+			}
+			if body := strings.TrimSpace(rec.Body.String()); body != `{"announcements":[]}` {
+				t.Errorf("body = %s", body)
+			}
+		})
+	}
+}
+
+func TestAnnouncement_Dismiss(t *testing.T) {
+	var gotUser, gotID string
+	mock := &mockAnnouncementService{
+		dismissFn: func(_ context.Context, userID, id string) error {
+			gotUser, gotID = userID, id
+			return nil
+		},
+	}
+	h := NewAnnouncementHandler(mock, 20, 100)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/announcements/"+testAnnouncementID+"/dismiss", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(testAnnouncementID)
+	c.Set("user_id", "user-1")
+
+	if err := h.Dismiss(c); err != nil {
+		t.Fatalf("Dismiss() error = %v", err)
+	}
+	if rec.Code != http.StatusNoContent || gotUser != "user-1" || gotID != testAnnouncementID {
+		t.Errorf("status = %d, user = %q, id = %q", rec.Code, gotUser, gotID)
+	}
+}
+
+func TestAnnouncement_Dismiss_InvalidID(t *testing.T) {
+	h := NewAnnouncementHandler(&mockAnnouncementService{}, 20, 100)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/announcements/nope/dismiss", nil)
+	c := e.NewContext(req, httptest.NewRecorder())
+	c.SetParamNames("id")
+	c.SetParamValues("nope")
+	c.Set("user_id", "user-1")
+
+	if err := h.Dismiss(c); !apperror.Is(err, apperror.CodeBadRequest) {
+		t.Errorf("Dismiss() error = %v, want BadRequest", err)
+	}
+}
+
+func TestAnnouncement_AdminList(t *testing.T) {
+	var gotPage, gotPerPage int
+	mock := &mockAnnouncementService{
+		listFn: func(_ context.Context, page, perPage int) (*announcement.ListResult, error) {
+			gotPage, gotPerPage = page, perPage
+			return &announcement.ListResult{Page: page, PerPage: perPage}, nil
+		},
+	}
+	h := NewAnnouncementHandler(mock, 20, 100)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/announcements?page=2&per_page=5", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_type", "admin")
+
+	if err := h.AdminList(c); err != nil {
+		t.Fatalf("AdminList() error = %v", err)
+	}
+	if gotPage != 2 || gotPerPage != 5 {
+		t.Errorf("page = %d, per_page = %d", gotPage, gotPerPage)
+	}
+}
+
+func TestAnnouncement_Admin_NonAdmin(t *testing.T) {
+	h := NewAnnouncementHandler(&mockAnnouncementService{}, 20, 100)
+	handlers := map[string]echo.HandlerFunc{
+		"AdminList":   h.AdminList,
+		"AdminCreate": h.AdminCreate,
+		"AdminGet":    h.AdminGet,
+		"AdminUpdate": h.AdminUpdate,
+		"AdminDelete": h.AdminDelete,
+	}
+	for name, fn := range handlers {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/announcements", strings.NewReader(`{}`))
+		c := e.NewContext(req, httptest.NewRecorder())
+		c.SetParamNames("id")
+		c.SetParamValues(testAnnouncementID)
+		c.Set("user_id", "user-1")
+		c.Set("user_type", "user")
+
+		if err := fn(c); !apperror.Is(err, apperror.CodeForbidden) {
+			t.Errorf("%s() error = %v, want Forbidden", name, err)
+		}
+	}
+}
+
+func TestAnnouncement_AdminCreate(t *testing.T) {
+	var gotIn announcement.NewAnnouncement
+	var gotActor string
+	mock := &mockAnnouncementService{
+		createFn: func(_ context.Context, in announcement.NewAnnouncement, actorID string) (*announcement.Announcement, error) {
+			gotIn, gotActor = in, actorID
+			return &announcement.Announcement{ID: testAnnouncementID, Title: in.Title}, nil
+		},
+	}
+	h := NewAnnouncementHandler(mock, 20, 100)
+
+	e := echo.New()
+	body := `{"title":"Maintenance tonight","severity":"warning","dismissible":false}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/announcements", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", "admin-1")
+	c.Set("user_type", "admin")
+
+	if err := h.AdminCreate(c); err != nil {
+		t.Fatalf("AdminCreate() error = %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if gotActor != "admin-1" || gotIn.Title != "Maintenance tonight" || gotIn.Severity != "warning" ||
+		gotIn.Dismissible == nil || *gotIn.Dismissible {
+		t.Errorf("actor = %q, input = %+v", gotActor, gotIn)
+	}
+}
+
+func TestAnnouncement_AdminGet(t *testing.T) {
+	mock := &mockAnnouncementService{
+		getFn: func(_ context.Context, id string) (*announcement.Announcement, error) {
+			return nil, apperror.NotFound("Announcement")
+		},
+	}
+	h := NewAnnouncementHandler(mock, 20, 100)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/announcements/"+testAnnouncementID, nil)
+	c := e.NewContext(req, httptest.NewRecorder())
+	c.SetParamNames("id")
+	c.SetParamValues(testAnnouncementID)
+	c.Set("user_type", "admin")
+
+	if err := h.AdminGet(c); !apperror.Is(err, apperror.CodeNotFound) {
+		t.Errorf("AdminGet() error = %v, want NotFound", err)
+	}
+}
+
+func TestAnnouncement_AdminUpdate(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		check     func(t *testing.T, u announcement.Update)
+		wantField string
+	}{
+		{"title only", `{"title":"Done"}`, func(t *testing.T, u announcement.Update) {
+			if u.Title == nil || *u.Title != "Done" || u.EndsAt != nil || u.ClearEndsAt {
+				t.Errorf("update = %+v", u)
+			}
+		}, ""},
+		{"set end", `{"ends_at":"2030-06-01T00:00:00Z"}`, func(t *testing.T, u announcement.Update) {
+			if u.EndsAt == nil || u.EndsAt.Year() != 2030 || u.ClearEndsAt {
+				t.Errorf("update = %+v", u)
+			}
+		}, ""},
+		{"clear end", `{"ends_at":null}`, func(t *testing.T, u announcement.Update) {
+			if u.EndsAt != nil || !u.ClearEndsAt {
+				t.Errorf("update = %+v", u)
+			}
+		}, ""},
+		{"bad end", `{"ends_at":"tomorrow"}`, nil, "ends_at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got announcement.Update
+			mock := &mockAnnouncementService{
+				updateFn: func(_ context.Context, id string, upd announcement.Update) (*announcement.Announcement, error) {
+					got = upd
+					return &announcement.Announcement{ID: id}, nil
+				},
+			}
+			h := NewAnnouncementHandler(mock, 20, 100)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/admin/announcements/"+testAnnouncementID, strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			c := e.NewContext(req, httptest.NewRecorder())
+			c.SetParamNames("id")
+			c.SetParamValues(testAnnouncementID)
+			c.Set("user_type", "admin")
+
+			err := h.AdminUpdate(c)
+			if tt.wantField != "" {
+				var appErr *apperror.AppError
+				if !errors.As(err, &appErr) || appErr.Details[tt.wantField] == "" {
+					t.Errorf("AdminUpdate() error = %v, want details[%s]", err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("AdminUpdate() error = %v", err)
+			}
+			tt.check(t, got)
+		})
+	}
+}
+
+func TestAnnouncement_AdminDelete(t *testing.T) {
+	var gotID string
+	mock := &mockAnnouncementService{
+		deleteFn: func(_ context.Context, id string) error {
+			gotID = id
+			return nil
+		},
+	}
+	h := NewAnnouncementHandler(mock, 20, 100)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/announcements/"+testAnnouncementID, nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(testAnnouncementID)
+	c.Set("user_type", "admin")
+
+	if err := h.AdminDelete(c); err != nil {
+		t.Fatalf("AdminDelete() error = %v", err)
+	}
+	if rec.Code != http.StatusNoContent || gotID != testAnnouncementID {
+		t.Errorf("status = %d, id = %q", rec.Code, gotID)
+	}
+}
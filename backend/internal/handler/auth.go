@@ -13,6 +13,7 @@ import (
 	"github.com/golid-ai/golid/backend/internal/retry"
 	"github.com/golid-ai/golid/backend/internal/service/auth"
 	"github.com/golid-ai/golid/backend/internal/service/email"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
 )
 
 // AuthHandler handles authentication endpoints.
@@ -41,59 +42,39 @@ type RegisterRequest struct {
 func (h *AuthHandler) Register(c echo.Context) error {
 	var req RegisterRequest
 	if err := c.Bind(&req); err != nil {
-		return apperror.BadRequest("Invalid request body")
+		return apperror.InvalidBody()
 	}
 
 	if req.Email == "" || req.Password == "" {
-		return apperror.Validation("Validation failed", map[string]string{
-			"email":    "Email is required",
-			"password": "Password is required",
-		})
+		return apperror.Validation().
+			WithDetail("email", "error.auth.email_required").
+			WithDetail("password", "error.auth.password_required")
 	}
 
-	result, err := h.authService.Register(c.Request().Context(), &auth.RegisterInput{
+	input := &auth.RegisterInput{
 		Email:     req.Email,
 		Password:  req.Password,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
-	})
-	if err != nil {
-		return err
 	}
-
-	// Send verification email (best-effort, don't fail registration)
-	if result.VerificationToken != "" && h.emailService.IsConfigured() {
-		requestID := c.Response().Header().Get(echo.HeaderXRequestID)
-		email, token := req.Email, result.VerificationToken
-		if h.queue.IsConfigured() {
-			task, err := queue.NewSendVerificationEmail(email, token)
+	// The verification email is written to the outbox with the new user,
+	// so it is sent even if this process stops right after the commit.
+	if h.emailService.IsConfigured() {
+		to := recipientFromRequest(c, req.Email)
+		input.VerificationEmail = func(token string) (outbox.Message, error) {
+			task, err := queue.NewSendVerificationEmail(to, token)
 			if err != nil {
-				logger.Error("failed to create verification email task",
-					slog.String("request_id", requestID),
-					slog.String("error", err.Error()),
-				)
-			} else if err := h.queue.Enqueue(task); err != nil {
-				logger.Error("failed to enqueue verification email",
-					slog.String("request_id", requestID),
-					slog.String("email", email),
-					slog.String("error", err.Error()),
-				)
+				return outbox.Message{}, err
 			}
-		} else {
-			go func() {
-			if err := retry.Retry(h.retryAttempts, h.retryDelay, func() error {
-				return h.emailService.SendVerificationEmail(email, token)
-			}); err != nil {
-					logger.Error("failed to send verification email after retries",
-						slog.String("request_id", requestID),
-						slog.String("email", email),
-						slog.String("error", err.Error()),
-					)
-				}
-			}()
+			return outbox.Task(task), nil
 		}
 	}
 
+	result, err := h.authService.Register(c.Request().Context(), input)
+	if err != nil {
+		return err
+	}
+
 	return c.JSON(http.StatusCreated, result)
 }
 
@@ -107,11 +88,11 @@ type LoginRequest struct {
 func (h *AuthHandler) Login(c echo.Context) error {
 	var req LoginRequest
 	if err := c.Bind(&req); err != nil {
-		return apperror.BadRequest("Invalid request body")
+		return apperror.InvalidBody()
 	}
 
 	if req.Email == "" || req.Password == "" {
-		return apperror.BadRequest("Email and password are required")
+		return apperror.BadRequest("Email and password are required").WithMessageID("error.auth.credentials_required")
 	}
 
 	result, err := h.authService.Login(c.Request().Context(), &auth.LoginInput{
@@ -134,11 +115,11 @@ type RefreshRequest struct {
 func (h *AuthHandler) Refresh(c echo.Context) error {
 	var req RefreshRequest
 	if err := c.Bind(&req); err != nil {
-		return apperror.BadRequest("Invalid request body")
+		return apperror.InvalidBody()
 	}
 
 	if req.RefreshToken == "" {
-		return apperror.BadRequest("Refresh token is required")
+		return apperror.BadRequest("Refresh token is required").WithMessageID("error.auth.refresh_token_required")
 	}
 
 	result, err := h.authService.Refresh(c.Request().Context(), &auth.RefreshInput{
@@ -182,11 +163,11 @@ func (h *AuthHandler) ChangePassword(c echo.Context) error {
 
 	var req ChangePasswordRequest
 	if err := c.Bind(&req); err != nil {
-		return apperror.BadRequest("Invalid request body")
+		return apperror.InvalidBody()
 	}
 
 	if req.CurrentPassword == "" || req.NewPassword == "" {
-		return apperror.BadRequest("Current password and new password are required")
+		return apperror.BadRequest("Current password and new password are required").WithMessageID("error.auth.passwords_required")
 	}
 
 	err = h.authService.ChangePassword(c.Request().Context(), &auth.ChangePasswordInput{
@@ -212,13 +193,11 @@ type ForgotPasswordRequest struct {
 func (h *AuthHandler) ForgotPassword(c echo.Context) error {
 	var req ForgotPasswordRequest
 	if err := c.Bind(&req); err != nil {
-		return apperror.BadRequest("Invalid request body")
+		return apperror.InvalidBody()
 	}
 
 	if req.Email == "" {
-		return apperror.Validation("Validation failed", map[string]string{
-			"email": "Email is required",
-		})
+		return apperror.Validation().WithDetail("email", "error.auth.email_required")
 	}
 
 	// Error logged but we still return 200 to prevent email enumeration
@@ -231,8 +210,9 @@ func (h *AuthHandler) ForgotPassword(c echo.Context) error {
 
 	// Send email if token was generated (user exists)
 	if token != "" && h.emailService.IsConfigured() {
+		to := recipientFromRequest(c, req.Email)
 		if h.queue.IsConfigured() {
-			task, err := queue.NewSendPasswordReset(req.Email, token)
+			task, err := queue.NewSendPasswordReset(to, token)
 			if err != nil {
 				logger.Error("failed to create password reset task",
 					slog.String("email", req.Email),
@@ -247,7 +227,7 @@ func (h *AuthHandler) ForgotPassword(c echo.Context) error {
 		} else {
 			go func() {
 				if err := retry.Retry(h.retryAttempts, h.retryDelay, func() error {
-				return h.emailService.SendPasswordResetEmail(req.Email, token)
+				return h.emailService.SendPasswordResetEmail(to, token)
 				}); err != nil {
 					logger.Error("failed to send password reset email after retries",
 						slog.String("email", req.Email),
@@ -268,7 +248,7 @@ func (h *AuthHandler) ForgotPassword(c echo.Context) error {
 func (h *AuthHandler) VerifyResetToken(c echo.Context) error {
 	token := c.QueryParam("token")
 	if token == "" {
-		return apperror.BadRequest("Token is required")
+		return apperror.BadRequest("Token is required").WithMessageID("error.auth.token_required")
 	}
 
 	result, err := h.authService.VerifyResetToken(c.Request().Context(), &auth.VerifyResetTokenInput{
@@ -291,14 +271,13 @@ type ResetPasswordRequest struct {
 func (h *AuthHandler) ResetPassword(c echo.Context) error {
 	var req ResetPasswordRequest
 	if err := c.Bind(&req); err != nil {
-		return apperror.BadRequest("Invalid request body")
+		return apperror.InvalidBody()
 	}
 
 	if req.Token == "" || req.Password == "" {
-		return apperror.Validation("Validation failed", map[string]string{
-			"token":    "Token is required",
-			"password": "Password is required",
-		})
+		return apperror.Validation().
+			WithDetail("token", "error.auth.token_required").
+			WithDetail("password", "error.auth.password_required")
 	}
 
 	err := h.authService.ResetPassword(c.Request().Context(), &auth.ResetPasswordInput{
@@ -318,7 +297,7 @@ func (h *AuthHandler) ResetPassword(c echo.Context) error {
 func (h *AuthHandler) VerifyEmail(c echo.Context) error {
 	token := c.QueryParam("token")
 	if token == "" {
-		return apperror.BadRequest("Token is required")
+		return apperror.BadRequest("Token is required").WithMessageID("error.auth.token_required")
 	}
 
 	err := h.authService.VerifyEmail(c.Request().Context(), &auth.VerifyEmailInput{
@@ -342,13 +321,11 @@ type ResendVerificationRequest struct {
 func (h *AuthHandler) ResendVerification(c echo.Context) error {
 	var req ResendVerificationRequest
 	if err := c.Bind(&req); err != nil {
-		return apperror.BadRequest("Invalid request body")
+		return apperror.InvalidBody()
 	}
 
 	if req.Email == "" {
-		return apperror.Validation("Validation failed", map[string]string{
-			"email": "Email is required",
-		})
+		return apperror.Validation().WithDetail("email", "error.auth.email_required")
 	}
 
 	// Error logged but we still return 200 to prevent email enumeration
@@ -361,8 +338,9 @@ func (h *AuthHandler) ResendVerification(c echo.Context) error {
 
 	// Send email if token was generated (user exists and not yet verified)
 	if token != "" && h.emailService.IsConfigured() {
+		to := recipientFromRequest(c, req.Email)
 		if h.queue.IsConfigured() {
-			task, err := queue.NewSendVerificationEmail(req.Email, token)
+			task, err := queue.NewSendVerificationEmail(to, token)
 			if err != nil {
 				logger.Error("failed to create verification email task",
 					slog.String("email", req.Email),
@@ -377,7 +355,7 @@ func (h *AuthHandler) ResendVerification(c echo.Context) error {
 		} else {
 			go func() {
 				if err := retry.Retry(h.retryAttempts, h.retryDelay, func() error {
-				return h.emailService.SendVerificationEmail(req.Email, token)
+				return h.emailService.SendVerificationEmail(to, token)
 				}); err != nil {
 					logger.Error("failed to send verification email after retries",
 						slog.String("email", req.Email),
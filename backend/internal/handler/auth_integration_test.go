@@ -40,7 +40,7 @@ func newIntegrationAPI(t *testing.T) (*echo.Echo, func()) {
 	emailSvc := email.NewEmailService(email.EmailConfig{AppName: "golid-test"})
 	jobQueue := queue.New("")
 	authH := NewAuthHandler(authSvc, emailSvc, jobQueue, 3, time.Second)
-	userH := NewUserHandler(user.NewUserService(db.Pool))
+	userH := NewUserHandler(user.NewUserService(db.Pool, 20, 100, time.Minute))
 
 	e := echo.New()
 	e.HTTPErrorHandler = middleware.ErrorHandler
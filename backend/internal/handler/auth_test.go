@@ -6,6 +6,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"strings"
 	"sync/atomic"
 	"testing"
@@ -15,7 +16,10 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/queue"
 	"github.com/golid-ai/golid/backend/internal/service/auth"
+	"github.com/golid-ai/golid/backend/internal/service/email"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
 )
 
 // =============================================================================
@@ -95,25 +99,34 @@ type mockEmailService struct {
 	configured              bool
 	sendVerificationCalled  atomic.Bool
 	sendResetCalled         atomic.Bool
+	sendStatusCalled        atomic.Bool
 	sendVerificationErr     error
 	sendResetErr            error
+	lastRecipient           email.Recipient
 }
 
 func (m *mockEmailService) IsConfigured() bool { return m.configured }
-func (m *mockEmailService) SendVerificationEmail(toEmail, token string) error {
+func (m *mockEmailService) SendVerificationEmail(to email.Recipient, token string) error {
+	m.lastRecipient = to
 	m.sendVerificationCalled.Store(true)
 	if m.sendVerificationErr != nil {
 		return m.sendVerificationErr
 	}
 	return nil
 }
-func (m *mockEmailService) SendPasswordResetEmail(toEmail, token string) error {
+func (m *mockEmailService) SendPasswordResetEmail(to email.Recipient, token string) error {
+	m.lastRecipient = to
 	m.sendResetCalled.Store(true)
 	if m.sendResetErr != nil {
 		return m.sendResetErr
 	}
 	return nil
 }
+func (m *mockEmailService) SendAccountStatusEmail(to email.Recipient, status string, suspendedUntil *time.Time, reason string) error {
+	m.lastRecipient = to
+	m.sendStatusCalled.Store(true)
+	return nil
+}
 
 // =============================================================================
 // MOCK QUEUE
@@ -361,7 +374,7 @@ func TestRegister_Success(t *testing.T) {
 	}
 }
 
-func TestRegister_EnqueuesWhenQueueConfigured(t *testing.T) {
+func TestRegister_LeavesEmailToOutbox(t *testing.T) {
 	result := testAuthResult()
 	result.VerificationToken = "verify-token-123"
 	mock := &mockAuthService{
@@ -369,8 +382,9 @@ func TestRegister_EnqueuesWhenQueueConfigured(t *testing.T) {
 			return result, nil
 		},
 	}
+	emailMock := &mockEmailService{configured: true}
 	q := &mockQueue{configured: true}
-	h := &AuthHandler{authService: mock, emailService: &mockEmailService{configured: true}, queue: q, retryAttempts: 3, retryDelay: time.Second}
+	h := &AuthHandler{authService: mock, emailService: emailMock, queue: q, retryAttempts: 3, retryDelay: time.Second}
 
 	body := `{"email":"new@example.com","password":"password123","first_name":"Jane","last_name":"Doe"}`
 	e := echo.New()
@@ -383,11 +397,12 @@ func TestRegister_EnqueuesWhenQueueConfigured(t *testing.T) {
 		t.Fatalf("Register() error = %v", err)
 	}
 
-	if len(q.enqueuedTasks) != 1 {
-		t.Fatalf("expected 1 enqueued task, got %d", len(q.enqueuedTasks))
+	// The service writes the email to the outbox; the relay delivers it.
+	if len(q.enqueuedTasks) != 0 {
+		t.Errorf("expected no tasks enqueued by the handler, got %v", q.enqueuedTasks)
 	}
-	if q.enqueuedTasks[0] != "email:verification" {
-		t.Errorf("expected task type email:verification, got %s", q.enqueuedTasks[0])
+	if emailMock.sendVerificationCalled.Load() {
+		t.Error("SendVerificationEmail should not be called by the handler")
 	}
 }
 
@@ -543,21 +558,21 @@ func TestChangePassword_NoAuth(t *testing.T) {
 	}
 }
 
-func TestRegister_SendsVerificationEmail(t *testing.T) {
-	emailMock := &mockEmailService{configured: true}
+func TestRegister_BuildsVerificationEmail(t *testing.T) {
+	var input *auth.RegisterInput
 	authMock := &mockAuthService{
-		registerFn: func(ctx context.Context, input *auth.RegisterInput) (*auth.AuthResult, error) {
-			result := testAuthResult()
-			result.VerificationToken = "verify-token-123"
-			return result, nil
+		registerFn: func(ctx context.Context, in *auth.RegisterInput) (*auth.AuthResult, error) {
+			input = in
+			return testAuthResult(), nil
 		},
 	}
-	h := &AuthHandler{authService: authMock, emailService: emailMock, queue: &mockQueue{}, retryAttempts: 3, retryDelay: time.Second}
+	h := &AuthHandler{authService: authMock, emailService: &mockEmailService{configured: true}, queue: &mockQueue{}, retryAttempts: 3, retryDelay: time.Second}
 
 	body := `{"email":"new@example.com","password":"password123","first_name":"Jane","last_name":"Doe"}`
 	e := echo.New()
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/register", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set("Accept-Language", "es-MX,es;q=0.9")
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 
@@ -565,24 +580,28 @@ func TestRegister_SendsVerificationEmail(t *testing.T) {
 		t.Fatalf("Register() error = %v", err)
 	}
 
-	// Give the goroutine time to execute
-	time.Sleep(100 * time.Millisecond)
-
-	if !emailMock.sendVerificationCalled.Load() {
-		t.Error("expected SendVerificationEmail to be called after registration")
+	if input.VerificationEmail == nil {
+		t.Fatal("expected RegisterInput.VerificationEmail to be set")
+	}
+	msg, err := input.VerificationEmail("verify-token-123")
+	if err != nil {
+		t.Fatalf("VerificationEmail() error = %v", err)
+	}
+	task, _ := queue.NewSendVerificationEmail(email.Recipient{Email: "new@example.com", Locale: "es"}, "verify-token-123")
+	if want := outbox.Task(task); !reflect.DeepEqual(msg, want) {
+		t.Errorf("VerificationEmail() = %+v, want %+v", msg, want)
 	}
 }
 
 func TestRegister_SkipsEmailWhenNotConfigured(t *testing.T) {
-	emailMock := &mockEmailService{configured: false}
+	var input *auth.RegisterInput
 	authMock := &mockAuthService{
-		registerFn: func(ctx context.Context, input *auth.RegisterInput) (*auth.AuthResult, error) {
-			result := testAuthResult()
-			result.VerificationToken = "verify-token-123"
-			return result, nil
+		registerFn: func(ctx context.Context, in *auth.RegisterInput) (*auth.AuthResult, error) {
+			input = in
+			return testAuthResult(), nil
 		},
 	}
-	h := &AuthHandler{authService: authMock, emailService: emailMock, queue: &mockQueue{}, retryAttempts: 3, retryDelay: time.Second}
+	h := &AuthHandler{authService: authMock, emailService: &mockEmailService{configured: false}, queue: &mockQueue{}, retryAttempts: 3, retryDelay: time.Second}
 
 	body := `{"email":"new@example.com","password":"password123","first_name":"Jane","last_name":"Doe"}`
 	e := echo.New()
@@ -595,10 +614,8 @@ func TestRegister_SkipsEmailWhenNotConfigured(t *testing.T) {
 		t.Fatalf("Register() error = %v", err)
 	}
 
-	time.Sleep(100 * time.Millisecond)
-
-	if emailMock.sendVerificationCalled.Load() {
-		t.Error("SendVerificationEmail should not be called when email is not configured")
+	if input.VerificationEmail != nil {
+		t.Error("VerificationEmail should not be set when email is not configured")
 	}
 }
 
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/queue"
+	"github.com/golid-ai/golid/backend/internal/service/avatar"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
+)
+
+// multipartOverhead is headroom for multipart boundaries and part headers
+// on top of the avatar size limit.
+const multipartOverhead = 64 << 10
+
+// AvatarHandler handles avatar upload and serving endpoints.
+//
+// Uploads are validated in the request and processed asynchronously: the
+// response returns as soon as the image is staged and its processing task
+// is in the outbox, and users.avatar_url changes once the renditions are
+// published.
+type AvatarHandler struct {
+	avatarService avatarServicer
+	maxUploadSize int64
+}
+
+// NewAvatarHandler creates a new avatar handler.
+func NewAvatarHandler(avatarService *avatar.AvatarService, maxUploadSize int64) *AvatarHandler {
+	return &AvatarHandler{
+		avatarService: avatarService,
+		maxUploadSize: maxUploadSize,
+	}
+}
+
+// AvatarUploadResponse is returned when an avatar has been accepted for
+// processing.
+type AvatarUploadResponse struct {
+	UploadID string `json:"upload_id"`
+	Status   string `json:"status"`
+}
+
+// Upload handles POST /api/v1/me/avatar (multipart/form-data, field "avatar")
+func (h *AvatarHandler) Upload(c echo.Context) error {
+	userID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+
+	req := c.Request()
+	req.Body = http.MaxBytesReader(c.Response(), req.Body, h.maxUploadSize+multipartOverhead)
+	fh, err := c.FormFile("avatar")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return h.tooLarge()
+		}
+		return apperror.Validation().WithDetail("avatar", "error.avatar.required")
+	}
+	if fh.Size > h.maxUploadSize {
+		return h.tooLarge()
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return apperror.BadRequest("Invalid upload").WithMessageID("error.avatar.invalid_upload")
+	}
+	defer func() { _ = f.Close() }()
+	data, err := io.ReadAll(io.LimitReader(f, h.maxUploadSize+1))
+	if err != nil {
+		return apperror.BadRequest("Invalid upload").WithMessageID("error.avatar.invalid_upload")
+	}
+
+	uploadID, err := h.avatarService.Stage(req.Context(), userID, data, func(uploadID string) (outbox.Message, error) {
+		task, err := queue.NewProcessAvatar(userID, uploadID)
+		if err != nil {
+			return outbox.Message{}, err
+		}
+		return outbox.Task(task), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, AvatarUploadResponse{UploadID: uploadID, Status: "processing"})
+}
+
+// Delete handles DELETE /api/v1/me/avatar
+func (h *AvatarHandler) Delete(c echo.Context) error {
+	userID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.avatarService.Remove(c.Request().Context(), userID); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Serve handles GET /api/v1/avatars/:user_id/:upload_id/:file (public).
+// Renditions are immutable: a new upload gets a new upload ID.
+func (h *AvatarHandler) Serve(c echo.Context) error {
+	name, ok := strings.CutSuffix(c.Param("file"), ".png")
+	if !ok {
+		return apperror.NotFound("Avatar")
+	}
+	size, err := strconv.Atoi(name)
+	if err != nil {
+		return apperror.NotFound("Avatar")
+	}
+
+	rc, err := h.avatarService.OpenRendition(c.Request().Context(), c.Param("user_id"), c.Param("upload_id"), size)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+
+	header := c.Response().Header()
+	header.Set("Cache-Control", "public, max-age=31536000, immutable")
+	header.Set("X-Content-Type-Options", "nosniff")
+	return c.Stream(http.StatusOK, "image/png", rc)
+}
+
+func (h *AvatarHandler) tooLarge() error {
+	return apperror.Validation().WithDetail("avatar", "error.avatar.too_large", h.maxUploadSize)
+}
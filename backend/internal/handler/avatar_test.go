@@ -0,0 +1,205 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/queue"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
+)
+
+// =============================================================================
+// MOCK AVATAR SERVICE
+// =============================================================================
+
+type mockAvatarService struct {
+	stageFn         func(ctx context.Context, userID string, data []byte, process func(string) (outbox.Message, error)) (string, error)
+	removeFn        func(ctx context.Context, userID string) error
+	openRenditionFn func(ctx context.Context, userID, uploadID string, size int) (io.ReadCloser, error)
+}
+
+func (m *mockAvatarService) Stage(ctx context.Context, userID string, data []byte, process func(string) (outbox.Message, error)) (string, error) {
+	if m.stageFn != nil {
+		return m.stageFn(ctx, userID, data, process)
+	}
+	panic("unexpected Stage")
+}
+func (m *mockAvatarService) Remove(ctx context.Context, userID string) error {
+	if m.removeFn != nil {
+		return m.removeFn(ctx, userID)
+	}
+	panic("unexpected Remove")
+}
+func (m *mockAvatarService) OpenRendition(ctx context.Context, userID, uploadID string, size int) (io.ReadCloser, error) {
+	if m.openRenditionFn != nil {
+		return m.openRenditionFn(ctx, userID, uploadID, size)
+	}
+	panic("unexpected OpenRendition")
+}
+
+const testUploadID = "01900000-0000-7000-8000-000000000000"
+
+func avatarUploadContext(t *testing.T, field string, data []byte) (echo.Context, *httptest.ResponseRecorder) {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile(field, "me.jpg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = part.Write(data)
+	_ = w.Close()
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/me/avatar", &body)
+	req.Header.Set(echo.HeaderContentType, w.FormDataContentType())
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", "user-123")
+	return c, rec
+}
+
+func TestAvatarUpload_StagesWithProcessingTask(t *testing.T) {
+	var staged []byte
+	var msg outbox.Message
+	h := &AvatarHandler{
+		avatarService: &mockAvatarService{
+			stageFn: func(_ context.Context, userID string, data []byte, process func(string) (outbox.Message, error)) (string, error) {
+				if userID != "user-123" {
+					t.Errorf("userID = %q", userID)
+				}
+				staged = data
+				var err error
+				msg, err = process(testUploadID)
+				return testUploadID, err
+			},
+		},
+		maxUploadSize: 1 << 20,
+	}
+
+	c, rec := avatarUploadContext(t, "avatar", []byte("image-bytes"))
+	if err := h.Upload(c); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("status = %d, want 202", rec.Code)
+	}
+	if string(staged) != "image-bytes" {
+		t.Errorf("staged = %q", staged)
+	}
+	task, _ := queue.NewProcessAvatar("user-123", testUploadID)
+	if want := outbox.Task(task); !reflect.DeepEqual(msg, want) {
+		t.Errorf("processing task = %+v, want %+v", msg, want)
+	}
+	if !strings.Contains(rec.Body.String(), testUploadID) {
+		t.Errorf("body = %s, want upload_id", rec.Body.String())
+	}
+}
+
+func TestAvatarUpload_MissingFile(t *testing.T) {
+	h := &AvatarHandler{avatarService: &mockAvatarService{}, maxUploadSize: 1 << 20}
+	c, _ := avatarUploadContext(t, "picture", []byte("image-bytes"))
+	if err := h.Upload(c); !apperror.Is(err, apperror.CodeValidation) {
+		t.Errorf("err = %v, want Validation", err)
+	}
+}
+
+func TestAvatarUpload_TooLarge(t *testing.T) {
+	h := &AvatarHandler{avatarService: &mockAvatarService{}, maxUploadSize: 16}
+	c, _ := avatarUploadContext(t, "avatar", bytes.Repeat([]byte("x"), 17))
+	if err := h.Upload(c); !apperror.Is(err, apperror.CodeValidation) {
+		t.Errorf("err = %v, want Validation", err)
+	}
+
+	// Far beyond the multipart headroom: rejected while reading the body.
+	c, _ = avatarUploadContext(t, "avatar", bytes.Repeat([]byte("x"), 16+multipartOverhead+1))
+	if err := h.Upload(c); !apperror.Is(err, apperror.CodeValidation) {
+		t.Errorf("err = %v, want Validation", err)
+	}
+}
+
+func TestAvatarUpload_RequiresAuth(t *testing.T) {
+	h := &AvatarHandler{avatarService: &mockAvatarService{}, maxUploadSize: 1 << 20}
+	c, _ := avatarUploadContext(t, "avatar", []byte("image-bytes"))
+	c.Set("user_id", nil)
+	if err := h.Upload(c); !apperror.Is(err, apperror.CodeUnauthorized) {
+		t.Errorf("err = %v, want Unauthorized", err)
+	}
+}
+
+func TestAvatarDelete(t *testing.T) {
+	var removed string
+	h := &AvatarHandler{avatarService: &mockAvatarService{
+		removeFn: func(_ context.Context, userID string) error {
+			removed = userID
+			return nil
+		},
+	}}
+
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodDelete, "/api/v1/me/avatar", nil), rec)
+	c.Set("user_id", "user-123")
+	if err := h.Delete(c); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if rec.Code != http.StatusNoContent || removed != "user-123" {
+		t.Errorf("status = %d, removed = %q", rec.Code, removed)
+	}
+}
+
+func avatarServeContext(file string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/avatars/x", nil), rec)
+	c.SetParamNames("user_id", "upload_id", "file")
+	c.SetParamValues("user-123", testUploadID, file)
+	return c, rec
+}
+
+func TestAvatarServe_StreamsImmutablePNG(t *testing.T) {
+	var gotSize int
+	h := &AvatarHandler{avatarService: &mockAvatarService{
+		openRenditionFn: func(_ context.Context, _, _ string, size int) (io.ReadCloser, error) {
+			gotSize = size
+			return io.NopCloser(strings.NewReader("png-bytes")), nil
+		},
+	}}
+
+	c, rec := avatarServeContext("128.png")
+	if err := h.Serve(c); err != nil {
+		t.Fatalf("Serve() error = %v", err)
+	}
+	if gotSize != 128 {
+		t.Errorf("size = %d, want 128", gotSize)
+	}
+	if ct := rec.Header().Get(echo.HeaderContentType); ct != "image/png" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cc := rec.Header().Get("Cache-Control"); !strings.Contains(cc, "immutable") {
+		t.Errorf("Cache-Control = %q, want immutable", cc)
+	}
+	if rec.Body.String() != "png-bytes" {
+		t.Errorf("body = %q", rec.Body.String())
+	}
+}
+
+func TestAvatarServe_RejectsBadFilename(t *testing.T) {
+	h := &AvatarHandler{avatarService: &mockAvatarService{}}
+	for _, name := range []string{"128.jpg", "source", "abc.png", ".png"} {
+		c, _ := avatarServeContext(name)
+		if err := h.Serve(c); !apperror.Is(err, apperror.CodeNotFound) {
+			t.Errorf("Serve(%q) err = %v, want NotFound", name, err)
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/pow"
+)
+
+// ChallengeHandler issues proof-of-work challenges for the adaptive auth
+// guard (middleware.ProofOfWork).
+type ChallengeHandler struct {
+	issuer challengeIssuer
+}
+
+// NewChallengeHandler creates a new challenge handler.
+func NewChallengeHandler(issuer *pow.Issuer) *ChallengeHandler {
+	return &ChallengeHandler{issuer: issuer}
+}
+
+// Challenge handles GET /api/v1/auth/challenge
+// Public. Stateless — nothing is stored until a solution is spent.
+func (h *ChallengeHandler) Challenge(c echo.Context) error {
+	ch, err := h.issuer.Issue()
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("issue pow challenge: %w", err))
+	}
+	c.Response().Header().Set("Cache-Control", "no-store")
+	return c.JSON(http.StatusOK, ch)
+}
@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/pow"
+)
+
+type mockChallengeIssuer struct {
+	err error
+}
+
+func (m *mockChallengeIssuer) Issue() (*pow.Challenge, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &pow.Challenge{Challenge: "tok", Difficulty: 18, Algorithm: pow.Algorithm, ExpiresAt: time.Now()}, nil
+}
+
+func TestChallenge_Success(t *testing.T) {
+	h := NewChallengeHandler(pow.NewIssuer("handler-test-secret-at-least-32-chars", 8, time.Minute))
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/challenge", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := h.Challenge(c); err != nil {
+		t.Fatalf("Challenge() error = %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+	if got := rec.Header().Get("Cache-Control"); got != "no-store" {
+		t.Errorf("Cache-Control = %q, want no-store", got)
+	}
+
+	var ch pow.Challenge
+	if err := json.Unmarshal(rec.Body.Bytes(), &ch); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if ch.Challenge == "" || ch.Difficulty != 8 || ch.Algorithm != "sha256" {
+		t.Errorf("unexpected challenge: %+v", ch)
+	}
+}
+
+func TestChallenge_IssueError(t *testing.T) {
+	h := &ChallengeHandler{issuer: &mockChallengeIssuer{err: errors.New("entropy exhausted")}}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/challenge", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	err := h.Challenge(c)
+	if !apperror.Is(err, apperror.CodeInternal) {
+		t.Errorf("expected CodeInternal, got %v", err)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/middleware"
+	"github.com/golid-ai/golid/backend/internal/service/email"
 )
 
 // contextString safely extracts a string value from the echo context.
@@ -20,7 +22,7 @@ func contextString(c echo.Context, key string) (string, bool) {
 func requireUserID(c echo.Context) (string, error) {
 	id, ok := contextString(c, "user_id")
 	if !ok || id == "" {
-		return "", apperror.Unauthorized("missing user identity")
+		return "", apperror.Unauthorized("")
 	}
 	return id, nil
 }
@@ -29,7 +31,14 @@ func requireUserID(c echo.Context) (string, error) {
 func requireUserType(c echo.Context) (string, error) {
 	t, ok := contextString(c, "user_type")
 	if !ok || t == "" {
-		return "", apperror.Unauthorized("missing user type")
+		return "", apperror.Unauthorized("")
 	}
 	return t, nil
 }
+
+// recipientFromRequest addresses an email to whoever made an anonymous
+// request (registration, password reset), in the request's locale. No time
+// zone is known for them, so dates render in UTC.
+func recipientFromRequest(c echo.Context, toEmail string) email.Recipient {
+	return email.Recipient{Email: toEmail, Locale: middleware.RequestLocale(c)}
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/queue"
+	"github.com/golid-ai/golid/backend/internal/service/export"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
+)
+
+// ExportHandler handles personal data export endpoints.
+//
+// Exports are built asynchronously, by a task written to the outbox with
+// the request. The user is notified by SSE and email when the archive is
+// ready; GET /me/exports/:id reports status for
+// clients that were not connected. The archive itself is served from a
+// signed URL so the emailed link works without a session.
+type ExportHandler struct {
+	exportService exportServicer
+}
+
+// NewExportHandler creates a new export handler.
+func NewExportHandler(exportService *export.ExportService) *ExportHandler {
+	return &ExportHandler{exportService: exportService}
+}
+
+// ExportResponse is an export's status plus a signed download URL once
+// the archive is ready.
+type ExportResponse struct {
+	*export.Export
+	DownloadURL *export.SignedURL `json:"download_url,omitempty"`
+}
+
+// Request handles POST /api/v1/me/export
+func (h *ExportHandler) Request(c echo.Context) error {
+	userID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+
+	e, err := h.exportService.Request(c.Request().Context(), userID, func(exportID string) (outbox.Message, error) {
+		task, err := queue.NewBuildDataExport(exportID)
+		if err != nil {
+			return outbox.Message{}, err
+		}
+		return outbox.Task(task), nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusAccepted, ExportResponse{Export: e})
+}
+
+// Get handles GET /api/v1/me/exports/:id
+func (h *ExportHandler) Get(c echo.Context) error {
+	userID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+
+	e, err := h.exportService.Get(c.Request().Context(), c.Param("id"), userID)
+	if err != nil {
+		return err
+	}
+
+	resp := ExportResponse{Export: e}
+	if e.Status == export.StatusReady {
+		if resp.DownloadURL, err = h.exportService.DownloadURL(e); err != nil {
+			return err
+		}
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Download handles GET /api/v1/exports/:id/download (signed URL)
+func (h *ExportHandler) Download(c echo.Context) error {
+	id := c.Param("id")
+	expires, signature := c.QueryParam("expires"), c.QueryParam("signature")
+	if expires == "" || signature == "" {
+		return apperror.Forbidden("Invalid or expired link").WithMessageID("error.link_expired")
+	}
+	if err := h.exportService.VerifyURL(id, expires, signature); err != nil {
+		return err
+	}
+
+	e, rc, err := h.exportService.Open(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+
+	header := c.Response().Header()
+	filename := "data-export-" + e.CreatedAt.UTC().Format(time.DateOnly) + ".zip"
+	header.Set(echo.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+	header.Set("Cache-Control", "private, no-store")
+	header.Set("X-Content-Type-Options", "nosniff")
+	if e.SizeBytes != nil {
+		header.Set(echo.HeaderContentLength, strconv.FormatInt(*e.SizeBytes, 10))
+	}
+	return c.Stream(http.StatusOK, "application/zip", rc)
+}
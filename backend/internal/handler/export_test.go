@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/queue"
+	"github.com/golid-ai/golid/backend/internal/service/export"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
+)
+
+// =============================================================================
+// MOCK EXPORT SERVICE
+// =============================================================================
+
+type mockExportService struct {
+	requestFn     func(ctx context.Context, userID string, build func(string) (outbox.Message, error)) (*export.Export, error)
+	getFn         func(ctx context.Context, exportID, userID string) (*export.Export, error)
+	downloadURLFn func(e *export.Export) (*export.SignedURL, error)
+	verifyURLFn   func(exportID, expires, signature string) error
+	openFn        func(ctx context.Context, exportID string) (*export.Export, io.ReadCloser, error)
+}
+
+func (m *mockExportService) Request(ctx context.Context, userID string, build func(string) (outbox.Message, error)) (*export.Export, error) {
+	if m.requestFn != nil {
+		return m.requestFn(ctx, userID, build)
+	}
+	panic("unexpected Request")
+}
+func (m *mockExportService) Get(ctx context.Context, exportID, userID string) (*export.Export, error) {
+	if m.getFn != nil {
+		return m.getFn(ctx, exportID, userID)
+	}
+	panic("unexpected Get")
+}
+func (m *mockExportService) DownloadURL(e *export.Export) (*export.SignedURL, error) {
+	if m.downloadURLFn != nil {
+		return m.downloadURLFn(e)
+	}
+	panic("unexpected DownloadURL")
+}
+func (m *mockExportService) VerifyURL(exportID, expires, signature string) error {
+	if m.verifyURLFn != nil {
+		return m.verifyURLFn(exportID, expires, signature)
+	}
+	panic("unexpected VerifyURL")
+}
+func (m *mockExportService) Open(ctx context.Context, exportID string) (*export.Export, io.ReadCloser, error) {
+	if m.openFn != nil {
+		return m.openFn(ctx, exportID)
+	}
+	panic("unexpected Open")
+}
+
+const testExportID = "22222222-2222-2222-2222-222222222222"
+
+func exportContext(method, target string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(method, target, nil), rec)
+	c.Set("user_id", "user-123")
+	return c, rec
+}
+
+func pendingExport() (*export.Export, error) {
+	return &export.Export{ID: testExportID, UserID: "user-123", Status: export.StatusPending}, nil
+}
+
+func TestExportRequest_WritesBuildTask(t *testing.T) {
+	var msg outbox.Message
+	h := &ExportHandler{
+		exportService: &mockExportService{
+			requestFn: func(_ context.Context, userID string, build func(string) (outbox.Message, error)) (*export.Export, error) {
+				if userID != "user-123" {
+					t.Errorf("userID = %q", userID)
+				}
+				var err error
+				if msg, err = build(testExportID); err != nil {
+					return nil, err
+				}
+				return pendingExport()
+			},
+		},
+	}
+
+	c, rec := exportContext(http.MethodPost, "/api/v1/me/export")
+	if err := h.Request(c); err != nil {
+		t.Fatalf("Request() error = %v", err)
+	}
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("status = %d, want 202", rec.Code)
+	}
+	task, _ := queue.NewBuildDataExport(testExportID)
+	if want := outbox.Task(task); !reflect.DeepEqual(msg, want) {
+		t.Errorf("build task = %+v, want %+v", msg, want)
+	}
+	if strings.Contains(rec.Body.String(), "download_url") {
+		t.Errorf("pending export should not include download_url: %s", rec.Body.String())
+	}
+}
+
+func TestExportRequest_Conflict(t *testing.T) {
+	h := &ExportHandler{
+		exportService: &mockExportService{
+			requestFn: func(context.Context, string, func(string) (outbox.Message, error)) (*export.Export, error) {
+				return nil, apperror.Conflict("An export is already being prepared")
+			},
+		},
+	}
+	c, _ := exportContext(http.MethodPost, "/api/v1/me/export")
+	if err := h.Request(c); !apperror.Is(err, apperror.CodeConflict) {
+		t.Errorf("err = %v, want Conflict", err)
+	}
+}
+
+func TestExportGet_ReadyIncludesDownloadURL(t *testing.T) {
+	h := &ExportHandler{exportService: &mockExportService{
+		getFn: func(_ context.Context, exportID, userID string) (*export.Export, error) {
+			if exportID != testExportID || userID != "user-123" {
+				t.Errorf("Get(%q, %q)", exportID, userID)
+			}
+			return &export.Export{ID: testExportID, Status: export.StatusReady}, nil
+		},
+		downloadURLFn: func(*export.Export) (*export.SignedURL, error) {
+			return &export.SignedURL{URL: "https://app.example.com/signed"}, nil
+		},
+	}}
+
+	c, rec := exportContext(http.MethodGet, "/api/v1/me/exports/"+testExportID)
+	c.SetParamNames("id")
+	c.SetParamValues(testExportID)
+	if err := h.Get(c); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !strings.Contains(rec.Body.String(), "https://app.example.com/signed") {
+		t.Errorf("body = %s, want download_url", rec.Body.String())
+	}
+}
+
+func TestExportDownload_StreamsArchive(t *testing.T) {
+	size := int64(len("zip-bytes"))
+	h := &ExportHandler{exportService: &mockExportService{
+		verifyURLFn: func(exportID, expires, signature string) error {
+			if exportID != testExportID || expires != "123" || signature != "sig" {
+				t.Errorf("VerifyURL(%q, %q, %q)", exportID, expires, signature)
+			}
+			return nil
+		},
+		openFn: func(context.Context, string) (*export.Export, io.ReadCloser, error) {
+			e := &export.Export{ID: testExportID, CreatedAt: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), SizeBytes: &size}
+			return e, io.NopCloser(strings.NewReader("zip-bytes")), nil
+		},
+	}}
+
+	c, rec := exportContext(http.MethodGet, "/api/v1/exports/"+testExportID+"/download?expires=123&signature=sig")
+	c.SetParamNames("id")
+	c.SetParamValues(testExportID)
+	if err := h.Download(c); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if ct := rec.Header().Get(echo.HeaderContentType); ct != "application/zip" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if cd := rec.Header().Get(echo.HeaderContentDisposition); !strings.Contains(cd, "data-export-2026-03-01.zip") {
+		t.Errorf("Content-Disposition = %q", cd)
+	}
+	if rec.Body.String() != "zip-bytes" {
+		t.Errorf("body = %q", rec.Body.String())
+	}
+}
+
+func TestExportDownload_RequiresSignature(t *testing.T) {
+	h := &ExportHandler{exportService: &mockExportService{}}
+	c, _ := exportContext(http.MethodGet, "/api/v1/exports/"+testExportID+"/download")
+	c.SetParamNames("id")
+	c.SetParamValues(testExportID)
+	if err := h.Download(c); !apperror.Is(err, apperror.CodeForbidden) {
+		t.Errorf("err = %v, want Forbidden", err)
+	}
+}
@@ -1,31 +1,39 @@
 package handler
 
 import (
+	"encoding/json"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/service/feature"
+	"github.com/golid-ai/golid/backend/internal/validate"
 )
 
 // FeatureHandler handles feature flag endpoints.
 type FeatureHandler struct {
-	featureService featureServicer
+	featureService    featureServicer
+	paginationDefault int
+	paginationMax     int
 }
 
-func NewFeatureHandler(fs featureServicer) *FeatureHandler {
-	return &FeatureHandler{featureService: fs}
+func NewFeatureHandler(fs featureServicer, paginationDefault, paginationMax int) *FeatureHandler {
+	return &FeatureHandler{
+		featureService:    fs,
+		paginationDefault: paginationDefault,
+		paginationMax:     paginationMax,
+	}
 }
 
 // List returns all feature flags with descriptions. Admin-only.
 func (h *FeatureHandler) List(c echo.Context) error {
-	userType, err := requireUserType(c)
-	if err != nil {
+	if err := requireAdmin(c); err != nil {
 		return err
 	}
-	if userType != "admin" {
-		return apperror.Forbidden("Admin access required")
-	}
 	flags, err := h.featureService.List(c.Request().Context())
 	if err != nil {
 		return err
@@ -33,8 +41,13 @@ func (h *FeatureHandler) List(c echo.Context) error {
 	return c.JSON(http.StatusOK, flags)
 }
 
-// ListEnabled returns a {key: bool} map. Public endpoint — no descriptions exposed.
+// ListEnabled returns a {key: bool} map, or with ?format=variants a
+// {key: {enabled, variant, value}} map. Public endpoint — no descriptions
+// exposed.
 func (h *FeatureHandler) ListEnabled(c echo.Context) error {
+	if wantVariants(c) {
+		return h.listEvaluated(c, feature.Subject{})
+	}
 	flags, err := h.featureService.ListEnabled(c.Request().Context())
 	if err != nil {
 		return err
@@ -42,29 +55,406 @@ func (h *FeatureHandler) ListEnabled(c echo.Context) error {
 	return c.JSON(http.StatusOK, flags)
 }
 
+// ListForUser returns a {key: bool} map evaluated for the authenticated
+// user, applying targeting rules.
+func (h *FeatureHandler) ListForUser(c echo.Context) error {
+	userID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+	userType, err := requireUserType(c)
+	if err != nil {
+		return err
+	}
+	subject := feature.Subject{UserID: userID, UserType: userType}
+	if wantVariants(c) {
+		return h.listEvaluated(c, subject)
+	}
+	flags, err := h.featureService.ListFor(c.Request().Context(), subject)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, flags)
+}
+
+func (h *FeatureHandler) listEvaluated(c echo.Context, subject feature.Subject) error {
+	flags, err := h.featureService.EvaluateAll(c.Request().Context(), subject)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, flags)
+}
+
+// wantVariants reports whether the caller asked for evaluated variants
+// and values instead of plain booleans.
+func wantVariants(c echo.Context) bool {
+	return c.QueryParam("format") == "variants"
+}
+
+const (
+	staleDaysDefault = 30
+	staleDaysMax     = 3650
+)
+
+// CreateFeatureRequest adds a flag. Reason is required and recorded in the
+// flag's history.
+type CreateFeatureRequest struct {
+	feature.NewFlag
+	Reason string `json:"reason"`
+}
+
+// Create handles POST /api/v1/admin/features. Fails with 409 if the key is
+// taken. Admin-only.
+func (h *FeatureHandler) Create(c echo.Context) error {
+	if err := requireAdmin(c); err != nil {
+		return err
+	}
+	var req CreateFeatureRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.InvalidBody()
+	}
+	change, err := flagChange(c, req.Reason)
+	if err != nil {
+		return err
+	}
+	flag, err := h.featureService.Create(c.Request().Context(), req.NewFlag, change)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, flag)
+}
+
+// Get handles GET /api/v1/admin/features/:key. Admin-only.
+func (h *FeatureHandler) Get(c echo.Context) error {
+	key, err := adminFlagKey(c)
+	if err != nil {
+		return err
+	}
+	flag, err := h.featureService.Get(c.Request().Context(), key)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, flag)
+}
+
+// UpdateFeatureRequest changes a flag's description, owner, or expiry.
+// Omitted fields are left alone; a null expires_at clears the expiry.
+type UpdateFeatureRequest struct {
+	Description *string         `json:"description"`
+	Owner       *string         `json:"owner"`
+	ExpiresAt   json.RawMessage `json:"expires_at"`
+}
+
+// Update handles PATCH /api/v1/admin/features/:key. Admin-only.
+func (h *FeatureHandler) Update(c echo.Context) error {
+	key, err := adminFlagKey(c)
+	if err != nil {
+		return err
+	}
+	adminID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+	var req UpdateFeatureRequest
+	if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+		return apperror.InvalidBody()
+	}
+	update := feature.FlagUpdate{Description: req.Description, Owner: req.Owner}
+	switch {
+	case req.ExpiresAt == nil:
+	case string(req.ExpiresAt) == "null":
+		update.ClearExpiresAt = true
+	default:
+		var t time.Time
+		if err := json.Unmarshal(req.ExpiresAt, &t); err != nil {
+			return apperror.Validation().WithDetail("expires_at", "error.timestamp_or_null")
+		}
+		update.ExpiresAt = &t
+	}
+	flag, err := h.featureService.Update(c.Request().Context(), key, update, adminID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, flag)
+}
+
+// DeleteFeatureRequest removes a flag. Reason is required and recorded in
+// the flag's history.
+type DeleteFeatureRequest struct {
+	Reason string `json:"reason"`
+}
+
+// Delete handles DELETE /api/v1/admin/features/:key. Pending schedules for
+// the flag are cancelled. Admin-only.
+func (h *FeatureHandler) Delete(c echo.Context) error {
+	key, err := adminFlagKey(c)
+	if err != nil {
+		return err
+	}
+	var req DeleteFeatureRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.InvalidBody()
+	}
+	change, err := flagChange(c, req.Reason)
+	if err != nil {
+		return err
+	}
+	if err := h.featureService.Delete(c.Request().Context(), key, change); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// Stale handles GET /api/v1/admin/feature-reports/stale.
+//
+// Query params: days (default 30) — flags not evaluated for this many days
+// are reported alongside expired ones. Admin-only.
+func (h *FeatureHandler) Stale(c echo.Context) error {
+	if err := requireAdmin(c); err != nil {
+		return err
+	}
+	days := staleDaysDefault
+	if v := c.QueryParam("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > staleDaysMax {
+			return apperror.Validation().WithDetail("days", "error.feature.stale_days", staleDaysMax)
+		}
+		days = n
+	}
+	flags, err := h.featureService.Stale(c.Request().Context(), time.Duration(days)*24*time.Hour)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, flags)
+}
+
+// SetFeatureRequest toggles a flag. Reason is required and recorded in
+// the flag's history.
 type SetFeatureRequest struct {
-	Enabled bool `json:"enabled"`
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason"`
 }
 
 // Set toggles a feature flag. Admin-only.
 func (h *FeatureHandler) Set(c echo.Context) error {
+	key, err := adminFlagKey(c)
+	if err != nil {
+		return err
+	}
+	var req SetFeatureRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.InvalidBody()
+	}
+	change, err := flagChange(c, req.Reason)
+	if err != nil {
+		return err
+	}
+	if err := h.featureService.Set(c.Request().Context(), key, req.Enabled, change); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Feature flag updated"})
+}
+
+// SetFeatureRulesRequest replaces a flag's targeting rules; null rules
+// remove them.
+type SetFeatureRulesRequest struct {
+	Rules  *feature.Rules `json:"rules"`
+	Reason string         `json:"reason"`
+}
+
+// SetRules replaces a flag's targeting rules. Admin-only.
+func (h *FeatureHandler) SetRules(c echo.Context) error {
+	key, err := adminFlagKey(c)
+	if err != nil {
+		return err
+	}
+	var req SetFeatureRulesRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.InvalidBody()
+	}
+	change, err := flagChange(c, req.Reason)
+	if err != nil {
+		return err
+	}
+	if err := h.featureService.SetRules(c.Request().Context(), key, req.Rules, change); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Feature flag rules updated"})
+}
+
+// SetFeatureValuesRequest replaces a flag's type, value, variants, and
+// schema.
+type SetFeatureValuesRequest struct {
+	feature.Values
+	Reason string `json:"reason"`
+}
+
+// SetValues replaces what a flag serves. Admin-only.
+func (h *FeatureHandler) SetValues(c echo.Context) error {
+	key, err := adminFlagKey(c)
+	if err != nil {
+		return err
+	}
+	var req SetFeatureValuesRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.InvalidBody()
+	}
+	change, err := flagChange(c, req.Reason)
+	if err != nil {
+		return err
+	}
+	if err := h.featureService.SetValues(c.Request().Context(), key, req.Values, change); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Feature flag values updated"})
+}
+
+// History returns a flag's recorded changes, newest first. Admin-only.
+func (h *FeatureHandler) History(c echo.Context) error {
+	key, err := adminFlagKey(c)
+	if err != nil {
+		return err
+	}
+	page, perPage := ParsePagination(c, h.paginationDefault, h.paginationMax)
+	result, err := h.featureService.History(c.Request().Context(), key, page, perPage)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// RollbackFeatureRequest restores the state a flag had just before EventID.
+type RollbackFeatureRequest struct {
+	EventID string `json:"event_id"`
+	Reason  string `json:"reason"`
+}
+
+// Rollback undoes a recorded change. Admin-only.
+func (h *FeatureHandler) Rollback(c echo.Context) error {
+	key, err := adminFlagKey(c)
+	if err != nil {
+		return err
+	}
+	var req RollbackFeatureRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.InvalidBody()
+	}
+	if err := validate.UUID(req.EventID, "event_id"); err != nil {
+		return err
+	}
+	change, err := flagChange(c, req.Reason)
+	if err != nil {
+		return err
+	}
+	if err := h.featureService.Rollback(c.Request().Context(), key, req.EventID, change); err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Feature flag rolled back"})
+}
+
+// ScheduleFeatureRequest queues a flag change for RunAt.
+type ScheduleFeatureRequest struct {
+	feature.ScheduleRequest
+	Reason string `json:"reason"`
+}
+
+// Schedule handles POST /api/v1/admin/feature-schedules. Admin-only.
+func (h *FeatureHandler) Schedule(c echo.Context) error {
+	if err := requireAdmin(c); err != nil {
+		return err
+	}
+	var req ScheduleFeatureRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.InvalidBody()
+	}
+	change, err := flagChange(c, req.Reason)
+	if err != nil {
+		return err
+	}
+	schedule, err := h.featureService.ScheduleChange(c.Request().Context(), req.ScheduleRequest, change)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, schedule)
+}
+
+// ListSchedules handles GET /api/v1/admin/feature-schedules.
+//
+// Query params: key, status (default pending; "all" for every status),
+// page, per_page. Admin-only.
+func (h *FeatureHandler) ListSchedules(c echo.Context) error {
+	if err := requireAdmin(c); err != nil {
+		return err
+	}
+	status := c.QueryParam("status")
+	switch status {
+	case "":
+		status = feature.SchedulePending
+	case "all":
+		status = ""
+	}
+	page, perPage := ParsePagination(c, h.paginationDefault, h.paginationMax)
+	result, err := h.featureService.ListSchedules(c.Request().Context(), c.QueryParam("key"), status, page, perPage)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// CancelSchedule handles DELETE /api/v1/admin/feature-schedules/:id.
+// Only pending schedules can be cancelled. Admin-only.
+func (h *FeatureHandler) CancelSchedule(c echo.Context) error {
+	if err := requireAdmin(c); err != nil {
+		return err
+	}
+	adminID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+	id := c.Param("id")
+	if err := validate.UUID(id, "id"); err != nil {
+		return err
+	}
+	if err := h.featureService.CancelSchedule(c.Request().Context(), id, adminID); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// requireAdmin rejects callers that are not admins.
+func requireAdmin(c echo.Context) error {
 	userType, err := requireUserType(c)
 	if err != nil {
 		return err
 	}
 	if userType != "admin" {
-		return apperror.Forbidden("Admin access required")
+		return apperror.Forbidden("Admin access required").WithMessageID("error.admin_required")
+	}
+	return nil
+}
+
+// adminFlagKey checks that the caller is an admin and returns the :key
+// path parameter.
+func adminFlagKey(c echo.Context) (string, error) {
+	if err := requireAdmin(c); err != nil {
+		return "", err
 	}
 	key := c.Param("key")
 	if key == "" {
-		return apperror.BadRequest("Feature flag key is required")
-	}
-	var req SetFeatureRequest
-	if err := c.Bind(&req); err != nil {
-		return apperror.BadRequest("Invalid request body")
+		return "", apperror.BadRequest("Feature flag key is required").WithMessageID("error.feature.key_required")
 	}
-	if err := h.featureService.Set(c.Request().Context(), key, req.Enabled); err != nil {
-		return err
+	return key, nil
+}
+
+// flagChange attributes a flag write to the calling admin and request.
+func flagChange(c echo.Context, reason string) (feature.Change, error) {
+	actorID, err := requireUserID(c)
+	if err != nil {
+		return feature.Change{}, err
 	}
-	return c.JSON(http.StatusOK, map[string]string{"message": "Feature flag updated"})
+	return feature.Change{
+		ActorID:   actorID,
+		Reason:    strings.TrimSpace(reason),
+		RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+	}, nil
 }
@@ -2,21 +2,40 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 
+	"github.com/golid-ai/golid/backend/internal/apperror"
 	"github.com/golid-ai/golid/backend/internal/service/feature"
 )
 
 type mockFeatureService struct {
-	flags   []feature.FeatureFlag
-	enabled map[string]bool
-	setKey  string
-	setVal  bool
+	flags       []feature.FeatureFlag
+	enabled     map[string]bool
+	setKey      string
+	setVal      bool
+	subject     feature.Subject
+	setRules    *feature.Rules
+	change      feature.Change
+	setValues   feature.Values
+	evaluated   map[string]feature.Evaluation
+	historyFn   func(ctx context.Context, key string, page, perPage int) (*feature.HistoryResult, error)
+	rollbackFn  func(ctx context.Context, key, eventID string, change feature.Change) error
+	scheduleFn  func(ctx context.Context, req feature.ScheduleRequest, change feature.Change) (*feature.Schedule, error)
+	listSchedFn func(ctx context.Context, key, status string, page, perPage int) (*feature.ScheduleList, error)
+	cancelFn    func(ctx context.Context, id, actorID string) error
+	getFn       func(ctx context.Context, key string) (*feature.FeatureFlag, error)
+	createFn    func(ctx context.Context, flag feature.NewFlag, change feature.Change) (*feature.FeatureFlag, error)
+	updateFn    func(ctx context.Context, key string, update feature.FlagUpdate, actorID string) (*feature.FeatureFlag, error)
+	deleteFn    func(ctx context.Context, key string, change feature.Change) error
+	staleFn     func(ctx context.Context, unusedFor time.Duration) ([]feature.StaleFlag, error)
 }
 
 func (m *mockFeatureService) List(ctx context.Context) ([]feature.FeatureFlag, error) {
@@ -27,19 +46,114 @@ func (m *mockFeatureService) ListEnabled(ctx context.Context) (map[string]bool,
 	return m.enabled, nil
 }
 
-func (m *mockFeatureService) Set(ctx context.Context, key string, enabled bool) error {
+func (m *mockFeatureService) Set(ctx context.Context, key string, enabled bool, change feature.Change) error {
 	m.setKey = key
 	m.setVal = enabled
+	m.change = change
 	return nil
 }
 
+func (m *mockFeatureService) ListFor(ctx context.Context, subject feature.Subject) (map[string]bool, error) {
+	m.subject = subject
+	return m.enabled, nil
+}
+
+func (m *mockFeatureService) EvaluateAll(ctx context.Context, subject feature.Subject) (map[string]feature.Evaluation, error) {
+	m.subject = subject
+	return m.evaluated, nil
+}
+
+func (m *mockFeatureService) SetValues(ctx context.Context, key string, values feature.Values, change feature.Change) error {
+	m.setKey = key
+	m.setValues = values
+	m.change = change
+	return nil
+}
+
+func (m *mockFeatureService) SetRules(ctx context.Context, key string, rules *feature.Rules, change feature.Change) error {
+	m.setKey = key
+	m.setRules = rules
+	m.change = change
+	return nil
+}
+
+func (m *mockFeatureService) History(ctx context.Context, key string, page, perPage int) (*feature.HistoryResult, error) {
+	if m.historyFn != nil {
+		return m.historyFn(ctx, key, page, perPage)
+	}
+	panic("unexpected History")
+}
+
+func (m *mockFeatureService) Rollback(ctx context.Context, key, eventID string, change feature.Change) error {
+	if m.rollbackFn != nil {
+		return m.rollbackFn(ctx, key, eventID, change)
+	}
+	panic("unexpected Rollback")
+}
+
+func (m *mockFeatureService) ScheduleChange(ctx context.Context, req feature.ScheduleRequest, change feature.Change) (*feature.Schedule, error) {
+	if m.scheduleFn != nil {
+		return m.scheduleFn(ctx, req, change)
+	}
+	panic("unexpected ScheduleChange")
+}
+
+func (m *mockFeatureService) ListSchedules(ctx context.Context, key, status string, page, perPage int) (*feature.ScheduleList, error) {
+	if m.listSchedFn != nil {
+		return m.listSchedFn(ctx, key, status, page, perPage)
+	}
+	panic("unexpected ListSchedules")
+}
+
+func (m *mockFeatureService) CancelSchedule(ctx context.Context, id, actorID string) error {
+	if m.cancelFn != nil {
+		return m.cancelFn(ctx, id, actorID)
+	}
+	panic("unexpected CancelSchedule")
+}
+
+func (m *mockFeatureService) Get(ctx context.Context, key string) (*feature.FeatureFlag, error) {
+	if m.getFn != nil {
+		return m.getFn(ctx, key)
+	}
+	panic("unexpected Get")
+}
+
+func (m *mockFeatureService) Create(ctx context.Context, flag feature.NewFlag, change feature.Change) (*feature.FeatureFlag, error) {
+	if m.createFn != nil {
+		return m.createFn(ctx, flag, change)
+	}
+	panic("unexpected Create")
+}
+
+func (m *mockFeatureService) Update(ctx context.Context, key string, update feature.FlagUpdate, actorID string) (*feature.FeatureFlag, error) {
+	if m.updateFn != nil {
+		return m.updateFn(ctx, key, update, actorID)
+	}
+	panic("unexpected Update")
+}
+
+func (m *mockFeatureService) Delete(ctx context.Context, key string, change feature.Change) error {
+	if m.deleteFn != nil {
+		return m.deleteFn(ctx, key, change)
+	}
+	panic("unexpected Delete")
+}
+
+func (m *mockFeatureService) Stale(ctx context.Context, unusedFor time.Duration) ([]feature.StaleFlag, error) {
+	if m.staleFn != nil {
+		return m.staleFn(ctx, unusedFor)
+	}
+	panic("unexpected Stale")
+}
+
 func TestFeature_List_Admin(t *testing.T) {
 	mock := &mockFeatureService{
 		flags: []feature.FeatureFlag{
 			{Key: "test_flag", Enabled: true, Description: "A test flag"},
 		},
 	}
-	h := NewFeatureHandler(mock)
+	h := NewFeatureHandler(mock, 20, 100)
 
 	e := echo.New()
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/features", nil)
@@ -56,7 +170,7 @@ func TestFeature_List_Admin(t *testing.T) {
 }
 
 func TestFeature_List_NonAdmin(t *testing.T) {
-	h := NewFeatureHandler(&mockFeatureService{})
+	h := NewFeatureHandler(&mockFeatureService{}, 20, 100)
 
 	e := echo.New()
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/features", nil)
@@ -74,7 +188,7 @@ func TestFeature_ListEnabled_Public(t *testing.T) {
 	mock := &mockFeatureService{
 		enabled: map[string]bool{"maintenance_mode": false, "new_dashboard": true},
 	}
-	h := NewFeatureHandler(mock)
+	h := NewFeatureHandler(mock, 20, 100)
 
 	e := echo.New()
 	req := httptest.NewRequest(http.MethodGet, "/api/v1/features", nil)
@@ -89,18 +203,44 @@ func TestFeature_ListEnabled_Public(t *testing.T) {
 	}
 }
 
+func TestFeature_ListEnabled_Variants(t *testing.T) {
+	mock := &mockFeatureService{
+		evaluated: map[string]feature.Evaluation{
+			"checkout_copy": {Enabled: true, Value: json.RawMessage(`"Buy now"`)},
+		},
+	}
+	h := NewFeatureHandler(mock, 20, 100)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/features?format=variants", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	if err := h.ListEnabled(c); err != nil {
+		t.Fatalf("ListEnabled() error = %v", err)
+	}
+	if mock.subject != (feature.Subject{}) {
+		t.Errorf("subject = %+v, want anonymous", mock.subject)
+	}
+	if !strings.Contains(rec.Body.String(), `"checkout_copy":{"enabled":true,"value":"Buy now"}`) {
+		t.Errorf("body = %s", rec.Body.String())
+	}
+}
+
 func TestFeature_Set_Admin(t *testing.T) {
 	mock := &mockFeatureService{}
-	h := NewFeatureHandler(mock)
+	h := NewFeatureHandler(mock, 20, 100)
 
 	e := echo.New()
-	body := `{"enabled": true}`
+	body := `{"enabled": true, "reason": "  launch  "}`
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/features/test_flag", strings.NewReader(body))
 	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
 	rec := httptest.NewRecorder()
+	rec.Header().Set(echo.HeaderXRequestID, "req-1")
 	c := e.NewContext(req, rec)
 	c.SetParamNames("key")
 	c.SetParamValues("test_flag")
+	c.Set("user_id", "admin-1")
 	c.Set("user_type", "admin")
 
 	if err := h.Set(c); err != nil {
@@ -112,10 +252,13 @@ func TestFeature_Set_Admin(t *testing.T) {
 	if !mock.setVal {
 		t.Error("expected enabled = true")
 	}
+	if mock.change != (feature.Change{ActorID: "admin-1", Reason: "launch", RequestID: "req-1"}) {
+		t.Errorf("change = %+v", mock.change)
+	}
 }
 
 func TestFeature_Set_MissingKey(t *testing.T) {
-	h := NewFeatureHandler(&mockFeatureService{})
+	h := NewFeatureHandler(&mockFeatureService{}, 20, 100)
 
 	e := echo.New()
 	body := `{"enabled": true}`
@@ -134,7 +277,7 @@ func TestFeature_Set_MissingKey(t *testing.T) {
 }
 
 func TestFeature_Set_InvalidJSON(t *testing.T) {
-	h := NewFeatureHandler(&mockFeatureService{})
+	h := NewFeatureHandler(&mockFeatureService{}, 20, 100)
 
 	e := echo.New()
 	req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/features/flag", strings.NewReader("not json"))
@@ -152,7 +295,7 @@ func TestFeature_Set_InvalidJSON(t *testing.T) {
 }
 
 func TestFeature_Set_NonAdmin(t *testing.T) {
-	h := NewFeatureHandler(&mockFeatureService{})
+	h := NewFeatureHandler(&mockFeatureService{}, 20, 100)
 
 	e := echo.New()
 	body := `{"enabled": true}`
@@ -169,3 +312,549 @@ func TestFeature_Set_NonAdmin(t *testing.T) {
 		t.Error("expected error for non-admin")
 	}
 }
+
+func TestFeature_ListForUser(t *testing.T) {
+	mock := &mockFeatureService{enabled: map[string]bool{"canary": true}}
+	h := NewFeatureHandler(mock, 20, 100)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/me/features", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", "user-1")
+	c.Set("user_type", "user")
+
+	if err := h.ListForUser(c); err != nil {
+		t.Fatalf("ListForUser() error = %v", err)
+	}
+	if mock.subject != (feature.Subject{UserID: "user-1", UserType: "user"}) {
+		t.Errorf("subject = %+v", mock.subject)
+	}
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"canary":true`) {
+		t.Errorf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestFeature_ListForUser_Variants(t *testing.T) {
+	mock := &mockFeatureService{
+		evaluated: map[string]feature.Evaluation{
+			"checkout_copy": {Enabled: true, Variant: "b", Value: json.RawMessage(`"Get it"`)},
+		},
+	}
+	h := NewFeatureHandler(mock, 20, 100)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/me/features?format=variants", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", "user-1")
+	c.Set("user_type", "user")
+
+	if err := h.ListForUser(c); err != nil {
+		t.Fatalf("ListForUser() error = %v", err)
+	}
+	if mock.subject != (feature.Subject{UserID: "user-1", UserType: "user"}) {
+		t.Errorf("subject = %+v", mock.subject)
+	}
+	if !strings.Contains(rec.Body.String(), `"variant":"b"`) {
+		t.Errorf("body = %s", rec.Body.String())
+	}
+}
+
+func TestFeature_SetValues(t *testing.T) {
+	mock := &mockFeatureService{}
+	h := NewFeatureHandler(mock, 20, 100)
+
+	e := echo.New()
+	body := `{"type":"string","value":"Buy now","variants":[{"key":"a","value":"Buy now","weight":50},{"key":"b","value":"Get it","weight":50}],"reason":"copy test"}`
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/features/checkout_copy/values", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("key")
+	c.SetParamValues("checkout_copy")
+	c.Set("user_id", "admin-1")
+	c.Set("user_type", "admin")
+
+	if err := h.SetValues(c); err != nil {
+		t.Fatalf("SetValues() error = %v", err)
+	}
+	if mock.setKey != "checkout_copy" || mock.setValues.Type != feature.TypeString || len(mock.setValues.Variants) != 2 {
+		t.Errorf("SetValues(%q, %+v)", mock.setKey, mock.setValues)
+	}
+	if mock.change.Reason != "copy test" {
+		t.Errorf("change = %+v", mock.change)
+	}
+}
+
+func TestFeature_SetRules(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		wantNil bool
+		wantErr bool
+	}{
+		{"rules", `{"rules":{"user_types":["admin"],"percentage":5},"reason":"canary"}`, false, false},
+		{"null clears", `{"rules":null,"reason":"full launch"}`, true, false},
+		{"invalid JSON", `not json`, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockFeatureService{}
+			h := NewFeatureHandler(mock, 20, 100)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/features/canary/rules", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("key")
+			c.SetParamValues("canary")
+			c.Set("user_id", "admin-1")
+			c.Set("user_type", "admin")
+
+			err := h.SetRules(c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SetRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if mock.setKey != "canary" || (mock.setRules == nil) != tt.wantNil || mock.change.Reason == "" {
+				t.Errorf("SetRules(%q, %+v, %+v)", mock.setKey, mock.setRules, mock.change)
+			}
+			if !tt.wantNil && (mock.setRules.Percentage == nil || *mock.setRules.Percentage != 5) {
+				t.Errorf("percentage = %v, want 5", mock.setRules.Percentage)
+			}
+		})
+	}
+}
+
+func TestFeature_SetRules_NonAdmin(t *testing.T) {
+	h := NewFeatureHandler(&mockFeatureService{}, 20, 100)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/admin/features/canary/rules", strings.NewReader(`null`))
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("key")
+	c.SetParamValues("canary")
+	c.Set("user_type", "user")
+
+	if err := h.SetRules(c); err == nil {
+		t.Error("expected error for non-admin")
+	}
+}
+
+func TestFeature_History(t *testing.T) {
+	var gotKey string
+	var gotPage, gotPerPage int
+	mock := &mockFeatureService{
+		historyFn: func(_ context.Context, key string, page, perPage int) (*feature.HistoryResult, error) {
+			gotKey, gotPage, gotPerPage = key, page, perPage
+			return &feature.HistoryResult{Events: []feature.Event{{ID: "e1", FlagKey: key, Action: "set"}}, Total: 1, Page: page, PerPage: perPage, TotalPages: 1}, nil
+		},
+	}
+	h := NewFeatureHandler(mock, 20, 100)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/features/canary/history?page=2&per_page=500", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("key")
+	c.SetParamValues("canary")
+	c.Set("user_type", "admin")
+
+	if err := h.History(c); err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	if gotKey != "canary" || gotPage != 2 || gotPerPage != 20 {
+		t.Errorf("History(%q, %d, %d)", gotKey, gotPage, gotPerPage)
+	}
+	if !strings.Contains(rec.Body.String(), `"flag_key":"canary"`) {
+		t.Errorf("body = %s", rec.Body.String())
+	}
+}
+
+func TestFeature_Rollback(t *testing.T) {
+	const eventID = "11111111-1111-1111-1111-111111111111"
+	tests := []struct {
+		name     string
+		body     string
+		wantCode apperror.Code
+	}{
+		{"ok", `{"event_id":"` + eventID + `","reason":"bad rollout"}`, ""},
+		{"invalid event id", `{"event_id":"nope","reason":"bad rollout"}`, apperror.CodeBadRequest},
+		{"invalid JSON", `not json`, apperror.CodeBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var called bool
+			mock := &mockFeatureService{
+				rollbackFn: func(_ context.Context, key, id string, change feature.Change) error {
+					called = true
+					if key != "canary" || id != eventID || change.ActorID != "admin-1" || change.Reason != "bad rollout" {
+						t.Errorf("Rollback(%q, %q, %+v)", key, id, change)
+					}
+					return nil
+				},
+			}
+			h := NewFeatureHandler(mock, 20, 100)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/features/canary/rollback", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("key")
+			c.SetParamValues("canary")
+			c.Set("user_id", "admin-1")
+			c.Set("user_type", "admin")
+
+			err := h.Rollback(c)
+			if tt.wantCode == "" {
+				if err != nil || !called {
+					t.Fatalf("Rollback() error = %v, called = %v", err, called)
+				}
+				return
+			}
+			if !apperror.Is(err, tt.wantCode) {
+				t.Errorf("err = %v, want %s", err, tt.wantCode)
+			}
+			if called {
+				t.Error("service should not be called")
+			}
+		})
+	}
+}
+
+func TestFeature_Schedule(t *testing.T) {
+	var gotReq feature.ScheduleRequest
+	var gotChange feature.Change
+	mock := &mockFeatureService{
+		scheduleFn: func(_ context.Context, req feature.ScheduleRequest, change feature.Change) (*feature.Schedule, error) {
+			gotReq, gotChange = req, change
+			return &feature.Schedule{ID: "s1", FlagKey: req.Key, Action: req.Action, Status: feature.SchedulePending}, nil
+		},
+	}
+	h := NewFeatureHandler(mock, 20, 100)
+
+	e := echo.New()
+	body := `{"key":"launch","action":"enable","run_at":"2030-01-01T09:00:00Z","revert_at":"2030-01-02T09:00:00Z","reason":"launch day"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/feature-schedules", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", "admin-1")
+	c.Set("user_type", "admin")
+
+	if err := h.Schedule(c); err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusCreated)
+	}
+	if gotReq.Key != "launch" || gotReq.Action != feature.ScheduleEnable || gotReq.RevertAt == nil || gotReq.RunAt.Year() != 2030 {
+		t.Errorf("req = %+v", gotReq)
+	}
+	if gotChange.ActorID != "admin-1" || gotChange.Reason != "launch day" {
+		t.Errorf("change = %+v", gotChange)
+	}
+}
+
+func TestFeature_Schedule_NonAdmin(t *testing.T) {
+	h := NewFeatureHandler(&mockFeatureService{}, 20, 100)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/feature-schedules", strings.NewReader(`{}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", "user-1")
+	c.Set("user_type", "user")
+
+	if err := h.Schedule(c); !apperror.Is(err, apperror.CodeForbidden) {
+		t.Errorf("err = %v, want Forbidden", err)
+	}
+}
+
+func TestFeature_ListSchedules(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantStatus string
+	}{
+		{"", feature.SchedulePending},
+		{"?status=all", ""},
+		{"?status=failed&key=launch", feature.ScheduleFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var gotStatus string
+			var gotKey string
+			mock := &mockFeatureService{
+				listSchedFn: func(_ context.Context, key, status string, page, perPage int) (*feature.ScheduleList, error) {
+					gotKey, gotStatus = key, status
+					return &feature.ScheduleList{Schedules: []feature.Schedule{}, Page: page, PerPage: perPage}, nil
+				},
+			}
+			h := NewFeatureHandler(mock, 20, 100)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/feature-schedules"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user_type", "admin")
+
+			if err := h.ListSchedules(c); err != nil {
+				t.Fatalf("ListSchedules() error = %v", err)
+			}
+			if gotStatus != tt.wantStatus {
+				t.Errorf("status = %q, want %q", gotStatus, tt.wantStatus)
+			}
+			if strings.Contains(tt.query, "key=launch") != (gotKey == "launch") {
+				t.Errorf("key = %q", gotKey)
+			}
+		})
+	}
+}
+
+func TestFeature_CancelSchedule(t *testing.T) {
+	const id = "22222222-2222-2222-2222-222222222222"
+	var gotID, gotActor string
+	mock := &mockFeatureService{
+		cancelFn: func(_ context.Context, id, actorID string) error {
+			gotID, gotActor = id, actorID
+			return nil
+		},
+	}
+	h := NewFeatureHandler(mock, 20, 100)
+
+	e := echo.New()
+	newContext := func(id string) (echo.Context, *httptest.ResponseRecorder) {
+		req := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/feature-schedules/"+id, nil)
+		rec := httptest.NewRecorder()
+		c := e.NewContext(req, rec)
+		c.SetParamNames("id")
+		c.SetParamValues(id)
+		c.Set("user_id", "admin-1")
+		c.Set("user_type", "admin")
+		return c, rec
+	}
+
+	c, rec := newContext(id)
+	if err := h.CancelSchedule(c); err != nil {
+		t.Fatalf("CancelSchedule() error = %v", err)
+	}
+	if rec.Code != http.StatusNoContent || gotID != id || gotActor != "admin-1" {
+		t.Errorf("status = %d, id = %q, actor = %q", rec.Code, gotID, gotActor)
+	}
+
+	c, _ = newContext("not-a-uuid")
+	if err := h.CancelSchedule(c); !apperror.Is(err, apperror.CodeBadRequest) {
+		t.Errorf("CancelSchedule(invalid id) error = %v, want BadRequest", err)
+	}
+}
+
+func TestFeature_Create(t *testing.T) {
+	var got feature.NewFlag
+	var gotChange feature.Change
+	mock := &mockFeatureService{
+		createFn: func(_ context.Context, flag feature.NewFlag, change feature.Change) (*feature.FeatureFlag, error) {
+			got, gotChange = flag, change
+			return &feature.FeatureFlag{Key: flag.Key, Owner: flag.Owner}, nil
+		},
+	}
+	h := NewFeatureHandler(mock, 20, 100)
+
+	e := echo.New()
+	body := `{"key":"new_checkout","description":"New checkout","owner":"payments","expires_at":"2030-01-01T00:00:00Z","reason":"launch prep"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/features", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", "admin-1")
+	c.Set("user_type", "admin")
+
+	if err := h.Create(c); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Errorf("status = %d, want 201", rec.Code)
+	}
+	if got.Key != "new_checkout" || got.Owner != "payments" || got.ExpiresAt == nil || got.Enabled {
+		t.Errorf("flag = %+v", got)
+	}
+	if gotChange.Reason != "launch prep" || gotChange.ActorID != "admin-1" {
+		t.Errorf("change = %+v", gotChange)
+	}
+}
+
+func TestFeature_Get(t *testing.T) {
+	mock := &mockFeatureService{
+		getFn: func(_ context.Context, key string) (*feature.FeatureFlag, error) {
+			if key != "dark_mode" {
+				return nil, apperror.NotFound("Feature flag")
+			}
+			return &feature.FeatureFlag{Key: key, Evaluations: 42}, nil
+		},
+	}
+	h := NewFeatureHandler(mock, 20, 100)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/features/dark_mode", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("key")
+	c.SetParamValues("dark_mode")
+	c.Set("user_type", "admin")
+
+	if err := h.Get(c); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	var flag feature.FeatureFlag
+	if err := json.Unmarshal(rec.Body.Bytes(), &flag); err != nil {
+		t.Fatal(err)
+	}
+	if flag.Key != "dark_mode" || flag.Evaluations != 42 {
+		t.Errorf("flag = %+v", flag)
+	}
+}
+
+func TestFeature_Update(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		check     func(t *testing.T, u feature.FlagUpdate)
+		wantField string
+	}{
+		{"owner only", `{"owner":"growth"}`, func(t *testing.T, u feature.FlagUpdate) {
+			if u.Owner == nil || *u.Owner != "growth" || u.Description != nil || u.ExpiresAt != nil || u.ClearExpiresAt {
+				t.Errorf("update = %+v", u)
+			}
+		}, ""},
+		{"set expiry", `{"expires_at":"2030-06-01T00:00:00Z"}`, func(t *testing.T, u feature.FlagUpdate) {
+			if u.ExpiresAt == nil || u.ExpiresAt.Year() != 2030 || u.ClearExpiresAt {
+				t.Errorf("update = %+v", u)
+			}
+		}, ""},
+		{"clear expiry", `{"expires_at":null}`, func(t *testing.T, u feature.FlagUpdate) {
+			if u.ExpiresAt != nil || !u.ClearExpiresAt {
+				t.Errorf("update = %+v", u)
+			}
+		}, ""},
+		{"bad expiry", `{"expires_at":"next week"}`, nil, "expires_at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got feature.FlagUpdate
+			var gotActor string
+			mock := &mockFeatureService{
+				updateFn: func(_ context.Context, key string, update feature.FlagUpdate, actorID string) (*feature.FeatureFlag, error) {
+					got, gotActor = update, actorID
+					return &feature.FeatureFlag{Key: key}, nil
+				},
+			}
+			h := NewFeatureHandler(mock, 20, 100)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPatch, "/api/v1/admin/features/dark_mode", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("key")
+			c.SetParamValues("dark_mode")
+			c.Set("user_id", "admin-1")
+			c.Set("user_type", "admin")
+
+			err := h.Update(c)
+			if tt.wantField != "" {
+				var appErr *apperror.AppError
+				if !errors.As(err, &appErr) || appErr.Details[tt.wantField] == "" {
+					t.Errorf("Update() error = %v, want details[%s]", err, tt.wantField)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			if gotActor != "admin-1" {
+				t.Errorf("actor = %q", gotActor)
+			}
+			tt.check(t, got)
+		})
+	}
+}
+
+func TestFeature_Delete(t *testing.T) {
+	var gotKey string
+	var gotChange feature.Change
+	mock := &mockFeatureService{
+		deleteFn: func(_ context.Context, key string, change feature.Change) error {
+			gotKey, gotChange = key, change
+			return nil
+		},
+	}
+	h := NewFeatureHandler(mock, 20, 100)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodDelete, "/api/v1/admin/features/old_banner", strings.NewReader(`{"reason":"cleanup"}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("key")
+	c.SetParamValues("old_banner")
+	c.Set("user_id", "admin-1")
+	c.Set("user_type", "admin")
+
+	if err := h.Delete(c); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if rec.Code != http.StatusNoContent || gotKey != "old_banner" || gotChange.Reason != "cleanup" {
+		t.Errorf("status = %d, key = %q, change = %+v", rec.Code, gotKey, gotChange)
+	}
+}
+
+func TestFeature_Stale(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 30 * 24 * time.Hour, false},
+		{"?days=7", 7 * 24 * time.Hour, false},
+		{"?days=0", 0, true},
+		{"?days=abc", 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			var got time.Duration
+			mock := &mockFeatureService{
+				staleFn: func(_ context.Context, unusedFor time.Duration) ([]feature.StaleFlag, error) {
+					got = unusedFor
+					return []feature.StaleFlag{}, nil
+				},
+			}
+			h := NewFeatureHandler(mock, 20, 100)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/feature-reports/stale"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.Set("user_type", "admin")
+
+			err := h.Stale(c)
+			if tt.wantErr {
+				if !apperror.Is(err, apperror.CodeValidation) {
+					t.Errorf("Stale() error = %v, want Validation", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Stale() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("unusedFor = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
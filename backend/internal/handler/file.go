@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/service/file"
+	"github.com/golid-ai/golid/backend/internal/storage"
+)
+
+// FileHandler handles file upload and download endpoints.
+//
+// Metadata endpoints are JWT-protected. Content endpoints are public but
+// require a signed URL (expires + signature query params) issued by the
+// metadata endpoints, so browsers can PUT/GET directly without headers.
+type FileHandler struct {
+	fileService fileServicer
+}
+
+// NewFileHandler creates a new file handler.
+func NewFileHandler(fileService *file.FileService) *FileHandler {
+	return &FileHandler{fileService: fileService}
+}
+
+// CreateUploadRequest is the request body for POST /api/v1/files.
+type CreateUploadRequest struct {
+	Filename string `json:"filename"`
+}
+
+// FileResponse is a file's metadata plus a signed download URL once the
+// content has been uploaded.
+type FileResponse struct {
+	*file.File
+	DownloadURL *file.SignedURL `json:"download_url,omitempty"`
+}
+
+// CreateUpload handles POST /api/v1/files
+func (h *FileHandler) CreateUpload(c echo.Context) error {
+	userID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req CreateUploadRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.InvalidBody()
+	}
+
+	ticket, err := h.fileService.CreateUpload(c.Request().Context(), userID, req.Filename)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, ticket)
+}
+
+// Upload handles PUT /api/v1/files/:id/content (signed URL)
+func (h *FileHandler) Upload(c echo.Context) error {
+	id := c.Param("id")
+	if err := h.verifySignature(c, storage.OpUpload, id); err != nil {
+		return err
+	}
+
+	f, err := h.fileService.Upload(c.Request().Context(), id, c.Request().Body)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, f)
+}
+
+// Get handles GET /api/v1/files/:id
+func (h *FileHandler) Get(c echo.Context) error {
+	userID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+	userType, _ := contextString(c, "user_type")
+
+	f, err := h.fileService.GetOwned(c.Request().Context(), c.Param("id"), userID, userType == "admin")
+	if err != nil {
+		return err
+	}
+
+	resp := FileResponse{File: f}
+	if f.Status == file.StatusUploaded {
+		if resp.DownloadURL, err = h.fileService.DownloadURL(f); err != nil {
+			return err
+		}
+	}
+	return c.JSON(http.StatusOK, resp)
+}
+
+// Download handles GET /api/v1/files/:id/content (signed URL)
+func (h *FileHandler) Download(c echo.Context) error {
+	id := c.Param("id")
+	if err := h.verifySignature(c, storage.OpDownload, id); err != nil {
+		return err
+	}
+
+	f, rc, err := h.fileService.Open(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rc.Close() }()
+
+	etag := `"` + derefStr(f.ChecksumSHA256) + `"`
+	header := c.Response().Header()
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", "private, max-age=0, must-revalidate")
+	if match := c.Request().Header.Get("If-None-Match"); match == etag {
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	contentType := derefStr(f.ContentType)
+	header.Set(echo.HeaderContentDisposition, contentDisposition(contentType, f.Filename))
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Content-Security-Policy", "sandbox")
+	if f.SizeBytes != nil {
+		header.Set(echo.HeaderContentLength, strconv.FormatInt(*f.SizeBytes, 10))
+	}
+	return c.Stream(http.StatusOK, contentType, rc)
+}
+
+// Delete handles DELETE /api/v1/files/:id
+func (h *FileHandler) Delete(c echo.Context) error {
+	userID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+	userType, _ := contextString(c, "user_type")
+
+	if err := h.fileService.Delete(c.Request().Context(), c.Param("id"), userID, userType == "admin"); err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+func (h *FileHandler) verifySignature(c echo.Context, op, id string) error {
+	expires, signature := c.QueryParam("expires"), c.QueryParam("signature")
+	if expires == "" || signature == "" {
+		return apperror.Forbidden("Invalid or expired link").WithMessageID("error.link_expired")
+	}
+	return h.fileService.VerifyURL(op, id, expires, signature)
+}
+
+// contentDisposition renders images inline and forces everything else to
+// download. Sniffing never yields image/svg+xml, so inline images cannot
+// carry script.
+func contentDisposition(contentType, filename string) string {
+	disposition := "attachment"
+	if strings.HasPrefix(contentType, "image/") {
+		disposition = "inline"
+	}
+	if v := mime.FormatMediaType(disposition, map[string]string{"filename": filename}); v != "" {
+		return v
+	}
+	return disposition + `; filename="download"`
+}
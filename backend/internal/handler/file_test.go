@@ -0,0 +1,287 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/service/file"
+	"github.com/golid-ai/golid/backend/internal/storage"
+)
+
+// =============================================================================
+// MOCK FILE SERVICE
+// =============================================================================
+
+type mockFileService struct {
+	createUploadFn func(ctx context.Context, ownerID, filename string) (*file.UploadTicket, error)
+	uploadFn       func(ctx context.Context, fileID string, body io.Reader) (*file.File, error)
+	getOwnedFn     func(ctx context.Context, fileID, userID string, isAdmin bool) (*file.File, error)
+	openFn         func(ctx context.Context, fileID string) (*file.File, io.ReadCloser, error)
+	deleteFn       func(ctx context.Context, fileID, userID string, isAdmin bool) error
+	verifyErr      error
+	verifiedOp     string
+}
+
+func (m *mockFileService) CreateUpload(ctx context.Context, ownerID, filename string) (*file.UploadTicket, error) {
+	if m.createUploadFn != nil {
+		return m.createUploadFn(ctx, ownerID, filename)
+	}
+	panic("unexpected CreateUpload")
+}
+func (m *mockFileService) Upload(ctx context.Context, fileID string, body io.Reader) (*file.File, error) {
+	if m.uploadFn != nil {
+		return m.uploadFn(ctx, fileID, body)
+	}
+	panic("unexpected Upload")
+}
+func (m *mockFileService) GetOwned(ctx context.Context, fileID, userID string, isAdmin bool) (*file.File, error) {
+	if m.getOwnedFn != nil {
+		return m.getOwnedFn(ctx, fileID, userID, isAdmin)
+	}
+	panic("unexpected GetOwned")
+}
+func (m *mockFileService) DownloadURL(f *file.File) (*file.SignedURL, error) {
+	return &file.SignedURL{URL: "/api/v1/files/" + f.ID + "/content?expires=1&signature=ab"}, nil
+}
+func (m *mockFileService) Open(ctx context.Context, fileID string) (*file.File, io.ReadCloser, error) {
+	if m.openFn != nil {
+		return m.openFn(ctx, fileID)
+	}
+	panic("unexpected Open")
+}
+func (m *mockFileService) Delete(ctx context.Context, fileID, userID string, isAdmin bool) error {
+	if m.deleteFn != nil {
+		return m.deleteFn(ctx, fileID, userID, isAdmin)
+	}
+	panic("unexpected Delete")
+}
+func (m *mockFileService) VerifyURL(op, _, _, _ string) error {
+	m.verifiedOp = op
+	return m.verifyErr
+}
+
+const testFileID = "22222222-2222-2222-2222-222222222222"
+
+func uploadedTestFile(contentType string) *file.File {
+	size := int64(5)
+	sum := "abc123"
+	now := time.Now()
+	return &file.File{
+		ID:             testFileID,
+		OwnerID:        "user-123",
+		Filename:       "report final.pdf",
+		ContentType:    &contentType,
+		SizeBytes:      &size,
+		ChecksumSHA256: &sum,
+		Status:         file.StatusUploaded,
+		CreatedAt:      now,
+		UploadedAt:     &now,
+	}
+}
+
+func fileContext(method, target, body string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if method == http.MethodPost {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(testFileID)
+	return c, rec
+}
+
+func TestFileCreateUpload_Success(t *testing.T) {
+	var gotOwner, gotName string
+	h := &FileHandler{fileService: &mockFileService{
+		createUploadFn: func(_ context.Context, ownerID, filename string) (*file.UploadTicket, error) {
+			gotOwner, gotName = ownerID, filename
+			return &file.UploadTicket{File: &file.File{ID: testFileID, Status: file.StatusPending}, UploadURL: "/upload"}, nil
+		},
+	}}
+
+	c, rec := fileContext(http.MethodPost, "/api/v1/files", `{"filename":"a.png"}`)
+	c.Set("user_id", "user-123")
+	if err := h.CreateUpload(c); err != nil {
+		t.Fatalf("CreateUpload() error = %v", err)
+	}
+	if rec.Code != http.StatusCreated {
+		t.Errorf("status = %d, want 201", rec.Code)
+	}
+	if gotOwner != "user-123" || gotName != "a.png" {
+		t.Errorf("CreateUpload(%q, %q)", gotOwner, gotName)
+	}
+}
+
+func TestFileCreateUpload_RequiresAuth(t *testing.T) {
+	h := &FileHandler{fileService: &mockFileService{}}
+	c, _ := fileContext(http.MethodPost, "/api/v1/files", `{"filename":"a.png"}`)
+	if err := h.CreateUpload(c); !apperror.Is(err, apperror.CodeUnauthorized) {
+		t.Errorf("err = %v, want Unauthorized", err)
+	}
+}
+
+func TestFileUpload_RequiresSignature(t *testing.T) {
+	h := &FileHandler{fileService: &mockFileService{}}
+	c, _ := fileContext(http.MethodPut, "/api/v1/files/"+testFileID+"/content", "data")
+	if err := h.Upload(c); !apperror.Is(err, apperror.CodeForbidden) {
+		t.Errorf("err = %v, want Forbidden", err)
+	}
+}
+
+func TestFileUpload_VerifiesUploadOperation(t *testing.T) {
+	svc := &mockFileService{
+		uploadFn: func(_ context.Context, _ string, body io.Reader) (*file.File, error) {
+			data, _ := io.ReadAll(body)
+			if string(data) != "hello" {
+				t.Errorf("body = %q", data)
+			}
+			return uploadedTestFile("text/plain"), nil
+		},
+	}
+	h := &FileHandler{fileService: svc}
+
+	c, rec := fileContext(http.MethodPut, "/api/v1/files/"+testFileID+"/content?expires=1&signature=ab", "hello")
+	if err := h.Upload(c); err != nil {
+		t.Fatalf("Upload() error = %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+	if svc.verifiedOp != storage.OpUpload {
+		t.Errorf("verified op = %q, want %q", svc.verifiedOp, storage.OpUpload)
+	}
+}
+
+func TestFileUpload_BadSignature(t *testing.T) {
+	h := &FileHandler{fileService: &mockFileService{verifyErr: apperror.Forbidden("Invalid or expired link")}}
+	c, _ := fileContext(http.MethodPut, "/api/v1/files/"+testFileID+"/content?expires=1&signature=ab", "hello")
+	if err := h.Upload(c); !apperror.Is(err, apperror.CodeForbidden) {
+		t.Errorf("err = %v, want Forbidden", err)
+	}
+}
+
+func TestFileGet_IncludesDownloadURL(t *testing.T) {
+	var gotAdmin bool
+	h := &FileHandler{fileService: &mockFileService{
+		getOwnedFn: func(_ context.Context, _, _ string, isAdmin bool) (*file.File, error) {
+			gotAdmin = isAdmin
+			return uploadedTestFile("application/pdf"), nil
+		},
+	}}
+
+	c, rec := fileContext(http.MethodGet, "/api/v1/files/"+testFileID, "")
+	c.Set("user_id", "admin-1")
+	c.Set("user_type", "admin")
+	if err := h.Get(c); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if !gotAdmin {
+		t.Error("admin user_type should be passed as isAdmin")
+	}
+
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if body["id"] != testFileID {
+		t.Errorf("id = %v", body["id"])
+	}
+	if _, ok := body["download_url"]; !ok {
+		t.Error("download_url missing for uploaded file")
+	}
+	if _, ok := body["StorageKey"]; ok {
+		t.Error("storage key must not be exposed")
+	}
+}
+
+func TestFileDownload_SetsSafeHeaders(t *testing.T) {
+	svc := &mockFileService{
+		openFn: func(_ context.Context, _ string) (*file.File, io.ReadCloser, error) {
+			return uploadedTestFile("application/pdf"), io.NopCloser(strings.NewReader("%PDF-")), nil
+		},
+	}
+	h := &FileHandler{fileService: svc}
+
+	c, rec := fileContext(http.MethodGet, "/api/v1/files/"+testFileID+"/content?expires=1&signature=ab", "")
+	if err := h.Download(c); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if svc.verifiedOp != storage.OpDownload {
+		t.Errorf("verified op = %q, want %q", svc.verifiedOp, storage.OpDownload)
+	}
+	if got := rec.Header().Get(echo.HeaderContentType); got != "application/pdf" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	if got := rec.Header().Get(echo.HeaderContentDisposition); got != `attachment; filename="report final.pdf"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	if got := rec.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Errorf("X-Content-Type-Options = %q", got)
+	}
+	if got := rec.Header().Get("ETag"); got != `"abc123"` {
+		t.Errorf("ETag = %q", got)
+	}
+	if rec.Body.String() != "%PDF-" {
+		t.Errorf("body = %q", rec.Body.String())
+	}
+}
+
+func TestFileDownload_NotModified(t *testing.T) {
+	h := &FileHandler{fileService: &mockFileService{
+		openFn: func(_ context.Context, _ string) (*file.File, io.ReadCloser, error) {
+			return uploadedTestFile("image/png"), io.NopCloser(strings.NewReader("png")), nil
+		},
+	}}
+
+	c, rec := fileContext(http.MethodGet, "/api/v1/files/"+testFileID+"/content?expires=1&signature=ab", "")
+	c.Request().Header.Set("If-None-Match", `"abc123"`)
+	if err := h.Download(c); err != nil {
+		t.Fatalf("Download() error = %v", err)
+	}
+	if rec.Code != http.StatusNotModified {
+		t.Errorf("status = %d, want 304", rec.Code)
+	}
+}
+
+func TestFileDelete_Success(t *testing.T) {
+	var gotUser string
+	h := &FileHandler{fileService: &mockFileService{
+		deleteFn: func(_ context.Context, _, userID string, isAdmin bool) error {
+			gotUser = userID
+			if isAdmin {
+				t.Error("regular user should not be treated as admin")
+			}
+			return nil
+		},
+	}}
+
+	c, rec := fileContext(http.MethodDelete, "/api/v1/files/"+testFileID, "")
+	c.Set("user_id", "user-123")
+	c.Set("user_type", "user")
+	if err := h.Delete(c); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if rec.Code != http.StatusNoContent || gotUser != "user-123" {
+		t.Errorf("status = %d, user = %q", rec.Code, gotUser)
+	}
+}
+
+func TestContentDisposition(t *testing.T) {
+	if got := contentDisposition("image/png", "cat.png"); got != `inline; filename=cat.png` {
+		t.Errorf("image disposition = %q", got)
+	}
+	if got := contentDisposition("text/plain", "notes.txt"); got != `attachment; filename=notes.txt` {
+		t.Errorf("text disposition = %q", got)
+	}
+}
@@ -2,11 +2,22 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
+	"io"
+	"time"
 
 	"github.com/hibiken/asynq"
 
+	"github.com/golid-ai/golid/backend/internal/pow"
+	"github.com/golid-ai/golid/backend/internal/service/announcement"
 	"github.com/golid-ai/golid/backend/internal/service/auth"
+	"github.com/golid-ai/golid/backend/internal/service/email"
+	"github.com/golid-ai/golid/backend/internal/service/export"
 	"github.com/golid-ai/golid/backend/internal/service/feature"
+	"github.com/golid-ai/golid/backend/internal/service/file"
+	"github.com/golid-ai/golid/backend/internal/service/notification"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
+	"github.com/golid-ai/golid/backend/internal/service/preference"
 	"github.com/golid-ai/golid/backend/internal/service/sse"
 	"github.com/golid-ai/golid/backend/internal/service/user"
 )
@@ -29,10 +40,74 @@ type userServicer interface {
 	UpdateProfile(ctx context.Context, userID string, update *user.ProfileUpdate) (*user.UserProfile, error)
 }
 
+type adminUserServicer interface {
+	GetByID(ctx context.Context, userID string) (*user.UserProfile, error)
+	ListUsers(ctx context.Context, input *user.ListUsersInput) (*user.UserListResult, error)
+	AdminUpdate(ctx context.Context, userID string, update *user.AdminUpdate) (*user.UserProfile, error)
+	SetStatus(ctx context.Context, userID string, input *user.SetStatusInput) (*user.UserProfile, error)
+}
+
+type fileServicer interface {
+	CreateUpload(ctx context.Context, ownerID, filename string) (*file.UploadTicket, error)
+	Upload(ctx context.Context, fileID string, body io.Reader) (*file.File, error)
+	GetOwned(ctx context.Context, fileID, userID string, isAdmin bool) (*file.File, error)
+	DownloadURL(f *file.File) (*file.SignedURL, error)
+	Open(ctx context.Context, fileID string) (*file.File, io.ReadCloser, error)
+	Delete(ctx context.Context, fileID, userID string, isAdmin bool) error
+	VerifyURL(op, fileID, expires, signature string) error
+}
+
+type avatarServicer interface {
+	Stage(ctx context.Context, userID string, data []byte, process func(uploadID string) (outbox.Message, error)) (string, error)
+	Remove(ctx context.Context, userID string) error
+	OpenRendition(ctx context.Context, userID, uploadID string, size int) (io.ReadCloser, error)
+}
+
+type exportServicer interface {
+	Request(ctx context.Context, userID string, build func(exportID string) (outbox.Message, error)) (*export.Export, error)
+	Get(ctx context.Context, exportID, userID string) (*export.Export, error)
+	DownloadURL(e *export.Export) (*export.SignedURL, error)
+	VerifyURL(exportID, expires, signature string) error
+	Open(ctx context.Context, exportID string) (*export.Export, io.ReadCloser, error)
+}
+
+type preferenceServicer interface {
+	Get(ctx context.Context, userID string) (map[string]any, error)
+	Update(ctx context.Context, userID string, patch map[string]json.RawMessage) (map[string]any, error)
+}
+
+// regionalReader resolves a user's locale and time zone for emails sent on
+// their behalf by someone else (an admin).
+type regionalReader interface {
+	Regional(ctx context.Context, userID string) (preference.Regional, error)
+}
+
+type announcementServicer interface {
+	Active(ctx context.Context, viewer announcement.Viewer) ([]announcement.Announcement, error)
+	Dismiss(ctx context.Context, userID, id string) error
+	List(ctx context.Context, page, perPage int) (*announcement.ListResult, error)
+	Get(ctx context.Context, id string) (*announcement.Announcement, error)
+	Create(ctx context.Context, in announcement.NewAnnouncement, actorID string) (*announcement.Announcement, error)
+	Update(ctx context.Context, id string, upd announcement.Update) (*announcement.Announcement, error)
+	Delete(ctx context.Context, id string) error
+}
+
+type notificationServicer interface {
+	List(ctx context.Context, userID string, page, perPage int, unreadOnly bool) (*notification.ListResult, error)
+	MarkRead(ctx context.Context, userID string, ids []string, read bool) (int, error)
+	MarkAllRead(ctx context.Context, userID string) (int, error)
+}
+
+// notifier records a notification; used by the dev-only SSE demo.
+type notifier interface {
+	Notify(ctx context.Context, userID, kind string, payload any) (*notification.Notification, error)
+}
+
 type emailServicer interface {
 	IsConfigured() bool
-	SendVerificationEmail(toEmail, token string) error
-	SendPasswordResetEmail(toEmail, token string) error
+	SendVerificationEmail(to email.Recipient, token string) error
+	SendPasswordResetEmail(to email.Recipient, token string) error
+	SendAccountStatusEmail(to email.Recipient, status string, suspendedUntil *time.Time, reason string) error
 }
 
 type queuer interface {
@@ -42,14 +117,41 @@ type queuer interface {
 
 type featureServicer interface {
 	List(ctx context.Context) ([]feature.FeatureFlag, error)
+	Get(ctx context.Context, key string) (*feature.FeatureFlag, error)
+	Create(ctx context.Context, flag feature.NewFlag, change feature.Change) (*feature.FeatureFlag, error)
+	Update(ctx context.Context, key string, update feature.FlagUpdate, actorID string) (*feature.FeatureFlag, error)
+	Delete(ctx context.Context, key string, change feature.Change) error
+	Stale(ctx context.Context, unusedFor time.Duration) ([]feature.StaleFlag, error)
 	ListEnabled(ctx context.Context) (map[string]bool, error)
-	Set(ctx context.Context, key string, enabled bool) error
+	ListFor(ctx context.Context, subject feature.Subject) (map[string]bool, error)
+	EvaluateAll(ctx context.Context, subject feature.Subject) (map[string]feature.Evaluation, error)
+	Set(ctx context.Context, key string, enabled bool, change feature.Change) error
+	SetRules(ctx context.Context, key string, rules *feature.Rules, change feature.Change) error
+	SetValues(ctx context.Context, key string, values feature.Values, change feature.Change) error
+	History(ctx context.Context, key string, page, perPage int) (*feature.HistoryResult, error)
+	Rollback(ctx context.Context, key, eventID string, change feature.Change) error
+	ScheduleChange(ctx context.Context, req feature.ScheduleRequest, change feature.Change) (*feature.Schedule, error)
+	ListSchedules(ctx context.Context, key, status string, page, perPage int) (*feature.ScheduleList, error)
+	CancelSchedule(ctx context.Context, id, actorID string) error
+}
+
+type challengeIssuer interface {
+	Issue() (*pow.Challenge, error)
 }
 
 type sseHubber interface {
-	CreateTicket(userID string) (string, error)
-	ValidateTicket(ticket string) (string, error)
-	Subscribe(userID string) (chan sse.SSEEvent, error)
+	AuthorizeTopics(ctx context.Context, userID string, topics []string) error
+	CreateTicket(userID string, topics ...string) (string, error)
+	ValidateTicket(ticket string) (sse.Grant, error)
+	Subscribe(userID string, topics ...string) (chan sse.SSEEvent, error)
 	Unsubscribe(userID string, ch chan sse.SSEEvent)
 	Send(userID string, event sse.SSEEvent)
+	Since(userID string, topics []string, lastID uint64) (sse.Replay, error)
+	Presence(ctx context.Context, topic string) ([]sse.Presence, error)
+	HandleMessage(ctx context.Context, from sse.Grant, msgType string, data json.RawMessage) error
+}
+
+type sseDisconnecter interface {
+	Send(userID string, event sse.SSEEvent)
+	Disconnect(userID string)
 }
@@ -0,0 +1,89 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/service/notification"
+)
+
+// NotificationHandler handles the current user's notification center.
+type NotificationHandler struct {
+	notificationService notificationServicer
+	paginationDefault   int
+	paginationMax       int
+}
+
+// NewNotificationHandler creates a new notification handler.
+func NewNotificationHandler(notificationService *notification.NotificationService, paginationDefault, paginationMax int) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+		paginationDefault:   paginationDefault,
+		paginationMax:       paginationMax,
+	}
+}
+
+// List handles GET /api/v1/me/notifications
+func (h *NotificationHandler) List(c echo.Context) error {
+	userID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+
+	page, perPage := ParsePagination(c, h.paginationDefault, h.paginationMax)
+	unreadOnly := false
+	if v := c.QueryParam("unread"); v != "" {
+		if unreadOnly, err = strconv.ParseBool(v); err != nil {
+			return apperror.Validation().WithDetail("unread", "error.notification.unread_bool")
+		}
+	}
+
+	result, err := h.notificationService.List(c.Request().Context(), userID, page, perPage, unreadOnly)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+// MarkNotificationsRequest is the request body for PATCH /me/notifications.
+// Either All or IDs must be set; Read defaults to true.
+type MarkNotificationsRequest struct {
+	IDs  []string `json:"ids"`
+	Read *bool    `json:"read"`
+	All  bool     `json:"all"`
+}
+
+// Update handles PATCH /api/v1/me/notifications
+func (h *NotificationHandler) Update(c echo.Context) error {
+	userID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req MarkNotificationsRequest
+	if err := c.Bind(&req); err != nil {
+		return apperror.InvalidBody()
+	}
+	read := req.Read == nil || *req.Read
+
+	ctx := c.Request().Context()
+	var unread int
+	switch {
+	case req.All && len(req.IDs) > 0:
+		return apperror.Validation().WithDetail("all", "error.notification.all_or_ids")
+	case req.All && !read:
+		return apperror.Validation().WithDetail("read", "error.notification.mark_all_unread")
+	case req.All:
+		unread, err = h.notificationService.MarkAllRead(ctx, userID)
+	default:
+		unread, err = h.notificationService.MarkRead(ctx, userID, req.IDs, read)
+	}
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, map[string]int{"unread_count": unread})
+}
@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/service/notification"
+)
+
+// =============================================================================
+// MOCK NOTIFICATION SERVICE
+// =============================================================================
+
+type mockNotificationService struct {
+	listFn        func(ctx context.Context, userID string, page, perPage int, unreadOnly bool) (*notification.ListResult, error)
+	markReadFn    func(ctx context.Context, userID string, ids []string, read bool) (int, error)
+	markAllReadFn func(ctx context.Context, userID string) (int, error)
+}
+
+func (m *mockNotificationService) List(ctx context.Context, userID string, page, perPage int, unreadOnly bool) (*notification.ListResult, error) {
+	if m.listFn != nil {
+		return m.listFn(ctx, userID, page, perPage, unreadOnly)
+	}
+	panic("unexpected List")
+}
+func (m *mockNotificationService) MarkRead(ctx context.Context, userID string, ids []string, read bool) (int, error) {
+	if m.markReadFn != nil {
+		return m.markReadFn(ctx, userID, ids, read)
+	}
+	panic("unexpected MarkRead")
+}
+func (m *mockNotificationService) MarkAllRead(ctx context.Context, userID string) (int, error) {
+	if m.markAllReadFn != nil {
+		return m.markAllReadFn(ctx, userID)
+	}
+	panic("unexpected MarkAllRead")
+}
+
+func notificationContext(method, target, body string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", "user-123")
+	return c, rec
+}
+
+func TestNotificationList(t *testing.T) {
+	h := &NotificationHandler{
+		notificationService: &mockNotificationService{
+			listFn: func(_ context.Context, userID string, page, perPage int, unreadOnly bool) (*notification.ListResult, error) {
+				if userID != "user-123" || page != 2 || perPage != 10 || !unreadOnly {
+					t.Errorf("List(%q, %d, %d, %v)", userID, page, perPage, unreadOnly)
+				}
+				return &notification.ListResult{Notifications: []notification.Notification{}, UnreadCount: 4}, nil
+			},
+		},
+		paginationDefault: 20,
+		paginationMax:     100,
+	}
+
+	c, rec := notificationContext(http.MethodGet, "/api/v1/me/notifications?page=2&per_page=10&unread=true", "")
+	if err := h.List(c); err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if !strings.Contains(rec.Body.String(), `"unread_count":4`) {
+		t.Errorf("body = %s", rec.Body.String())
+	}
+}
+
+func TestNotificationList_BadUnreadParam(t *testing.T) {
+	h := &NotificationHandler{notificationService: &mockNotificationService{}, paginationDefault: 20, paginationMax: 100}
+	c, _ := notificationContext(http.MethodGet, "/api/v1/me/notifications?unread=maybe", "")
+	if err := h.List(c); !apperror.Is(err, apperror.CodeValidation) {
+		t.Errorf("err = %v, want Validation", err)
+	}
+}
+
+func TestNotificationUpdate_MarkIDs(t *testing.T) {
+	tests := []struct {
+		body     string
+		wantRead bool
+	}{
+		{`{"ids":["a","b"]}`, true},
+		{`{"ids":["a","b"],"read":false}`, false},
+	}
+	for _, tt := range tests {
+		h := &NotificationHandler{notificationService: &mockNotificationService{
+			markReadFn: func(_ context.Context, _ string, ids []string, read bool) (int, error) {
+				if len(ids) != 2 || read != tt.wantRead {
+					t.Errorf("%s: MarkRead(%v, %v)", tt.body, ids, read)
+				}
+				return 3, nil
+			},
+		}}
+		c, rec := notificationContext(http.MethodPatch, "/api/v1/me/notifications", tt.body)
+		if err := h.Update(c); err != nil {
+			t.Fatalf("Update(%s) error = %v", tt.body, err)
+		}
+		if !strings.Contains(rec.Body.String(), `"unread_count":3`) {
+			t.Errorf("body = %s", rec.Body.String())
+		}
+	}
+}
+
+func TestNotificationUpdate_MarkAll(t *testing.T) {
+	called := false
+	h := &NotificationHandler{notificationService: &mockNotificationService{
+		markAllReadFn: func(context.Context, string) (int, error) {
+			called = true
+			return 0, nil
+		},
+	}}
+	c, _ := notificationContext(http.MethodPatch, "/api/v1/me/notifications", `{"all":true}`)
+	if err := h.Update(c); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if !called {
+		t.Error("MarkAllRead was not called")
+	}
+}
+
+func TestNotificationUpdate_InvalidCombinations(t *testing.T) {
+	h := &NotificationHandler{notificationService: &mockNotificationService{}}
+	for _, body := range []string{`{"all":true,"ids":["a"]}`, `{"all":true,"read":false}`} {
+		c, _ := notificationContext(http.MethodPatch, "/api/v1/me/notifications", body)
+		if err := h.Update(c); !apperror.Is(err, apperror.CodeValidation) {
+			t.Errorf("Update(%s) err = %v, want Validation", body, err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/service/preference"
+)
+
+// PreferenceHandler handles the current user's preferences.
+type PreferenceHandler struct {
+	prefService preferenceServicer
+}
+
+// NewPreferenceHandler creates a new preference handler.
+func NewPreferenceHandler(prefService *preference.PreferenceService) *PreferenceHandler {
+	return &PreferenceHandler{prefService: prefService}
+}
+
+// Get handles GET /api/v1/me/preferences
+func (h *PreferenceHandler) Get(c echo.Context) error {
+	userID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+
+	prefs, err := h.prefService.Get(c.Request().Context(), userID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, prefs)
+}
+
+// Update handles PATCH /api/v1/me/preferences
+//
+// The body is a JSON object of keys to set; null resets a key to its
+// default. Responds with the full set of preferences after the update.
+func (h *PreferenceHandler) Update(c echo.Context) error {
+	userID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(c.Request().Body).Decode(&patch); err != nil {
+		return apperror.InvalidBody()
+	}
+
+	prefs, err := h.prefService.Update(c.Request().Context(), userID, patch)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, prefs)
+}
@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+)
+
+// =============================================================================
+// MOCK PREFERENCE SERVICE
+// =============================================================================
+
+type mockPreferenceService struct {
+	getFn    func(ctx context.Context, userID string) (map[string]any, error)
+	updateFn func(ctx context.Context, userID string, patch map[string]json.RawMessage) (map[string]any, error)
+}
+
+func (m *mockPreferenceService) Get(ctx context.Context, userID string) (map[string]any, error) {
+	if m.getFn != nil {
+		return m.getFn(ctx, userID)
+	}
+	panic("unexpected Get")
+}
+func (m *mockPreferenceService) Update(ctx context.Context, userID string, patch map[string]json.RawMessage) (map[string]any, error) {
+	if m.updateFn != nil {
+		return m.updateFn(ctx, userID, patch)
+	}
+	panic("unexpected Update")
+}
+
+func preferenceContext(method, body string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, "/api/v1/me/preferences", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", "user-123")
+	return c, rec
+}
+
+func TestPreferenceGet(t *testing.T) {
+	h := &PreferenceHandler{prefService: &mockPreferenceService{
+		getFn: func(_ context.Context, userID string) (map[string]any, error) {
+			if userID != "user-123" {
+				t.Errorf("userID = %q", userID)
+			}
+			return map[string]any{"locale": "en", "timezone": "UTC"}, nil
+		},
+	}}
+
+	c, rec := preferenceContext(http.MethodGet, "")
+	if err := h.Get(c); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"timezone":"UTC"`) {
+		t.Errorf("status = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestPreferenceUpdate_PassesRawPatch(t *testing.T) {
+	h := &PreferenceHandler{prefService: &mockPreferenceService{
+		updateFn: func(_ context.Context, _ string, patch map[string]json.RawMessage) (map[string]any, error) {
+			if string(patch["timezone"]) != `"Europe/Paris"` || string(patch["locale"]) != "null" {
+				t.Errorf("patch = %s", patch)
+			}
+			return map[string]any{"locale": "en", "timezone": "Europe/Paris"}, nil
+		},
+	}}
+
+	c, rec := preferenceContext(http.MethodPatch, `{"timezone":"Europe/Paris","locale":null}`)
+	if err := h.Update(c); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if rec.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", rec.Code)
+	}
+}
+
+func TestPreferenceUpdate_InvalidBody(t *testing.T) {
+	h := &PreferenceHandler{prefService: &mockPreferenceService{}}
+	for _, body := range []string{`not json`, `["locale"]`} {
+		c, _ := preferenceContext(http.MethodPatch, body)
+		if err := h.Update(c); !apperror.Is(err, apperror.CodeBadRequest) {
+			t.Errorf("Update(%s) err = %v, want BadRequest", body, err)
+		}
+	}
+}
+
+func TestPreferenceUpdate_RequiresAuth(t *testing.T) {
+	h := &PreferenceHandler{prefService: &mockPreferenceService{}}
+	c, _ := preferenceContext(http.MethodPatch, `{}`)
+	c.Set("user_id", nil)
+	if err := h.Update(c); !apperror.Is(err, apperror.CodeUnauthorized) {
+		t.Errorf("err = %v, want Unauthorized", err)
+	}
+}
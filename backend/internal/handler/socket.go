@@ -0,0 +1,230 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/time/rate"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/logger"
+	"github.com/golid-ai/golid/backend/internal/service/sse"
+)
+
+const (
+	// Largest client message accepted — bounds memory per connection; not environment-specific.
+	socketMaxMessageSize = 64 << 10
+	// Client message rate per connection — room for typing and cursor updates; not environment-specific.
+	socketMessageRate  = 20
+	socketMessageBurst = 40
+	// Per-frame write deadline, so a stalled client can't hold its connection's writer.
+	socketWriteTimeout = 10 * time.Second
+	// Error replies queued for the writer; more are dropped for a client flooding bad messages.
+	socketReplyBuffer = 16
+)
+
+// socketUpgrader accepts every origin: the ticket is the credential, not
+// cookies.
+var socketUpgrader = websocket.Upgrader{
+	CheckOrigin: func(*http.Request) bool { return true },
+}
+
+// eventError is sent to a WebSocket client whose message failed.
+const eventError = "error"
+
+// socketMessage is a message from a WebSocket client. ID, if set, is
+// echoed as ref in the error event when the message fails.
+type socketMessage struct {
+	ID   string          `json:"id,omitempty"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// socketError is the data of an error event.
+type socketError struct {
+	Ref       string        `json:"ref,omitempty"`
+	Code      apperror.Code `json:"code"`
+	Message   string        `json:"message"`
+	MessageID string        `json:"message_id,omitempty"`
+}
+
+// Socket handles GET /api/v1/events/ws?ticket=...
+// The WebSocket counterpart of Stream: same ticket, same events (sent as
+// JSON text frames {"id", "event", "data"}), same replay with
+// last_event_id. Clients may also send {"id", "type", "data"} messages,
+// which are routed to the handler registered for type; failures come back
+// as "error" events. Ping frames replace Stream's keepalive comments; a
+// client that answers neither pings nor with messages for two keepalive
+// intervals is disconnected.
+func (h *SSEHandler) Socket(c echo.Context) error {
+	ticket := c.QueryParam("ticket")
+	if ticket == "" {
+		return apperror.Unauthorized("ticket is required").WithMessageID("error.sse.ticket_required")
+	}
+
+	grant, err := h.hub.ValidateTicket(ticket)
+	if err != nil {
+		return apperror.Unauthorized("invalid or expired ticket").WithMessageID("error.sse.invalid_ticket")
+	}
+
+	ch, err := h.hub.Subscribe(grant.UserID, grant.Topics...)
+	if err != nil {
+		return apperror.BadRequest("unable to subscribe to events").WithMessageID("error.sse.subscribe_failed")
+	}
+	defer h.hub.Unsubscribe(grant.UserID, ch)
+
+	// Upgrade replies with an HTTP error itself when the handshake fails.
+	ws, err := socketUpgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		return nil
+	}
+
+	logger.Info("WebSocket connected",
+		slog.String("user_id", grant.UserID),
+		slog.String("remote_ip", c.RealIP()),
+	)
+	h.serveSocket(c, ws, grant, ch)
+
+	logger.Info("WebSocket disconnected",
+		slog.String("user_id", grant.UserID),
+		slog.String("remote_ip", c.RealIP()),
+	)
+	return nil
+}
+
+// serveSocket writes events to the client, and reads its messages on a
+// second goroutine, until either side closes.
+func (h *SSEHandler) serveSocket(c echo.Context, ws *websocket.Conn, grant sse.Grant, ch chan sse.SSEEvent) {
+	defer ws.Close() //nolint:errcheck // connection is being discarded
+
+	ws.SetReadLimit(socketMaxMessageSize)
+
+	ctx, cancel := context.WithCancel(c.Request().Context())
+	defer cancel()
+	replies := make(chan sse.SSEEvent, socketReplyBuffer)
+	go h.readSocket(ctx, cancel, ws, grant, replies)
+
+	// Subscribed before reading history, so nothing sent in between is
+	// lost; events that arrive both ways are written once.
+	missed, replayed := h.catchUp(c, grant)
+	for _, event := range missed {
+		if err := writeSocket(ws, event); err != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(h.keepaliveInterval)
+	defer ticker.Stop()
+
+	for {
+		var err error
+		select {
+		case event, open := <-ch:
+			if !open {
+				return
+			}
+			if _, dup := replayed[event.ID]; dup {
+				delete(replayed, event.ID)
+				continue
+			}
+			err = writeSocket(ws, event)
+
+		case reply := <-replies:
+			err = writeSocket(ws, reply)
+
+		case <-ticker.C:
+			err = ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(socketWriteTimeout))
+
+		case <-ctx.Done():
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// readSocket routes client messages to their handlers, in order, until
+// the connection fails or closes, then calls done. Every pong or message
+// extends the read deadline by two keepalive intervals, so a client that
+// vanished without closing TCP is dropped once it misses a ping.
+// Messages over socketMaxMessageSize close the connection (code 1009).
+func (h *SSEHandler) readSocket(ctx context.Context, done context.CancelFunc, ws *websocket.Conn, grant sse.Grant, replies chan<- sse.SSEEvent) {
+	defer done()
+
+	idle := 2 * h.keepaliveInterval
+	_ = ws.SetReadDeadline(time.Now().Add(idle)) //nolint:errcheck // surfaces as a read error
+	ws.SetPongHandler(func(string) error {
+		return ws.SetReadDeadline(time.Now().Add(idle))
+	})
+
+	limiter := rate.NewLimiter(socketMessageRate, socketMessageBurst)
+	reply := func(ref string, err error) {
+		select {
+		case replies <- socketErrorEvent(ref, err):
+		default:
+		}
+	}
+
+	for {
+		_, raw, err := ws.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = ws.SetReadDeadline(time.Now().Add(idle)) //nolint:errcheck // surfaces as a read error
+
+		var msg socketMessage
+		if err := json.Unmarshal(raw, &msg); err != nil || msg.Type == "" {
+			reply(msg.ID, apperror.InvalidBody())
+			continue
+		}
+		if !limiter.Allow() {
+			reply(msg.ID, apperror.RateLimited())
+			continue
+		}
+		if err := h.hub.HandleMessage(ctx, grant, msg.Type, msg.Data); err != nil {
+			reply(msg.ID, err)
+		}
+	}
+}
+
+// socketErrorEvent describes err to the client: an *apperror.AppError as
+// is, anything else as a logged internal error.
+func socketErrorEvent(ref string, err error) sse.SSEEvent {
+	var appErr *apperror.AppError
+	if !errors.As(err, &appErr) {
+		logger.Error("WebSocket message failed", slog.String("error", err.Error()))
+		appErr = apperror.Internal(err)
+	}
+	return sse.SSEEvent{Event: eventError, Data: socketError{
+		Ref:       ref,
+		Code:      appErr.Code,
+		Message:   appErr.Message,
+		MessageID: appErr.MessageID,
+	}}
+}
+
+// writeSocket sends one event as a JSON text frame. An event that can't
+// be marshaled is logged and skipped; only connection errors are
+// returned.
+func writeSocket(ws *websocket.Conn, event sse.SSEEvent) error {
+	data, err := event.MarshalData()
+	if err != nil {
+		logger.Error("WebSocket marshal error",
+			slog.String("event", event.Event),
+			slog.String("error", err.Error()),
+		)
+		return nil
+	}
+	// data is already valid JSON, so this cannot fail.
+	frame, _ := json.Marshal(sse.SSEEvent{ID: event.ID, Event: event.Event, Data: json.RawMessage(data)}) //nolint:errcheck // see above
+
+	_ = ws.SetWriteDeadline(time.Now().Add(socketWriteTimeout)) //nolint:errcheck // surfaces as a write error
+	return ws.WriteMessage(websocket.TextMessage, frame)
+}
@@ -0,0 +1,213 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/service/sse"
+)
+
+// dialSocket serves h.Socket and connects a client to it.
+func dialSocket(t *testing.T, h *SSEHandler, query string) *websocket.Conn {
+	t.Helper()
+	e := echo.New()
+	e.GET("/api/v1/events/ws", h.Socket)
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/v1/events/ws?" + query
+	ws, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { _ = ws.Close() })
+	return ws
+}
+
+func receiveFrame(t *testing.T, ws *websocket.Conn) map[string]any {
+	t.Helper()
+	_ = ws.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var frame map[string]any
+	if err := ws.ReadJSON(&frame); err != nil {
+		t.Fatalf("Receive() error = %v", err)
+	}
+	return frame
+}
+
+func TestSocket_InvalidTicket(t *testing.T) {
+	hub := &mockSSEHub{
+		validateTicketFn: func(ticket string) (sse.Grant, error) {
+			return sse.Grant{}, fmt.Errorf("invalid ticket")
+		},
+	}
+	h := &SSEHandler{hub: hub, keepaliveInterval: 30 * time.Second}
+
+	for _, url := range []string{"/api/v1/events/ws", "/api/v1/events/ws?ticket=bad"} {
+		c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, url, nil), httptest.NewRecorder())
+		if err := h.Socket(c); err == nil {
+			t.Errorf("Socket(%s) expected error", url)
+		}
+	}
+}
+
+func TestSocket_EventsAndReplay(t *testing.T) {
+	ch := make(chan sse.SSEEvent, 2)
+	hub := &mockSSEHub{
+		validateTicketFn: func(ticket string) (sse.Grant, error) {
+			return sse.Grant{UserID: "user-1", Topics: []string{"doc:a"}}, nil
+		},
+		subscribeFn: func(userID string, topics []string) (chan sse.SSEEvent, error) { return ch, nil },
+		sinceFn: func(userID string, topics []string, lastID uint64) (sse.Replay, error) {
+			if lastID != 10 || len(topics) != 1 {
+				t.Errorf("Since(%v, %d)", topics, lastID)
+			}
+			return sse.Replay{Complete: true, Latest: 11, Events: []sse.SSEEvent{{ID: 11, Event: "notification", Data: "missed"}}}, nil
+		},
+	}
+	h := &SSEHandler{hub: hub, keepaliveInterval: 30 * time.Second}
+
+	// Event 11 raced the replay and must be sent once.
+	ch <- sse.SSEEvent{ID: 11, Event: "notification", Data: "missed"}
+	ch <- sse.SSEEvent{ID: 12, Event: "doc_updated", Data: map[string]string{"doc": "a"}}
+	ws := dialSocket(t, h, "ticket=valid&last_event_id=10")
+
+	if f := receiveFrame(t, ws); f["id"] != float64(11) || f["data"] != "missed" {
+		t.Errorf("first frame = %v, want replayed event 11", f)
+	}
+	f := receiveFrame(t, ws)
+	if f["id"] != float64(12) || f["event"] != "doc_updated" {
+		t.Errorf("second frame = %v, want live event 12", f)
+	}
+	if data, _ := f["data"].(map[string]any); data["doc"] != "a" {
+		t.Errorf("data = %v", f["data"])
+	}
+}
+
+func TestSocket_ClientMessages(t *testing.T) {
+	got := make(chan string, 1)
+	hub := &mockSSEHub{
+		validateTicketFn: func(ticket string) (sse.Grant, error) { return sse.Grant{UserID: "user-1"}, nil },
+		subscribeFn: func(userID string, topics []string) (chan sse.SSEEvent, error) {
+			return make(chan sse.SSEEvent), nil
+		},
+		handleMessageFn: func(_ context.Context, from sse.Grant, msgType string, data json.RawMessage) error {
+			if msgType == "typing" {
+				got <- from.UserID + " " + string(data)
+				return nil
+			}
+			return apperror.BadRequest("unknown message type")
+		},
+	}
+	h := &SSEHandler{hub: hub, keepaliveInterval: 30 * time.Second}
+	ws := dialSocket(t, h, "ticket=valid")
+
+	if err := ws.WriteMessage(websocket.TextMessage, []byte(`{"type":"typing","data":{"doc":"a"}}`)); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case msg := <-got:
+		if msg != `user-1 {"doc":"a"}` {
+			t.Errorf("handler got %q", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("message not routed")
+	}
+
+	// Failures come back as error events referencing the message.
+	_ = ws.WriteMessage(websocket.TextMessage, []byte(`{"id":"m2","type":"bogus"}`))
+	f := receiveFrame(t, ws)
+	data, _ := f["data"].(map[string]any)
+	if f["event"] != "error" || data["ref"] != "m2" || data["code"] != string(apperror.CodeBadRequest) {
+		t.Errorf("frame = %v, want a bad request error for m2", f)
+	}
+
+	_ = ws.WriteMessage(websocket.TextMessage, []byte(`not json`))
+	if f := receiveFrame(t, ws); f["event"] != "error" {
+		t.Errorf("frame = %v, want an error for malformed JSON", f)
+	}
+}
+
+func TestSocket_DropsClientThatStopsAnsweringPings(t *testing.T) {
+	unsubscribed := make(chan struct{})
+	hub := &mockSSEHub{
+		validateTicketFn: func(ticket string) (sse.Grant, error) { return sse.Grant{UserID: "user-1"}, nil },
+		subscribeFn: func(userID string, topics []string) (chan sse.SSEEvent, error) {
+			return make(chan sse.SSEEvent), nil
+		},
+		unsubscribeFn: func(userID string, ch chan sse.SSEEvent) { close(unsubscribed) },
+	}
+	h := &SSEHandler{hub: hub, keepaliveInterval: 50 * time.Millisecond}
+	ws := dialSocket(t, h, "ticket=valid")
+
+	// Keep reading, so pings arrive, but never answer them.
+	ws.SetPingHandler(func(string) error { return nil })
+	closed := make(chan error, 1)
+	go func() {
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				closed <- err
+				return
+			}
+		}
+	}()
+
+	select {
+	case <-closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection still open after the client stopped answering pings")
+	}
+	select {
+	case <-unsubscribed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("client was not unsubscribed")
+	}
+}
+
+func TestSocket_KeepsClientThatAnswersPings(t *testing.T) {
+	got := make(chan string, 1)
+	hub := &mockSSEHub{
+		validateTicketFn: func(ticket string) (sse.Grant, error) { return sse.Grant{UserID: "user-1"}, nil },
+		subscribeFn: func(userID string, topics []string) (chan sse.SSEEvent, error) {
+			return make(chan sse.SSEEvent), nil
+		},
+		handleMessageFn: func(_ context.Context, _ sse.Grant, msgType string, _ json.RawMessage) error {
+			got <- msgType
+			return nil
+		},
+	}
+	h := &SSEHandler{hub: hub, keepaliveInterval: 50 * time.Millisecond}
+	ws := dialSocket(t, h, "ticket=valid")
+
+	// The default ping handler answers with a pong while reading.
+	go func() {
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Idle for several read deadlines, kept alive by pongs alone.
+	time.Sleep(300 * time.Millisecond)
+	if err := ws.WriteMessage(websocket.TextMessage, []byte(`{"type":"typing"}`)); err != nil {
+		t.Fatalf("WriteMessage() error = %v", err)
+	}
+	select {
+	case msgType := <-got:
+		if msgType != "typing" {
+			t.Errorf("handler got %q", msgType)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("connection dropped although the client answered pings")
+	}
+}
@@ -2,37 +2,49 @@ package handler
 
 import (
 	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/labstack/echo/v4"
 
 	"github.com/golid-ai/golid/backend/internal/apperror"
 	"github.com/golid-ai/golid/backend/internal/logger"
+	"github.com/golid-ai/golid/backend/internal/service/notification"
 	"github.com/golid-ai/golid/backend/internal/service/sse"
 )
 
 // SSEHandler handles Server-Sent Events endpoints.
 type SSEHandler struct {
 	hub               sseHubber
+	notifications     notifier
 	keepaliveInterval time.Duration
 }
 
 // NewSSEHandler creates a new SSE handler.
-func NewSSEHandler(hub *sse.SSEHub, keepaliveInterval time.Duration) *SSEHandler {
-	return &SSEHandler{hub: hub, keepaliveInterval: keepaliveInterval}
+func NewSSEHandler(hub *sse.SSEHub, notifications *notification.NotificationService, keepaliveInterval time.Duration) *SSEHandler {
+	return &SSEHandler{hub: hub, notifications: notifications, keepaliveInterval: keepaliveInterval}
 }
 
-// Ticket handles POST /api/v1/events/ticket
+// Ticket handles POST /api/v1/events/ticket?topic=org:123&topic=...
 // Requires JWT auth. Returns a one-time ticket for SSE stream connection.
+// Each topic is checked by its registered authorizer now; the stream
+// opened with the ticket receives them without further checks.
 func (h *SSEHandler) Ticket(c echo.Context) error {
 	userID, err := requireUserID(c)
 	if err != nil {
 		return err
 	}
 
-	ticket, err := h.hub.CreateTicket(userID)
+	topics := slices.Compact(slices.Sorted(slices.Values(c.QueryParams()["topic"])))
+	if err := h.hub.AuthorizeTopics(c.Request().Context(), userID, topics); err != nil {
+		return err
+	}
+
+	ticket, err := h.hub.CreateTicket(userID, topics...)
 	if err != nil {
 		return apperror.Internal(fmt.Errorf("create SSE ticket: %w", err))
 	}
@@ -44,20 +56,27 @@ func (h *SSEHandler) Ticket(c echo.Context) error {
 
 // Stream handles GET /api/v1/events/stream?ticket=...
 // Uses one-time ticket auth (not JWT — EventSource can't set headers).
+//
+// Events carry an id. A client reconnecting with the last one it saw, in
+// the Last-Event-ID header or the last_event_id query parameter (a fresh
+// ticket means a fresh EventSource, which won't send the header), is first
+// sent what it missed; if that history is gone it gets a "reset" event
+// instead and must refetch.
 func (h *SSEHandler) Stream(c echo.Context) error {
 	ticket := c.QueryParam("ticket")
 	if ticket == "" {
-		return apperror.Unauthorized("ticket is required")
+		return apperror.Unauthorized("ticket is required").WithMessageID("error.sse.ticket_required")
 	}
 
-	userID, err := h.hub.ValidateTicket(ticket)
+	grant, err := h.hub.ValidateTicket(ticket)
 	if err != nil {
-		return apperror.Unauthorized("invalid or expired ticket")
+		return apperror.Unauthorized("invalid or expired ticket").WithMessageID("error.sse.invalid_ticket")
 	}
+	userID := grant.UserID
 
-	ch, err := h.hub.Subscribe(userID)
+	ch, err := h.hub.Subscribe(userID, grant.Topics...)
 	if err != nil {
-		return apperror.BadRequest("unable to subscribe to events")
+		return apperror.BadRequest("unable to subscribe to events").WithMessageID("error.sse.subscribe_failed")
 	}
 	defer h.hub.Unsubscribe(userID, ch)
 
@@ -78,6 +97,19 @@ func (h *SSEHandler) Stream(c echo.Context) error {
 		return apperror.Internal(fmt.Errorf("streaming not supported"))
 	}
 
+	// Subscribed before reading history, so nothing sent in between is
+	// lost; events that arrive both ways are written once.
+	missed, replayed := h.catchUp(c, grant)
+	for _, event := range missed {
+		if err := writeSSE(w, event); err != nil {
+			logger.Error("SSE marshal error",
+				slog.String("user_id", userID),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+	flusher.Flush()
+
 	ticker := time.NewTicker(h.keepaliveInterval)
 	defer ticker.Stop()
 
@@ -89,15 +121,17 @@ func (h *SSEHandler) Stream(c echo.Context) error {
 			if !open {
 				return nil
 			}
-			data, err := event.MarshalData()
-			if err != nil {
+			if _, dup := replayed[event.ID]; dup {
+				delete(replayed, event.ID)
+				continue
+			}
+			if err := writeSSE(w, event); err != nil {
 				logger.Error("SSE marshal error",
 					slog.String("user_id", userID),
 					slog.String("error", err.Error()),
 				)
 				continue
 			}
-			_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Event, data) //nolint:errcheck // SSE write to flushed stream
 			flusher.Flush()
 
 		case <-ticker.C:
@@ -114,21 +148,99 @@ func (h *SSEHandler) Stream(c echo.Context) error {
 	}
 }
 
+// catchUp returns what a reconnecting client missed, or a reset event
+// when that can't be done, along with the IDs of replayed events so their
+// live copies can be skipped. A first connection gets nothing.
+func (h *SSEHandler) catchUp(c echo.Context, grant sse.Grant) ([]sse.SSEEvent, map[uint64]struct{}) {
+	raw := c.Request().Header.Get("Last-Event-ID")
+	if raw == "" {
+		raw = c.QueryParam("last_event_id")
+	}
+	if raw == "" {
+		return nil, nil
+	}
+	// An unparseable ID is 0, which no store issued, so it resets.
+	lastID, _ := strconv.ParseUint(raw, 10, 64) //nolint:errcheck // see above
+
+	replay, err := h.hub.Since(grant.UserID, grant.Topics, lastID)
+	if err != nil {
+		logger.Error("SSE replay failed",
+			slog.String("user_id", grant.UserID),
+			slog.String("error", err.Error()),
+		)
+	}
+	if err != nil || !replay.Complete {
+		return []sse.SSEEvent{{ID: replay.Latest, Event: sse.EventReset, Data: struct{}{}}}, nil
+	}
+
+	replayed := make(map[uint64]struct{}, len(replay.Events))
+	for _, event := range replay.Events {
+		replayed[event.ID] = struct{}{}
+	}
+	return replay.Events, replayed
+}
+
+// writeSSE writes one event in text/event-stream format. Events without an
+// ID (never recorded) are written without an id line, so the client keeps
+// its last ID. A reconnect event also sets the EventSource retry delay.
+func writeSSE(w io.Writer, event sse.SSEEvent) error {
+	data, err := event.MarshalData()
+	if err != nil {
+		return err
+	}
+	if event.ID != 0 {
+		_, _ = fmt.Fprintf(w, "id: %d\n", event.ID) //nolint:errcheck // SSE write to flushed stream
+	}
+	if hint, ok := event.Data.(sse.Reconnect); ok {
+		_, _ = fmt.Fprintf(w, "retry: %d\n", hint.Retry) //nolint:errcheck // SSE write to flushed stream
+	}
+	_, _ = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Event, data) //nolint:errcheck // SSE write to flushed stream
+	return nil
+}
+
+// Presence handles GET /api/v1/presence?topic=org:123
+// Requires JWT auth and the same authorization as subscribing to the
+// topic. Lists who is online in it and who was seen in the last day.
+func (h *SSEHandler) Presence(c echo.Context) error {
+	userID, err := requireUserID(c)
+	if err != nil {
+		return err
+	}
+
+	topic := c.QueryParam("topic")
+	if topic == "" {
+		return apperror.BadRequest("topic is required").WithMessageID("error.sse.topic_required")
+	}
+	ctx := c.Request().Context()
+	if err := h.hub.AuthorizeTopics(ctx, userID, []string{topic}); err != nil {
+		return err
+	}
+
+	users, err := h.hub.Presence(ctx, topic)
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("list presence: %w", err))
+	}
+
+	return c.JSON(http.StatusOK, map[string]any{
+		"topic": topic,
+		"users": users,
+	})
+}
+
 // Demo handles POST /api/v1/events/demo
-// Requires JWT auth. Sends a demo notification event to the calling user's SSE stream.
+// Requires JWT auth. Records a demo notification, which is pushed to the
+// calling user's SSE stream.
 func (h *SSEHandler) Demo(c echo.Context) error {
 	userID, err := requireUserID(c)
 	if err != nil {
 		return err
 	}
 
-	h.hub.Send(userID, sse.SSEEvent{
-		Event: "notification",
-		Data: map[string]any{
-			"message":   "This is a demo notification",
-			"timestamp": time.Now().UTC().Format(time.RFC3339),
-		},
-	})
+	if _, err := h.notifications.Notify(c.Request().Context(), userID, "demo", map[string]any{
+		"message": "This is a demo notification",
+	}); err != nil {
+		return err
+	}
 
 	return c.JSON(http.StatusOK, map[string]string{
 		"message": "Demo event sent",
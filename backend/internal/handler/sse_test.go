@@ -1,16 +1,21 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/labstack/echo/v4"
 
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/service/notification"
 	"github.com/golid-ai/golid/backend/internal/service/sse"
 )
 
@@ -19,19 +24,32 @@ import (
 // =============================================================================
 
 type mockSSEHub struct {
-	createTicketFn   func(userID string) (string, error)
-	validateTicketFn func(ticket string) (string, error)
-	subscribeFn      func(userID string) (chan sse.SSEEvent, error)
-	unsubscribeFn    func(userID string, ch chan sse.SSEEvent)
-	sendFn           func(userID string, event sse.SSEEvent)
+	authorizeTopicsFn func(ctx context.Context, userID string, topics []string) error
+	createTicketFn    func(userID string, topics []string) (string, error)
+	validateTicketFn  func(ticket string) (sse.Grant, error)
+	subscribeFn       func(userID string, topics []string) (chan sse.SSEEvent, error)
+	unsubscribeFn     func(userID string, ch chan sse.SSEEvent)
+	sendFn            func(userID string, event sse.SSEEvent)
+	disconnectFn      func(userID string)
+	sinceFn           func(userID string, topics []string, lastID uint64) (sse.Replay, error)
+	presenceFn        func(ctx context.Context, topic string) ([]sse.Presence, error)
+	handleMessageFn   func(ctx context.Context, from sse.Grant, msgType string, data json.RawMessage) error
 }
 
-func (m *mockSSEHub) CreateTicket(userID string) (string, error) { return m.createTicketFn(userID) }
-func (m *mockSSEHub) ValidateTicket(ticket string) (string, error) {
+func (m *mockSSEHub) AuthorizeTopics(ctx context.Context, userID string, topics []string) error {
+	if m.authorizeTopicsFn != nil {
+		return m.authorizeTopicsFn(ctx, userID, topics)
+	}
+	return nil
+}
+func (m *mockSSEHub) CreateTicket(userID string, topics ...string) (string, error) {
+	return m.createTicketFn(userID, topics)
+}
+func (m *mockSSEHub) ValidateTicket(ticket string) (sse.Grant, error) {
 	return m.validateTicketFn(ticket)
 }
-func (m *mockSSEHub) Subscribe(userID string) (chan sse.SSEEvent, error) {
-	return m.subscribeFn(userID)
+func (m *mockSSEHub) Subscribe(userID string, topics ...string) (chan sse.SSEEvent, error) {
+	return m.subscribeFn(userID, topics)
 }
 func (m *mockSSEHub) Unsubscribe(userID string, ch chan sse.SSEEvent) {
 	if m.unsubscribeFn != nil {
@@ -43,6 +61,42 @@ func (m *mockSSEHub) Send(userID string, event sse.SSEEvent) {
 		m.sendFn(userID, event)
 	}
 }
+func (m *mockSSEHub) Disconnect(userID string) {
+	if m.disconnectFn != nil {
+		m.disconnectFn(userID)
+	}
+}
+func (m *mockSSEHub) Since(userID string, topics []string, lastID uint64) (sse.Replay, error) {
+	if m.sinceFn != nil {
+		return m.sinceFn(userID, topics, lastID)
+	}
+	panic("unexpected Since")
+}
+
+func (m *mockSSEHub) Presence(ctx context.Context, topic string) ([]sse.Presence, error) {
+	if m.presenceFn != nil {
+		return m.presenceFn(ctx, topic)
+	}
+	panic("unexpected Presence")
+}
+
+func (m *mockSSEHub) HandleMessage(ctx context.Context, from sse.Grant, msgType string, data json.RawMessage) error {
+	if m.handleMessageFn != nil {
+		return m.handleMessageFn(ctx, from, msgType, data)
+	}
+	panic("unexpected HandleMessage")
+}
+
+type mockNotifier struct {
+	notifyFn func(ctx context.Context, userID, kind string, payload any) (*notification.Notification, error)
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, userID, kind string, payload any) (*notification.Notification, error) {
+	if m.notifyFn != nil {
+		return m.notifyFn(ctx, userID, kind, payload)
+	}
+	panic("unexpected Notify")
+}
 
 // =============================================================================
 // TICKET HANDLER TESTS
@@ -50,7 +104,7 @@ func (m *mockSSEHub) Send(userID string, event sse.SSEEvent) {
 
 func TestTicket_Success(t *testing.T) {
 	hub := &mockSSEHub{
-		createTicketFn: func(userID string) (string, error) {
+		createTicketFn: func(userID string, topics []string) (string, error) {
 			return "test-ticket-abc123", nil
 		},
 	}
@@ -77,6 +131,54 @@ func TestTicket_Success(t *testing.T) {
 	}
 }
 
+func TestTicket_Topics(t *testing.T) {
+	var authorized, granted []string
+	hub := &mockSSEHub{
+		authorizeTopicsFn: func(_ context.Context, userID string, topics []string) error {
+			authorized = topics
+			return nil
+		},
+		createTicketFn: func(userID string, topics []string) (string, error) {
+			granted = topics
+			return "ticket", nil
+		},
+	}
+	h := &SSEHandler{hub: hub, keepaliveInterval: 30 * time.Second}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/events/ticket?topic=org:1&topic=doc:a&topic=org:1", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", "test-user-id")
+
+	if err := h.Ticket(c); err != nil {
+		t.Fatalf("Ticket() error = %v", err)
+	}
+	want := []string{"doc:a", "org:1"}
+	if !slices.Equal(authorized, want) || !slices.Equal(granted, want) {
+		t.Errorf("authorized %v, granted %v; want %v for both", authorized, granted, want)
+	}
+}
+
+func TestTicket_TopicDenied(t *testing.T) {
+	hub := &mockSSEHub{
+		authorizeTopicsFn: func(context.Context, string, []string) error {
+			return apperror.Forbidden("not a member")
+		},
+	}
+	h := &SSEHandler{hub: hub, keepaliveInterval: 30 * time.Second}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/events/ticket?topic=org:2", nil)
+	c := e.NewContext(req, httptest.NewRecorder())
+	c.Set("user_id", "test-user-id")
+
+	var appErr *apperror.AppError
+	if err := h.Ticket(c); !errors.As(err, &appErr) || appErr.Code != apperror.CodeForbidden {
+		t.Errorf("Ticket() error = %v, want forbidden", err)
+	}
+}
+
 func TestTicket_NoAuth(t *testing.T) {
 	h := &SSEHandler{hub: &mockSSEHub{}, keepaliveInterval: 30 * time.Second}
 
@@ -92,18 +194,84 @@ func TestTicket_NoAuth(t *testing.T) {
 }
 
 // =============================================================================
-// DEMO HANDLER TESTS
+// PRESENCE HANDLER TESTS
 // =============================================================================
 
-func TestDemo_Success(t *testing.T) {
-	var sentEvent sse.SSEEvent
+func TestPresence_Success(t *testing.T) {
+	seen := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	hub := &mockSSEHub{
+		authorizeTopicsFn: func(_ context.Context, userID string, topics []string) error {
+			if userID != "test-user-id" || !slices.Equal(topics, []string{"org:1"}) {
+				t.Errorf("AuthorizeTopics(%q, %v)", userID, topics)
+			}
+			return nil
+		},
+		presenceFn: func(_ context.Context, topic string) ([]sse.Presence, error) {
+			return []sse.Presence{{UserID: "user-2", Online: true, LastSeen: seen}}, nil
+		},
+	}
+	h := &SSEHandler{hub: hub, keepaliveInterval: 30 * time.Second}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/presence?topic=org:1", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", "test-user-id")
+
+	if err := h.Presence(c); err != nil {
+		t.Fatalf("Presence() error = %v", err)
+	}
+	want := `{"topic":"org:1","users":[{"user_id":"user-2","online":true,"last_seen":"2026-10-01T12:00:00Z"}]}`
+	if got := strings.TrimSpace(rec.Body.String()); got != want {
+		t.Errorf("body = %s, want %s", got, want)
+	}
+}
+
+func TestPresence_Errors(t *testing.T) {
 	hub := &mockSSEHub{
-		sendFn: func(userID string, event sse.SSEEvent) {
-			sentEvent = event
+		authorizeTopicsFn: func(context.Context, string, []string) error {
+			return apperror.Forbidden("not a member")
 		},
 	}
 	h := &SSEHandler{hub: hub, keepaliveInterval: 30 * time.Second}
 
+	tests := []struct {
+		name string
+		url  string
+		code apperror.Code
+	}{
+		{"missing topic", "/api/v1/presence", apperror.CodeBadRequest},
+		{"not authorized", "/api/v1/presence?topic=org:2", apperror.CodeForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, tt.url, nil), httptest.NewRecorder())
+			c.Set("user_id", "test-user-id")
+
+			var appErr *apperror.AppError
+			if err := h.Presence(c); !errors.As(err, &appErr) || appErr.Code != tt.code {
+				t.Errorf("Presence() error = %v, want %s", err, tt.code)
+			}
+		})
+	}
+}
+
+// =============================================================================
+// DEMO HANDLER TESTS
+// =============================================================================
+
+func TestDemo_Success(t *testing.T) {
+	var gotUser, gotKind string
+	var gotPayload any
+	notifications := &mockNotifier{
+		notifyFn: func(_ context.Context, userID, kind string, payload any) (*notification.Notification, error) {
+			gotUser, gotKind, gotPayload = userID, kind, payload
+			return &notification.Notification{ID: "n-1", Kind: kind}, nil
+		},
+	}
+	h := &SSEHandler{hub: &mockSSEHub{}, notifications: notifications, keepaliveInterval: 30 * time.Second}
+
 	e := echo.New()
 	req := httptest.NewRequest(http.MethodPost, "/api/v1/events/demo", nil)
 	rec := httptest.NewRecorder()
@@ -118,8 +286,11 @@ func TestDemo_Success(t *testing.T) {
 		t.Errorf("status = %d, want %d", rec.Code, http.StatusOK)
 	}
 
-	if sentEvent.Event != "notification" {
-		t.Errorf("sent event = %q, want %q", sentEvent.Event, "notification")
+	if gotUser != "test-user-id" || gotKind != "demo" {
+		t.Errorf("Notify(%q, %q), want test-user-id, demo", gotUser, gotKind)
+	}
+	if p, ok := gotPayload.(map[string]any); !ok || p["message"] == "" {
+		t.Errorf("payload = %v, want a message", gotPayload)
 	}
 
 	var result map[string]string
@@ -145,8 +316,8 @@ func TestDemo_NoAuth(t *testing.T) {
 
 func TestStream_InvalidTicket(t *testing.T) {
 	hub := &mockSSEHub{
-		validateTicketFn: func(ticket string) (string, error) {
-			return "", fmt.Errorf("invalid ticket")
+		validateTicketFn: func(ticket string) (sse.Grant, error) {
+			return sse.Grant{}, fmt.Errorf("invalid ticket")
 		},
 	}
 	h := &SSEHandler{hub: hub, keepaliveInterval: 30 * time.Second}
@@ -179,8 +350,8 @@ func TestStream_MissingTicket(t *testing.T) {
 func TestStream_ReceivesEvent(t *testing.T) {
 	ch := make(chan sse.SSEEvent, 1)
 	hub := &mockSSEHub{
-		validateTicketFn: func(ticket string) (string, error) { return "user-1", nil },
-		subscribeFn:      func(userID string) (chan sse.SSEEvent, error) { return ch, nil },
+		validateTicketFn: func(ticket string) (sse.Grant, error) { return sse.Grant{UserID: "user-1"}, nil },
+		subscribeFn:      func(userID string, topics []string) (chan sse.SSEEvent, error) { return ch, nil },
 	}
 	h := &SSEHandler{hub: hub, keepaliveInterval: 30 * time.Second}
 
@@ -202,3 +373,118 @@ func TestStream_ReceivesEvent(t *testing.T) {
 		t.Errorf("response should contain event data, got: %s", body)
 	}
 }
+
+func TestStream_EvictedWithRetryHint(t *testing.T) {
+	ch := make(chan sse.SSEEvent, 1)
+	hub := &mockSSEHub{
+		validateTicketFn: func(ticket string) (sse.Grant, error) { return sse.Grant{UserID: "user-1"}, nil },
+		subscribeFn:      func(userID string, topics []string) (chan sse.SSEEvent, error) { return ch, nil },
+	}
+	h := &SSEHandler{hub: hub, keepaliveInterval: 30 * time.Second}
+
+	// The hub ends a connection that fell behind with a reconnect event.
+	ch <- sse.SSEEvent{Event: sse.EventReconnect, Data: sse.Reconnect{Retry: 5000}}
+	close(ch)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/events/stream?ticket=valid", nil)
+	rec := httptest.NewRecorder()
+	_ = h.Stream(e.NewContext(req, rec))
+
+	want := "retry: 5000\nevent: reconnect\ndata: {\"retry\":5000}\n\n"
+	if body := rec.Body.String(); !strings.Contains(body, want) {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
+
+func TestStream_ReplaysMissedEvents(t *testing.T) {
+	ch := make(chan sse.SSEEvent, 2)
+	var gotLastID uint64
+	hub := &mockSSEHub{
+		validateTicketFn: func(ticket string) (sse.Grant, error) { return sse.Grant{UserID: "user-1"}, nil },
+		subscribeFn:      func(userID string, topics []string) (chan sse.SSEEvent, error) { return ch, nil },
+		sinceFn: func(userID string, topics []string, lastID uint64) (sse.Replay, error) {
+			gotLastID = lastID
+			return sse.Replay{Complete: true, Latest: 12, Events: []sse.SSEEvent{
+				{ID: 11, Event: "notification", Data: "missed"},
+				{ID: 12, Event: "notification", Data: "raced"},
+			}}, nil
+		},
+	}
+	h := &SSEHandler{hub: hub, keepaliveInterval: 30 * time.Second}
+
+	// Event 12 was sent after Subscribe and before Since: it must be
+	// written once.
+	ch <- sse.SSEEvent{ID: 12, Event: "notification", Data: "raced"}
+	ch <- sse.SSEEvent{ID: 13, Event: "notification", Data: "live"}
+	close(ch)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/events/stream?ticket=valid", nil)
+	req.Header.Set("Last-Event-ID", "10")
+	rec := httptest.NewRecorder()
+	_ = h.Stream(e.NewContext(req, rec))
+
+	if gotLastID != 10 {
+		t.Errorf("Since lastID = %d, want 10", gotLastID)
+	}
+	want := "id: 11\nevent: notification\ndata: \"missed\"\n\n" +
+		"id: 12\nevent: notification\ndata: \"raced\"\n\n" +
+		"id: 13\nevent: notification\ndata: \"live\"\n\n"
+	if body := rec.Body.String(); body != want {
+		t.Errorf("body =\n%s\nwant\n%s", body, want)
+	}
+}
+
+func TestStream_SubscribesGrantedTopics(t *testing.T) {
+	ch := make(chan sse.SSEEvent)
+	close(ch)
+	var subscribed, replayed []string
+	hub := &mockSSEHub{
+		validateTicketFn: func(ticket string) (sse.Grant, error) {
+			return sse.Grant{UserID: "user-1", Topics: []string{"org:1"}}, nil
+		},
+		subscribeFn: func(userID string, topics []string) (chan sse.SSEEvent, error) {
+			subscribed = topics
+			return ch, nil
+		},
+		sinceFn: func(userID string, topics []string, lastID uint64) (sse.Replay, error) {
+			replayed = topics
+			return sse.Replay{Complete: true}, nil
+		},
+	}
+	h := &SSEHandler{hub: hub, keepaliveInterval: 30 * time.Second}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/events/stream?ticket=valid&last_event_id=5", nil)
+	_ = h.Stream(e.NewContext(req, httptest.NewRecorder()))
+
+	if !slices.Equal(subscribed, []string{"org:1"}) || !slices.Equal(replayed, []string{"org:1"}) {
+		t.Errorf("subscribed %v, replayed %v; want the granted topics", subscribed, replayed)
+	}
+}
+
+func TestStream_ResetWhenHistoryIncomplete(t *testing.T) {
+	ch := make(chan sse.SSEEvent)
+	close(ch)
+	hub := &mockSSEHub{
+		validateTicketFn: func(ticket string) (sse.Grant, error) { return sse.Grant{UserID: "user-1"}, nil },
+		subscribeFn:      func(userID string, topics []string) (chan sse.SSEEvent, error) { return ch, nil },
+		sinceFn: func(userID string, topics []string, lastID uint64) (sse.Replay, error) {
+			if lastID != 5 {
+				t.Errorf("Since lastID = %d, want 5 from the query", lastID)
+			}
+			return sse.Replay{Complete: false, Latest: 99}, nil
+		},
+	}
+	h := &SSEHandler{hub: hub, keepaliveInterval: 30 * time.Second}
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/events/stream?ticket=valid&last_event_id=5", nil)
+	rec := httptest.NewRecorder()
+	_ = h.Stream(e.NewContext(req, rec))
+
+	if body := rec.Body.String(); body != "id: 99\nevent: reset\ndata: {}\n\n" {
+		t.Errorf("body = %q, want a reset event", body)
+	}
+}
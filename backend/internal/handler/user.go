@@ -53,6 +53,9 @@ func (h *UserHandler) Me(c echo.Context) error {
 }
 
 // UpdateProfileRequest is the request body for profile updates.
+//
+// AvatarURL is accepted only so a non-null value can be rejected with a
+// pointer to POST /api/v1/me/avatar; avatars are no longer set by URL.
 type UpdateProfileRequest struct {
 	FirstName string  `json:"first_name,omitempty"`
 	LastName  string  `json:"last_name,omitempty"`
@@ -68,7 +71,7 @@ func (h *UserHandler) UpdateProfile(c echo.Context) error {
 
 	var req UpdateProfileRequest
 	if err := c.Bind(&req); err != nil {
-		return apperror.BadRequest("Invalid request body")
+		return apperror.InvalidBody()
 	}
 
 	if err := validateProfileUpdate(&req); err != nil {
@@ -76,10 +79,8 @@ func (h *UserHandler) UpdateProfile(c echo.Context) error {
 	}
 
 	user, err := h.userService.UpdateProfile(c.Request().Context(), userID, &user.ProfileUpdate{
-		FirstName:    req.FirstName,
-		LastName:     req.LastName,
-		AvatarURL:    derefStr(req.AvatarURL),
-		AvatarURLSet: req.AvatarURL != nil,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
 	})
 	if err != nil {
 		return err
@@ -97,20 +98,23 @@ func derefStr(s *string) string {
 
 // validateProfileUpdate checks profile fields for reasonable formats and lengths.
 func validateProfileUpdate(req *UpdateProfileRequest) error {
-	details := make(map[string]string)
+	verr := apperror.Validation()
 
 	req.FirstName = strings.TrimSpace(req.FirstName)
 	req.LastName = strings.TrimSpace(req.LastName)
 
 	if len(req.FirstName) > 100 {
-		details["first_name"] = "First name must be 100 characters or fewer"
+		verr.WithDetail("first_name", "error.user.first_name_too_long", 100)
 	}
 	if len(req.LastName) > 100 {
-		details["last_name"] = "Last name must be 100 characters or fewer"
+		verr.WithDetail("last_name", "error.user.last_name_too_long", 100)
+	}
+	if req.AvatarURL != nil {
+		verr.WithDetail("avatar_url", "error.user.avatar_url")
 	}
 
-	if len(details) > 0 {
-		return apperror.Validation("Validation failed", details)
+	if len(verr.Details) > 0 {
+		return verr
 	}
 	return nil
 }
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/labstack/echo/v4"
 
+	"github.com/golid-ai/golid/backend/internal/apperror"
 	"github.com/golid-ai/golid/backend/internal/service/user"
 )
 
@@ -61,22 +63,9 @@ func TestUpdateProfile_Success(t *testing.T) {
 	}
 }
 
-func TestUpdateProfile_WithAvatarURL(t *testing.T) {
-	var receivedUpdate *user.ProfileUpdate
-	mock := &mockUserService{
-		getByIDFn: func(ctx context.Context, userID string) (*user.UserProfile, error) {
-			return testUserProfile("John"), nil
-		},
-		updateProfileFn: func(ctx context.Context, userID string, update *user.ProfileUpdate) (*user.UserProfile, error) {
-			receivedUpdate = update
-			p := testUserProfile("John")
-			if update.AvatarURLSet {
-				p.AvatarURL = &update.AvatarURL
-			}
-			return p, nil
-		},
-	}
-	h := &UserHandler{userService: mock}
+func TestUpdateProfile_RejectsAvatarURL(t *testing.T) {
+	// No mock functions: reaching the service would panic.
+	h := &UserHandler{userService: &mockUserService{}}
 
 	body := `{"avatar_url":"https://example.com/pic.jpg"}`
 	e := echo.New()
@@ -86,16 +75,14 @@ func TestUpdateProfile_WithAvatarURL(t *testing.T) {
 	c := e.NewContext(req, rec)
 	c.Set("user_id", "user-123")
 
-	if err := h.UpdateProfile(c); err != nil {
-		t.Fatalf("UpdateProfile() error = %v", err)
+	err := h.UpdateProfile(c)
+	if !apperror.Is(err, apperror.CodeValidation) {
+		t.Fatalf("UpdateProfile() error = %v, want validation error", err)
 	}
-	if receivedUpdate == nil {
-		t.Fatal("expected UpdateProfile to be called with update")
-	}
-	if !receivedUpdate.AvatarURLSet {
-		t.Error("AvatarURLSet should be true when avatar_url is provided")
-	}
-	if receivedUpdate.AvatarURL != "https://example.com/pic.jpg" {
-		t.Errorf("AvatarURL = %q, want %q", receivedUpdate.AvatarURL, "https://example.com/pic.jpg")
+	var appErr *apperror.AppError
+	if errors.As(err, &appErr) {
+		if _, ok := appErr.Details["avatar_url"]; !ok {
+			t.Errorf("details = %v, want avatar_url", appErr.Details)
+		}
 	}
 }
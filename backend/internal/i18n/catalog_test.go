@@ -0,0 +1,88 @@
+package i18n_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"maps"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/i18n"
+)
+
+var messageIDPattern = regexp.MustCompile(`^error(\.[a-z0-9_]+)+$`)
+
+// Every error message ID the backend can return must be in every catalog,
+// including the IDs apperror.NotFound derives from its resource name.
+// Literal IDs are collected from the source so a new call site without a
+// catalog entry fails here instead of reaching users in English.
+func TestCatalogs_CoverErrorIDs(t *testing.T) {
+	ids := map[string]string{} // id -> first position seen
+	for _, root := range []string{"..", "../../cmd"} {
+		err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return err
+			}
+			fset := token.NewFileSet()
+			file, err := parser.ParseFile(fset, path, nil, 0)
+			if err != nil {
+				return err
+			}
+			ast.Inspect(file, func(n ast.Node) bool {
+				var id string
+				switch n := n.(type) {
+				case *ast.CallExpr:
+					sel, ok := n.Fun.(*ast.SelectorExpr)
+					if !ok || sel.Sel.Name != "NotFound" || len(n.Args) != 1 {
+						return true
+					}
+					if pkg, ok := sel.X.(*ast.Ident); !ok || pkg.Name != "apperror" {
+						return true
+					}
+					if resource, ok := stringLit(n.Args[0]); ok {
+						id = apperror.NotFound(resource).MessageID
+					}
+				case *ast.BasicLit:
+					if s, ok := stringLit(n); ok && messageIDPattern.MatchString(s) {
+						id = s
+					}
+				}
+				if _, seen := ids[id]; id != "" && !seen {
+					ids[id] = fset.Position(n.Pos()).String()
+				}
+				return true
+			})
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("scan %s: %v", root, err)
+		}
+	}
+	if len(ids) == 0 {
+		t.Fatal("found no message IDs; is the scan rooted correctly?")
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(ids)) {
+		for _, locale := range i18n.Locales() {
+			if !i18n.HasMessage(locale, id) {
+				t.Errorf("%s: %q missing from %s catalog", ids[id], id, locale)
+			}
+		}
+	}
+}
+
+func stringLit(e ast.Expr) (string, bool) {
+	lit, ok := e.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	s, err := strconv.Unquote(lit.Value)
+	return s, err == nil
+}
@@ -0,0 +1,8 @@
+package i18n
+
+// HasMessage reports whether locale's own catalog defines id, without
+// falling back to the default locale.
+func HasMessage(locale, id string) bool {
+	_, ok := catalogs[locale][id]
+	return ok
+}
@@ -0,0 +1,208 @@
+// Package i18n holds the message catalogs for user-facing text: API error
+// messages and email copy.
+//
+// Catalogs are flat JSON objects embedded from locales/<tag>.json, mapping
+// a stable message ID to a fmt format string. Translations that reorder
+// arguments use explicit indexes ("%[2]s ... %[1]s"). A message missing
+// from a locale falls back to DefaultLocale.
+//
+// Callers resolve a locale once with Match, from the user's stored
+// preference and the request's Accept-Language, and pass the result to T.
+package i18n
+
+import (
+	"cmp"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultLocale is used when nothing the user asked for is available, and
+// for messages a catalog does not translate.
+const DefaultLocale = "en"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// catalogs maps a canonical locale tag to its messages.
+var catalogs = mustLoad()
+
+func mustLoad() map[string]map[string]string {
+	entries, err := localeFS.ReadDir("locales")
+	if err != nil {
+		panic(fmt.Sprintf("i18n: read locales: %v", err))
+	}
+	out := make(map[string]map[string]string, len(entries))
+	for _, e := range entries {
+		data, err := localeFS.ReadFile("locales/" + e.Name())
+		if err != nil {
+			panic(fmt.Sprintf("i18n: read %s: %v", e.Name(), err))
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: parse %s: %v", e.Name(), err))
+		}
+		out[strings.TrimSuffix(e.Name(), path.Ext(e.Name()))] = messages
+	}
+	if _, ok := out[DefaultLocale]; !ok {
+		panic("i18n: missing catalog for default locale " + DefaultLocale)
+	}
+	return out
+}
+
+// Locales returns the tags of every shipped catalog, sorted.
+func Locales() []string {
+	tags := make([]string, 0, len(catalogs))
+	for tag := range catalogs {
+		tags = append(tags, tag)
+	}
+	slices.Sort(tags)
+	return tags
+}
+
+// Match returns the first supported locale for the given language tags, in
+// order of preference. Each tag matches a catalog exactly ("pt-BR"), then
+// by language ("es-MX" → "es", "pt" → "pt-BR"). Empty and wildcard tags are
+// skipped; DefaultLocale is returned when nothing matches.
+func Match(tags ...string) string {
+	for _, tag := range tags {
+		lang, region := canonical(tag)
+		if lang == "" {
+			continue
+		}
+		if region != "" {
+			if _, ok := catalogs[lang+"-"+region]; ok {
+				return lang + "-" + region
+			}
+		}
+		if _, ok := catalogs[lang]; ok {
+			return lang
+		}
+		for _, supported := range Locales() {
+			if strings.HasPrefix(supported, lang+"-") {
+				return supported
+			}
+		}
+	}
+	return DefaultLocale
+}
+
+// canonical splits a language tag into a lowercase language and an
+// uppercase region. Script and variant subtags are dropped.
+func canonical(tag string) (lang, region string) {
+	parts := strings.FieldsFunc(strings.TrimSpace(tag), func(r rune) bool { return r == '-' || r == '_' })
+	if len(parts) == 0 || parts[0] == "*" {
+		return "", ""
+	}
+	lang = strings.ToLower(parts[0])
+	for _, p := range parts[1:] {
+		if len(p) == 2 {
+			region = strings.ToUpper(p)
+			break
+		}
+	}
+	return lang, region
+}
+
+// maxAcceptLanguage bounds how many entries of a header are considered.
+const maxAcceptLanguage = 20
+
+// ParseAcceptLanguage returns the language tags in an Accept-Language
+// header, most preferred first. Entries with q=0 or a malformed q are
+// dropped; ties keep header order.
+func ParseAcceptLanguage(header string) []string {
+	type entry struct {
+		tag string
+		q   float64
+	}
+	var entries []entry
+	for part := range strings.SplitSeq(header, ",") {
+		if len(entries) == maxAcceptLanguage {
+			break
+		}
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q <= 0 {
+			continue
+		}
+		entries = append(entries, entry{tag, q})
+	}
+	slices.SortStableFunc(entries, func(a, b entry) int { return cmp.Compare(b.q, a.q) })
+
+	tags := make([]string, len(entries))
+	for i, e := range entries {
+		tags[i] = e.tag
+	}
+	return tags
+}
+
+// T returns message id in locale, formatted with args. time.Time args are
+// formatted with FormatTime in UTC. Returns id itself when no catalog has
+// the message, so a missing translation is visible rather than blank.
+func T(locale, id string, args ...any) string {
+	if msg, ok := Lookup(locale, id, args...); ok {
+		return msg
+	}
+	return id
+}
+
+// Lookup is T, reporting whether any catalog has the message.
+func Lookup(locale, id string, args ...any) (string, bool) {
+	format, ok := catalogs[locale][id]
+	if !ok {
+		format, ok = catalogs[DefaultLocale][id]
+	}
+	if !ok {
+		return "", false
+	}
+	if len(args) == 0 {
+		return format, true
+	}
+	formatted := make([]any, len(args))
+	for i, a := range args {
+		if t, ok := a.(time.Time); ok {
+			a = FormatTime(locale, t, time.UTC)
+		}
+		formatted[i] = a
+	}
+	return fmt.Sprintf(format, formatted...), true
+}
+
+// Plural returns the "<id>.one" message when n is 1 and "<id>.other"
+// otherwise, formatted with n. This covers the shipped locales; a locale
+// with more plural forms would need more suffixes here.
+func Plural(locale, id string, n int) string {
+	form := ".other"
+	if n == 1 {
+		form = ".one"
+	}
+	return T(locale, id+form, n)
+}
+
+// FormatTime formats t in loc (UTC when nil) using the locale's
+// "format.datetime" layout, with the month name translated.
+func FormatTime(locale string, t time.Time, loc *time.Location) string {
+	if loc == nil {
+		loc = time.UTC
+	}
+	t = t.In(loc)
+	s := t.Format(T(locale, "format.datetime"))
+	month := t.Month().String()
+	return strings.Replace(s, month, T(locale, "month."+strings.ToLower(month)), 1)
+}
@@ -0,0 +1,153 @@
+package i18n
+
+import (
+	"maps"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+)
+
+var verbPattern = regexp.MustCompile(`%(\[\d+\])?[a-z]`)
+
+// Every catalog must translate exactly the default catalog's messages with
+// the same format verbs, so a missing or mistyped argument fails here
+// rather than in a user's inbox.
+func TestCatalogs_MatchDefault(t *testing.T) {
+	base := catalogs[DefaultLocale]
+	for locale, messages := range catalogs {
+		for id, format := range base {
+			translated, ok := messages[id]
+			if !ok {
+				t.Errorf("%s: missing %q", locale, id)
+				continue
+			}
+			want := verbPattern.FindAllString(format, -1)
+			got := verbPattern.FindAllString(translated, -1)
+			slices.Sort(want)
+			slices.Sort(got)
+			if !slices.Equal(got, want) {
+				t.Errorf("%s: %q verbs = %v, want %v", locale, id, got, want)
+			}
+		}
+		for id := range messages {
+			if _, ok := base[id]; !ok {
+				t.Errorf("%s: %q is not in the %s catalog", locale, id, DefaultLocale)
+			}
+		}
+	}
+}
+
+func TestLocales(t *testing.T) {
+	got := Locales()
+	if !slices.Equal(got, slices.Sorted(maps.Keys(catalogs))) || !slices.Contains(got, DefaultLocale) {
+		t.Errorf("Locales() = %v", got)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		tags []string
+		want string
+	}{
+		{nil, DefaultLocale},
+		{[]string{""}, DefaultLocale},
+		{[]string{"es"}, "es"},
+		{[]string{"es-MX"}, "es"},
+		{[]string{"pt-BR"}, "pt-BR"},
+		{[]string{"pt_br"}, "pt-BR"},
+		{[]string{"pt"}, "pt-BR"},
+		{[]string{"zh-Hant-TW", "es"}, "es"},
+		{[]string{"", "*", "fr", "pt-PT"}, "pt-BR"},
+		{[]string{"de"}, DefaultLocale},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.tags...); got != tt.want {
+			t.Errorf("Match(%q) = %q, want %q", tt.tags, got, tt.want)
+		}
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		header string
+		want   []string
+	}{
+		{"", []string{}},
+		{"es", []string{"es"}},
+		{"fr;q=0.5, pt-BR, en;q=0.8", []string{"pt-BR", "en", "fr"}},
+		{"de;q=0, es;q=abc, it", []string{"it"}},
+		{"en-US,en;q=0.9,*;q=0.1", []string{"en-US", "en", "*"}},
+	}
+	for _, tt := range tests {
+		if got := ParseAcceptLanguage(tt.header); !slices.Equal(got, tt.want) {
+			t.Errorf("ParseAcceptLanguage(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestT(t *testing.T) {
+	if got := T("es", "email.team", "Golid"); got != "El equipo de Golid" {
+		t.Errorf("T(es) = %q", got)
+	}
+	// Unknown locales and untranslated IDs fall back.
+	if got := T("de", "email.team", "Golid"); got != "The Golid team" {
+		t.Errorf("T(de) = %q", got)
+	}
+	if got := T("es", "no.such.message"); got != "no.such.message" {
+		t.Errorf("T(missing) = %q", got)
+	}
+	if _, ok := Lookup("es", "no.such.message"); ok {
+		t.Error("Lookup(missing) reported ok")
+	}
+
+	until := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	args := []any{until}
+	if got := T("pt-BR", "error.account.suspended_until", args...); got != "Sua conta está suspensa até 1 de março de 2026 às 09:30 UTC" {
+		t.Errorf("T(time arg) = %q", got)
+	}
+	if _, ok := args[0].(time.Time); !ok {
+		t.Error("T modified the caller's args")
+	}
+}
+
+func TestPlural(t *testing.T) {
+	tests := []struct {
+		locale string
+		n      int
+		want   string
+	}{
+		{"en", 1, "1 hour"},
+		{"en", 24, "24 hours"},
+		{"es", 1, "1 hora"},
+		{"pt-BR", 3, "3 horas"},
+	}
+	for _, tt := range tests {
+		if got := Plural(tt.locale, "duration.hours", tt.n); got != tt.want {
+			t.Errorf("Plural(%s, %d) = %q, want %q", tt.locale, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	ts := time.Date(2026, 3, 4, 12, 5, 0, 0, time.UTC)
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+
+	tests := []struct {
+		locale string
+		loc    *time.Location
+		want   string
+	}{
+		{"en", nil, "March 4, 2026 at 12:05 UTC"},
+		{"en", paris, "March 4, 2026 at 13:05 CET"},
+		{"es", paris, "4 de marzo de 2026, 13:05 CET"},
+	}
+	for _, tt := range tests {
+		if got := FormatTime(tt.locale, ts, tt.loc); got != tt.want {
+			t.Errorf("FormatTime(%s, %v) = %q, want %q", tt.locale, tt.loc, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+// Package imageproc validates and resizes user-supplied images.
+//
+// Images are identified by decoding, never by file extension or
+// Content-Type. Dimensions are read from the header before any pixel data
+// is allocated, so decompression bombs (a small file declaring an enormous
+// canvas) are rejected cheaply. Output is always re-encoded from decoded
+// pixels, which drops EXIF, GPS, ICC, and every other metadata block; the
+// EXIF orientation of JPEGs is applied first so photos are not left
+// sideways.
+package imageproc
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+	"slices"
+
+	// Registered decoders. WebP and other formats are not accepted.
+	_ "image/gif"
+	_ "image/jpeg"
+)
+
+var (
+	// ErrUnsupportedFormat is returned for data that is not a JPEG, PNG, or GIF.
+	ErrUnsupportedFormat = errors.New("imageproc: unsupported image format")
+	// ErrTooLarge is returned when the declared dimensions exceed Limits.
+	ErrTooLarge = errors.New("imageproc: image dimensions too large")
+)
+
+// Limits bounds the images Decode will accept.
+type Limits struct {
+	MaxPixels int // width × height
+}
+
+// Info describes an image header.
+type Info struct {
+	Format string // "jpeg", "png" or "gif"
+	Width  int
+	Height int
+}
+
+// Inspect reads the image header and enforces limits without decoding
+// pixel data.
+func Inspect(data []byte, limits Limits) (*Info, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, ErrUnsupportedFormat
+	}
+	// Divide rather than multiply so hostile dimensions cannot overflow.
+	if limits.MaxPixels > 0 && cfg.Width > limits.MaxPixels/cfg.Height {
+		return nil, fmt.Errorf("%w: %dx%d", ErrTooLarge, cfg.Width, cfg.Height)
+	}
+	return &Info{Format: format, Width: cfg.Width, Height: cfg.Height}, nil
+}
+
+// Decode inspects data, decodes it, and applies any JPEG EXIF orientation.
+func Decode(data []byte, limits Limits) (image.Image, error) {
+	info, err := Inspect(data, limits)
+	if err != nil {
+		return nil, err
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	if info.Format == "jpeg" {
+		img = orient(img, exifOrientation(data))
+	}
+	return img, nil
+}
+
+// Squares returns center-cropped square renditions of src at each size,
+// keyed by size. Sizes are produced largest first, each downsampled from
+// the previous one, so a large source is only scanned once.
+func Squares(src image.Image, sizes []int) map[int]*image.RGBA {
+	b := src.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side)
+	base := image.NewRGBA(crop)
+	draw.Draw(base, crop, src, image.Pt(b.Min.X+(b.Dx()-side)/2, b.Min.Y+(b.Dy()-side)/2), draw.Src)
+
+	ordered := slices.Clone(sizes)
+	slices.Sort(ordered)
+	slices.Reverse(ordered)
+
+	out := make(map[int]*image.RGBA, len(sizes))
+	prev := base
+	for _, size := range ordered {
+		img := resample(prev, size)
+		out[size] = img
+		if size <= prev.Bounds().Dx() {
+			prev = img
+		}
+	}
+	return out
+}
+
+// EncodePNG encodes img as PNG.
+func EncodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("imageproc: encode png: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// resample scales a square image to size×size with a box filter over
+// premultiplied RGBA (so transparent pixels do not darken edges).
+// Upscaling degrades to nearest-neighbour.
+func resample(src *image.RGBA, size int) *image.RGBA {
+	side := src.Bounds().Dx()
+	dst := image.NewRGBA(image.Rect(0, 0, size, size))
+	for dy := 0; dy < size; dy++ {
+		sy0, sy1 := span(dy, size, side)
+		for dx := 0; dx < size; dx++ {
+			sx0, sx1 := span(dx, size, side)
+			var r, g, b, a, n uint64
+			for sy := sy0; sy < sy1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := sx0; sx < sx1; sx++ {
+					p := row[sx*4 : sx*4+4 : sx*4+4]
+					r += uint64(p[0])
+					g += uint64(p[1])
+					b += uint64(p[2])
+					a += uint64(p[3])
+					n++
+				}
+			}
+			o := dst.PixOffset(dx, dy)
+			dst.Pix[o+0] = uint8((r + n/2) / n)
+			dst.Pix[o+1] = uint8((g + n/2) / n)
+			dst.Pix[o+2] = uint8((b + n/2) / n)
+			dst.Pix[o+3] = uint8((a + n/2) / n)
+		}
+	}
+	return dst
+}
+
+// span maps destination index d (of size) to the half-open source range
+// it covers in an axis of length side. Always at least one pixel wide.
+func span(d, size, side int) (int, int) {
+	lo := d * side / size
+	hi := (d + 1) * side / size
+	if hi <= lo {
+		hi = lo + 1
+	}
+	return lo, hi
+}
@@ -0,0 +1,194 @@
+package imageproc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+// quadrants returns a w×h image whose top-left quadrant is red and the
+// rest white, so orientation changes are observable.
+func quadrants(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			c := color.RGBA{255, 255, 255, 255}
+			if x < w/2 && y < h/2 {
+				c = color.RGBA{255, 0, 0, 255}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// jpegWithOrientation encodes img as JPEG and inserts an EXIF APP1
+// segment carrying the given orientation right after SOI.
+func jpegWithOrientation(t *testing.T, img image.Image, orientation uint16) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	raw := buf.Bytes()
+
+	tiff := []byte("MM\x00\x2a\x00\x00\x00\x08")  // big-endian, IFD0 at 8
+	tiff = binary.BigEndian.AppendUint16(tiff, 1) // one entry
+	tiff = binary.BigEndian.AppendUint16(tiff, 0x0112)
+	tiff = binary.BigEndian.AppendUint16(tiff, 3) // SHORT
+	tiff = binary.BigEndian.AppendUint32(tiff, 1)
+	tiff = binary.BigEndian.AppendUint16(tiff, orientation)
+	tiff = append(tiff, 0, 0, 0, 0, 0, 0) // value padding + next IFD
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+
+	seg := []byte{0xFF, 0xE1}
+	seg = binary.BigEndian.AppendUint16(seg, uint16(len(payload)+2))
+	seg = append(seg, payload...)
+
+	out := append([]byte{}, raw[:2]...)
+	out = append(out, seg...)
+	return append(out, raw[2:]...)
+}
+
+func TestInspect_RejectsNonImages(t *testing.T) {
+	for _, data := range [][]byte{nil, []byte("hello"), []byte("%PDF-1.7"), []byte("GIF89a")} {
+		if _, err := Inspect(data, Limits{}); !errors.Is(err, ErrUnsupportedFormat) {
+			t.Errorf("Inspect(%q) error = %v, want ErrUnsupportedFormat", data, err)
+		}
+	}
+}
+
+func TestInspect_RejectsDecompressionBomb(t *testing.T) {
+	data := encodePNG(t, image.NewGray(image.Rect(0, 0, 1, 1)))
+	// Rewrite IHDR to declare a 60000×60000 canvas and fix up its CRC.
+	binary.BigEndian.PutUint32(data[16:], 60000)
+	binary.BigEndian.PutUint32(data[20:], 60000)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+
+	_, err := Inspect(data, Limits{MaxPixels: 25_000_000})
+	if !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Inspect() error = %v, want ErrTooLarge", err)
+	}
+	if _, err := Decode(data, Limits{MaxPixels: 25_000_000}); !errors.Is(err, ErrTooLarge) {
+		t.Fatalf("Decode() error = %v, want ErrTooLarge", err)
+	}
+}
+
+func TestInspect_AcceptsWithinLimits(t *testing.T) {
+	info, err := Inspect(encodePNG(t, quadrants(40, 20)), Limits{MaxPixels: 800})
+	if err != nil {
+		t.Fatalf("Inspect() error = %v", err)
+	}
+	if info.Format != "png" || info.Width != 40 || info.Height != 20 {
+		t.Errorf("Info = %+v", info)
+	}
+	if _, err := Inspect(encodePNG(t, quadrants(40, 20)), Limits{MaxPixels: 799}); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("one pixel over limit error = %v, want ErrTooLarge", err)
+	}
+}
+
+func TestDecode_AppliesEXIFOrientation(t *testing.T) {
+	src := quadrants(64, 32) // red top-left, landscape
+
+	tests := []struct {
+		orientation uint16
+		wantW       int
+		wantRedAt   image.Point // a pixel that should be red after orientation
+	}{
+		{1, 64, image.Pt(5, 5)},
+		{3, 64, image.Pt(58, 26)},  // rotated 180: red moves bottom-right
+		{6, 32, image.Pt(26, 5)},   // rotated 90 CW: red moves top-right
+		{8, 32, image.Pt(5, 58)},   // rotated 90 CCW: red moves bottom-left
+		{2, 64, image.Pt(58, 5)},   // mirrored: red moves top-right
+		{0xFF, 64, image.Pt(5, 5)}, // invalid value ignored
+	}
+	for _, tt := range tests {
+		img, err := Decode(jpegWithOrientation(t, src, tt.orientation), Limits{MaxPixels: 1 << 20})
+		if err != nil {
+			t.Fatalf("orientation %d: Decode() error = %v", tt.orientation, err)
+		}
+		if got := img.Bounds().Dx(); got != tt.wantW {
+			t.Errorf("orientation %d: width = %d, want %d", tt.orientation, got, tt.wantW)
+		}
+		r, g, _, _ := img.At(tt.wantRedAt.X, tt.wantRedAt.Y).RGBA()
+		if r>>8 < 200 || g>>8 > 60 {
+			t.Errorf("orientation %d: pixel %v not red (r=%d g=%d)", tt.orientation, tt.wantRedAt, r>>8, g>>8)
+		}
+	}
+}
+
+func TestExifOrientation_Malformed(t *testing.T) {
+	for _, data := range [][]byte{
+		{0xFF, 0xD8},
+		{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0xFF}, // length past end
+		{0xFF, 0xD8, 0xFF, 0xE1, 0x00, 0x0A, 'E', 'x', 'i', 'f', 0, 0, 'M', 'M'},
+		[]byte("not a jpeg"),
+	} {
+		if got := exifOrientation(data); got != 1 {
+			t.Errorf("exifOrientation(%x) = %d, want 1", data, got)
+		}
+	}
+}
+
+func TestSquares_CropsAndScales(t *testing.T) {
+	// 300×100 landscape: center crop is x∈[100,200), all white.
+	src := quadrants(300, 100)
+	out := Squares(src, []int{32, 128, 64})
+
+	for _, size := range []int{32, 64, 128} {
+		img, ok := out[size]
+		if !ok {
+			t.Fatalf("missing size %d", size)
+		}
+		if b := img.Bounds(); b.Dx() != size || b.Dy() != size {
+			t.Errorf("size %d bounds = %v", size, b)
+		}
+		r, g, b, _ := img.At(size/2, size/2).RGBA()
+		if r>>8 != 255 || g>>8 != 255 || b>>8 != 255 {
+			t.Errorf("size %d center = (%d,%d,%d), want white", size, r>>8, g>>8, b>>8)
+		}
+	}
+}
+
+func TestSquares_UpscalesSmallSource(t *testing.T) {
+	out := Squares(quadrants(10, 10), []int{64})
+	if b := out[64].Bounds(); b.Dx() != 64 {
+		t.Fatalf("bounds = %v", b)
+	}
+	r, g, _, _ := out[64].At(2, 2).RGBA()
+	if r>>8 != 255 || g>>8 != 0 {
+		t.Errorf("top-left should stay red after upscale")
+	}
+}
+
+func TestEncodePNG_StripsMetadata(t *testing.T) {
+	img, err := Decode(jpegWithOrientation(t, quadrants(16, 16), 1), Limits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := EncodePNG(Squares(img, []int{16})[16])
+	if err != nil {
+		t.Fatalf("EncodePNG() error = %v", err)
+	}
+	if bytes.Contains(data, []byte("Exif")) {
+		t.Error("re-encoded image still contains EXIF")
+	}
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Errorf("output is not a valid PNG: %v", err)
+	}
+}
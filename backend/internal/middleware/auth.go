@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"context"
 	"strings"
 	"time"
 
@@ -18,18 +19,44 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
+// AccountStatusChecker reports whether a user may still use the API.
+// It returns an error (e.g. ACCOUNT_SUSPENDED) to reject the request.
+type AccountStatusChecker interface {
+	CheckAccountStatus(ctx context.Context, userID string) error
+}
+
+// JWTOption configures JWTAuth.
+type JWTOption func(*jwtOptions)
+
+type jwtOptions struct {
+	statusChecker AccountStatusChecker
+}
+
+// WithAccountStatus rejects otherwise valid tokens whose user has been
+// suspended or banned since the token was issued.
+func WithAccountStatus(checker AccountStatusChecker) JWTOption {
+	return func(o *jwtOptions) {
+		o.statusChecker = checker
+	}
+}
+
 // JWTAuth returns JWT authentication middleware.
-func JWTAuth(secret string) echo.MiddlewareFunc {
+func JWTAuth(secret string, opts ...JWTOption) echo.MiddlewareFunc {
+	var o jwtOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			authHeader := c.Request().Header.Get("Authorization")
 			if authHeader == "" {
-				return apperror.Unauthorized("Missing authorization header")
+				return apperror.Unauthorized("Missing authorization header").WithMessageID("error.auth.missing_header")
 			}
 
 			parts := strings.SplitN(authHeader, " ", 2)
 			if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-				return apperror.Unauthorized("Invalid authorization header format")
+				return apperror.Unauthorized("Invalid authorization header format").WithMessageID("error.auth.invalid_header")
 			}
 
 			tokenString := parts[1]
@@ -42,12 +69,18 @@ func JWTAuth(secret string) echo.MiddlewareFunc {
 			})
 
 			if err != nil {
-				return apperror.Unauthorized("Invalid token")
+				return apperror.Unauthorized("Invalid token").WithMessageID("error.auth.invalid_token")
 			}
 
 			claims, ok := token.Claims.(*Claims)
 			if !ok || !token.Valid {
-				return apperror.Unauthorized("Invalid token claims")
+				return apperror.Unauthorized("Invalid token claims").WithMessageID("error.auth.invalid_token_claims")
+			}
+
+			if o.statusChecker != nil {
+				if err := o.statusChecker.CheckAccountStatus(c.Request().Context(), claims.UserID); err != nil {
+					return err
+				}
 			}
 
 			c.Set("user_id", claims.UserID)
@@ -64,7 +97,7 @@ func RequireRole(roles ...string) echo.MiddlewareFunc {
 		return func(c echo.Context) error {
 			userType, ok := c.Get("user_type").(string)
 			if !ok {
-				return apperror.Unauthorized("User type not found")
+				return apperror.Unauthorized("")
 			}
 
 			for _, role := range roles {
@@ -73,7 +106,23 @@ func RequireRole(roles ...string) echo.MiddlewareFunc {
 				}
 			}
 
-			return apperror.Forbidden("Insufficient permissions")
+			return apperror.Forbidden("Insufficient permissions").WithMessageID("error.insufficient_permissions")
+		}
+	}
+}
+
+// OptionalAuth runs auth only for requests that carry an Authorization
+// header, so a public route can tailor its response to a signed-in caller.
+// A header with a bad token is still rejected rather than treated as
+// anonymous.
+func OptionalAuth(auth echo.MiddlewareFunc) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		authed := auth(next)
+		return func(c echo.Context) error {
+			if c.Request().Header.Get("Authorization") == "" {
+				return next(c)
+			}
+			return authed(c)
 		}
 	}
 }
@@ -1,12 +1,15 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
 )
 
 const testSecret = "test-secret-key-that-is-long-enough"
@@ -274,3 +277,98 @@ func TestRequireRole_Denied(t *testing.T) {
 		t.Error("RequireRole() expected error for denied role")
 	}
 }
+
+type statusCheckerFunc func(ctx context.Context, userID string) error
+
+func (f statusCheckerFunc) CheckAccountStatus(ctx context.Context, userID string) error {
+	return f(ctx, userID)
+}
+
+func TestJWTAuth_WithAccountStatus(t *testing.T) {
+	token, err := GenerateToken(testSecret, "user-123", "user", testIssuer, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		checkErr error
+		wantCode apperror.Code
+		wantNext bool
+	}{
+		{"active", nil, "", true},
+		{"suspended", apperror.AccountSuspended("Account suspended", nil), apperror.CodeAccountSuspended, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var checked string
+			checker := statusCheckerFunc(func(_ context.Context, userID string) error {
+				checked = userID
+				return tt.checkErr
+			})
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			called := false
+			err := JWTAuth(testSecret, WithAccountStatus(checker))(func(c echo.Context) error {
+				called = true
+				return nil
+			})(c)
+
+			if checked != "user-123" {
+				t.Errorf("checked user = %q, want user-123", checked)
+			}
+			if called != tt.wantNext {
+				t.Errorf("next called = %v, want %v", called, tt.wantNext)
+			}
+			if tt.wantCode != "" && !apperror.Is(err, tt.wantCode) {
+				t.Errorf("err = %v, want %s", err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestOptionalAuth(t *testing.T) {
+	token, err := GenerateToken(testSecret, "user-123", "user", testIssuer, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateToken() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		header   string
+		wantErr  bool
+		wantUser any
+	}{
+		{"anonymous", "", false, nil},
+		{"valid token", "Bearer " + token, false, "user-123"},
+		{"invalid token", "Bearer not-a-token", true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			c := e.NewContext(req, httptest.NewRecorder())
+
+			var gotUser any
+			handler := OptionalAuth(JWTAuth(testSecret))(func(c echo.Context) error {
+				gotUser = c.Get("user_id")
+				return nil
+			})
+
+			err := handler(c)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("OptionalAuth() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if gotUser != tt.wantUser {
+				t.Errorf("user_id = %v, want %v", gotUser, tt.wantUser)
+			}
+		})
+	}
+}
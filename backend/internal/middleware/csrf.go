@@ -23,7 +23,7 @@ func CSRF(enforce bool, log Logger) echo.MiddlewareFunc {
 			}
 
 			if enforce {
-				return apperror.Forbidden("CSRF check failed")
+				return apperror.Forbidden("CSRF check failed").WithMessageID("error.csrf")
 			}
 
 			args := []any{
@@ -48,7 +48,8 @@ func csrfBypass(c echo.Context) bool {
 		return true
 	}
 
-	return strings.HasPrefix(c.Path(), "/api/v1/webhooks/")
+	// Signed file URLs carry their own credential, not a session.
+	return strings.HasPrefix(c.Path(), "/api/v1/webhooks/") || c.Path() == fileContentRoute
 }
 
 func csrfHeaderValid(c echo.Context) bool {
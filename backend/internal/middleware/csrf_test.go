@@ -67,6 +67,13 @@ func TestCSRF(t *testing.T) {
 			enforce:    true,
 			wantStatus: http.StatusOK,
 		},
+		{
+			name:       "signed file content route bypasses",
+			method:     http.MethodPut,
+			path:       "/api/v1/files/:id/content",
+			enforce:    true,
+			wantStatus: http.StatusOK,
+		},
 		{
 			name:    "missing header enforce rejects",
 			method:  http.MethodPost,
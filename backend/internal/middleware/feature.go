@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/service/feature"
+)
+
+// FeatureChecker evaluates a feature flag for a subject. Satisfied by
+// *feature.FeatureService.
+type FeatureChecker interface {
+	IsEnabledFor(ctx context.Context, key string, subject feature.Subject) bool
+}
+
+// FeatureOption configures RequireFeature.
+type FeatureOption func(*featureOptions)
+
+type featureOptions struct {
+	disabledCode bool
+}
+
+// WithFeatureDisabledCode answers requests to a disabled feature with
+// 404 FEATURE_DISABLED instead of the router's plain 404, so clients can
+// tell a switched-off feature from a wrong URL.
+func WithFeatureDisabledCode() FeatureOption {
+	return func(o *featureOptions) {
+		o.disabledCode = true
+	}
+}
+
+// RequireFeature rejects requests unless the flag key is enabled for the
+// caller. The flag is evaluated on every request, so turning it off takes
+// effect without a restart. Mounted after JWTAuth, targeting rules see the
+// authenticated user; elsewhere the caller is evaluated as anonymous.
+// By default a disabled feature is indistinguishable from a route that
+// does not exist.
+func RequireFeature(flags FeatureChecker, key string, opts ...FeatureOption) echo.MiddlewareFunc {
+	var o featureOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			userID, _ := c.Get("user_id").(string)
+			userType, _ := c.Get("user_type").(string)
+			subject := feature.Subject{UserID: userID, UserType: userType}
+			if flags.IsEnabledFor(c.Request().Context(), key, subject) {
+				return next(c)
+			}
+			if o.disabledCode {
+				return apperror.FeatureDisabled()
+			}
+			return echo.ErrNotFound
+		}
+	}
+}
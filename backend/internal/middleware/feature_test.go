@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/service/feature"
+)
+
+type stubFeatureChecker struct {
+	enabled map[string]bool
+	got     feature.Subject
+}
+
+func (s *stubFeatureChecker) IsEnabledFor(_ context.Context, key string, subject feature.Subject) bool {
+	s.got = subject
+	return s.enabled[subject.UserID+":"+key]
+}
+
+func TestRequireFeature(t *testing.T) {
+	tests := []struct {
+		name     string
+		userID   string
+		userType string
+		opts     []FeatureOption
+		wantErr  error
+		wantCode apperror.Code
+	}{
+		{"enabled for user", "user-1", "user", nil, nil, ""},
+		{"disabled for user", "user-2", "user", nil, echo.ErrNotFound, ""},
+		{"anonymous", "", "", nil, echo.ErrNotFound, ""},
+		{"disabled code", "user-2", "user", []FeatureOption{WithFeatureDisabledCode()}, nil, apperror.CodeFeatureDisabled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags := &stubFeatureChecker{enabled: map[string]bool{"user-1:reports": true}}
+			e := echo.New()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/reports", nil), httptest.NewRecorder())
+			if tt.userID != "" {
+				c.Set("user_id", tt.userID)
+				c.Set("user_type", tt.userType)
+			}
+
+			called := false
+			err := RequireFeature(flags, "reports", tt.opts...)(func(echo.Context) error {
+				called = true
+				return nil
+			})(c)
+
+			if flags.got != (feature.Subject{UserID: tt.userID, UserType: tt.userType}) {
+				t.Errorf("subject = %+v", flags.got)
+			}
+			switch {
+			case tt.wantCode != "":
+				if !apperror.Is(err, tt.wantCode) || apperror.HTTPStatus(err) != http.StatusNotFound {
+					t.Errorf("err = %v, want 404 %s", err, tt.wantCode)
+				}
+			case !errors.Is(err, tt.wantErr):
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if called != (err == nil) {
+				t.Errorf("handler called = %v with err = %v", called, err)
+			}
+		})
+	}
+}
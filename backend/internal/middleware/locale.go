@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/i18n"
+	"github.com/golid-ai/golid/backend/internal/logger"
+)
+
+// LocaleReader returns a user's stored locale, or "" when they have not
+// chosen one. Satisfied by *preference.PreferenceService.
+type LocaleReader interface {
+	Locale(ctx context.Context, userID string) (string, error)
+}
+
+const (
+	localeKey       = "locale"
+	localeReaderKey = "locale_reader"
+)
+
+// Locale makes the user's stored locale available to RequestLocale. It
+// does no work itself: the preference is read only when something needs
+// the locale (an error response, an email), and at most once per request.
+func Locale(prefs LocaleReader) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set(localeReaderKey, prefs)
+			return next(c)
+		}
+	}
+}
+
+// RequestLocale returns the locale for the current request: the
+// authenticated user's stored preference, then Accept-Language, then
+// i18n.DefaultLocale. Without the Locale middleware, or before JWTAuth has
+// identified the user, only Accept-Language is consulted.
+func RequestLocale(c echo.Context) string {
+	if locale, ok := c.Get(localeKey).(string); ok {
+		return locale
+	}
+
+	tags := i18n.ParseAcceptLanguage(c.Request().Header.Get("Accept-Language"))
+	prefs, _ := c.Get(localeReaderKey).(LocaleReader)
+	userID, _ := c.Get("user_id").(string)
+	if prefs == nil || userID == "" {
+		return i18n.Match(tags...)
+	}
+
+	stored, err := prefs.Locale(c.Request().Context(), userID)
+	if err != nil {
+		logger.FromEcho(c).Warn("failed to read locale preference", slog.String("error", err.Error()))
+	}
+	locale := i18n.Match(append([]string{stored}, tags...)...)
+	c.Set(localeKey, locale)
+	return locale
+}
@@ -0,0 +1,153 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+)
+
+type stubLocaleReader struct {
+	locale string
+	err    error
+	calls  int
+}
+
+func (s *stubLocaleReader) Locale(_ context.Context, _ string) (string, error) {
+	s.calls++
+	return s.locale, s.err
+}
+
+func localeContext(acceptLanguage string, prefs LocaleReader, userID string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if acceptLanguage != "" {
+		req.Header.Set("Accept-Language", acceptLanguage)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	if prefs != nil {
+		_ = Locale(prefs)(func(echo.Context) error { return nil })(c)
+	}
+	if userID != "" {
+		c.Set("user_id", userID)
+	}
+	return c, rec
+}
+
+func TestRequestLocale(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		prefs  *stubLocaleReader
+		userID string
+		want   string
+	}{
+		{"default", "", nil, "", "en"},
+		{"accept-language", "de, es-MX;q=0.8", nil, "", "es"},
+		{"anonymous ignores preference", "es", &stubLocaleReader{locale: "pt-BR"}, "", "es"},
+		{"stored preference wins", "es", &stubLocaleReader{locale: "pt-BR"}, "user-1", "pt-BR"},
+		{"unset preference", "es", &stubLocaleReader{}, "user-1", "es"},
+		{"lookup error", "es", &stubLocaleReader{err: errors.New("db down")}, "user-1", "es"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var prefs LocaleReader
+			if tt.prefs != nil {
+				prefs = tt.prefs
+			}
+			c, _ := localeContext(tt.header, prefs, tt.userID)
+			if got := RequestLocale(c); got != tt.want {
+				t.Errorf("RequestLocale() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRequestLocale_ReadsPreferenceOnce(t *testing.T) {
+	prefs := &stubLocaleReader{locale: "es"}
+	c, _ := localeContext("", prefs, "user-1")
+	RequestLocale(c)
+	RequestLocale(c)
+	if prefs.calls != 1 {
+		t.Errorf("Locale called %d times, want 1", prefs.calls)
+	}
+}
+
+func TestErrorHandler_Localized(t *testing.T) {
+	c, rec := localeContext("pt-BR,pt;q=0.9", nil, "")
+	shared := apperror.NotFound("User")
+	ErrorHandler(shared, c)
+
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse body: %v", err)
+	}
+	if body["message"] != "Usuário não encontrado" || body["message_id"] != "error.not_found.user" {
+		t.Errorf("body = %v", body)
+	}
+	if got := rec.Header().Get("Content-Language"); got != "pt-BR" {
+		t.Errorf("Content-Language = %q, want pt-BR", got)
+	}
+	if shared.Message != "User not found" {
+		t.Errorf("ErrorHandler modified the error: %q", shared.Message)
+	}
+}
+
+func TestErrorHandler_LocalizedDetails(t *testing.T) {
+	c, rec := localeContext("es", nil, "")
+	shared := apperror.Validation().WithDetail("email", "error.auth.email_required")
+	ErrorHandler(shared, c)
+
+	var body struct {
+		Message string            `json:"message"`
+		Details map[string]string `json:"details"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse body: %v", err)
+	}
+	if body.Message != "La validación falló" {
+		t.Errorf("message = %q", body.Message)
+	}
+	if body.Details["email"] != "El correo electrónico es obligatorio" {
+		t.Errorf("details = %v", body.Details)
+	}
+	if shared.Details["email"] != "Email is required" {
+		t.Errorf("ErrorHandler modified the details: %v", shared.Details)
+	}
+}
+
+func TestErrorHandler_UntranslatedKeepsMessage(t *testing.T) {
+	c, rec := localeContext("es", nil, "")
+	ErrorHandler(apperror.NotFound("Widget"), c)
+
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse body: %v", err)
+	}
+	if body["message"] != "Widget not found" {
+		t.Errorf("message = %v, want English fallback", body["message"])
+	}
+	if rec.Header().Get("Content-Language") != "" {
+		t.Error("Content-Language set for an untranslated message")
+	}
+}
+
+func TestErrorHandler_InternalLocalized(t *testing.T) {
+	c, rec := localeContext("es", nil, "")
+	ErrorHandler(apperror.Internal(errors.New("secret")), c)
+
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to parse body: %v", err)
+	}
+	if body["message"] != "Se produjo un error interno" {
+		t.Errorf("message = %v", body["message"])
+	}
+}
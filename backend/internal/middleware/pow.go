@@ -0,0 +1,299 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/logger"
+	"github.com/golid-ai/golid/backend/internal/pow"
+)
+
+// powStore counts auth failures per key and remembers which challenges
+// have already been spent. Implementations must be safe for concurrent use.
+type powStore interface {
+	Failures(key string) int64
+	RecordFailure(key string)
+	Reset(key string)
+	// MarkUsed records a solved challenge ID until ttl elapses. Returns
+	// false if the ID was already used (replay).
+	MarkUsed(id string, ttl time.Duration) bool
+}
+
+// ProofOfWork returns middleware for auth endpoints that demands a solved
+// proof-of-work challenge once auth failures from the caller's IP, or for
+// the account named in the request body, reach threshold within window.
+// Below the threshold requests pass through untouched, so users behind a
+// shared NAT are asked to burn a few seconds of CPU instead of being
+// locked out the way StrictRateLimiter would.
+//
+// A failure is a 401 from the wrapped handler. A successful response
+// clears the account counter but never the IP counter (an attacker could
+// otherwise reset it by logging into their own account). A threshold <= 0
+// disables the middleware.
+//
+// Uses Redis when configured (shared across instances), falls back to
+// in-memory — same selection rule as RateLimiter.
+func ProofOfWork(issuer *pow.Issuer, threshold int, window time.Duration) echo.MiddlewareFunc {
+	if issuer == nil || threshold <= 0 {
+		return func(next echo.HandlerFunc) echo.HandlerFunc { return next }
+	}
+	var store powStore
+	if redisClient != nil {
+		store = newRedisPoWStore(redisClient, window)
+	} else {
+		store = newMemoryPoWStore(window)
+	}
+	return proofOfWork(issuer, int64(threshold), store)
+}
+
+func proofOfWork(issuer *pow.Issuer, threshold int64, store powStore) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			ipKey := "ip:" + c.RealIP()
+			acctKey := ""
+			if email := peekEmail(c); email != "" {
+				acctKey = "acct:" + email
+			}
+
+			if store.Failures(ipKey) >= threshold || (acctKey != "" && store.Failures(acctKey) >= threshold) {
+				if err := verifyChallenge(c, issuer, store); err != nil {
+					return err
+				}
+			}
+
+			err := next(c)
+
+			status := c.Response().Status
+			if err != nil {
+				status = errorStatus(err)
+			}
+			switch {
+			case status == http.StatusUnauthorized:
+				store.RecordFailure(ipKey)
+				if acctKey != "" {
+					store.RecordFailure(acctKey)
+				}
+			case status < 400 && acctKey != "":
+				store.Reset(acctKey)
+			}
+			return err
+		}
+	}
+}
+
+// verifyChallenge checks the X-PoW-* headers and burns the challenge.
+func verifyChallenge(c echo.Context, issuer *pow.Issuer, store powStore) error {
+	token := c.Request().Header.Get(pow.HeaderChallenge)
+	solution := c.Request().Header.Get(pow.HeaderSolution)
+	if token == "" || solution == "" {
+		return apperror.ChallengeRequired("")
+	}
+
+	solved, err := issuer.Verify(token, solution)
+	if err != nil {
+		logger.FromEcho(c).Warn("proof-of-work rejected",
+			slog.String("ip", c.RealIP()),
+			slog.String("error", err.Error()),
+		)
+		return apperror.ChallengeRequired("Invalid or expired proof-of-work solution").WithMessageID("error.challenge_invalid")
+	}
+	if !store.MarkUsed(solved.ID, time.Until(solved.ExpiresAt)) {
+		return apperror.ChallengeRequired("Proof-of-work challenge already used").WithMessageID("error.challenge_used")
+	}
+	return nil
+}
+
+// peekEmail reads the "email" field from a JSON request body without
+// consuming it, so the account can be tracked alongside the IP. The body
+// is already capped by the global BodyLimit middleware.
+func peekEmail(c echo.Context) string {
+	req := c.Request()
+	if req.Body == nil || !strings.HasPrefix(req.Header.Get(echo.HeaderContentType), echo.MIMEApplicationJSON) {
+		return ""
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	if err != nil {
+		return ""
+	}
+	var body struct {
+		Email string `json:"email"`
+	}
+	if json.Unmarshal(data, &body) != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(body.Email))
+}
+
+// errorStatus maps a handler error to the status ErrorHandler will write.
+func errorStatus(err error) int {
+	var echoErr *echo.HTTPError
+	if errors.As(err, &echoErr) {
+		return echoErr.Code
+	}
+	return apperror.HTTPStatus(err)
+}
+
+// ----------------------------------------------------------------------------
+// In-memory store
+// ----------------------------------------------------------------------------
+
+type memoryPoWStore struct {
+	mu        sync.Mutex
+	window    time.Duration
+	failures  map[string]*failureWindow
+	used      map[string]time.Time
+	lastSweep time.Time
+}
+
+type failureWindow struct {
+	count     int64
+	expiresAt time.Time
+}
+
+func newMemoryPoWStore(window time.Duration) *memoryPoWStore {
+	return &memoryPoWStore{
+		window:    window,
+		failures:  make(map[string]*failureWindow),
+		used:      make(map[string]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+func (s *memoryPoWStore) Failures(key string) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if f, ok := s.failures[key]; ok && time.Now().Before(f.expiresAt) {
+		return f.count
+	}
+	return 0
+}
+
+// RecordFailure uses a fixed window like RedisRateLimiterStore: the first
+// failure starts the window, later ones only bump the count.
+func (s *memoryPoWStore) RecordFailure(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.sweepLocked(now)
+	f, ok := s.failures[key]
+	if !ok || now.After(f.expiresAt) {
+		s.failures[key] = &failureWindow{count: 1, expiresAt: now.Add(s.window)}
+		return
+	}
+	f.count++
+}
+
+func (s *memoryPoWStore) Reset(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.failures, key)
+}
+
+func (s *memoryPoWStore) MarkUsed(id string, ttl time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	s.sweepLocked(now)
+	if exp, ok := s.used[id]; ok && now.Before(exp) {
+		return false
+	}
+	s.used[id] = now.Add(ttl)
+	return true
+}
+
+// sweepLocked drops expired entries at most once per window so the maps
+// can't grow without bound under a spray of distinct IPs. Must be called
+// with mu held.
+func (s *memoryPoWStore) sweepLocked(now time.Time) {
+	if now.Sub(s.lastSweep) < s.window {
+		return
+	}
+	for k, f := range s.failures {
+		if now.After(f.expiresAt) {
+			delete(s.failures, k)
+		}
+	}
+	for id, exp := range s.used {
+		if now.After(exp) {
+			delete(s.used, id)
+		}
+	}
+	s.lastSweep = now
+}
+
+// ----------------------------------------------------------------------------
+// Redis store
+// ----------------------------------------------------------------------------
+
+type redisPoWStore struct {
+	client *redis.Client
+	window time.Duration
+}
+
+func newRedisPoWStore(client *redis.Client, window time.Duration) *redisPoWStore {
+	return &redisPoWStore{client: client, window: window}
+}
+
+func (s *redisPoWStore) Failures(key string) int64 {
+	n, err := s.client.Get(context.Background(), "pow:fail:"+key).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		logger.Error("redis pow store error, failing open",
+			slog.String("key", key),
+			slog.String("error", err.Error()),
+		)
+	}
+	return n
+}
+
+func (s *redisPoWStore) RecordFailure(key string) {
+	ctx := context.Background()
+	k := "pow:fail:" + key
+	pipe := s.client.TxPipeline()
+	pipe.Incr(ctx, k)
+	pipe.ExpireNX(ctx, k, s.window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		logger.Error("redis pow store error",
+			slog.String("key", key),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+func (s *redisPoWStore) Reset(key string) {
+	if err := s.client.Del(context.Background(), "pow:fail:"+key).Err(); err != nil {
+		logger.Error("redis pow store error",
+			slog.String("key", key),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// MarkUsed fails closed on Redis errors: a replay is worse than asking
+// the client to solve another challenge.
+func (s *redisPoWStore) MarkUsed(id string, ttl time.Duration) bool {
+	if ttl <= 0 {
+		ttl = time.Second
+	}
+	ok, err := s.client.SetNX(context.Background(), "pow:used:"+id, 1, ttl).Result()
+	if err != nil {
+		logger.Error("redis pow store error",
+			slog.String("challenge_id", id),
+			slog.String("error", err.Error()),
+		)
+		return false
+	}
+	return ok
+}
@@ -0,0 +1,211 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/pow"
+)
+
+const powTestSecret = "pow-middleware-test-secret-32-chars!"
+
+// powTestHandler returns 401 for password "wrong" and 200 otherwise.
+func powTestHandler(c echo.Context) error {
+	var body struct {
+		Password string `json:"password"`
+	}
+	if err := c.Bind(&body); err != nil {
+		return apperror.InvalidBody()
+	}
+	if body.Password == "wrong" {
+		return apperror.Unauthorized("Invalid email or password")
+	}
+	return c.NoContent(http.StatusOK)
+}
+
+func runPoW(t *testing.T, mw echo.MiddlewareFunc, body string, headers map[string]string) error {
+	t.Helper()
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.RemoteAddr = "203.0.113.7:1234"
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	return mw(powTestHandler)(c)
+}
+
+func solvedHeaders(t *testing.T, issuer *pow.Issuer) map[string]string {
+	t.Helper()
+	ch, err := issuer.Issue()
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	return map[string]string{
+		pow.HeaderChallenge: ch.Challenge,
+		pow.HeaderSolution:  pow.Solve(ch.Challenge, ch.Difficulty),
+	}
+}
+
+func TestProofOfWork_DisabledWhenThresholdZero(t *testing.T) {
+	mw := ProofOfWork(pow.NewIssuer(powTestSecret, 4, time.Minute), 0, time.Minute)
+	for i := 0; i < 10; i++ {
+		err := runPoW(t, mw, `{"email":"a@b.co","password":"wrong"}`, nil)
+		if !apperror.Is(err, apperror.CodeUnauthorized) {
+			t.Fatalf("attempt %d: err = %v, want Unauthorized", i+1, err)
+		}
+	}
+}
+
+func TestProofOfWork_RequiresChallengeAfterThreshold(t *testing.T) {
+	issuer := pow.NewIssuer(powTestSecret, 4, time.Minute)
+	mw := proofOfWork(issuer, 3, newMemoryPoWStore(time.Minute))
+
+	for i := 0; i < 3; i++ {
+		err := runPoW(t, mw, `{"email":"a@b.co","password":"wrong"}`, nil)
+		if !apperror.Is(err, apperror.CodeUnauthorized) {
+			t.Fatalf("attempt %d: err = %v, want Unauthorized", i+1, err)
+		}
+	}
+
+	err := runPoW(t, mw, `{"email":"a@b.co","password":"right"}`, nil)
+	if !apperror.Is(err, apperror.CodeChallengeRequired) {
+		t.Fatalf("err = %v, want ChallengeRequired", err)
+	}
+
+	if err := runPoW(t, mw, `{"email":"a@b.co","password":"right"}`, solvedHeaders(t, issuer)); err != nil {
+		t.Fatalf("solved request err = %v, want nil", err)
+	}
+}
+
+func TestProofOfWork_RejectsReplayedSolution(t *testing.T) {
+	issuer := pow.NewIssuer(powTestSecret, 4, time.Minute)
+	store := newMemoryPoWStore(time.Minute)
+	store.RecordFailure("ip:203.0.113.7")
+	mw := proofOfWork(issuer, 1, store)
+
+	headers := solvedHeaders(t, issuer)
+	if err := runPoW(t, mw, `{"password":"right"}`, headers); err != nil {
+		t.Fatalf("first use err = %v", err)
+	}
+	err := runPoW(t, mw, `{"password":"right"}`, headers)
+	if !apperror.Is(err, apperror.CodeChallengeRequired) {
+		t.Fatalf("replay err = %v, want ChallengeRequired", err)
+	}
+}
+
+func TestProofOfWork_RejectsBadSolution(t *testing.T) {
+	issuer := pow.NewIssuer(powTestSecret, 24, time.Minute)
+	store := newMemoryPoWStore(time.Minute)
+	store.RecordFailure("ip:203.0.113.7")
+	mw := proofOfWork(issuer, 1, store)
+
+	ch, _ := issuer.Issue()
+	err := runPoW(t, mw, `{}`, map[string]string{
+		pow.HeaderChallenge: ch.Challenge,
+		pow.HeaderSolution:  "not-a-solution",
+	})
+	if !apperror.Is(err, apperror.CodeChallengeRequired) {
+		t.Fatalf("err = %v, want ChallengeRequired", err)
+	}
+}
+
+func TestProofOfWork_AccountThreshold(t *testing.T) {
+	issuer := pow.NewIssuer(powTestSecret, 4, time.Minute)
+	store := newMemoryPoWStore(time.Minute)
+	store.RecordFailure("acct:victim@example.com")
+	store.RecordFailure("acct:victim@example.com")
+	mw := proofOfWork(issuer, 2, store)
+
+	// Same IP, different account — not challenged.
+	if err := runPoW(t, mw, `{"email":"other@example.com","password":"right"}`, nil); err != nil {
+		t.Fatalf("other account err = %v, want nil", err)
+	}
+	// Targeted account is challenged regardless of case.
+	err := runPoW(t, mw, `{"email":"Victim@Example.com","password":"right"}`, nil)
+	if !apperror.Is(err, apperror.CodeChallengeRequired) {
+		t.Fatalf("err = %v, want ChallengeRequired", err)
+	}
+}
+
+func TestProofOfWork_SuccessResetsAccountNotIP(t *testing.T) {
+	issuer := pow.NewIssuer(powTestSecret, 4, time.Minute)
+	store := newMemoryPoWStore(time.Minute)
+	mw := proofOfWork(issuer, 5, store)
+
+	_ = runPoW(t, mw, `{"email":"a@b.co","password":"wrong"}`, nil)
+	if err := runPoW(t, mw, `{"email":"a@b.co","password":"right"}`, nil); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+
+	if got := store.Failures("acct:a@b.co"); got != 0 {
+		t.Errorf("account failures = %d, want 0 after success", got)
+	}
+	if got := store.Failures("ip:203.0.113.7"); got != 1 {
+		t.Errorf("ip failures = %d, want 1", got)
+	}
+}
+
+func TestProofOfWork_HandlerStillReadsBody(t *testing.T) {
+	mw := proofOfWork(pow.NewIssuer(powTestSecret, 4, time.Minute), 5, newMemoryPoWStore(time.Minute))
+	err := runPoW(t, mw, `{"email":"a@b.co","password":"wrong"}`, nil)
+	if !apperror.Is(err, apperror.CodeUnauthorized) {
+		t.Fatalf("err = %v, want Unauthorized (handler must see the body)", err)
+	}
+}
+
+func TestMemoryPoWStore_WindowExpiry(t *testing.T) {
+	store := newMemoryPoWStore(10 * time.Millisecond)
+	store.RecordFailure("k")
+	store.RecordFailure("k")
+	if got := store.Failures("k"); got != 2 {
+		t.Fatalf("Failures = %d, want 2", got)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got := store.Failures("k"); got != 0 {
+		t.Errorf("Failures after window = %d, want 0", got)
+	}
+}
+
+func TestRedisPoWStore_CountsAndReplay(t *testing.T) {
+	client, _ := newTestRedisClient(t)
+	store := newRedisPoWStore(client, time.Minute)
+
+	store.RecordFailure("ip:1.2.3.4")
+	store.RecordFailure("ip:1.2.3.4")
+	if got := store.Failures("ip:1.2.3.4"); got != 2 {
+		t.Errorf("Failures = %d, want 2", got)
+	}
+	store.Reset("ip:1.2.3.4")
+	if got := store.Failures("ip:1.2.3.4"); got != 0 {
+		t.Errorf("Failures after reset = %d, want 0", got)
+	}
+
+	if !store.MarkUsed("abc", time.Minute) {
+		t.Error("first MarkUsed should succeed")
+	}
+	if store.MarkUsed("abc", time.Minute) {
+		t.Error("second MarkUsed should report replay")
+	}
+}
+
+func TestRedisPoWStore_FailsClosedOnReplayCheck(t *testing.T) {
+	client, mr := newTestRedisClient(t)
+	store := newRedisPoWStore(client, time.Minute)
+	mr.Close()
+
+	if store.MarkUsed("abc", time.Minute) {
+		t.Error("MarkUsed should fail closed when Redis is down")
+	}
+	if got := store.Failures("ip:1.2.3.4"); got != 0 {
+		t.Errorf("Failures should fail open (0) when Redis is down, got %d", got)
+	}
+}
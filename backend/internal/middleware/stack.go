@@ -3,6 +3,7 @@ package middleware
 import (
 	"errors"
 	"log/slog"
+	"maps"
 	"strings"
 	"sync"
 	"time"
@@ -14,9 +15,30 @@ import (
 
 	"github.com/golid-ai/golid/backend/internal/apperror"
 	"github.com/golid-ai/golid/backend/internal/config"
+	"github.com/golid-ai/golid/backend/internal/i18n"
 	"github.com/golid-ai/golid/backend/internal/logger"
+	"github.com/golid-ai/golid/backend/internal/pow"
 )
 
+// fileContentRoute streams upload/download bodies authorized by a signed
+// URL. The file service enforces its own size limit and the transfer may
+// legitimately outlast RequestTimeout, so the global body limit and
+// timeout skip it.
+const fileContentRoute = "/api/v1/files/:id/content"
+
+// avatarUploadRoute accepts images larger than the global body limit; the
+// avatar handler caps the body at AvatarMaxUploadSize itself.
+const avatarUploadRoute = "/api/v1/me/avatar"
+
+// exportDownloadRoute streams a data export archive, which may outlast
+// RequestTimeout on slow connections.
+const exportDownloadRoute = "/api/v1/exports/:id/download"
+
+// eventSocketRoute upgrades to a long-lived WebSocket, which hijacks the
+// connection: a gzip or timeout writer wrapped around it would break the
+// upgrade.
+const eventSocketRoute = "/api/v1/events/ws"
+
 // Setup configures all middleware for the Echo server.
 func Setup(e *echo.Echo, cfg *config.Config) {
 	// Request ID (first, so it's available for logging)
@@ -60,19 +82,24 @@ func Setup(e *echo.Echo, cfg *config.Config) {
 			return false, nil
 		},
 		AllowMethods:     []string{echo.GET, echo.POST, echo.PUT, echo.PATCH, echo.DELETE, echo.OPTIONS},
-		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization},
+		AllowHeaders:     []string{echo.HeaderOrigin, echo.HeaderContentType, echo.HeaderAccept, echo.HeaderAuthorization, pow.HeaderChallenge, pow.HeaderSolution},
 		AllowCredentials: true,
 		MaxAge:           86400, // 24 hours
 	}))
 
 	// Request body size limit (prevents memory exhaustion from oversized payloads)
-	e.Use(middleware.BodyLimit("1M"))
+	e.Use(middleware.BodyLimitWithConfig(middleware.BodyLimitConfig{
+		Limit: "1M",
+		Skipper: func(c echo.Context) bool {
+			return c.Path() == fileContentRoute || c.Path() == avatarUploadRoute
+		},
+	}))
 
-	// Gzip compression (skip for SSE streams — long-lived connections)
+	// Gzip compression (skip for SSE streams and WebSockets — long-lived connections)
 	e.Use(middleware.GzipWithConfig(middleware.GzipConfig{
 		Level: 5,
 		Skipper: func(c echo.Context) bool {
-			return strings.HasPrefix(c.Path(), "/api/v1/events/stream")
+			return strings.HasPrefix(c.Path(), "/api/v1/events/stream") || c.Path() == eventSocketRoute
 		},
 	}))
 
@@ -112,13 +139,13 @@ func RateLimiter(requests int, window time.Duration) echo.MiddlewareFunc {
 			return c.RealIP(), nil
 		},
 		ErrorHandler: func(c echo.Context, err error) error {
-			return c.JSON(apperror.HTTPStatus(apperror.RateLimited()), apperror.RateLimited())
+			return apperror.RateLimited()
 		},
 		DenyHandler: func(c echo.Context, identifier string, err error) error {
 			logger.FromEcho(c).Warn("rate limit exceeded",
 				slog.String("ip", identifier),
 			)
-			return c.JSON(apperror.HTTPStatus(apperror.RateLimited()), apperror.RateLimited())
+			return apperror.RateLimited()
 		},
 	})
 }
@@ -136,7 +163,7 @@ func Timeout(duration time.Duration) echo.MiddlewareFunc {
 		Timeout:      duration,
 		ErrorMessage: "Request timeout exceeded",
 		Skipper: func(c echo.Context) bool {
-			return strings.HasPrefix(c.Path(), "/api/v1/events/stream")
+			return strings.HasPrefix(c.Path(), "/api/v1/events/stream") || c.Path() == eventSocketRoute || c.Path() == fileContentRoute || c.Path() == exportDownloadRoute
 		},
 	})
 }
@@ -166,6 +193,7 @@ func RequestLogger() echo.MiddlewareFunc {
 }
 
 // ErrorHandler is a custom error handler that returns structured errors.
+// Messages and details with a catalog ID are translated for RequestLocale.
 func ErrorHandler(err error, c echo.Context) {
 	if c.Response().Committed {
 		return
@@ -178,14 +206,29 @@ func ErrorHandler(err error, c echo.Context) {
 				slog.String("error", appErr.Error()),
 			)
 			if err := c.JSON(appErr.HTTPStatus, map[string]interface{}{
-				"code":    appErr.Code,
-				"message": "An internal error occurred",
+				"code":       appErr.Code,
+				"message":    localizedMessage(c, "error.internal", "An internal error occurred"),
+				"message_id": "error.internal",
 			}); err != nil {
 				logger.FromEcho(c).Error("failed to write error response", slog.String("error", err.Error()))
 			}
 			return
 		}
 
+		if appErr.MessageID != "" || len(appErr.DetailIDs) > 0 {
+			// Copy: constructors may hand out shared values.
+			localized := *appErr
+			if appErr.MessageID != "" {
+				localized.Message = localizedMessage(c, appErr.MessageID, appErr.Message, appErr.Args...)
+			}
+			if len(appErr.DetailIDs) > 0 {
+				localized.Details = maps.Clone(appErr.Details)
+				for field, d := range appErr.DetailIDs {
+					localized.Details[field] = localizedMessage(c, d.ID, appErr.Details[field], d.Args...)
+				}
+			}
+			appErr = &localized
+		}
 		if err := c.JSON(appErr.HTTPStatus, appErr); err != nil {
 			logger.FromEcho(c).Error("failed to write error response", slog.String("error", err.Error()))
 		}
@@ -211,9 +254,23 @@ func ErrorHandler(err error, c echo.Context) {
 		slog.String("error", err.Error()),
 	)
 	if err := c.JSON(500, map[string]interface{}{
-		"code":    apperror.CodeInternal,
-		"message": "An internal error occurred",
+		"code":       apperror.CodeInternal,
+		"message":    localizedMessage(c, "error.internal", "An internal error occurred"),
+		"message_id": "error.internal",
 	}); err != nil {
 		logger.FromEcho(c).Error("failed to write error response", slog.String("error", err.Error()))
 	}
 }
+
+// localizedMessage translates id for the request, falling back to the
+// English message when no catalog has it. Sets Content-Language when a
+// translation is used.
+func localizedMessage(c echo.Context, id, fallback string, args ...any) string {
+	locale := RequestLocale(c)
+	msg, ok := i18n.Lookup(locale, id, args...)
+	if !ok {
+		return fallback
+	}
+	c.Response().Header().Set("Content-Language", locale)
+	return msg
+}
@@ -3,8 +3,11 @@ package middleware
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -153,9 +156,7 @@ func TestErrorHandler_ValidationError_IncludesDetails(t *testing.T) {
 	rec := httptest.NewRecorder()
 	c := e.NewContext(req, rec)
 
-	err := apperror.Validation("Validation failed", map[string]string{
-		"email": "Email is required",
-	})
+	err := apperror.Validation().WithDetail("email", "error.auth.email_required")
 	ErrorHandler(err, c)
 
 	if rec.Code != http.StatusBadRequest {
@@ -228,6 +229,27 @@ func TestTimeout_SkipsSSEStream(t *testing.T) {
 	}
 }
 
+func TestTimeout_SkipsExportDownload(t *testing.T) {
+	e := echo.New()
+	mw := Timeout(1 * time.Millisecond)
+
+	handler := mw(func(c echo.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		return c.String(http.StatusOK, "archive")
+	})
+
+	rec := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/exports/abc/download", nil), rec)
+	c.SetPath(exportDownloadRoute)
+
+	if err := handler(c); err != nil {
+		t.Fatalf("Timeout() should skip export downloads, got error: %v", err)
+	}
+	if rec.Body.String() != "archive" {
+		t.Errorf("body = %q, want archive", rec.Body.String())
+	}
+}
+
 func TestTimeout_ExceedsDeadline(t *testing.T) {
 	e := echo.New()
 	mw := Timeout(10 * time.Millisecond)
@@ -411,3 +433,46 @@ func TestRequestLogger_HandlesError(t *testing.T) {
 		t.Errorf("RequestLogger should swallow errors after calling c.Error, got: %v", err)
 	}
 }
+
+func TestSetup_BodyLimitSkipsFileContent(t *testing.T) {
+	e := echo.New()
+	cfg := &config.Config{
+		AllowedOrigins: []string{"http://localhost:3000"},
+		RequestTimeout: 30 * time.Second,
+	}
+	Setup(e, cfg)
+
+	read := func(c echo.Context) error {
+		n, err := io.Copy(io.Discard, c.Request().Body)
+		if err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, strconv.FormatInt(n, 10))
+	}
+	e.PUT("/api/v1/files/:id/content", read)
+	e.POST("/api/v1/me/avatar", read)
+	e.PUT("/api/v1/other", read)
+
+	body := strings.Repeat("x", 2<<20)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/files/abc/content", strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("file content status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/me/avatar", strings.NewReader(body))
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("avatar upload status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	req = httptest.NewRequest(http.MethodPut, "/api/v1/other", strings.NewReader(body))
+	rec = httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("other route status = %d, want %d", rec.Code, http.StatusRequestEntityTooLarge)
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
 )
 
 // UserType represents the type of user account.
@@ -20,18 +22,68 @@ const (
 	UserTypeAdmin UserType = "admin"
 )
 
+// UserStatus represents whether an account may sign in.
+type UserStatus string
+
+const (
+	UserStatusActive    UserStatus = "active"
+	UserStatusSuspended UserStatus = "suspended"
+	UserStatusBanned    UserStatus = "banned"
+)
+
+// Valid reports whether s is a value of the user_status enum.
+func (s UserStatus) Valid() bool {
+	switch s {
+	case UserStatusActive, UserStatusSuspended, UserStatusBanned:
+		return true
+	}
+	return false
+}
+
+// Effective returns the status in force at now. A suspension whose
+// suspended_until has passed counts as active.
+func (s UserStatus) Effective(suspendedUntil *time.Time, now time.Time) UserStatus {
+	if s == UserStatusSuspended && (suspendedUntil == nil || !now.Before(*suspendedUntil)) {
+		return UserStatusActive
+	}
+	return s
+}
+
+// AccountStatusError returns an ACCOUNT_SUSPENDED error if the account is
+// suspended or banned at now, or nil if it may be used.
+func AccountStatusError(status UserStatus, suspendedUntil *time.Time, now time.Time) error {
+	switch status.Effective(suspendedUntil, now) {
+	case UserStatusSuspended:
+		until := suspendedUntil.UTC().Format(time.RFC3339)
+		return apperror.AccountSuspended("Your account is suspended until "+until, map[string]string{
+			"status":          string(UserStatusSuspended),
+			"suspended_until": until,
+		}).WithMessageID("error.account.suspended_until", suspendedUntil.UTC())
+	case UserStatusBanned:
+		return apperror.AccountSuspended("Your account has been banned", map[string]string{
+			"status": string(UserStatusBanned),
+		}).WithMessageID("error.account.banned")
+	}
+	return nil
+}
+
 // User represents a row in the users table.
 type User struct {
-	ID            uuid.UUID `json:"id"`
-	Email         string    `json:"email"`
-	PasswordHash  string    `json:"-"`
-	Type          UserType  `json:"type"`
-	EmailVerified bool      `json:"email_verified"`
-	FirstName     *string   `json:"first_name,omitempty"`
-	LastName      *string   `json:"last_name,omitempty"`
-	AvatarURL     *string   `json:"avatar_url,omitempty"`
-	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+	ID              uuid.UUID  `json:"id"`
+	Email           string     `json:"email"`
+	PasswordHash    string     `json:"-"`
+	Type            UserType   `json:"type"`
+	EmailVerified   bool       `json:"email_verified"`
+	FirstName       *string    `json:"first_name,omitempty"`
+	LastName        *string    `json:"last_name,omitempty"`
+	AvatarURL       *string    `json:"avatar_url,omitempty"`
+	Status          UserStatus `json:"status"`
+	SuspendedUntil  *time.Time `json:"suspended_until,omitempty"`
+	StatusReason    *string    `json:"status_reason,omitempty"`
+	StatusChangedBy *uuid.UUID `json:"status_changed_by,omitempty"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
 }
 
 // RefreshToken represents a row in the refresh_tokens table.
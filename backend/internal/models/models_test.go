@@ -0,0 +1,65 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+)
+
+func TestUserStatus_Effective(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+
+	tests := []struct {
+		name   string
+		status UserStatus
+		until  *time.Time
+		want   UserStatus
+	}{
+		{"active", UserStatusActive, nil, UserStatusActive},
+		{"suspended in force", UserStatusSuspended, &future, UserStatusSuspended},
+		{"suspension lapsed", UserStatusSuspended, &past, UserStatusActive},
+		{"suspension ends now", UserStatusSuspended, &now, UserStatusActive},
+		{"suspended without end", UserStatusSuspended, nil, UserStatusActive},
+		{"banned", UserStatusBanned, nil, UserStatusBanned},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.status.Effective(tt.until, now); got != tt.want {
+				t.Errorf("Effective() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAccountStatusError(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Hour)
+
+	if err := AccountStatusError(UserStatusActive, nil, now); err != nil {
+		t.Errorf("active: err = %v, want nil", err)
+	}
+
+	err := AccountStatusError(UserStatusSuspended, &future, now)
+	if !apperror.Is(err, apperror.CodeAccountSuspended) {
+		t.Fatalf("suspended: err = %v, want ACCOUNT_SUSPENDED", err)
+	}
+
+	err = AccountStatusError(UserStatusBanned, nil, now)
+	if !apperror.Is(err, apperror.CodeAccountSuspended) {
+		t.Fatalf("banned: err = %v, want ACCOUNT_SUSPENDED", err)
+	}
+}
+
+func TestUserStatus_Valid(t *testing.T) {
+	for _, s := range []UserStatus{UserStatusActive, UserStatusSuspended, UserStatusBanned} {
+		if !s.Valid() {
+			t.Errorf("%q should be valid", s)
+		}
+	}
+	if UserStatus("deleted").Valid() {
+		t.Error(`"deleted" should be invalid`)
+	}
+}
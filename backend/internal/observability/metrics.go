@@ -21,4 +21,19 @@ var (
 		Name: "active_sse_connections",
 		Help: "Number of active SSE connections.",
 	})
+
+	SSEEventsDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sse_events_dropped_total",
+		Help: "SSE events dropped for slow clients, by event type and overflow policy.",
+	}, []string{"event", "policy"})
+
+	SSEEvictions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "sse_evictions_total",
+		Help: "SSE clients disconnected for falling behind, by the event type that overflowed.",
+	}, []string{"event"})
+
+	OutboxMessages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "outbox_messages_total",
+		Help: "Outbox delivery attempts by message kind and result (dispatched, retried, failed).",
+	}, []string{"kind", "result"})
 )
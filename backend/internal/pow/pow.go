@@ -0,0 +1,207 @@
+// Package pow implements stateless, HMAC-signed hashcash-style proof-of-work
+// challenges for the adaptive auth guard (middleware.ProofOfWork).
+//
+// A challenge token carries its own ID, difficulty, and expiry, signed with
+// the server secret — nothing is stored when a challenge is issued. The
+// client finds a solution string such that
+//
+//	sha256(token + ":" + solution)
+//
+// has at least `difficulty` leading zero bits, then sends both back in the
+// X-PoW-Challenge and X-PoW-Solution headers.
+package pow
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// HeaderChallenge carries the signed challenge token on a solved request.
+	HeaderChallenge = "X-PoW-Challenge"
+	// HeaderSolution carries the client's solution for HeaderChallenge.
+	HeaderSolution = "X-PoW-Solution"
+
+	// Algorithm is advertised to clients so the hash can change later
+	// without guessing on the client side.
+	Algorithm = "sha256"
+
+	// Upper bound on difficulty — 32 leading zero bits is already ~4 billion
+	// hashes on average, far past anything a browser should be asked to do.
+	maxDifficulty = 32
+	// Solutions are decimal counters in practice; cap length so a hostile
+	// client can't make us hash megabytes.
+	maxSolutionLength = 64
+	// 128-bit challenge IDs — collision-free for replay tracking.
+	challengeIDLength = 16
+	// Domain separation: the secret may be shared with JWT signing.
+	macPrefix = "pow:v1:"
+)
+
+var (
+	ErrMalformed        = errors.New("pow: malformed challenge")
+	ErrBadSignature     = errors.New("pow: invalid challenge signature")
+	ErrExpired          = errors.New("pow: challenge expired")
+	ErrInsufficientWork = errors.New("pow: solution does not meet difficulty")
+)
+
+// Challenge is the payload returned by GET /api/v1/auth/challenge.
+type Challenge struct {
+	Challenge  string    `json:"challenge"`
+	Difficulty int       `json:"difficulty"`
+	Algorithm  string    `json:"algorithm"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Solved describes a verified challenge. ID and ExpiresAt let callers
+// reject replays of the same solution until the challenge expires.
+type Solved struct {
+	ID        string
+	ExpiresAt time.Time
+}
+
+// Issuer signs and verifies challenges. Safe for concurrent use.
+type Issuer struct {
+	secret     []byte
+	difficulty int
+	ttl        time.Duration
+	now        func() time.Time
+}
+
+// NewIssuer creates a challenge issuer. Difficulty is clamped to [1, 32]
+// leading zero bits; a zero ttl defaults to 2 minutes.
+func NewIssuer(secret string, difficulty int, ttl time.Duration) *Issuer {
+	if difficulty < 1 {
+		difficulty = 1
+	}
+	if difficulty > maxDifficulty {
+		difficulty = maxDifficulty
+	}
+	if ttl == 0 {
+		ttl = 2 * time.Minute
+	}
+	return &Issuer{
+		secret:     []byte(secret),
+		difficulty: difficulty,
+		ttl:        ttl,
+		now:        time.Now,
+	}
+}
+
+// Difficulty returns the number of leading zero bits new challenges require.
+func (i *Issuer) Difficulty() int {
+	return i.difficulty
+}
+
+// Issue creates a new signed challenge.
+func (i *Issuer) Issue() (*Challenge, error) {
+	b := make([]byte, challengeIDLength)
+	if _, err := rand.Read(b); err != nil {
+		return nil, fmt.Errorf("generate challenge id: %w", err)
+	}
+	expiresAt := i.now().Add(i.ttl).Truncate(time.Second)
+	payload := fmt.Sprintf("%s.%d.%d", hex.EncodeToString(b), i.difficulty, expiresAt.Unix())
+
+	token := base64.RawURLEncoding.EncodeToString([]byte(payload)) + "." +
+		base64.RawURLEncoding.EncodeToString(i.sign(payload))
+
+	return &Challenge{
+		Challenge:  token,
+		Difficulty: i.difficulty,
+		Algorithm:  Algorithm,
+		ExpiresAt:  expiresAt,
+	}, nil
+}
+
+// Verify checks the token signature and expiry, then checks that solution
+// meets the difficulty embedded in the token. The difficulty is read from
+// the signed token rather than the issuer so a config change doesn't
+// invalidate challenges already handed out.
+func (i *Issuer) Verify(token, solution string) (*Solved, error) {
+	if solution == "" || len(solution) > maxSolutionLength {
+		return nil, ErrMalformed
+	}
+
+	encPayload, encMAC, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrMalformed
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encPayload)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(encMAC)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	if !hmac.Equal(mac, i.sign(string(payload))) {
+		return nil, ErrBadSignature
+	}
+
+	parts := strings.Split(string(payload), ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+	difficulty, err := strconv.Atoi(parts[1])
+	if err != nil || difficulty < 1 || difficulty > maxDifficulty {
+		return nil, ErrMalformed
+	}
+	expUnix, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	expiresAt := time.Unix(expUnix, 0)
+	if i.now().After(expiresAt) {
+		return nil, ErrExpired
+	}
+
+	if LeadingZeroBits(digest(token, solution)) < difficulty {
+		return nil, ErrInsufficientWork
+	}
+
+	return &Solved{ID: parts[0], ExpiresAt: expiresAt}, nil
+}
+
+// Solve brute-forces a solution for token. Intended for tests and
+// non-browser clients; the frontend ships its own solver.
+func Solve(token string, difficulty int) string {
+	for n := uint64(0); ; n++ {
+		s := strconv.FormatUint(n, 10)
+		if LeadingZeroBits(digest(token, s)) >= difficulty {
+			return s
+		}
+	}
+}
+
+// LeadingZeroBits counts the zero bits at the start of b.
+func LeadingZeroBits(b []byte) int {
+	n := 0
+	for _, v := range b {
+		if v == 0 {
+			n += 8
+			continue
+		}
+		return n + bits.LeadingZeros8(v)
+	}
+	return n
+}
+
+func (i *Issuer) sign(payload string) []byte {
+	m := hmac.New(sha256.New, i.secret)
+	m.Write([]byte(macPrefix + payload))
+	return m.Sum(nil)
+}
+
+func digest(token, solution string) []byte {
+	sum := sha256.Sum256([]byte(token + ":" + solution))
+	return sum[:]
+}
@@ -0,0 +1,163 @@
+package pow
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSecret = "pow-test-secret-at-least-32-characters"
+
+func TestNewIssuer_ClampsDifficulty(t *testing.T) {
+	tests := []struct {
+		name string
+		in   int
+		want int
+	}{
+		{"zero", 0, 1},
+		{"negative", -4, 1},
+		{"in range", 12, 12},
+		{"too high", 64, 32},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewIssuer(testSecret, tt.in, time.Minute).Difficulty(); got != tt.want {
+				t.Errorf("Difficulty() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewIssuer_DefaultTTL(t *testing.T) {
+	i := NewIssuer(testSecret, 4, 0)
+	if i.ttl != 2*time.Minute {
+		t.Errorf("ttl = %v, want 2m", i.ttl)
+	}
+}
+
+func TestIssueAndVerify_RoundTrip(t *testing.T) {
+	i := NewIssuer(testSecret, 8, time.Minute)
+
+	ch, err := i.Issue()
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if ch.Difficulty != 8 || ch.Algorithm != Algorithm {
+		t.Errorf("unexpected challenge metadata: %+v", ch)
+	}
+
+	solved, err := i.Verify(ch.Challenge, Solve(ch.Challenge, ch.Difficulty))
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if solved.ID == "" {
+		t.Error("Solved.ID should not be empty")
+	}
+	if !solved.ExpiresAt.Equal(ch.ExpiresAt) {
+		t.Errorf("ExpiresAt = %v, want %v", solved.ExpiresAt, ch.ExpiresAt)
+	}
+}
+
+func TestIssue_UniqueIDs(t *testing.T) {
+	i := NewIssuer(testSecret, 1, time.Minute)
+	a, _ := i.Issue()
+	b, _ := i.Issue()
+	if a.Challenge == b.Challenge {
+		t.Error("two issued challenges should differ")
+	}
+}
+
+func TestVerify_InsufficientWork(t *testing.T) {
+	i := NewIssuer(testSecret, 20, time.Minute)
+	ch, _ := i.Issue()
+
+	// Find a solution that does NOT meet the difficulty.
+	var bad string
+	for n := 0; ; n++ {
+		s := strings.Repeat("x", n%8) + string(rune('a'+n%26))
+		if LeadingZeroBits(digest(ch.Challenge, s)) < 20 {
+			bad = s
+			break
+		}
+	}
+	if _, err := i.Verify(ch.Challenge, bad); !errors.Is(err, ErrInsufficientWork) {
+		t.Errorf("Verify() error = %v, want ErrInsufficientWork", err)
+	}
+}
+
+func TestVerify_Expired(t *testing.T) {
+	i := NewIssuer(testSecret, 1, time.Minute)
+	ch, _ := i.Issue()
+	solution := Solve(ch.Challenge, 1)
+
+	i.now = func() time.Time { return time.Now().Add(2 * time.Minute) }
+	if _, err := i.Verify(ch.Challenge, solution); !errors.Is(err, ErrExpired) {
+		t.Errorf("Verify() error = %v, want ErrExpired", err)
+	}
+}
+
+func TestVerify_WrongSecret(t *testing.T) {
+	ch, _ := NewIssuer(testSecret, 1, time.Minute).Issue()
+	other := NewIssuer("a-different-secret-that-is-also-long", 1, time.Minute)
+
+	if _, err := other.Verify(ch.Challenge, Solve(ch.Challenge, 1)); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("Verify() error = %v, want ErrBadSignature", err)
+	}
+}
+
+func TestVerify_TamperedDifficulty(t *testing.T) {
+	i := NewIssuer(testSecret, 16, time.Minute)
+	ch, _ := i.Issue()
+
+	// Swap the payload for one claiming difficulty 1 but keep the original MAC.
+	_, mac, _ := strings.Cut(ch.Challenge, ".")
+	forged := NewIssuer(testSecret, 1, time.Minute)
+	fch, _ := forged.Issue()
+	payload, _, _ := strings.Cut(fch.Challenge, ".")
+	tampered := payload + "." + mac
+
+	if _, err := i.Verify(tampered, Solve(tampered, 1)); !errors.Is(err, ErrBadSignature) {
+		t.Errorf("Verify() error = %v, want ErrBadSignature", err)
+	}
+}
+
+func TestVerify_Malformed(t *testing.T) {
+	i := NewIssuer(testSecret, 1, time.Minute)
+	tests := []struct {
+		name     string
+		token    string
+		solution string
+	}{
+		{"empty solution", "a.b", ""},
+		{"oversized solution", "a.b", strings.Repeat("9", maxSolutionLength+1)},
+		{"no separator", "abc", "1"},
+		{"bad payload encoding", "!!!.abc", "1"},
+		{"bad mac encoding", "YWJj.!!!", "1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := i.Verify(tt.token, tt.solution); !errors.Is(err, ErrMalformed) {
+				t.Errorf("Verify() error = %v, want ErrMalformed", err)
+			}
+		})
+	}
+}
+
+func TestLeadingZeroBits(t *testing.T) {
+	tests := []struct {
+		in   []byte
+		want int
+	}{
+		{[]byte{0xFF}, 0},
+		{[]byte{0x80}, 0},
+		{[]byte{0x01}, 7},
+		{[]byte{0x00, 0x10}, 11},
+		{[]byte{0x00, 0x00}, 16},
+	}
+	for _, tt := range tests {
+		if got := LeadingZeroBits(tt.in); got != tt.want {
+			t.Errorf("LeadingZeroBits(%x) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
@@ -3,15 +3,21 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/hibiken/asynq"
+
+	"github.com/golid-ai/golid/backend/internal/service/avatar"
+	"github.com/golid-ai/golid/backend/internal/service/email"
 )
 
 // EmailSender is the subset of EmailService needed by queue handlers.
 type EmailSender interface {
-	SendVerificationEmail(toEmail, token string) error
-	SendPasswordResetEmail(toEmail, token string) error
+	SendVerificationEmail(to email.Recipient, token string) error
+	SendPasswordResetEmail(to email.Recipient, token string) error
+	SendAccountStatusEmail(to email.Recipient, status string, suspendedUntil *time.Time, reason string) error
 }
 
 type EmailHandler struct {
@@ -27,7 +33,7 @@ func (h *EmailHandler) HandleVerification(ctx context.Context, task *asynq.Task)
 	if err := json.Unmarshal(task.Payload(), &p); err != nil {
 		return fmt.Errorf("unmarshal verification payload: %w", err)
 	}
-	return h.emailService.SendVerificationEmail(p.To, p.Token)
+	return h.emailService.SendVerificationEmail(recipient(p.To, p.Locale, p.Timezone), p.Token)
 }
 
 func (h *EmailHandler) HandlePasswordReset(ctx context.Context, task *asynq.Task) error {
@@ -35,5 +41,75 @@ func (h *EmailHandler) HandlePasswordReset(ctx context.Context, task *asynq.Task
 	if err := json.Unmarshal(task.Payload(), &p); err != nil {
 		return fmt.Errorf("unmarshal password reset payload: %w", err)
 	}
-	return h.emailService.SendPasswordResetEmail(p.To, p.Token)
+	return h.emailService.SendPasswordResetEmail(recipient(p.To, p.Locale, p.Timezone), p.Token)
+}
+
+func (h *EmailHandler) HandleAccountStatus(ctx context.Context, task *asynq.Task) error {
+	var p AccountStatusPayload
+	if err := json.Unmarshal(task.Payload(), &p); err != nil {
+		return fmt.Errorf("unmarshal account status payload: %w", err)
+	}
+	return h.emailService.SendAccountStatusEmail(recipient(p.To, p.Locale, p.Timezone), p.Status, p.SuspendedUntil, p.Reason)
+}
+
+// AvatarProcessor is the subset of AvatarService needed by queue handlers.
+type AvatarProcessor interface {
+	Process(ctx context.Context, userID, uploadID string) error
+}
+
+type AvatarHandler struct {
+	avatarService AvatarProcessor
+}
+
+func NewAvatarHandler(avatarService AvatarProcessor) *AvatarHandler {
+	return &AvatarHandler{avatarService: avatarService}
+}
+
+func (h *AvatarHandler) HandleProcessAvatar(ctx context.Context, task *asynq.Task) error {
+	var p ProcessAvatarPayload
+	if err := json.Unmarshal(task.Payload(), &p); err != nil {
+		return fmt.Errorf("unmarshal process avatar payload: %w", err)
+	}
+	err := h.avatarService.Process(ctx, p.UserID, p.UploadID)
+	if errors.Is(err, avatar.ErrInvalidImage) {
+		return fmt.Errorf("%w: %v", asynq.SkipRetry, err)
+	}
+	return err
+}
+
+// DataExportProcessor is the subset of ExportService needed by queue handlers.
+type DataExportProcessor interface {
+	Process(ctx context.Context, exportID string) error
+}
+
+type DataExportHandler struct {
+	exportService DataExportProcessor
+}
+
+func NewDataExportHandler(exportService DataExportProcessor) *DataExportHandler {
+	return &DataExportHandler{exportService: exportService}
+}
+
+func (h *DataExportHandler) HandleBuildDataExport(ctx context.Context, task *asynq.Task) error {
+	var p BuildDataExportPayload
+	if err := json.Unmarshal(task.Payload(), &p); err != nil {
+		return fmt.Errorf("unmarshal build data export payload: %w", err)
+	}
+	return h.exportService.Process(ctx, p.ExportID)
+}
+
+// NewServeMux routes every task type to its handler. The worker serves it
+// from Redis; without Redis the outbox relay runs tasks through it in
+// process.
+func NewServeMux(emailService EmailSender, avatarService AvatarProcessor, exportService DataExportProcessor) *asynq.ServeMux {
+	emailHandler := NewEmailHandler(emailService)
+	avatarHandler := NewAvatarHandler(avatarService)
+	exportHandler := NewDataExportHandler(exportService)
+	mux := asynq.NewServeMux()
+	mux.HandleFunc(TypeSendVerificationEmail, emailHandler.HandleVerification)
+	mux.HandleFunc(TypeSendPasswordReset, emailHandler.HandlePasswordReset)
+	mux.HandleFunc(TypeSendAccountStatus, emailHandler.HandleAccountStatus)
+	mux.HandleFunc(TypeProcessAvatar, avatarHandler.HandleProcessAvatar)
+	mux.HandleFunc(TypeBuildDataExport, exportHandler.HandleBuildDataExport)
+	return mux
 }
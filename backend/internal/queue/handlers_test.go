@@ -3,32 +3,50 @@ package queue
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/hibiken/asynq"
+
+	"github.com/golid-ai/golid/backend/internal/service/avatar"
+	"github.com/golid-ai/golid/backend/internal/service/email"
 )
 
 type mockEmailSender struct {
 	verificationCalled bool
 	resetCalled        bool
+	statusCalled       bool
 	lastTo             string
+	lastRecipient      email.Recipient
 	lastToken          string
+	lastStatus         string
+	lastUntil          *time.Time
 }
 
-func (m *mockEmailSender) SendVerificationEmail(toEmail, token string) error {
+func (m *mockEmailSender) SendVerificationEmail(to email.Recipient, token string) error {
 	m.verificationCalled = true
-	m.lastTo = toEmail
+	m.lastTo, m.lastRecipient = to.Email, to
 	m.lastToken = token
 	return nil
 }
 
-func (m *mockEmailSender) SendPasswordResetEmail(toEmail, token string) error {
+func (m *mockEmailSender) SendPasswordResetEmail(to email.Recipient, token string) error {
 	m.resetCalled = true
-	m.lastTo = toEmail
+	m.lastTo, m.lastRecipient = to.Email, to
 	m.lastToken = token
 	return nil
 }
 
+func (m *mockEmailSender) SendAccountStatusEmail(to email.Recipient, status string, suspendedUntil *time.Time, reason string) error {
+	m.statusCalled = true
+	m.lastTo, m.lastRecipient = to.Email, to
+	m.lastStatus = status
+	m.lastUntil = suspendedUntil
+	return nil
+}
+
 func TestEmailHandler_HandleVerification(t *testing.T) {
 	mock := &mockEmailSender{}
 	h := NewEmailHandler(mock)
@@ -83,3 +101,147 @@ func TestEmailHandler_HandleVerification_InvalidPayload(t *testing.T) {
 		t.Error("expected SendVerificationEmail NOT to be called on invalid payload")
 	}
 }
+
+func TestEmailHandler_HandleAccountStatus(t *testing.T) {
+	mock := &mockEmailSender{}
+	h := NewEmailHandler(mock)
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	until := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := email.Recipient{Email: "user@example.com", Locale: "es", Location: paris}
+	task, err := NewSendAccountStatus(to, "suspended", &until, "spam")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := h.HandleAccountStatus(context.Background(), task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !mock.statusCalled {
+		t.Error("expected SendAccountStatusEmail to be called")
+	}
+	if mock.lastStatus != "suspended" || mock.lastUntil == nil || !mock.lastUntil.Equal(until) {
+		t.Errorf("status = %q, until = %v", mock.lastStatus, mock.lastUntil)
+	}
+	if r := mock.lastRecipient; r.Email != to.Email || r.Locale != "es" || r.Location.String() != "Europe/Paris" {
+		t.Errorf("recipient = %+v, want %+v", r, to)
+	}
+}
+
+func TestEmailHandler_LegacyPayloadUsesDefaults(t *testing.T) {
+	mock := &mockEmailSender{}
+	h := NewEmailHandler(mock)
+
+	// Payloads enqueued before locale and timezone existed.
+	task := asynq.NewTask(TypeSendPasswordReset, []byte(`{"to":"user@example.com","token":"t"}`))
+	if err := h.HandlePasswordReset(context.Background(), task); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r := mock.lastRecipient; r.Locale != "" || r.Location != nil {
+		t.Errorf("recipient = %+v, want defaults", r)
+	}
+}
+
+type mockAvatarProcessor struct {
+	err            error
+	userID, upload string
+}
+
+func (m *mockAvatarProcessor) Process(_ context.Context, userID, uploadID string) error {
+	m.userID, m.upload = userID, uploadID
+	return m.err
+}
+
+func TestAvatarHandler_HandleProcessAvatar(t *testing.T) {
+	mock := &mockAvatarProcessor{}
+	h := NewAvatarHandler(mock)
+
+	payload, _ := json.Marshal(ProcessAvatarPayload{UserID: "user-1", UploadID: "upload-1"})
+	if err := h.HandleProcessAvatar(context.Background(), asynq.NewTask(TypeProcessAvatar, payload)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mock.userID != "user-1" || mock.upload != "upload-1" {
+		t.Errorf("Process called with (%q, %q)", mock.userID, mock.upload)
+	}
+}
+
+func TestAvatarHandler_InvalidImageSkipsRetry(t *testing.T) {
+	h := NewAvatarHandler(&mockAvatarProcessor{err: fmt.Errorf("%w: bad header", avatar.ErrInvalidImage)})
+
+	payload, _ := json.Marshal(ProcessAvatarPayload{UserID: "user-1", UploadID: "upload-1"})
+	err := h.HandleProcessAvatar(context.Background(), asynq.NewTask(TypeProcessAvatar, payload))
+	if !errors.Is(err, asynq.SkipRetry) {
+		t.Errorf("error = %v, want asynq.SkipRetry", err)
+	}
+}
+
+func TestAvatarHandler_TransientErrorRetries(t *testing.T) {
+	h := NewAvatarHandler(&mockAvatarProcessor{err: errors.New("storage unavailable")})
+
+	payload, _ := json.Marshal(ProcessAvatarPayload{UserID: "user-1", UploadID: "upload-1"})
+	err := h.HandleProcessAvatar(context.Background(), asynq.NewTask(TypeProcessAvatar, payload))
+	if err == nil || errors.Is(err, asynq.SkipRetry) {
+		t.Errorf("error = %v, want retryable error", err)
+	}
+}
+
+type mockDataExportProcessor struct {
+	err      error
+	exportID string
+}
+
+func (m *mockDataExportProcessor) Process(_ context.Context, exportID string) error {
+	m.exportID = exportID
+	return m.err
+}
+
+func TestDataExportHandler_HandleBuildDataExport(t *testing.T) {
+	mock := &mockDataExportProcessor{}
+	h := NewDataExportHandler(mock)
+
+	payload, _ := json.Marshal(BuildDataExportPayload{ExportID: "export-1"})
+	if err := h.HandleBuildDataExport(context.Background(), asynq.NewTask(TypeBuildDataExport, payload)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if mock.exportID != "export-1" {
+		t.Errorf("Process called with %q", mock.exportID)
+	}
+}
+
+func TestDataExportHandler_PropagatesError(t *testing.T) {
+	h := NewDataExportHandler(&mockDataExportProcessor{err: errors.New("exporter failed")})
+
+	payload, _ := json.Marshal(BuildDataExportPayload{ExportID: "export-1"})
+	if err := h.HandleBuildDataExport(context.Background(), asynq.NewTask(TypeBuildDataExport, payload)); err == nil {
+		t.Error("expected error to be returned for retry")
+	}
+}
+
+func TestNewServeMux_RoutesEveryTaskType(t *testing.T) {
+	emails := &mockEmailSender{}
+	exports := &mockDataExportProcessor{}
+	mux := NewServeMux(emails, &mockAvatarProcessor{}, exports)
+
+	verification, _ := NewSendVerificationEmail(email.Recipient{Email: "test@example.com"}, "abc123")
+	if err := mux.ProcessTask(context.Background(), verification); err != nil {
+		t.Fatalf("verification task: %v", err)
+	}
+	if !emails.verificationCalled || emails.lastToken != "abc123" {
+		t.Errorf("verification email not sent: %+v", emails)
+	}
+
+	build, _ := NewBuildDataExport("export-1")
+	if err := mux.ProcessTask(context.Background(), build); err != nil {
+		t.Fatalf("export task: %v", err)
+	}
+	if exports.exportID != "export-1" {
+		t.Errorf("Process called with %q", exports.exportID)
+	}
+
+	if err := mux.ProcessTask(context.Background(), asynq.NewTask("unknown:type", nil)); err == nil {
+		t.Error("expected an error for an unregistered task type")
+	}
+}
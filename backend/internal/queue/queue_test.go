@@ -5,6 +5,8 @@ import (
 
 	"github.com/alicebob/miniredis/v2"
 	"github.com/hibiken/asynq"
+
+	"github.com/golid-ai/golid/backend/internal/service/email"
 )
 
 // =============================================================================
@@ -20,7 +22,7 @@ func TestQueue_IsConfigured_NilClient(t *testing.T) {
 
 func TestQueue_Enqueue_NotConfigured(t *testing.T) {
 	q := New("")
-	task, err := NewSendVerificationEmail("test@example.com", "token123")
+	task, err := NewSendVerificationEmail(email.Recipient{Email: "test@example.com"}, "token123")
 	if err != nil {
 		t.Fatalf("unexpected error creating task: %v", err)
 	}
@@ -2,34 +2,112 @@ package queue
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/hibiken/asynq"
+
+	"github.com/golid-ai/golid/backend/internal/service/email"
 )
 
 const (
 	TypeSendVerificationEmail = "email:verification"
 	TypeSendPasswordReset     = "email:password_reset"
+	TypeSendAccountStatus     = "email:account_status"
+	TypeProcessAvatar         = "image:process_avatar"
+	TypeBuildDataExport       = "export:build"
 
-	taskMaxRetry = 3
+	// TaskMaxRetry is how many times a failed task is retried. Tasks
+	// rebuilt from the outbox are enqueued with it too.
+	TaskMaxRetry = 3
 )
 
+// Recipient fields are flattened into email payloads. Locale and Timezone
+// are omitted for the default locale and UTC, so payloads enqueued before
+// they existed still decode.
 type SendEmailPayload struct {
-	To    string `json:"to"`
-	Token string `json:"token"`
+	To       string `json:"to"`
+	Locale   string `json:"locale,omitempty"`
+	Timezone string `json:"timezone,omitempty"`
+	Token    string `json:"token"`
+}
+
+func NewSendVerificationEmail(to email.Recipient, token string) (*asynq.Task, error) {
+	payload, err := json.Marshal(SendEmailPayload{To: to.Email, Locale: to.Locale, Timezone: zoneName(to.Location), Token: token})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeSendVerificationEmail, payload, asynq.MaxRetry(TaskMaxRetry)), nil
+}
+
+func NewSendPasswordReset(to email.Recipient, token string) (*asynq.Task, error) {
+	payload, err := json.Marshal(SendEmailPayload{To: to.Email, Locale: to.Locale, Timezone: zoneName(to.Location), Token: token})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeSendPasswordReset, payload, asynq.MaxRetry(TaskMaxRetry)), nil
+}
+
+type AccountStatusPayload struct {
+	To             string     `json:"to"`
+	Locale         string     `json:"locale,omitempty"`
+	Timezone       string     `json:"timezone,omitempty"`
+	Status         string     `json:"status"`
+	SuspendedUntil *time.Time `json:"suspended_until,omitempty"`
+	Reason         string     `json:"reason,omitempty"`
+}
+
+func NewSendAccountStatus(to email.Recipient, status string, suspendedUntil *time.Time, reason string) (*asynq.Task, error) {
+	payload, err := json.Marshal(AccountStatusPayload{
+		To: to.Email, Locale: to.Locale, Timezone: zoneName(to.Location),
+		Status: status, SuspendedUntil: suspendedUntil, Reason: reason,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeSendAccountStatus, payload, asynq.MaxRetry(TaskMaxRetry)), nil
 }
 
-func NewSendVerificationEmail(to, token string) (*asynq.Task, error) {
-	payload, err := json.Marshal(SendEmailPayload{To: to, Token: token})
+type ProcessAvatarPayload struct {
+	UserID   string `json:"user_id"`
+	UploadID string `json:"upload_id"`
+}
+
+func NewProcessAvatar(userID, uploadID string) (*asynq.Task, error) {
+	payload, err := json.Marshal(ProcessAvatarPayload{UserID: userID, UploadID: uploadID})
 	if err != nil {
 		return nil, err
 	}
-	return asynq.NewTask(TypeSendVerificationEmail, payload, asynq.MaxRetry(taskMaxRetry)), nil
+	return asynq.NewTask(TypeProcessAvatar, payload, asynq.MaxRetry(TaskMaxRetry)), nil
+}
+
+type BuildDataExportPayload struct {
+	ExportID string `json:"export_id"`
 }
 
-func NewSendPasswordReset(to, token string) (*asynq.Task, error) {
-	payload, err := json.Marshal(SendEmailPayload{To: to, Token: token})
+func NewBuildDataExport(exportID string) (*asynq.Task, error) {
+	payload, err := json.Marshal(BuildDataExportPayload{ExportID: exportID})
 	if err != nil {
 		return nil, err
 	}
-	return asynq.NewTask(TypeSendPasswordReset, payload, asynq.MaxRetry(taskMaxRetry)), nil
+	return asynq.NewTask(TypeBuildDataExport, payload, asynq.MaxRetry(TaskMaxRetry)), nil
+}
+
+// zoneName is the IANA name stored in a payload; "" for UTC.
+func zoneName(loc *time.Location) string {
+	if loc == nil || loc == time.UTC {
+		return ""
+	}
+	return loc.String()
+}
+
+// recipient rebuilds an email recipient from payload fields. An unknown
+// time zone falls back to UTC rather than failing the task.
+func recipient(to, locale, timezone string) email.Recipient {
+	r := email.Recipient{Email: to, Locale: locale}
+	if timezone != "" {
+		if loc, err := time.LoadLocation(timezone); err == nil {
+			r.Location = loc
+		}
+	}
+	return r
 }
@@ -3,10 +3,12 @@ package queue
 import (
 	"encoding/json"
 	"testing"
+
+	"github.com/golid-ai/golid/backend/internal/service/email"
 )
 
 func TestNewSendVerificationEmail_Payload(t *testing.T) {
-	task, err := NewSendVerificationEmail("user@example.com", "verify-token-123")
+	task, err := NewSendVerificationEmail(email.Recipient{Email: "user@example.com", Locale: "pt-BR"}, "verify-token-123")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -24,10 +26,13 @@ func TestNewSendVerificationEmail_Payload(t *testing.T) {
 	if p.Token != "verify-token-123" {
 		t.Errorf("expected Token = verify-token-123, got %s", p.Token)
 	}
+	if p.Locale != "pt-BR" || p.Timezone != "" {
+		t.Errorf("expected Locale = pt-BR and no Timezone, got %q, %q", p.Locale, p.Timezone)
+	}
 }
 
 func TestNewSendPasswordReset_Payload(t *testing.T) {
-	task, err := NewSendPasswordReset("user@example.com", "reset-token-456")
+	task, err := NewSendPasswordReset(email.Recipient{Email: "user@example.com"}, "reset-token-456")
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -46,3 +51,39 @@ func TestNewSendPasswordReset_Payload(t *testing.T) {
 		t.Errorf("expected Token = reset-token-456, got %s", p.Token)
 	}
 }
+
+func TestNewProcessAvatar_Payload(t *testing.T) {
+	task, err := NewProcessAvatar("user-1", "upload-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task.Type() != TypeProcessAvatar {
+		t.Errorf("expected type %s, got %s", TypeProcessAvatar, task.Type())
+	}
+
+	var p ProcessAvatarPayload
+	if err := json.Unmarshal(task.Payload(), &p); err != nil {
+		t.Fatalf("failed to unmarshal payload: %v", err)
+	}
+	if p.UserID != "user-1" || p.UploadID != "upload-1" {
+		t.Errorf("unexpected payload: %+v", p)
+	}
+}
+
+func TestNewBuildDataExport_Payload(t *testing.T) {
+	task, err := NewBuildDataExport("export-1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task.Type() != TypeBuildDataExport {
+		t.Errorf("expected type %s, got %s", TypeBuildDataExport, task.Type())
+	}
+
+	var p BuildDataExportPayload
+	if err := json.Unmarshal(task.Payload(), &p); err != nil {
+		t.Fatalf("failed to unmarshal payload: %v", err)
+	}
+	if p.ExportID != "export-1" {
+		t.Errorf("unexpected payload: %+v", p)
+	}
+}
@@ -0,0 +1,491 @@
+// Package announcement manages site-wide notices, such as maintenance
+// windows and release banners, that admins show to an audience for a
+// period of time.
+//
+// Announcements visible to signed-in users are pushed to every open SSE
+// connection when they are created or changed, so banners appear without a
+// reload; the rest are seen on the next GET /announcements.
+package announcement
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/pagination"
+	"github.com/golid-ai/golid/backend/internal/service/sse"
+	"github.com/golid-ai/golid/backend/internal/validate"
+)
+
+// Severities, most urgent last.
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Audiences an announcement can be shown to.
+const (
+	AudienceEveryone = "everyone" // signed in or not
+	AudienceGuests   = "guests"   // anonymous visitors only
+	AudienceUsers    = "users"    // any signed-in user
+	AudienceAdmins   = "admins"
+)
+
+// SSE events. Published and removed are broadcast to every connection;
+// dismissed goes to the dismissing user's other tabs.
+const (
+	// EventPublished carries an Announcement that was created or changed.
+	// It may not have started yet.
+	EventPublished = "announcement"
+	// EventRemoved carries {"id": ...} for an announcement that was deleted
+	// or is no longer shown to signed-in users.
+	EventRemoved = "announcement_removed"
+	// EventDismissed carries {"id": ...}.
+	EventDismissed = "announcement_dismissed"
+)
+
+const (
+	maxTitleLen = 200
+	maxBodyLen  = 5000
+)
+
+// Pusher delivers live events. Satisfied by *sse.SSEHub.
+type Pusher interface {
+	Broadcast(event sse.SSEEvent)
+	Send(userID string, event sse.SSEEvent)
+}
+
+// Announcement is one notice and the window it is shown in. EndsAt nil
+// means until it is removed.
+type Announcement struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Body        string     `json:"body"`
+	Severity    string     `json:"severity"`
+	Audience    string     `json:"audience"`
+	StartsAt    time.Time  `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	Dismissible bool       `json:"dismissible"`
+	CreatedBy   *string    `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// NewAnnouncement is the input to Create. Severity defaults to info,
+// Audience to everyone, StartsAt to now, and Dismissible to true.
+type NewAnnouncement struct {
+	Title       string     `json:"title"`
+	Body        string     `json:"body"`
+	Severity    string     `json:"severity"`
+	Audience    string     `json:"audience"`
+	StartsAt    *time.Time `json:"starts_at"`
+	EndsAt      *time.Time `json:"ends_at"`
+	Dismissible *bool      `json:"dismissible"`
+}
+
+// Update changes the non-nil fields of an announcement; ClearEndsAt
+// removes its end time.
+type Update struct {
+	Title       *string
+	Body        *string
+	Severity    *string
+	Audience    *string
+	StartsAt    *time.Time
+	EndsAt      *time.Time
+	ClearEndsAt bool
+	Dismissible *bool
+}
+
+// Viewer is who the public list is for. The zero value is an anonymous
+// visitor.
+type Viewer struct {
+	UserID   string
+	UserType string
+}
+
+// ListResult is a page of announcements, newest first.
+type ListResult struct {
+	Announcements []Announcement `json:"announcements"`
+	Total         int            `json:"total"`
+	Page          int            `json:"page"`
+	PerPage       int            `json:"per_page"`
+	TotalPages    int            `json:"total_pages"`
+}
+
+// AnnouncementService stores announcements and dismissals.
+type AnnouncementService struct {
+	pool              *pgxpool.Pool
+	pusher            Pusher
+	paginationDefault int
+	paginationMax     int
+}
+
+// NewAnnouncementService creates a new announcement service. pusher may be
+// nil, in which case changes are not pushed live.
+func NewAnnouncementService(pool *pgxpool.Pool, pusher Pusher, paginationDefault, paginationMax int) *AnnouncementService {
+	return &AnnouncementService{
+		pool:              pool,
+		pusher:            pusher,
+		paginationDefault: paginationDefault,
+		paginationMax:     paginationMax,
+	}
+}
+
+const announcementColumns = `id, title, body, severity, audience, starts_at, ends_at, dismissible,
+	created_by::text, created_at, updated_at`
+
+func scanAnnouncement(row pgx.Row, a *Announcement) error {
+	return row.Scan(&a.ID, &a.Title, &a.Body, &a.Severity, &a.Audience, &a.StartsAt, &a.EndsAt, &a.Dismissible,
+		&a.CreatedBy, &a.CreatedAt, &a.UpdatedAt)
+}
+
+// validate normalizes the announcement's text and reports every invalid
+// field.
+func (a *Announcement) validate() error {
+	a.Title = strings.TrimSpace(a.Title)
+	a.Body = strings.TrimSpace(a.Body)
+
+	verr := apperror.Validation()
+	switch n := utf8.RuneCountInString(a.Title); {
+	case n == 0:
+		verr.WithDetail("title", "error.announcement.title_required")
+	case n > maxTitleLen:
+		verr.WithDetail("title", "error.announcement.title_too_long", maxTitleLen)
+	}
+	if utf8.RuneCountInString(a.Body) > maxBodyLen {
+		verr.WithDetail("body", "error.announcement.body_too_long", maxBodyLen)
+	}
+	switch a.Severity {
+	case SeverityInfo, SeverityWarning, SeverityCritical:
+	default:
+		verr.WithDetail("severity", "error.announcement.severity")
+	}
+	switch a.Audience {
+	case AudienceEveryone, AudienceGuests, AudienceUsers, AudienceAdmins:
+	default:
+		verr.WithDetail("audience", "error.announcement.audience")
+	}
+	if a.EndsAt != nil && !a.EndsAt.After(a.StartsAt) {
+		verr.WithDetail("ends_at", "error.announcement.ends_at")
+	}
+	if len(verr.Details) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// Create stores an announcement and pushes it to signed-in users it is
+// shown to.
+func (s *AnnouncementService) Create(ctx context.Context, in NewAnnouncement, actorID string) (*Announcement, error) {
+	a := Announcement{
+		Title:       in.Title,
+		Body:        in.Body,
+		Severity:    in.Severity,
+		Audience:    in.Audience,
+		StartsAt:    time.Now().UTC(),
+		EndsAt:      in.EndsAt,
+		Dismissible: in.Dismissible == nil || *in.Dismissible,
+	}
+	if a.Severity == "" {
+		a.Severity = SeverityInfo
+	}
+	if a.Audience == "" {
+		a.Audience = AudienceEveryone
+	}
+	if in.StartsAt != nil {
+		a.StartsAt = *in.StartsAt
+	}
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+
+	err := scanAnnouncement(s.pool.QueryRow(ctx,
+		`INSERT INTO announcements (title, body, severity, audience, starts_at, ends_at, dismissible, created_by)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 RETURNING `+announcementColumns,
+		a.Title, a.Body, a.Severity, a.Audience, a.StartsAt, a.EndsAt, a.Dismissible, nilIfEmpty(actorID),
+	), &a)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("insert announcement: %w", err))
+	}
+
+	if a.pushed(time.Now()) {
+		s.broadcast(EventPublished, a)
+	}
+	return &a, nil
+}
+
+// Get returns one announcement.
+func (s *AnnouncementService) Get(ctx context.Context, id string) (*Announcement, error) {
+	if err := validate.UUID(id, "id"); err != nil {
+		return nil, err
+	}
+	var a Announcement
+	err := scanAnnouncement(s.pool.QueryRow(ctx,
+		`SELECT `+announcementColumns+` FROM announcements WHERE id = $1`, id,
+	), &a)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperror.NotFound("Announcement")
+	}
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("get announcement: %w", err))
+	}
+	return &a, nil
+}
+
+// List returns every announcement, past, current, and upcoming, newest
+// first.
+func (s *AnnouncementService) List(ctx context.Context, page, perPage int) (*ListResult, error) {
+	page, perPage = pagination.NormalizePagination(page, perPage, s.paginationDefault, s.paginationMax)
+	offset := (page - 1) * perPage
+
+	var total int
+	if err := s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM announcements`).Scan(&total); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("count announcements: %w", err))
+	}
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT `+announcementColumns+` FROM announcements
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT $1 OFFSET $2`,
+		perPage, offset,
+	)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("list announcements: %w", err))
+	}
+	items, err := collect(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListResult{
+		Announcements: items,
+		Total:         total,
+		Page:          page,
+		PerPage:       perPage,
+		TotalPages:    (total + perPage - 1) / perPage,
+	}, nil
+}
+
+// Update changes an announcement and pushes the result: the new version if
+// signed-in users are shown it, otherwise its removal.
+func (s *AnnouncementService) Update(ctx context.Context, id string, upd Update) (*Announcement, error) {
+	if err := validate.UUID(id, "id"); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("begin update announcement: %w", err))
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var a Announcement
+	err = scanAnnouncement(tx.QueryRow(ctx,
+		`SELECT `+announcementColumns+` FROM announcements WHERE id = $1 FOR UPDATE`, id,
+	), &a)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperror.NotFound("Announcement")
+	}
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("get announcement: %w", err))
+	}
+
+	upd.apply(&a)
+	if err := a.validate(); err != nil {
+		return nil, err
+	}
+
+	err = scanAnnouncement(tx.QueryRow(ctx,
+		`UPDATE announcements
+		 SET title = $2, body = $3, severity = $4, audience = $5, starts_at = $6, ends_at = $7,
+		     dismissible = $8, updated_at = NOW()
+		 WHERE id = $1
+		 RETURNING `+announcementColumns,
+		id, a.Title, a.Body, a.Severity, a.Audience, a.StartsAt, a.EndsAt, a.Dismissible,
+	), &a)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("update announcement: %w", err))
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("commit update announcement: %w", err))
+	}
+
+	if a.pushed(time.Now()) {
+		s.broadcast(EventPublished, a)
+	} else {
+		s.broadcast(EventRemoved, map[string]string{"id": a.ID})
+	}
+	return &a, nil
+}
+
+func (u Update) apply(a *Announcement) {
+	if u.Title != nil {
+		a.Title = *u.Title
+	}
+	if u.Body != nil {
+		a.Body = *u.Body
+	}
+	if u.Severity != nil {
+		a.Severity = *u.Severity
+	}
+	if u.Audience != nil {
+		a.Audience = *u.Audience
+	}
+	if u.StartsAt != nil {
+		a.StartsAt = *u.StartsAt
+	}
+	if u.EndsAt != nil {
+		a.EndsAt = u.EndsAt
+	}
+	if u.ClearEndsAt {
+		a.EndsAt = nil
+	}
+	if u.Dismissible != nil {
+		a.Dismissible = *u.Dismissible
+	}
+}
+
+// Delete removes an announcement and its dismissals.
+func (s *AnnouncementService) Delete(ctx context.Context, id string) error {
+	if err := validate.UUID(id, "id"); err != nil {
+		return err
+	}
+	tag, err := s.pool.Exec(ctx, `DELETE FROM announcements WHERE id = $1`, id)
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("delete announcement: %w", err))
+	}
+	if tag.RowsAffected() == 0 {
+		return apperror.NotFound("Announcement")
+	}
+	s.broadcast(EventRemoved, map[string]string{"id": id})
+	return nil
+}
+
+// Active returns the announcements showing now for the viewer, minus those
+// they dismissed: most severe first, then newest.
+func (s *AnnouncementService) Active(ctx context.Context, v Viewer) ([]Announcement, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT `+announcementColumns+` FROM announcements a
+		 WHERE audience = ANY($1) AND starts_at <= NOW() AND (ends_at IS NULL OR ends_at > NOW())
+		   AND NOT EXISTS (
+		       SELECT 1 FROM announcement_dismissals d
+		       WHERE d.announcement_id = a.id AND d.user_id = $2)
+		 ORDER BY CASE severity WHEN 'critical' THEN 0 WHEN 'warning' THEN 1 ELSE 2 END,
+		          starts_at DESC, id DESC`,
+		v.audiences(), nilIfEmpty(v.UserID),
+	)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("list active announcements: %w", err))
+	}
+	return collect(rows)
+}
+
+// audiences returns the audiences the viewer belongs to.
+func (v Viewer) audiences() []string {
+	switch {
+	case v.UserID == "":
+		return []string{AudienceEveryone, AudienceGuests}
+	case v.UserType == "admin":
+		return []string{AudienceEveryone, AudienceUsers, AudienceAdmins}
+	default:
+		return []string{AudienceEveryone, AudienceUsers}
+	}
+}
+
+// Dismiss hides an announcement from the user for good, on every device,
+// and tells their other tabs. Dismissing twice is a no-op.
+func (s *AnnouncementService) Dismiss(ctx context.Context, userID, id string) error {
+	if err := validate.UUID(id, "id"); err != nil {
+		return err
+	}
+
+	var dismissible bool
+	err := s.pool.QueryRow(ctx, `SELECT dismissible FROM announcements WHERE id = $1`, id).Scan(&dismissible)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return apperror.NotFound("Announcement")
+	}
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("get announcement: %w", err))
+	}
+	if !dismissible {
+		return apperror.BadRequest("This announcement cannot be dismissed").WithMessageID("error.announcement.not_dismissible")
+	}
+
+	if _, err := s.pool.Exec(ctx,
+		`INSERT INTO announcement_dismissals (user_id, announcement_id) VALUES ($1, $2)
+		 ON CONFLICT DO NOTHING`,
+		userID, id,
+	); err != nil {
+		return apperror.Internal(fmt.Errorf("dismiss announcement: %w", err))
+	}
+
+	if s.pusher != nil {
+		s.pusher.Send(userID, sse.SSEEvent{Event: EventDismissed, Data: map[string]string{"id": id}})
+	}
+	return nil
+}
+
+// SSEEventPolicies implements sse.EventPolicyDeclarer: a client that falls
+// behind needs only the latest version of each announcement.
+func (s *AnnouncementService) SSEEventPolicies() []sse.EventPolicy {
+	return []sse.EventPolicy{{Event: EventPublished, Overflow: sse.Coalesce, Key: announcementID}}
+}
+
+func announcementID(event sse.SSEEvent) string {
+	switch data := event.Data.(type) {
+	case Announcement:
+		return data.ID
+	case json.RawMessage:
+		var a Announcement
+		_ = json.Unmarshal(data, &a) //nolint:errcheck // unkeyed events coalesce together
+		return a.ID
+	}
+	return ""
+}
+
+// pushed reports whether the announcement goes out over SSE: streams
+// belong to signed-in users, and only audiences every one of them is in
+// can be broadcast.
+func (a *Announcement) pushed(now time.Time) bool {
+	live := a.EndsAt == nil || a.EndsAt.After(now)
+	return live && (a.Audience == AudienceEveryone || a.Audience == AudienceUsers)
+}
+
+func (s *AnnouncementService) broadcast(event string, data any) {
+	if s.pusher != nil {
+		s.pusher.Broadcast(sse.SSEEvent{Event: event, Data: data})
+	}
+}
+
+func collect(rows pgx.Rows) ([]Announcement, error) {
+	defer rows.Close()
+	items := []Announcement{}
+	for rows.Next() {
+		var a Announcement
+		if err := scanAnnouncement(rows, &a); err != nil {
+			return nil, apperror.Internal(fmt.Errorf("scan announcement: %w", err))
+		}
+		items = append(items, a)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("iterate announcements: %w", err))
+	}
+	return items, nil
+}
+
+func nilIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
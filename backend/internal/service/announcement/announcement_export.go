@@ -0,0 +1,44 @@
+package announcement
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Dismissal records that the user hid an announcement.
+type Dismissal struct {
+	AnnouncementID string    `json:"announcement_id"`
+	Title          string    `json:"title"`
+	DismissedAt    time.Time `json:"dismissed_at"`
+}
+
+// ExportName implements export.Exporter.
+func (s *AnnouncementService) ExportName() string { return "announcement_dismissals" }
+
+// ExportUserData implements export.Exporter: the announcements the user
+// dismissed, most recent first.
+func (s *AnnouncementService) ExportUserData(ctx context.Context, userID string) (any, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT a.id, a.title, d.dismissed_at
+		 FROM announcement_dismissals d JOIN announcements a ON a.id = d.announcement_id
+		 WHERE d.user_id = $1 ORDER BY d.dismissed_at DESC, a.id`, userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("export announcement dismissals: %w", err)
+	}
+	defer rows.Close()
+
+	items := []Dismissal{}
+	for rows.Next() {
+		var d Dismissal
+		if err := rows.Scan(&d.AnnouncementID, &d.Title, &d.DismissedAt); err != nil {
+			return nil, fmt.Errorf("scan announcement dismissal: %w", err)
+		}
+		items = append(items, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate announcement dismissals: %w", err)
+	}
+	return items, nil
+}
@@ -0,0 +1,165 @@
+//go:build integration
+
+package announcement
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/service/auth"
+	"github.com/golid-ai/golid/backend/internal/service/sse"
+	"github.com/golid-ai/golid/backend/internal/testutil"
+)
+
+type recordingPusher struct {
+	mu     sync.Mutex
+	events []sse.SSEEvent
+}
+
+func (r *recordingPusher) Broadcast(event sse.SSEEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recordingPusher) Send(_ string, event sse.SSEEvent) {
+	r.Broadcast(event)
+}
+
+func (r *recordingPusher) take() []sse.SSEEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := r.events
+	r.events = nil
+	return events
+}
+
+func TestAnnouncements_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		ctx := context.Background()
+		authSvc := auth.NewAuthService(pool, "test-jwt-secret-that-is-at-least-32-characters-long!", "test-issuer", 15*time.Minute, 7*24*time.Hour, time.Hour)
+		result, err := authSvc.Register(ctx, &auth.RegisterInput{
+			Email:     "announce@example.com",
+			Password:  "password123",
+			FirstName: "Announce",
+			LastName:  "User",
+		})
+		if err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+		userID := result.User.ID
+		user := Viewer{UserID: userID, UserType: "user"}
+
+		pusher := &recordingPusher{}
+		svc := NewAnnouncementService(pool, pusher, 20, 100)
+
+		banner, err := svc.Create(ctx, NewAnnouncement{Title: "v2 is out"}, userID)
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if banner.Severity != SeverityInfo || banner.Audience != AudienceEveryone || !banner.Dismissible {
+			t.Errorf("Create() defaults = %+v", banner)
+		}
+		if ev := pusher.take(); len(ev) != 1 || ev[0].Event != EventPublished {
+			t.Errorf("pushed = %+v, want one %s", ev, EventPublished)
+		}
+
+		locked := false
+		maint, err := svc.Create(ctx, NewAnnouncement{Title: "Maintenance", Severity: SeverityCritical, Dismissible: &locked}, userID)
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		// Admins-only announcements are never broadcast.
+		admins, err := svc.Create(ctx, NewAnnouncement{Title: "Admins only", Audience: AudienceAdmins}, userID)
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if ev := pusher.take(); len(ev) != 1 {
+			t.Errorf("pushed %d events, want 1", len(ev))
+		}
+		past := time.Now().Add(-time.Hour)
+		if _, err := svc.Create(ctx, NewAnnouncement{Title: "Old", StartsAt: &past, EndsAt: &past}, userID); err == nil {
+			t.Error("Create() with ends_at == starts_at should fail")
+		}
+
+		active, err := svc.Active(ctx, user)
+		if err != nil || len(active) != 2 || active[0].ID != maint.ID || active[1].ID != banner.ID {
+			t.Fatalf("Active(user) = %+v, %v; want maintenance then banner", active, err)
+		}
+		if active, _ := svc.Active(ctx, Viewer{UserID: userID, UserType: "admin"}); len(active) != 3 {
+			t.Errorf("Active(admin) = %d announcements, want 3", len(active))
+		}
+		if active, _ := svc.Active(ctx, Viewer{}); len(active) != 2 {
+			t.Errorf("Active(guest) = %d announcements, want 2", len(active))
+		}
+
+		if err := svc.Dismiss(ctx, userID, maint.ID); !apperror.Is(err, apperror.CodeBadRequest) {
+			t.Errorf("Dismiss(non-dismissible) error = %v", err)
+		}
+		for range 2 {
+			if err := svc.Dismiss(ctx, userID, banner.ID); err != nil {
+				t.Fatalf("Dismiss() error = %v", err)
+			}
+		}
+		if ev := pusher.take(); len(ev) != 2 || ev[0].Event != EventDismissed {
+			t.Errorf("pushed = %+v, want %s twice", ev, EventDismissed)
+		}
+		if active, _ := svc.Active(ctx, user); len(active) != 1 || active[0].ID != maint.ID {
+			t.Errorf("Active(user) after dismiss = %+v", active)
+		}
+		if active, _ := svc.Active(ctx, Viewer{}); len(active) != 2 {
+			t.Errorf("dismissal should not affect guests, got %d", len(active))
+		}
+
+		// Ending an announcement takes it down live.
+		updated, err := svc.Update(ctx, maint.ID, Update{EndsAt: &past})
+		if err == nil {
+			t.Errorf("Update() ending before start = %+v, want error", updated)
+		}
+		soon := time.Now().Add(time.Hour)
+		if _, err := svc.Update(ctx, maint.ID, Update{EndsAt: &soon}); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if updated, err = svc.Update(ctx, maint.ID, Update{ClearEndsAt: true}); err != nil || updated.EndsAt != nil {
+			t.Errorf("Update(clear ends_at) = %+v, %v", updated, err)
+		}
+		if ev := pusher.take(); len(ev) != 2 || ev[1].Event != EventPublished {
+			t.Errorf("pushed = %+v, want %s twice", ev, EventPublished)
+		}
+		audience := AudienceAdmins
+		if _, err := svc.Update(ctx, maint.ID, Update{Audience: &audience}); err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if ev := pusher.take(); len(ev) != 1 || ev[0].Event != EventRemoved {
+			t.Errorf("pushed = %+v, want %s", ev, EventRemoved)
+		}
+
+		page, err := svc.List(ctx, 1, 2)
+		if err != nil || page.Total != 3 || len(page.Announcements) != 2 || page.Announcements[0].ID != admins.ID {
+			t.Errorf("List() = %+v, %v", page, err)
+		}
+
+		exported, err := svc.ExportUserData(ctx, userID)
+		if err != nil || len(exported.([]Dismissal)) != 1 {
+			t.Errorf("ExportUserData() = %v, %v", exported, err)
+		}
+
+		if err := svc.Delete(ctx, banner.ID); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := svc.Get(ctx, banner.ID); !apperror.Is(err, apperror.CodeNotFound) {
+			t.Errorf("Get() after delete error = %v", err)
+		}
+		if err := svc.Delete(ctx, banner.ID); !apperror.Is(err, apperror.CodeNotFound) {
+			t.Errorf("Delete() twice error = %v", err)
+		}
+		if exported, _ := svc.ExportUserData(ctx, userID); len(exported.([]Dismissal)) != 0 {
+			t.Errorf("dismissals should go with the announcement, got %v", exported)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package announcement
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/service/sse"
+)
+
+// A nil pool panics if a test reaches the database.
+func newTestService() *AnnouncementService {
+	return NewAnnouncementService(nil, nil, 20, 100)
+}
+
+func TestCreate_Validation(t *testing.T) {
+	svc := newTestService()
+	start := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	before := start.Add(-time.Hour)
+
+	tests := map[string]struct {
+		in    NewAnnouncement
+		field string
+	}{
+		"blank title":       {NewAnnouncement{Title: "  "}, "title"},
+		"long title":        {NewAnnouncement{Title: strings.Repeat("x", maxTitleLen+1)}, "title"},
+		"long body":         {NewAnnouncement{Title: "t", Body: strings.Repeat("x", maxBodyLen+1)}, "body"},
+		"bad severity":      {NewAnnouncement{Title: "t", Severity: "urgent"}, "severity"},
+		"bad audience":      {NewAnnouncement{Title: "t", Audience: "staff"}, "audience"},
+		"ends before start": {NewAnnouncement{Title: "t", StartsAt: &start, EndsAt: &before}, "ends_at"},
+	}
+	for name, tt := range tests {
+		_, err := svc.Create(context.Background(), tt.in, "")
+		var appErr *apperror.AppError
+		if !errors.As(err, &appErr) || appErr.Code != apperror.CodeValidation || appErr.Details[tt.field] == "" {
+			t.Errorf("%s: error = %v, want validation error on %s", name, err, tt.field)
+		}
+	}
+}
+
+func TestBadIDs(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+
+	if _, err := svc.Get(ctx, "nope"); !apperror.Is(err, apperror.CodeBadRequest) {
+		t.Errorf("Get: error = %v, want BadRequest", err)
+	}
+	if _, err := svc.Update(ctx, "nope", Update{}); !apperror.Is(err, apperror.CodeBadRequest) {
+		t.Errorf("Update: error = %v, want BadRequest", err)
+	}
+	if err := svc.Delete(ctx, "nope"); !apperror.Is(err, apperror.CodeBadRequest) {
+		t.Errorf("Delete: error = %v, want BadRequest", err)
+	}
+	if err := svc.Dismiss(ctx, "user-1", "nope"); !apperror.Is(err, apperror.CodeBadRequest) {
+		t.Errorf("Dismiss: error = %v, want BadRequest", err)
+	}
+}
+
+func TestViewer_Audiences(t *testing.T) {
+	tests := []struct {
+		viewer Viewer
+		sees   []string
+	}{
+		{Viewer{}, []string{AudienceEveryone, AudienceGuests}},
+		{Viewer{UserID: "u", UserType: "user"}, []string{AudienceEveryone, AudienceUsers}},
+		{Viewer{UserID: "a", UserType: "admin"}, []string{AudienceEveryone, AudienceUsers, AudienceAdmins}},
+	}
+	for _, tt := range tests {
+		if got := tt.viewer.audiences(); !slices.Equal(got, tt.sees) {
+			t.Errorf("%+v sees %v, want %v", tt.viewer, got, tt.sees)
+		}
+	}
+}
+
+func TestAnnouncement_Pushed(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+	tests := []struct {
+		name string
+		a    Announcement
+		want bool
+	}{
+		{"everyone", Announcement{Audience: AudienceEveryone}, true},
+		{"users", Announcement{Audience: AudienceUsers}, true},
+		{"guests have no stream", Announcement{Audience: AudienceGuests}, false},
+		{"admins only", Announcement{Audience: AudienceAdmins}, false},
+		{"ended", Announcement{Audience: AudienceEveryone, EndsAt: &past}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.a.pushed(now); got != tt.want {
+			t.Errorf("%s: pushed = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUpdate_Apply(t *testing.T) {
+	end := time.Now()
+	title, dismissible := "New", false
+	a := Announcement{Title: "Old", Body: "kept", EndsAt: &end, Dismissible: true}
+
+	Update{Title: &title, Dismissible: &dismissible, ClearEndsAt: true}.apply(&a)
+	if a.Title != "New" || a.Body != "kept" || a.EndsAt != nil || a.Dismissible {
+		t.Errorf("after apply: %+v", a)
+	}
+}
+
+func TestAnnouncementID(t *testing.T) {
+	local := sse.SSEEvent{Event: EventPublished, Data: Announcement{ID: "a1"}}
+	remote := sse.SSEEvent{Event: EventPublished, Data: json.RawMessage(`{"id":"a1","title":"t"}`)}
+	if announcementID(local) != "a1" || announcementID(remote) != "a1" {
+		t.Errorf("keys = %q, %q, want a1", announcementID(local), announcementID(remote))
+	}
+}
@@ -15,6 +15,8 @@ import (
 
 	"github.com/golid-ai/golid/backend/internal/apperror"
 	"github.com/golid-ai/golid/backend/internal/middleware"
+	"github.com/golid-ai/golid/backend/internal/models"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
 )
 
 type dbExecer interface {
@@ -58,6 +60,12 @@ type RegisterInput struct {
 	Password  string
 	FirstName string
 	LastName  string
+
+	// VerificationEmail, when set, builds the message that emails the new
+	// user their verification token. It is written to the outbox in the
+	// registration transaction, so the email goes out once the account
+	// exists even if the process stops right after the commit.
+	VerificationEmail func(token string) (outbox.Message, error)
 }
 
 // AuthResult is returned after successful authentication.
@@ -113,7 +121,7 @@ func (s *AuthService) Register(ctx context.Context, input *RegisterInput) (*Auth
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			return nil, apperror.Conflict("Email already registered")
+			return nil, apperror.Conflict("Email already registered").WithMessageID("error.auth.email_taken")
 		}
 		return nil, apperror.Internal(fmt.Errorf("create user: %w", err))
 	}
@@ -123,6 +131,16 @@ func (s *AuthService) Register(ctx context.Context, input *RegisterInput) (*Auth
 		return nil, err
 	}
 
+	if input.VerificationEmail != nil {
+		msg, err := input.VerificationEmail(verificationToken)
+		if err != nil {
+			return nil, apperror.Internal(fmt.Errorf("build verification email: %w", err))
+		}
+		if err := outbox.Enqueue(ctx, tx, msg); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return nil, apperror.Internal(fmt.Errorf("commit transaction: %w", err))
 	}
@@ -145,21 +163,29 @@ func (s *AuthService) Login(ctx context.Context, input *LoginInput) (*AuthResult
 	var passwordHash string
 	var userType string
 	var createdAt time.Time
+	var status models.UserStatus
+	var suspendedUntil *time.Time
 
 	err := s.pool.QueryRow(ctx,
-		"SELECT id, password_hash, type, created_at FROM users WHERE email = $1",
+		"SELECT id, password_hash, type, created_at, status, suspended_until FROM users WHERE email = $1",
 		input.Email,
-	).Scan(&userID, &passwordHash, &userType, &createdAt)
+	).Scan(&userID, &passwordHash, &userType, &createdAt, &status, &suspendedUntil)
 
 	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, apperror.Unauthorized("Invalid email or password")
+		return nil, apperror.Unauthorized("Invalid email or password").WithMessageID("error.auth.invalid_credentials")
 	}
 	if err != nil {
 		return nil, apperror.Internal(fmt.Errorf("get user: %w", err))
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(input.Password)); err != nil {
-		return nil, apperror.Unauthorized("Invalid email or password")
+		return nil, apperror.Unauthorized("Invalid email or password").WithMessageID("error.auth.invalid_credentials")
+	}
+
+	// Checked after the password so the status of an account is only
+	// revealed to someone who knows its credentials.
+	if err := models.AccountStatusError(status, suspendedUntil, time.Now()); err != nil {
+		return nil, err
 	}
 
 	return s.generateAuthResult(ctx, s.pool, userID.String(), input.Email, userType, createdAt)
@@ -188,7 +214,13 @@ type RefreshInput struct {
 func (s *AuthService) Refresh(ctx context.Context, input *RefreshInput) (*AuthResult, error) {
 	claims, err := middleware.ParseToken(s.jwtSecret, input.RefreshToken)
 	if err != nil {
-		return nil, apperror.Unauthorized("Invalid refresh token")
+		return nil, apperror.Unauthorized("Invalid refresh token").WithMessageID("error.auth.invalid_refresh_token")
+	}
+
+	// Suspending an account revokes its refresh tokens, so check status
+	// first to return ACCOUNT_SUSPENDED rather than a generic revocation.
+	if err := s.checkAccountStatus(ctx, claims.Subject); err != nil {
+		return nil, err
 	}
 
 	tokenHash := hashVerifier(input.RefreshToken)
@@ -208,7 +240,7 @@ func (s *AuthService) Refresh(ctx context.Context, input *RefreshInput) (*AuthRe
 	).Scan(&storedUserID)
 
 	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, apperror.Unauthorized("Refresh token revoked or expired")
+		return nil, apperror.Unauthorized("Refresh token revoked or expired").WithMessageID("error.auth.refresh_token_revoked")
 	}
 	if err != nil {
 		return nil, apperror.Internal(fmt.Errorf("revoke refresh token: %w", err))
@@ -217,18 +249,23 @@ func (s *AuthService) Refresh(ctx context.Context, input *RefreshInput) (*AuthRe
 	var email string
 	var userType string
 	var createdAt time.Time
+	var status models.UserStatus
+	var suspendedUntil *time.Time
 
 	err = tx.QueryRow(ctx,
-		"SELECT email, type, created_at FROM users WHERE id = $1",
+		"SELECT email, type, created_at, status, suspended_until FROM users WHERE id = $1",
 		claims.Subject,
-	).Scan(&email, &userType, &createdAt)
+	).Scan(&email, &userType, &createdAt, &status, &suspendedUntil)
 
 	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, apperror.Unauthorized("User not found")
+		return nil, apperror.Unauthorized("User not found").WithMessageID("error.not_found.user")
 	}
 	if err != nil {
 		return nil, apperror.Internal(fmt.Errorf("get user: %w", err))
 	}
+	if err := models.AccountStatusError(status, suspendedUntil, time.Now()); err != nil {
+		return nil, err
+	}
 
 	result, err := s.generateAuthResult(ctx, tx, claims.Subject, email, userType, createdAt)
 	if err != nil {
@@ -242,6 +279,24 @@ func (s *AuthService) Refresh(ctx context.Context, input *RefreshInput) (*AuthRe
 	return result, nil
 }
 
+// checkAccountStatus returns ACCOUNT_SUSPENDED if the user is suspended or
+// banned. Unknown users are left to the caller's own lookup.
+func (s *AuthService) checkAccountStatus(ctx context.Context, userID string) error {
+	var status models.UserStatus
+	var suspendedUntil *time.Time
+	err := s.pool.QueryRow(ctx,
+		"SELECT status, suspended_until FROM users WHERE id = $1",
+		userID,
+	).Scan(&status, &suspendedUntil)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("get account status: %w", err))
+	}
+	return models.AccountStatusError(status, suspendedUntil, time.Now())
+}
+
 // generateAuthResult creates tokens and stores the refresh token.
 func (s *AuthService) generateAuthResult(ctx context.Context, db dbExecer, userID, email, userType string, createdAt time.Time) (*AuthResult, error) {
 	accessToken, err := middleware.GenerateToken(s.jwtSecret, userID, userType, s.jwtIssuer, s.accessDuration)
@@ -279,27 +334,27 @@ func (s *AuthService) generateAuthResult(ctx context.Context, db dbExecer, userI
 
 // validateRegisterInput validates registration input.
 func validateRegisterInput(input *RegisterInput) error {
-	details := make(map[string]string)
+	verr := apperror.Validation()
 
 	if input.Email == "" {
-		details["email"] = "Email is required"
+		verr.WithDetail("email", "error.auth.email_required")
 	} else if !strings.Contains(input.Email, "@") || !strings.Contains(input.Email[strings.LastIndex(input.Email, "@"):], ".") {
-		details["email"] = "Invalid email format"
+		verr.WithDetail("email", "error.auth.email_invalid")
 	}
 	if len(input.Password) < 8 {
-		details["password"] = "Password must be at least 8 characters"
+		verr.WithDetail("password", "error.auth.password_too_short")
 	} else if len(input.Password) > 72 {
-		details["password"] = "Password must not exceed 72 characters"
+		verr.WithDetail("password", "error.auth.password_too_long")
 	}
 	if input.FirstName == "" {
-		details["first_name"] = "First name is required"
+		verr.WithDetail("first_name", "error.auth.first_name_required")
 	}
 	if input.LastName == "" {
-		details["last_name"] = "Last name is required"
+		verr.WithDetail("last_name", "error.auth.last_name_required")
 	}
 
-	if len(details) > 0 {
-		return apperror.Validation("Validation failed", details)
+	if len(verr.Details) > 0 {
+		return verr
 	}
 	return nil
 }
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+)
+
+// SessionExport is one refresh token as it appears in a data export. The
+// token hash is never exported.
+type SessionExport struct {
+	ID        string     `json:"id"`
+	CreatedAt *time.Time `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	Revoked   bool       `json:"revoked"`
+}
+
+// ExportName implements export.Exporter.
+func (s *AuthService) ExportName() string { return "sessions" }
+
+// ExportUserData implements export.Exporter: the user's refresh token
+// sessions, newest first.
+func (s *AuthService) ExportUserData(ctx context.Context, userID string) (any, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT id, created_at, expires_at, COALESCE(revoked, FALSE)
+		 FROM refresh_tokens WHERE user_id = $1
+		 ORDER BY created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("export sessions: %w", err))
+	}
+	defer rows.Close()
+
+	sessions := []SessionExport{}
+	for rows.Next() {
+		var sess SessionExport
+		if err := rows.Scan(&sess.ID, &sess.CreatedAt, &sess.ExpiresAt, &sess.Revoked); err != nil {
+			return nil, apperror.Internal(fmt.Errorf("scan session: %w", err))
+		}
+		sessions = append(sessions, sess)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("iterate sessions: %w", err))
+	}
+	return sessions, nil
+}
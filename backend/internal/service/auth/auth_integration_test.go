@@ -4,10 +4,14 @@ package auth
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/hibiken/asynq"
+
 	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
 	"github.com/golid-ai/golid/backend/internal/testutil"
 )
 
@@ -77,6 +81,45 @@ func TestRegister_DuplicateEmail_Integration(t *testing.T) {
 	}
 }
 
+func TestRegister_VerificationEmailOutbox_Integration(t *testing.T) {
+	svc, cleanup := newTestAuthService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	var token string
+	input := &RegisterInput{
+		Email:     "outbox@example.com",
+		Password:  "password123",
+		FirstName: "Test",
+		LastName:  "User",
+		VerificationEmail: func(tok string) (outbox.Message, error) {
+			token = tok
+			return outbox.Task(asynq.NewTask("email:verification", []byte(`{"token":"`+tok+`"}`))), nil
+		},
+	}
+	result, err := svc.Register(ctx, input)
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if token == "" || token != result.VerificationToken {
+		t.Errorf("VerificationEmail got token %q, result has %q", token, result.VerificationToken)
+	}
+
+	// A rejected registration writes nothing.
+	if _, err := svc.Register(ctx, input); !apperror.Is(err, apperror.CodeConflict) {
+		t.Fatalf("second Register() error = %v, want conflict", err)
+	}
+
+	var kind, name string
+	var payload []byte
+	if err := svc.pool.QueryRow(ctx, `SELECT kind, name, payload FROM outbox`).Scan(&kind, &name, &payload); err != nil {
+		t.Fatalf("expected one outbox row: %v", err)
+	}
+	if kind != outbox.KindTask || name != "email:verification" || !strings.Contains(string(payload), result.VerificationToken) {
+		t.Errorf("outbox row = %s %s %s", kind, name, payload)
+	}
+}
+
 func TestLogin_Integration(t *testing.T) {
 	svc, cleanup := newTestAuthService(t)
 	defer cleanup()
@@ -421,6 +464,45 @@ func TestForgotPassword_ResetFlow_Integration(t *testing.T) {
 	}
 }
 
+func TestForgotPassword_ResetEmailOutbox_Integration(t *testing.T) {
+	svc, cleanup := newTestAuthService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	if _, err := svc.Register(ctx, &RegisterInput{
+		Email:     "reset-outbox@example.com",
+		Password:  "password123",
+		FirstName: "Test",
+		LastName:  "User",
+	}); err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	resetEmail := func(tok string) (outbox.Message, error) {
+		return outbox.Task(asynq.NewTask("email:password_reset", []byte(`{"token":"`+tok+`"}`))), nil
+	}
+
+	// No user, no token, no email.
+	token, err := svc.ForgotPassword(ctx, &ForgotPasswordInput{Email: "nobody@example.com", ResetEmail: resetEmail})
+	if err != nil || token != "" {
+		t.Fatalf("ForgotPassword(unknown) = %q, %v", token, err)
+	}
+
+	token, err = svc.ForgotPassword(ctx, &ForgotPasswordInput{Email: "reset-outbox@example.com", ResetEmail: resetEmail})
+	if err != nil {
+		t.Fatalf("ForgotPassword() error = %v", err)
+	}
+
+	var kind, name string
+	var payload []byte
+	if err := svc.pool.QueryRow(ctx, `SELECT kind, name, payload FROM outbox`).Scan(&kind, &name, &payload); err != nil {
+		t.Fatalf("expected one outbox row: %v", err)
+	}
+	if kind != outbox.KindTask || name != "email:password_reset" || !strings.Contains(string(payload), token) {
+		t.Errorf("outbox row = %s %s %s", kind, name, payload)
+	}
+}
+
 func TestResetPassword_AntiReplay_Integration(t *testing.T) {
 	svc, cleanup := newTestAuthService(t)
 	defer cleanup()
@@ -567,3 +649,52 @@ func setEmailVerified(t *testing.T, svc *AuthService, email string, verified boo
 		t.Fatalf("setEmailVerified(%s, %t): %v", email, verified, err)
 	}
 }
+
+func TestSuspendedAccount_LoginAndRefresh_Integration(t *testing.T) {
+	svc, cleanup := newTestAuthService(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	result, err := svc.Register(ctx, &RegisterInput{
+		Email:     "suspended@example.com",
+		Password:  "password123",
+		FirstName: "Test",
+		LastName:  "User",
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+
+	if _, err := svc.pool.Exec(ctx,
+		"UPDATE users SET status = 'suspended', suspended_until = NOW() + INTERVAL '1 day' WHERE id = $1",
+		result.User.ID,
+	); err != nil {
+		t.Fatalf("suspend user: %v", err)
+	}
+
+	_, err = svc.Login(ctx, &LoginInput{Email: "suspended@example.com", Password: "password123"})
+	if !apperror.Is(err, apperror.CodeAccountSuspended) {
+		t.Errorf("Login() err = %v, want ACCOUNT_SUSPENDED", err)
+	}
+
+	_, err = svc.Login(ctx, &LoginInput{Email: "suspended@example.com", Password: "wrong-password"})
+	if !apperror.Is(err, apperror.CodeUnauthorized) {
+		t.Errorf("Login() with wrong password err = %v, want Unauthorized", err)
+	}
+
+	_, err = svc.Refresh(ctx, &RefreshInput{RefreshToken: result.RefreshToken})
+	if !apperror.Is(err, apperror.CodeAccountSuspended) {
+		t.Errorf("Refresh() err = %v, want ACCOUNT_SUSPENDED", err)
+	}
+
+	// A lapsed suspension no longer blocks login.
+	if _, err := svc.pool.Exec(ctx,
+		"UPDATE users SET suspended_until = NOW() - INTERVAL '1 minute' WHERE id = $1",
+		result.User.ID,
+	); err != nil {
+		t.Fatalf("lapse suspension: %v", err)
+	}
+	if _, err := svc.Login(ctx, &LoginInput{Email: "suspended@example.com", Password: "password123"}); err != nil {
+		t.Errorf("Login() after suspension lapsed error = %v", err)
+	}
+}
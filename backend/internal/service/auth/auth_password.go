@@ -17,6 +17,7 @@ import (
 	"golang.org/x/crypto/bcrypt"
 
 	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
 )
 
 // ============================================================================
@@ -33,14 +34,10 @@ type ChangePasswordInput struct {
 // ChangePassword changes a user's password after verifying their current one.
 func (s *AuthService) ChangePassword(ctx context.Context, input *ChangePasswordInput) error {
 	if len(input.NewPassword) < 8 {
-		return apperror.Validation("Validation failed", map[string]string{
-			"new_password": "Password must be at least 8 characters",
-		})
+		return apperror.Validation().WithDetail("new_password", "error.auth.password_too_short")
 	}
 	if len(input.NewPassword) > 72 {
-		return apperror.Validation("Validation failed", map[string]string{
-			"new_password": "Password must not exceed 72 characters",
-		})
+		return apperror.Validation().WithDetail("new_password", "error.auth.password_too_long")
 	}
 
 	var passwordHash string
@@ -50,14 +47,14 @@ func (s *AuthService) ChangePassword(ctx context.Context, input *ChangePasswordI
 	).Scan(&passwordHash)
 
 	if errors.Is(err, pgx.ErrNoRows) {
-		return apperror.NotFound("User not found")
+		return apperror.NotFound("User")
 	}
 	if err != nil {
 		return apperror.Internal(fmt.Errorf("get user: %w", err))
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(input.CurrentPassword)); err != nil {
-		return apperror.BadRequest("Current password is incorrect")
+		return apperror.BadRequest("Current password is incorrect").WithMessageID("error.auth.wrong_password")
 	}
 
 	newHash, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
@@ -101,6 +98,12 @@ func (s *AuthService) ChangePassword(ctx context.Context, input *ChangePasswordI
 // ForgotPasswordInput is the input for forgot password.
 type ForgotPasswordInput struct {
 	Email string
+
+	// ResetEmail, when set, builds the message that emails the user their
+	// reset token. It is written to the outbox with the token, so the email
+	// goes out once the token is stored even if the process stops right
+	// after the commit.
+	ResetEmail func(token string) (outbox.Message, error)
 }
 
 // ForgotPassword initiates a password reset using the selector.verifier pattern.
@@ -109,7 +112,7 @@ func (s *AuthService) ForgotPassword(ctx context.Context, input *ForgotPasswordI
 	input.Email = strings.ToLower(strings.TrimSpace(input.Email))
 
 	if input.Email == "" {
-		return "", apperror.BadRequest("Email is required")
+		return "", apperror.BadRequest("Email is required").WithMessageID("error.auth.email_required")
 	}
 
 	var userID uuid.UUID
@@ -133,7 +136,13 @@ func (s *AuthService) ForgotPassword(ctx context.Context, input *ForgotPasswordI
 	verifierHash := hashVerifier(verifier)
 	expires := time.Now().Add(s.passwordResetTTL)
 
-	_, err = s.pool.Exec(ctx,
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return "", apperror.Internal(fmt.Errorf("begin tx: %w", err))
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	_, err = tx.Exec(ctx,
 		`UPDATE users 
 		 SET password_reset_selector = $2, 
 		     password_reset_verifier_hash = $3, 
@@ -145,6 +154,20 @@ func (s *AuthService) ForgotPassword(ctx context.Context, input *ForgotPasswordI
 		return "", apperror.Internal(fmt.Errorf("store reset token: %w", err))
 	}
 
+	if input.ResetEmail != nil {
+		msg, err := input.ResetEmail(token)
+		if err != nil {
+			return "", apperror.Internal(fmt.Errorf("build password reset email: %w", err))
+		}
+		if err := outbox.Enqueue(ctx, tx, msg); err != nil {
+			return "", err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return "", apperror.Internal(fmt.Errorf("commit tx: %w", err))
+	}
+
 	return token, nil
 }
 
@@ -162,7 +185,7 @@ type VerifyResetTokenResult struct {
 // VerifyResetToken checks if a password reset token is valid.
 func (s *AuthService) VerifyResetToken(ctx context.Context, input *VerifyResetTokenInput) (*VerifyResetTokenResult, error) {
 	if input.Token == "" {
-		return nil, apperror.BadRequest("Token is required")
+		return nil, apperror.BadRequest("Token is required").WithMessageID("error.auth.token_required")
 	}
 
 	selector, verifier, err := parseResetToken(input.Token)
@@ -206,22 +229,18 @@ type ResetPasswordInput struct {
 // ResetPassword resets a user's password using a valid reset token.
 func (s *AuthService) ResetPassword(ctx context.Context, input *ResetPasswordInput) error {
 	if input.Token == "" {
-		return apperror.BadRequest("Token is required")
+		return apperror.BadRequest("Token is required").WithMessageID("error.auth.token_required")
 	}
 	if len(input.NewPassword) < 8 {
-		return apperror.Validation("Validation failed", map[string]string{
-			"password": "Password must be at least 8 characters",
-		})
+		return apperror.Validation().WithDetail("password", "error.auth.password_too_short")
 	}
 	if len(input.NewPassword) > 72 {
-		return apperror.Validation("Validation failed", map[string]string{
-			"password": "Password must not exceed 72 characters",
-		})
+		return apperror.Validation().WithDetail("password", "error.auth.password_too_long")
 	}
 
 	selector, verifier, err := parseResetToken(input.Token)
 	if err != nil {
-		return apperror.BadRequest("Invalid reset token")
+		return apperror.BadRequest("Invalid or expired reset token").WithMessageID("error.auth.invalid_reset_token")
 	}
 
 	tx, err := s.pool.Begin(ctx)
@@ -243,14 +262,14 @@ func (s *AuthService) ResetPassword(ctx context.Context, input *ResetPasswordInp
 	).Scan(&userID, &storedHash)
 
 	if errors.Is(err, pgx.ErrNoRows) {
-		return apperror.BadRequest("Invalid or expired reset token")
+		return apperror.BadRequest("Invalid or expired reset token").WithMessageID("error.auth.invalid_reset_token")
 	}
 	if err != nil {
 		return apperror.Internal(fmt.Errorf("get user: %w", err))
 	}
 
 	if !verifyHash(verifier, storedHash) {
-		return apperror.BadRequest("Invalid reset token")
+		return apperror.BadRequest("Invalid or expired reset token").WithMessageID("error.auth.invalid_reset_token")
 	}
 
 	hash, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
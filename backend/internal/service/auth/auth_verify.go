@@ -26,12 +26,12 @@ type VerifyEmailInput struct {
 // VerifyEmail verifies a user's email address.
 func (s *AuthService) VerifyEmail(ctx context.Context, input *VerifyEmailInput) error {
 	if input.Token == "" {
-		return apperror.BadRequest("Token is required")
+		return apperror.BadRequest("Token is required").WithMessageID("error.auth.token_required")
 	}
 
 	selector, verifier, err := parseVerificationToken(input.Token)
 	if err != nil {
-		return apperror.BadRequest("Invalid verification token")
+		return apperror.BadRequest("Invalid or already-used verification token").WithMessageID("error.auth.invalid_verification_token")
 	}
 
 	tx, err := s.pool.Begin(ctx)
@@ -50,14 +50,14 @@ func (s *AuthService) VerifyEmail(ctx context.Context, input *VerifyEmailInput)
 	).Scan(&userID, &storedHash)
 
 	if errors.Is(err, pgx.ErrNoRows) {
-		return apperror.BadRequest("Invalid or already-used verification token")
+		return apperror.BadRequest("Invalid or already-used verification token").WithMessageID("error.auth.invalid_verification_token")
 	}
 	if err != nil {
 		return apperror.Internal(fmt.Errorf("verify email: %w", err))
 	}
 
 	if !verifyHash(verifier, storedHash) {
-		return apperror.BadRequest("Invalid verification token")
+		return apperror.BadRequest("Invalid or already-used verification token").WithMessageID("error.auth.invalid_verification_token")
 	}
 
 	_, err = tx.Exec(ctx,
@@ -86,7 +86,7 @@ func (s *AuthService) ResendVerification(ctx context.Context, input *ResendVerif
 	input.Email = strings.ToLower(strings.TrimSpace(input.Email))
 
 	if input.Email == "" {
-		return "", apperror.BadRequest("Email is required")
+		return "", apperror.BadRequest("Email is required").WithMessageID("error.auth.email_required")
 	}
 
 	var userID uuid.UUID
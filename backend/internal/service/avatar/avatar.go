@@ -0,0 +1,297 @@
+// Package avatar turns uploaded images into the square PNG renditions
+// served as user avatars.
+//
+// Stage runs in the request: it decodes the upload (rejecting non-images
+// and decompression bombs) and parks the original bytes in blob storage.
+// Process runs in a queue job: it re-encodes the renditions from decoded
+// pixels — which drops EXIF/GPS metadata — publishes them, and points
+// users.avatar_url at the DisplaySize rendition. The staged original is
+// never served and is deleted once processed.
+package avatar
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/imageproc"
+	"github.com/golid-ai/golid/backend/internal/logger"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
+	"github.com/golid-ai/golid/backend/internal/storage"
+	"github.com/golid-ai/golid/backend/internal/validate"
+)
+
+// Sizes are the square renditions generated for every upload, in pixels.
+var Sizes = []int{64, 128, 256, 512}
+
+// DisplaySize is the rendition users.avatar_url points at.
+const DisplaySize = 256
+
+// ErrInvalidImage is returned by Process when the staged upload cannot be
+// decoded. It is permanent: retrying will not help.
+var ErrInvalidImage = errors.New("avatar: staged upload is not a valid image")
+
+// Config controls upload limits and the public URL of renditions.
+type Config struct {
+	MaxUploadSize int64  // bytes
+	MaxPixels     int    // decoded width × height
+	BaseURL       string // prefix for avatar URLs; empty = relative paths
+}
+
+// AvatarService stages and processes avatar uploads.
+type AvatarService struct {
+	pool *pgxpool.Pool
+	blob storage.Blob
+	cfg  Config
+}
+
+// NewAvatarService creates a new avatar service.
+func NewAvatarService(pool *pgxpool.Pool, blob storage.Blob, cfg Config) *AvatarService {
+	return &AvatarService{pool: pool, blob: blob, cfg: cfg}
+}
+
+// prefix is the storage prefix for one upload. Upload IDs are UUIDv7, so
+// prefixes for the same user sort by upload time.
+func prefix(userID, uploadID string) string {
+	return "avatars/" + userID + "/" + uploadID
+}
+
+func sourceKey(userID, uploadID string) string {
+	return prefix(userID, uploadID) + "/source"
+}
+
+func renditionKey(avatarKey string, size int) string {
+	return avatarKey + "/" + strconv.Itoa(size) + ".png"
+}
+
+// URL returns the public URL of a rendition.
+func (s *AvatarService) URL(userID, uploadID string, size int) string {
+	return s.cfg.BaseURL + "/api/v1/avatars/" + userID + "/" + uploadID + "/" + strconv.Itoa(size) + ".png"
+}
+
+// Stage validates an upload by decoding it and stores the original for
+// Process. Returns the upload ID to pass to Process.
+//
+// process, when set, builds the message that runs Process for the upload.
+// It is written to the outbox once the original is stored, so the upload
+// is processed even if this process stops right after Stage returns.
+func (s *AvatarService) Stage(ctx context.Context, userID string, data []byte, process func(uploadID string) (outbox.Message, error)) (string, error) {
+	if int64(len(data)) > s.cfg.MaxUploadSize {
+		return "", apperror.Validation().WithDetail("avatar", "error.avatar.too_large", s.cfg.MaxUploadSize)
+	}
+	if _, err := imageproc.Decode(data, imageproc.Limits{MaxPixels: s.cfg.MaxPixels}); err != nil {
+		if errors.Is(err, imageproc.ErrTooLarge) {
+			return "", apperror.Validation().WithDetail("avatar", "error.avatar.too_many_pixels", s.cfg.MaxPixels)
+		}
+		return "", apperror.Validation().WithDetail("avatar", "error.avatar.not_image")
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", apperror.Internal(fmt.Errorf("generate upload id: %w", err))
+	}
+	uploadID := id.String()
+	src := sourceKey(userID, uploadID)
+	if err := s.blob.Put(ctx, src, bytes.NewReader(data), int64(len(data)), "application/octet-stream"); err != nil {
+		return "", apperror.Internal(fmt.Errorf("stage avatar: %w", err))
+	}
+
+	if process != nil {
+		if err := s.enqueueProcess(ctx, uploadID, process); err != nil {
+			s.deleteKeys(ctx, src)
+			return "", err
+		}
+	}
+	return uploadID, nil
+}
+
+// enqueueProcess writes the message built by process to the outbox.
+func (s *AvatarService) enqueueProcess(ctx context.Context, uploadID string, process func(uploadID string) (outbox.Message, error)) error {
+	msg, err := process(uploadID)
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("build avatar processing task: %w", err))
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("begin tx: %w", err))
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := outbox.Enqueue(ctx, tx, msg); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return apperror.Internal(fmt.Errorf("commit tx: %w", err))
+	}
+	return nil
+}
+
+// Process generates and publishes the renditions for a staged upload and
+// makes it the user's avatar, unless a newer upload already has. Safe to
+// retry: a missing source means the upload was already processed.
+func (s *AvatarService) Process(ctx context.Context, userID, uploadID string) error {
+	src := sourceKey(userID, uploadID)
+	rc, _, err := s.blob.Get(ctx, src)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("read staged avatar: %w", err)
+	}
+	data, err := io.ReadAll(io.LimitReader(rc, s.cfg.MaxUploadSize+1))
+	_ = rc.Close()
+	if err != nil {
+		return fmt.Errorf("read staged avatar: %w", err)
+	}
+
+	img, err := imageproc.Decode(data, imageproc.Limits{MaxPixels: s.cfg.MaxPixels})
+	if err != nil {
+		s.deleteKeys(ctx, src)
+		return fmt.Errorf("%w: %v", ErrInvalidImage, err)
+	}
+
+	key := prefix(userID, uploadID)
+	written := make([]string, 0, len(Sizes))
+	for size, rendition := range imageproc.Squares(img, Sizes) {
+		encoded, err := imageproc.EncodePNG(rendition)
+		if err != nil {
+			s.deleteKeys(ctx, written...)
+			return err
+		}
+		k := renditionKey(key, size)
+		if err := s.blob.Put(ctx, k, bytes.NewReader(encoded), int64(len(encoded)), "image/png"); err != nil {
+			s.deleteKeys(ctx, written...)
+			return fmt.Errorf("store avatar rendition: %w", err)
+		}
+		written = append(written, k)
+	}
+
+	old, applied, err := s.setAvatar(ctx, userID, key, s.URL(userID, uploadID, DisplaySize))
+	if err != nil {
+		return err
+	}
+	if !applied {
+		// Superseded by a newer upload (or the user is gone).
+		s.deleteKeys(ctx, append(written, src)...)
+		return nil
+	}
+	s.deleteKeys(ctx, src)
+	if old != "" {
+		s.deleteKeys(ctx, renditionKeys(old)...)
+	}
+	return nil
+}
+
+// setAvatar points the user at key unless their current avatar is newer.
+// Returns the previous avatar key and whether the update was applied.
+func (s *AvatarService) setAvatar(ctx context.Context, userID, key, url string) (string, bool, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return "", false, fmt.Errorf("begin tx: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var old *string
+	err = tx.QueryRow(ctx, `SELECT avatar_key FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&old)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("lock user avatar: %w", err)
+	}
+	if old != nil && *old >= key {
+		return "", false, nil
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE users SET avatar_url = $2, avatar_key = $3, updated_at = NOW() WHERE id = $1`,
+		userID, url, key,
+	); err != nil {
+		return "", false, fmt.Errorf("set avatar: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return "", false, fmt.Errorf("commit tx: %w", err)
+	}
+	if old == nil {
+		return "", true, nil
+	}
+	return *old, true, nil
+}
+
+// Remove clears the user's avatar and deletes its renditions.
+func (s *AvatarService) Remove(ctx context.Context, userID string) error {
+	var old *string
+	err := s.pool.QueryRow(ctx,
+		`WITH prev AS (SELECT id, avatar_key FROM users WHERE id = $1 FOR UPDATE)
+		 UPDATE users u SET avatar_url = NULL, avatar_key = NULL, updated_at = NOW()
+		 FROM prev
+		 WHERE u.id = prev.id
+		 RETURNING prev.avatar_key`,
+		userID,
+	).Scan(&old)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return apperror.NotFound("User")
+	}
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("remove avatar: %w", err))
+	}
+	if old != nil {
+		s.deleteKeys(ctx, renditionKeys(*old)...)
+	}
+	return nil
+}
+
+// OpenRendition returns a reader over a published rendition.
+func (s *AvatarService) OpenRendition(ctx context.Context, userID, uploadID string, size int) (io.ReadCloser, error) {
+	if err := validate.UUID(userID, "user_id"); err != nil {
+		return nil, err
+	}
+	if err := validate.UUID(uploadID, "upload_id"); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(Sizes, size) {
+		return nil, apperror.NotFound("Avatar")
+	}
+	rc, _, err := s.blob.Get(ctx, renditionKey(prefix(userID, uploadID), size))
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, apperror.NotFound("Avatar")
+	}
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("open avatar: %w", err))
+	}
+	return rc, nil
+}
+
+func renditionKeys(avatarKey string) []string {
+	keys := make([]string, len(Sizes))
+	for i, size := range Sizes {
+		keys[i] = renditionKey(avatarKey, size)
+	}
+	return keys
+}
+
+// deleteKeys removes objects best-effort; leftovers are unreachable.
+func (s *AvatarService) deleteKeys(ctx context.Context, keys ...string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+	for _, key := range keys {
+		if err := s.blob.Delete(ctx, key); err != nil {
+			logger.Warn("failed to delete avatar object",
+				slog.String("key", key),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
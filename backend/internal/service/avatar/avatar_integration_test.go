@@ -0,0 +1,109 @@
+//go:build integration
+
+package avatar
+
+import (
+	"context"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/golid-ai/golid/backend/internal/service/auth"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
+	"github.com/golid-ai/golid/backend/internal/storage"
+	"github.com/golid-ai/golid/backend/internal/testutil"
+)
+
+func registerAvatarUser(t *testing.T, pool *pgxpool.Pool) string {
+	t.Helper()
+	authSvc := auth.NewAuthService(pool, "test-jwt-secret-that-is-at-least-32-characters-long!", "test-issuer", 15*time.Minute, 7*24*time.Hour, time.Hour)
+	result, err := authSvc.Register(context.Background(), &auth.RegisterInput{
+		Email:     "avatar@example.com",
+		Password:  "password123",
+		FirstName: "Avatar",
+		LastName:  "User",
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	return result.User.ID
+}
+
+func avatarURL(t *testing.T, pool *pgxpool.Pool, userID string) *string {
+	t.Helper()
+	var url *string
+	if err := pool.QueryRow(context.Background(), `SELECT avatar_url FROM users WHERE id = $1`, userID).Scan(&url); err != nil {
+		t.Fatalf("read avatar_url: %v", err)
+	}
+	return url
+}
+
+func TestAvatarLifecycle_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		ctx := context.Background()
+		blob := storage.NewLocal(t.TempDir())
+		svc := NewAvatarService(pool, blob, Config{MaxUploadSize: 1 << 20, MaxPixels: 1 << 20})
+		userID := registerAvatarUser(t, pool)
+
+		first, err := svc.Stage(ctx, userID, testPNG(t, 300, 200), func(uploadID string) (outbox.Message, error) {
+			return outbox.Task(asynq.NewTask("image:process_avatar", []byte(`{"upload_id":"`+uploadID+`"}`))), nil
+		})
+		if err != nil {
+			t.Fatalf("Stage() error = %v", err)
+		}
+		var payload string
+		if err := pool.QueryRow(ctx, `SELECT payload::text FROM outbox`).Scan(&payload); err != nil || !strings.Contains(payload, first) {
+			t.Errorf("outbox payload = %q, %v; want the processing task for %s", payload, err, first)
+		}
+		second, err := svc.Stage(ctx, userID, testPNG(t, 80, 80), nil)
+		if err != nil {
+			t.Fatalf("Stage() error = %v", err)
+		}
+
+		// Process out of order: the older upload must not replace the newer.
+		if err := svc.Process(ctx, userID, second); err != nil {
+			t.Fatalf("Process(second) error = %v", err)
+		}
+		if err := svc.Process(ctx, userID, first); err != nil {
+			t.Fatalf("Process(first) error = %v", err)
+		}
+		if got, want := avatarURL(t, pool, userID), svc.URL(userID, second, DisplaySize); got == nil || *got != want {
+			t.Fatalf("avatar_url = %v, want %q", got, want)
+		}
+		if _, err := blob.Stat(ctx, renditionKey(prefix(userID, first), 64)); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("superseded renditions should be deleted, Stat error = %v", err)
+		}
+
+		for _, size := range Sizes {
+			rc, err := svc.OpenRendition(ctx, userID, second, size)
+			if err != nil {
+				t.Fatalf("OpenRendition(%d) error = %v", size, err)
+			}
+			cfg, err := png.DecodeConfig(rc)
+			_ = rc.Close()
+			if err != nil || cfg.Width != size || cfg.Height != size {
+				t.Errorf("rendition %d = %dx%d, %v", size, cfg.Width, cfg.Height, err)
+			}
+		}
+
+		// Retrying a processed upload is a no-op.
+		if err := svc.Process(ctx, userID, second); err != nil {
+			t.Errorf("Process(retry) error = %v", err)
+		}
+
+		if err := svc.Remove(ctx, userID); err != nil {
+			t.Fatalf("Remove() error = %v", err)
+		}
+		if got := avatarURL(t, pool, userID); got != nil {
+			t.Errorf("avatar_url after Remove = %q, want NULL", *got)
+		}
+		if _, err := blob.Stat(ctx, renditionKey(prefix(userID, second), DisplaySize)); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("renditions should be deleted on Remove, Stat error = %v", err)
+		}
+	})
+}
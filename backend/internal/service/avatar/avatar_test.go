@@ -0,0 +1,121 @@
+package avatar
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/storage"
+)
+
+const (
+	testUserID   = "11111111-1111-1111-1111-111111111111"
+	testUploadID = "01900000-0000-7000-8000-000000000000"
+)
+
+func newTestService(t *testing.T) (*AvatarService, storage.Blob) {
+	t.Helper()
+	blob := storage.NewLocal(t.TempDir())
+	return NewAvatarService(nil, blob, Config{
+		MaxUploadSize: 1 << 20,
+		MaxPixels:     1 << 20,
+		BaseURL:       "https://api.example.com",
+	}), blob
+}
+
+func testPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, w, h))); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestStage_StoresSource(t *testing.T) {
+	svc, blob := newTestService(t)
+	data := testPNG(t, 20, 10)
+
+	uploadID, err := svc.Stage(context.Background(), testUserID, data, nil)
+	if err != nil {
+		t.Fatalf("Stage() error = %v", err)
+	}
+	rc, _, err := blob.Get(context.Background(), sourceKey(testUserID, uploadID))
+	if err != nil {
+		t.Fatalf("source not stored: %v", err)
+	}
+	defer rc.Close()
+	got, _ := io.ReadAll(rc)
+	if !bytes.Equal(got, data) {
+		t.Error("staged bytes differ from upload")
+	}
+}
+
+func TestStage_RejectsInvalidUploads(t *testing.T) {
+	svc, _ := newTestService(t)
+	svc.cfg.MaxPixels = 100
+
+	tests := map[string][]byte{
+		"not an image": []byte("<svg xmlns='http://www.w3.org/2000/svg'/>"),
+		"too large":    bytes.Repeat([]byte{0}, int(svc.cfg.MaxUploadSize)+1),
+		"too many px":  testPNG(t, 20, 10),
+	}
+	for name, data := range tests {
+		if _, err := svc.Stage(context.Background(), testUserID, data, nil); !apperror.Is(err, apperror.CodeValidation) {
+			t.Errorf("%s: Stage() error = %v, want Validation", name, err)
+		}
+	}
+}
+
+func TestProcess_MissingSourceIsNoop(t *testing.T) {
+	svc, _ := newTestService(t)
+	// A nil pool would panic if Process got as far as the database.
+	if err := svc.Process(context.Background(), testUserID, testUploadID); err != nil {
+		t.Errorf("Process() error = %v, want nil for already-processed upload", err)
+	}
+}
+
+func TestProcess_InvalidSourceIsPermanent(t *testing.T) {
+	svc, blob := newTestService(t)
+	ctx := context.Background()
+	key := sourceKey(testUserID, testUploadID)
+	if err := blob.Put(ctx, key, strings.NewReader("garbage"), 7, "application/octet-stream"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := svc.Process(ctx, testUserID, testUploadID); !errors.Is(err, ErrInvalidImage) {
+		t.Fatalf("Process() error = %v, want ErrInvalidImage", err)
+	}
+	if _, err := blob.Stat(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("invalid source should be deleted, Stat error = %v", err)
+	}
+}
+
+func TestURL(t *testing.T) {
+	svc, _ := newTestService(t)
+	want := "https://api.example.com/api/v1/avatars/" + testUserID + "/" + testUploadID + "/256.png"
+	if got := svc.URL(testUserID, testUploadID, 256); got != want {
+		t.Errorf("URL() = %q, want %q", got, want)
+	}
+}
+
+func TestOpenRendition_Validation(t *testing.T) {
+	svc, _ := newTestService(t)
+	ctx := context.Background()
+
+	if _, err := svc.OpenRendition(ctx, "../etc", testUploadID, 64); !apperror.Is(err, apperror.CodeBadRequest) {
+		t.Errorf("bad user id error = %v, want BadRequest", err)
+	}
+	if _, err := svc.OpenRendition(ctx, testUserID, testUploadID, 100); !apperror.Is(err, apperror.CodeNotFound) {
+		t.Errorf("unknown size error = %v, want NotFound", err)
+	}
+	if _, err := svc.OpenRendition(ctx, testUserID, testUploadID, 64); !apperror.Is(err, apperror.CodeNotFound) {
+		t.Errorf("missing rendition error = %v, want NotFound", err)
+	}
+}
@@ -3,6 +3,7 @@ package email
 import (
 	"encoding/json"
 	"fmt"
+	"html"
 	"io"
 	"log/slog"
 	"net/http"
@@ -10,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/golid-ai/golid/backend/internal/i18n"
 	"github.com/golid-ai/golid/backend/internal/logger"
 )
 
@@ -67,128 +69,185 @@ func (s *EmailService) IsConfigured() bool {
 	return s.config.APIKey != "" && s.config.Domain != ""
 }
 
-// SendVerificationEmail sends an email verification link.
-func (s *EmailService) SendVerificationEmail(toEmail, token string) error {
-	verifyURL := fmt.Sprintf("%s/verify-email?token=%s", s.config.FrontendURL, token)
-
-	subject := fmt.Sprintf("Verify your %s email", s.config.AppName)
-	textBody := fmt.Sprintf(`Welcome to %s!
-
-Please verify your email address by clicking the link below:
-
-%s
-
-If you didn't create an account, you can safely ignore this email.
-
-Thanks,
-The %s team`, s.config.AppName, verifyURL, s.config.AppName)
-
-	htmlBody := fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-<head>
-  <meta charset="utf-8">
-  <meta name="viewport" content="width=device-width, initial-scale=1.0">
-</head>
-<body style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
-  <h1 style="color: #0d9488;">Welcome to %s!</h1>
-  <p>Please verify your email address by clicking the button below:</p>
-  <p style="margin: 30px 0;">
-    <a href="%s" style="background-color: #0d9488; color: white; padding: 12px 24px; text-decoration: none; border-radius: 6px; display: inline-block;">Verify Email</a>
-  </p>
-  <p style="color: #666; font-size: 14px;">If you didn't create an account, you can safely ignore this email.</p>
-  <hr style="border: none; border-top: 1px solid #eee; margin: 30px 0;">
-  <p style="color: #999; font-size: 12px;">Thanks,<br>The %s team</p>
-</body>
-</html>`, s.config.AppName, verifyURL, s.config.AppName)
+// Recipient is who an email goes to and how to format it for them.
+type Recipient struct {
+	Email    string
+	Locale   string         // any language tag, or "" for i18n.DefaultLocale
+	Location *time.Location // time zone for dates in the body; nil means UTC
+}
 
-	return s.sendEmail(toEmail, subject, textBody, htmlBody)
+// SendVerificationEmail sends an email verification link.
+func (s *EmailService) SendVerificationEmail(to Recipient, token string) error {
+	locale := i18n.Match(to.Locale)
+	app := s.config.AppName
+
+	return s.send(to, locale, message{
+		subject: i18n.T(locale, "email.verify.subject", app),
+		heading: i18n.T(locale, "email.verify.heading", app),
+		body:    []string{i18n.T(locale, "email.verify.body")},
+		link:    fmt.Sprintf("%s/verify-email?token=%s", s.config.FrontendURL, token),
+		button:  i18n.T(locale, "email.verify.button"),
+		notes:   []string{i18n.T(locale, "email.verify.ignore")},
+	})
 }
 
 // SendPasswordResetEmail sends a password reset link.
-func (s *EmailService) SendPasswordResetEmail(toEmail, token string) error {
-	resetURL := fmt.Sprintf("%s/reset-password?token=%s", s.config.FrontendURL, token)
-	expiry := formatDuration(s.config.PasswordResetTTL)
-
-	subject := fmt.Sprintf("Reset your %s password", s.config.AppName)
-	textBody := fmt.Sprintf(`Hi there,
-
-We received a request to reset your password. Click the link below to choose a new one:
-
-%s
-
-This link expires in %s.
-
-If you didn't request a password reset, you can safely ignore this email.
-
-Thanks,
-The %s team`, resetURL, expiry, s.config.AppName)
+func (s *EmailService) SendPasswordResetEmail(to Recipient, token string) error {
+	locale := i18n.Match(to.Locale)
+	app := s.config.AppName
+
+	return s.send(to, locale, message{
+		subject: i18n.T(locale, "email.reset.subject", app),
+		heading: i18n.T(locale, "email.reset.heading"),
+		body:    []string{i18n.T(locale, "email.reset.body")},
+		link:    fmt.Sprintf("%s/reset-password?token=%s", s.config.FrontendURL, token),
+		button:  i18n.T(locale, "email.reset.button"),
+		notes: []string{
+			i18n.T(locale, "email.reset.expiry", formatDuration(locale, s.config.PasswordResetTTL)),
+			i18n.T(locale, "email.reset.ignore"),
+		},
+	})
+}
 
-	htmlBody := fmt.Sprintf(`
-<!DOCTYPE html>
-<html>
-<head>
-  <meta charset="utf-8">
-  <meta name="viewport" content="width=device-width, initial-scale=1.0">
-</head>
-<body style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
-  <h1 style="color: #0d9488;">Reset Your Password</h1>
-  <p>We received a request to reset your password. Click the button below to choose a new one:</p>
-  <p style="margin: 30px 0;">
-    <a href="%s" style="background-color: #0d9488; color: white; padding: 12px 24px; text-decoration: none; border-radius: 6px; display: inline-block;">Reset Password</a>
-  </p>
-  <p style="color: #666; font-size: 14px;">This link expires in %s.</p>
-  <p style="color: #666; font-size: 14px;">If you didn't request a password reset, you can safely ignore this email.</p>
-  <hr style="border: none; border-top: 1px solid #eee; margin: 30px 0;">
-  <p style="color: #999; font-size: 12px;">Thanks,<br>The %s team</p>
-</body>
-</html>`, resetURL, expiry, s.config.AppName)
+// SendWelcomeEmail sends a welcome email after registration.
+func (s *EmailService) SendWelcomeEmail(to Recipient, firstName string) error {
+	locale := i18n.Match(to.Locale)
+	app := s.config.AppName
 
-	return s.sendEmail(toEmail, subject, textBody, htmlBody)
+	heading := i18n.T(locale, "email.welcome.subject", app)
+	if firstName != "" {
+		heading = i18n.T(locale, "email.welcome.heading", app, firstName)
+	}
+	return s.send(to, locale, message{
+		subject: i18n.T(locale, "email.welcome.subject", app),
+		heading: heading,
+		body:    []string{i18n.T(locale, "email.welcome.body")},
+		link:    fmt.Sprintf("%s/dashboard", s.config.FrontendURL),
+		button:  i18n.T(locale, "email.welcome.button"),
+		notes:   []string{i18n.T(locale, "email.welcome.questions")},
+	})
 }
 
-// SendWelcomeEmail sends a welcome email after registration.
-func (s *EmailService) SendWelcomeEmail(toEmail, firstName string) error {
-	dashboardURL := fmt.Sprintf("%s/dashboard", s.config.FrontendURL)
+// SendAccountStatusEmail tells a user their account was suspended, banned,
+// or reinstated. until is only used for suspensions; reason is included
+// verbatim (HTML-escaped) when non-empty.
+func (s *EmailService) SendAccountStatusEmail(to Recipient, status string, until *time.Time, reason string) error {
+	locale := i18n.Match(to.Locale)
+	app := s.config.AppName
+
+	var subject, summary string
+	switch status {
+	case "suspended":
+		subject = i18n.T(locale, "email.status.suspended.subject", app)
+		summary = i18n.T(locale, "email.status.suspended.body")
+		if until != nil {
+			summary = i18n.T(locale, "email.status.suspended_until.body", i18n.FormatTime(locale, *until, to.Location))
+		}
+	case "banned":
+		subject = i18n.T(locale, "email.status.banned.subject", app)
+		summary = i18n.T(locale, "email.status.banned.body")
+	default:
+		subject = i18n.T(locale, "email.status.active.subject", app)
+		summary = i18n.T(locale, "email.status.active.body")
+	}
 
-	name := firstName
-	if name == "" {
-		name = "there"
+	body := []string{summary}
+	if reason != "" {
+		body = append(body, i18n.T(locale, "email.status.reason", reason))
 	}
+	return s.send(to, locale, message{
+		subject: subject,
+		body:    body,
+		notes:   []string{i18n.T(locale, "email.status.mistake")},
+	})
+}
 
-	subject := fmt.Sprintf("Welcome to %s!", s.config.AppName)
-	textBody := fmt.Sprintf(`Hi %s,
+// SendDataExportEmail sends the download link for a finished personal
+// data export. The link stops working at expiresAt.
+func (s *EmailService) SendDataExportEmail(to Recipient, downloadURL string, expiresAt time.Time) error {
+	locale := i18n.Match(to.Locale)
+
+	return s.send(to, locale, message{
+		subject: i18n.T(locale, "email.export.subject", s.config.AppName),
+		heading: i18n.T(locale, "email.export.heading"),
+		body:    []string{i18n.T(locale, "email.export.body")},
+		link:    downloadURL,
+		button:  i18n.T(locale, "email.export.button"),
+		notes: []string{
+			i18n.T(locale, "email.export.expiry", i18n.FormatTime(locale, expiresAt, to.Location)),
+			i18n.T(locale, "email.export.warning"),
+		},
+	})
+}
 
-Welcome to %s! We're glad you're here.
+// message is the localized copy of one email. Every field is plain text;
+// render escapes it for HTML.
+type message struct {
+	subject string
+	heading string   // optional
+	body    []string // paragraphs before the link
+	link    string   // optional call to action, a button in HTML
+	button  string
+	notes   []string // small print after the link
+}
 
-Head to your dashboard to get started: %s
+// send renders m in the shared layout and delivers it.
+func (s *EmailService) send(to Recipient, locale string, m message) error {
+	text, htmlBody := s.render(locale, m)
+	return s.sendEmail(to.Email, m.subject, text, htmlBody)
+}
 
-If you have any questions, just reply to this email.
+// render lays m out as plain text and HTML.
+func (s *EmailService) render(locale string, m message) (string, string) {
+	signoff := i18n.T(locale, "email.signoff")
+	team := i18n.T(locale, "email.team", s.config.AppName)
 
-Thanks,
-The %s team`, name, s.config.AppName, dashboardURL, s.config.AppName)
+	var text strings.Builder
+	if m.heading != "" {
+		text.WriteString(m.heading + "\n\n")
+	}
+	for _, p := range m.body {
+		text.WriteString(p + "\n\n")
+	}
+	if m.link != "" {
+		text.WriteString(m.link + "\n\n")
+	}
+	for _, p := range m.notes {
+		text.WriteString(p + "\n\n")
+	}
+	text.WriteString(signoff + "\n" + team)
 
-	htmlBody := fmt.Sprintf(`
+	var b strings.Builder
+	fmt.Fprintf(&b, `
 <!DOCTYPE html>
-<html>
+<html lang="%s">
 <head>
   <meta charset="utf-8">
   <meta name="viewport" content="width=device-width, initial-scale=1.0">
 </head>
 <body style="font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, sans-serif; line-height: 1.6; color: #333; max-width: 600px; margin: 0 auto; padding: 20px;">
-  <h1 style="color: #0d9488;">Welcome to %s, %s!</h1>
-  <p>We're glad you're here.</p>
-  <p>Head to your dashboard to get started.</p>
-  <p style="margin: 30px 0;">
-    <a href="%s" style="background-color: #0d9488; color: white; padding: 12px 24px; text-decoration: none; border-radius: 6px; display: inline-block;">Go to Dashboard</a>
+`, html.EscapeString(locale))
+	if m.heading != "" {
+		fmt.Fprintf(&b, "  <h1 style=\"color: #0d9488;\">%s</h1>\n", html.EscapeString(m.heading))
+	}
+	for _, p := range m.body {
+		fmt.Fprintf(&b, "  <p>%s</p>\n", html.EscapeString(p))
+	}
+	if m.link != "" {
+		fmt.Fprintf(&b, `  <p style="margin: 30px 0;">
+    <a href="%s" style="background-color: #0d9488; color: white; padding: 12px 24px; text-decoration: none; border-radius: 6px; display: inline-block;">%s</a>
   </p>
-  <p style="color: #666; font-size: 14px;">If you have any questions, just reply to this email.</p>
-  <hr style="border: none; border-top: 1px solid #eee; margin: 30px 0;">
-  <p style="color: #999; font-size: 12px;">Thanks,<br>The %s team</p>
+`, html.EscapeString(m.link), html.EscapeString(m.button))
+	}
+	for _, p := range m.notes {
+		fmt.Fprintf(&b, "  <p style=\"color: #666; font-size: 14px;\">%s</p>\n", html.EscapeString(p))
+	}
+	fmt.Fprintf(&b, `  <hr style="border: none; border-top: 1px solid #eee; margin: 30px 0;">
+  <p style="color: #999; font-size: 12px;">%s<br>%s</p>
 </body>
-</html>`, s.config.AppName, name, dashboardURL, s.config.AppName)
+</html>`, html.EscapeString(signoff), html.EscapeString(team))
 
-	return s.sendEmail(toEmail, subject, textBody, htmlBody)
+	return text.String(), b.String()
 }
 
 // sendEmail sends an email via Mailgun API.
@@ -278,21 +337,13 @@ func (s *EmailService) sendEmail(to, subject, textBody, htmlBody string) error {
 	return nil
 }
 
-// formatDuration returns a human-readable string like "1 hour" or "30 minutes".
-func formatDuration(d time.Duration) string {
+// formatDuration returns a localized string like "1 hour" or "30 minutes".
+func formatDuration(locale string, d time.Duration) string {
 	if d == 0 {
 		d = time.Hour
 	}
 	if h := int(d.Hours()); h > 0 && d == time.Duration(h)*time.Hour {
-		if h == 1 {
-			return "1 hour"
-		}
-		return fmt.Sprintf("%d hours", h)
-	}
-	m := int(d.Minutes())
-	if m == 1 {
-		return "1 minute"
+		return i18n.Plural(locale, "duration.hours", h)
 	}
-	return fmt.Sprintf("%d minutes", m)
+	return i18n.Plural(locale, "duration.minutes", int(d.Minutes()))
 }
-
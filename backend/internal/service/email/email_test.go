@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 // =============================================================================
@@ -237,12 +238,12 @@ func TestEmailService_SendWithoutConfig(t *testing.T) {
 
 	// Sending without config should log but not error
 	// (the actual implementation logs the email content)
-	err := svc.SendVerificationEmail("test@example.com", "token123")
+	err := svc.SendVerificationEmail(Recipient{Email: "test@example.com"}, "token123")
 	if err != nil {
 		t.Errorf("SendVerificationEmail without config should not error, got: %v", err)
 	}
 
-	err = svc.SendPasswordResetEmail("test@example.com", "resettoken")
+	err = svc.SendPasswordResetEmail(Recipient{Email: "test@example.com"}, "resettoken")
 	if err != nil {
 		t.Errorf("SendPasswordResetEmail without config should not error, got: %v", err)
 	}
@@ -276,7 +277,7 @@ func TestEmailService_SendEmail_Success(t *testing.T) {
 		FrontendURL: "https://app.example.com",
 	})
 
-	err := svc.SendVerificationEmail("user@example.com", "verify-token-abc")
+	err := svc.SendVerificationEmail(Recipient{Email: "user@example.com"}, "verify-token-abc")
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -314,7 +315,7 @@ func TestEmailService_SendPasswordReset_Success(t *testing.T) {
 		FrontendURL: "https://app.example.com",
 	})
 
-	err := svc.SendPasswordResetEmail("user@example.com", "reset-token-xyz")
+	err := svc.SendPasswordResetEmail(Recipient{Email: "user@example.com"}, "reset-token-xyz")
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -327,6 +328,77 @@ func TestEmailService_SendPasswordReset_Success(t *testing.T) {
 	}
 }
 
+func TestEmailService_AccountStatusEmail(t *testing.T) {
+	var receivedSubject, receivedText, receivedHTML string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		receivedSubject = r.FormValue("subject")
+		receivedText = r.FormValue("text")
+		receivedHTML = r.FormValue("html")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": "<msg-id>", "message": "Queued"})
+	}))
+	defer server.Close()
+
+	svc := NewEmailService(EmailConfig{
+		APIKey:  "test-key",
+		Domain:  "test.mailgun.org",
+		BaseURL: server.URL,
+	})
+
+	until := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	if err := svc.SendAccountStatusEmail(Recipient{Email: "user@example.com"}, "suspended", &until, "<b>spam</b>"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if receivedSubject != "Your Golid account has been suspended" {
+		t.Errorf("subject = %q", receivedSubject)
+	}
+	if !strings.Contains(receivedText, "March 1, 2026") || !strings.Contains(receivedText, "Reason: <b>spam</b>") {
+		t.Errorf("text body missing date or reason: %q", receivedText)
+	}
+	if strings.Contains(receivedHTML, "<b>spam</b>") || !strings.Contains(receivedHTML, "&lt;b&gt;spam&lt;/b&gt;") {
+		t.Error("HTML body should escape the reason")
+	}
+}
+
+func TestEmailService_DataExportEmail(t *testing.T) {
+	var receivedSubject, receivedText, receivedHTML string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		receivedSubject = r.FormValue("subject")
+		receivedText = r.FormValue("text")
+		receivedHTML = r.FormValue("html")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": "<msg-id>", "message": "Queued"})
+	}))
+	defer server.Close()
+
+	svc := NewEmailService(EmailConfig{
+		APIKey:  "test-key",
+		Domain:  "test.mailgun.org",
+		BaseURL: server.URL,
+	})
+
+	link := "https://api.example.com/api/v1/exports/abc/download?expires=1&signature=ff"
+	expires := time.Date(2026, 3, 4, 9, 30, 0, 0, time.UTC)
+	if err := svc.SendDataExportEmail(Recipient{Email: "user@example.com"}, link, expires); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if receivedSubject != "Your Golid data export is ready" {
+		t.Errorf("subject = %q", receivedSubject)
+	}
+	if !strings.Contains(receivedText, link) || !strings.Contains(receivedText, "March 4, 2026") {
+		t.Errorf("text body missing link or expiry: %q", receivedText)
+	}
+	if !strings.Contains(receivedHTML, "expires=1&amp;signature=ff") {
+		t.Error("HTML body should contain the escaped link")
+	}
+}
+
 func TestEmailService_DevEmailOverride(t *testing.T) {
 	var receivedTo string
 
@@ -346,7 +418,7 @@ func TestEmailService_DevEmailOverride(t *testing.T) {
 		DevEmailOverride: "dev@override.com",
 	})
 
-	err := svc.SendVerificationEmail("real-user@example.com", "token")
+	err := svc.SendVerificationEmail(Recipient{Email: "real-user@example.com"}, "token")
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -370,7 +442,7 @@ func TestEmailService_HTTPError(t *testing.T) {
 		FrontendURL: "https://app.example.com",
 	})
 
-	err := svc.SendVerificationEmail("user@example.com", "token")
+	err := svc.SendVerificationEmail(Recipient{Email: "user@example.com"}, "token")
 	if err == nil {
 		t.Fatal("expected error for 500 response")
 	}
@@ -390,7 +462,7 @@ func TestEmailService_ConnectionError(t *testing.T) {
 		FrontendURL: "https://app.example.com",
 	})
 
-	err := svc.SendVerificationEmail("user@example.com", "token")
+	err := svc.SendVerificationEmail(Recipient{Email: "user@example.com"}, "token")
 	if err == nil {
 		t.Fatal("expected error for closed server")
 	}
@@ -415,7 +487,7 @@ func TestEmailService_WelcomeEmail(t *testing.T) {
 		FrontendURL: "https://app.example.com",
 	})
 
-	err := svc.SendWelcomeEmail("user@example.com", "Steve")
+	err := svc.SendWelcomeEmail(Recipient{Email: "user@example.com"}, "Steve")
 	if err != nil {
 		t.Fatalf("expected no error, got %v", err)
 	}
@@ -461,3 +533,72 @@ func TestEmailService_RawEmail(t *testing.T) {
 		t.Errorf("html should be empty for raw email, got %q", receivedHTML)
 	}
 }
+
+func TestEmailService_Localized(t *testing.T) {
+	var receivedSubject, receivedText, receivedHTML string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		receivedSubject = r.FormValue("subject")
+		receivedText = r.FormValue("text")
+		receivedHTML = r.FormValue("html")
+		w.WriteHeader(http.StatusOK)
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": "<msg-id>"})
+	}))
+	defer server.Close()
+
+	svc := NewEmailService(EmailConfig{
+		APIKey:           "test-key",
+		Domain:           "test.mailgun.org",
+		BaseURL:          server.URL,
+		PasswordResetTTL: 30 * time.Minute,
+	})
+
+	saoPaulo, err := time.LoadLocation("America/Sao_Paulo")
+	if err != nil {
+		t.Skipf("tzdata unavailable: %v", err)
+	}
+	to := Recipient{Email: "user@example.com", Locale: "pt", Location: saoPaulo}
+
+	expires := time.Date(2026, 3, 4, 9, 30, 0, 0, time.UTC)
+	if err := svc.SendDataExportEmail(to, "https://example.com/x", expires); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if receivedSubject != "Sua exportação de dados do Golid está pronta" {
+		t.Errorf("subject = %q", receivedSubject)
+	}
+	// 09:30 UTC is 06:30 in São Paulo.
+	if !strings.Contains(receivedText, "4 de março de 2026 às 06:30") {
+		t.Errorf("text body missing localized expiry: %q", receivedText)
+	}
+	if !strings.Contains(receivedHTML, `<html lang="pt-BR">`) {
+		t.Error("HTML body should declare the locale")
+	}
+
+	if err := svc.SendPasswordResetEmail(Recipient{Email: "user@example.com", Locale: "es-AR"}, "tok"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.Contains(receivedText, "Este enlace caduca en 30 minutos.") {
+		t.Errorf("text body missing localized duration: %q", receivedText)
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		locale string
+		d      time.Duration
+		want   string
+	}{
+		{"en", 0, "1 hour"},
+		{"en", 2 * time.Hour, "2 hours"},
+		{"en", time.Minute, "1 minute"},
+		{"en", 90 * time.Minute, "90 minutes"},
+		{"es", 24 * time.Hour, "24 horas"},
+		{"pt-BR", 15 * time.Minute, "15 minutos"},
+	}
+	for _, tt := range tests {
+		if got := formatDuration(tt.locale, tt.d); got != tt.want {
+			t.Errorf("formatDuration(%s, %v) = %q, want %q", tt.locale, tt.d, got, tt.want)
+		}
+	}
+}
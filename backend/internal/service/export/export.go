@@ -0,0 +1,524 @@
+// Package export builds personal data exports: a ZIP archive holding one
+// JSON file per registered Exporter with every row a user owns.
+//
+// Modules join the export by implementing Exporter on their service; the
+// wire package registers every service in wire.Services that does, so a
+// new module is covered as soon as it is wired in.
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/i18n"
+	"github.com/golid-ai/golid/backend/internal/logger"
+	"github.com/golid-ai/golid/backend/internal/service/email"
+	"github.com/golid-ai/golid/backend/internal/service/notification"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
+	"github.com/golid-ai/golid/backend/internal/service/preference"
+	"github.com/golid-ai/golid/backend/internal/storage"
+	"github.com/golid-ai/golid/backend/internal/validate"
+)
+
+// Exporter contributes one module's data to a user's export.
+type Exporter interface {
+	// ExportName names the archive entry (<name>.json). Lowercase letters,
+	// digits, and underscores; unique across exporters.
+	ExportName() string
+	// ExportUserData returns JSON-serializable data belonging to userID.
+	// Secrets (password hashes, token hashes) must be left out.
+	ExportUserData(ctx context.Context, userID string) (any, error)
+}
+
+// Notifier records the in-app "export ready" notification. Satisfied by
+// *notification.NotificationService.
+type Notifier interface {
+	Notify(ctx context.Context, userID, kind string, payload any) (*notification.Notification, error)
+}
+
+// Mailer delivers the "export ready" email. Satisfied by *email.EmailService.
+type Mailer interface {
+	IsConfigured() bool
+	SendDataExportEmail(to email.Recipient, downloadURL string, expiresAt time.Time) error
+}
+
+// RegionalReader supplies the locale and time zone the notification and
+// email are written in. Satisfied by *preference.PreferenceService.
+type RegionalReader interface {
+	Regional(ctx context.Context, userID string) (preference.Regional, error)
+}
+
+// Export statuses.
+const (
+	StatusPending = "pending"
+	StatusReady   = "ready"
+	StatusFailed  = "failed"
+)
+
+// KindReady is the notification kind recorded when an export finishes.
+const KindReady = "data_export.ready"
+
+// stalePendingAge is how long a pending export may sit before the sweep
+// removes it so the user can request a new one. Failed exports are kept
+// as long, so the user can see what happened to their request.
+const stalePendingAge = 24 * time.Hour
+
+// defaultMaxAttempts applies when Config.MaxAttempts is unset.
+const defaultMaxAttempts = 1
+
+var exportNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Export is a data export request and, once ready, its archive.
+type Export struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"user_id"`
+	Status      string     `json:"status"`
+	SizeBytes   *int64     `json:"size_bytes"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	StorageKey  *string    `json:"-"`
+}
+
+// SignedURL is a time-limited download link.
+type SignedURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Manifest is written to manifest.json at the root of every archive.
+type Manifest struct {
+	ExportID    string    `json:"export_id"`
+	UserID      string    `json:"user_id"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Files       []string  `json:"files"`
+}
+
+// Config controls archive lifetime and link generation.
+type Config struct {
+	LinkTTL     time.Duration // archive and download link lifetime
+	BaseURL     string        // absolute origin for download links (emails need one)
+	MaxAttempts int           // builds started before a failing export is marked failed
+}
+
+// ExportService requests, builds, and serves data exports.
+type ExportService struct {
+	pool      *pgxpool.Pool
+	blob      storage.Blob
+	signer    *storage.URLSigner
+	notifier  Notifier
+	mailer    Mailer
+	regional  RegionalReader
+	cfg       Config
+	exporters []Exporter
+}
+
+// NewExportService creates a new export service. notifier, mailer, and
+// regional may be nil; without regional the user is notified in
+// i18n.DefaultLocale and UTC.
+func NewExportService(pool *pgxpool.Pool, blob storage.Blob, signer *storage.URLSigner, notifier Notifier, mailer Mailer, regional RegionalReader, cfg Config) *ExportService {
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = defaultMaxAttempts
+	}
+	return &ExportService{pool: pool, blob: blob, signer: signer, notifier: notifier, mailer: mailer, regional: regional, cfg: cfg}
+}
+
+// Register adds an exporter. Called during wiring, before any export is
+// built; panics on an invalid or duplicate name.
+func (s *ExportService) Register(e Exporter) {
+	name := e.ExportName()
+	if !exportNamePattern.MatchString(name) || name == "manifest" {
+		panic(fmt.Sprintf("export: invalid exporter name %q", name))
+	}
+	for _, existing := range s.exporters {
+		if existing.ExportName() == name {
+			panic(fmt.Sprintf("export: duplicate exporter name %q", name))
+		}
+	}
+	s.exporters = append(s.exporters, e)
+}
+
+// Exporters returns the registered exporter names in registration order.
+func (s *ExportService) Exporters() []string {
+	names := make([]string, len(s.exporters))
+	for i, e := range s.exporters {
+		names[i] = e.ExportName()
+	}
+	return names
+}
+
+const exportColumns = `id, user_id, status::text, size_bytes, created_at, completed_at, expires_at, storage_key`
+
+// scanExport scans exportColumns into e, followed by any extra columns.
+func scanExport(row pgx.Row, e *Export, extra ...any) error {
+	dest := []any{&e.ID, &e.UserID, &e.Status, &e.SizeBytes, &e.CreatedAt, &e.CompletedAt, &e.ExpiresAt, &e.StorageKey}
+	return row.Scan(append(dest, extra...)...)
+}
+
+// Request creates a pending export for the user. Only one export may be
+// pending at a time.
+//
+// build, when set, builds the message that runs Process for the export.
+// It is written to the outbox in the transaction that creates the export,
+// so a pending export always has a build on its way.
+func (s *ExportService) Request(ctx context.Context, userID string, build func(exportID string) (outbox.Message, error)) (*Export, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("begin tx: %w", err))
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var e Export
+	err = scanExport(tx.QueryRow(ctx,
+		`INSERT INTO data_exports (user_id) VALUES ($1) RETURNING `+exportColumns,
+		userID,
+	), &e)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return nil, apperror.Conflict("An export is already being prepared").WithMessageID("error.export.in_progress")
+	}
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("create export: %w", err))
+	}
+
+	if build != nil {
+		msg, err := build(e.ID)
+		if err != nil {
+			return nil, apperror.Internal(fmt.Errorf("build data export task: %w", err))
+		}
+		if err := outbox.Enqueue(ctx, tx, msg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("commit tx: %w", err))
+	}
+	return &e, nil
+}
+
+// Get returns one of the user's exports.
+func (s *ExportService) Get(ctx context.Context, exportID, userID string) (*Export, error) {
+	if err := validate.UUID(exportID, "id"); err != nil {
+		return nil, err
+	}
+	var e Export
+	err := scanExport(s.pool.QueryRow(ctx,
+		`SELECT `+exportColumns+` FROM data_exports WHERE id = $1 AND user_id = $2`,
+		exportID, userID,
+	), &e)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperror.NotFound("Export")
+	}
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("get export: %w", err))
+	}
+	return &e, nil
+}
+
+// Process builds a pending export and notifies the user by SSE and email.
+// Safe to retry: an export that is no longer pending is left alone.
+//
+// Every call counts an attempt. When the build fails on attempt
+// Config.MaxAttempts the export is marked failed, which lets the user
+// request a new one, and nil is returned so the caller stops retrying.
+func (s *ExportService) Process(ctx context.Context, exportID string) error {
+	var e Export
+	var attempts int
+	err := scanExport(s.pool.QueryRow(ctx,
+		`UPDATE data_exports SET attempts = attempts + 1
+		 WHERE id = $1 AND status = 'pending'
+		 RETURNING `+exportColumns+`, attempts`, exportID,
+	), &e, &attempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil // no longer pending, swept, or user deleted
+	}
+	if err != nil {
+		return fmt.Errorf("load export: %w", err)
+	}
+
+	ready, err := s.build(ctx, &e)
+	if err != nil {
+		if attempts < s.cfg.MaxAttempts {
+			return err
+		}
+		return s.fail(ctx, &e, attempts, err)
+	}
+	if ready == nil {
+		return nil // another worker finished first
+	}
+	s.notify(ctx, ready)
+	return nil
+}
+
+// build writes the archive and marks the export ready. Returns nil if the
+// export stopped being pending while the archive was being written.
+func (s *ExportService) build(ctx context.Context, e *Export) (*Export, error) {
+	tmp, err := os.CreateTemp("", "golid-export-*.zip")
+	if err != nil {
+		return nil, fmt.Errorf("create export spool: %w", err)
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if err := s.writeArchive(ctx, tmp, e); err != nil {
+		return nil, err
+	}
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("size export archive: %w", err)
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("rewind export archive: %w", err)
+	}
+
+	// A unique key per attempt, so a concurrent build that loses the race
+	// below can delete its own archive without touching the winner's.
+	key := "exports/" + e.UserID + "/" + e.ID + "/" + uuid.NewString() + ".zip"
+	if err := s.blob.Put(ctx, key, tmp, size, "application/zip"); err != nil {
+		return nil, fmt.Errorf("store export archive: %w", err)
+	}
+
+	var ready Export
+	err = scanExport(s.pool.QueryRow(ctx,
+		`UPDATE data_exports
+		 SET status = 'ready', storage_key = $2, size_bytes = $3,
+		     completed_at = NOW(), expires_at = NOW() + make_interval(secs => $4)
+		 WHERE id = $1 AND status = 'pending'
+		 RETURNING `+exportColumns,
+		e.ID, key, size, s.cfg.LinkTTL.Seconds(),
+	), &ready)
+	if errors.Is(err, pgx.ErrNoRows) {
+		s.deleteKeys(ctx, key)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("mark export ready: %w", err)
+	}
+	return &ready, nil
+}
+
+// fail marks an export failed after its last attempt. The build error is
+// logged rather than returned: there is nothing left to retry.
+func (s *ExportService) fail(ctx context.Context, e *Export, attempts int, buildErr error) error {
+	// The build may have failed because ctx ended; the row must still be
+	// released or it blocks new requests until the stale sweep.
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+	if _, err := s.pool.Exec(ctx,
+		`UPDATE data_exports SET status = 'failed', completed_at = NOW()
+		 WHERE id = $1 AND status = 'pending'`, e.ID,
+	); err != nil {
+		return fmt.Errorf("mark export failed: %w", err)
+	}
+	logger.Error("data export failed",
+		slog.String("export_id", e.ID),
+		slog.String("user_id", e.UserID),
+		slog.Int("attempts", attempts),
+		slog.String("error", buildErr.Error()),
+	)
+	return nil
+}
+
+// writeArchive writes manifest.json plus one <name>.json per exporter.
+func (s *ExportService) writeArchive(ctx context.Context, w io.Writer, e *Export) error {
+	zw := zip.NewWriter(w)
+	manifest := Manifest{ExportID: e.ID, UserID: e.UserID, GeneratedAt: time.Now().UTC(), Files: []string{}}
+
+	for _, exp := range s.exporters {
+		data, err := exp.ExportUserData(ctx, e.UserID)
+		if err != nil {
+			return fmt.Errorf("export %s: %w", exp.ExportName(), err)
+		}
+		name := exp.ExportName() + ".json"
+		if err := writeJSON(zw, name, data); err != nil {
+			return err
+		}
+		manifest.Files = append(manifest.Files, name)
+	}
+	if err := writeJSON(zw, "manifest.json", manifest); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("finish export archive: %w", err)
+	}
+	return nil
+}
+
+func writeJSON(zw *zip.Writer, name string, v any) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("add %s: %w", name, err)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("encode %s: %w", name, err)
+	}
+	return nil
+}
+
+// notify tells the user their export is ready. Best-effort: the export is
+// already built and GET /me/exports/:id reports it either way.
+func (s *ExportService) notify(ctx context.Context, e *Export) {
+	link, err := s.DownloadURL(e)
+	if err != nil {
+		logger.Error("failed to sign export link", slog.String("export_id", e.ID), slog.String("error", err.Error()))
+		return
+	}
+
+	var r preference.Regional
+	if s.regional != nil {
+		if r, err = s.regional.Regional(ctx, e.UserID); err != nil {
+			logger.Warn("failed to read export recipient preferences", slog.String("export_id", e.ID), slog.String("error", err.Error()))
+		}
+	}
+	locale := i18n.Match(r.Locale)
+
+	if s.notifier != nil {
+		_, err := s.notifier.Notify(ctx, e.UserID, KindReady, map[string]any{
+			"message":      i18n.T(locale, "notification.export_ready"),
+			"export_id":    e.ID,
+			"download_url": link.URL,
+			"expires_at":   link.ExpiresAt,
+		})
+		if err != nil {
+			logger.Error("failed to record export notification", slog.String("export_id", e.ID), slog.String("error", err.Error()))
+		}
+	}
+
+	if s.mailer == nil || !s.mailer.IsConfigured() {
+		return
+	}
+	to := email.Recipient{Locale: locale, Location: r.Location}
+	if err := s.pool.QueryRow(ctx, `SELECT email FROM users WHERE id = $1`, e.UserID).Scan(&to.Email); err != nil {
+		logger.Error("failed to look up export recipient", slog.String("export_id", e.ID), slog.String("error", err.Error()))
+		return
+	}
+	if err := s.mailer.SendDataExportEmail(to, link.URL, link.ExpiresAt); err != nil {
+		logger.Error("failed to send export email",
+			slog.String("export_id", e.ID),
+			slog.String("email", to.Email),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// DownloadURL returns a signed link to a ready export, valid until the
+// archive expires.
+func (s *ExportService) DownloadURL(e *Export) (*SignedURL, error) {
+	if e.Status != StatusReady || e.ExpiresAt == nil {
+		return nil, apperror.Conflict("Export is not ready yet").WithMessageID("error.export.not_ready")
+	}
+	expires := e.ExpiresAt.Truncate(time.Second)
+	q := url.Values{}
+	q.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	q.Set("signature", s.signer.Sign(storage.OpExport, e.ID, expires))
+	return &SignedURL{
+		URL:       s.cfg.BaseURL + "/api/v1/exports/" + e.ID + "/download?" + q.Encode(),
+		ExpiresAt: expires,
+	}, nil
+}
+
+// VerifyURL checks a signed download link for exportID.
+func (s *ExportService) VerifyURL(exportID, expires, signature string) error {
+	if err := s.signer.Verify(storage.OpExport, exportID, expires, signature); err != nil {
+		return apperror.Forbidden("Invalid or expired link").WithMessageID("error.link_expired")
+	}
+	return nil
+}
+
+// Open returns a ready export and a reader over its archive. The caller
+// must close the reader.
+func (s *ExportService) Open(ctx context.Context, exportID string) (*Export, io.ReadCloser, error) {
+	if err := validate.UUID(exportID, "id"); err != nil {
+		return nil, nil, err
+	}
+	var e Export
+	err := scanExport(s.pool.QueryRow(ctx,
+		`SELECT `+exportColumns+` FROM data_exports
+		 WHERE id = $1 AND status = 'ready' AND expires_at > NOW()`, exportID,
+	), &e)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, apperror.NotFound("Export")
+	}
+	if err != nil {
+		return nil, nil, apperror.Internal(fmt.Errorf("get export: %w", err))
+	}
+	rc, _, err := s.blob.Get(ctx, *e.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, apperror.NotFound("Export")
+	}
+	if err != nil {
+		return nil, nil, apperror.Internal(fmt.Errorf("open export: %w", err))
+	}
+	return &e, rc, nil
+}
+
+// CleanupExpired deletes expired archives, and exports stuck pending or
+// failed for longer than stalePendingAge. Returns the number of rows removed.
+func (s *ExportService) CleanupExpired(ctx context.Context) (int, error) {
+	rows, err := s.pool.Query(ctx,
+		`DELETE FROM data_exports
+		 WHERE (status = 'ready' AND expires_at < NOW())
+		    OR (status = 'pending' AND created_at < NOW() - make_interval(secs => $1))
+		    OR (status = 'failed' AND completed_at < NOW() - make_interval(secs => $1))
+		 RETURNING storage_key`,
+		stalePendingAge.Seconds(),
+	)
+	if err != nil {
+		return 0, apperror.Internal(fmt.Errorf("delete expired exports: %w", err))
+	}
+	defer rows.Close()
+
+	var keys []string
+	n := 0
+	for rows.Next() {
+		var key *string
+		if err := rows.Scan(&key); err != nil {
+			return 0, apperror.Internal(fmt.Errorf("scan expired export: %w", err))
+		}
+		n++
+		if key != nil {
+			keys = append(keys, *key)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, apperror.Internal(fmt.Errorf("iterate expired exports: %w", err))
+	}
+
+	s.deleteKeys(ctx, keys...)
+	return n, nil
+}
+
+// deleteKeys removes archives best-effort; leftovers are unreachable.
+func (s *ExportService) deleteKeys(ctx context.Context, keys ...string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+	for _, key := range keys {
+		if err := s.blob.Delete(ctx, key); err != nil {
+			logger.Warn("failed to delete export archive",
+				slog.String("key", key),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+}
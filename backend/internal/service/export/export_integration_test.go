@@ -0,0 +1,203 @@
+//go:build integration
+
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/service/auth"
+	"github.com/golid-ai/golid/backend/internal/service/email"
+	"github.com/golid-ai/golid/backend/internal/service/notification"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
+	"github.com/golid-ai/golid/backend/internal/service/user"
+	"github.com/golid-ai/golid/backend/internal/storage"
+	"github.com/golid-ai/golid/backend/internal/testutil"
+)
+
+type recordingNotifier struct{ kinds []string }
+
+func (r *recordingNotifier) Notify(_ context.Context, _, kind string, _ any) (*notification.Notification, error) {
+	r.kinds = append(r.kinds, kind)
+	return &notification.Notification{Kind: kind}, nil
+}
+
+type recordingMailer struct{ to []string }
+
+func (r *recordingMailer) IsConfigured() bool { return true }
+func (r *recordingMailer) SendDataExportEmail(to email.Recipient, _ string, _ time.Time) error {
+	r.to = append(r.to, to.Email)
+	return nil
+}
+
+func TestExportLifecycle_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		ctx := context.Background()
+		authSvc := auth.NewAuthService(pool, "test-jwt-secret-that-is-at-least-32-characters-long!", "test-issuer", 15*time.Minute, 7*24*time.Hour, time.Hour)
+		result, err := authSvc.Register(ctx, &auth.RegisterInput{
+			Email:     "export@example.com",
+			Password:  "password123",
+			FirstName: "Export",
+			LastName:  "User",
+		})
+		if err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+		userID := result.User.ID
+
+		notifier, mailer := &recordingNotifier{}, &recordingMailer{}
+		svc := NewExportService(pool, storage.NewLocal(t.TempDir()), storage.NewURLSigner("export-integration-secret"), notifier, mailer, nil, Config{LinkTTL: time.Hour})
+		svc.Register(user.NewUserService(pool, 20, 100, time.Minute))
+		svc.Register(authSvc)
+
+		build := func(exportID string) (outbox.Message, error) {
+			return outbox.Task(asynq.NewTask("export:build", []byte(`{"export_id":"`+exportID+`"}`))), nil
+		}
+		e, err := svc.Request(ctx, userID, build)
+		if err != nil {
+			t.Fatalf("Request() error = %v", err)
+		}
+		if _, err := svc.Request(ctx, userID, build); !apperror.Is(err, apperror.CodeConflict) {
+			t.Errorf("second Request() error = %v, want Conflict", err)
+		}
+		// Only the accepted request wrote a build task.
+		var payloads []string
+		rows, err := pool.Query(ctx, `SELECT payload::text FROM outbox WHERE name = 'export:build'`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for rows.Next() {
+			var p string
+			if err := rows.Scan(&p); err != nil {
+				t.Fatal(err)
+			}
+			payloads = append(payloads, p)
+		}
+		rows.Close()
+		if len(payloads) != 1 || !strings.Contains(payloads[0], e.ID) {
+			t.Errorf("build tasks = %v, want one for %s", payloads, e.ID)
+		}
+
+		if err := svc.Process(ctx, e.ID); err != nil {
+			t.Fatalf("Process() error = %v", err)
+		}
+		// Retrying a finished export is a no-op.
+		if err := svc.Process(ctx, e.ID); err != nil {
+			t.Fatalf("Process(retry) error = %v", err)
+		}
+		if len(notifier.kinds) != 1 || notifier.kinds[0] != KindReady {
+			t.Errorf("notifications = %v, want one %s", notifier.kinds, KindReady)
+		}
+		if len(mailer.to) != 1 || mailer.to[0] != "export@example.com" {
+			t.Errorf("mailer recipients = %v", mailer.to)
+		}
+
+		got, err := svc.Get(ctx, e.ID, userID)
+		if err != nil || got.Status != StatusReady {
+			t.Fatalf("Get() = %+v, %v; want ready", got, err)
+		}
+
+		_, rc, err := svc.Open(ctx, e.ID)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		data, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("archive is not a valid zip: %v", err)
+		}
+		names := map[string]bool{}
+		for _, f := range zr.File {
+			names[f.Name] = true
+		}
+		for _, want := range []string{"manifest.json", "profile.json", "sessions.json"} {
+			if !names[want] {
+				t.Errorf("archive missing %s", want)
+			}
+		}
+
+		// A finished export no longer blocks a new request.
+		if _, err := svc.Request(ctx, userID, nil); err != nil {
+			t.Errorf("Request() after ready error = %v", err)
+		}
+
+		if _, err := pool.Exec(ctx, `UPDATE data_exports SET expires_at = NOW() - INTERVAL '1 minute' WHERE id = $1`, e.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, _, err := svc.Open(ctx, e.ID); !apperror.Is(err, apperror.CodeNotFound) {
+			t.Errorf("Open(expired) error = %v, want NotFound", err)
+		}
+		if n, err := svc.CleanupExpired(ctx); err != nil || n != 1 {
+			t.Errorf("CleanupExpired() = %d, %v; want 1", n, err)
+		}
+	})
+}
+
+func TestExportFailedBuildDoesNotBlockNextRequest_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		ctx := context.Background()
+		authSvc := auth.NewAuthService(pool, "test-jwt-secret-that-is-at-least-32-characters-long!", "test-issuer", 15*time.Minute, 7*24*time.Hour, time.Hour)
+		result, err := authSvc.Register(ctx, &auth.RegisterInput{
+			Email:     "export-fail@example.com",
+			Password:  "password123",
+			FirstName: "Export",
+			LastName:  "User",
+		})
+		if err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+		userID := result.User.ID
+
+		notifier := &recordingNotifier{}
+		svc := NewExportService(pool, storage.NewLocal(t.TempDir()), storage.NewURLSigner("export-integration-secret"), notifier, nil, nil, Config{LinkTTL: time.Hour, MaxAttempts: 2})
+		svc.Register(fakeExporter{name: "broken", err: errors.New("exporter down")})
+
+		e, err := svc.Request(ctx, userID, nil)
+		if err != nil {
+			t.Fatalf("Request() error = %v", err)
+		}
+		// The first failure is returned so the queue retries it.
+		if err := svc.Process(ctx, e.ID); err == nil {
+			t.Fatal("Process(attempt 1) error = nil, want the build error")
+		}
+		if _, err := svc.Request(ctx, userID, nil); !apperror.Is(err, apperror.CodeConflict) {
+			t.Errorf("Request() while retrying error = %v, want Conflict", err)
+		}
+		// The last failure marks the export failed and stops the retries.
+		if err := svc.Process(ctx, e.ID); err != nil {
+			t.Fatalf("Process(attempt 2) error = %v, want nil", err)
+		}
+		got, err := svc.Get(ctx, e.ID, userID)
+		if err != nil || got.Status != StatusFailed || got.CompletedAt == nil {
+			t.Fatalf("Get() = %+v, %v; want failed", got, err)
+		}
+		if len(notifier.kinds) != 0 {
+			t.Errorf("notifications = %v, want none", notifier.kinds)
+		}
+		if err := svc.Process(ctx, e.ID); err != nil {
+			t.Errorf("Process(after failure) error = %v, want nil", err)
+		}
+
+		if _, err := svc.Request(ctx, userID, nil); err != nil {
+			t.Errorf("Request() after failure error = %v", err)
+		}
+
+		if _, err := pool.Exec(ctx, `UPDATE data_exports SET completed_at = NOW() - INTERVAL '25 hours' WHERE id = $1`, e.ID); err != nil {
+			t.Fatal(err)
+		}
+		if n, err := svc.CleanupExpired(ctx); err != nil || n != 1 {
+			t.Errorf("CleanupExpired() = %d, %v; want the old failed export", n, err)
+		}
+	})
+}
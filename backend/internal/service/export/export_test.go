@@ -0,0 +1,159 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/storage"
+)
+
+const (
+	testUserID   = "11111111-1111-1111-1111-111111111111"
+	testExportID = "22222222-2222-2222-2222-222222222222"
+)
+
+type fakeExporter struct {
+	name string
+	data any
+	err  error
+}
+
+func (f fakeExporter) ExportName() string { return f.name }
+func (f fakeExporter) ExportUserData(_ context.Context, userID string) (any, error) {
+	if userID != testUserID {
+		return nil, errors.New("unexpected user " + userID)
+	}
+	return f.data, f.err
+}
+
+func newTestService(t *testing.T) *ExportService {
+	t.Helper()
+	return NewExportService(nil, storage.NewLocal(t.TempDir()), storage.NewURLSigner("export-test-signing-secret"), nil, nil, nil, Config{
+		LinkTTL: time.Hour,
+		BaseURL: "https://app.example.com",
+	})
+}
+
+func TestRegister_RejectsBadNames(t *testing.T) {
+	for _, name := range []string{"", "Profile", "my-files", "manifest", "../x"} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Register(%q) should panic", name)
+				}
+			}()
+			newTestService(t).Register(fakeExporter{name: name})
+		}()
+	}
+}
+
+func TestRegister_RejectsDuplicates(t *testing.T) {
+	svc := newTestService(t)
+	svc.Register(fakeExporter{name: "profile"})
+	defer func() {
+		if recover() == nil {
+			t.Error("duplicate Register should panic")
+		}
+	}()
+	svc.Register(fakeExporter{name: "profile"})
+}
+
+func TestWriteArchive(t *testing.T) {
+	svc := newTestService(t)
+	svc.Register(fakeExporter{name: "profile", data: map[string]string{"email": "a@example.com"}})
+	svc.Register(fakeExporter{name: "files", data: []string{}})
+
+	var buf bytes.Buffer
+	if err := svc.writeArchive(context.Background(), &buf, &Export{ID: testExportID, UserID: testUserID}); err != nil {
+		t.Fatalf("writeArchive() error = %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("archive is not a valid zip: %v", err)
+	}
+	entries := map[string][]byte{}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[f.Name], _ = io.ReadAll(rc)
+		_ = rc.Close()
+	}
+
+	var profile map[string]string
+	if err := json.Unmarshal(entries["profile.json"], &profile); err != nil || profile["email"] != "a@example.com" {
+		t.Errorf("profile.json = %s, %v", entries["profile.json"], err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(entries["manifest.json"], &manifest); err != nil {
+		t.Fatalf("manifest.json: %v", err)
+	}
+	if manifest.ExportID != testExportID || strings.Join(manifest.Files, ",") != "profile.json,files.json" {
+		t.Errorf("manifest = %+v", manifest)
+	}
+}
+
+func TestWriteArchive_ExporterError(t *testing.T) {
+	svc := newTestService(t)
+	svc.Register(fakeExporter{name: "profile", err: errors.New("db down")})
+
+	err := svc.writeArchive(context.Background(), io.Discard, &Export{ID: testExportID, UserID: testUserID})
+	if err == nil || !strings.Contains(err.Error(), "export profile") {
+		t.Errorf("writeArchive() error = %v, want exporter error", err)
+	}
+}
+
+func TestDownloadURL_RoundTrip(t *testing.T) {
+	svc := newTestService(t)
+	expires := time.Now().Add(time.Hour)
+	link, err := svc.DownloadURL(&Export{ID: testExportID, Status: StatusReady, ExpiresAt: &expires})
+	if err != nil {
+		t.Fatalf("DownloadURL() error = %v", err)
+	}
+
+	u, err := url.Parse(link.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u.Host != "app.example.com" || u.Path != "/api/v1/exports/"+testExportID+"/download" {
+		t.Errorf("URL = %q", link.URL)
+	}
+	q := u.Query()
+	if err := svc.VerifyURL(testExportID, q.Get("expires"), q.Get("signature")); err != nil {
+		t.Errorf("VerifyURL() error = %v", err)
+	}
+	if err := svc.VerifyURL("33333333-3333-3333-3333-333333333333", q.Get("expires"), q.Get("signature")); !apperror.Is(err, apperror.CodeForbidden) {
+		t.Errorf("VerifyURL(other export) error = %v, want Forbidden", err)
+	}
+}
+
+func TestDownloadURL_NotReady(t *testing.T) {
+	svc := newTestService(t)
+	if _, err := svc.DownloadURL(&Export{ID: testExportID, Status: StatusPending}); !apperror.Is(err, apperror.CodeConflict) {
+		t.Errorf("DownloadURL(pending) error = %v, want Conflict", err)
+	}
+}
+
+func TestVerifyURL_Expired(t *testing.T) {
+	svc := newTestService(t)
+	expires := time.Now().Add(-time.Minute)
+	link, err := svc.DownloadURL(&Export{ID: testExportID, Status: StatusReady, ExpiresAt: &expires})
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(link.URL)
+	if err := svc.VerifyURL(testExportID, u.Query().Get("expires"), u.Query().Get("signature")); !apperror.Is(err, apperror.CodeForbidden) {
+		t.Errorf("VerifyURL(expired) error = %v, want Forbidden", err)
+	}
+}
@@ -2,10 +2,12 @@ package feature
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"sync"
 	"time"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/sync/singleflight"
 
@@ -13,80 +15,168 @@ import (
 	"github.com/golid-ai/golid/backend/internal/logger"
 )
 
-// FeatureFlag represents a feature toggle.
+// FeatureFlag represents a feature toggle. Rules, when set, limit an
+// enabled flag to the users they select; non-boolean flags also serve a
+// value (see Values). Owner and ExpiresAt are bookkeeping for the stale
+// flag report; Evaluations and LastEvaluatedAt come from usage counters.
 type FeatureFlag struct {
-	Key         string `json:"key"`
-	Enabled     bool   `json:"enabled"`
-	Description string `json:"description"`
+	Key             string          `json:"key"`
+	Enabled         bool            `json:"enabled"`
+	Description     string          `json:"description"`
+	Owner           string          `json:"owner"`
+	ExpiresAt       *time.Time      `json:"expires_at"`
+	Rules           *Rules          `json:"rules,omitempty"`
+	Type            ValueType       `json:"type"`
+	Value           json.RawMessage `json:"value,omitempty"`
+	Variants        []Variant       `json:"variants,omitempty"`
+	Schema          json.RawMessage `json:"schema,omitempty"`
+	Evaluations     int64           `json:"evaluations"`
+	LastEvaluatedAt *time.Time      `json:"last_evaluated_at"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+const flagColumns = `f.key, f.enabled, f.description, f.owner, f.expires_at, f.rules, f.value_type, f.value,
+	f.variants, f.value_schema, COALESCE(u.evaluations, 0), u.last_evaluated_at, f.created_at, f.updated_at`
+
+const flagFrom = `feature_flags f LEFT JOIN feature_flag_usage u ON u.flag_key = f.key`
+
+func scanFlag(row pgx.Row, f *FeatureFlag) error {
+	return row.Scan(&f.Key, &f.Enabled, &f.Description, &f.Owner, &f.ExpiresAt, &f.Rules, &f.Type, &f.Value,
+		&f.Variants, &f.Schema, &f.Evaluations, &f.LastEvaluatedAt, &f.CreatedAt, &f.UpdatedAt)
+}
+
+// FlagState is everything that decides how a flag evaluates. It is what
+// the cache holds and what feature_flag_events records before and after
+// each change.
+type FlagState struct {
+	Enabled  bool            `json:"enabled"`
+	Rules    *Rules          `json:"rules,omitempty"`
+	Type     ValueType       `json:"type,omitempty"`
+	Value    json.RawMessage `json:"value,omitempty"`
+	Variants []Variant       `json:"variants,omitempty"`
+	Schema   json.RawMessage `json:"schema,omitempty"`
+}
+
+func (f FlagState) evaluate(key string, s Subject) bool {
+	return f.Enabled && f.Rules.match(key, s)
 }
 
 // FeatureService provides feature flag operations with an in-memory cache.
-// The cache refreshes lazily when TTL expires. Set() invalidates the cache
-// immediately for the calling instance; other instances refresh within the TTL window.
+// Writes update the local cache immediately and are broadcast through the
+// Invalidator; instances running Watch refresh as soon as they hear about
+// a change. Without a live subscription the cache refreshes lazily when the
+// TTL expires.
 type FeatureService struct {
-	pool     *pgxpool.Pool
-	cache    map[string]bool
-	cacheMu  sync.RWMutex
-	cacheAt  time.Time
-	cacheTTL time.Duration
-	sflight  singleflight.Group
+	pool        *pgxpool.Pool
+	invalidator Invalidator
+	cache       map[string]FlagState
+	cacheMu     sync.RWMutex
+	cacheAt     time.Time
+	cacheTTL    time.Duration
+	sflight     singleflight.Group
+
+	// seq counts invalidations; loadedSeq is the value the cache was loaded
+	// at, so a refresh that raced an invalidation never counts as fresh.
+	seq       uint64
+	loadedSeq uint64
+	listening bool
+
+	// usage counts evaluations per known flag key (*usageCounter) until
+	// FlushUsage writes them to feature_flag_usage.
+	usage sync.Map
 }
 
-func NewFeatureService(pool *pgxpool.Pool, cacheTTL time.Duration) *FeatureService {
+// NewFeatureService creates the service. invalidator may be nil, in which
+// case other instances only see changes once their TTL expires.
+func NewFeatureService(pool *pgxpool.Pool, cacheTTL time.Duration, invalidator Invalidator) *FeatureService {
 	if cacheTTL == 0 {
 		cacheTTL = 30 * time.Second
 	}
 	return &FeatureService{
-		pool:     pool,
-		cache:    make(map[string]bool),
-		cacheTTL: cacheTTL,
+		pool:        pool,
+		invalidator: invalidator,
+		cache:       make(map[string]FlagState),
+		cacheTTL:    cacheTTL,
 	}
 }
 
-// IsEnabled returns whether a feature flag is enabled.
-// Returns false for unknown keys (safe default).
+// IsEnabled returns whether a feature flag is enabled for an anonymous
+// visitor. Returns false for unknown keys (safe default).
 func (s *FeatureService) IsEnabled(ctx context.Context, key string) bool {
+	return s.IsEnabledFor(ctx, key, Subject{})
+}
+
+// IsEnabledFor returns whether a feature flag is enabled for the subject,
+// applying the flag's targeting rules. Returns false for unknown keys.
+func (s *FeatureService) IsEnabledFor(ctx context.Context, key string, subject Subject) bool {
+	return s.lookup(ctx, key).evaluate(key, subject)
+}
+
+// lookup returns the cached state of a flag, refreshing the cache first if
+// it has expired, and counts the evaluation if the flag exists.
+func (s *FeatureService) lookup(ctx context.Context, key string) FlagState {
 	s.cacheMu.RLock()
-	if time.Since(s.cacheAt) < s.cacheTTL {
-		enabled := s.cache[key]
+	if !s.fresh() {
 		s.cacheMu.RUnlock()
-		return enabled
+		s.refresh(ctx)
+		s.cacheMu.RLock()
 	}
+	f, ok := s.cache[key]
 	s.cacheMu.RUnlock()
 
-	if _, _, shared := s.sflight.Do("refresh", func() (interface{}, error) {
-		s.refresh(ctx)
-		return nil, nil
-	}); shared {
-		logger.Debug("feature cache refresh was shared via singleflight")
+	if ok {
+		s.countEvaluation(key)
 	}
+	return f
+}
 
-	s.cacheMu.RLock()
-	defer s.cacheMu.RUnlock()
-	return s.cache[key]
-}
-
-// Set creates or updates a feature flag.
-// Invalidates the local cache immediately.
-func (s *FeatureService) Set(ctx context.Context, key string, enabled bool) error {
-	_, err := s.pool.Exec(ctx,
-		`INSERT INTO feature_flags (key, enabled, updated_at)
-		 VALUES ($1, $2, NOW())
-		 ON CONFLICT (key) DO UPDATE SET enabled = $2, updated_at = NOW()`,
-		key, enabled)
-	if err != nil {
-		return apperror.Internal(fmt.Errorf("set feature flag: %w", err))
+// Set creates or updates a feature flag, keeping its rules and values. The
+// change is recorded in feature_flag_events. Invalidates the local cache
+// immediately.
+func (s *FeatureService) Set(ctx context.Context, key string, enabled bool, change Change) error {
+	return s.apply(ctx, key, actionSet, change, nil, setEnabled(key, enabled))
+}
+
+// setEnabled flips a flag on or off, creating it (if the key is valid)
+// when it does not exist.
+func setEnabled(key string, enabled bool) func(old *FlagState) (FlagState, error) {
+	return func(old *FlagState) (FlagState, error) {
+		var next FlagState
+		if old != nil {
+			next = *old
+		} else if err := validateKey(key); err != nil {
+			return FlagState{}, err
+		}
+		next.Enabled = enabled
+		return next, nil
 	}
-	s.cacheMu.Lock()
-	s.cache[key] = enabled
-	s.cacheMu.Unlock()
-	return nil
 }
 
-// List returns all feature flags with descriptions. Admin-only.
+// SetRules replaces a flag's targeting rules; nil removes them so the flag
+// applies to everyone. Invalidates the local cache immediately.
+func (s *FeatureService) SetRules(ctx context.Context, key string, rules *Rules, change Change) error {
+	if err := rules.Validate(); err != nil {
+		return apperror.Validation().WithDetail("rules", "error.feature.invalid_rules", err.Error())
+	}
+	return s.apply(ctx, key, actionRules, change, nil, func(old *FlagState) (FlagState, error) {
+		if old == nil {
+			return FlagState{}, apperror.NotFound("Feature flag")
+		}
+		next := *old
+		next.Rules = rules
+		return next, nil
+	})
+}
+
+// List returns all feature flags with descriptions, rules, and usage.
+// Admin-only.
 func (s *FeatureService) List(ctx context.Context) ([]FeatureFlag, error) {
-	rows, err := s.pool.Query(ctx,
-		`SELECT key, enabled, description FROM feature_flags ORDER BY key`)
+	return s.queryFlags(ctx, `SELECT `+flagColumns+` FROM `+flagFrom+` ORDER BY f.key`)
+}
+
+func (s *FeatureService) queryFlags(ctx context.Context, query string, args ...any) ([]FeatureFlag, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, apperror.Internal(fmt.Errorf("list feature flags: %w", err))
 	}
@@ -95,7 +185,7 @@ func (s *FeatureService) List(ctx context.Context) ([]FeatureFlag, error) {
 	var flags []FeatureFlag
 	for rows.Next() {
 		var f FeatureFlag
-		if err := rows.Scan(&f.Key, &f.Enabled, &f.Description); err != nil {
+		if err := scanFlag(rows, &f); err != nil {
 			return nil, apperror.Internal(fmt.Errorf("scan feature flag: %w", err))
 		}
 		flags = append(flags, f)
@@ -106,22 +196,41 @@ func (s *FeatureService) List(ctx context.Context) ([]FeatureFlag, error) {
 	return flags, nil
 }
 
-// ListEnabled returns a key->bool map of all flags. Public endpoint.
+// ListEnabled returns a key->bool map of all flags evaluated for an
+// anonymous visitor, so targeted flags read as off. Public endpoint.
 func (s *FeatureService) ListEnabled(ctx context.Context) (map[string]bool, error) {
-	rows, err := s.pool.Query(ctx, `SELECT key, enabled FROM feature_flags`)
+	return s.ListFor(ctx, Subject{})
+}
+
+// ListFor returns a key->bool map of all flags evaluated for the subject.
+func (s *FeatureService) ListFor(ctx context.Context, subject Subject) (map[string]bool, error) {
+	flags, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]bool, len(flags))
+	for key, f := range flags {
+		result[key] = f.evaluate(key, subject)
+	}
+	return result, nil
+}
+
+func (s *FeatureService) load(ctx context.Context) (map[string]FlagState, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT key, enabled, rules, value_type, value, variants, value_schema FROM feature_flags`)
 	if err != nil {
 		return nil, apperror.Internal(fmt.Errorf("list enabled flags: %w", err))
 	}
 	defer rows.Close()
 
-	result := make(map[string]bool)
+	result := make(map[string]FlagState)
 	for rows.Next() {
 		var key string
-		var enabled bool
-		if err := rows.Scan(&key, &enabled); err != nil {
+		var f FlagState
+		if err := rows.Scan(&key, &f.Enabled, &f.Rules, &f.Type, &f.Value, &f.Variants, &f.Schema); err != nil {
 			return nil, apperror.Internal(fmt.Errorf("scan feature flag: %w", err))
 		}
-		result[key] = enabled
+		result[key] = f
 	}
 	if err := rows.Err(); err != nil {
 		return nil, apperror.Internal(fmt.Errorf("iterate feature flags: %w", err))
@@ -129,14 +238,55 @@ func (s *FeatureService) ListEnabled(ctx context.Context) (map[string]bool, erro
 	return result, nil
 }
 
-func (s *FeatureService) refresh(ctx context.Context) {
-	result, err := s.ListEnabled(ctx)
-	if err != nil {
-		logger.Error("failed to refresh feature flags", "error", err.Error())
-		return
+// fresh reports whether the cache can be served without a reload. While
+// Watch is subscribed, invalidations rather than the TTL decide. Callers
+// hold cacheMu.
+func (s *FeatureService) fresh() bool {
+	if s.loadedSeq != s.seq {
+		return false
 	}
+	age := time.Since(s.cacheAt)
+	if s.listening {
+		return age < max(s.cacheTTL, maxListenAge)
+	}
+	return age < s.cacheTTL
+}
+
+// invalidate marks the cache stale, including any reload already in flight.
+func (s *FeatureService) invalidate() {
+	s.cacheMu.Lock()
+	s.seq++
+	s.cacheMu.Unlock()
+}
+
+func (s *FeatureService) setListening(listening bool) {
 	s.cacheMu.Lock()
-	s.cache = result
-	s.cacheAt = time.Now()
+	s.listening = listening
 	s.cacheMu.Unlock()
 }
+
+// refresh reloads the cache, deduplicating concurrent callers, and reports
+// whether it succeeded. On failure the previous values keep being served.
+func (s *FeatureService) refresh(ctx context.Context) bool {
+	ok, _, shared := s.sflight.Do("refresh", func() (interface{}, error) {
+		s.cacheMu.RLock()
+		seq := s.seq
+		s.cacheMu.RUnlock()
+
+		result, err := s.load(ctx)
+		if err != nil {
+			logger.Error("failed to refresh feature flags", "error", err.Error())
+			return false, nil
+		}
+		s.cacheMu.Lock()
+		s.cache = result
+		s.cacheAt = time.Now()
+		s.loadedSeq = seq
+		s.cacheMu.Unlock()
+		return true, nil
+	})
+	if shared {
+		logger.Debug("feature cache refresh was shared via singleflight")
+	}
+	return ok.(bool)
+}
@@ -0,0 +1,234 @@
+package feature
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/pagination"
+)
+
+// Event actions recorded in feature_flag_events.
+const (
+	actionSet      = "set"
+	actionRules    = "rules"
+	actionValues   = "values"
+	actionRollback = "rollback"
+	actionCreate   = "create"
+	actionDelete   = "delete"
+	actionSync     = "sync"
+)
+
+const (
+	maxReasonLen = 500
+
+	historyPerPageDefault = 20
+	historyPerPageMax     = 100
+)
+
+// Change identifies who is changing a flag and why. Every write requires
+// a reason; ActorID and RequestID are recorded when known.
+type Change struct {
+	ActorID   string
+	Reason    string
+	RequestID string
+
+	// scheduleID links events written by the scheduler to their schedule.
+	scheduleID string
+}
+
+// Event is one recorded change to a flag. OldState is nil when the change
+// created the flag; NewState is nil when it deleted it.
+type Event struct {
+	ID         string     `json:"id"`
+	FlagKey    string     `json:"flag_key"`
+	Action     string     `json:"action"`
+	ActorID    *string    `json:"actor_id"`
+	OldState   *FlagState `json:"old_state"`
+	NewState   *FlagState `json:"new_state"`
+	Reason     string     `json:"reason"`
+	RequestID  string     `json:"request_id,omitempty"`
+	RollbackOf *string    `json:"rollback_of,omitempty"`
+	ScheduleID *string    `json:"schedule_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// HistoryResult is a page of a flag's events, newest first.
+type HistoryResult struct {
+	Events     []Event `json:"events"`
+	Total      int     `json:"total"`
+	Page       int     `json:"page"`
+	PerPage    int     `json:"per_page"`
+	TotalPages int     `json:"total_pages"`
+}
+
+func (c Change) validate() error {
+	reason := strings.TrimSpace(c.Reason)
+	if reason == "" {
+		return apperror.Validation().WithDetail("reason", "error.reason_required")
+	}
+	if len(reason) > maxReasonLen {
+		return apperror.Validation().WithDetail("reason", "error.reason_too_long", maxReasonLen)
+	}
+	return nil
+}
+
+// apply runs one audited write: it locks the flag, derives the new state
+// from the old one (nil if the flag does not exist yet), stores it, and
+// records the event in the same transaction.
+func (s *FeatureService) apply(ctx context.Context, key, action string, change Change, rollbackOf *string, next func(old *FlagState) (FlagState, error)) error {
+	if err := change.validate(); err != nil {
+		return err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("begin tx: %w", err))
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	state, _, err := applyTx(ctx, tx, key, action, change, rollbackOf, next)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return apperror.Internal(fmt.Errorf("commit tx: %w", err))
+	}
+	s.stored(ctx, key, state)
+	return nil
+}
+
+// applyTx is apply inside the caller's transaction. It returns the new
+// state and the old one; the caller commits and then calls stored.
+func applyTx(ctx context.Context, tx pgx.Tx, key, action string, change Change, rollbackOf *string, next func(old *FlagState) (FlagState, error)) (FlagState, *FlagState, error) {
+	var old *FlagState
+	var current FlagState
+	err := tx.QueryRow(ctx,
+		`SELECT enabled, rules, value_type, value, variants, value_schema
+		 FROM feature_flags WHERE key = $1 FOR UPDATE`, key,
+	).Scan(&current.Enabled, &current.Rules, &current.Type, &current.Value, &current.Variants, &current.Schema)
+	switch {
+	case errors.Is(err, pgx.ErrNoRows):
+	case err != nil:
+		return FlagState{}, nil, apperror.Internal(fmt.Errorf("lock feature flag: %w", err))
+	default:
+		old = &current
+	}
+
+	state, err := next(old)
+	if err != nil {
+		return FlagState{}, nil, err
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO feature_flags (key, enabled, rules, value_type, value, variants, value_schema, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		 ON CONFLICT (key) DO UPDATE SET enabled = $2, rules = $3, value_type = $4,
+		     value = $5, variants = $6, value_schema = $7, updated_at = NOW()`,
+		key, state.Enabled, state.Rules, state.valueType(), state.Value, state.Variants, state.Schema,
+	); err != nil {
+		return FlagState{}, nil, apperror.Internal(fmt.Errorf("set feature flag: %w", err))
+	}
+
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO feature_flag_events
+		     (flag_key, action, actor_id, old_state, new_state, reason, request_id, rollback_of, schedule_id)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+		key, action, nilIfEmpty(change.ActorID), old, state,
+		strings.TrimSpace(change.Reason), nilIfEmpty(change.RequestID), rollbackOf, nilIfEmpty(change.scheduleID),
+	); err != nil {
+		return FlagState{}, nil, apperror.Internal(fmt.Errorf("record feature flag event: %w", err))
+	}
+	return state, old, nil
+}
+
+// stored updates the local cache with a committed write and tells other
+// instances about it.
+func (s *FeatureService) stored(ctx context.Context, key string, state FlagState) {
+	s.cacheMu.Lock()
+	s.cache[key] = state
+	s.cacheMu.Unlock()
+	s.publish(ctx, key)
+}
+
+// Rollback restores the state a flag had just before the given event.
+// The rollback is itself recorded, pointing at the event it undid.
+func (s *FeatureService) Rollback(ctx context.Context, key, eventID string, change Change) error {
+	var target *FlagState
+	err := s.pool.QueryRow(ctx,
+		`SELECT old_state FROM feature_flag_events WHERE id = $1 AND flag_key = $2`, eventID, key,
+	).Scan(&target)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return apperror.NotFound("Feature flag event")
+	}
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("get feature flag event: %w", err))
+	}
+	if target == nil {
+		return apperror.Conflict("This change created the flag; there is no earlier state to restore").WithMessageID("error.feature.rollback_creation")
+	}
+	return s.apply(ctx, key, actionRollback, change, &eventID, func(*FlagState) (FlagState, error) {
+		return *target, nil
+	})
+}
+
+// History returns a flag's recorded changes, newest first. History
+// outlives the flag, so an unknown key is an empty page, not an error.
+func (s *FeatureService) History(ctx context.Context, key string, page, perPage int) (*HistoryResult, error) {
+	page, perPage = pagination.NormalizePagination(page, perPage, historyPerPageDefault, historyPerPageMax)
+	offset := (page - 1) * perPage
+
+	var total int
+	if err := s.pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM feature_flag_events WHERE flag_key = $1`, key,
+	).Scan(&total); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("count feature flag events: %w", err))
+	}
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT id, flag_key, action, actor_id::text, old_state, new_state, reason,
+		        COALESCE(request_id, ''), rollback_of::text, schedule_id::text, created_at
+		 FROM feature_flag_events
+		 WHERE flag_key = $1
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT $2 OFFSET $3`,
+		key, perPage, offset)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("list feature flag events: %w", err))
+	}
+	defer rows.Close()
+
+	events := make([]Event, 0, perPage)
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.ID, &e.FlagKey, &e.Action, &e.ActorID, &e.OldState, &e.NewState,
+			&e.Reason, &e.RequestID, &e.RollbackOf, &e.ScheduleID, &e.CreatedAt); err != nil {
+			return nil, apperror.Internal(fmt.Errorf("scan feature flag event: %w", err))
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("iterate feature flag events: %w", err))
+	}
+
+	return &HistoryResult{
+		Events:     events,
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: (total + perPage - 1) / perPage,
+	}, nil
+}
+
+func nilIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
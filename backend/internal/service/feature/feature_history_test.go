@@ -0,0 +1,33 @@
+package feature
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+)
+
+func TestChange_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		reason  string
+		wantErr bool
+	}{
+		{"reason", "launch", false},
+		{"empty", "", true},
+		{"whitespace only", "   ", true},
+		{"at limit", strings.Repeat("a", maxReasonLen), false},
+		{"too long", strings.Repeat("a", maxReasonLen+1), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Change{Reason: tt.reason}.validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !apperror.Is(err, apperror.CodeValidation) {
+				t.Errorf("validate() error = %v, want Validation", err)
+			}
+		})
+	}
+}
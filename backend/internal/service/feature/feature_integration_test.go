@@ -4,19 +4,24 @@ package feature
 
 import (
 	"context"
+	"encoding/json"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/golid-ai/golid/backend/internal/apperror"
 	"github.com/golid-ai/golid/backend/internal/testutil"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+var testChange = Change{Reason: "test"}
+
 func TestFeatureService_SetAndIsEnabled_Integration(t *testing.T) {
 	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
-		svc := NewFeatureService(pool, 30*time.Second)
+		svc := NewFeatureService(pool, 30*time.Second, nil)
 		ctx := context.Background()
 
-		if err := svc.Set(ctx, "dark_mode", true); err != nil {
+		if err := svc.Set(ctx, "dark_mode", true, testChange); err != nil {
 			t.Fatalf("Set() error = %v", err)
 		}
 
@@ -24,7 +29,7 @@ func TestFeatureService_SetAndIsEnabled_Integration(t *testing.T) {
 			t.Error("IsEnabled(dark_mode) = false, want true")
 		}
 
-		if err := svc.Set(ctx, "dark_mode", false); err != nil {
+		if err := svc.Set(ctx, "dark_mode", false, testChange); err != nil {
 			t.Fatalf("Set() toggle error = %v", err)
 		}
 
@@ -40,7 +45,7 @@ func TestFeatureService_SetAndIsEnabled_Integration(t *testing.T) {
 
 func TestFeatureService_IsEnabled_UnknownKey_Integration(t *testing.T) {
 	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
-		svc := NewFeatureService(pool, 30*time.Second)
+		svc := NewFeatureService(pool, 30*time.Second, nil)
 		ctx := context.Background()
 
 		if svc.IsEnabled(ctx, "nonexistent_flag") {
@@ -51,10 +56,10 @@ func TestFeatureService_IsEnabled_UnknownKey_Integration(t *testing.T) {
 
 func TestFeatureService_CacheExpiry_Integration(t *testing.T) {
 	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
-		svc := NewFeatureService(pool, 1*time.Millisecond)
+		svc := NewFeatureService(pool, 1*time.Millisecond, nil)
 		ctx := context.Background()
 
-		if err := svc.Set(ctx, "cached_flag", true); err != nil {
+		if err := svc.Set(ctx, "cached_flag", true, testChange); err != nil {
 			t.Fatalf("Set() error = %v", err)
 		}
 
@@ -77,13 +82,13 @@ func TestFeatureService_CacheExpiry_Integration(t *testing.T) {
 
 func TestFeatureService_List_Integration(t *testing.T) {
 	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
-		svc := NewFeatureService(pool, 30*time.Second)
+		svc := NewFeatureService(pool, 30*time.Second, nil)
 		ctx := context.Background()
 
-		if err := svc.Set(ctx, "flag_a", true); err != nil {
+		if err := svc.Set(ctx, "flag_a", true, testChange); err != nil {
 			t.Fatalf("Set(flag_a) error = %v", err)
 		}
-		if err := svc.Set(ctx, "flag_b", false); err != nil {
+		if err := svc.Set(ctx, "flag_b", false, testChange); err != nil {
 			t.Fatalf("Set(flag_b) error = %v", err)
 		}
 
@@ -108,3 +113,624 @@ func TestFeatureService_List_Integration(t *testing.T) {
 		}
 	})
 }
+
+func TestFeatureService_Rules_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		svc := NewFeatureService(pool, 30*time.Second, nil)
+		ctx := context.Background()
+
+		if err := svc.SetRules(ctx, "missing_flag", &Rules{}, testChange); !apperror.Is(err, apperror.CodeNotFound) {
+			t.Fatalf("SetRules(missing) error = %v, want NotFound", err)
+		}
+		if err := svc.Set(ctx, "admin_only", true, testChange); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+		if err := svc.SetRules(ctx, "admin_only", &Rules{UserTypes: []string{"admin"}}, testChange); err != nil {
+			t.Fatalf("SetRules() error = %v", err)
+		}
+
+		// A second instance reads the rules from the database.
+		other := NewFeatureService(pool, 30*time.Second, nil)
+		if !other.IsEnabledFor(ctx, "admin_only", Subject{UserID: "u-1", UserType: "admin"}) {
+			t.Error("admin_only should be on for an admin")
+		}
+		if other.IsEnabledFor(ctx, "admin_only", Subject{UserID: "u-2", UserType: "user"}) {
+			t.Error("admin_only should be off for a user")
+		}
+
+		// Re-enabling keeps the rules.
+		if err := other.Set(ctx, "admin_only", true, testChange); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+		if other.IsEnabled(ctx, "admin_only") {
+			t.Error("Set dropped the cached rules")
+		}
+
+		public, err := svc.ListEnabled(ctx)
+		if err != nil {
+			t.Fatalf("ListEnabled() error = %v", err)
+		}
+		if public["admin_only"] {
+			t.Error("ListEnabled should evaluate targeted flags as anonymous")
+		}
+
+		flags, err := svc.List(ctx)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		for _, f := range flags {
+			if f.Key == "admin_only" && (f.Rules == nil || len(f.Rules.UserTypes) != 1) {
+				t.Errorf("List() rules = %+v", f.Rules)
+			}
+		}
+
+		if err := svc.SetRules(ctx, "admin_only", nil, testChange); err != nil {
+			t.Fatalf("SetRules(nil) error = %v", err)
+		}
+		if !svc.IsEnabled(ctx, "admin_only") {
+			t.Error("clearing rules should enable the flag for everyone")
+		}
+	})
+}
+
+func TestFeatureService_Watch_Postgres_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		writer := NewFeatureService(pool, time.Hour, NewPostgresInvalidator(pool))
+		reader := NewFeatureService(pool, time.Hour, NewPostgresInvalidator(pool))
+		go reader.Watch(ctx)
+
+		waitFor := func(what string, cond func() bool) {
+			t.Helper()
+			deadline := time.Now().Add(5 * time.Second)
+			for !cond() {
+				if time.Now().After(deadline) {
+					t.Fatalf("timed out waiting for %s", what)
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+		}
+		waitFor("listener", func() bool {
+			reader.cacheMu.RLock()
+			defer reader.cacheMu.RUnlock()
+			return reader.listening
+		})
+
+		// With an hour-long TTL only the notification can make this visible.
+		if reader.IsEnabled(ctx, "live_flag") {
+			t.Fatal("live_flag should start disabled")
+		}
+		if err := writer.Set(ctx, "live_flag", true, testChange); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+		waitFor("live_flag to turn on", func() bool { return reader.IsEnabled(ctx, "live_flag") })
+
+		if err := writer.SetRules(ctx, "live_flag", &Rules{UserTypes: []string{"admin"}}, testChange); err != nil {
+			t.Fatalf("SetRules() error = %v", err)
+		}
+		waitFor("rules to apply", func() bool { return !reader.IsEnabled(ctx, "live_flag") })
+	})
+}
+
+func TestFeatureService_HistoryAndRollback_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		svc := NewFeatureService(pool, 30*time.Second, nil)
+		ctx := context.Background()
+
+		if err := svc.Set(ctx, "checkout_v2", true, Change{}); !apperror.Is(err, apperror.CodeValidation) {
+			t.Fatalf("Set() without reason error = %v, want Validation", err)
+		}
+
+		if err := svc.Set(ctx, "checkout_v2", true, Change{Reason: "launch", RequestID: "req-1"}); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+		if err := svc.SetRules(ctx, "checkout_v2", &Rules{Percentage: percent(5)}, Change{Reason: "canary"}); err != nil {
+			t.Fatalf("SetRules() error = %v", err)
+		}
+
+		history, err := svc.History(ctx, "checkout_v2", 1, 20)
+		if err != nil {
+			t.Fatalf("History() error = %v", err)
+		}
+		if history.Total != 2 || len(history.Events) != 2 {
+			t.Fatalf("History() = %d events, total %d; want 2", len(history.Events), history.Total)
+		}
+		rulesEvent, created := history.Events[0], history.Events[1]
+		if rulesEvent.Action != actionRules || rulesEvent.Reason != "canary" || rulesEvent.NewState.Rules == nil {
+			t.Errorf("newest event = %+v", rulesEvent)
+		}
+		if created.OldState != nil || created.RequestID != "req-1" || !created.NewState.Enabled {
+			t.Errorf("creating event = %+v", created)
+		}
+
+		if err := svc.Rollback(ctx, "checkout_v2", created.ID, testChange); !apperror.Is(err, apperror.CodeConflict) {
+			t.Errorf("Rollback(creating event) error = %v, want Conflict", err)
+		}
+		if err := svc.Rollback(ctx, "other_flag", rulesEvent.ID, testChange); !apperror.Is(err, apperror.CodeNotFound) {
+			t.Errorf("Rollback(wrong flag) error = %v, want NotFound", err)
+		}
+
+		if err := svc.Rollback(ctx, "checkout_v2", rulesEvent.ID, Change{Reason: "error spike"}); err != nil {
+			t.Fatalf("Rollback() error = %v", err)
+		}
+		if !svc.IsEnabledFor(ctx, "checkout_v2", Subject{UserID: "u-1"}) {
+			t.Error("rollback should restore the flag without rules")
+		}
+
+		history, err = svc.History(ctx, "checkout_v2", 1, 1)
+		if err != nil {
+			t.Fatalf("History() error = %v", err)
+		}
+		latest := history.Events[0]
+		if latest.Action != actionRollback || latest.RollbackOf == nil || *latest.RollbackOf != rulesEvent.ID {
+			t.Errorf("rollback event = %+v", latest)
+		}
+		if latest.OldState == nil || latest.OldState.Rules == nil || latest.NewState.Rules != nil {
+			t.Errorf("rollback states = %+v -> %+v", latest.OldState, latest.NewState)
+		}
+		if history.Total != 3 || history.TotalPages != 3 {
+			t.Errorf("History() total = %d, pages = %d", history.Total, history.TotalPages)
+		}
+	})
+}
+
+func TestFeatureService_SetValues_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		svc := NewFeatureService(pool, 30*time.Second, nil)
+		ctx := context.Background()
+
+		values := Values{
+			Type:   TypeNumber,
+			Value:  json.RawMessage(`20`),
+			Schema: json.RawMessage(`{"type":"integer","minimum":1,"maximum":100}`),
+			Variants: []Variant{
+				{Key: "small", Value: json.RawMessage(`10`), Weight: 50},
+				{Key: "large", Value: json.RawMessage(`50`), Weight: 50},
+			},
+		}
+		if err := svc.SetValues(ctx, "page_size", values, testChange); !apperror.Is(err, apperror.CodeNotFound) {
+			t.Fatalf("SetValues(missing) error = %v, want NotFound", err)
+		}
+		if err := svc.Set(ctx, "page_size", true, testChange); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+		if err := svc.SetValues(ctx, "page_size", values, testChange); err != nil {
+			t.Fatalf("SetValues() error = %v", err)
+		}
+		bad := values
+		bad.Value = json.RawMessage(`500`)
+		if err := svc.SetValues(ctx, "page_size", bad, testChange); !apperror.Is(err, apperror.CodeValidation) {
+			t.Errorf("SetValues(out of schema) error = %v, want Validation", err)
+		}
+
+		// Toggling keeps the values.
+		if err := svc.Set(ctx, "page_size", true, testChange); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+		if got := svc.GetInt(ctx, "page_size", Subject{}, 0); got != 20 {
+			t.Errorf("GetInt(anonymous) = %d, want 20", got)
+		}
+		if got := svc.GetInt(ctx, "page_size", Subject{UserID: "u-1"}, 0); got != 10 && got != 50 {
+			t.Errorf("GetInt(user) = %d, want a variant", got)
+		}
+
+		flags, err := svc.List(ctx)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		for _, f := range flags {
+			if f.Key == "page_size" && (f.Type != TypeNumber || len(f.Variants) != 2 || f.Schema == nil) {
+				t.Errorf("List() page_size = %+v", f)
+			}
+		}
+
+		all, err := svc.EvaluateAll(ctx, Subject{UserID: "u-1"})
+		if err != nil {
+			t.Fatalf("EvaluateAll() error = %v", err)
+		}
+		if e := all["page_size"]; !e.Enabled || e.Variant == "" {
+			t.Errorf("EvaluateAll()[page_size] = %+v", e)
+		}
+
+		history, err := svc.History(ctx, "page_size", 1, 20)
+		if err != nil {
+			t.Fatalf("History() error = %v", err)
+		}
+		if err := svc.Rollback(ctx, "page_size", history.Events[1].ID, testChange); err != nil {
+			t.Fatalf("Rollback(values) error = %v", err)
+		}
+		if got := svc.GetInt(ctx, "page_size", Subject{}, -1); got != -1 {
+			t.Errorf("GetInt() after rollback = %d, want fallback for a boolean flag", got)
+		}
+	})
+}
+
+func TestFeatureService_Schedules_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		ctx := context.Background()
+		a := NewFeatureService(pool, 30*time.Second, nil)
+		b := NewFeatureService(pool, 30*time.Second, nil)
+
+		// makeDue moves a schedule's run time into the past; ScheduleChange
+		// only accepts future times.
+		makeDue := func(id string) {
+			t.Helper()
+			if _, err := pool.Exec(ctx,
+				`UPDATE feature_flag_schedules SET run_at = NOW() - INTERVAL '1 second' WHERE id = $1`, id,
+			); err != nil {
+				t.Fatalf("makeDue: %v", err)
+			}
+		}
+
+		runAt := time.Now().Add(time.Hour)
+		revertAt := runAt.Add(time.Hour)
+		launch, err := a.ScheduleChange(ctx, ScheduleRequest{
+			Key: "launch", Action: ScheduleEnable, RunAt: runAt, RevertAt: &revertAt,
+		}, Change{Reason: "launch day"})
+		if err != nil {
+			t.Fatalf("ScheduleChange() error = %v", err)
+		}
+		if launch.Status != SchedulePending || launch.RevertAt == nil {
+			t.Fatalf("ScheduleChange() = %+v", launch)
+		}
+
+		// Nothing is due yet.
+		if n, err := a.ApplyDue(ctx); err != nil || n != 0 {
+			t.Fatalf("ApplyDue() = %d, %v; want 0", n, err)
+		}
+
+		makeDue(launch.ID)
+
+		// Two instances race; exactly one applies the schedule.
+		var wg sync.WaitGroup
+		counts := make([]int, 2)
+		for i, svc := range []*FeatureService{a, b} {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				n, err := svc.ApplyDue(ctx)
+				if err != nil {
+					t.Errorf("ApplyDue() error = %v", err)
+				}
+				counts[i] = n
+			}()
+		}
+		wg.Wait()
+		if counts[0]+counts[1] != 1 {
+			t.Fatalf("ApplyDue() counts = %v, want exactly one application", counts)
+		}
+		if !a.IsEnabled(ctx, "launch") || !b.IsEnabled(ctx, "launch") {
+			t.Error("launch should be enabled after the schedule ran")
+		}
+
+		history, err := a.History(ctx, "launch", 1, 20)
+		if err != nil {
+			t.Fatalf("History() error = %v", err)
+		}
+		if history.Total != 1 || history.Events[0].ScheduleID == nil || *history.Events[0].ScheduleID != launch.ID {
+			t.Errorf("History() = %+v, want one event linked to the schedule", history.Events)
+		}
+
+		// The temporary enable queued its own revert.
+		pending, err := a.ListSchedules(ctx, "launch", SchedulePending, 1, 20)
+		if err != nil {
+			t.Fatalf("ListSchedules() error = %v", err)
+		}
+		if pending.Total != 1 {
+			t.Fatalf("ListSchedules(pending) total = %d, want the revert", pending.Total)
+		}
+		revert := pending.Schedules[0]
+		if revert.Action != ScheduleDisable || revert.RevertOf == nil || *revert.RevertOf != launch.ID || !revert.RunAt.Equal(*launch.RevertAt) {
+			t.Errorf("revert schedule = %+v", revert)
+		}
+
+		makeDue(revert.ID)
+		if n, err := a.ApplyDue(ctx); err != nil || n != 1 {
+			t.Fatalf("ApplyDue(revert) = %d, %v", n, err)
+		}
+		if a.IsEnabled(ctx, "launch") {
+			t.Error("launch should be off after the revert")
+		}
+		if err := a.CancelSchedule(ctx, revert.ID, ""); !apperror.Is(err, apperror.CodeConflict) {
+			t.Errorf("CancelSchedule(applied) error = %v, want Conflict", err)
+		}
+
+		// Cancelled schedules never run.
+		cancelled, err := a.ScheduleChange(ctx, ScheduleRequest{Key: "launch", Action: ScheduleEnable, RunAt: runAt}, testChange)
+		if err != nil {
+			t.Fatalf("ScheduleChange() error = %v", err)
+		}
+		if err := a.CancelSchedule(ctx, cancelled.ID, ""); err != nil {
+			t.Fatalf("CancelSchedule() error = %v", err)
+		}
+		makeDue(cancelled.ID)
+		if n, err := a.ApplyDue(ctx); err != nil || n != 0 {
+			t.Errorf("ApplyDue() after cancel = %d, %v; want 0", n, err)
+		}
+		if err := a.CancelSchedule(ctx, "00000000-0000-0000-0000-000000000000", ""); !apperror.Is(err, apperror.CodeNotFound) {
+			t.Errorf("CancelSchedule(missing) error = %v, want NotFound", err)
+		}
+
+		// A values change for a flag that never existed fails and is not retried.
+		missing, err := a.ScheduleChange(ctx, ScheduleRequest{
+			Key: "never_created", Action: ScheduleValues, RunAt: runAt,
+			Values: &Values{Type: TypeString, Value: json.RawMessage(`"x"`)},
+		}, testChange)
+		if err != nil {
+			t.Fatalf("ScheduleChange(values) error = %v", err)
+		}
+		makeDue(missing.ID)
+		if _, err := a.ApplyDue(ctx); err != nil {
+			t.Fatalf("ApplyDue() error = %v", err)
+		}
+		failed, err := a.ListSchedules(ctx, "never_created", ScheduleFailed, 1, 20)
+		if err != nil {
+			t.Fatalf("ListSchedules(failed) error = %v", err)
+		}
+		if failed.Total != 1 || failed.Schedules[0].Error == "" {
+			t.Errorf("ListSchedules(failed) = %+v", failed.Schedules)
+		}
+	})
+}
+
+func TestFeatureService_Lifecycle_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		svc := NewFeatureService(pool, 30*time.Second, nil)
+		ctx := context.Background()
+		expires := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Microsecond)
+
+		created, err := svc.Create(ctx, NewFlag{
+			Key: "new_checkout", Enabled: true, Description: " New checkout ", Owner: "payments", ExpiresAt: &expires,
+		}, testChange)
+		if err != nil {
+			t.Fatalf("Create() error = %v", err)
+		}
+		if !created.Enabled || created.Description != "New checkout" || created.Owner != "payments" ||
+			created.ExpiresAt == nil || !created.ExpiresAt.Equal(expires) {
+			t.Errorf("created = %+v", created)
+		}
+		if !svc.IsEnabled(ctx, "new_checkout") {
+			t.Error("IsEnabled(new_checkout) = false after Create")
+		}
+		if _, err := svc.Create(ctx, NewFlag{Key: "new_checkout"}, testChange); !apperror.Is(err, apperror.CodeConflict) {
+			t.Errorf("Create(duplicate) error = %v, want Conflict", err)
+		}
+		if _, err := svc.Create(ctx, NewFlag{Key: "New-Checkout"}, testChange); !apperror.Is(err, apperror.CodeValidation) {
+			t.Errorf("Create(invalid key) error = %v, want Validation", err)
+		}
+
+		owner := "growth"
+		updated, err := svc.Update(ctx, "new_checkout", FlagUpdate{Owner: &owner, ClearExpiresAt: true}, "")
+		if err != nil {
+			t.Fatalf("Update() error = %v", err)
+		}
+		if updated.Owner != "growth" || updated.Description != "New checkout" || updated.ExpiresAt != nil {
+			t.Errorf("updated = %+v", updated)
+		}
+		if _, err := svc.Update(ctx, "missing", FlagUpdate{Owner: &owner}, ""); !apperror.Is(err, apperror.CodeNotFound) {
+			t.Errorf("Update(missing) error = %v, want NotFound", err)
+		}
+
+		sched, err := svc.ScheduleChange(ctx, ScheduleRequest{
+			Key: "new_checkout", Action: ScheduleDisable, RunAt: time.Now().Add(time.Hour),
+		}, testChange)
+		if err != nil {
+			t.Fatalf("ScheduleChange() error = %v", err)
+		}
+
+		if err := svc.Delete(ctx, "new_checkout", Change{}); !apperror.Is(err, apperror.CodeValidation) {
+			t.Errorf("Delete() without reason error = %v, want Validation", err)
+		}
+		if err := svc.Delete(ctx, "new_checkout", Change{Reason: "launched"}); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if svc.IsEnabled(ctx, "new_checkout") {
+			t.Error("IsEnabled(new_checkout) = true after Delete")
+		}
+		if _, err := svc.Get(ctx, "new_checkout"); !apperror.Is(err, apperror.CodeNotFound) {
+			t.Errorf("Get(deleted) error = %v, want NotFound", err)
+		}
+		if err := svc.Delete(ctx, "new_checkout", testChange); !apperror.Is(err, apperror.CodeNotFound) {
+			t.Errorf("Delete(deleted) error = %v, want NotFound", err)
+		}
+		schedules, err := svc.ListSchedules(ctx, "new_checkout", ScheduleCancelled, 1, 10)
+		if err != nil || len(schedules.Schedules) != 1 || schedules.Schedules[0].ID != sched.ID {
+			t.Errorf("cancelled schedules = %+v, err = %v", schedules, err)
+		}
+
+		history, err := svc.History(ctx, "new_checkout", 1, 10)
+		if err != nil {
+			t.Fatalf("History() error = %v", err)
+		}
+		deleted := history.Events[0]
+		if deleted.Action != actionDelete || deleted.NewState != nil || deleted.OldState == nil || !deleted.OldState.Enabled {
+			t.Errorf("delete event = %+v", deleted)
+		}
+		if last := history.Events[len(history.Events)-1]; last.Action != actionCreate || last.OldState != nil {
+			t.Errorf("create event = %+v", last)
+		}
+
+		if err := svc.Rollback(ctx, "new_checkout", deleted.ID, Change{Reason: "still needed"}); err != nil {
+			t.Fatalf("Rollback(delete) error = %v", err)
+		}
+		if !svc.IsEnabled(ctx, "new_checkout") {
+			t.Error("IsEnabled(new_checkout) = false after rolling back the delete")
+		}
+	})
+}
+
+func TestFeatureService_UsageAndStale_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		svc := NewFeatureService(pool, 30*time.Second, nil)
+		ctx := context.Background()
+
+		for _, key := range []string{"active", "forgotten", "expiring"} {
+			if err := svc.Set(ctx, key, true, testChange); err != nil {
+				t.Fatalf("Set(%s) error = %v", key, err)
+			}
+		}
+		if _, err := pool.Exec(ctx,
+			`UPDATE feature_flags SET created_at = NOW() - INTERVAL '90 days'`); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := pool.Exec(ctx,
+			`UPDATE feature_flags SET expires_at = NOW() - INTERVAL '1 day' WHERE key = 'expiring'`); err != nil {
+			t.Fatal(err)
+		}
+
+		svc.IsEnabled(ctx, "active")
+		svc.IsEnabled(ctx, "active")
+		svc.IsEnabled(ctx, "expiring")
+		n, err := svc.FlushUsage(ctx)
+		if err != nil || n != 2 {
+			t.Fatalf("FlushUsage() = %d, %v; want 2", n, err)
+		}
+		svc.IsEnabled(ctx, "active")
+		if _, err := svc.FlushUsage(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		active, err := svc.Get(ctx, "active")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if active.Evaluations != 3 || active.LastEvaluatedAt == nil {
+			t.Errorf("active usage = %d at %v, want 3", active.Evaluations, active.LastEvaluatedAt)
+		}
+
+		stale, err := svc.Stale(ctx, 30*24*time.Hour)
+		if err != nil {
+			t.Fatalf("Stale() error = %v", err)
+		}
+		got := map[string][]string{}
+		for _, f := range stale {
+			got[f.Key] = f.Reasons
+		}
+		if len(got) != 2 || len(got["forgotten"]) != 1 || got["forgotten"][0] != StaleUnused ||
+			len(got["expiring"]) != 1 || got["expiring"][0] != StaleExpired {
+			t.Errorf("Stale() = %v, want forgotten unused and expiring expired", got)
+		}
+	})
+}
+
+func TestFeatureService_Sync_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		svc := NewFeatureService(pool, 30*time.Second, nil)
+		ctx := context.Background()
+
+		m, err := ParseManifest([]byte(`
+flags:
+  new_checkout:
+    description: Redesigned checkout
+    owner: payments
+    environments:
+      staging: {enabled: true}
+  page_size:
+    type: number
+    value: 20
+    enabled: true
+  legacy:
+    enabled: true
+`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// A flag created at runtime before the manifest declared it.
+		if err := svc.Set(ctx, "legacy", false, testChange); err != nil {
+			t.Fatal(err)
+		}
+		staging := SyncOptions{Environment: "staging"}
+
+		dry := staging
+		dry.DryRun = true
+		result, err := svc.Sync(ctx, m, dry)
+		if err != nil {
+			t.Fatalf("Sync(dry run) error = %v", err)
+		}
+		if len(result.Changes) != 3 || !result.DryRun {
+			t.Errorf("dry run = %+v", result)
+		}
+		if _, err := svc.Get(ctx, "new_checkout"); !apperror.Is(err, apperror.CodeNotFound) {
+			t.Errorf("dry run wrote new_checkout: err = %v", err)
+		}
+
+		result, err = svc.Sync(ctx, m, staging)
+		if err != nil {
+			t.Fatalf("Sync() error = %v", err)
+		}
+		actions := map[string]string{}
+		for _, c := range result.Changes {
+			actions[c.Key] = c.Action
+		}
+		if actions["new_checkout"] != SyncCreate || actions["page_size"] != SyncCreate || actions["legacy"] != SyncSkip {
+			t.Errorf("first sync = %v", actions)
+		}
+		checkout, err := svc.Get(ctx, "new_checkout")
+		if err != nil || !checkout.Enabled || checkout.Owner != "payments" {
+			t.Errorf("new_checkout = %+v, err = %v", checkout, err)
+		}
+		if !svc.IsEnabled(ctx, "new_checkout") || svc.GetInt(ctx, "page_size", Subject{}, 0) != 20 {
+			t.Error("cache not updated by sync")
+		}
+
+		// Second run: nothing to do except the still-skipped legacy flag.
+		result, err = svc.Sync(ctx, m, staging)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Unchanged != 2 || len(result.Changes) != 1 || result.Changes[0].Key != "legacy" {
+			t.Errorf("second sync = %+v", result)
+		}
+
+		// An admin turns the flag off at runtime; sync must not undo it.
+		if err := svc.Set(ctx, "new_checkout", false, testChange); err != nil {
+			t.Fatal(err)
+		}
+		result, err = svc.Sync(ctx, m, staging)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range result.Changes {
+			if c.Key == "new_checkout" && c.Action != SyncSkip {
+				t.Errorf("runtime change not preserved: %+v", c)
+			}
+		}
+		if svc.IsEnabled(ctx, "new_checkout") {
+			t.Error("sync overwrote a runtime change")
+		}
+
+		forced := staging
+		forced.Force = true
+		forced.Reason = "Reset to manifest"
+		if _, err := svc.Sync(ctx, m, forced); err != nil {
+			t.Fatal(err)
+		}
+		if !svc.IsEnabled(ctx, "new_checkout") || !svc.IsEnabled(ctx, "legacy") {
+			t.Error("forced sync did not apply the manifest")
+		}
+		history, err := svc.History(ctx, "new_checkout", 1, 10)
+		if err != nil || history.Events[0].Action != actionSync || history.Events[0].Reason != "Reset to manifest" {
+			t.Errorf("history = %+v, err = %v", history, err)
+		}
+
+		// Removing a managed flag from the manifest deletes it only with Prune.
+		delete(m.Flags, "page_size")
+		result, err = svc.Sync(ctx, m, staging)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := svc.Get(ctx, "page_size"); err != nil {
+			t.Errorf("page_size deleted without prune: %v (%+v)", err, result)
+		}
+		pruned := staging
+		pruned.Prune = true
+		if _, err := svc.Sync(ctx, m, pruned); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := svc.Get(ctx, "page_size"); !apperror.Is(err, apperror.CodeNotFound) {
+			t.Errorf("page_size after prune: err = %v, want NotFound", err)
+		}
+	})
+}
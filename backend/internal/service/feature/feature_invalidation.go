@@ -0,0 +1,158 @@
+package feature
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/golid-ai/golid/backend/internal/logger"
+)
+
+// InvalidationChannel is the Postgres NOTIFY channel and Redis pub/sub
+// channel that carry feature flag changes. The payload is the flag key.
+const InvalidationChannel = "feature_flags"
+
+// Invalidator broadcasts flag changes to every instance.
+type Invalidator interface {
+	// Publish announces that the flag key changed.
+	Publish(ctx context.Context, key string) error
+	// Listen subscribes and blocks, calling ready once the subscription is
+	// live and invalidate for every change, until ctx is cancelled or the
+	// connection fails. It returns the reason it stopped.
+	Listen(ctx context.Context, ready func(), invalidate func(key string)) error
+}
+
+const (
+	// maxListenAge bounds staleness while the listener is connected, in
+	// case a publish was lost (Redis pub/sub is fire-and-forget).
+	maxListenAge = 10 * time.Minute
+
+	watchMinBackoff = time.Second
+	watchMaxBackoff = 30 * time.Second
+)
+
+// Watch keeps the cache in sync with other instances until ctx is
+// cancelled. While subscribed the cache is refreshed on every change and
+// the TTL is not consulted; while disconnected Watch retries with backoff
+// and IsEnabled falls back to TTL polling. A no-op without an Invalidator.
+func (s *FeatureService) Watch(ctx context.Context) {
+	if s.invalidator == nil {
+		return
+	}
+	backoff := watchMinBackoff
+	for {
+		err := s.invalidator.Listen(ctx,
+			func() {
+				// Changes made while disconnected were never delivered.
+				s.invalidate()
+				if s.refresh(ctx) {
+					s.setListening(true)
+					logger.Info("feature flag listener connected")
+				}
+				backoff = watchMinBackoff
+			},
+			func(key string) {
+				logger.Debug("feature flag changed", slog.String("key", key))
+				s.invalidate()
+				s.refresh(ctx)
+			})
+		s.setListening(false)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Warn("feature flag listener disconnected; polling until it reconnects",
+			slog.String("error", fmt.Sprint(err)),
+			slog.Duration("retry_in", backoff))
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff = min(backoff*2, watchMaxBackoff)
+	}
+}
+
+// publish tells other instances that key changed. Failures are logged, not
+// returned: the write has already committed, and other instances catch up
+// within maxListenAge (or the TTL while disconnected).
+func (s *FeatureService) publish(ctx context.Context, key string) {
+	if s.invalidator == nil {
+		return
+	}
+	if err := s.invalidator.Publish(ctx, key); err != nil {
+		logger.Error("failed to publish feature flag change",
+			slog.String("key", key),
+			slog.String("error", err.Error()))
+	}
+}
+
+// PostgresInvalidator uses LISTEN/NOTIFY. Listen holds its own connection
+// outside the pool so a long-lived LISTEN never takes a pooled slot.
+type PostgresInvalidator struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresInvalidator(pool *pgxpool.Pool) *PostgresInvalidator {
+	return &PostgresInvalidator{pool: pool}
+}
+
+func (p *PostgresInvalidator) Publish(ctx context.Context, key string) error {
+	_, err := p.pool.Exec(ctx, `SELECT pg_notify($1, $2)`, InvalidationChannel, key)
+	return err
+}
+
+func (p *PostgresInvalidator) Listen(ctx context.Context, ready func(), invalidate func(string)) error {
+	conn, err := pgx.ConnectConfig(ctx, p.pool.Config().ConnConfig.Copy())
+	if err != nil {
+		return fmt.Errorf("connect: %w", err)
+	}
+	defer conn.Close(context.Background()) //nolint:errcheck // connection is being discarded
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{InvalidationChannel}.Sanitize()); err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	ready()
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		invalidate(n.Payload)
+	}
+}
+
+// RedisInvalidator uses Redis pub/sub.
+type RedisInvalidator struct {
+	client *redis.Client
+}
+
+func NewRedisInvalidator(client *redis.Client) *RedisInvalidator {
+	return &RedisInvalidator{client: client}
+}
+
+func (r *RedisInvalidator) Publish(ctx context.Context, key string) error {
+	return r.client.Publish(ctx, InvalidationChannel, key).Err()
+}
+
+func (r *RedisInvalidator) Listen(ctx context.Context, ready func(), invalidate func(string)) error {
+	sub := r.client.Subscribe(ctx, InvalidationChannel)
+	defer sub.Close() //nolint:errcheck // subscription is being discarded
+
+	// The first reply confirms the subscription (or reports why it failed).
+	if _, err := sub.Receive(ctx); err != nil {
+		return fmt.Errorf("subscribe: %w", err)
+	}
+	ready()
+	for {
+		msg, err := sub.ReceiveMessage(ctx)
+		if err != nil {
+			return err
+		}
+		invalidate(msg.Payload)
+	}
+}
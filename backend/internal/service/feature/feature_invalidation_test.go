@@ -0,0 +1,132 @@
+package feature
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestFeatureService_Fresh(t *testing.T) {
+	svc := NewFeatureService(nil, time.Minute, nil)
+	seedCache(svc, map[string]bool{"flag": true})
+
+	svc.cacheMu.RLock()
+	fresh := svc.fresh()
+	svc.cacheMu.RUnlock()
+	if !fresh {
+		t.Fatal("newly loaded cache should be fresh")
+	}
+
+	svc.invalidate()
+	svc.cacheMu.RLock()
+	fresh = svc.fresh()
+	svc.cacheMu.RUnlock()
+	if fresh {
+		t.Error("cache should be stale after an invalidation")
+	}
+}
+
+func TestFeatureService_Fresh_ListeningOutlivesTTL(t *testing.T) {
+	svc := NewFeatureService(nil, time.Second, nil)
+	seedCache(svc, map[string]bool{"flag": true})
+	svc.cacheMu.Lock()
+	svc.cacheAt = time.Now().Add(-time.Minute)
+	svc.cacheMu.Unlock()
+
+	svc.setListening(true)
+	if !svc.IsEnabled(context.Background(), "flag") {
+		t.Error("a subscribed instance should serve the cache past its TTL")
+	}
+
+	// Past maxListenAge the cache is reloaded even while subscribed; with
+	// a nil pool that would panic, so only check the predicate.
+	svc.cacheMu.Lock()
+	svc.cacheAt = time.Now().Add(-maxListenAge)
+	fresh := svc.fresh()
+	svc.cacheMu.Unlock()
+	if fresh {
+		t.Error("cache older than maxListenAge should be stale")
+	}
+}
+
+type failingInvalidator struct{ listens atomic.Int32 }
+
+func (f *failingInvalidator) Publish(context.Context, string) error { return errors.New("down") }
+func (f *failingInvalidator) Listen(context.Context, func(), func(string)) error {
+	f.listens.Add(1)
+	return errors.New("connection refused")
+}
+
+func TestFeatureService_Watch_StopsOnCancel(t *testing.T) {
+	inv := &failingInvalidator{}
+	svc := NewFeatureService(nil, time.Minute, inv)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		svc.Watch(ctx)
+		close(done)
+	}()
+
+	// The first attempt fails; Watch then waits out its backoff.
+	deadline := time.Now().Add(time.Second)
+	for inv.listens.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Watch did not return after cancel")
+	}
+	if svc.listening {
+		t.Error("listening should be false after a failed subscription")
+	}
+}
+
+func TestRedisInvalidator_RoundTrip(t *testing.T) {
+	mr := miniredis.RunT(t)
+	inv := NewRedisInvalidator(redis.NewClient(&redis.Options{Addr: mr.Addr()}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ready := make(chan struct{})
+	keys := make(chan string, 1)
+	errc := make(chan error, 1)
+	go func() {
+		errc <- inv.Listen(ctx, func() { close(ready) }, func(key string) { keys <- key })
+	}()
+
+	select {
+	case <-ready:
+	case <-time.After(time.Second):
+		t.Fatal("Listen never became ready")
+	}
+	if err := inv.Publish(ctx, "dark_mode"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	select {
+	case key := <-keys:
+		if key != "dark_mode" {
+			t.Errorf("key = %q, want dark_mode", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("invalidation not delivered")
+	}
+
+	// Losing the server ends Listen so Watch can reconnect.
+	mr.Close()
+	select {
+	case err := <-errc:
+		if err == nil {
+			t.Error("Listen returned nil after the connection dropped")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Listen did not return after the server went away")
+	}
+}
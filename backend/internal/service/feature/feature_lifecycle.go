@@ -0,0 +1,282 @@
+package feature
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/logger"
+)
+
+const (
+	maxKeyLen         = 64
+	maxDescriptionLen = 1000
+	maxOwnerLen       = 200
+)
+
+// Stale reasons reported by Stale.
+const (
+	StaleExpired = "expired"
+	StaleUnused  = "unused"
+)
+
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// NewFlag describes a flag to create.
+type NewFlag struct {
+	Key         string     `json:"key"`
+	Enabled     bool       `json:"enabled"`
+	Description string     `json:"description"`
+	Owner       string     `json:"owner"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// FlagUpdate changes a flag's bookkeeping. Nil fields are left alone;
+// ClearExpiresAt removes the expiry.
+type FlagUpdate struct {
+	Description    *string
+	Owner          *string
+	ExpiresAt      *time.Time
+	ClearExpiresAt bool
+}
+
+// StaleFlag is a flag that is past its expiry or has not been evaluated
+// recently, with the reasons it was reported.
+type StaleFlag struct {
+	FeatureFlag
+	Reasons []string `json:"reasons"`
+}
+
+// validateKey checks that a new flag's key is snake_case.
+func validateKey(key string) error {
+	if len(key) > maxKeyLen || !keyPattern.MatchString(key) {
+		return apperror.Validation().WithDetail("key", "error.feature.key_format", maxKeyLen)
+	}
+	return nil
+}
+
+func validateMetadata(verr *apperror.AppError, description, owner *string) {
+	if description != nil && len(*description) > maxDescriptionLen {
+		verr.WithDetail("description", "error.feature.description_too_long", maxDescriptionLen)
+	}
+	if owner != nil && len(*owner) > maxOwnerLen {
+		verr.WithDetail("owner", "error.feature.owner_too_long", maxOwnerLen)
+	}
+}
+
+// Get returns one flag with its metadata and usage.
+func (s *FeatureService) Get(ctx context.Context, key string) (*FeatureFlag, error) {
+	var f FeatureFlag
+	err := scanFlag(s.pool.QueryRow(ctx,
+		`SELECT `+flagColumns+` FROM `+flagFrom+` WHERE f.key = $1`, key,
+	), &f)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperror.NotFound("Feature flag")
+	}
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("get feature flag: %w", err))
+	}
+	return &f, nil
+}
+
+// Create adds a flag. Unlike Set it fails with Conflict if the key is
+// taken. The creation is recorded in the flag's history.
+func (s *FeatureService) Create(ctx context.Context, flag NewFlag, change Change) (*FeatureFlag, error) {
+	if err := validateKey(flag.Key); err != nil {
+		return nil, err
+	}
+	flag.Description = strings.TrimSpace(flag.Description)
+	flag.Owner = strings.TrimSpace(flag.Owner)
+	verr := apperror.Validation()
+	validateMetadata(verr, &flag.Description, &flag.Owner)
+	if flag.ExpiresAt != nil && !flag.ExpiresAt.After(time.Now()) {
+		verr.WithDetail("expires_at", "error.feature.expires_at_past")
+	}
+	if len(verr.Details) > 0 {
+		return nil, verr
+	}
+	if err := change.validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("begin tx: %w", err))
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	state, _, err := applyTx(ctx, tx, flag.Key, actionCreate, change, nil, func(old *FlagState) (FlagState, error) {
+		if old != nil {
+			return FlagState{}, apperror.Conflict("A feature flag with this key already exists").WithMessageID("error.feature.key_taken")
+		}
+		return FlagState{Enabled: flag.Enabled}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE feature_flags SET description = $2, owner = $3, expires_at = $4 WHERE key = $1`,
+		flag.Key, flag.Description, flag.Owner, flag.ExpiresAt,
+	); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("set feature flag metadata: %w", err))
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("commit tx: %w", err))
+	}
+	s.stored(ctx, flag.Key, state)
+	return s.Get(ctx, flag.Key)
+}
+
+// Update changes a flag's description, owner, or expiry. These do not
+// affect evaluation, so they are logged rather than recorded in the flag's
+// history.
+func (s *FeatureService) Update(ctx context.Context, key string, update FlagUpdate, actorID string) (*FeatureFlag, error) {
+	if update.Description != nil {
+		d := strings.TrimSpace(*update.Description)
+		update.Description = &d
+	}
+	if update.Owner != nil {
+		o := strings.TrimSpace(*update.Owner)
+		update.Owner = &o
+	}
+	verr := apperror.Validation()
+	validateMetadata(verr, update.Description, update.Owner)
+	if update.ExpiresAt != nil && update.ClearExpiresAt {
+		verr.WithDetail("expires_at", "error.feature.expires_at_conflict")
+	}
+	if len(verr.Details) > 0 {
+		return nil, verr
+	}
+
+	tag, err := s.pool.Exec(ctx,
+		`UPDATE feature_flags
+		 SET description = COALESCE($2, description),
+		     owner = COALESCE($3, owner),
+		     expires_at = CASE WHEN $5 THEN NULL ELSE COALESCE($4, expires_at) END,
+		     updated_at = NOW()
+		 WHERE key = $1`,
+		key, update.Description, update.Owner, update.ExpiresAt, update.ClearExpiresAt)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("update feature flag: %w", err))
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, apperror.NotFound("Feature flag")
+	}
+	logger.Info("feature flag metadata updated",
+		slog.String("key", key),
+		slog.String("actor_id", actorID))
+	return s.Get(ctx, key)
+}
+
+// Delete removes a flag, its usage counters, and its pending schedules.
+// The deletion is recorded in the flag's history with a null new state;
+// rolling back to it recreates the flag with its evaluation state.
+func (s *FeatureService) Delete(ctx context.Context, key string, change Change) error {
+	if err := change.validate(); err != nil {
+		return err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("begin tx: %w", err))
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if err := deleteTx(ctx, tx, key, change); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return apperror.Internal(fmt.Errorf("commit tx: %w", err))
+	}
+	s.removed(ctx, key)
+	return nil
+}
+
+// deleteTx is Delete inside the caller's transaction; the caller commits
+// and then calls removed.
+func deleteTx(ctx context.Context, tx pgx.Tx, key string, change Change) error {
+	var old FlagState
+	err := tx.QueryRow(ctx,
+		`DELETE FROM feature_flags WHERE key = $1
+		 RETURNING enabled, rules, value_type, value, variants, value_schema`, key,
+	).Scan(&old.Enabled, &old.Rules, &old.Type, &old.Value, &old.Variants, &old.Schema)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return apperror.NotFound("Feature flag")
+	}
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("delete feature flag: %w", err))
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM feature_flag_usage WHERE flag_key = $1`, key); err != nil {
+		return apperror.Internal(fmt.Errorf("delete feature flag usage: %w", err))
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE feature_flag_schedules
+		 SET status = 'cancelled', cancelled_by = $2, cancelled_at = NOW()
+		 WHERE flag_key = $1 AND status = 'pending'`,
+		key, nilIfEmpty(change.ActorID),
+	); err != nil {
+		return apperror.Internal(fmt.Errorf("cancel feature flag schedules: %w", err))
+	}
+	if _, err := tx.Exec(ctx,
+		`INSERT INTO feature_flag_events (flag_key, action, actor_id, old_state, new_state, reason, request_id)
+		 VALUES ($1, $2, $3, $4, NULL, $5, $6)`,
+		key, actionDelete, nilIfEmpty(change.ActorID), old,
+		strings.TrimSpace(change.Reason), nilIfEmpty(change.RequestID),
+	); err != nil {
+		return apperror.Internal(fmt.Errorf("record feature flag event: %w", err))
+	}
+	return nil
+}
+
+// removed drops a committed deletion from the local cache and usage
+// counters and tells other instances about it.
+func (s *FeatureService) removed(ctx context.Context, key string) {
+	s.usage.Delete(key)
+	s.cacheMu.Lock()
+	delete(s.cache, key)
+	s.cacheMu.Unlock()
+	s.publish(ctx, key)
+}
+
+// Stale returns flags that are past their expiry or have not been
+// evaluated for unusedFor (counting from creation for flags never
+// evaluated). Usage is as of the last FlushUsage on each instance.
+func (s *FeatureService) Stale(ctx context.Context, unusedFor time.Duration) ([]StaleFlag, error) {
+	now := time.Now()
+	cutoff := now.Add(-unusedFor)
+	flags, err := s.queryFlags(ctx,
+		`SELECT `+flagColumns+` FROM `+flagFrom+`
+		 WHERE f.expires_at < $1 OR COALESCE(u.last_evaluated_at, f.created_at) < $2
+		 ORDER BY f.key`,
+		now, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	stale := make([]StaleFlag, 0, len(flags))
+	for _, f := range flags {
+		sf := StaleFlag{FeatureFlag: f, Reasons: []string{}}
+		if f.ExpiresAt != nil && f.ExpiresAt.Before(now) {
+			sf.Reasons = append(sf.Reasons, StaleExpired)
+		}
+		lastUsed := f.CreatedAt
+		if f.LastEvaluatedAt != nil {
+			lastUsed = *f.LastEvaluatedAt
+		}
+		if lastUsed.Before(cutoff) {
+			sf.Reasons = append(sf.Reasons, StaleUnused)
+		}
+		stale = append(stale, sf)
+	}
+	return stale, nil
+}
@@ -0,0 +1,90 @@
+package feature
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+)
+
+func TestValidateKey(t *testing.T) {
+	tests := []struct {
+		key   string
+		valid bool
+	}{
+		{"dark_mode", true},
+		{"checkout_v2", true},
+		{"a", true},
+		{strings.Repeat("a", maxKeyLen), true},
+		{"", false},
+		{"Dark_mode", false},
+		{"dark-mode", false},
+		{"2fa", false},
+		{"_hidden", false},
+		{"dark mode", false},
+		{strings.Repeat("a", maxKeyLen+1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			err := validateKey(tt.key)
+			if tt.valid && err != nil {
+				t.Errorf("validateKey(%q) error = %v", tt.key, err)
+			}
+			if !tt.valid && !apperror.Is(err, apperror.CodeValidation) {
+				t.Errorf("validateKey(%q) error = %v, want Validation", tt.key, err)
+			}
+		})
+	}
+}
+
+func TestFeatureService_CountsEvaluations(t *testing.T) {
+	svc := NewFeatureService(nil, time.Minute, nil)
+	svc.cacheMu.Lock()
+	svc.cache = map[string]FlagState{
+		"dark_mode": {Enabled: true},
+		"headline":  {Enabled: true, Type: TypeString, Value: raw(`"Hello"`)},
+	}
+	svc.cacheAt = time.Now()
+	svc.cacheMu.Unlock()
+
+	ctx := context.Background()
+	before := time.Now()
+	svc.IsEnabled(ctx, "dark_mode")
+	svc.IsEnabledFor(ctx, "dark_mode", Subject{UserID: "u-1"})
+	svc.GetString(ctx, "headline", Subject{}, "")
+	svc.IsEnabled(ctx, "missing")
+
+	count := func(key string) int64 {
+		c, ok := svc.usage.Load(key)
+		if !ok {
+			return 0
+		}
+		return c.(*usageCounter).count.Load()
+	}
+	if got := count("dark_mode"); got != 2 {
+		t.Errorf("dark_mode evaluations = %d, want 2", got)
+	}
+	if got := count("headline"); got != 1 {
+		t.Errorf("headline evaluations = %d, want 1", got)
+	}
+	if _, ok := svc.usage.Load("missing"); ok {
+		t.Error("unknown flag was counted")
+	}
+	c, _ := svc.usage.Load("dark_mode")
+	if last := time.Unix(0, c.(*usageCounter).last.Load()); last.Before(before) {
+		t.Errorf("last evaluated = %v, want after %v", last, before)
+	}
+}
+
+func TestFeatureService_FlushUsage_NothingCounted(t *testing.T) {
+	svc := NewFeatureService(nil, time.Minute, nil)
+	svc.usage.Store("idle", &usageCounter{})
+
+	// A nil pool would panic if FlushUsage tried to write.
+	n, err := svc.FlushUsage(context.Background())
+	if n != 0 || err != nil {
+		t.Errorf("FlushUsage() = %d, %v; want 0, nil", n, err)
+	}
+}
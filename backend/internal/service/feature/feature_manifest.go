@@ -0,0 +1,282 @@
+package feature
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"go.yaml.in/yaml/v2"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+)
+
+// Manifest declares feature flags as code. It is YAML (or JSON, which is
+// valid YAML):
+//
+//	flags:
+//	  new_checkout:
+//	    description: Redesigned checkout
+//	    owner: payments
+//	    expires_at: 2027-01-31
+//	    enabled: false
+//	    rules: {user_types: [admin]}
+//	    environments:
+//	      staging: {enabled: true}
+//
+// Top-level fields are the defaults; an environment's entry overrides
+// enabled, rules, value, and variants. Value fields (type, value,
+// variants, schema) use the same names as the admin API.
+type Manifest struct {
+	Flags map[string]ManifestFlag `json:"flags"`
+}
+
+// ManifestFlag is one flag's declared defaults and per-environment
+// overrides.
+type ManifestFlag struct {
+	Description string `json:"description"`
+	Owner       string `json:"owner"`
+	// ExpiresAt is a date (YYYY-MM-DD, midnight UTC) or RFC 3339 timestamp.
+	ExpiresAt string `json:"expires_at"`
+	Enabled   bool   `json:"enabled"`
+	Rules     *Rules `json:"rules"`
+	Values
+	Environments map[string]ManifestOverride `json:"environments"`
+}
+
+// ManifestOverride replaces the fields it sets for one environment.
+type ManifestOverride struct {
+	Enabled  *bool           `json:"enabled"`
+	Rules    *Rules          `json:"rules"`
+	Value    json.RawMessage `json:"value"`
+	Variants []Variant       `json:"variants"`
+}
+
+// syncState is everything the manifest controls for a flag in one
+// environment. It is also what feature_flags.synced_state records, so the
+// sync can tell its own writes from changes made at runtime.
+type syncState struct {
+	FlagState
+	Description string     `json:"description"`
+	Owner       string     `json:"owner"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// LoadManifest reads and parses a manifest file.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read manifest: %w", err)
+	}
+	return ParseManifest(data)
+}
+
+// ParseManifest parses a YAML or JSON manifest and checks every flag in
+// every environment it names. Unknown fields are rejected so typos do not
+// silently fall back to defaults.
+func ParseManifest(data []byte) (*Manifest, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	// Round-trip through JSON so the manifest shares the API's field names
+	// and raw value handling.
+	buf, err := json.Marshal(jsonCompatible(doc))
+	if err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.DisallowUnknownFields()
+	var m Manifest
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+
+	for _, key := range m.keys() {
+		if err := validateKey(key); err != nil {
+			return nil, fmt.Errorf("flags.%s: key must be lowercase letters, digits, and underscores and start with a letter", key)
+		}
+		envs := []string{""}
+		for env := range m.Flags[key].Environments {
+			envs = append(envs, env)
+		}
+		for _, env := range envs {
+			if _, err := m.Flags[key].resolve(env); err != nil {
+				if env != "" {
+					return nil, fmt.Errorf("flags.%s.environments.%s: %w", key, env, err)
+				}
+				return nil, fmt.Errorf("flags.%s: %w", key, err)
+			}
+		}
+	}
+	return &m, nil
+}
+
+// keys returns the manifest's flag keys in order.
+func (m *Manifest) keys() []string {
+	keys := make([]string, 0, len(m.Flags))
+	for key := range m.Flags {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// resolve returns what every flag should look like in env.
+func (m *Manifest) resolve(env string) (map[string]syncState, error) {
+	states := make(map[string]syncState, len(m.Flags))
+	for key, f := range m.Flags {
+		state, err := f.resolve(env)
+		if err != nil {
+			return nil, fmt.Errorf("flags.%s: %w", key, err)
+		}
+		states[key] = state
+	}
+	return states, nil
+}
+
+// resolve applies env's overrides to the defaults and validates the result.
+func (f ManifestFlag) resolve(env string) (syncState, error) {
+	values := f.Values
+	if values.Type == "" {
+		values.Type = TypeBoolean
+	}
+	state := syncState{
+		FlagState:   FlagState{Enabled: f.Enabled, Rules: f.Rules},
+		Description: strings.TrimSpace(f.Description),
+		Owner:       strings.TrimSpace(f.Owner),
+	}
+	if o, ok := f.Environments[env]; ok && env != "" {
+		if o.Enabled != nil {
+			state.Enabled = *o.Enabled
+		}
+		if o.Rules != nil {
+			state.Rules = o.Rules
+		}
+		if o.Value != nil {
+			values.Value = o.Value
+		}
+		if o.Variants != nil {
+			values.Variants = o.Variants
+		}
+	}
+	state.Type, state.Value, state.Variants, state.Schema = values.Type, values.Value, values.Variants, values.Schema
+
+	if err := state.Rules.Validate(); err != nil {
+		return syncState{}, fmt.Errorf("rules: %w", err)
+	}
+	if err := values.Validate(); err != nil {
+		return syncState{}, err
+	}
+	verr := apperror.Validation()
+	validateMetadata(verr, &state.Description, &state.Owner)
+	for _, field := range []string{"description", "owner"} {
+		if msg := verr.Details[field]; msg != "" {
+			return syncState{}, fmt.Errorf("%s: %s", field, msg)
+		}
+	}
+	if f.ExpiresAt != "" {
+		t, err := parseManifestTime(f.ExpiresAt)
+		if err != nil {
+			return syncState{}, fmt.Errorf("expires_at: must be a YYYY-MM-DD date or RFC 3339 timestamp")
+		}
+		state.ExpiresAt = &t
+	}
+	return state.normalize(), nil
+}
+
+func parseManifestTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// normalize puts a state in the form it has after a round trip through
+// the database, so equal states compare equal.
+func (st syncState) normalize() syncState {
+	st.Type = st.valueType()
+	if len(st.Variants) == 0 {
+		st.Variants = nil
+	}
+	if len(st.Value) == 0 {
+		st.Value = nil
+	}
+	if len(st.Schema) == 0 {
+		st.Schema = nil
+	}
+	if st.ExpiresAt != nil {
+		t := st.ExpiresAt.UTC().Truncate(time.Microsecond)
+		st.ExpiresAt = &t
+	}
+	return st
+}
+
+// diff returns the names of the fields that differ between two states.
+// JSON fields are compared by meaning, not formatting.
+func (st syncState) diff(other syncState) []string {
+	a, b := st.normalize(), other.normalize()
+	fields := []struct {
+		name string
+		a, b any
+	}{
+		{"enabled", a.Enabled, b.Enabled},
+		{"rules", a.Rules, b.Rules},
+		{"type", a.Type, b.Type},
+		{"value", a.Value, b.Value},
+		{"variants", a.Variants, b.Variants},
+		{"schema", a.Schema, b.Schema},
+		{"description", a.Description, b.Description},
+		{"owner", a.Owner, b.Owner},
+		{"expires_at", a.ExpiresAt, b.ExpiresAt},
+	}
+	var changed []string
+	for _, f := range fields {
+		if !jsonEqual(f.a, f.b) {
+			changed = append(changed, f.name)
+		}
+	}
+	return changed
+}
+
+func jsonEqual(a, b any) bool {
+	x, errA := canonical(a)
+	y, errB := canonical(b)
+	return errA == nil && errB == nil && reflect.DeepEqual(x, y)
+}
+
+// canonical decodes v's JSON encoding into generic maps, slices, and
+// scalars, dropping formatting and key order.
+func canonical(v any) (any, error) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	err = json.Unmarshal(buf, &out)
+	return out, err
+}
+
+// jsonCompatible converts the map[any]any values YAML produces into
+// map[string]any so they can be encoded as JSON.
+func jsonCompatible(v any) any {
+	switch v := v.(type) {
+	case map[any]any:
+		m := make(map[string]any, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = jsonCompatible(val)
+		}
+		return m
+	case []any:
+		for i, val := range v {
+			v[i] = jsonCompatible(val)
+		}
+		return v
+	default:
+		return v
+	}
+}
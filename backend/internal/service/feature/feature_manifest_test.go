@@ -0,0 +1,161 @@
+package feature
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testManifest = `
+flags:
+  new_checkout:
+    description: Redesigned checkout
+    owner: payments
+    expires_at: 2027-01-31
+    enabled: false
+    rules: {user_types: [admin]}
+    environments:
+      staging:
+        enabled: true
+        rules: {percentage: 50}
+  page_size:
+    type: number
+    value: 20
+    schema: {type: integer, minimum: 1}
+    enabled: true
+    environments:
+      production: {value: 50}
+`
+
+func TestParseManifest(t *testing.T) {
+	m, err := ParseManifest([]byte(testManifest))
+	if err != nil {
+		t.Fatalf("ParseManifest() error = %v", err)
+	}
+	if got := m.keys(); !slices.Equal(got, []string{"new_checkout", "page_size"}) {
+		t.Errorf("keys = %v", got)
+	}
+
+	dev, err := m.resolve("development")
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkout := dev["new_checkout"]
+	wantExpiry := time.Date(2027, 1, 31, 0, 0, 0, 0, time.UTC)
+	if checkout.Enabled || checkout.Type != TypeBoolean || checkout.Owner != "payments" ||
+		checkout.ExpiresAt == nil || !checkout.ExpiresAt.Equal(wantExpiry) ||
+		checkout.Rules == nil || !slices.Equal(checkout.Rules.UserTypes, []string{"admin"}) {
+		t.Errorf("development new_checkout = %+v", checkout)
+	}
+	if string(dev["page_size"].Value) != "20" || dev["page_size"].Schema == nil {
+		t.Errorf("development page_size = %+v", dev["page_size"])
+	}
+
+	staging, _ := m.resolve("staging")
+	if c := staging["new_checkout"]; !c.Enabled || c.Rules == nil || c.Rules.UserTypes != nil || *c.Rules.Percentage != 50 {
+		t.Errorf("staging new_checkout = %+v", c)
+	}
+	prod, _ := m.resolve("production")
+	if string(prod["page_size"].Value) != "50" {
+		t.Errorf("production page_size value = %s", prod["page_size"].Value)
+	}
+
+	jsonManifest := `{"flags": {"dark_mode": {"enabled": true, "description": "Dark theme"}}}`
+	if m, err := ParseManifest([]byte(jsonManifest)); err != nil || !m.Flags["dark_mode"].Enabled {
+		t.Errorf("ParseManifest(json) = %+v, %v", m, err)
+	}
+}
+
+func TestParseManifest_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		wantErr  string
+	}{
+		{"not yaml", "flags: [", "parse manifest"},
+		{"unknown field", "flags:\n  dark_mode:\n    enabeld: true", "unknown field"},
+		{"bad key", "flags:\n  Dark-Mode:\n    enabled: true", "flags.Dark-Mode: key"},
+		{"bad rules", "flags:\n  beta:\n    rules: {percentage: 150}", "flags.beta: rules"},
+		{"bad expiry", "flags:\n  beta:\n    expires_at: next week", "flags.beta: expires_at"},
+		{"value on boolean", "flags:\n  beta:\n    value: 3", "flags.beta: boolean flags"},
+		{"bad override", "flags:\n  size:\n    type: number\n    value: 1\n    environments:\n      production: {value: \"x\"}",
+			"flags.size.environments.production: value: must be a number"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseManifest([]byte(tt.manifest))
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseManifest() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSyncState_Diff(t *testing.T) {
+	expires := time.Date(2027, 1, 31, 0, 0, 0, 0, time.UTC)
+	a := syncState{
+		FlagState:   FlagState{Enabled: true, Type: TypeJSON, Value: raw(`{"a":1,"b":[1,2]}`)},
+		Description: "x",
+		ExpiresAt:   &expires,
+	}
+
+	// The same state after a round trip through JSONB and a local time zone.
+	local := expires.In(time.FixedZone("EST", -5*60*60))
+	b := a
+	b.Value = raw(`{"b": [1, 2], "a": 1.0}`)
+	b.Variants = []Variant{}
+	b.ExpiresAt = &local
+	if got := a.diff(b); len(got) != 0 {
+		t.Errorf("diff of equivalent states = %v", got)
+	}
+
+	c := a
+	c.Enabled = false
+	c.Owner = "growth"
+	c.ExpiresAt = nil
+	if got := a.diff(c); !slices.Equal(got, []string{"enabled", "owner", "expires_at"}) {
+		t.Errorf("diff = %v", got)
+	}
+
+	boolean := syncState{}
+	if got := boolean.diff(syncState{FlagState: FlagState{Type: TypeBoolean}}); len(got) != 0 {
+		t.Errorf("empty type should equal boolean, diff = %v", got)
+	}
+}
+
+func TestPlanSync(t *testing.T) {
+	on := syncState{FlagState: FlagState{Enabled: true}, Description: "d"}
+	off := syncState{FlagState: FlagState{Enabled: false}, Description: "d"}
+	offOwned := off
+	offOwned.Owner = "growth"
+
+	tests := []struct {
+		name       string
+		want       syncState
+		row        syncRow
+		force      bool
+		wantAction string
+		wantFields []string
+	}{
+		{"in sync", on, syncRow{current: on, synced: &on}, false, "", nil},
+		{"matches but unmanaged", on, syncRow{current: on}, false, SyncAdopt, nil},
+		{"admin already applied the new manifest", on, syncRow{current: on, synced: &off}, false, SyncAdopt, nil},
+		{"manifest changed", on, syncRow{current: off, synced: &off}, false, SyncUpdate, []string{"enabled"}},
+		{"runtime edit", on, syncRow{current: offOwned, synced: &off}, false, SyncSkip, []string{"enabled", "owner"}},
+		{"runtime edit forced", on, syncRow{current: offOwned, synced: &off}, true, SyncUpdate, []string{"enabled", "owner"}},
+		{"created outside manifest", on, syncRow{current: off}, false, SyncSkip, []string{"enabled"}},
+		{"created outside manifest forced", on, syncRow{current: off}, true, SyncUpdate, []string{"enabled"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := planSync("flag", tt.want, tt.row, tt.force)
+			if got.Action != tt.wantAction || !slices.Equal(got.Fields, tt.wantFields) {
+				t.Errorf("planSync() = %+v, want %s %v", got, tt.wantAction, tt.wantFields)
+			}
+			if got.Action == SyncSkip && got.Reason == "" {
+				t.Error("skip without a reason")
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package feature
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func percent(n int) *int { return &n }
+
+func TestRules_Match(t *testing.T) {
+	admin := Subject{UserID: "u-admin", UserType: "admin"}
+	user := Subject{UserID: "u-user", UserType: "user"}
+	anon := Subject{}
+
+	tests := []struct {
+		name    string
+		rules   *Rules
+		subject Subject
+		want    bool
+	}{
+		{"nil rules select everyone", nil, anon, true},
+		{"empty rules select everyone", &Rules{}, user, true},
+		{"allow list only", &Rules{AllowUsers: []string{"u-user"}}, user, true},
+		{"allow list excludes others", &Rules{AllowUsers: []string{"u-user"}}, admin, false},
+		{"allow list excludes anonymous", &Rules{AllowUsers: []string{"u-user"}}, anon, false},
+		{"deny list", &Rules{DenyUsers: []string{"u-user"}}, user, false},
+		{"deny list leaves others", &Rules{DenyUsers: []string{"u-user"}}, admin, true},
+		{"deny beats allow", &Rules{AllowUsers: []string{"u-user"}, DenyUsers: []string{"u-user"}}, user, false},
+		{"user type match", &Rules{UserTypes: []string{"admin"}}, admin, true},
+		{"user type mismatch", &Rules{UserTypes: []string{"admin"}}, user, false},
+		{"allow bypasses user type", &Rules{AllowUsers: []string{"u-user"}, UserTypes: []string{"admin"}}, user, true},
+		{"zero percent", &Rules{Percentage: percent(0)}, user, false},
+		{"full percent", &Rules{Percentage: percent(100)}, user, true},
+		{"percentage excludes anonymous", &Rules{Percentage: percent(100)}, anon, false},
+		{"user type and percentage", &Rules{UserTypes: []string{"user"}, Percentage: percent(100)}, admin, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rules.match("flag", tt.subject); got != tt.want {
+				t.Errorf("match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRules_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   *Rules
+		wantErr bool
+	}{
+		{"nil", nil, false},
+		{"valid", &Rules{AllowUsers: []string{"a"}, UserTypes: []string{"admin"}, Percentage: percent(5)}, false},
+		{"negative percentage", &Rules{Percentage: percent(-1)}, true},
+		{"percentage over 100", &Rules{Percentage: percent(101)}, true},
+		{"empty user ID", &Rules{DenyUsers: []string{""}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rules.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestBucket_StableAndSpread(t *testing.T) {
+	if bucket("flag", "user-1") != bucket("flag", "user-1") {
+		t.Fatal("bucket is not deterministic")
+	}
+
+	const users = 10000
+	in := 0
+	for i := range users {
+		b := bucket("checkout_v2", fmt.Sprintf("user-%d", i))
+		if b < 0 || b >= 100 {
+			t.Fatalf("bucket = %d, want [0, 100)", b)
+		}
+		if b < 5 {
+			in++
+		}
+	}
+	// 5% of 10k is 500; allow generous slack for hash variance.
+	if in < 400 || in > 600 {
+		t.Errorf("%d of %d users in a 5%% rollout", in, users)
+	}
+}
+
+func TestBucket_RaisingPercentageOnlyAddsUsers(t *testing.T) {
+	five, ten := &Rules{Percentage: percent(5)}, &Rules{Percentage: percent(10)}
+	for i := range 1000 {
+		s := Subject{UserID: fmt.Sprintf("user-%d", i)}
+		if five.match("flag", s) && !ten.match("flag", s) {
+			t.Fatalf("%s dropped out when the rollout grew", s.UserID)
+		}
+	}
+}
+
+func TestFeatureService_IsEnabledFor_CacheHit(t *testing.T) {
+	svc := NewFeatureService(nil, time.Minute, nil)
+	svc.cacheMu.Lock()
+	svc.cache = map[string]FlagState{
+		"admin_only": {Enabled: true, Rules: &Rules{UserTypes: []string{"admin"}}},
+		"killed":     {Enabled: false, Rules: &Rules{AllowUsers: []string{"u-1"}}},
+	}
+	svc.cacheAt = time.Now()
+	svc.cacheMu.Unlock()
+
+	ctx := context.Background()
+	admin := Subject{UserID: "u-1", UserType: "admin"}
+	if !svc.IsEnabledFor(ctx, "admin_only", admin) {
+		t.Error("admin_only should be on for an admin")
+	}
+	if svc.IsEnabledFor(ctx, "admin_only", Subject{UserID: "u-2", UserType: "user"}) {
+		t.Error("admin_only should be off for a user")
+	}
+	if svc.IsEnabled(ctx, "admin_only") {
+		t.Error("IsEnabled should evaluate targeted flags as anonymous")
+	}
+	if svc.IsEnabledFor(ctx, "killed", admin) {
+		t.Error("a disabled flag should be off even for allow-listed users")
+	}
+}
@@ -0,0 +1,361 @@
+package feature
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/logger"
+	"github.com/golid-ai/golid/backend/internal/pagination"
+)
+
+// Scheduled actions.
+const (
+	ScheduleEnable  = "enable"
+	ScheduleDisable = "disable"
+	ScheduleValues  = "values"
+)
+
+// Schedule statuses.
+const (
+	SchedulePending   = "pending"
+	ScheduleApplied   = "applied"
+	ScheduleCancelled = "cancelled"
+	ScheduleFailed    = "failed"
+)
+
+const (
+	schedulePerPageDefault = 20
+	schedulePerPageMax     = 100
+
+	// applyDueBatch bounds how many schedules one ApplyDue call works
+	// through, so a backlog cannot hold a ticker goroutine indefinitely.
+	applyDueBatch = 100
+)
+
+// ScheduleRequest describes a change to make at RunAt. Values is required
+// for ScheduleValues and ignored otherwise. RevertAt, only valid for
+// ScheduleEnable, turns the flag back off at that time.
+type ScheduleRequest struct {
+	Key      string     `json:"key"`
+	Action   string     `json:"action"`
+	Values   *Values    `json:"values,omitempty"`
+	RunAt    time.Time  `json:"run_at"`
+	RevertAt *time.Time `json:"revert_at,omitempty"`
+}
+
+// Schedule is a pending, applied, cancelled, or failed scheduled change.
+type Schedule struct {
+	ID          string     `json:"id"`
+	FlagKey     string     `json:"flag_key"`
+	Action      string     `json:"action"`
+	Values      *Values    `json:"values,omitempty"`
+	RunAt       time.Time  `json:"run_at"`
+	RevertAt    *time.Time `json:"revert_at,omitempty"`
+	Status      string     `json:"status"`
+	Reason      string     `json:"reason"`
+	CreatedBy   *string    `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	AppliedAt   *time.Time `json:"applied_at,omitempty"`
+	CancelledBy *string    `json:"cancelled_by,omitempty"`
+	CancelledAt *time.Time `json:"cancelled_at,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	RevertOf    *string    `json:"revert_of,omitempty"`
+}
+
+// ScheduleList is a page of schedules ordered by run time.
+type ScheduleList struct {
+	Schedules  []Schedule `json:"schedules"`
+	Total      int        `json:"total"`
+	Page       int        `json:"page"`
+	PerPage    int        `json:"per_page"`
+	TotalPages int        `json:"total_pages"`
+}
+
+const scheduleColumns = `id, flag_key, action, flag_values, run_at, revert_at, status, reason,
+	created_by::text, created_at, applied_at, cancelled_by::text, cancelled_at, COALESCE(error, ''), revert_of::text`
+
+func scanSchedule(row pgx.Row, sc *Schedule) error {
+	return row.Scan(&sc.ID, &sc.FlagKey, &sc.Action, &sc.Values, &sc.RunAt, &sc.RevertAt, &sc.Status, &sc.Reason,
+		&sc.CreatedBy, &sc.CreatedAt, &sc.AppliedAt, &sc.CancelledBy, &sc.CancelledAt, &sc.Error, &sc.RevertOf)
+}
+
+func (r ScheduleRequest) validate(now time.Time) error {
+	verr := apperror.Validation()
+	if r.Key == "" {
+		verr.WithDetail("key", "error.feature.schedule_key_required")
+	}
+	switch r.Action {
+	case ScheduleEnable, ScheduleDisable:
+		if r.Values != nil {
+			verr.WithDetail("values", "error.feature.schedule_values_unexpected")
+		}
+	case ScheduleValues:
+		if r.Values == nil {
+			verr.WithDetail("values", "error.feature.schedule_values_required")
+		} else if err := r.Values.Validate(); err != nil {
+			verr.WithDetail("values", "error.feature.invalid_values", err.Error())
+		}
+	default:
+		verr.WithDetail("action", "error.feature.schedule_action")
+	}
+	if !r.RunAt.After(now) {
+		verr.WithDetail("run_at", "error.feature.schedule_run_at_past")
+	}
+	if r.RevertAt != nil {
+		switch {
+		case r.Action != ScheduleEnable:
+			verr.WithDetail("revert_at", "error.feature.schedule_revert_enable_only")
+		case !r.RevertAt.After(r.RunAt):
+			verr.WithDetail("revert_at", "error.feature.schedule_revert_before_run")
+		}
+	}
+	if len(verr.Details) > 0 {
+		return verr
+	}
+	return nil
+}
+
+// ScheduleChange queues a flag change for a future time. The flag does not
+// need to exist yet unless the action is ScheduleValues, and even then it
+// is only checked when the schedule runs.
+func (s *FeatureService) ScheduleChange(ctx context.Context, req ScheduleRequest, change Change) (*Schedule, error) {
+	if err := req.validate(time.Now()); err != nil {
+		return nil, err
+	}
+	if err := change.validate(); err != nil {
+		return nil, err
+	}
+
+	var sc Schedule
+	err := scanSchedule(s.pool.QueryRow(ctx,
+		`INSERT INTO feature_flag_schedules (flag_key, action, flag_values, run_at, revert_at, reason, created_by)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 RETURNING `+scheduleColumns,
+		req.Key, req.Action, req.Values, req.RunAt, req.RevertAt,
+		strings.TrimSpace(change.Reason), nilIfEmpty(change.ActorID),
+	), &sc)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("create feature flag schedule: %w", err))
+	}
+	return &sc, nil
+}
+
+// ListSchedules returns schedules ordered by run time, optionally narrowed
+// to one flag and one status.
+func (s *FeatureService) ListSchedules(ctx context.Context, key, status string, page, perPage int) (*ScheduleList, error) {
+	switch status {
+	case "", SchedulePending, ScheduleApplied, ScheduleCancelled, ScheduleFailed:
+	default:
+		return nil, apperror.Validation().WithDetail("status", "error.feature.schedule_status")
+	}
+	page, perPage = pagination.NormalizePagination(page, perPage, schedulePerPageDefault, schedulePerPageMax)
+	offset := (page - 1) * perPage
+
+	const where = `WHERE ($1 = '' OR flag_key = $1) AND ($2 = '' OR status = $2)`
+	var total int
+	if err := s.pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM feature_flag_schedules `+where, key, status,
+	).Scan(&total); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("count feature flag schedules: %w", err))
+	}
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT `+scheduleColumns+` FROM feature_flag_schedules `+where+`
+		 ORDER BY run_at, id
+		 LIMIT $3 OFFSET $4`,
+		key, status, perPage, offset)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("list feature flag schedules: %w", err))
+	}
+	defer rows.Close()
+
+	schedules := make([]Schedule, 0, perPage)
+	for rows.Next() {
+		var sc Schedule
+		if err := scanSchedule(rows, &sc); err != nil {
+			return nil, apperror.Internal(fmt.Errorf("scan feature flag schedule: %w", err))
+		}
+		schedules = append(schedules, sc)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("iterate feature flag schedules: %w", err))
+	}
+
+	return &ScheduleList{
+		Schedules:  schedules,
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: (total + perPage - 1) / perPage,
+	}, nil
+}
+
+// CancelSchedule cancels a pending schedule. A schedule the scheduler is
+// applying stays locked until it commits, so cancel and apply never both
+// succeed.
+func (s *FeatureService) CancelSchedule(ctx context.Context, id, actorID string) error {
+	tag, err := s.pool.Exec(ctx,
+		`UPDATE feature_flag_schedules
+		 SET status = 'cancelled', cancelled_by = $2, cancelled_at = NOW()
+		 WHERE id = $1 AND status = 'pending'`,
+		id, nilIfEmpty(actorID))
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("cancel feature flag schedule: %w", err))
+	}
+	if tag.RowsAffected() == 1 {
+		return nil
+	}
+
+	var status string
+	err = s.pool.QueryRow(ctx, `SELECT status FROM feature_flag_schedules WHERE id = $1`, id).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return apperror.NotFound("Feature flag schedule")
+	}
+	if err != nil {
+		return apperror.Internal(fmt.Errorf("get feature flag schedule: %w", err))
+	}
+	return apperror.Conflict(fmt.Sprintf("Schedule is already %s", status)).WithMessageID("error.feature.schedule_already", status)
+}
+
+// ApplyDue applies schedules whose run time has passed and returns how
+// many it applied. Each schedule is claimed with FOR UPDATE SKIP LOCKED
+// and marked applied in the same transaction as the flag write, so
+// concurrent callers on different instances never apply one twice.
+// Schedules that can no longer be applied (a values change for a flag
+// that was never created) are marked failed; database errors leave the
+// schedule pending for the next call.
+func (s *FeatureService) ApplyDue(ctx context.Context) (int, error) {
+	applied := 0
+	for applied < applyDueBatch {
+		ok, err := s.applyNext(ctx)
+		if err != nil {
+			return applied, err
+		}
+		if !ok {
+			break
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+// applyNext applies the earliest due schedule and reports whether there
+// was one. A schedule that fails permanently counts as handled.
+func (s *FeatureService) applyNext(ctx context.Context) (bool, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, apperror.Internal(fmt.Errorf("begin tx: %w", err))
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var sc Schedule
+	err = scanSchedule(tx.QueryRow(ctx,
+		`SELECT `+scheduleColumns+` FROM feature_flag_schedules
+		 WHERE status = 'pending' AND run_at <= NOW()
+		 ORDER BY run_at, id
+		 LIMIT 1
+		 FOR UPDATE SKIP LOCKED`,
+	), &sc)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, apperror.Internal(fmt.Errorf("claim feature flag schedule: %w", err))
+	}
+
+	change := Change{Reason: sc.Reason, scheduleID: sc.ID}
+	if sc.CreatedBy != nil {
+		change.ActorID = *sc.CreatedBy
+	}
+	action, next := sc.transition()
+
+	// A savepoint keeps the claim usable if the write is rejected, so the
+	// schedule can be marked failed in the same transaction.
+	write, err := tx.Begin(ctx)
+	if err != nil {
+		return false, apperror.Internal(fmt.Errorf("begin savepoint: %w", err))
+	}
+	state, old, err := applyTx(ctx, write, sc.FlagKey, action, change, nil, next)
+	if err != nil {
+		_ = write.Rollback(ctx)
+		var appErr *apperror.AppError
+		if !errors.As(err, &appErr) || appErr.Code == apperror.CodeInternal {
+			return false, err
+		}
+		failure := appErr.Message
+		if detail, ok := appErr.Details["values"]; ok {
+			failure += ": " + detail
+		}
+		if _, err := tx.Exec(ctx,
+			`UPDATE feature_flag_schedules SET status = 'failed', error = $2, applied_at = NOW() WHERE id = $1`,
+			sc.ID, failure,
+		); err != nil {
+			return false, apperror.Internal(fmt.Errorf("mark feature flag schedule failed: %w", err))
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return false, apperror.Internal(fmt.Errorf("commit tx: %w", err))
+		}
+		logger.Warn("scheduled feature flag change failed",
+			slog.String("schedule_id", sc.ID),
+			slog.String("key", sc.FlagKey),
+			slog.String("error", failure))
+		return true, nil
+	}
+	if err := write.Commit(ctx); err != nil {
+		return false, apperror.Internal(fmt.Errorf("release savepoint: %w", err))
+	}
+
+	if _, err := tx.Exec(ctx,
+		`UPDATE feature_flag_schedules SET status = 'applied', applied_at = NOW() WHERE id = $1`, sc.ID,
+	); err != nil {
+		return false, apperror.Internal(fmt.Errorf("mark feature flag schedule applied: %w", err))
+	}
+
+	// A temporary enable of a flag that was already on has nothing to
+	// revert; turning it off later would undo someone else's change.
+	if sc.RevertAt != nil && (old == nil || !old.Enabled) {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO feature_flag_schedules (flag_key, action, run_at, reason, created_by, revert_of)
+			 VALUES ($1, 'disable', $2, $3, $4, $5)`,
+			sc.FlagKey, *sc.RevertAt, "Revert: "+sc.Reason, sc.CreatedBy, sc.ID,
+		); err != nil {
+			return false, apperror.Internal(fmt.Errorf("schedule feature flag revert: %w", err))
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, apperror.Internal(fmt.Errorf("commit tx: %w", err))
+	}
+	s.stored(ctx, sc.FlagKey, state)
+	logger.Info("applied scheduled feature flag change",
+		slog.String("schedule_id", sc.ID),
+		slog.String("key", sc.FlagKey),
+		slog.String("action", sc.Action))
+	return true, nil
+}
+
+// transition maps a schedule to the audited write it performs.
+func (sc *Schedule) transition() (string, func(old *FlagState) (FlagState, error)) {
+	if sc.Action == ScheduleValues {
+		values := *sc.Values
+		return actionValues, func(old *FlagState) (FlagState, error) {
+			if old == nil {
+				return FlagState{}, apperror.NotFound("Feature flag")
+			}
+			if err := values.Validate(); err != nil {
+				return FlagState{}, apperror.Validation().WithDetail("values", "error.feature.invalid_values", err.Error())
+			}
+			next := *old
+			next.Type, next.Value, next.Variants, next.Schema = values.Type, values.Value, values.Variants, values.Schema
+			return next, nil
+		}
+	}
+	return actionSet, setEnabled(sc.FlagKey, sc.Action == ScheduleEnable)
+}
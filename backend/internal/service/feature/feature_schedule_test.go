@@ -0,0 +1,78 @@
+package feature
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+)
+
+func TestScheduleRequest_Validate(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Hour)
+	evenLater := now.Add(2 * time.Hour)
+	stringValues := &Values{Type: TypeString, Value: raw(`"Hello"`)}
+
+	tests := []struct {
+		name      string
+		req       ScheduleRequest
+		wantField string
+	}{
+		{"enable", ScheduleRequest{Key: "launch", Action: ScheduleEnable, RunAt: later}, ""},
+		{"temporary enable", ScheduleRequest{Key: "launch", Action: ScheduleEnable, RunAt: later, RevertAt: &evenLater}, ""},
+		{"values", ScheduleRequest{Key: "launch", Action: ScheduleValues, Values: stringValues, RunAt: later}, ""},
+		{"missing key", ScheduleRequest{Action: ScheduleDisable, RunAt: later}, "key"},
+		{"unknown action", ScheduleRequest{Key: "launch", Action: "toggle", RunAt: later}, "action"},
+		{"past run time", ScheduleRequest{Key: "launch", Action: ScheduleEnable, RunAt: now.Add(-time.Minute)}, "run_at"},
+		{"missing run time", ScheduleRequest{Key: "launch", Action: ScheduleEnable}, "run_at"},
+		{"values missing", ScheduleRequest{Key: "launch", Action: ScheduleValues, RunAt: later}, "values"},
+		{"values invalid", ScheduleRequest{Key: "launch", Action: ScheduleValues, Values: &Values{Type: TypeNumber, Value: raw(`"x"`)}, RunAt: later}, "values"},
+		{"values on enable", ScheduleRequest{Key: "launch", Action: ScheduleEnable, Values: stringValues, RunAt: later}, "values"},
+		{"revert on disable", ScheduleRequest{Key: "launch", Action: ScheduleDisable, RunAt: later, RevertAt: &evenLater}, "revert_at"},
+		{"revert before run", ScheduleRequest{Key: "launch", Action: ScheduleEnable, RunAt: evenLater, RevertAt: &later}, "revert_at"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.validate(now)
+			if tt.wantField == "" {
+				if err != nil {
+					t.Fatalf("validate() error = %v", err)
+				}
+				return
+			}
+			var appErr *apperror.AppError
+			if !errors.As(err, &appErr) || appErr.Code != apperror.CodeValidation || appErr.Details[tt.wantField] == "" {
+				t.Errorf("validate() error = %v, want details[%s]", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestSchedule_Transition(t *testing.T) {
+	on := FlagState{Enabled: true, Rules: &Rules{UserTypes: []string{"admin"}}, Type: TypeString, Value: raw(`"a"`)}
+
+	action, next := (&Schedule{Action: ScheduleDisable}).transition()
+	got, err := next(&on)
+	if action != actionSet || err != nil || got.Enabled || got.Rules == nil || got.Type != TypeString {
+		t.Errorf("disable: action = %s, state = %+v, err = %v", action, got, err)
+	}
+
+	_, next = (&Schedule{FlagKey: "launch", Action: ScheduleEnable}).transition()
+	if got, err := next(nil); err != nil || !got.Enabled {
+		t.Errorf("enable of a new flag: state = %+v, err = %v", got, err)
+	}
+	_, next = (&Schedule{FlagKey: "Bad Key", Action: ScheduleEnable}).transition()
+	if _, err := next(nil); !apperror.Is(err, apperror.CodeValidation) {
+		t.Errorf("enable of a new flag with an invalid key: err = %v, want Validation", err)
+	}
+
+	values := &Values{Type: TypeString, Value: raw(`"b"`)}
+	action, next = (&Schedule{Action: ScheduleValues, Values: values}).transition()
+	if got, err := next(&on); action != actionValues || err != nil || string(got.Value) != `"b"` || !got.Enabled {
+		t.Errorf("values: action = %s, state = %+v, err = %v", action, got, err)
+	}
+	if _, err := next(nil); !apperror.Is(err, apperror.CodeNotFound) {
+		t.Errorf("values on a missing flag: err = %v, want NotFound", err)
+	}
+}
@@ -0,0 +1,229 @@
+package feature
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+)
+
+// Sync actions reported in SyncChange.
+const (
+	SyncCreate = "create"
+	SyncUpdate = "update"
+	SyncAdopt  = "adopt"
+	SyncDelete = "delete"
+	SyncSkip   = "skip"
+)
+
+const defaultSyncReason = "Synced from manifest"
+
+// syncLockID serializes concurrent syncs (for example several instances
+// syncing on startup) with a transaction-scoped advisory lock.
+const syncLockID = 0x666c616773 // "flags"
+
+// SyncOptions controls Sync.
+type SyncOptions struct {
+	// Environment selects the manifest's per-environment overrides.
+	Environment string
+	// DryRun computes the changes without writing them.
+	DryRun bool
+	// Force overwrites flags that were changed at runtime since the last
+	// sync, or that were created outside the manifest.
+	Force bool
+	// Prune deletes flags the manifest used to declare but no longer does.
+	Prune bool
+	// Reason is recorded in the history of every flag the sync writes.
+	Reason string
+}
+
+// SyncChange is one flag the sync changed, or in a dry run would change.
+// Fields lists what differs from the manifest; Reason explains a skip.
+type SyncChange struct {
+	Key    string   `json:"key"`
+	Action string   `json:"action"`
+	Fields []string `json:"fields,omitempty"`
+	Reason string   `json:"reason,omitempty"`
+}
+
+// SyncResult is the outcome of a Sync.
+type SyncResult struct {
+	Changes   []SyncChange `json:"changes"`
+	Unchanged int          `json:"unchanged"`
+	DryRun    bool         `json:"dry_run"`
+}
+
+// syncRow is a flag as the sync sees it: its current state and the state
+// the last sync wrote (nil if the manifest has never managed it).
+type syncRow struct {
+	current syncState
+	synced  *syncState
+}
+
+// Sync reconciles feature_flags with the manifest for one environment.
+// Flags are created or updated to match it, but a flag whose row no longer
+// matches what the last sync wrote was changed by an admin at runtime and
+// is skipped unless Force is set; so is a flag created outside the
+// manifest. Flags the manifest stops declaring are deleted only with
+// Prune. Writes go through the audited path (action "sync" or "create")
+// in a single transaction, so a sync applies completely or not at all.
+// Flags that already match are adopted: marked as managed without a
+// history event.
+func (s *FeatureService) Sync(ctx context.Context, m *Manifest, opts SyncOptions) (*SyncResult, error) {
+	desired, err := m.resolve(opts.Environment)
+	if err != nil {
+		return nil, apperror.Validation().WithDetail("manifest", "error.feature.invalid_manifest", err.Error())
+	}
+	change := Change{Reason: opts.Reason}
+	if strings.TrimSpace(change.Reason) == "" {
+		change.Reason = defaultSyncReason
+	}
+	if err := change.validate(); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("begin tx: %w", err))
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, syncLockID); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("lock feature flag sync: %w", err))
+	}
+	rows, err := tx.Query(ctx,
+		`SELECT key, enabled, rules, value_type, value, variants, value_schema,
+		        description, owner, expires_at, synced_state
+		 FROM feature_flags FOR UPDATE`)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("load feature flags: %w", err))
+	}
+	existing := map[string]syncRow{}
+	for rows.Next() {
+		var key string
+		var r syncRow
+		c := &r.current
+		if err := rows.Scan(&key, &c.Enabled, &c.Rules, &c.Type, &c.Value, &c.Variants, &c.Schema,
+			&c.Description, &c.Owner, &c.ExpiresAt, &r.synced); err != nil {
+			rows.Close()
+			return nil, apperror.Internal(fmt.Errorf("scan feature flag: %w", err))
+		}
+		existing[key] = r
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("iterate feature flags: %w", err))
+	}
+
+	result := &SyncResult{Changes: []SyncChange{}, DryRun: opts.DryRun}
+	written := map[string]FlagState{}
+	var deleted []string
+
+	for _, key := range m.keys() {
+		want := desired[key]
+		row, exists := existing[key]
+		var c SyncChange
+		if exists {
+			c = planSync(key, want, row, opts.Force)
+		} else {
+			c = SyncChange{Key: key, Action: SyncCreate}
+		}
+
+		switch c.Action {
+		case "":
+			result.Unchanged++
+			continue
+		case SyncCreate, SyncUpdate:
+			action := actionSync
+			if c.Action == SyncCreate {
+				action = actionCreate
+			}
+			state, _, err := applyTx(ctx, tx, key, action, change, nil, func(*FlagState) (FlagState, error) {
+				return want.FlagState, nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			if _, err := tx.Exec(ctx,
+				`UPDATE feature_flags SET description = $2, owner = $3, expires_at = $4, synced_state = $5
+				 WHERE key = $1`,
+				key, want.Description, want.Owner, want.ExpiresAt, want,
+			); err != nil {
+				return nil, apperror.Internal(fmt.Errorf("sync feature flag metadata: %w", err))
+			}
+			written[key] = state
+		case SyncAdopt:
+			if _, err := tx.Exec(ctx,
+				`UPDATE feature_flags SET synced_state = $2 WHERE key = $1`, key, want,
+			); err != nil {
+				return nil, apperror.Internal(fmt.Errorf("adopt feature flag: %w", err))
+			}
+		}
+		result.Changes = append(result.Changes, c)
+	}
+
+	// Flags the manifest used to manage but no longer declares.
+	var removedKeys []string
+	for key, row := range existing {
+		if _, ok := desired[key]; !ok && row.synced != nil {
+			removedKeys = append(removedKeys, key)
+		}
+	}
+	slices.Sort(removedKeys)
+	for _, key := range removedKeys {
+		row := existing[key]
+		switch {
+		case !opts.Prune:
+			result.Changes = append(result.Changes, SyncChange{Key: key, Action: SyncSkip,
+				Reason: "No longer in the manifest; prune to delete"})
+		case !opts.Force && len(row.synced.diff(row.current)) > 0:
+			result.Changes = append(result.Changes, SyncChange{Key: key, Action: SyncSkip,
+				Reason: "No longer in the manifest but changed at runtime since the last sync; force to delete"})
+		default:
+			if err := deleteTx(ctx, tx, key, change); err != nil {
+				return nil, err
+			}
+			deleted = append(deleted, key)
+			result.Changes = append(result.Changes, SyncChange{Key: key, Action: SyncDelete})
+		}
+	}
+
+	if opts.DryRun {
+		return result, nil
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("commit tx: %w", err))
+	}
+	for key, state := range written {
+		s.stored(ctx, key, state)
+	}
+	for _, key := range deleted {
+		s.removed(ctx, key)
+	}
+	return result, nil
+}
+
+// planSync decides what to do with a flag that exists in both the
+// manifest and the database. An empty Action means it is already in sync.
+func planSync(key string, want syncState, row syncRow, force bool) SyncChange {
+	fields := row.current.diff(want)
+	if len(fields) == 0 {
+		if row.synced != nil && len(row.synced.diff(want)) == 0 {
+			return SyncChange{}
+		}
+		return SyncChange{Key: key, Action: SyncAdopt}
+	}
+	if !force {
+		if row.synced == nil {
+			return SyncChange{Key: key, Action: SyncSkip, Fields: fields,
+				Reason: "Created outside the manifest; force to take it over"}
+		}
+		if len(row.synced.diff(row.current)) > 0 {
+			return SyncChange{Key: key, Action: SyncSkip, Fields: fields,
+				Reason: "Changed at runtime since the last sync; force to overwrite"}
+		}
+	}
+	return SyncChange{Key: key, Action: SyncUpdate, Fields: fields}
+}
@@ -3,6 +3,7 @@ package feature
 import (
 	"context"
 	"encoding/json"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -13,7 +14,7 @@ import (
 // =============================================================================
 
 func TestNewFeatureService_DefaultCacheTTL(t *testing.T) {
-	svc := NewFeatureService(nil, 0)
+	svc := NewFeatureService(nil, 0, nil)
 
 	if svc.cacheTTL != 30*time.Second {
 		t.Errorf("cacheTTL = %v, want 30s", svc.cacheTTL)
@@ -33,7 +34,7 @@ func TestNewFeatureService_CustomCacheTTL(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			svc := NewFeatureService(nil, tt.ttl)
+			svc := NewFeatureService(nil, tt.ttl, nil)
 			if svc.cacheTTL != tt.want {
 				t.Errorf("cacheTTL = %v, want %v", svc.cacheTTL, tt.want)
 			}
@@ -42,7 +43,7 @@ func TestNewFeatureService_CustomCacheTTL(t *testing.T) {
 }
 
 func TestNewFeatureService_InitializesCache(t *testing.T) {
-	svc := NewFeatureService(nil, time.Second)
+	svc := NewFeatureService(nil, time.Second, nil)
 
 	if svc.cache == nil {
 		t.Error("cache map should be initialized, got nil")
@@ -53,7 +54,7 @@ func TestNewFeatureService_InitializesCache(t *testing.T) {
 }
 
 func TestNewFeatureService_NilPool(t *testing.T) {
-	svc := NewFeatureService(nil, time.Second)
+	svc := NewFeatureService(nil, time.Second, nil)
 
 	if svc.pool != nil {
 		t.Error("pool should be nil when nil is passed")
@@ -65,14 +66,18 @@ func TestNewFeatureService_NilPool(t *testing.T) {
 // =============================================================================
 
 func seedCache(svc *FeatureService, flags map[string]bool) {
+	cache := make(map[string]FlagState, len(flags))
+	for key, enabled := range flags {
+		cache[key] = FlagState{Enabled: enabled}
+	}
 	svc.cacheMu.Lock()
-	svc.cache = flags
+	svc.cache = cache
 	svc.cacheAt = time.Now()
 	svc.cacheMu.Unlock()
 }
 
 func TestFeatureService_IsEnabled_CacheHit(t *testing.T) {
-	svc := NewFeatureService(nil, time.Minute)
+	svc := NewFeatureService(nil, time.Minute, nil)
 	seedCache(svc, map[string]bool{
 		"dark_mode":   true,
 		"beta_access": false,
@@ -100,7 +105,7 @@ func TestFeatureService_IsEnabled_CacheHit(t *testing.T) {
 func TestFeatureService_IsEnabled_CacheHit_NoPoolAccess(t *testing.T) {
 	// nil pool — if cache is fresh, IsEnabled must not touch pool at all.
 	// A panic here would prove the cache path is broken.
-	svc := NewFeatureService(nil, time.Minute)
+	svc := NewFeatureService(nil, time.Minute, nil)
 	seedCache(svc, map[string]bool{"feature_x": true})
 
 	got := svc.IsEnabled(context.Background(), "feature_x")
@@ -110,7 +115,7 @@ func TestFeatureService_IsEnabled_CacheHit_NoPoolAccess(t *testing.T) {
 }
 
 func TestFeatureService_IsEnabled_UnknownKey_ReturnsFalse(t *testing.T) {
-	svc := NewFeatureService(nil, time.Minute)
+	svc := NewFeatureService(nil, time.Minute, nil)
 	seedCache(svc, map[string]bool{"known_flag": true})
 
 	if svc.IsEnabled(context.Background(), "unknown_flag") {
@@ -127,7 +132,7 @@ func TestFeatureService_IsEnabled_StaleCache_UsesOldValueOnRefreshFailure(t *tes
 	// remain. IsEnabled returns the stale value rather than panicking.
 	// We can't test this with nil pool (would panic on pool.Query), but we
 	// can verify the cacheAt boundary: a cache exactly at TTL boundary is stale.
-	svc := NewFeatureService(nil, 50*time.Millisecond)
+	svc := NewFeatureService(nil, 50*time.Millisecond, nil)
 	seedCache(svc, map[string]bool{"flag": true})
 
 	// Cache is fresh — should return true
@@ -151,10 +156,10 @@ func TestFeatureService_IsEnabled_StaleCache_UsesOldValueOnRefreshFailure(t *tes
 
 func TestFeatureService_CacheTTL_FreshBoundary(t *testing.T) {
 	ttl := 100 * time.Millisecond
-	svc := NewFeatureService(nil, ttl)
+	svc := NewFeatureService(nil, ttl, nil)
 
 	svc.cacheMu.Lock()
-	svc.cache = map[string]bool{"flag": true}
+	svc.cache = map[string]FlagState{"flag": {Enabled: true}}
 	svc.cacheAt = time.Now().Add(-90 * time.Millisecond) // 90ms ago, within 100ms TTL
 	svc.cacheMu.Unlock()
 
@@ -172,12 +177,12 @@ func TestFeatureService_Set_UpdatesCacheDirectly(t *testing.T) {
 	// Set() writes to both DB and local cache. We can't test the DB write
 	// without a pool, but we can verify the cache update logic by inspecting
 	// the struct fields directly after manually performing what Set does to cache.
-	svc := NewFeatureService(nil, time.Minute)
+	svc := NewFeatureService(nil, time.Minute, nil)
 	seedCache(svc, map[string]bool{"toggle": false})
 
 	// Simulate what Set does to cache (without pool.Exec)
 	svc.cacheMu.Lock()
-	svc.cache["toggle"] = true
+	svc.cache["toggle"] = FlagState{Enabled: true}
 	svc.cacheMu.Unlock()
 
 	if !svc.IsEnabled(context.Background(), "toggle") {
@@ -190,7 +195,7 @@ func TestFeatureService_Set_UpdatesCacheDirectly(t *testing.T) {
 // =============================================================================
 
 func TestFeatureService_IsEnabled_ConcurrentReads(t *testing.T) {
-	svc := NewFeatureService(nil, time.Minute)
+	svc := NewFeatureService(nil, time.Minute, nil)
 	seedCache(svc, map[string]bool{
 		"flag_a": true,
 		"flag_b": false,
@@ -298,7 +303,7 @@ func TestFeatureFlag_JSONRoundtrip(t *testing.T) {
 		t.Fatalf("Unmarshal failed: %v", err)
 	}
 
-	if decoded != original {
+	if !reflect.DeepEqual(decoded, original) {
 		t.Errorf("roundtrip mismatch: got %+v, want %+v", decoded, original)
 	}
 }
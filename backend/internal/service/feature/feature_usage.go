@@ -0,0 +1,71 @@
+package feature
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+)
+
+// usageCounter accumulates evaluations of one flag between flushes.
+type usageCounter struct {
+	count atomic.Int64
+	last  atomic.Int64 // unix nanoseconds
+}
+
+// countEvaluation records one evaluation of key. It is on the hot path of
+// every IsEnabled call, so it only touches atomics.
+func (s *FeatureService) countEvaluation(key string) {
+	c, ok := s.usage.Load(key)
+	if !ok {
+		c, _ = s.usage.LoadOrStore(key, &usageCounter{})
+	}
+	counter := c.(*usageCounter)
+	counter.count.Add(1)
+	counter.last.Store(time.Now().UnixNano())
+}
+
+// FlushUsage adds the evaluations counted since the last flush to
+// feature_flag_usage and returns how many flags it updated. Counts are
+// kept in memory (and retried next time) if the write fails. Only
+// single-flag evaluations (IsEnabled, IsEnabledFor, Evaluate, and the typed
+// getters) are counted; bulk listings such as ListEnabled are not, since
+// they would mark every flag as used on each page load.
+func (s *FeatureService) FlushUsage(ctx context.Context) (int, error) {
+	var keys []string
+	var counts []int64
+	var lasts []time.Time
+	var counters []*usageCounter
+	s.usage.Range(func(k, v any) bool {
+		c := v.(*usageCounter)
+		n := c.count.Swap(0)
+		if n == 0 {
+			return true
+		}
+		keys = append(keys, k.(string))
+		counts = append(counts, n)
+		lasts = append(lasts, time.Unix(0, c.last.Load()))
+		counters = append(counters, c)
+		return true
+	})
+	if len(keys) == 0 {
+		return 0, nil
+	}
+
+	_, err := s.pool.Exec(ctx,
+		`INSERT INTO feature_flag_usage (flag_key, evaluations, last_evaluated_at)
+		 SELECT * FROM unnest($1::text[], $2::bigint[], $3::timestamptz[])
+		 ON CONFLICT (flag_key) DO UPDATE
+		 SET evaluations = feature_flag_usage.evaluations + EXCLUDED.evaluations,
+		     last_evaluated_at = GREATEST(feature_flag_usage.last_evaluated_at, EXCLUDED.last_evaluated_at)`,
+		keys, counts, lasts)
+	if err != nil {
+		for i, c := range counters {
+			c.count.Add(counts[i])
+		}
+		return 0, apperror.Internal(fmt.Errorf("flush feature flag usage: %w", err))
+	}
+	return len(keys), nil
+}
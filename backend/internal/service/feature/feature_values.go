@@ -0,0 +1,252 @@
+package feature
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+)
+
+// ValueType is what a flag serves when it is on for a subject.
+type ValueType string
+
+const (
+	// TypeBoolean flags are plain on/off toggles and carry no value.
+	TypeBoolean ValueType = "boolean"
+	TypeString  ValueType = "string"
+	TypeNumber  ValueType = "number"
+	TypeJSON    ValueType = "json"
+)
+
+// Variant is one arm of a multivariate flag. Weights are percentages and
+// must add up to 100 across a flag's variants.
+type Variant struct {
+	Key    string          `json:"key"`
+	Value  json.RawMessage `json:"value"`
+	Weight int             `json:"weight"`
+}
+
+// Values configures what a non-boolean flag serves. Subjects the flag is
+// on for get Value, or, when Variants are set, the variant their user ID
+// hashes into; anonymous subjects always get Value. Schema, when set, is a
+// JSON Schema (see schema for the supported subset) that Value and every
+// variant must satisfy.
+type Values struct {
+	Type     ValueType       `json:"type"`
+	Value    json.RawMessage `json:"value,omitempty"`
+	Variants []Variant       `json:"variants,omitempty"`
+	Schema   json.RawMessage `json:"schema,omitempty"`
+}
+
+// Evaluation is a flag resolved for one subject. Variant and Value are
+// empty for boolean flags and whenever Enabled is false.
+type Evaluation struct {
+	Enabled bool            `json:"enabled"`
+	Variant string          `json:"variant,omitempty"`
+	Value   json.RawMessage `json:"value,omitempty"`
+}
+
+// Validate reports the first problem with the configuration, or nil.
+func (v Values) Validate() error {
+	switch v.Type {
+	case TypeBoolean:
+		if v.Value != nil || v.Variants != nil || v.Schema != nil {
+			return fmt.Errorf("boolean flags take no value, variants, or schema")
+		}
+		return nil
+	case TypeString, TypeNumber, TypeJSON:
+	default:
+		return fmt.Errorf("type must be one of boolean, string, number, json")
+	}
+
+	var sch *schema
+	if v.Schema != nil {
+		var err error
+		if sch, err = parseSchema(v.Schema); err != nil {
+			return err
+		}
+	}
+	if v.Value == nil {
+		return fmt.Errorf("value is required")
+	}
+	if err := v.Type.check(v.Value, sch, "value"); err != nil {
+		return err
+	}
+
+	if len(v.Variants) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(v.Variants))
+	total := 0
+	for i, variant := range v.Variants {
+		path := fmt.Sprintf("variants[%d]", i)
+		if variant.Key == "" {
+			return fmt.Errorf("%s: key is required", path)
+		}
+		if seen[variant.Key] {
+			return fmt.Errorf("%s: duplicate key %q", path, variant.Key)
+		}
+		seen[variant.Key] = true
+		if variant.Weight < 0 || variant.Weight > 100 {
+			return fmt.Errorf("%s: weight must be between 0 and 100", path)
+		}
+		total += variant.Weight
+		if err := v.Type.check(variant.Value, sch, path+".value"); err != nil {
+			return err
+		}
+	}
+	if total != 100 {
+		return fmt.Errorf("variant weights must add up to 100, got %d", total)
+	}
+	return nil
+}
+
+// check reports whether raw is a JSON value of type t that satisfies sch.
+func (t ValueType) check(raw json.RawMessage, sch *schema, path string) error {
+	var v any
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return fmt.Errorf("%s: invalid JSON", path)
+	}
+	switch t {
+	case TypeString:
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("%s: must be a string", path)
+		}
+	case TypeNumber:
+		if _, ok := v.(float64); !ok {
+			return fmt.Errorf("%s: must be a number", path)
+		}
+	case TypeJSON:
+		if v == nil {
+			return fmt.Errorf("%s: must not be null", path)
+		}
+	}
+	if sch != nil {
+		return sch.validate(v, path)
+	}
+	return nil
+}
+
+// valueType treats states recorded before flags had types as boolean.
+func (f FlagState) valueType() ValueType {
+	if f.Type == "" {
+		return TypeBoolean
+	}
+	return f.Type
+}
+
+// resolve evaluates the flag for the subject, picking a variant when the
+// flag has them.
+func (f FlagState) resolve(key string, s Subject) Evaluation {
+	if !f.evaluate(key, s) {
+		return Evaluation{}
+	}
+	e := Evaluation{Enabled: true, Value: f.Value}
+	if len(f.Variants) > 0 && s.UserID != "" {
+		v := pickVariant(key, s.UserID, f.Variants)
+		e.Variant, e.Value = v.Key, v.Value
+	}
+	return e
+}
+
+// pickVariant walks the cumulative weights with a bucket salted apart from
+// the percentage rollout, so which users are in a rollout and which arm
+// they see are independent. A user keeps their variant as long as the
+// weights do not change.
+func pickVariant(key, userID string, variants []Variant) Variant {
+	b := bucket(key+"\x00variant", userID)
+	for _, v := range variants {
+		if b < v.Weight {
+			return v
+		}
+		b -= v.Weight
+	}
+	return variants[len(variants)-1]
+}
+
+// Evaluate resolves a flag for the subject. Unknown keys evaluate as off.
+func (s *FeatureService) Evaluate(ctx context.Context, key string, subject Subject) Evaluation {
+	return s.lookup(ctx, key).resolve(key, subject)
+}
+
+// GetString returns a string flag's value for the subject, or fallback if
+// the flag is unknown, off for the subject, or not a string flag.
+func (s *FeatureService) GetString(ctx context.Context, key string, subject Subject, fallback string) string {
+	var v string
+	if !s.decode(ctx, key, subject, TypeString, &v) {
+		return fallback
+	}
+	return v
+}
+
+// GetInt returns a number flag's value for the subject, or fallback if the
+// flag is unknown, off for the subject, not a number flag, or not a whole
+// number.
+func (s *FeatureService) GetInt(ctx context.Context, key string, subject Subject, fallback int) int {
+	var v int
+	if !s.decode(ctx, key, subject, TypeNumber, &v) {
+		return fallback
+	}
+	return v
+}
+
+// GetFloat returns a number flag's value for the subject, or fallback if
+// the flag is unknown, off for the subject, or not a number flag.
+func (s *FeatureService) GetFloat(ctx context.Context, key string, subject Subject, fallback float64) float64 {
+	var v float64
+	if !s.decode(ctx, key, subject, TypeNumber, &v) {
+		return fallback
+	}
+	return v
+}
+
+// GetJSON unmarshals a json flag's value for the subject into dst and
+// reports whether it did. Returns false if the flag is unknown, off for
+// the subject, not a json flag, or its value does not fit dst.
+func (s *FeatureService) GetJSON(ctx context.Context, key string, subject Subject, dst any) bool {
+	return s.decode(ctx, key, subject, TypeJSON, dst)
+}
+
+func (s *FeatureService) decode(ctx context.Context, key string, subject Subject, want ValueType, dst any) bool {
+	f := s.lookup(ctx, key)
+	if f.valueType() != want {
+		return false
+	}
+	e := f.resolve(key, subject)
+	if !e.Enabled || e.Value == nil {
+		return false
+	}
+	return json.Unmarshal(e.Value, dst) == nil
+}
+
+// EvaluateAll resolves every flag for the subject, including variants and
+// values.
+func (s *FeatureService) EvaluateAll(ctx context.Context, subject Subject) (map[string]Evaluation, error) {
+	flags, err := s.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]Evaluation, len(flags))
+	for key, f := range flags {
+		result[key] = f.resolve(key, subject)
+	}
+	return result, nil
+}
+
+// SetValues replaces a flag's type, value, variants, and schema. Setting
+// TypeBoolean turns it back into a plain toggle. Invalidates the local
+// cache immediately.
+func (s *FeatureService) SetValues(ctx context.Context, key string, values Values, change Change) error {
+	if err := values.Validate(); err != nil {
+		return apperror.Validation().WithDetail("values", "error.feature.invalid_values", err.Error())
+	}
+	return s.apply(ctx, key, actionValues, change, nil, func(old *FlagState) (FlagState, error) {
+		if old == nil {
+			return FlagState{}, apperror.NotFound("Feature flag")
+		}
+		next := *old
+		next.Type, next.Value, next.Variants, next.Schema = values.Type, values.Value, values.Variants, values.Schema
+		return next, nil
+	})
+}
@@ -0,0 +1,202 @@
+package feature
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func raw(s string) json.RawMessage { return json.RawMessage(s) }
+
+func TestValues_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		values  Values
+		wantErr string
+	}{
+		{"boolean", Values{Type: TypeBoolean}, ""},
+		{"boolean with value", Values{Type: TypeBoolean, Value: raw(`true`)}, "boolean flags"},
+		{"unknown type", Values{Type: "date", Value: raw(`"x"`)}, "type must be"},
+		{"string", Values{Type: TypeString, Value: raw(`"Buy now"`)}, ""},
+		{"missing value", Values{Type: TypeString}, "value is required"},
+		{"string mismatch", Values{Type: TypeString, Value: raw(`3`)}, "value: must be a string"},
+		{"number", Values{Type: TypeNumber, Value: raw(`2.5`)}, ""},
+		{"number mismatch", Values{Type: TypeNumber, Value: raw(`"3"`)}, "value: must be a number"},
+		{"json", Values{Type: TypeJSON, Value: raw(`{"limit":10}`)}, ""},
+		{"json null", Values{Type: TypeJSON, Value: raw(`null`)}, "must not be null"},
+		{"variants", Values{Type: TypeString, Value: raw(`"a"`), Variants: []Variant{
+			{Key: "a", Value: raw(`"a"`), Weight: 30}, {Key: "b", Value: raw(`"b"`), Weight: 70},
+		}}, ""},
+		{"weights under 100", Values{Type: TypeString, Value: raw(`"a"`), Variants: []Variant{
+			{Key: "a", Value: raw(`"a"`), Weight: 30}, {Key: "b", Value: raw(`"b"`), Weight: 60},
+		}}, "add up to 100"},
+		{"negative weight", Values{Type: TypeString, Value: raw(`"a"`), Variants: []Variant{
+			{Key: "a", Value: raw(`"a"`), Weight: 110}, {Key: "b", Value: raw(`"b"`), Weight: -10},
+		}}, "variants[0]: weight"},
+		{"duplicate key", Values{Type: TypeString, Value: raw(`"a"`), Variants: []Variant{
+			{Key: "a", Value: raw(`"a"`), Weight: 50}, {Key: "a", Value: raw(`"b"`), Weight: 50},
+		}}, "duplicate key"},
+		{"variant type mismatch", Values{Type: TypeNumber, Value: raw(`1`), Variants: []Variant{
+			{Key: "a", Value: raw(`1`), Weight: 50}, {Key: "b", Value: raw(`"2"`), Weight: 50},
+		}}, "variants[1].value: must be a number"},
+		{"schema", Values{Type: TypeNumber, Value: raw(`5`), Schema: raw(`{"type":"integer","minimum":1,"maximum":10}`)}, ""},
+		{"schema violated", Values{Type: TypeNumber, Value: raw(`50`), Schema: raw(`{"type":"integer","maximum":10}`)}, "value: must be at most 10"},
+		{"schema violated by variant", Values{Type: TypeNumber, Value: raw(`5`), Schema: raw(`{"type":"integer"}`), Variants: []Variant{
+			{Key: "a", Value: raw(`5`), Weight: 50}, {Key: "b", Value: raw(`5.5`), Weight: 50},
+		}}, "variants[1].value: must be of type integer"},
+		{"schema typo", Values{Type: TypeNumber, Value: raw(`5`), Schema: raw(`{"maximun":10}`)}, "invalid schema"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.values.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSchema_Validate(t *testing.T) {
+	sch, err := parseSchema(raw(`{
+		"type": "object",
+		"required": ["limit"],
+		"additionalProperties": false,
+		"properties": {
+			"limit": {"type": "integer", "minimum": 1},
+			"mode": {"enum": ["fast", "safe"]},
+			"tags": {"type": "array", "maxItems": 2, "items": {"type": "string", "minLength": 1}}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("parseSchema() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr string
+	}{
+		{"valid", `{"limit":3,"mode":"fast","tags":["a"]}`, ""},
+		{"missing required", `{"mode":"fast"}`, "value.limit: is required"},
+		{"below minimum", `{"limit":0}`, "value.limit: must be at least 1"},
+		{"not in enum", `{"limit":1,"mode":"slow"}`, "value.mode: must be one of"},
+		{"extra property", `{"limit":1,"debug":true}`, "value.debug: is not allowed"},
+		{"too many items", `{"limit":1,"tags":["a","b","c"]}`, "value.tags: must have at most 2"},
+		{"bad item", `{"limit":1,"tags":[""]}`, "value.tags[0]: must be at least 1"},
+		{"wrong type", `[1]`, "value: must be of type object"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var v any
+			if err := json.Unmarshal(raw(tt.value), &v); err != nil {
+				t.Fatal(err)
+			}
+			err := sch.validate(v, "value")
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("validate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := parseSchema(raw(`{"type":"date"}`)); err == nil {
+		t.Error("parseSchema() accepted an unknown type")
+	}
+}
+
+func TestPickVariant_StickyAndWeighted(t *testing.T) {
+	variants := []Variant{
+		{Key: "control", Weight: 80},
+		{Key: "treatment", Weight: 20},
+		{Key: "never", Weight: 0},
+	}
+	counts := map[string]int{}
+	for i := range 10000 {
+		user := fmt.Sprintf("user-%d", i)
+		v := pickVariant("pricing", user, variants)
+		if again := pickVariant("pricing", user, variants); again.Key != v.Key {
+			t.Fatalf("%s moved from %s to %s", user, v.Key, again.Key)
+		}
+		counts[v.Key]++
+	}
+	if counts["never"] != 0 {
+		t.Errorf("zero-weight variant served %d times", counts["never"])
+	}
+	// 20% of 10k is 2000; allow generous slack for hash variance.
+	if counts["treatment"] < 1800 || counts["treatment"] > 2200 {
+		t.Errorf("treatment served %d of 10000", counts["treatment"])
+	}
+}
+
+func TestFeatureService_TypedGetters(t *testing.T) {
+	svc := NewFeatureService(nil, time.Minute, nil)
+	svc.cacheMu.Lock()
+	svc.cache = map[string]FlagState{
+		"headline":   {Enabled: true, Type: TypeString, Value: raw(`"Hello"`)},
+		"page_size":  {Enabled: true, Type: TypeNumber, Value: raw(`25`)},
+		"ratio":      {Enabled: true, Type: TypeNumber, Value: raw(`0.75`)},
+		"limits":     {Enabled: true, Type: TypeJSON, Value: raw(`{"max":3}`)},
+		"off_string": {Enabled: false, Type: TypeString, Value: raw(`"Hidden"`)},
+		"admins": {Enabled: true, Type: TypeString, Value: raw(`"default"`),
+			Rules: &Rules{UserTypes: []string{"admin"}}},
+		"arms": {Enabled: true, Type: TypeString, Value: raw(`"control"`), Variants: []Variant{
+			{Key: "only", Value: raw(`"treatment"`), Weight: 100},
+		}},
+		"toggle": {Enabled: true},
+	}
+	svc.cacheAt = time.Now()
+	svc.cacheMu.Unlock()
+
+	ctx := context.Background()
+	user := Subject{UserID: "u-1", UserType: "user"}
+
+	if got := svc.GetString(ctx, "headline", user, "fallback"); got != "Hello" {
+		t.Errorf("GetString(headline) = %q", got)
+	}
+	if got := svc.GetInt(ctx, "page_size", user, 10); got != 25 {
+		t.Errorf("GetInt(page_size) = %d", got)
+	}
+	if got := svc.GetInt(ctx, "ratio", user, 10); got != 10 {
+		t.Errorf("GetInt(ratio) = %d, want fallback for a fractional value", got)
+	}
+	if got := svc.GetFloat(ctx, "ratio", user, 0); got != 0.75 {
+		t.Errorf("GetFloat(ratio) = %v", got)
+	}
+	var limits struct{ Max int }
+	if !svc.GetJSON(ctx, "limits", user, &limits) || limits.Max != 3 {
+		t.Errorf("GetJSON(limits) = %+v", limits)
+	}
+
+	for _, key := range []string{"off_string", "admins", "missing", "page_size", "toggle"} {
+		if got := svc.GetString(ctx, key, user, "fallback"); got != "fallback" {
+			t.Errorf("GetString(%s) = %q, want fallback", key, got)
+		}
+	}
+
+	if got := svc.GetString(ctx, "arms", user, ""); got != "treatment" {
+		t.Errorf("GetString(arms) = %q, want the variant", got)
+	}
+	if got := svc.GetString(ctx, "arms", Subject{}, ""); got != "control" {
+		t.Errorf("GetString(arms) = %q, want the default for anonymous", got)
+	}
+	if e := svc.Evaluate(ctx, "arms", user); e.Variant != "only" || !e.Enabled {
+		t.Errorf("Evaluate(arms) = %+v", e)
+	}
+	if e := svc.Evaluate(ctx, "toggle", user); !e.Enabled || e.Value != nil {
+		t.Errorf("Evaluate(toggle) = %+v", e)
+	}
+}
@@ -0,0 +1,412 @@
+// Package file records uploads in the files table and brokers access to
+// their bytes in blob storage. Clients never stream through a JWT-authed
+// endpoint: they ask for a signed URL, then PUT or GET the content
+// directly against it.
+package file
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/logger"
+	"github.com/golid-ai/golid/backend/internal/storage"
+	"github.com/golid-ai/golid/backend/internal/validate"
+)
+
+// File status values (file_status enum).
+const (
+	StatusPending  = "pending"
+	StatusUploaded = "uploaded"
+)
+
+// maxFilenameLen bounds the client-supplied display name.
+const maxFilenameLen = 255
+
+// Config controls upload limits and signed URL generation.
+type Config struct {
+	URLTTL       time.Duration // lifetime of signed URLs (default 15m)
+	MaxSize      int64         // maximum upload size in bytes
+	AllowedTypes []string      // sniffed media types accepted ("image/*" wildcards allowed)
+	BaseURL      string        // prefix for signed URLs; empty = relative paths
+}
+
+// FileService manages file metadata and signed access to stored objects.
+type FileService struct {
+	pool   *pgxpool.Pool
+	blob   storage.Blob
+	signer *storage.URLSigner
+	cfg    Config
+}
+
+// NewFileService creates a new file service.
+func NewFileService(pool *pgxpool.Pool, blob storage.Blob, signer *storage.URLSigner, cfg Config) *FileService {
+	if cfg.URLTTL == 0 {
+		cfg.URLTTL = 15 * time.Minute
+	}
+	cfg.BaseURL = strings.TrimSuffix(cfg.BaseURL, "/")
+	return &FileService{pool: pool, blob: blob, signer: signer, cfg: cfg}
+}
+
+// File is a row in the files table. ContentType, SizeBytes and
+// ChecksumSHA256 are set once the upload completes.
+type File struct {
+	ID             string     `json:"id"`
+	OwnerID        string     `json:"owner_id"`
+	Filename       string     `json:"filename"`
+	ContentType    *string    `json:"content_type"`
+	SizeBytes      *int64     `json:"size_bytes"`
+	ChecksumSHA256 *string    `json:"checksum_sha256"`
+	Status         string     `json:"status"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UploadedAt     *time.Time `json:"uploaded_at"`
+	StorageKey     string     `json:"-"`
+}
+
+// UploadTicket is returned by CreateUpload: the pending file plus a signed
+// URL the client PUTs the raw body to.
+type UploadTicket struct {
+	File      *File     `json:"file"`
+	UploadURL string    `json:"upload_url"`
+	ExpiresAt time.Time `json:"expires_at"`
+	MaxSize   int64     `json:"max_size"`
+}
+
+// SignedURL is a time-limited link to a file's content.
+type SignedURL struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+const fileColumns = `id, owner_id, filename, content_type, size_bytes, checksum_sha256,
+	status::text, created_at, uploaded_at, storage_key`
+
+func scanFile(row pgx.Row, f *File) error {
+	return row.Scan(&f.ID, &f.OwnerID, &f.Filename, &f.ContentType, &f.SizeBytes,
+		&f.ChecksumSHA256, &f.Status, &f.CreatedAt, &f.UploadedAt, &f.StorageKey)
+}
+
+// CreateUpload registers a pending file owned by ownerID and returns a
+// signed upload URL for it.
+func (s *FileService) CreateUpload(ctx context.Context, ownerID, filename string) (*UploadTicket, error) {
+	name, err := sanitizeFilename(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.New().String()
+	key := "uploads/" + ownerID + "/" + id
+
+	var f File
+	err = scanFile(s.pool.QueryRow(ctx,
+		`INSERT INTO files (id, owner_id, storage_key, filename)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING `+fileColumns,
+		id, ownerID, key, name,
+	), &f)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("insert file: %w", err))
+	}
+
+	signed := s.signURL(storage.OpUpload, f.ID)
+	return &UploadTicket{
+		File:      &f,
+		UploadURL: signed.URL,
+		ExpiresAt: signed.ExpiresAt,
+		MaxSize:   s.cfg.MaxSize,
+	}, nil
+}
+
+// Upload stores body as the content of a pending file. The body is spooled
+// to a temp file so its size, SHA-256 and sniffed content type are known
+// before anything is written to the backend; the client's Content-Type
+// header is never trusted. The row stays locked while the object is
+// written, so concurrent uploads to the same URL cannot interleave.
+func (s *FileService) Upload(ctx context.Context, fileID string, body io.Reader) (*File, error) {
+	f, err := s.get(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if f.Status != StatusPending {
+		return nil, apperror.Conflict("File has already been uploaded").WithMessageID("error.file.already_uploaded")
+	}
+
+	spool, err := spoolUpload(body, s.cfg.MaxSize)
+	if err != nil {
+		return nil, err
+	}
+	defer spool.Close()
+
+	if !storage.TypeAllowed(spool.contentType, s.cfg.AllowedTypes) {
+		return nil, apperror.Validation().WithDetail("content_type", "error.file.type_not_allowed", spool.contentType)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("begin tx: %w", err))
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status::text FROM files WHERE id = $1 FOR UPDATE`, fileID).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperror.NotFound("File")
+	}
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("lock file: %w", err))
+	}
+	if status != StatusPending {
+		return nil, apperror.Conflict("File has already been uploaded").WithMessageID("error.file.already_uploaded")
+	}
+
+	if _, err := spool.file.Seek(0, io.SeekStart); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("rewind upload: %w", err))
+	}
+	if err := s.blob.Put(ctx, f.StorageKey, spool.file, spool.size, spool.contentType); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("store upload: %w", err))
+	}
+
+	err = scanFile(tx.QueryRow(ctx,
+		`UPDATE files
+		 SET content_type = $2, size_bytes = $3, checksum_sha256 = $4,
+		     status = 'uploaded', uploaded_at = NOW()
+		 WHERE id = $1
+		 RETURNING `+fileColumns,
+		fileID, spool.contentType, spool.size, spool.checksum,
+	), f)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("mark file uploaded: %w", err))
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("commit tx: %w", err))
+	}
+	return f, nil
+}
+
+// GetOwned returns a file's metadata. Files owned by someone else read as
+// not found unless isAdmin is set, so IDs cannot be probed.
+func (s *FileService) GetOwned(ctx context.Context, fileID, userID string, isAdmin bool) (*File, error) {
+	f, err := s.get(ctx, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if f.OwnerID != userID && !isAdmin {
+		return nil, apperror.NotFound("File")
+	}
+	return f, nil
+}
+
+// DownloadURL returns a signed download link for an uploaded file.
+func (s *FileService) DownloadURL(f *File) (*SignedURL, error) {
+	if f.Status != StatusUploaded {
+		return nil, apperror.Conflict("File has not been uploaded yet").WithMessageID("error.file.not_uploaded")
+	}
+	return s.signURL(storage.OpDownload, f.ID), nil
+}
+
+// Open returns an uploaded file's metadata and a reader over its content.
+// The caller must close the reader.
+func (s *FileService) Open(ctx context.Context, fileID string) (*File, io.ReadCloser, error) {
+	f, err := s.get(ctx, fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if f.Status != StatusUploaded {
+		return nil, nil, apperror.NotFound("File")
+	}
+	rc, _, err := s.blob.Get(ctx, f.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, apperror.NotFound("File")
+	}
+	if err != nil {
+		return nil, nil, apperror.Internal(fmt.Errorf("open file: %w", err))
+	}
+	return f, rc, nil
+}
+
+// Delete removes a file's row and its stored object. Only the owner or an
+// admin may delete.
+func (s *FileService) Delete(ctx context.Context, fileID, userID string, isAdmin bool) error {
+	f, err := s.GetOwned(ctx, fileID, userID, isAdmin)
+	if err != nil {
+		return err
+	}
+	if _, err := s.pool.Exec(ctx, `DELETE FROM files WHERE id = $1`, f.ID); err != nil {
+		return apperror.Internal(fmt.Errorf("delete file: %w", err))
+	}
+	// The row is the source of truth; a leftover object is unreachable.
+	if err := s.blob.Delete(ctx, f.StorageKey); err != nil {
+		logger.Warn("failed to delete stored object",
+			slog.String("file_id", f.ID),
+			slog.String("error", err.Error()),
+		)
+	}
+	return nil
+}
+
+// VerifyURL checks the signature on an upload or download URL.
+func (s *FileService) VerifyURL(op, fileID, expires, signature string) error {
+	if err := s.signer.Verify(op, fileID, expires, signature); err != nil {
+		return apperror.Forbidden("Invalid or expired link").WithMessageID("error.link_expired")
+	}
+	return nil
+}
+
+// CleanupPendingUploads deletes pending files older than olderThan along
+// with any partially stored objects. Returns the number of rows removed.
+func (s *FileService) CleanupPendingUploads(ctx context.Context, olderThan time.Duration) (int, error) {
+	rows, err := s.pool.Query(ctx,
+		`DELETE FROM files
+		 WHERE status = 'pending' AND created_at < NOW() - make_interval(secs => $1)
+		 RETURNING storage_key`,
+		olderThan.Seconds(),
+	)
+	if err != nil {
+		return 0, apperror.Internal(fmt.Errorf("delete stale uploads: %w", err))
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			return 0, apperror.Internal(fmt.Errorf("scan stale upload: %w", err))
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, apperror.Internal(fmt.Errorf("iterate stale uploads: %w", err))
+	}
+
+	for _, key := range keys {
+		if err := s.blob.Delete(ctx, key); err != nil {
+			logger.Warn("failed to delete stale upload object",
+				slog.String("key", key),
+				slog.String("error", err.Error()),
+			)
+		}
+	}
+	return len(keys), nil
+}
+
+func (s *FileService) get(ctx context.Context, fileID string) (*File, error) {
+	if err := validate.UUID(fileID, "id"); err != nil {
+		return nil, err
+	}
+	var f File
+	err := scanFile(s.pool.QueryRow(ctx,
+		`SELECT `+fileColumns+` FROM files WHERE id = $1`, fileID,
+	), &f)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperror.NotFound("File")
+	}
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("get file: %w", err))
+	}
+	return &f, nil
+}
+
+// signURL builds {BaseURL}/api/v1/files/{id}/content?expires=..&signature=..
+func (s *FileService) signURL(op, fileID string) *SignedURL {
+	expires := time.Now().Add(s.cfg.URLTTL).Truncate(time.Second)
+	q := url.Values{}
+	q.Set("expires", strconv.FormatInt(expires.Unix(), 10))
+	q.Set("signature", s.signer.Sign(op, fileID, expires))
+	return &SignedURL{
+		URL:       s.cfg.BaseURL + "/api/v1/files/" + fileID + "/content?" + q.Encode(),
+		ExpiresAt: expires,
+	}
+}
+
+// sanitizeFilename keeps only the base name and rejects control characters.
+// The name is display-only; storage keys never contain it.
+func sanitizeFilename(name string) (string, error) {
+	name = strings.TrimSpace(path.Base(strings.ReplaceAll(name, `\`, "/")))
+	if name == "" || name == "." || name == "/" {
+		return "", apperror.Validation().WithDetail("filename", "error.file.filename_required")
+	}
+	if len(name) > maxFilenameLen {
+		return "", apperror.Validation().WithDetail("filename", "error.file.filename_too_long", maxFilenameLen)
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return "", apperror.Validation().WithDetail("filename", "error.file.filename_invalid")
+	}
+	return name, nil
+}
+
+// spooledUpload is an upload body buffered to disk.
+type spooledUpload struct {
+	file        *os.File
+	size        int64
+	checksum    string
+	contentType string
+}
+
+// Close removes the temp file.
+func (u *spooledUpload) Close() {
+	_ = u.file.Close()
+	_ = os.Remove(u.file.Name())
+}
+
+// spoolUpload copies body to a temp file, hashing it and sniffing the
+// leading bytes. Bodies that are empty or exceed maxSize are rejected.
+func spoolUpload(body io.Reader, maxSize int64) (*spooledUpload, error) {
+	tmp, err := os.CreateTemp("", "golid-upload-*")
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("create upload spool: %w", err))
+	}
+	u := &spooledUpload{file: tmp}
+
+	h := sha256.New()
+	head := &headBuffer{limit: storage.SniffLength}
+	n, err := io.Copy(io.MultiWriter(tmp, h, head), io.LimitReader(body, maxSize+1))
+	if err != nil {
+		u.Close()
+		return nil, apperror.BadRequest("Failed to read upload body").WithMessageID("error.file.read_failed")
+	}
+	if n == 0 {
+		u.Close()
+		return nil, apperror.BadRequest("Upload body is empty").WithMessageID("error.file.empty")
+	}
+	if n > maxSize {
+		u.Close()
+		return nil, apperror.BadRequest(fmt.Sprintf("File exceeds maximum size of %d bytes", maxSize)).WithMessageID("error.file.too_large", maxSize)
+	}
+
+	u.size = n
+	u.checksum = hex.EncodeToString(h.Sum(nil))
+	u.contentType = storage.DetectContentType(head.buf)
+	return u, nil
+}
+
+// headBuffer keeps the first limit bytes written to it.
+type headBuffer struct {
+	buf   []byte
+	limit int
+}
+
+func (b *headBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - len(b.buf); room > 0 {
+		if len(p) < room {
+			room = len(p)
+		}
+		b.buf = append(b.buf, p[:room]...)
+	}
+	return len(p), nil
+}
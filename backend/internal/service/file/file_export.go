@@ -0,0 +1,38 @@
+package file
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+)
+
+// ExportName implements export.Exporter.
+func (s *FileService) ExportName() string { return "files" }
+
+// ExportUserData implements export.Exporter: metadata for every file the
+// user owns, newest first. File contents stay in storage; each entry's ID
+// can be fetched through the files API.
+func (s *FileService) ExportUserData(ctx context.Context, userID string) (any, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT `+fileColumns+` FROM files WHERE owner_id = $1 ORDER BY created_at DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("export files: %w", err))
+	}
+	defer rows.Close()
+
+	files := []File{}
+	for rows.Next() {
+		var f File
+		if err := scanFile(rows, &f); err != nil {
+			return nil, apperror.Internal(fmt.Errorf("scan file: %w", err))
+		}
+		files = append(files, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("iterate files: %w", err))
+	}
+	return files, nil
+}
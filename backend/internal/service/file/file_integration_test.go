@@ -0,0 +1,164 @@
+//go:build integration
+
+package file
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/service/auth"
+	"github.com/golid-ai/golid/backend/internal/storage"
+	"github.com/golid-ai/golid/backend/internal/testutil"
+)
+
+func newIntegrationService(t *testing.T, pool *pgxpool.Pool) (*FileService, storage.Blob) {
+	t.Helper()
+	blob := storage.NewLocal(t.TempDir())
+	svc := NewFileService(pool, blob, storage.NewURLSigner("file-integration-secret"), Config{
+		URLTTL:       time.Minute,
+		MaxSize:      1 << 20,
+		AllowedTypes: []string{"image/*", "text/plain"},
+	})
+	return svc, blob
+}
+
+func registerFileOwner(t *testing.T, pool *pgxpool.Pool, email string) string {
+	t.Helper()
+	authSvc := auth.NewAuthService(pool, "test-jwt-secret-that-is-at-least-32-characters-long!", "test-issuer", 15*time.Minute, 7*24*time.Hour, time.Hour)
+	result, err := authSvc.Register(context.Background(), &auth.RegisterInput{
+		Email:     email,
+		Password:  "password123",
+		FirstName: "File",
+		LastName:  "Owner",
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	return result.User.ID
+}
+
+func TestFileLifecycle_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		ctx := context.Background()
+		svc, blob := newIntegrationService(t, pool)
+		ownerID := registerFileOwner(t, pool, "files@example.com")
+		otherID := registerFileOwner(t, pool, "other-files@example.com")
+
+		ticket, err := svc.CreateUpload(ctx, ownerID, "notes.txt")
+		if err != nil {
+			t.Fatalf("CreateUpload() error = %v", err)
+		}
+		if ticket.File.Status != StatusPending {
+			t.Errorf("Status = %q, want pending", ticket.File.Status)
+		}
+
+		u, err := url.Parse(ticket.UploadURL)
+		if err != nil {
+			t.Fatalf("parse upload URL: %v", err)
+		}
+		q := u.Query()
+		if err := svc.VerifyURL(storage.OpUpload, ticket.File.ID, q.Get("expires"), q.Get("signature")); err != nil {
+			t.Fatalf("VerifyURL() error = %v", err)
+		}
+
+		f, err := svc.Upload(ctx, ticket.File.ID, bytes.NewReader([]byte("hello, world\n")))
+		if err != nil {
+			t.Fatalf("Upload() error = %v", err)
+		}
+		if f.Status != StatusUploaded || *f.SizeBytes != 13 || *f.ContentType != "text/plain" {
+			t.Errorf("unexpected uploaded file: %+v", f)
+		}
+
+		if _, err := svc.Upload(ctx, ticket.File.ID, bytes.NewReader([]byte("again"))); !apperror.Is(err, apperror.CodeConflict) {
+			t.Errorf("second Upload() error = %v, want Conflict", err)
+		}
+
+		if _, err := svc.GetOwned(ctx, f.ID, otherID, false); !apperror.Is(err, apperror.CodeNotFound) {
+			t.Errorf("GetOwned(other) error = %v, want NotFound", err)
+		}
+		if _, err := svc.GetOwned(ctx, f.ID, otherID, true); err != nil {
+			t.Errorf("GetOwned(admin) error = %v", err)
+		}
+
+		_, rc, err := svc.Open(ctx, f.ID)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		data, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		if string(data) != "hello, world\n" {
+			t.Errorf("content = %q", data)
+		}
+
+		if err := svc.Delete(ctx, f.ID, ownerID, false); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+		if _, err := blob.Stat(ctx, f.StorageKey); err != storage.ErrNotFound {
+			t.Errorf("Stat after delete error = %v, want ErrNotFound", err)
+		}
+	})
+}
+
+func TestUpload_RejectsDisallowedType_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		ctx := context.Background()
+		svc, _ := newIntegrationService(t, pool)
+		ownerID := registerFileOwner(t, pool, "pdf-files@example.com")
+
+		ticket, err := svc.CreateUpload(ctx, ownerID, "image.png")
+		if err != nil {
+			t.Fatalf("CreateUpload() error = %v", err)
+		}
+
+		// Named .png and would be sent as image/png, but sniffs as PDF.
+		_, err = svc.Upload(ctx, ticket.File.ID, bytes.NewReader([]byte("%PDF-1.7\n")))
+		if !apperror.Is(err, apperror.CodeValidation) {
+			t.Fatalf("Upload() error = %v, want Validation", err)
+		}
+
+		f, err := svc.GetOwned(ctx, ticket.File.ID, ownerID, false)
+		if err != nil {
+			t.Fatalf("GetOwned() error = %v", err)
+		}
+		if f.Status != StatusPending {
+			t.Errorf("Status = %q, want pending after rejected upload", f.Status)
+		}
+	})
+}
+
+func TestCleanupPendingUploads_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		ctx := context.Background()
+		svc, _ := newIntegrationService(t, pool)
+		ownerID := registerFileOwner(t, pool, "stale-files@example.com")
+
+		stale, err := svc.CreateUpload(ctx, ownerID, "stale.txt")
+		if err != nil {
+			t.Fatalf("CreateUpload() error = %v", err)
+		}
+		if _, err := svc.CreateUpload(ctx, ownerID, "fresh.txt"); err != nil {
+			t.Fatalf("CreateUpload() error = %v", err)
+		}
+		if _, err := pool.Exec(ctx, `UPDATE files SET created_at = NOW() - INTERVAL '2 days' WHERE id = $1`, stale.File.ID); err != nil {
+			t.Fatalf("backdate file: %v", err)
+		}
+
+		n, err := svc.CleanupPendingUploads(ctx, 24*time.Hour)
+		if err != nil {
+			t.Fatalf("CleanupPendingUploads() error = %v", err)
+		}
+		if n != 1 {
+			t.Errorf("removed = %d, want 1", n)
+		}
+		if _, err := svc.GetOwned(ctx, stale.File.ID, ownerID, false); !apperror.Is(err, apperror.CodeNotFound) {
+			t.Errorf("stale file still present: %v", err)
+		}
+	})
+}
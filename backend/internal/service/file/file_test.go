@@ -0,0 +1,98 @@
+package file
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/storage"
+)
+
+func TestSanitizeFilename(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "report.pdf", want: "report.pdf"},
+		{in: "  spaced.txt  ", want: "spaced.txt"},
+		{in: "../../etc/passwd", want: "passwd"},
+		{in: `C:\Users\me\photo.png`, want: "photo.png"},
+		{in: "", wantErr: true},
+		{in: "/", wantErr: true},
+		{in: "bad\x00name", wantErr: true},
+		{in: strings.Repeat("a", maxFilenameLen+1), wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := sanitizeFilename(tt.in)
+		if tt.wantErr {
+			if !apperror.Is(err, apperror.CodeValidation) {
+				t.Errorf("sanitizeFilename(%q) error = %v, want validation error", tt.in, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("sanitizeFilename(%q) = %q, %v; want %q", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestSpoolUpload(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 1000)...)
+
+	u, err := spoolUpload(bytes.NewReader(png), 2000)
+	if err != nil {
+		t.Fatalf("spoolUpload() error = %v", err)
+	}
+	defer u.Close()
+
+	if u.size != int64(len(png)) {
+		t.Errorf("size = %d, want %d", u.size, len(png))
+	}
+	if u.contentType != "image/png" {
+		t.Errorf("contentType = %q, want image/png", u.contentType)
+	}
+	if len(u.checksum) != 64 {
+		t.Errorf("checksum = %q, want 64 hex chars", u.checksum)
+	}
+}
+
+func TestSpoolUpload_RejectsEmptyAndOversized(t *testing.T) {
+	if _, err := spoolUpload(bytes.NewReader(nil), 10); !apperror.Is(err, apperror.CodeBadRequest) {
+		t.Errorf("empty body error = %v, want BAD_REQUEST", err)
+	}
+	if _, err := spoolUpload(bytes.NewReader(make([]byte, 11)), 10); !apperror.Is(err, apperror.CodeBadRequest) {
+		t.Errorf("oversized body error = %v, want BAD_REQUEST", err)
+	}
+	u, err := spoolUpload(bytes.NewReader(make([]byte, 10)), 10)
+	if err != nil {
+		t.Fatalf("body at limit error = %v", err)
+	}
+	u.Close()
+}
+
+func TestSignURL_VerifiesForOperation(t *testing.T) {
+	signer := storage.NewURLSigner("test-secret")
+	s := NewFileService(nil, nil, signer, Config{URLTTL: time.Minute, BaseURL: "https://api.example.com/"})
+	id := "7f1a6f7e-0b7c-4b53-9a52-6f5b0f0a2d11"
+
+	signed := s.signURL(storage.OpDownload, id)
+	u, err := url.Parse(signed.URL)
+	if err != nil {
+		t.Fatalf("parse signed URL: %v", err)
+	}
+	if u.Host != "api.example.com" || u.Path != "/api/v1/files/"+id+"/content" {
+		t.Errorf("signed URL = %s", signed.URL)
+	}
+
+	q := u.Query()
+	if err := s.VerifyURL(storage.OpDownload, id, q.Get("expires"), q.Get("signature")); err != nil {
+		t.Errorf("VerifyURL(download) error = %v", err)
+	}
+	if err := s.VerifyURL(storage.OpUpload, id, q.Get("expires"), q.Get("signature")); !apperror.Is(err, apperror.CodeForbidden) {
+		t.Errorf("VerifyURL(upload) with download signature error = %v, want FORBIDDEN", err)
+	}
+}
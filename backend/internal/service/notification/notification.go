@@ -0,0 +1,321 @@
+// Package notification persists in-app notifications and pushes them to
+// the user's open SSE connections.
+//
+// A notification is stored before it is pushed, so users who are offline
+// see it on their next GET /me/notifications. Every change to read state
+// pushes the new unread count to all of the user's tabs.
+//
+// Changes for one user are serialized on their notification_counters row,
+// and each bumps its seq. The count pushed with a change is taken after the
+// previous change committed and is tagged with that seq as unread_seq, so a
+// client that receives pushes out of order keeps the count with the highest
+// unread_seq.
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/pagination"
+	"github.com/golid-ai/golid/backend/internal/service/sse"
+	"github.com/golid-ai/golid/backend/internal/validate"
+)
+
+// SSE events pushed to the user's connections.
+const (
+	// EventCreated carries {"notification": Notification, "unread_count": int,
+	// "unread_seq": int}.
+	EventCreated = "notification"
+	// EventRead carries {"ids": [...], "read": bool, "unread_count": int,
+	// "unread_seq": int}, or {"all": true, "read": true, ...} after
+	// mark-all-read.
+	EventRead = "notifications_read"
+)
+
+// maxMarkIDs bounds a single PATCH so the ANY($n) array stays small.
+const maxMarkIDs = 100
+
+var kindPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`)
+
+// Pusher delivers live events. Satisfied by *sse.SSEHub.
+type Pusher interface {
+	Send(userID string, event sse.SSEEvent)
+}
+
+// Notification is one stored notification. Payload is producer-defined
+// JSON; a "message" string is shown as a toast by the frontend.
+type Notification struct {
+	ID        string          `json:"id"`
+	Kind      string          `json:"kind"`
+	Payload   json.RawMessage `json:"payload"`
+	ReadAt    *time.Time      `json:"read_at"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// ListResult is a page of notifications plus the unread total. UnreadSeq
+// is the unread_seq of the last change the count includes.
+type ListResult struct {
+	Notifications []Notification `json:"notifications"`
+	Total         int            `json:"total"`
+	UnreadCount   int            `json:"unread_count"`
+	UnreadSeq     int64          `json:"unread_seq"`
+	Page          int            `json:"page"`
+	PerPage       int            `json:"per_page"`
+	TotalPages    int            `json:"total_pages"`
+}
+
+// NotificationService stores and delivers notifications.
+type NotificationService struct {
+	pool              *pgxpool.Pool
+	pusher            Pusher
+	paginationDefault int
+	paginationMax     int
+}
+
+// NewNotificationService creates a new notification service. pusher may be
+// nil, in which case notifications are only stored.
+func NewNotificationService(pool *pgxpool.Pool, pusher Pusher, paginationDefault, paginationMax int) *NotificationService {
+	return &NotificationService{
+		pool:              pool,
+		pusher:            pusher,
+		paginationDefault: paginationDefault,
+		paginationMax:     paginationMax,
+	}
+}
+
+const notificationColumns = `id, kind, payload, read_at, created_at`
+
+func scanNotification(row pgx.Row, n *Notification) error {
+	return row.Scan(&n.ID, &n.Kind, &n.Payload, &n.ReadAt, &n.CreatedAt)
+}
+
+// Notify stores a notification for the user and pushes it to their open
+// connections. kind is a lowercase dot-separated identifier such as
+// "data_export.ready"; payload must marshal to a JSON object.
+func (s *NotificationService) Notify(ctx context.Context, userID, kind string, payload any) (*Notification, error) {
+	if !kindPattern.MatchString(kind) {
+		return nil, fmt.Errorf("notification: invalid kind %q", kind)
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("notification: marshal payload: %w", err)
+	}
+	if len(data) == 0 || data[0] != '{' {
+		return nil, fmt.Errorf("notification: payload for %q must be a JSON object", kind)
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("begin notify: %w", err))
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	seq, err := nextSeq(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	var n Notification
+	err = scanNotification(tx.QueryRow(ctx,
+		`INSERT INTO notifications (user_id, kind, payload) VALUES ($1, $2, $3)
+		 RETURNING `+notificationColumns,
+		userID, kind, data,
+	), &n)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("insert notification: %w", err))
+	}
+	unread, err := unreadCount(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("commit notify: %w", err))
+	}
+
+	s.push(userID, EventCreated, map[string]any{"notification": n, "unread_count": unread, "unread_seq": seq})
+	return &n, nil
+}
+
+// List returns the user's notifications, newest first.
+func (s *NotificationService) List(ctx context.Context, userID string, page, perPage int, unreadOnly bool) (*ListResult, error) {
+	page, perPage = pagination.NormalizePagination(page, perPage, s.paginationDefault, s.paginationMax)
+	offset := (page - 1) * perPage
+
+	where := ` WHERE user_id = $1`
+	if unreadOnly {
+		where += ` AND read_at IS NULL`
+	}
+
+	// One statement, so the seq matches the snapshot the counts come from.
+	var total, unread int
+	var seq int64
+	err := s.pool.QueryRow(ctx,
+		`SELECT COUNT(*), COUNT(*) FILTER (WHERE read_at IS NULL),
+		        COALESCE((SELECT seq FROM notification_counters WHERE user_id = $1), 0)
+		 FROM notifications`+where, userID,
+	).Scan(&total, &unread, &seq)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("count notifications: %w", err))
+	}
+
+	rows, err := s.pool.Query(ctx,
+		`SELECT `+notificationColumns+` FROM notifications`+where+`
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT $2 OFFSET $3`,
+		userID, perPage, offset,
+	)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("list notifications: %w", err))
+	}
+	defer rows.Close()
+
+	items := []Notification{}
+	for rows.Next() {
+		var n Notification
+		if err := scanNotification(rows, &n); err != nil {
+			return nil, apperror.Internal(fmt.Errorf("scan notification: %w", err))
+		}
+		items = append(items, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("iterate notifications: %w", err))
+	}
+
+	return &ListResult{
+		Notifications: items,
+		Total:         total,
+		UnreadCount:   unread,
+		UnreadSeq:     seq,
+		Page:          page,
+		PerPage:       perPage,
+		TotalPages:    (total + perPage - 1) / perPage,
+	}, nil
+}
+
+// UnreadCount returns how many of the user's notifications are unread.
+func (s *NotificationService) UnreadCount(ctx context.Context, userID string) (int, error) {
+	return unreadCount(ctx, s.pool, userID)
+}
+
+// MarkRead sets the read state of the given notifications. IDs that do not
+// exist or belong to another user are ignored. Returns the new unread count.
+func (s *NotificationService) MarkRead(ctx context.Context, userID string, ids []string, read bool) (int, error) {
+	if len(ids) == 0 {
+		return 0, apperror.Validation().WithDetail("ids", "error.notification.ids_required")
+	}
+	if len(ids) > maxMarkIDs {
+		return 0, apperror.Validation().WithDetail("ids", "error.notification.too_many_ids", maxMarkIDs)
+	}
+	for _, id := range ids {
+		if err := validate.UUID(id, "ids"); err != nil {
+			return 0, err
+		}
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, apperror.Internal(fmt.Errorf("begin mark read: %w", err))
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	seq, err := nextSeq(ctx, tx, userID)
+	if err != nil {
+		return 0, err
+	}
+	// Only rows whose state actually changes are touched, so read_at keeps
+	// the time the user first read the notification.
+	query := `UPDATE notifications SET read_at = NOW()
+		 WHERE user_id = $1 AND id = ANY($2::uuid[]) AND read_at IS NULL`
+	if !read {
+		query = `UPDATE notifications SET read_at = NULL
+		 WHERE user_id = $1 AND id = ANY($2::uuid[]) AND read_at IS NOT NULL`
+	}
+	if _, err := tx.Exec(ctx, query, userID, ids); err != nil {
+		return 0, apperror.Internal(fmt.Errorf("mark notifications: %w", err))
+	}
+	unread, err := unreadCount(ctx, tx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, apperror.Internal(fmt.Errorf("commit mark read: %w", err))
+	}
+
+	s.push(userID, EventRead, map[string]any{"ids": ids, "read": read, "unread_count": unread, "unread_seq": seq})
+	return unread, nil
+}
+
+// MarkAllRead marks every unread notification read. Returns the new unread
+// count; a notification arriving concurrently waits for this change, so it
+// is counted by its own push rather than this one.
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID string) (int, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, apperror.Internal(fmt.Errorf("begin mark all read: %w", err))
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	seq, err := nextSeq(ctx, tx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := tx.Exec(ctx,
+		`UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`, userID,
+	); err != nil {
+		return 0, apperror.Internal(fmt.Errorf("mark all notifications: %w", err))
+	}
+	unread, err := unreadCount(ctx, tx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, apperror.Internal(fmt.Errorf("commit mark all read: %w", err))
+	}
+
+	s.push(userID, EventRead, map[string]any{"all": true, "read": true, "unread_count": unread, "unread_seq": seq})
+	return unread, nil
+}
+
+// querier is satisfied by both *pgxpool.Pool and pgx.Tx.
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// nextSeq bumps the user's notification seq. The row stays locked until tx
+// ends, so other changes for the user wait, and an unread count read after
+// this call includes every change with a lower seq.
+func nextSeq(ctx context.Context, q querier, userID string) (int64, error) {
+	var seq int64
+	err := q.QueryRow(ctx,
+		`INSERT INTO notification_counters (user_id, seq) VALUES ($1, 1)
+		 ON CONFLICT (user_id) DO UPDATE SET seq = notification_counters.seq + 1
+		 RETURNING seq`, userID,
+	).Scan(&seq)
+	if err != nil {
+		return 0, apperror.Internal(fmt.Errorf("bump notification seq: %w", err))
+	}
+	return seq, nil
+}
+
+func unreadCount(ctx context.Context, q querier, userID string) (int, error) {
+	var n int
+	err := q.QueryRow(ctx,
+		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID,
+	).Scan(&n)
+	if err != nil {
+		return 0, apperror.Internal(fmt.Errorf("count unread notifications: %w", err))
+	}
+	return n, nil
+}
+
+func (s *NotificationService) push(userID, event string, data map[string]any) {
+	if s.pusher != nil {
+		s.pusher.Send(userID, sse.SSEEvent{Event: event, Data: data})
+	}
+}
@@ -0,0 +1,35 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+)
+
+// ExportName implements export.Exporter.
+func (s *NotificationService) ExportName() string { return "notifications" }
+
+// ExportUserData implements export.Exporter: every notification the user
+// has received, newest first.
+func (s *NotificationService) ExportUserData(ctx context.Context, userID string) (any, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT `+notificationColumns+` FROM notifications
+		 WHERE user_id = $1 ORDER BY created_at DESC, id DESC`, userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("export notifications: %w", err)
+	}
+	defer rows.Close()
+
+	items := []Notification{}
+	for rows.Next() {
+		var n Notification
+		if err := scanNotification(rows, &n); err != nil {
+			return nil, fmt.Errorf("scan notification: %w", err)
+		}
+		items = append(items, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate notifications: %w", err)
+	}
+	return items, nil
+}
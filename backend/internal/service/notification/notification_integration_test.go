@@ -0,0 +1,179 @@
+//go:build integration
+
+package notification
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/golid-ai/golid/backend/internal/service/auth"
+	"github.com/golid-ai/golid/backend/internal/service/sse"
+	"github.com/golid-ai/golid/backend/internal/testutil"
+)
+
+type recordingPusher struct {
+	mu     sync.Mutex
+	events []sse.SSEEvent
+}
+
+func (r *recordingPusher) Send(_ string, event sse.SSEEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recordingPusher) last() sse.SSEEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.events[len(r.events)-1]
+}
+
+func TestNotifications_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		ctx := context.Background()
+		authSvc := auth.NewAuthService(pool, "test-jwt-secret-that-is-at-least-32-characters-long!", "test-issuer", 15*time.Minute, 7*24*time.Hour, time.Hour)
+		result, err := authSvc.Register(ctx, &auth.RegisterInput{
+			Email:     "notify@example.com",
+			Password:  "password123",
+			FirstName: "Notify",
+			LastName:  "User",
+		})
+		if err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+		userID := result.User.ID
+
+		pusher := &recordingPusher{}
+		svc := NewNotificationService(pool, pusher, 2, 100)
+
+		var ids []string
+		for i := range 3 {
+			n, err := svc.Notify(ctx, userID, "demo", map[string]any{"message": "hello", "n": i})
+			if err != nil {
+				t.Fatalf("Notify() error = %v", err)
+			}
+			ids = append(ids, n.ID)
+		}
+		if ev := pusher.last(); ev.Event != EventCreated || ev.Data.(map[string]any)["unread_count"] != 3 {
+			t.Errorf("last pushed event = %+v, want %s with unread_count 3", ev, EventCreated)
+		}
+
+		page, err := svc.List(ctx, userID, 1, 0, false)
+		if err != nil {
+			t.Fatalf("List() error = %v", err)
+		}
+		if page.Total != 3 || page.UnreadCount != 3 || len(page.Notifications) != 2 || page.TotalPages != 2 {
+			t.Errorf("List() = %+v", page)
+		}
+		if page.Notifications[0].ID != ids[2] {
+			t.Errorf("List() should be newest first, got %s", page.Notifications[0].ID)
+		}
+
+		unread, err := svc.MarkRead(ctx, userID, ids[:2], true)
+		if err != nil || unread != 1 {
+			t.Fatalf("MarkRead() = %d, %v; want 1", unread, err)
+		}
+		if ev := pusher.last(); ev.Event != EventRead || ev.Data.(map[string]any)["unread_count"] != 1 {
+			t.Errorf("last pushed event = %+v, want %s with unread_count 1", ev, EventRead)
+		}
+
+		unreadPage, err := svc.List(ctx, userID, 1, 10, true)
+		if err != nil || unreadPage.Total != 1 || unreadPage.Notifications[0].ID != ids[2] {
+			t.Errorf("List(unread) = %+v, %v", unreadPage, err)
+		}
+
+		if unread, err := svc.MarkRead(ctx, userID, ids[:1], false); err != nil || unread != 2 {
+			t.Errorf("MarkRead(unread) = %d, %v; want 2", unread, err)
+		}
+
+		// Another user's ids are ignored.
+		otherUser, err := authSvc.Register(ctx, &auth.RegisterInput{
+			Email:     "notify-other@example.com",
+			Password:  "password123",
+			FirstName: "Other",
+			LastName:  "User",
+		})
+		if err != nil {
+			t.Fatalf("Register(other) error = %v", err)
+		}
+		other := NewNotificationService(pool, nil, 20, 100)
+		if unread, err := other.MarkRead(ctx, otherUser.User.ID, ids, true); err != nil || unread != 0 {
+			t.Errorf("MarkRead(other user) = %d, %v", unread, err)
+		}
+		if n, _ := svc.UnreadCount(ctx, userID); n != 2 {
+			t.Errorf("UnreadCount() = %d, want 2 after another user's MarkRead", n)
+		}
+
+		if unread, err := svc.MarkAllRead(ctx, userID); err != nil || unread != 0 {
+			t.Errorf("MarkAllRead() = %d, %v", unread, err)
+		}
+
+		exported, err := svc.ExportUserData(ctx, userID)
+		if err != nil || len(exported.([]Notification)) != 3 {
+			t.Errorf("ExportUserData() = %v, %v", exported, err)
+		}
+	})
+}
+
+func TestNotifications_ConcurrentUnreadCounts_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		ctx := context.Background()
+		authSvc := auth.NewAuthService(pool, "test-jwt-secret-that-is-at-least-32-characters-long!", "test-issuer", 15*time.Minute, 7*24*time.Hour, time.Hour)
+		result, err := authSvc.Register(ctx, &auth.RegisterInput{
+			Email:     "notify-race@example.com",
+			Password:  "password123",
+			FirstName: "Notify",
+			LastName:  "Race",
+		})
+		if err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+		userID := result.User.ID
+
+		pusher := &recordingPusher{}
+		svc := NewNotificationService(pool, pusher, 20, 100)
+
+		const n = 10
+		var wg sync.WaitGroup
+		for i := range n {
+			wg.Go(func() {
+				if _, err := svc.Notify(ctx, userID, "demo", map[string]any{"n": i}); err != nil {
+					t.Errorf("Notify() error = %v", err)
+				}
+			})
+		}
+		wg.Wait()
+
+		// Each push counts every change before it, whatever order the
+		// pushes went out in, so the highest unread_seq has the final count.
+		seen := map[int64]bool{}
+		var latestSeq int64
+		var latestCount int
+		for _, ev := range pusher.events {
+			data := ev.Data.(map[string]any)
+			seq, count := data["unread_seq"].(int64), data["unread_count"].(int)
+			if seen[seq] {
+				t.Errorf("unread_seq %d pushed twice", seq)
+			}
+			seen[seq] = true
+			if int64(count) != seq {
+				t.Errorf("push with unread_seq %d has unread_count %d", seq, count)
+			}
+			if seq > latestSeq {
+				latestSeq, latestCount = seq, count
+			}
+		}
+		if latestCount != n {
+			t.Errorf("latest unread_count = %d, want %d", latestCount, n)
+		}
+
+		page, err := svc.List(ctx, userID, 1, 1, false)
+		if err != nil || page.UnreadCount != n || page.UnreadSeq != latestSeq {
+			t.Errorf("List() = %+v, %v; want unread_count %d at unread_seq %d", page, err, n, latestSeq)
+		}
+	})
+}
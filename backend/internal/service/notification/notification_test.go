@@ -0,0 +1,50 @@
+package notification
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+)
+
+// A nil pool panics if a test reaches the database.
+func newTestService() *NotificationService {
+	return NewNotificationService(nil, nil, 20, 100)
+}
+
+func TestNotify_RejectsBadInput(t *testing.T) {
+	svc := newTestService()
+	tests := map[string]struct {
+		kind    string
+		payload any
+	}{
+		"uppercase kind": {"Demo", map[string]any{}},
+		"empty kind":     {"", map[string]any{}},
+		"array payload":  {"demo", []string{"a"}},
+		"string payload": {"demo", "hello"},
+		"nil payload":    {"demo", nil},
+		"unmarshalable":  {"demo", map[string]any{"ch": make(chan int)}},
+	}
+	for name, tt := range tests {
+		if _, err := svc.Notify(context.Background(), "user-1", tt.kind, tt.payload); err == nil {
+			t.Errorf("%s: Notify() should fail", name)
+		}
+	}
+}
+
+func TestMarkRead_Validation(t *testing.T) {
+	svc := newTestService()
+	ctx := context.Background()
+
+	if _, err := svc.MarkRead(ctx, "user-1", nil, true); !apperror.Is(err, apperror.CodeValidation) {
+		t.Errorf("no ids: error = %v, want Validation", err)
+	}
+	tooMany := strings.Split(strings.Repeat("x,", maxMarkIDs+1), ",")
+	if _, err := svc.MarkRead(ctx, "user-1", tooMany, true); !apperror.Is(err, apperror.CodeValidation) {
+		t.Errorf("too many ids: error = %v, want Validation", err)
+	}
+	if _, err := svc.MarkRead(ctx, "user-1", []string{"not-a-uuid"}, true); !apperror.Is(err, apperror.CodeBadRequest) {
+		t.Errorf("bad id: error = %v, want BadRequest", err)
+	}
+}
@@ -0,0 +1,101 @@
+// Package outbox delivers the side effects of a database change reliably.
+//
+// A service that must queue a task, push an SSE event, or call a webhook
+// after a change writes the side effect to the outbox table with Enqueue,
+// in the same transaction as the change. If the transaction rolls back,
+// nothing is sent; once it commits, the Relay delivers the message at
+// least once, retrying with backoff, even if the process stops right after
+// the commit. Consumers must therefore tolerate duplicates: tasks are
+// enqueued with the outbox ID as their asynq task ID, and webhooks carry
+// it in the X-Outbox-ID header.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+)
+
+// Message kinds, stored in outbox.kind.
+const (
+	KindTask         = "task"
+	KindSSEUser      = "sse_user"
+	KindSSETopic     = "sse_topic"
+	KindSSEBroadcast = "sse_broadcast"
+	KindWebhook      = "webhook"
+)
+
+// Message is one side effect to deliver after commit. Build it with Task,
+// SendEvent, PublishEvent, BroadcastEvent, or Webhook.
+type Message struct {
+	kind    string
+	name    string // task type, SSE event, or webhook event
+	target  string // user ID, topic, or webhook URL
+	payload []byte
+}
+
+// Task enqueues task with asynq. Only its type and payload are stored;
+// the relay adds the retry limit and task ID when it enqueues.
+func Task(task *asynq.Task) Message {
+	return Message{kind: KindTask, name: task.Type(), payload: task.Payload()}
+}
+
+// SendEvent pushes an SSE event to every connection of one user.
+func SendEvent(userID, event string, data any) (Message, error) {
+	return sseMessage(KindSSEUser, userID, event, data)
+}
+
+// PublishEvent pushes an SSE event to the subscribers of a topic.
+func PublishEvent(topic, event string, data any) (Message, error) {
+	return sseMessage(KindSSETopic, topic, event, data)
+}
+
+// BroadcastEvent pushes an SSE event to every connected client.
+func BroadcastEvent(event string, data any) (Message, error) {
+	return sseMessage(KindSSEBroadcast, "", event, data)
+}
+
+func sseMessage(kind, target, event string, data any) (Message, error) {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return Message{}, fmt.Errorf("marshal %s event: %w", event, err)
+	}
+	return Message{kind: kind, name: event, target: target, payload: payload}, nil
+}
+
+// Webhook POSTs body, as JSON, to an absolute http(s) URL. The event name
+// is sent in the X-Outbox-Event header.
+func Webhook(endpoint, event string, body any) (Message, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return Message{}, fmt.Errorf("webhook URL must be an absolute http(s) URL, got %q", endpoint)
+	}
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return Message{}, fmt.Errorf("marshal %s webhook: %w", event, err)
+	}
+	return Message{kind: KindWebhook, name: event, target: endpoint, payload: payload}, nil
+}
+
+// Enqueue writes msgs to the outbox in tx, the transaction that makes the
+// change they announce. They are delivered only if tx commits.
+func Enqueue(ctx context.Context, tx pgx.Tx, msgs ...Message) error {
+	for _, m := range msgs {
+		if m.kind == "" {
+			return apperror.Internal(fmt.Errorf("enqueue outbox message: zero Message"))
+		}
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO outbox (kind, name, target, payload) VALUES ($1, $2, $3, $4)`,
+			m.kind, m.name, m.target, m.payload,
+		); err != nil {
+			return apperror.Internal(fmt.Errorf("enqueue outbox message: %w", err))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+//go:build integration
+
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/golid-ai/golid/backend/internal/testutil"
+)
+
+func enqueueCommitted(t *testing.T, pool *pgxpool.Pool, msgs ...Message) {
+	t.Helper()
+	ctx := context.Background()
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+	if err := Enqueue(ctx, tx, msgs...); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+}
+
+func TestRelay_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		ctx := context.Background()
+		pusher := &recordingPusher{}
+		var ran []string
+		inline := asynq.HandlerFunc(func(_ context.Context, task *asynq.Task) error {
+			ran = append(ran, task.Type())
+			return nil
+		})
+		relay := NewRelay(pool, &fakeQueue{}, inline, pusher, Config{MaxAttempts: 3})
+
+		// Rolled back: never delivered.
+		tx, err := pool.Begin(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rolledBack, _ := SendEvent("user-1", "rolled_back", nil)
+		if err := Enqueue(ctx, tx, rolledBack); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+		_ = tx.Rollback(ctx)
+
+		sent, _ := SendEvent("user-1", "notification", map[string]int{"unread_count": 1})
+		enqueueCommitted(t, pool, sent, Task(asynq.NewTask("email:verification", []byte(`{}`))))
+
+		n, err := relay.Dispatch(ctx)
+		if err != nil {
+			t.Fatalf("Dispatch() error = %v", err)
+		}
+		if n != 2 {
+			t.Errorf("Dispatch() = %d, want 2", n)
+		}
+		if len(pusher.events) != 1 || pusher.events[0] != "send:user-1:notification" {
+			t.Errorf("events = %v", pusher.events)
+		}
+		if len(ran) != 1 || ran[0] != "email:verification" {
+			t.Errorf("tasks run = %v", ran)
+		}
+
+		// Delivered messages are not delivered again.
+		if n, err := relay.Dispatch(ctx); err != nil || n != 0 {
+			t.Errorf("second Dispatch() = %d, %v; want 0", n, err)
+		}
+
+		// Kept until retention passes.
+		if n, err := relay.Cleanup(ctx, time.Hour); err != nil || n != 0 {
+			t.Errorf("Cleanup(1h) = %d, %v; want 0", n, err)
+		}
+		if n, err := relay.Cleanup(ctx, 0); err != nil || n != 2 {
+			t.Errorf("Cleanup(0) = %d, %v; want 2", n, err)
+		}
+	})
+}
+
+func TestRelay_RetriesThenFails_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		ctx := context.Background()
+		attempts := 0
+		inline := asynq.HandlerFunc(func(context.Context, *asynq.Task) error {
+			attempts++
+			return errors.New("mail provider down")
+		})
+		relay := NewRelay(pool, &fakeQueue{}, inline, &recordingPusher{}, Config{MaxAttempts: 2})
+		enqueueCommitted(t, pool, Task(asynq.NewTask("email:verification", []byte(`{}`))))
+
+		if n, err := relay.Dispatch(ctx); err != nil || n != 0 {
+			t.Fatalf("Dispatch() = %d, %v; want 0", n, err)
+		}
+		var tries int
+		var lastError string
+		var availableAt time.Time
+		if err := pool.QueryRow(ctx, `SELECT attempts, last_error, available_at FROM outbox`).Scan(&tries, &lastError, &availableAt); err != nil {
+			t.Fatal(err)
+		}
+		if tries != 1 || lastError != "mail provider down" || !availableAt.After(time.Now()) {
+			t.Errorf("after one failure: attempts=%d error=%q available_at=%s", tries, lastError, availableAt)
+		}
+
+		// Not due again until the backoff passes.
+		if _, err := relay.Dispatch(ctx); err != nil || attempts != 1 {
+			t.Errorf("retried before backoff: attempts=%d err=%v", attempts, err)
+		}
+
+		if _, err := pool.Exec(ctx, `UPDATE outbox SET available_at = NOW()`); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := relay.Dispatch(ctx); err != nil {
+			t.Fatal(err)
+		}
+		var failed bool
+		if err := pool.QueryRow(ctx, `SELECT failed_at IS NOT NULL FROM outbox`).Scan(&failed); err != nil {
+			t.Fatal(err)
+		}
+		if attempts != 2 || !failed {
+			t.Errorf("expected the message to fail after MaxAttempts: attempts=%d failed=%v", attempts, failed)
+		}
+
+		// Failed messages are not retried, and are cleaned up like delivered ones.
+		if _, err := pool.Exec(ctx, `UPDATE outbox SET available_at = NOW()`); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := relay.Dispatch(ctx); err != nil || attempts != 2 {
+			t.Errorf("failed message retried: attempts=%d err=%v", attempts, err)
+		}
+		if n, err := relay.Cleanup(ctx, 0); err != nil || n != 1 {
+			t.Errorf("Cleanup(0) = %d, %v; want 1", n, err)
+		}
+	})
+}
+
+func TestRelay_ConcurrentDispatchDeliversOnce_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		ctx := context.Background()
+		pusher := &recordingPusher{}
+		for range 20 {
+			msg, _ := BroadcastEvent("tick", nil)
+			enqueueCommitted(t, pool, msg)
+		}
+
+		relays := []*Relay{
+			NewRelay(pool, &fakeQueue{}, nil, pusher, Config{MaxAttempts: 3}),
+			NewRelay(pool, &fakeQueue{}, nil, pusher, Config{MaxAttempts: 3}),
+		}
+		errs := make(chan error, len(relays))
+		for _, r := range relays {
+			go func() {
+				_, err := r.Dispatch(ctx)
+				errs <- err
+			}()
+		}
+		for range relays {
+			if err := <-errs; err != nil {
+				t.Fatalf("Dispatch() error = %v", err)
+			}
+		}
+
+		if len(pusher.events) != 20 {
+			t.Errorf("delivered %d events, want 20", len(pusher.events))
+		}
+	})
+}
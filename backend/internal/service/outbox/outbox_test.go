@@ -0,0 +1,278 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+
+	"github.com/golid-ai/golid/backend/internal/service/sse"
+)
+
+type recordingPusher struct {
+	mu     sync.Mutex
+	events []string // "send:user:event", "publish:topic:event", "broadcast::event"
+	data   []string
+}
+
+func (p *recordingPusher) record(kind, target string, event sse.SSEEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, kind+":"+target+":"+event.Event)
+	b, _ := json.Marshal(event.Data)
+	p.data = append(p.data, string(b))
+}
+
+func (p *recordingPusher) Send(userID string, event sse.SSEEvent) { p.record("send", userID, event) }
+func (p *recordingPusher) Publish(topic string, event sse.SSEEvent) {
+	p.record("publish", topic, event)
+}
+func (p *recordingPusher) Broadcast(event sse.SSEEvent) { p.record("broadcast", "", event) }
+
+type fakeQueue struct {
+	configured bool
+	err        error
+	tasks      []*asynq.Task
+	opts       [][]asynq.Option
+}
+
+func (q *fakeQueue) IsConfigured() bool { return q.configured }
+
+func (q *fakeQueue) Enqueue(task *asynq.Task, opts ...asynq.Option) error {
+	q.tasks = append(q.tasks, task)
+	q.opts = append(q.opts, opts)
+	return q.err
+}
+
+func newTestRelay(q TaskQueue, inline asynq.Handler, pusher Pusher, cfg Config) *Relay {
+	return NewRelay(nil, q, inline, pusher, cfg)
+}
+
+func TestMessageConstructors(t *testing.T) {
+	task := Task(asynq.NewTask("email:verification", []byte(`{"token":"abc"}`), asynq.MaxRetry(3)))
+	if task.kind != KindTask || task.name != "email:verification" || string(task.payload) != `{"token":"abc"}` {
+		t.Errorf("Task() = %+v", task)
+	}
+
+	send, err := SendEvent("user-1", "notification", map[string]int{"unread_count": 2})
+	if err != nil {
+		t.Fatalf("SendEvent() error = %v", err)
+	}
+	if send.kind != KindSSEUser || send.target != "user-1" || string(send.payload) != `{"unread_count":2}` {
+		t.Errorf("SendEvent() = %+v", send)
+	}
+
+	pub, _ := PublishEvent("org:1", "updated", nil)
+	if pub.kind != KindSSETopic || pub.target != "org:1" || string(pub.payload) != "null" {
+		t.Errorf("PublishEvent() = %+v", pub)
+	}
+
+	all, _ := BroadcastEvent("announcement", struct{}{})
+	if all.kind != KindSSEBroadcast || all.target != "" {
+		t.Errorf("BroadcastEvent() = %+v", all)
+	}
+
+	if _, err := SendEvent("user-1", "bad", make(chan int)); err == nil {
+		t.Error("expected an error for data that can't be marshaled")
+	}
+}
+
+func TestWebhook_RequiresAbsoluteHTTPURL(t *testing.T) {
+	for _, endpoint := range []string{"", "/hooks/x", "ftp://example.com/x", "https://"} {
+		if _, err := Webhook(endpoint, "user.created", nil); err == nil {
+			t.Errorf("Webhook(%q) expected error", endpoint)
+		}
+	}
+	m, err := Webhook("https://example.com/hooks", "user.created", map[string]string{"id": "u1"})
+	if err != nil {
+		t.Fatalf("Webhook() error = %v", err)
+	}
+	if m.kind != KindWebhook || m.name != "user.created" || m.target != "https://example.com/hooks" {
+		t.Errorf("Webhook() = %+v", m)
+	}
+}
+
+func TestEnqueue_RejectsZeroMessage(t *testing.T) {
+	// The zero check runs before the tx is used.
+	if err := Enqueue(context.Background(), nil, Message{}); err == nil {
+		t.Error("expected an error for a zero Message")
+	}
+}
+
+func TestDeliver_SSE(t *testing.T) {
+	pusher := &recordingPusher{}
+	r := newTestRelay(&fakeQueue{}, nil, pusher, Config{})
+
+	for _, m := range []stored{
+		{Message: Message{kind: KindSSEUser, name: "notification", target: "user-1", payload: []byte(`{"n":1}`)}},
+		{Message: Message{kind: KindSSETopic, name: "updated", target: "org:1", payload: []byte(`{"n":2}`)}},
+		{Message: Message{kind: KindSSEBroadcast, name: "announcement", payload: []byte(`{"n":3}`)}},
+	} {
+		if err := r.deliver(context.Background(), m); err != nil {
+			t.Fatalf("deliver(%s) error = %v", m.kind, err)
+		}
+	}
+
+	want := []string{"send:user-1:notification", "publish:org:1:updated", "broadcast::announcement"}
+	if strings.Join(pusher.events, ",") != strings.Join(want, ",") {
+		t.Errorf("events = %v, want %v", pusher.events, want)
+	}
+	// The stored JSON is passed through, not re-encoded as a string.
+	if pusher.data[0] != `{"n":1}` {
+		t.Errorf("data = %s", pusher.data[0])
+	}
+}
+
+func TestDeliver_TaskEnqueuedWithOutboxID(t *testing.T) {
+	q := &fakeQueue{configured: true}
+	r := newTestRelay(q, nil, &recordingPusher{}, Config{TaskMaxRetry: 3})
+
+	m := stored{Message: Message{kind: KindTask, name: "email:verification", payload: []byte(`{}`)}, id: "row-1"}
+	if err := r.deliver(context.Background(), m); err != nil {
+		t.Fatalf("deliver() error = %v", err)
+	}
+	if len(q.tasks) != 1 || q.tasks[0].Type() != "email:verification" {
+		t.Fatalf("enqueued = %v", q.tasks)
+	}
+	if len(q.opts[0]) != 1 || q.opts[0][0].Value() != "row-1" {
+		t.Errorf("expected the outbox ID as task ID, got %v", q.opts[0])
+	}
+
+	// An earlier attempt already enqueued it.
+	q.err = asynq.ErrTaskIDConflict
+	if err := r.deliver(context.Background(), m); err != nil {
+		t.Errorf("expected a task ID conflict to count as delivered, got %v", err)
+	}
+
+	q.err = errors.New("redis down")
+	if err := r.deliver(context.Background(), m); err == nil {
+		t.Error("expected enqueue errors to be returned for retry")
+	}
+}
+
+func TestDeliver_TaskRunsInlineWithoutRedis(t *testing.T) {
+	var got string
+	inline := asynq.HandlerFunc(func(_ context.Context, task *asynq.Task) error {
+		got = task.Type() + " " + string(task.Payload())
+		return nil
+	})
+	q := &fakeQueue{}
+	r := newTestRelay(q, inline, &recordingPusher{}, Config{})
+
+	m := stored{Message: Message{kind: KindTask, name: "email:verification", payload: []byte(`{"token":"abc"}`)}}
+	if err := r.deliver(context.Background(), m); err != nil {
+		t.Fatalf("deliver() error = %v", err)
+	}
+	if got != `email:verification {"token":"abc"}` {
+		t.Errorf("inline handler got %q", got)
+	}
+	if len(q.tasks) != 0 {
+		t.Error("nothing should be enqueued without Redis")
+	}
+}
+
+func TestDeliver_UnknownKindSkipsRetry(t *testing.T) {
+	r := newTestRelay(&fakeQueue{}, nil, &recordingPusher{}, Config{})
+	err := r.deliver(context.Background(), stored{Message: Message{kind: "carrier_pigeon"}})
+	if !errors.Is(err, asynq.SkipRetry) {
+		t.Errorf("deliver() error = %v, want SkipRetry", err)
+	}
+}
+
+func TestDeliver_Webhook(t *testing.T) {
+	var gotHeaders http.Header
+	var gotBody []byte
+	status := http.StatusNoContent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeaders = r.Header.Clone()
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	r := newTestRelay(&fakeQueue{}, nil, &recordingPusher{}, Config{WebhookSecret: "hook-secret", WebhookTimeout: time.Second})
+	msg, _ := Webhook(srv.URL, "user.created", map[string]string{"id": "u1"})
+	m := stored{Message: msg, id: "row-1"}
+
+	if err := r.deliver(context.Background(), m); err != nil {
+		t.Fatalf("deliver() error = %v", err)
+	}
+	if string(gotBody) != `{"id":"u1"}` {
+		t.Errorf("body = %s", gotBody)
+	}
+	if gotHeaders.Get("X-Outbox-ID") != "row-1" || gotHeaders.Get("X-Outbox-Event") != "user.created" {
+		t.Errorf("headers = %v", gotHeaders)
+	}
+	if got, want := gotHeaders.Get("X-Outbox-Signature"), "sha256="+Sign("hook-secret", gotBody); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+
+	status = http.StatusInternalServerError
+	if err := r.deliver(context.Background(), m); err == nil {
+		t.Error("expected a non-2xx response to be retried")
+	}
+}
+
+func TestDeliver_WebhookUnsignedWithoutSecret(t *testing.T) {
+	var signature string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get("X-Outbox-Signature")
+	}))
+	defer srv.Close()
+
+	r := newTestRelay(&fakeQueue{}, nil, &recordingPusher{}, Config{WebhookTimeout: time.Second})
+	msg, _ := Webhook(srv.URL, "user.created", nil)
+	if err := r.deliver(context.Background(), stored{Message: msg}); err != nil {
+		t.Fatalf("deliver() error = %v", err)
+	}
+	if signature != "" {
+		t.Errorf("expected no signature, got %q", signature)
+	}
+}
+
+func TestSign(t *testing.T) {
+	// echo -n '{"a":1}' | openssl dgst -sha256 -hmac secret
+	if got := Sign("secret", []byte(`{"a":1}`)); got != "aa9e2e3575f5d7098b6caccd790888c36d5fdb63342a73bada2d6a51747a8494" {
+		t.Errorf("Sign() = %q", got)
+	}
+	if Sign("secret", []byte("a")) == Sign("other", []byte("a")) {
+		t.Error("signatures under different secrets should differ")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 2 * time.Second},
+		{2, 4 * time.Second},
+		{5, 32 * time.Second},
+		{11, maxBackoff},
+		{100, maxBackoff},
+	}
+	for _, tt := range tests {
+		if got := backoff(tt.attempts); got != tt.want {
+			t.Errorf("backoff(%d) = %s, want %s", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestTruncate_KeepsValidUTF8(t *testing.T) {
+	s := strings.Repeat("é", maxErrorLen)
+	got := truncate(s)
+	if len(got) > maxErrorLen || !strings.HasPrefix(s, got) {
+		t.Errorf("truncate() returned %d bytes", len(got))
+	}
+	if got != strings.ToValidUTF8(got, "") {
+		t.Error("truncate() split a rune")
+	}
+}
@@ -0,0 +1,287 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/logger"
+	"github.com/golid-ai/golid/backend/internal/observability"
+	"github.com/golid-ai/golid/backend/internal/service/sse"
+)
+
+const (
+	// dispatchBatch caps the messages delivered per poll. A larger backlog
+	// drains over successive polls, which keeps each Dispatch short enough
+	// for shutdown to wait on it.
+	dispatchBatch = 100
+
+	// maxBackoff caps the delay between attempts at one message.
+	maxBackoff = 30 * time.Minute
+
+	// maxErrorLen bounds the last_error kept for a failing message.
+	maxErrorLen = 1000
+)
+
+// TaskQueue is where task messages go when Redis is configured; the relay
+// passes the outbox ID as the asynq task ID. Satisfied by *queue.Queue.
+type TaskQueue interface {
+	IsConfigured() bool
+	Enqueue(task *asynq.Task, opts ...asynq.Option) error
+}
+
+// Pusher receives the SSE messages the relay claims, with the stored JSON
+// payload as event data. In production this is the *sse.SSEHub, which
+// also reaches other instances when a broker is configured.
+type Pusher interface {
+	Send(userID string, event sse.SSEEvent)
+	Publish(topic string, event sse.SSEEvent)
+	Broadcast(event sse.SSEEvent)
+}
+
+// Config tunes delivery.
+type Config struct {
+	MaxAttempts    int           // attempts before a message is marked failed
+	TaskMaxRetry   int           // asynq retries for enqueued tasks
+	WebhookSecret  string        // HMAC key for X-Outbox-Signature; empty: unsigned
+	WebhookTimeout time.Duration // per webhook request
+}
+
+// Relay delivers outbox messages. Every instance can run it; messages are
+// claimed with SKIP LOCKED so concurrent relays never deliver one at the
+// same time.
+type Relay struct {
+	pool   *pgxpool.Pool
+	tasks  TaskQueue
+	inline asynq.Handler
+	pusher Pusher
+	client *http.Client
+	cfg    Config
+}
+
+// NewRelay creates a relay. Tasks go to tasks when it is configured, and
+// are otherwise run in process by inline, the handler the worker serves.
+func NewRelay(pool *pgxpool.Pool, tasks TaskQueue, inline asynq.Handler, pusher Pusher, cfg Config) *Relay {
+	return &Relay{
+		pool:   pool,
+		tasks:  tasks,
+		inline: inline,
+		pusher: pusher,
+		client: &http.Client{Timeout: cfg.WebhookTimeout},
+		cfg:    cfg,
+	}
+}
+
+// stored is a claimed outbox row.
+type stored struct {
+	Message
+	id       string
+	attempts int
+}
+
+// Dispatch delivers messages that are due and returns how many it
+// delivered. Each message is claimed with FOR UPDATE SKIP LOCKED and
+// marked dispatched in the same transaction, after delivery. A delivery
+// error schedules a retry with exponential backoff until MaxAttempts,
+// after which the message is marked failed; it stays in the table until
+// Cleanup for inspection. Database errors leave the message pending for
+// the next call, which may deliver it again.
+func (r *Relay) Dispatch(ctx context.Context) (int, error) {
+	delivered := 0
+	for range dispatchBatch {
+		claimed, ok, err := r.dispatchNext(ctx)
+		if err != nil {
+			return delivered, err
+		}
+		if !claimed {
+			break
+		}
+		if ok {
+			delivered++
+		}
+	}
+	return delivered, nil
+}
+
+// dispatchNext handles the earliest due message, reporting whether there
+// was one and whether it was delivered. A failed attempt is not an error:
+// the message is rescheduled, and no longer due, so the batch goes on.
+func (r *Relay) dispatchNext(ctx context.Context) (claimed, delivered bool, err error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, false, apperror.Internal(fmt.Errorf("begin tx: %w", err))
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var m stored
+	err = tx.QueryRow(ctx,
+		`SELECT id, kind, name, target, payload, attempts FROM outbox
+		 WHERE dispatched_at IS NULL AND failed_at IS NULL AND available_at <= NOW()
+		 ORDER BY available_at, id
+		 LIMIT 1
+		 FOR UPDATE SKIP LOCKED`,
+	).Scan(&m.id, &m.kind, &m.name, &m.target, &m.payload, &m.attempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, apperror.Internal(fmt.Errorf("claim outbox message: %w", err))
+	}
+
+	attempts := m.attempts + 1
+	deliverErr := r.deliver(ctx, m)
+	permanent := deliverErr != nil && (attempts >= r.cfg.MaxAttempts || errors.Is(deliverErr, asynq.SkipRetry))
+	switch {
+	case deliverErr == nil:
+		_, err = tx.Exec(ctx,
+			`UPDATE outbox SET attempts = $2, last_error = '', dispatched_at = NOW() WHERE id = $1`,
+			m.id, attempts)
+	case permanent:
+		_, err = tx.Exec(ctx,
+			`UPDATE outbox SET attempts = $2, last_error = $3, failed_at = NOW() WHERE id = $1`,
+			m.id, attempts, truncate(deliverErr.Error()))
+	default:
+		_, err = tx.Exec(ctx,
+			`UPDATE outbox SET attempts = $2, last_error = $3,
+			        available_at = NOW() + make_interval(secs => $4)
+			 WHERE id = $1`,
+			m.id, attempts, truncate(deliverErr.Error()), backoff(attempts).Seconds())
+	}
+	if err != nil {
+		return true, false, apperror.Internal(fmt.Errorf("update outbox message: %w", err))
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return true, false, apperror.Internal(fmt.Errorf("commit tx: %w", err))
+	}
+
+	if deliverErr == nil {
+		observability.OutboxMessages.WithLabelValues(m.kind, "dispatched").Inc()
+		return true, true, nil
+	}
+	result := "retried"
+	if permanent {
+		result = "failed"
+		logger.Error("outbox message failed permanently",
+			slog.String("id", m.id),
+			slog.String("kind", m.kind),
+			slog.String("name", m.name),
+			slog.Int("attempts", attempts),
+			slog.String("error", deliverErr.Error()),
+		)
+	}
+	observability.OutboxMessages.WithLabelValues(m.kind, result).Inc()
+	return true, false, nil
+}
+
+// deliver sends one message to its destination.
+func (r *Relay) deliver(ctx context.Context, m stored) error {
+	switch m.kind {
+	case KindTask:
+		task := asynq.NewTask(m.name, m.payload, asynq.MaxRetry(r.cfg.TaskMaxRetry))
+		if !r.tasks.IsConfigured() {
+			return r.inline.ProcessTask(ctx, task)
+		}
+		// A task enqueued by an earlier attempt whose update was lost keeps
+		// its ID in asynq, so the retry is recognized as a duplicate.
+		err := r.tasks.Enqueue(task, asynq.TaskID(m.id))
+		if errors.Is(err, asynq.ErrTaskIDConflict) {
+			return nil
+		}
+		return err
+	case KindSSEUser:
+		r.pusher.Send(m.target, sse.SSEEvent{Event: m.name, Data: json.RawMessage(m.payload)})
+		return nil
+	case KindSSETopic:
+		r.pusher.Publish(m.target, sse.SSEEvent{Event: m.name, Data: json.RawMessage(m.payload)})
+		return nil
+	case KindSSEBroadcast:
+		r.pusher.Broadcast(sse.SSEEvent{Event: m.name, Data: json.RawMessage(m.payload)})
+		return nil
+	case KindWebhook:
+		return r.postWebhook(ctx, m)
+	default:
+		return fmt.Errorf("%w: unknown outbox kind %q", asynq.SkipRetry, m.kind)
+	}
+}
+
+// postWebhook POSTs the payload to the message URL. Any 2xx response is
+// a delivery; anything else is retried.
+func (r *Relay) postWebhook(ctx context.Context, m stored) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.target, bytes.NewReader(m.payload))
+	if err != nil {
+		return fmt.Errorf("%w: build webhook request: %v", asynq.SkipRetry, err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Outbox-ID", m.id)
+	req.Header.Set("X-Outbox-Event", m.name)
+	if r.cfg.WebhookSecret != "" {
+		req.Header.Set("X-Outbox-Signature", "sha256="+Sign(r.cfg.WebhookSecret, m.payload))
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of body under secret, as sent in the
+// X-Outbox-Signature header after "sha256=". Receivers recompute it over
+// the raw request body to check a webhook came from this server.
+func Sign(secret string, body []byte) string {
+	m := hmac.New(sha256.New, []byte(secret))
+	m.Write(body)
+	return hex.EncodeToString(m.Sum(nil))
+}
+
+// Cleanup deletes messages that were delivered or failed more than
+// olderThan ago and returns how many it removed. Payloads can hold
+// secrets such as verification tokens, so retention should stay short.
+func (r *Relay) Cleanup(ctx context.Context, olderThan time.Duration) (int, error) {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM outbox
+		 WHERE dispatched_at < NOW() - make_interval(secs => $1)
+		    OR failed_at < NOW() - make_interval(secs => $1)`,
+		olderThan.Seconds(),
+	)
+	if err != nil {
+		return 0, apperror.Internal(fmt.Errorf("delete old outbox messages: %w", err))
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// backoff is the delay before attempt n+1 after n failed attempts:
+// 2s, 4s, 8s, ... capped at maxBackoff.
+func backoff(attempts int) time.Duration {
+	if attempts >= 20 {
+		return maxBackoff
+	}
+	return min(time.Second<<attempts, maxBackoff)
+}
+
+func truncate(s string) string {
+	if len(s) <= maxErrorLen {
+		return s
+	}
+	return strings.ToValidUTF8(s[:maxErrorLen], "")
+}
@@ -0,0 +1,333 @@
+// Package preference stores per-user settings in a single JSONB document
+// validated against a registry of declared keys.
+//
+// Modules declare their keys by implementing Declarer on their service; the
+// wire package registers every service in wire.Services that does. Stored
+// values that no longer validate (a key was removed or its allowed values
+// narrowed) read back as the declared default.
+package preference
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/i18n"
+)
+
+// Kind is the JSON type of a preference value.
+type Kind string
+
+// Preference kinds.
+const (
+	String Kind = "string"
+	Bool   Kind = "bool"
+	Int    Kind = "int"
+)
+
+// Def declares one preference key.
+type Def struct {
+	Key     string // lowercase, dot-separated: "locale", "notifications.email"
+	Kind    Kind
+	Default any      // must be a valid value of Kind
+	Allowed []string // String only: permitted values; empty allows any
+	Min     *int     // Int only: inclusive bounds
+	Max     *int
+	// Validate runs after the kind and Allowed/Min/Max checks. It returns
+	// the catalog ID of a user-facing message, or "" when v is acceptable.
+	Validate func(v any) string
+}
+
+// Declarer is implemented by services that own preference keys.
+type Declarer interface {
+	PreferenceDefs() []Def
+}
+
+// Well-known keys declared by the users module.
+const (
+	KeyLocale   = "locale"
+	KeyTimezone = "timezone"
+)
+
+// maxStringLen bounds free-form string preferences.
+const maxStringLen = 200
+
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*(\.[a-z][a-z0-9_]*)*$`)
+
+// PreferenceService reads and updates user preferences.
+type PreferenceService struct {
+	pool *pgxpool.Pool
+	defs map[string]Def
+	keys []string // registration order
+}
+
+// NewPreferenceService creates a new preference service.
+func NewPreferenceService(pool *pgxpool.Pool) *PreferenceService {
+	return &PreferenceService{pool: pool, defs: make(map[string]Def)}
+}
+
+// Register declares preference keys. Called during wiring, before any
+// request is served; panics on an invalid or duplicate declaration.
+func (s *PreferenceService) Register(defs ...Def) {
+	for _, d := range defs {
+		if !keyPattern.MatchString(d.Key) {
+			panic(fmt.Sprintf("preference: invalid key %q", d.Key))
+		}
+		if _, dup := s.defs[d.Key]; dup {
+			panic(fmt.Sprintf("preference: duplicate key %q", d.Key))
+		}
+		if p := d.check(d.Default); p.ID != "" {
+			panic(fmt.Sprintf("preference: default for %q: %s", d.Key, i18n.T(i18n.DefaultLocale, p.ID, p.Args...)))
+		}
+		s.defs[d.Key] = d
+		s.keys = append(s.keys, d.Key)
+	}
+}
+
+// Defs returns the registered declarations in registration order.
+func (s *PreferenceService) Defs() []Def {
+	out := make([]Def, len(s.keys))
+	for i, k := range s.keys {
+		out[i] = s.defs[k]
+	}
+	return out
+}
+
+// Get returns every registered preference for the user, with defaults
+// filled in for keys the user has not set.
+func (s *PreferenceService) Get(ctx context.Context, userID string) (map[string]any, error) {
+	stored, err := s.load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.resolve(stored), nil
+}
+
+// Update applies a partial update. A null value resets the key to its
+// default. Unknown keys and invalid values are reported per key through
+// apperror.Validation details; nothing is written unless every key is valid.
+func (s *PreferenceService) Update(ctx context.Context, userID string, patch map[string]json.RawMessage) (map[string]any, error) {
+	if len(patch) == 0 {
+		return nil, apperror.BadRequest("No preferences to update").WithMessageID("error.preference.empty")
+	}
+
+	set := make(map[string]any)
+	var reset []string
+	verr := apperror.Validation()
+	for key, raw := range patch {
+		d, ok := s.defs[key]
+		if !ok {
+			verr.WithDetail(key, "error.preference.unknown")
+			continue
+		}
+		if string(raw) == "null" {
+			reset = append(reset, key)
+			continue
+		}
+		v, p := d.decode(raw)
+		if p.ID != "" {
+			verr.WithDetail(key, p.ID, p.Args...)
+			continue
+		}
+		set[key] = v
+	}
+	if len(verr.Details) > 0 {
+		return nil, verr
+	}
+
+	setJSON, err := json.Marshal(set)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("marshal preferences: %w", err))
+	}
+	if reset == nil {
+		reset = []string{}
+	}
+
+	var stored []byte
+	err = s.pool.QueryRow(ctx,
+		`INSERT INTO user_preferences (user_id, prefs)
+		 VALUES ($1, $2::jsonb - $3::text[])
+		 ON CONFLICT (user_id) DO UPDATE
+		 SET prefs = (user_preferences.prefs || EXCLUDED.prefs) - $3::text[], updated_at = NOW()
+		 RETURNING prefs`,
+		userID, setJSON, reset,
+	).Scan(&stored)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("update preferences: %w", err))
+	}
+
+	values, err := unmarshalStored(stored)
+	if err != nil {
+		return nil, err
+	}
+	return s.resolve(values), nil
+}
+
+// Value returns one preference, typed. The key must be registered with a
+// matching kind; a mismatch is a programming error and returns an error
+// rather than a zero value.
+func Value[T string | bool | int](ctx context.Context, s *PreferenceService, userID, key string) (T, error) {
+	var zero T
+	if _, ok := s.defs[key]; !ok {
+		return zero, fmt.Errorf("preference: %q is not registered", key)
+	}
+	prefs, err := s.Get(ctx, userID)
+	if err != nil {
+		return zero, err
+	}
+	v, ok := prefs[key].(T)
+	if !ok {
+		return zero, fmt.Errorf("preference: %q is %T, not %T", key, prefs[key], zero)
+	}
+	return v, nil
+}
+
+// Regional is a user's locale and time zone. Locale is "" when the user
+// has not chosen one; pass it through i18n.Match before use.
+type Regional struct {
+	Locale   string
+	Location *time.Location
+}
+
+// Regional returns the user's locale and time zone in one read. Intended
+// for services that format user-facing text, such as emails.
+func (s *PreferenceService) Regional(ctx context.Context, userID string) (Regional, error) {
+	prefs, err := s.Get(ctx, userID)
+	if err != nil {
+		return Regional{}, err
+	}
+	r := Regional{Location: time.UTC}
+	if v, ok := prefs[KeyLocale].(string); ok {
+		r.Locale = v
+	}
+	if v, ok := prefs[KeyTimezone].(string); ok {
+		if loc, err := time.LoadLocation(v); err == nil {
+			r.Location = loc
+		}
+	}
+	return r, nil
+}
+
+// Locale returns the user's chosen locale, or "" when the request's
+// Accept-Language should decide. Satisfies middleware.LocaleReader.
+func (s *PreferenceService) Locale(ctx context.Context, userID string) (string, error) {
+	r, err := s.Regional(ctx, userID)
+	return r.Locale, err
+}
+
+func (s *PreferenceService) load(ctx context.Context, userID string) (map[string]json.RawMessage, error) {
+	var stored []byte
+	err := s.pool.QueryRow(ctx,
+		`SELECT prefs FROM user_preferences WHERE user_id = $1`, userID,
+	).Scan(&stored)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("get preferences: %w", err))
+	}
+	return unmarshalStored(stored)
+}
+
+func unmarshalStored(stored []byte) (map[string]json.RawMessage, error) {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(stored, &values); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("decode preferences: %w", err))
+	}
+	return values, nil
+}
+
+// resolve merges stored values over defaults. Stored values for unknown
+// keys are dropped; values that no longer validate fall back to the default.
+func (s *PreferenceService) resolve(stored map[string]json.RawMessage) map[string]any {
+	out := make(map[string]any, len(s.keys))
+	for _, k := range s.keys {
+		d := s.defs[k]
+		out[k] = d.Default
+		if raw, ok := stored[k]; ok {
+			if v, p := d.decode(raw); p.ID == "" {
+				out[k] = v
+			}
+		}
+	}
+	return out
+}
+
+// decode parses and validates a JSON value for d. The returned detail has
+// an empty ID when the value is acceptable.
+func (d Def) decode(raw json.RawMessage) (any, apperror.Detail) {
+	var v any
+	switch d.Kind {
+	case String:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, apperror.Detail{ID: "error.preference.string"}
+		}
+		v = s
+	case Bool:
+		var b bool
+		if err := json.Unmarshal(raw, &b); err != nil {
+			return nil, apperror.Detail{ID: "error.preference.bool"}
+		}
+		v = b
+	case Int:
+		var n int
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return nil, apperror.Detail{ID: "error.preference.int"}
+		}
+		v = n
+	default:
+		return nil, apperror.Detail{ID: "error.preference.kind"}
+	}
+	if p := d.check(v); p.ID != "" {
+		return nil, p
+	}
+	return v, apperror.Detail{}
+}
+
+// check validates a typed value against d.
+func (d Def) check(v any) apperror.Detail {
+	switch d.Kind {
+	case String:
+		s, ok := v.(string)
+		if !ok {
+			return apperror.Detail{ID: "error.preference.string"}
+		}
+		if len(s) > maxStringLen {
+			return apperror.Detail{ID: "error.preference.too_long", Args: []any{maxStringLen}}
+		}
+		if len(d.Allowed) > 0 && !slices.Contains(d.Allowed, s) {
+			return apperror.Detail{ID: "error.preference.one_of", Args: []any{strings.Join(d.Allowed, ", ")}}
+		}
+	case Bool:
+		if _, ok := v.(bool); !ok {
+			return apperror.Detail{ID: "error.preference.bool"}
+		}
+	case Int:
+		n, ok := v.(int)
+		if !ok {
+			return apperror.Detail{ID: "error.preference.int"}
+		}
+		if d.Min != nil && n < *d.Min {
+			return apperror.Detail{ID: "error.preference.min", Args: []any{*d.Min}}
+		}
+		if d.Max != nil && n > *d.Max {
+			return apperror.Detail{ID: "error.preference.max", Args: []any{*d.Max}}
+		}
+	default:
+		return apperror.Detail{ID: "error.preference.kind"}
+	}
+	if d.Validate != nil {
+		return apperror.Detail{ID: d.Validate(v)}
+	}
+	return apperror.Detail{}
+}
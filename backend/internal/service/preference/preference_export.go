@@ -0,0 +1,12 @@
+package preference
+
+import "context"
+
+// ExportName implements export.Exporter.
+func (s *PreferenceService) ExportName() string { return "preferences" }
+
+// ExportUserData implements export.Exporter: every registered preference,
+// with defaults filled in.
+func (s *PreferenceService) ExportUserData(ctx context.Context, userID string) (any, error) {
+	return s.Get(ctx, userID)
+}
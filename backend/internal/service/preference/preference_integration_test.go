@@ -0,0 +1,83 @@
+//go:build integration
+
+package preference
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/golid-ai/golid/backend/internal/service/auth"
+	"github.com/golid-ai/golid/backend/internal/testutil"
+)
+
+func TestPreferences_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		ctx := context.Background()
+		authSvc := auth.NewAuthService(pool, "test-jwt-secret-that-is-at-least-32-characters-long!", "test-issuer", 15*time.Minute, 7*24*time.Hour, time.Hour)
+		result, err := authSvc.Register(ctx, &auth.RegisterInput{
+			Email:     "prefs@example.com",
+			Password:  "password123",
+			FirstName: "Pref",
+			LastName:  "User",
+		})
+		if err != nil {
+			t.Fatalf("Register() error = %v", err)
+		}
+		userID := result.User.ID
+
+		s := newTestService()
+		s.Register(
+			Def{Key: KeyLocale, Kind: String, Default: "en"},
+			Def{Key: KeyTimezone, Kind: String, Default: "UTC"},
+		)
+
+		got, err := s.Get(ctx, userID)
+		if err != nil || got["theme"] != "system" || got["page_size"] != 20 {
+			t.Fatalf("Get() before any update = %v, %v; want defaults", got, err)
+		}
+
+		got, err = s.Update(ctx, userID, map[string]json.RawMessage{
+			"theme":     json.RawMessage(`"dark"`),
+			"page_size": json.RawMessage(`50`),
+			KeyTimezone: json.RawMessage(`"Europe/Paris"`),
+		})
+		if err != nil || got["theme"] != "dark" || got["page_size"] != 50 {
+			t.Fatalf("Update() = %v, %v", got, err)
+		}
+
+		// A second patch merges; null resets to the default.
+		if _, err := s.Update(ctx, userID, map[string]json.RawMessage{
+			"theme":               json.RawMessage(`null`),
+			"notifications.email": json.RawMessage(`false`),
+		}); err != nil {
+			t.Fatalf("Update(merge) error = %v", err)
+		}
+		got, err = s.Get(ctx, userID)
+		if err != nil {
+			t.Fatalf("Get() error = %v", err)
+		}
+		if got["theme"] != "system" || got["page_size"] != 50 || got["notifications.email"] != false {
+			t.Errorf("Get() after merge = %v", got)
+		}
+
+		size, err := Value[int](ctx, s, userID, "page_size")
+		if err != nil || size != 50 {
+			t.Errorf("Value[int](page_size) = %d, %v", size, err)
+		}
+		if _, err := Value[bool](ctx, s, userID, "page_size"); err == nil {
+			t.Error("Value with the wrong type should fail")
+		}
+
+		r, err := s.Regional(ctx, userID)
+		if err != nil || r.Locale != "en" || r.Location.String() != "Europe/Paris" {
+			t.Errorf("Regional() = %+v, %v", r, err)
+		}
+		if locale, err := s.Locale(ctx, userID); err != nil || locale != "en" {
+			t.Errorf("Locale() = %q, %v", locale, err)
+		}
+	})
+}
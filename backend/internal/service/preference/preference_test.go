@@ -0,0 +1,139 @@
+package preference
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+)
+
+func intPtr(n int) *int { return &n }
+
+func newTestService() *PreferenceService {
+	// A nil pool panics if a test reaches the database.
+	s := NewPreferenceService(nil)
+	s.Register(
+		Def{Key: "theme", Kind: String, Default: "system", Allowed: []string{"light", "dark", "system"}},
+		Def{Key: "notifications.email", Kind: Bool, Default: true},
+		Def{Key: "page_size", Kind: Int, Default: 20, Min: intPtr(5), Max: intPtr(100)},
+	)
+	return s
+}
+
+func TestRegister_Panics(t *testing.T) {
+	tests := map[string]Def{
+		"bad key":       {Key: "Theme", Kind: String, Default: "x"},
+		"bad default":   {Key: "theme", Kind: String, Default: "blue", Allowed: []string{"light"}},
+		"kind mismatch": {Key: "flag", Kind: Bool, Default: "yes"},
+		"unknown kind":  {Key: "list", Kind: "array", Default: nil},
+	}
+	for name, d := range tests {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s: Register should panic", name)
+				}
+			}()
+			NewPreferenceService(nil).Register(d)
+		}()
+	}
+
+	s := newTestService()
+	defer func() {
+		if recover() == nil {
+			t.Error("duplicate Register should panic")
+		}
+	}()
+	s.Register(Def{Key: "theme", Kind: String, Default: "light"})
+}
+
+func TestResolve_DefaultsAndFallback(t *testing.T) {
+	s := newTestService()
+	got := s.resolve(map[string]json.RawMessage{
+		"theme":     json.RawMessage(`"dark"`),
+		"page_size": json.RawMessage(`500`), // no longer valid
+		"removed":   json.RawMessage(`1`),   // no longer registered
+	})
+
+	if got["theme"] != "dark" {
+		t.Errorf("theme = %v, want dark", got["theme"])
+	}
+	if got["page_size"] != 20 {
+		t.Errorf("page_size = %v, want default 20", got["page_size"])
+	}
+	if got["notifications.email"] != true {
+		t.Errorf("notifications.email = %v, want default true", got["notifications.email"])
+	}
+	if _, ok := got["removed"]; ok {
+		t.Error("unregistered key should be dropped")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	s := newTestService()
+	tests := []struct {
+		key, raw string
+		wantErr  bool
+	}{
+		{"theme", `"light"`, false},
+		{"theme", `"blue"`, true},
+		{"theme", `1`, true},
+		{"notifications.email", `false`, false},
+		{"notifications.email", `"false"`, true},
+		{"page_size", `50`, false},
+		{"page_size", `4`, true},
+		{"page_size", `101`, true},
+		{"page_size", `10.5`, true},
+	}
+	for _, tt := range tests {
+		_, p := s.defs[tt.key].decode(json.RawMessage(tt.raw))
+		if (p.ID != "") != tt.wantErr {
+			t.Errorf("decode(%s, %s) msg = %q, wantErr %v", tt.key, tt.raw, p.ID, tt.wantErr)
+		}
+	}
+}
+
+func TestDecode_CustomValidate(t *testing.T) {
+	d := Def{Key: "nickname", Kind: String, Default: "", Validate: func(v any) string {
+		if v.(string) == "admin" {
+			return "Reserved"
+		}
+		return ""
+	}}
+	if _, p := d.decode(json.RawMessage(`"admin"`)); p.ID != "Reserved" {
+		t.Errorf("msg = %q, want Reserved", p.ID)
+	}
+}
+
+func TestUpdate_ValidationBeforeWrite(t *testing.T) {
+	s := newTestService()
+	_, err := s.Update(context.Background(), "user-1", map[string]json.RawMessage{
+		"theme":     json.RawMessage(`"blue"`),
+		"unknown":   json.RawMessage(`1`),
+		"page_size": json.RawMessage(`null`),
+	})
+	var appErr *apperror.AppError
+	if !errors.As(err, &appErr) || appErr.Code != apperror.CodeValidation {
+		t.Fatalf("Update() error = %v, want Validation", err)
+	}
+	if appErr.Details["theme"] == "" || appErr.Details["unknown"] != "Unknown preference" {
+		t.Errorf("details = %v", appErr.Details)
+	}
+	if _, ok := appErr.Details["page_size"]; ok {
+		t.Error("null reset should be accepted")
+	}
+}
+
+func TestUpdate_Empty(t *testing.T) {
+	if _, err := newTestService().Update(context.Background(), "user-1", nil); !apperror.Is(err, apperror.CodeBadRequest) {
+		t.Errorf("Update(empty) error = %v, want BadRequest", err)
+	}
+}
+
+func TestValue_Unregistered(t *testing.T) {
+	if _, err := Value[string](context.Background(), newTestService(), "user-1", "nope"); err == nil {
+		t.Error("Value(unregistered) should fail")
+	}
+}
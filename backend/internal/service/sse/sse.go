@@ -1,6 +1,7 @@
 package sse
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
@@ -14,16 +15,19 @@ import (
 )
 
 const (
-	// Fixed internal buffer — sized for typical burst; not environment-specific.
-	sseChannelBuffer = 16
-	// Hard cap per user — prevents resource exhaustion; not environment-specific.
-	sseMaxConnsPerUser = 5
 	// Crypto entropy for one-time tickets (256 bits) — security parameter, not tunable.
 	sseTicketLength = 32
 )
 
+// EventReset tells a reconnecting client that the events it missed are no
+// longer available, so it must refetch its state.
+const EventReset = "reset"
+
 // SSEEvent is a server-sent event with a named event type and arbitrary data.
+// ID is assigned by the hub's ReplayStore when the event is sent; it is 0
+// for events that were never recorded.
 type SSEEvent struct {
+	ID    uint64 `json:"id,omitempty"`
 	Event string `json:"event"`
 	Data  any    `json:"data"`
 }
@@ -33,35 +37,127 @@ func (e SSEEvent) MarshalData() ([]byte, error) {
 	return json.Marshal(e.Data)
 }
 
-type sseTicket struct {
-	UserID    string
-	ExpiresAt time.Time
-}
-
-// SSEHub manages per-user SSE client channels and one-time connection tickets.
+// SSEHub manages per-user SSE client channels, their topic subscriptions,
+// and one-time connection tickets.
+//
+// Clients live in a process-local map. With a Broker, Send, Broadcast,
+// Publish, and Disconnect are also published to the other instances, which
+// deliver to their own clients; without one the hub serves this process
+// only.
 type SSEHub struct {
 	mu        sync.RWMutex
 	clients   map[string]map[chan SSEEvent]struct{}
-	tickets   map[string]sseTicket
+	topics    map[string]map[chan SSEEvent]struct{}
+	conns     map[chan SSEEvent]*client
+	kinds     map[string]Topic
+	messages  map[string]Message
+	policies  map[string]EventPolicy
+	present   map[presenceKey]int // local connections per topic and user
+	leaving   map[presenceKey]*time.Timer
+	tickets   TicketStore
 	ticketTTL time.Duration
+	replay    ReplayStore
+	presence  PresenceStore
+	broker    Broker
+	origin    string // tags this hub's broker messages so it skips its own
 	done      chan struct{}
+
+	presenceTTL   time.Duration
+	presenceGrace time.Duration
+
+	clientBuffer    int
+	maxConnsPerUser int
+	overflow        OverflowPolicy // for event types without a policy
+	evictRetry      time.Duration
 }
 
-// NewSSEHub creates a new SSE hub.
-func NewSSEHub(ticketTTL time.Duration) *SSEHub {
+// Option configures NewSSEHub.
+type Option func(*SSEHub)
+
+// WithBroker fans Send, Broadcast, and Disconnect out to other instances.
+// Run receives theirs.
+func WithBroker(b Broker) Option {
+	return func(h *SSEHub) {
+		h.broker = b
+	}
+}
+
+// WithTicketStore keeps tickets outside the process, so a ticket issued by
+// one instance opens a stream on any other.
+func WithTicketStore(s TicketStore) Option {
+	return func(h *SSEHub) {
+		h.tickets = s
+	}
+}
+
+// WithReplayStore sets where event IDs come from and recent events are
+// kept for reconnecting clients.
+func WithReplayStore(s ReplayStore) Option {
+	return func(h *SSEHub) {
+		h.replay = s
+	}
+}
+
+// NewSSEHub creates a new SSE hub. By default tickets and replay history
+// are held in memory, events reach only this process's clients, and a
+// client that falls behind misses new events, except presence changes,
+// which replace older ones for the same user.
+func NewSSEHub(ticketTTL time.Duration, opts ...Option) *SSEHub {
 	h := &SSEHub{
 		clients:   make(map[string]map[chan SSEEvent]struct{}),
-		tickets:   make(map[string]sseTicket),
+		topics:    make(map[string]map[chan SSEEvent]struct{}),
+		conns:     make(map[chan SSEEvent]*client),
+		kinds:     make(map[string]Topic),
+		messages:  make(map[string]Message),
+		policies:  make(map[string]EventPolicy),
+		present:   make(map[presenceKey]int),
+		leaving:   make(map[presenceKey]*time.Timer),
 		ticketTTL: ticketTTL,
+		origin:    newOrigin(),
 		done:      make(chan struct{}),
+
+		clientBuffer:    DefaultClientBuffer,
+		maxConnsPerUser: DefaultMaxConnsPerUser,
+		overflow:        DropNewest,
+		evictRetry:      DefaultEvictRetry,
+	}
+	h.policies[EventPresence] = EventPolicy{Event: EventPresence, Overflow: Coalesce, Key: presenceUser}
+	for _, opt := range opts {
+		opt(h)
+	}
+	if h.replay == nil {
+		h.replay = NewMemoryReplayStore(DefaultReplaySize, DefaultReplayTTL)
+	}
+	if h.presence == nil {
+		h.presence = NewMemoryPresenceStore()
+		h.presenceTTL = DefaultPresenceTTL
+		h.presenceGrace = DefaultPresenceGrace
+	}
+	go h.heartbeatLoop(h.done)
+	if h.tickets == nil {
+		mem := newMemoryTicketStore()
+		go mem.cleanupLoop(ticketTTL, h.done)
+		h.tickets = mem
 	}
-	go h.ticketCleanupLoop()
 	return h
 }
 
-// Subscribe registers a new SSE client channel for the given user.
-// Returns an error if the user already has sseMaxConnsPerUser connections.
-func (h *SSEHub) Subscribe(userID string) (chan SSEEvent, error) {
+// Subscribe registers a new SSE client channel for the given user and
+// topics, marking the user present in each. Topics must already be
+// authorized (see AuthorizeTopics). Returns an error if the user is at the
+// hub's connection limit.
+func (h *SSEHub) Subscribe(userID string, topics ...string) (chan SSEEvent, error) {
+	ch, arrived, err := h.subscribe(userID, topics)
+	if err != nil {
+		return nil, err
+	}
+	h.arrive(userID, arrived)
+	return ch, nil
+}
+
+// subscribe registers the channel and returns the topics the user newly
+// arrived in on this instance.
+func (h *SSEHub) subscribe(userID string, topics []string) (chan SSEEvent, []string, error) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
@@ -69,24 +165,33 @@ func (h *SSEHub) Subscribe(userID string) (chan SSEEvent, error) {
 		h.clients[userID] = make(map[chan SSEEvent]struct{})
 	}
 
-	if len(h.clients[userID]) >= sseMaxConnsPerUser {
-		return nil, fmt.Errorf("max SSE connections (%d) reached for user", sseMaxConnsPerUser)
+	if len(h.clients[userID]) >= h.maxConnsPerUser {
+		return nil, nil, fmt.Errorf("max SSE connections (%d) reached for user", h.maxConnsPerUser)
 	}
 
-	ch := make(chan SSEEvent, sseChannelBuffer)
+	ch := make(chan SSEEvent, h.clientBuffer)
 	h.clients[userID][ch] = struct{}{}
+	h.conns[ch] = &client{userID: userID}
+	h.joinLocked(ch, topics)
 	observability.ActiveSSEConns.Inc()
-	return ch, nil
+	return ch, h.arriveLocked(userID, topics), nil
 }
 
 // Unsubscribe removes a client channel and closes it.
 func (h *SSEHub) Unsubscribe(userID string, ch chan SSEEvent) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
+	h.removeLocked(userID, ch)
+}
 
+// removeLocked removes a client channel, if still registered, and closes
+// it. Must be called with mu held.
+func (h *SSEHub) removeLocked(userID string, ch chan SSEEvent) {
 	if conns, ok := h.clients[userID]; ok {
 		if _, exists := conns[ch]; exists {
 			delete(conns, ch)
+			h.leaveLocked(userID, ch)
+			delete(h.conns, ch)
 			close(ch)
 			observability.ActiveSSEConns.Dec()
 		}
@@ -96,115 +201,138 @@ func (h *SSEHub) Unsubscribe(userID string, ch chan SSEEvent) {
 	}
 }
 
-// Send delivers an event to all connections for a specific user.
-// Non-blocking: if a client's buffer is full, the event is dropped for that client.
-func (h *SSEHub) Send(userID string, event SSEEvent) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+// Disconnect closes every connection for a user, on every instance, and
+// burns their unused tickets so they cannot reconnect with one. Events
+// already buffered are still delivered before the stream ends, so callers
+// can Send a final event (e.g. "account_suspended") first.
+func (h *SSEHub) Disconnect(userID string) {
+	h.disconnect(userID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), brokerTimeout)
+	defer cancel()
+	if err := h.tickets.Revoke(ctx, userID); err != nil {
+		logger.Error("failed to revoke SSE tickets",
+			slog.String("user_id", userID),
+			slog.String("error", err.Error()),
+		)
+	}
+	h.fanout(message{Kind: kindDisconnect, UserID: userID})
+}
 
-	conns, ok := h.clients[userID]
-	if !ok {
-		return
+// disconnect closes the user's connections on this instance.
+func (h *SSEHub) disconnect(userID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.clients[userID] {
+		h.leaveLocked(userID, ch)
+		delete(h.conns, ch)
+		close(ch)
+		observability.ActiveSSEConns.Dec()
 	}
+	delete(h.clients, userID)
+}
+
+// Send delivers an event to all connections for a specific user, on every
+// instance, and records it for replay. Non-blocking: if a client's buffer
+// is full, the event type's overflow policy applies.
+func (h *SSEHub) Send(userID string, event SSEEvent) {
+	event.ID = h.record(userStream(userID), event)
+	h.send(userID, event)
+	h.fanoutEvent(message{Kind: kindSend, UserID: userID}, event)
+}
 
-	for ch := range conns {
-		select {
-		case ch <- event:
-		default:
-			logger.Warn("SSE event dropped for slow client",
-				slog.String("user_id", userID),
-				slog.String("event", event.Event),
-			)
+// send delivers an event to the user's connections on this instance.
+func (h *SSEHub) send(userID string, event SSEEvent) {
+	var evicted []chan SSEEvent
+	h.mu.RLock()
+	for ch := range h.clients[userID] {
+		if h.deliverLocked(ch, event) {
+			evicted = append(evicted, ch)
 		}
 	}
+	h.mu.RUnlock()
+	h.evict(evicted)
 }
 
-// Broadcast delivers an event to all connected clients across all users.
-// Non-blocking: slow clients have their events dropped.
+// Broadcast delivers an event to all connected clients across all users
+// and instances, and records it for replay. Non-blocking: slow clients
+// are handled by the event type's overflow policy.
 func (h *SSEHub) Broadcast(event SSEEvent) {
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+	event.ID = h.record(broadcastStream, event)
+	h.broadcast(event)
+	h.fanoutEvent(message{Kind: kindBroadcast}, event)
+}
 
-	for userID, conns := range h.clients {
+// broadcast delivers an event to every connection on this instance.
+func (h *SSEHub) broadcast(event SSEEvent) {
+	var evicted []chan SSEEvent
+	h.mu.RLock()
+	for _, conns := range h.clients {
 		for ch := range conns {
-			select {
-			case ch <- event:
-			default:
-				logger.Warn("SSE broadcast dropped for slow client",
-					slog.String("user_id", userID),
-					slog.String("event", event.Event),
-				)
+			if h.deliverLocked(ch, event) {
+				evicted = append(evicted, ch)
 			}
 		}
 	}
+	h.mu.RUnlock()
+	h.evict(evicted)
 }
 
-// CreateTicket generates a one-time ticket for SSE connection auth.
-// The ticket is valid for sseTicketTTL and can only be used once.
-func (h *SSEHub) CreateTicket(userID string) (string, error) {
-	b := make([]byte, sseTicketLength)
-	if _, err := rand.Read(b); err != nil {
-		return "", fmt.Errorf("generate ticket: %w", err)
+// record assigns the event its ID and stores it on stream for replay. On
+// failure the event is still delivered, without an ID.
+func (h *SSEHub) record(stream string, event SSEEvent) uint64 {
+	ctx, cancel := context.WithTimeout(context.Background(), brokerTimeout)
+	defer cancel()
+	id, err := h.replay.Append(ctx, stream, event)
+	if err != nil {
+		logger.Error("failed to record SSE event",
+			slog.String("stream", stream),
+			slog.String("event", event.Event),
+			slog.String("error", err.Error()),
+		)
+		return 0
 	}
-	ticket := base64.URLEncoding.EncodeToString(b)
-
-	h.mu.Lock()
-	defer h.mu.Unlock()
-
-	h.cleanExpiredTicketsLocked()
-
-	h.tickets[ticket] = sseTicket{
-		UserID:    userID,
-		ExpiresAt: time.Now().Add(h.ticketTTL),
-	}
-
-	return ticket, nil
+	return id
 }
 
-// ValidateTicket checks a ticket, burns it (single-use), and returns the user ID.
-func (h *SSEHub) ValidateTicket(ticket string) (string, error) {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-
-	t, ok := h.tickets[ticket]
-	if !ok {
-		return "", fmt.Errorf("invalid ticket")
+// Since returns the events a client subscribed to topics missed after
+// lastID, for a client reconnecting with Last-Event-ID.
+func (h *SSEHub) Since(userID string, topics []string, lastID uint64) (Replay, error) {
+	streams := []string{userStream(userID), broadcastStream}
+	for _, topic := range topics {
+		streams = append(streams, topicStream(topic))
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), brokerTimeout)
+	defer cancel()
+	return h.replay.Since(ctx, streams, lastID)
+}
 
-	delete(h.tickets, ticket)
-
-	if time.Now().After(t.ExpiresAt) {
-		return "", fmt.Errorf("ticket expired")
+// CreateTicket generates a one-time ticket for SSE connection auth,
+// granting the user's events and the given topics, which must already be
+// authorized. The ticket is valid for the hub's ticket TTL and can only be
+// used once.
+func (h *SSEHub) CreateTicket(userID string, topics ...string) (string, error) {
+	b := make([]byte, sseTicketLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate ticket: %w", err)
 	}
+	ticket := base64.URLEncoding.EncodeToString(b)
 
-	return t.UserID, nil
-}
-
-// cleanExpiredTicketsLocked removes expired tickets. Must be called with mu held.
-func (h *SSEHub) cleanExpiredTicketsLocked() {
-	now := time.Now()
-	for ticket, t := range h.tickets {
-		if now.After(t.ExpiresAt) {
-			delete(h.tickets, ticket)
-		}
+	ctx, cancel := context.WithTimeout(context.Background(), brokerTimeout)
+	defer cancel()
+	if err := h.tickets.Put(ctx, ticket, Grant{UserID: userID, Topics: topics}, h.ticketTTL); err != nil {
+		return "", fmt.Errorf("store ticket: %w", err)
 	}
+	return ticket, nil
 }
 
-// ticketCleanupLoop periodically removes expired tickets even when no new
-// tickets are being created. Runs until Shutdown() closes the done channel.
-func (h *SSEHub) ticketCleanupLoop() {
-	ticker := time.NewTicker(h.ticketTTL)
-	defer ticker.Stop()
-	for {
-		select {
-		case <-ticker.C:
-			h.mu.Lock()
-			h.cleanExpiredTicketsLocked()
-			h.mu.Unlock()
-		case <-h.done:
-			return
-		}
-	}
+// ValidateTicket checks a ticket, burns it (single-use), and returns what
+// it grants.
+func (h *SSEHub) ValidateTicket(ticket string) (Grant, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), brokerTimeout)
+	defer cancel()
+	return h.tickets.Take(ctx, ticket)
 }
 
 // Shutdown closes all client channels. Call during graceful server shutdown.
@@ -220,8 +348,19 @@ func (h *SSEHub) Shutdown() {
 		}
 		delete(h.clients, userID)
 	}
+	clear(h.topics)
+	clear(h.conns)
+	// Entries for this instance expire on their own; users who reconnect
+	// elsewhere within the TTL never appear to leave.
+	for _, t := range h.leaving {
+		t.Stop()
+	}
+	clear(h.leaving)
+	clear(h.present)
 
-	h.tickets = make(map[string]sseTicket)
+	if mem, ok := h.tickets.(*memoryTicketStore); ok {
+		mem.clear()
+	}
 
 	logger.Info("SSE hub shut down")
 }
@@ -0,0 +1,245 @@
+package sse
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/golid-ai/golid/backend/internal/logger"
+)
+
+// BrokerChannel is the Redis pub/sub channel that carries hub messages
+// between instances.
+const BrokerChannel = "sse_events"
+
+const (
+	// brokerTimeout bounds each publish and ticket operation, which run on
+	// the caller's goroutine.
+	brokerTimeout = 2 * time.Second
+
+	runMinBackoff = time.Second
+	runMaxBackoff = 30 * time.Second
+
+	ticketKeyPrefix     = "sse:ticket:"
+	userTicketKeyPrefix = "sse:tickets:"
+)
+
+// Message kinds, one per fanned-out hub method.
+const (
+	kindSend       = "send"
+	kindBroadcast  = "broadcast"
+	kindPublish    = "publish"
+	kindDisconnect = "disconnect"
+)
+
+// Broker carries hub messages between instances.
+type Broker interface {
+	// Publish sends a message to every subscribed hub, including this one.
+	Publish(ctx context.Context, payload []byte) error
+	// Listen subscribes and blocks, calling ready once the subscription is
+	// live and deliver for every message, until ctx is cancelled or the
+	// connection fails. It returns the reason it stopped.
+	Listen(ctx context.Context, ready func(), deliver func(payload []byte)) error
+}
+
+// message is the wire format of a hub call. Data stays raw so an event
+// reaches remote clients byte-for-byte as the sender marshaled it.
+type message struct {
+	Origin string          `json:"origin"`
+	Kind   string          `json:"kind"`
+	UserID string          `json:"user_id,omitempty"`
+	Topic  string          `json:"topic,omitempty"`
+	ID     uint64          `json:"id,omitempty"`
+	Event  string          `json:"event,omitempty"`
+	Data   json.RawMessage `json:"data,omitempty"`
+}
+
+func newOrigin() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b) //nolint:errcheck // crypto/rand.Read never fails
+	return hex.EncodeToString(b)
+}
+
+// fanoutEvent publishes a Send, Broadcast, or Publish for the other
+// instances. msg names the kind and audience.
+func (h *SSEHub) fanoutEvent(msg message, event SSEEvent) {
+	if h.broker == nil {
+		return
+	}
+	data, err := event.MarshalData()
+	if err != nil {
+		logger.Error("SSE marshal error",
+			slog.String("event", event.Event),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	msg.ID, msg.Event, msg.Data = event.ID, event.Event, data
+	h.fanout(msg)
+}
+
+// fanout sends msg to the other instances. Failures are logged, not
+// returned: local clients already have the event, and there is no one to
+// retry for.
+func (h *SSEHub) fanout(msg message) {
+	if h.broker == nil {
+		return
+	}
+	msg.Origin = h.origin
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		logger.Error("SSE broker marshal error", slog.String("error", err.Error()))
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), brokerTimeout)
+	defer cancel()
+	if err := h.broker.Publish(ctx, payload); err != nil {
+		logger.Error("failed to publish SSE message",
+			slog.String("kind", msg.Kind),
+			slog.String("error", err.Error()),
+		)
+	}
+}
+
+// Run delivers messages published by other instances to this instance's
+// clients until ctx is cancelled, reconnecting with backoff. Messages
+// published while disconnected are lost, as they would be for a client
+// whose buffer is full. A no-op without a Broker.
+func (h *SSEHub) Run(ctx context.Context) {
+	if h.broker == nil {
+		return
+	}
+	backoff := runMinBackoff
+	for {
+		err := h.broker.Listen(ctx,
+			func() {
+				logger.Info("SSE broker connected")
+				backoff = runMinBackoff
+			},
+			h.receive)
+		if ctx.Err() != nil {
+			return
+		}
+		logger.Warn("SSE broker disconnected; serving local clients only until it reconnects",
+			slog.String("error", fmt.Sprint(err)),
+			slog.Duration("retry_in", backoff))
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff = min(backoff*2, runMaxBackoff)
+	}
+}
+
+// receive applies a message from another instance to local clients.
+func (h *SSEHub) receive(payload []byte) {
+	var msg message
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		logger.Warn("invalid SSE broker message", slog.String("error", err.Error()))
+		return
+	}
+	if msg.Origin == h.origin {
+		return
+	}
+	event := SSEEvent{ID: msg.ID, Event: msg.Event, Data: msg.Data}
+	switch msg.Kind {
+	case kindSend:
+		h.send(msg.UserID, event)
+	case kindBroadcast:
+		h.broadcast(event)
+	case kindPublish:
+		h.publish(msg.Topic, event)
+	case kindDisconnect:
+		h.disconnect(msg.UserID)
+	default:
+		logger.Warn("unknown SSE broker message", slog.String("kind", msg.Kind))
+	}
+}
+
+// RedisBroker fans hub messages out over Redis pub/sub and stores tickets
+// in Redis, so every instance accepts every ticket.
+type RedisBroker struct {
+	client *redis.Client
+}
+
+func NewRedisBroker(client *redis.Client) *RedisBroker {
+	return &RedisBroker{client: client}
+}
+
+func (r *RedisBroker) Publish(ctx context.Context, payload []byte) error {
+	return r.client.Publish(ctx, BrokerChannel, payload).Err()
+}
+
+func (r *RedisBroker) Listen(ctx context.Context, ready func(), deliver func([]byte)) error {
+	sub := r.client.Subscribe(ctx, BrokerChannel)
+	defer sub.Close() //nolint:errcheck // subscription is being discarded
+
+	// The first reply confirms the subscription (or reports why it failed).
+	if _, err := sub.Receive(ctx); err != nil {
+		return fmt.Errorf("subscribe: %w", err)
+	}
+	ready()
+	for {
+		msg, err := sub.ReceiveMessage(ctx)
+		if err != nil {
+			return err
+		}
+		deliver([]byte(msg.Payload))
+	}
+}
+
+// Put stores the ticket and indexes it under the user so Revoke can find
+// it. Both keys expire with the ticket.
+func (r *RedisBroker) Put(ctx context.Context, ticket string, grant Grant, ttl time.Duration) error {
+	value, err := json.Marshal(grant)
+	if err != nil {
+		return fmt.Errorf("marshal grant: %w", err)
+	}
+	userKey := userTicketKeyPrefix + grant.UserID
+	_, err = r.client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		p.Set(ctx, ticketKeyPrefix+ticket, value, ttl)
+		p.SAdd(ctx, userKey, ticket)
+		p.Expire(ctx, userKey, ttl)
+		return nil
+	})
+	return err
+}
+
+// Take burns the ticket atomically, so two instances racing on the same
+// ticket cannot both accept it.
+func (r *RedisBroker) Take(ctx context.Context, ticket string) (Grant, error) {
+	value, err := r.client.GetDel(ctx, ticketKeyPrefix+ticket).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return Grant{}, fmt.Errorf("invalid ticket")
+	}
+	if err != nil {
+		return Grant{}, fmt.Errorf("take ticket: %w", err)
+	}
+	var grant Grant
+	if err := json.Unmarshal(value, &grant); err != nil {
+		return Grant{}, fmt.Errorf("decode ticket: %w", err)
+	}
+	r.client.SRem(ctx, userTicketKeyPrefix+grant.UserID, ticket) //nolint:errcheck // index entry expires with the set
+	return grant, nil
+}
+
+func (r *RedisBroker) Revoke(ctx context.Context, userID string) error {
+	userKey := userTicketKeyPrefix + userID
+	tickets, err := r.client.SMembers(ctx, userKey).Result()
+	if err != nil {
+		return fmt.Errorf("list tickets: %w", err)
+	}
+	keys := []string{userKey}
+	for _, t := range tickets {
+		keys = append(keys, ticketKeyPrefix+t)
+	}
+	return r.client.Del(ctx, keys...).Err()
+}
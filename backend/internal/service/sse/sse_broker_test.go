@@ -0,0 +1,137 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newClusterHubs returns two hubs sharing a miniredis broker, both
+// subscribed, as if running on separate instances.
+func newClusterHubs(t *testing.T) (*SSEHub, *SSEHub) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	var hubs [2]*SSEHub
+	for i := range hubs {
+		client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+		t.Cleanup(func() { _ = client.Close() })
+		broker := NewRedisBroker(client)
+		hub := NewSSEHub(30*time.Second, WithBroker(broker), WithTicketStore(broker))
+		ctx, cancel := context.WithCancel(context.Background())
+		t.Cleanup(cancel)
+		go hub.Run(ctx)
+		hubs[i] = hub
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for mr.PubSubNumSub(BrokerChannel)[BrokerChannel] < len(hubs) {
+		if time.Now().After(deadline) {
+			t.Fatal("hubs did not subscribe")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	return hubs[0], hubs[1]
+}
+
+func receive(t *testing.T, ch chan SSEEvent) (SSEEvent, bool) {
+	t.Helper()
+	select {
+	case ev, open := <-ch:
+		return ev, open
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return SSEEvent{}, false
+	}
+}
+
+func expectNone(t *testing.T, ch chan SSEEvent) {
+	t.Helper()
+	select {
+	case ev := <-ch:
+		t.Errorf("unexpected event %q", ev.Event)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestSSEHub_BrokerSend(t *testing.T) {
+	a, b := newClusterHubs(t)
+	local, _ := a.Subscribe("user-1")
+	remote, _ := b.Subscribe("user-1")
+	other, _ := b.Subscribe("user-2")
+
+	a.Send("user-1", SSEEvent{Event: "notification", Data: map[string]string{"msg": "hi"}})
+
+	if ev, _ := receive(t, local); ev.Event != "notification" {
+		t.Errorf("local event = %q", ev.Event)
+	}
+	ev, _ := receive(t, remote)
+	data, _ := ev.MarshalData()
+	if ev.Event != "notification" || string(data) != `{"msg":"hi"}` {
+		t.Errorf("remote event = %q %s", ev.Event, data)
+	}
+	// The sender's own message comes back from Redis and must be skipped.
+	expectNone(t, local)
+	expectNone(t, other)
+}
+
+func TestSSEHub_BrokerBroadcast(t *testing.T) {
+	a, b := newClusterHubs(t)
+	ch1, _ := a.Subscribe("user-1")
+	ch2, _ := b.Subscribe("user-2")
+
+	b.Broadcast(SSEEvent{Event: "maintenance", Data: json.RawMessage(`true`)})
+
+	for _, ch := range []chan SSEEvent{ch1, ch2} {
+		if ev, _ := receive(t, ch); ev.Event != "maintenance" {
+			t.Errorf("event = %q, want maintenance", ev.Event)
+		}
+	}
+	expectNone(t, ch2)
+}
+
+func TestSSEHub_BrokerPublish(t *testing.T) {
+	a, b := newClusterHubs(t)
+	member, _ := b.Subscribe("user-1", "org:1")
+	outsider, _ := b.Subscribe("user-2", "org:2")
+	drain(member)
+	drain(outsider)
+
+	a.Publish("org:1", SSEEvent{Event: "org_updated"})
+
+	if ev, _ := receive(t, member); ev.Event != "org_updated" || ev.ID == 0 {
+		t.Errorf("event = %q id=%d, want org_updated with an ID", ev.Event, ev.ID)
+	}
+	expectNone(t, outsider)
+}
+
+func TestSSEHub_BrokerDisconnectAndTickets(t *testing.T) {
+	a, b := newClusterHubs(t)
+
+	// A ticket issued by one instance is accepted once, by any instance.
+	ticket, err := a.CreateTicket("user-1", "org:1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if grant, err := b.ValidateTicket(ticket); err != nil || grant.UserID != "user-1" || len(grant.Topics) != 1 {
+		t.Fatalf("ValidateTicket on other instance = %+v, %v", grant, err)
+	}
+	if _, err := a.ValidateTicket(ticket); err == nil {
+		t.Error("ticket should be burned on every instance")
+	}
+
+	remote, _ := b.Subscribe("user-1")
+	unused, _ := b.CreateTicket("user-1")
+	a.Disconnect("user-1")
+
+	if _, open := receive(t, remote); open {
+		t.Error("remote connection should be closed by Disconnect")
+	}
+	if _, err := a.ValidateTicket(unused); err == nil {
+		t.Error("unused ticket should be revoked by Disconnect")
+	}
+	b.Unsubscribe("user-1", remote)
+}
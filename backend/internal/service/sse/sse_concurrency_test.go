@@ -139,12 +139,12 @@ func TestSSEHub_ConcurrentTickets(t *testing.T) {
 	for _, ticket := range allTickets {
 		go func(ticket string) {
 			defer wg.Done()
-			userID, err := hub.ValidateTicket(ticket)
+			grant, err := hub.ValidateTicket(ticket)
 			if err != nil {
 				return
 			}
-			if userID != "user-1" {
-				t.Errorf("ticket resolved to %q, want user-1", userID)
+			if grant.UserID != "user-1" {
+				t.Errorf("ticket resolved to %q, want user-1", grant.UserID)
 			}
 		}(ticket)
 	}
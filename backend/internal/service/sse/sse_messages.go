@@ -0,0 +1,62 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+)
+
+// messageTypePattern matches a client message type, such as "typing" or
+// "doc.edit".
+var messageTypePattern = regexp.MustCompile(`^[a-z][a-z0-9_.]*$`)
+
+// Message declares a type of message WebSocket clients may send.
+type Message struct {
+	Type string
+	// Handle processes one message from a client holding grant. Replies go
+	// through the hub (Send, Publish); a returned error is sent back to the
+	// client as an "error" event, with the message and code of an
+	// *apperror.AppError or a generic internal error otherwise.
+	Handle func(ctx context.Context, from Grant, data json.RawMessage) error
+}
+
+// MessageDeclarer is implemented by services that accept client messages.
+type MessageDeclarer interface {
+	SSEMessages() []Message
+}
+
+// RegisterMessages declares client message types. Called during wiring,
+// before any request is served; panics on an invalid or duplicate
+// declaration.
+func (h *SSEHub) RegisterMessages(messages ...Message) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, m := range messages {
+		if !messageTypePattern.MatchString(m.Type) {
+			panic(fmt.Sprintf("sse: invalid message type %q", m.Type))
+		}
+		if m.Handle == nil {
+			panic(fmt.Sprintf("sse: message type %q has no handler", m.Type))
+		}
+		if _, dup := h.messages[m.Type]; dup {
+			panic(fmt.Sprintf("sse: duplicate message type %q", m.Type))
+		}
+		h.messages[m.Type] = m
+	}
+}
+
+// HandleMessage routes a client message to the handler registered for its
+// type.
+func (h *SSEHub) HandleMessage(ctx context.Context, from Grant, msgType string, data json.RawMessage) error {
+	h.mu.RLock()
+	m, ok := h.messages[msgType]
+	h.mu.RUnlock()
+	if !ok {
+		return apperror.BadRequest(fmt.Sprintf("unknown message type %q", msgType)).WithMessageID("error.sse.unknown_message", msgType)
+	}
+	return m.Handle(ctx, from, data)
+}
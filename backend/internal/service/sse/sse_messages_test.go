@@ -0,0 +1,57 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+)
+
+func TestSSEHub_HandleMessage(t *testing.T) {
+	hub := NewSSEHub(30 * time.Second)
+	var got string
+	hub.RegisterMessages(Message{
+		Type: "doc.typing",
+		Handle: func(_ context.Context, from Grant, data json.RawMessage) error {
+			got = from.UserID + " " + string(data)
+			return nil
+		},
+	})
+	ctx := context.Background()
+
+	if err := hub.HandleMessage(ctx, Grant{UserID: "user-1"}, "doc.typing", json.RawMessage(`{"doc":"a"}`)); err != nil {
+		t.Fatalf("HandleMessage() error = %v", err)
+	}
+	if got != `user-1 {"doc":"a"}` {
+		t.Errorf("handler got %q", got)
+	}
+
+	err := hub.HandleMessage(ctx, Grant{UserID: "user-1"}, "unknown", nil)
+	var appErr *apperror.AppError
+	if !errors.As(err, &appErr) || appErr.Code != apperror.CodeBadRequest {
+		t.Errorf("unknown type: err = %v, want bad request", err)
+	}
+}
+
+func TestSSEHub_RegisterMessagesPanics(t *testing.T) {
+	handle := func(context.Context, Grant, json.RawMessage) error { return nil }
+	for name, msg := range map[string]Message{
+		"duplicate":    {Type: "typing", Handle: handle},
+		"invalid type": {Type: "Typing!", Handle: handle},
+		"no handler":   {Type: "cursor"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			hub := NewSSEHub(30 * time.Second)
+			hub.RegisterMessages(Message{Type: "typing", Handle: handle})
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			hub.RegisterMessages(msg)
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package sse
+
+import (
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/golid-ai/golid/backend/internal/logger"
+	"github.com/golid-ai/golid/backend/internal/observability"
+)
+
+const (
+	// DefaultClientBuffer is how many events a connection can fall behind
+	// before its overflow policy applies.
+	DefaultClientBuffer = 16
+	// DefaultMaxConnsPerUser caps a user's connections per instance.
+	DefaultMaxConnsPerUser = 5
+	// DefaultEvictRetry is how long a client disconnected for falling
+	// behind is asked to wait before reconnecting.
+	DefaultEvictRetry = 5 * time.Second
+)
+
+// OverflowPolicy decides what happens to an event for a connection whose
+// buffer is full.
+type OverflowPolicy string
+
+const (
+	// DropNewest discards the new event. The default.
+	DropNewest OverflowPolicy = "drop_newest"
+	// DropOldest discards the connection's oldest pending event to make
+	// room for the new one.
+	DropOldest OverflowPolicy = "drop_oldest"
+	// Coalesce replaces pending events of the same type and key with the
+	// new one, for state where only the latest value matters, such as a
+	// cursor position. With none pending, the new event is dropped.
+	Coalesce OverflowPolicy = "coalesce"
+	// Disconnect discards everything pending and ends the connection with
+	// a reconnect event. The client comes back after the retry hint and
+	// is caught up by replay from its last event ID.
+	Disconnect OverflowPolicy = "disconnect"
+)
+
+// EventReconnect is the last event on a connection closed for falling
+// behind. Its data is a Reconnect.
+const EventReconnect = "reconnect"
+
+// Reconnect tells a client how long to wait, in milliseconds, before
+// reconnecting.
+type Reconnect struct {
+	Retry int64 `json:"retry"`
+}
+
+// ParseOverflowPolicy parses a policy name, such as "drop_oldest".
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch p := OverflowPolicy(s); p {
+	case DropNewest, DropOldest, Coalesce, Disconnect:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown SSE overflow policy %q", s)
+}
+
+// EventPolicy sets the overflow policy for one event type.
+type EventPolicy struct {
+	Event    string
+	Overflow OverflowPolicy
+	// Key identifies events that supersede each other under Coalesce, such
+	// as the document a cursor event belongs to. Required for Coalesce.
+	// Data is as passed to Send or Publish, or json.RawMessage for events
+	// from other instances.
+	Key func(event SSEEvent) string
+}
+
+// EventPolicyDeclarer is implemented by services whose events need an
+// overflow policy other than the hub's default.
+type EventPolicyDeclarer interface {
+	SSEEventPolicies() []EventPolicy
+}
+
+// WithLimits sets how many events each connection buffers and how many
+// connections a user may hold on this instance. A value ≤ 0 keeps the
+// default.
+func WithLimits(buffer, maxConnsPerUser int) Option {
+	return func(h *SSEHub) {
+		if buffer > 0 {
+			h.clientBuffer = buffer
+		}
+		if maxConnsPerUser > 0 {
+			h.maxConnsPerUser = maxConnsPerUser
+		}
+	}
+}
+
+// WithOverflow sets the policy for event types without a registered one,
+// and the retry hint sent to clients disconnected for falling behind. An
+// empty policy or a retry ≤ 0 keeps the default. Coalesce needs a key, so
+// it can't be the default.
+func WithOverflow(policy OverflowPolicy, evictRetry time.Duration) Option {
+	if policy == "" {
+		policy = DropNewest
+	}
+	if _, err := ParseOverflowPolicy(string(policy)); err != nil || policy == Coalesce {
+		panic(fmt.Sprintf("sse: invalid default overflow policy %q", policy))
+	}
+	return func(h *SSEHub) {
+		h.overflow = policy
+		if evictRetry > 0 {
+			h.evictRetry = evictRetry
+		}
+	}
+}
+
+// RegisterEventPolicies sets overflow policies by event type. Called during
+// wiring, before any request is served; panics on an invalid or duplicate
+// declaration.
+func (h *SSEHub) RegisterEventPolicies(policies ...EventPolicy) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, p := range policies {
+		if p.Event == "" {
+			panic("sse: event policy has no event type")
+		}
+		if _, err := ParseOverflowPolicy(string(p.Overflow)); err != nil {
+			panic(fmt.Sprintf("sse: event %q: %v", p.Event, err))
+		}
+		if p.Overflow == Coalesce && p.Key == nil {
+			panic(fmt.Sprintf("sse: event %q coalesces without a key", p.Event))
+		}
+		if _, dup := h.policies[p.Event]; dup {
+			panic(fmt.Sprintf("sse: duplicate policy for event %q", p.Event))
+		}
+		h.policies[p.Event] = p
+	}
+}
+
+// client is one connection's delivery state. Its mutex serializes
+// deliveries, so an overflow policy sees a buffer no other sender is
+// changing; only the connection's reader drains it meanwhile.
+type client struct {
+	mu      sync.Mutex
+	userID  string
+	topics  []string
+	evicted bool // ended by Disconnect, awaiting removal
+}
+
+// deliverLocked queues event for ch, applying the event type's overflow
+// policy if the buffer is full. It reports whether the client must now be
+// evicted. Must be called with mu held, for reading or writing.
+func (h *SSEHub) deliverLocked(ch chan SSEEvent, event SSEEvent) bool {
+	c := h.conns[ch]
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.evicted {
+		return false
+	}
+	select {
+	case ch <- event:
+		return false
+	default:
+	}
+
+	p, ok := h.policies[event.Event]
+	if !ok {
+		p.Overflow = h.overflow
+	}
+	switch p.Overflow {
+	case DropOldest:
+		select {
+		case old := <-ch:
+			dropped(c, old, DropOldest)
+		default:
+		}
+		enqueue(c, ch, event, DropOldest)
+
+	case Coalesce:
+		key := p.Key(event)
+		pending := drainQueue(ch)
+		kept := pending[:0]
+		for _, e := range pending {
+			if e.Event == event.Event && p.Key(e) == key {
+				dropped(c, e, Coalesce)
+				continue
+			}
+			kept = append(kept, e)
+		}
+		if len(kept) < len(pending) {
+			kept = append(kept, event)
+		} else {
+			dropped(c, event, Coalesce)
+		}
+		for _, e := range kept {
+			enqueue(c, ch, e, Coalesce)
+		}
+
+	case Disconnect:
+		for _, e := range drainQueue(ch) {
+			dropped(c, e, Disconnect)
+		}
+		dropped(c, event, Disconnect)
+		enqueue(c, ch, SSEEvent{Event: EventReconnect, Data: Reconnect{Retry: h.evictRetry.Milliseconds()}}, Disconnect)
+		c.evicted = true
+		observability.SSEEvictions.WithLabelValues(event.Event).Inc()
+		logger.Warn("SSE client disconnected for falling behind",
+			slog.String("user_id", c.userID),
+			slog.String("event", event.Event),
+		)
+		return true
+
+	default:
+		dropped(c, event, DropNewest)
+	}
+	return false
+}
+
+// evict removes clients ended by the Disconnect policy. Their channels
+// close after the reconnect event, which ends their streams.
+func (h *SSEHub) evict(evicted []chan SSEEvent) {
+	if len(evicted) == 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, ch := range evicted {
+		if c, ok := h.conns[ch]; ok {
+			h.removeLocked(c.userID, ch)
+		}
+	}
+}
+
+// enqueue sends without blocking. The caller holds the client's lock, so
+// the room it just made can only grow; a failure is counted all the same.
+func enqueue(c *client, ch chan SSEEvent, event SSEEvent, policy OverflowPolicy) {
+	select {
+	case ch <- event:
+	default:
+		dropped(c, event, policy)
+	}
+}
+
+// drainQueue takes every event pending on ch, oldest first.
+func drainQueue(ch chan SSEEvent) []SSEEvent {
+	var pending []SSEEvent
+	for {
+		select {
+		case e := <-ch:
+			pending = append(pending, e)
+		default:
+			return pending
+		}
+	}
+}
+
+func dropped(c *client, event SSEEvent, policy OverflowPolicy) {
+	observability.SSEEventsDropped.WithLabelValues(event.Event, string(policy)).Inc()
+	logger.Debug("SSE event dropped for slow client",
+		slog.String("user_id", c.userID),
+		slog.String("event", event.Event),
+		slog.String("policy", string(policy)),
+	)
+}
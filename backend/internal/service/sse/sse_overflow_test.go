@@ -0,0 +1,174 @@
+package sse
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/golid-ai/golid/backend/internal/observability"
+)
+
+// pending drains ch and returns the data of each event.
+func pending(ch chan SSEEvent) []any {
+	var data []any
+	for _, e := range drainQueue(ch) {
+		data = append(data, e.Data)
+	}
+	return data
+}
+
+func docKey(e SSEEvent) string {
+	return e.Data.(map[string]string)["doc"]
+}
+
+func TestSSEHub_WithLimits(t *testing.T) {
+	hub := NewSSEHub(30*time.Second, WithLimits(2, 1))
+
+	ch, err := hub.Subscribe("user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cap(ch) != 2 {
+		t.Errorf("buffer = %d, want 2", cap(ch))
+	}
+	if _, err := hub.Subscribe("user-1"); err == nil {
+		t.Error("expected error past one connection")
+	}
+	hub.Unsubscribe("user-1", ch)
+}
+
+func TestSSEHub_OverflowDrop(t *testing.T) {
+	hub := NewSSEHub(30*time.Second, WithLimits(2, 0))
+	hub.RegisterEventPolicies(EventPolicy{Event: "tick_oldest", Overflow: DropOldest})
+	ch, _ := hub.Subscribe("user-1")
+	defer hub.Unsubscribe("user-1", ch)
+
+	newest := observability.SSEEventsDropped.WithLabelValues("tick_newest", string(DropNewest))
+	before := testutil.ToFloat64(newest)
+	for i := range 3 {
+		hub.Send("user-1", SSEEvent{Event: "tick_newest", Data: i})
+	}
+	if got := pending(ch); len(got) != 2 || got[0] != 0 || got[1] != 1 {
+		t.Errorf("drop newest kept %v, want [0 1]", got)
+	}
+	if d := testutil.ToFloat64(newest) - before; d != 1 {
+		t.Errorf("drop newest counted %v drops, want 1", d)
+	}
+
+	for i := range 3 {
+		hub.Send("user-1", SSEEvent{Event: "tick_oldest", Data: i})
+	}
+	if got := pending(ch); len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Errorf("drop oldest kept %v, want [1 2]", got)
+	}
+}
+
+func TestSSEHub_OverflowCoalesce(t *testing.T) {
+	hub := NewSSEHub(30*time.Second, WithLimits(3, 0))
+	hub.RegisterEventPolicies(EventPolicy{Event: "cursor", Overflow: Coalesce, Key: docKey})
+	ch, _ := hub.Subscribe("user-1")
+	defer hub.Unsubscribe("user-1", ch)
+
+	a1 := map[string]string{"doc": "a", "pos": "1"}
+	b1 := map[string]string{"doc": "b", "pos": "1"}
+	a2 := map[string]string{"doc": "a", "pos": "2"}
+	hub.Send("user-1", SSEEvent{Event: "cursor", Data: a1})
+	hub.Send("user-1", SSEEvent{Event: "other", Data: a1})
+	hub.Send("user-1", SSEEvent{Event: "cursor", Data: b1})
+	hub.Send("user-1", SSEEvent{Event: "cursor", Data: a2})
+
+	// The pending cursor for doc a is replaced; order is otherwise kept.
+	got := drainQueue(ch)
+	if len(got) != 3 || got[0].Event != "other" || docKey(got[1]) != "b" || got[2].Data.(map[string]string)["pos"] != "2" {
+		t.Errorf("pending = %v, want other, cursor b, cursor a@2", got)
+	}
+
+	// Nothing to coalesce with: the new event is dropped.
+	for range 3 {
+		hub.Send("user-1", SSEEvent{Event: "other", Data: a1})
+	}
+	hub.Send("user-1", SSEEvent{Event: "cursor", Data: b1})
+	for _, e := range drainQueue(ch) {
+		if e.Event == "cursor" {
+			t.Error("cursor event queued past a full buffer")
+		}
+	}
+}
+
+func TestSSEHub_OverflowDisconnect(t *testing.T) {
+	hub := NewSSEHub(30*time.Second, WithLimits(2, 0), WithOverflow(Disconnect, 3*time.Second))
+	slow, _ := hub.Subscribe("user-1")
+	fast, _ := hub.Subscribe("user-1")
+
+	evictions := observability.SSEEvictions.WithLabelValues("flood")
+	before := testutil.ToFloat64(evictions)
+	for i := range 2 {
+		hub.Send("user-1", SSEEvent{Event: "flood", Data: i})
+		<-fast
+	}
+	hub.Send("user-1", SSEEvent{Event: "flood", Data: 2})
+
+	// The slow client's backlog is replaced by a retry hint, then it closes.
+	got, open := <-slow
+	if !open || got.Event != EventReconnect || got.Data != (Reconnect{Retry: 3000}) {
+		t.Errorf("first event = %+v, want a reconnect hint", got)
+	}
+	if _, open := <-slow; open {
+		t.Error("slow client still open")
+	}
+	if e := <-fast; e.Data != 2 {
+		t.Errorf("fast client got %v, want 2", e.Data)
+	}
+	if hub.ConnectedClients() != 1 {
+		t.Errorf("ConnectedClients = %d, want 1", hub.ConnectedClients())
+	}
+	if d := testutil.ToFloat64(evictions) - before; d != 1 {
+		t.Errorf("counted %v evictions, want 1", d)
+	}
+
+	// The stream's own cleanup after eviction is a no-op.
+	hub.Unsubscribe("user-1", slow)
+	hub.Unsubscribe("user-1", fast)
+	if hub.ConnectedUsers() != 0 {
+		t.Errorf("ConnectedUsers = %d, want 0", hub.ConnectedUsers())
+	}
+}
+
+func TestSSEHub_RegisterEventPoliciesPanics(t *testing.T) {
+	for name, p := range map[string]EventPolicy{
+		"duplicate":       {Event: "cursor", Overflow: DropOldest},
+		"no event":        {Overflow: DropOldest},
+		"unknown policy":  {Event: "typing", Overflow: "drop_all"},
+		"coalesce no key": {Event: "typing", Overflow: Coalesce},
+	} {
+		t.Run(name, func(t *testing.T) {
+			hub := NewSSEHub(30 * time.Second)
+			hub.RegisterEventPolicies(EventPolicy{Event: "cursor", Overflow: DropNewest})
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			hub.RegisterEventPolicies(p)
+		})
+	}
+}
+
+func TestParseOverflowPolicy(t *testing.T) {
+	if p, err := ParseOverflowPolicy("drop_oldest"); err != nil || p != DropOldest {
+		t.Errorf("ParseOverflowPolicy(drop_oldest) = %q, %v", p, err)
+	}
+	if _, err := ParseOverflowPolicy("drop"); err == nil {
+		t.Error("expected error for unknown policy")
+	}
+}
+
+func TestPresenceUser(t *testing.T) {
+	local := SSEEvent{Event: EventPresence, Data: Presence{UserID: "user-1", Online: true}}
+	remote := SSEEvent{Event: EventPresence, Data: json.RawMessage(`{"user_id":"user-1","online":false}`)}
+	if presenceUser(local) != "user-1" || presenceUser(remote) != "user-1" {
+		t.Errorf("keys = %q, %q, want user-1", presenceUser(local), presenceUser(remote))
+	}
+}
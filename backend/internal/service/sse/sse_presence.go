@@ -0,0 +1,438 @@
+package sse
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/golid-ai/golid/backend/internal/logger"
+)
+
+const (
+	// DefaultPresenceTTL is how long a user stays online after their
+	// instance last confirmed the connection, when not configured.
+	// Instances confirm every third of it, so only a crashed instance's
+	// users ever reach it.
+	DefaultPresenceTTL = 90 * time.Second
+	// DefaultPresenceGrace is how long a user whose last connection closed
+	// has to reconnect before they are reported offline, when not
+	// configured.
+	DefaultPresenceGrace = 10 * time.Second
+
+	// How long an offline user's last-seen time is kept — presence is for
+	// "recently active", not an audit log; not environment-specific.
+	presenceRetention = 24 * time.Hour
+
+	presenceKeyPrefix = "sse:presence:"
+)
+
+// EventPresence is published to a topic when a subscriber comes online or
+// goes offline. Its data is a Presence.
+const EventPresence = "presence"
+
+// Presence is a user's presence in a topic.
+type Presence struct {
+	UserID   string    `json:"user_id"`
+	Online   bool      `json:"online"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+// PresenceStore records which users are connected to which topics, on
+// which instance. Entries expire unless refreshed, so users connected to
+// an instance that crashed drop offline after the TTL.
+type PresenceStore interface {
+	// Join records that userID is connected to topic on instance for ttl.
+	// It reports whether no instance had the user online before, and is
+	// also how an instance refreshes its entries.
+	Join(ctx context.Context, topic, userID, instance string, ttl time.Duration) (first bool, err error)
+	// Leave removes instance's entry and reports whether no instance has
+	// the user online any more.
+	Leave(ctx context.Context, topic, userID, instance string) (last bool, err error)
+	// List returns the topic's online users and those seen within the
+	// retention period, most recently seen first.
+	List(ctx context.Context, topic string) ([]Presence, error)
+}
+
+// WithPresence sets where presence is recorded, how long an entry lives
+// without a refresh (DefaultPresenceTTL if not positive), and how long a
+// disconnected user has to reconnect before going offline.
+func WithPresence(s PresenceStore, ttl, grace time.Duration) Option {
+	if ttl <= 0 {
+		ttl = DefaultPresenceTTL
+	}
+	return func(h *SSEHub) {
+		h.presence = s
+		h.presenceTTL = ttl
+		h.presenceGrace = grace
+	}
+}
+
+type presenceKey struct {
+	topic  string
+	userID string
+}
+
+// Presence returns who is, and recently was, subscribed to topic.
+func (h *SSEHub) Presence(ctx context.Context, topic string) ([]Presence, error) {
+	return h.presence.List(ctx, topic)
+}
+
+// arriveLocked counts a new connection for userID in each topic and
+// returns the topics where the user was not already present on this
+// instance. A reconnect within the grace period cancels the pending leave
+// and is not returned, so flapping produces no events. Must be called
+// with mu held.
+func (h *SSEHub) arriveLocked(userID string, topics []string) []string {
+	var arrived []string
+	for _, topic := range topics {
+		key := presenceKey{topic, userID}
+		h.present[key]++
+		if h.present[key] > 1 {
+			continue
+		}
+		if t, ok := h.leaving[key]; ok {
+			t.Stop()
+			delete(h.leaving, key)
+			continue
+		}
+		arrived = append(arrived, topic)
+	}
+	return arrived
+}
+
+// departLocked uncounts a closed connection and starts the grace period
+// for topics the user no longer has connections to. Must be called with
+// mu held.
+func (h *SSEHub) departLocked(userID string, topics []string) {
+	for _, topic := range topics {
+		key := presenceKey{topic, userID}
+		if h.present[key]--; h.present[key] > 0 {
+			continue
+		}
+		delete(h.present, key)
+		h.leaving[key] = time.AfterFunc(h.presenceGrace, func() { h.depart(key) })
+	}
+}
+
+// arrive records the user as present and announces them to topics where
+// no instance had them online.
+func (h *SSEHub) arrive(userID string, topics []string) {
+	for _, topic := range topics {
+		ctx, cancel := context.WithTimeout(context.Background(), brokerTimeout)
+		first, err := h.presence.Join(ctx, topic, userID, h.origin, h.presenceTTL)
+		cancel()
+		if err != nil {
+			logger.Error("failed to record SSE presence",
+				slog.String("topic", topic),
+				slog.String("user_id", userID),
+				slog.String("error", err.Error()),
+			)
+			continue
+		}
+		if first {
+			h.Publish(topic, SSEEvent{Event: EventPresence, Data: Presence{UserID: userID, Online: true, LastSeen: time.Now().UTC()}})
+		}
+	}
+}
+
+// depart ends the grace period: unless the user reconnected, their entry
+// is removed and, if no other instance has them, they are announced
+// offline.
+func (h *SSEHub) depart(key presenceKey) {
+	h.mu.Lock()
+	if h.present[key] > 0 || h.leaving[key] == nil {
+		h.mu.Unlock()
+		return
+	}
+	delete(h.leaving, key)
+	h.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), brokerTimeout)
+	defer cancel()
+	last, err := h.presence.Leave(ctx, key.topic, key.userID, h.origin)
+	if err != nil {
+		logger.Error("failed to clear SSE presence",
+			slog.String("topic", key.topic),
+			slog.String("user_id", key.userID),
+			slog.String("error", err.Error()),
+		)
+		return
+	}
+	if last {
+		h.Publish(key.topic, SSEEvent{Event: EventPresence, Data: Presence{UserID: key.userID, LastSeen: time.Now().UTC()}})
+	}
+}
+
+// heartbeatLoop refreshes this instance's presence entries, including
+// those in their grace period, until done is closed.
+func (h *SSEHub) heartbeatLoop(done <-chan struct{}) {
+	ticker := time.NewTicker(h.presenceTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			h.heartbeat()
+		case <-done:
+			return
+		}
+	}
+}
+
+func (h *SSEHub) heartbeat() {
+	h.mu.RLock()
+	keys := make([]presenceKey, 0, len(h.present)+len(h.leaving))
+	for key := range h.present {
+		keys = append(keys, key)
+	}
+	for key := range h.leaving {
+		keys = append(keys, key)
+	}
+	h.mu.RUnlock()
+
+	for _, key := range keys {
+		ctx, cancel := context.WithTimeout(context.Background(), brokerTimeout)
+		_, err := h.presence.Join(ctx, key.topic, key.userID, h.origin, h.presenceTTL)
+		cancel()
+		if err != nil {
+			logger.Error("failed to refresh SSE presence",
+				slog.String("topic", key.topic),
+				slog.String("error", err.Error()),
+			)
+			return
+		}
+	}
+}
+
+// presenceUser keys presence events by user, so a client that falls
+// behind gets only each user's latest change.
+func presenceUser(event SSEEvent) string {
+	switch data := event.Data.(type) {
+	case Presence:
+		return data.UserID
+	case json.RawMessage:
+		var p Presence
+		_ = json.Unmarshal(data, &p) //nolint:errcheck // unkeyed events coalesce together
+		return p.UserID
+	}
+	return ""
+}
+
+func sortPresence(list []Presence) {
+	slices.SortFunc(list, func(a, b Presence) int {
+		if c := b.LastSeen.Compare(a.LastSeen); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.UserID, b.UserID)
+	})
+}
+
+// MemoryPresenceStore records presence in process memory, for a single
+// instance.
+type MemoryPresenceStore struct {
+	mu   sync.Mutex
+	now  func() time.Time
+	live map[presenceKey]map[string]time.Time // instance → expiry
+	seen map[string]map[string]time.Time      // topic → user → last seen
+}
+
+func NewMemoryPresenceStore() *MemoryPresenceStore {
+	return newMemoryPresenceStore(time.Now)
+}
+
+func newMemoryPresenceStore(now func() time.Time) *MemoryPresenceStore {
+	return &MemoryPresenceStore{
+		now:  now,
+		live: make(map[presenceKey]map[string]time.Time),
+		seen: make(map[string]map[string]time.Time),
+	}
+}
+
+func (m *MemoryPresenceStore) Join(_ context.Context, topic, userID, instance string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	key := presenceKey{topic, userID}
+	instances := m.liveLocked(key, now)
+	if instances == nil {
+		instances = make(map[string]time.Time)
+		m.live[key] = instances
+	}
+	first := len(instances) == 0
+	instances[instance] = now.Add(ttl)
+	m.seeLocked(topic, userID, now)
+	return first, nil
+}
+
+func (m *MemoryPresenceStore) Leave(_ context.Context, topic, userID, instance string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	key := presenceKey{topic, userID}
+	instances := m.liveLocked(key, now)
+	delete(instances, instance)
+	if len(instances) == 0 {
+		delete(m.live, key)
+	}
+	m.seeLocked(topic, userID, now)
+	return len(instances) == 0, nil
+}
+
+func (m *MemoryPresenceStore) List(_ context.Context, topic string) ([]Presence, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	list := make([]Presence, 0, len(m.seen[topic]))
+	for userID, seen := range m.seen[topic] {
+		if now.Sub(seen) > presenceRetention {
+			delete(m.seen[topic], userID)
+			continue
+		}
+		online := len(m.liveLocked(presenceKey{topic, userID}, now)) > 0
+		list = append(list, Presence{UserID: userID, Online: online, LastSeen: seen.UTC()})
+	}
+	if len(m.seen[topic]) == 0 {
+		delete(m.seen, topic)
+	}
+	sortPresence(list)
+	return list, nil
+}
+
+// liveLocked returns key's unexpired instances, dropping expired ones.
+// Must be called with mu held.
+func (m *MemoryPresenceStore) liveLocked(key presenceKey, now time.Time) map[string]time.Time {
+	instances := m.live[key]
+	for instance, expiry := range instances {
+		if !now.Before(expiry) {
+			delete(instances, instance)
+		}
+	}
+	return instances
+}
+
+// seeLocked records userID as seen now. Must be called with mu held.
+func (m *MemoryPresenceStore) seeLocked(topic, userID string, now time.Time) {
+	if m.seen[topic] == nil {
+		m.seen[topic] = make(map[string]time.Time)
+	}
+	m.seen[topic][userID] = now
+}
+
+// joinScript adds or refreshes the instance's entry, and reports whether
+// the user had no unexpired entry before. The live set holds each user's
+// latest expiry, so List reads one key per topic.
+//
+// KEYS: instances, live, seen. ARGV: instance, user, now ms, expiry ms,
+// TTL ms, retention cutoff ms, retention ms.
+var joinScript = redis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[3])
+local first = redis.call('ZCARD', KEYS[1]) == 0
+redis.call('ZADD', KEYS[1], ARGV[4], ARGV[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[5])
+local top = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')[2]
+redis.call('ZADD', KEYS[2], top, ARGV[2])
+redis.call('PEXPIRE', KEYS[2], ARGV[5])
+redis.call('ZADD', KEYS[3], ARGV[3], ARGV[2])
+redis.call('ZREMRANGEBYSCORE', KEYS[3], '-inf', ARGV[6])
+redis.call('PEXPIRE', KEYS[3], ARGV[7])
+if first then return 1 end
+return 0
+`)
+
+// leaveScript removes the instance's entry and reports whether the user
+// has no unexpired entry left.
+//
+// KEYS: instances, live, seen. ARGV: instance, user, now ms, retention ms.
+var leaveScript = redis.NewScript(`
+redis.call('ZREM', KEYS[1], ARGV[1])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[3])
+redis.call('ZADD', KEYS[3], ARGV[3], ARGV[2])
+redis.call('PEXPIRE', KEYS[3], ARGV[4])
+if redis.call('ZCARD', KEYS[1]) > 0 then
+  local top = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')[2]
+  redis.call('ZADD', KEYS[2], top, ARGV[2])
+  return 0
+end
+redis.call('ZREM', KEYS[2], ARGV[2])
+return 1
+`)
+
+// RedisPresenceStore records presence in Redis, shared by every instance.
+type RedisPresenceStore struct {
+	client *redis.Client
+	now    func() time.Time
+}
+
+func NewRedisPresenceStore(client *redis.Client) *RedisPresenceStore {
+	return &RedisPresenceStore{client: client, now: time.Now}
+}
+
+// presenceKeys prefixes rather than suffixes the topic, since topic names
+// may contain colons; user IDs do not.
+func presenceKeys(topic, userID string) (instances, live, seen string) {
+	return presenceKeyPrefix + "inst:" + userID + ":" + topic,
+		presenceKeyPrefix + "live:" + topic,
+		presenceKeyPrefix + "seen:" + topic
+}
+
+func (r *RedisPresenceStore) Join(ctx context.Context, topic, userID, instance string, ttl time.Duration) (bool, error) {
+	instances, live, seen := presenceKeys(topic, userID)
+	now := r.now()
+	first, err := joinScript.Run(ctx, r.client, []string{instances, live, seen},
+		instance, userID, now.UnixMilli(), now.Add(ttl).UnixMilli(), ttl.Milliseconds(),
+		now.Add(-presenceRetention).UnixMilli(), presenceRetention.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("join presence: %w", err)
+	}
+	return first == 1, nil
+}
+
+func (r *RedisPresenceStore) Leave(ctx context.Context, topic, userID, instance string) (bool, error) {
+	instances, live, seen := presenceKeys(topic, userID)
+	last, err := leaveScript.Run(ctx, r.client, []string{instances, live, seen},
+		instance, userID, r.now().UnixMilli(), presenceRetention.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("leave presence: %w", err)
+	}
+	return last == 1, nil
+}
+
+func (r *RedisPresenceStore) List(ctx context.Context, topic string) ([]Presence, error) {
+	_, live, seen := presenceKeys(topic, "")
+	now := r.now().UnixMilli()
+	cutoff := strconv.FormatInt(now-presenceRetention.Milliseconds(), 10)
+
+	pipe := r.client.Pipeline()
+	pipe.ZRemRangeByScore(ctx, seen, "-inf", cutoff)
+	online := pipe.ZRangeByScore(ctx, live, &redis.ZRangeBy{Min: "(" + strconv.FormatInt(now, 10), Max: "+inf"})
+	seenAt := pipe.ZRangeWithScores(ctx, seen, 0, -1)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return nil, fmt.Errorf("list presence: %w", err)
+	}
+
+	isOnline := make(map[string]bool, len(online.Val()))
+	for _, userID := range online.Val() {
+		isOnline[userID] = true
+	}
+	list := make([]Presence, 0, len(seenAt.Val()))
+	for _, z := range seenAt.Val() {
+		userID, _ := z.Member.(string)
+		list = append(list, Presence{
+			UserID:   userID,
+			Online:   isOnline[userID],
+			LastSeen: time.UnixMilli(int64(z.Score)).UTC(),
+		})
+	}
+	sortPresence(list)
+	return list, nil
+}
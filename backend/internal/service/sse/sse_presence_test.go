@@ -0,0 +1,149 @@
+package sse
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// presenceStores returns each PresenceStore implementation on a shared
+// fake clock.
+func presenceStores(t *testing.T) map[string]func(*fakeClock) PresenceStore {
+	return map[string]func(*fakeClock) PresenceStore{
+		"memory": func(c *fakeClock) PresenceStore {
+			return newMemoryPresenceStore(c.now)
+		},
+		"redis": func(c *fakeClock) PresenceStore {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			t.Cleanup(func() { _ = client.Close() })
+			s := NewRedisPresenceStore(client)
+			s.now = c.now
+			return s
+		},
+	}
+}
+
+func onlineUsers(t *testing.T, s PresenceStore, topic string) map[string]bool {
+	t.Helper()
+	list, err := s.List(context.Background(), topic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	online := make(map[string]bool)
+	for _, p := range list {
+		online[p.UserID] = p.Online
+	}
+	return online
+}
+
+func TestPresenceStore(t *testing.T) {
+	for name, newStore := range presenceStores(t) {
+		t.Run(name, func(t *testing.T) {
+			clock := &fakeClock{t: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+			s := newStore(clock)
+			ctx := context.Background()
+			ttl := time.Minute
+
+			// The user is online while either instance has them.
+			if first, _ := s.Join(ctx, "org:1", "u1", "a", ttl); !first {
+				t.Error("first join should report first")
+			}
+			if first, _ := s.Join(ctx, "org:1", "u1", "b", ttl); first {
+				t.Error("join on a second instance should not report first")
+			}
+			if last, _ := s.Leave(ctx, "org:1", "u1", "a"); last {
+				t.Error("leave with another instance online should not report last")
+			}
+			if got := onlineUsers(t, s, "org:1"); !got["u1"] {
+				t.Errorf("presence = %v, want u1 online", got)
+			}
+			clock.advance(time.Second)
+			if last, _ := s.Leave(ctx, "org:1", "u1", "b"); !last {
+				t.Error("leaving the last instance should report last")
+			}
+
+			list, _ := s.List(ctx, "org:1")
+			if len(list) != 1 || list[0].Online || !list[0].LastSeen.Equal(clock.t) {
+				t.Errorf("after leave = %+v, want u1 offline, last seen now", list)
+			}
+			if got := onlineUsers(t, s, "org:2"); len(got) != 0 {
+				t.Errorf("other topic = %v, want empty", got)
+			}
+
+			// An instance that stops refreshing (crashed) drops its users
+			// after the TTL, without a Leave.
+			s.Join(ctx, "org:1", "u2", "crashed", ttl) //nolint:errcheck // checked via List
+			clock.advance(ttl)
+			if got := onlineUsers(t, s, "org:1"); got["u2"] {
+				t.Error("u2 should expire with its instance")
+			}
+			if first, _ := s.Join(ctx, "org:1", "u2", "a", ttl); !first {
+				t.Error("joining after expiry should report first")
+			}
+
+			// Last-seen times are kept for the retention period.
+			clock.advance(presenceRetention + time.Minute)
+			if got := onlineUsers(t, s, "org:1"); len(got) != 0 {
+				t.Errorf("after retention = %v, want empty", got)
+			}
+		})
+	}
+}
+
+// presenceEvents returns the presence changes buffered on ch.
+func presenceEvents(ch chan SSEEvent) []Presence {
+	var out []Presence
+	for {
+		select {
+		case e := <-ch:
+			if p, ok := e.Data.(Presence); ok && e.Event == EventPresence {
+				out = append(out, p)
+			}
+		default:
+			return out
+		}
+	}
+}
+
+func TestSSEHub_PresenceDebounce(t *testing.T) {
+	hub := NewSSEHub(30*time.Second, WithPresence(NewMemoryPresenceStore(), time.Minute, 50*time.Millisecond))
+	defer hub.Shutdown()
+	watcher, _ := hub.Subscribe("watcher", "org:1")
+	drain(watcher)
+
+	ch, _ := hub.Subscribe("user-1", "org:1")
+	if got := presenceEvents(watcher); len(got) != 1 || got[0].UserID != "user-1" || !got[0].Online {
+		t.Fatalf("join events = %+v, want user-1 online", got)
+	}
+
+	// A reconnect within the grace period is invisible.
+	hub.Unsubscribe("user-1", ch)
+	ch, _ = hub.Subscribe("user-1", "org:1")
+	time.Sleep(100 * time.Millisecond)
+	if got := presenceEvents(watcher); len(got) != 0 {
+		t.Errorf("flap produced events %+v", got)
+	}
+
+	hub.Unsubscribe("user-1", ch)
+	if got := presenceEvents(watcher); len(got) != 0 {
+		t.Errorf("leave announced before the grace period: %+v", got)
+	}
+	deadline := time.Now().Add(time.Second)
+	var got []Presence
+	for len(got) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		got = presenceEvents(watcher)
+	}
+	if len(got) != 1 || got[0].UserID != "user-1" || got[0].Online {
+		t.Errorf("leave events = %+v, want user-1 offline", got)
+	}
+
+	list, _ := hub.Presence(context.Background(), "org:1")
+	if len(list) != 2 {
+		t.Errorf("Presence = %+v, want watcher and user-1", list)
+	}
+}
@@ -0,0 +1,284 @@
+package sse
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// DefaultReplaySize is how many events per stream a replay store holds
+	// when not configured.
+	DefaultReplaySize = 100
+	// DefaultReplayTTL is how long a reconnecting client can have been gone
+	// and still be caught up when not configured.
+	DefaultReplayTTL = 5 * time.Minute
+
+	replayKeyPrefix = "sse:replay:"
+	replaySeqKey    = replayKeyPrefix + "seq"
+
+	broadcastStream = "all"
+)
+
+// userStream and topicStream name the replay streams for a user's events
+// and a topic's. Broadcasts share broadcastStream.
+func userStream(userID string) string { return "u:" + userID }
+func topicStream(topic string) string { return "t:" + topic }
+
+// ReplayStore assigns event IDs and holds recent events so a reconnecting
+// client can be sent what it missed. Events are kept per stream: one per
+// user, one per topic, and one for broadcasts.
+//
+// IDs increase monotonically across all users and never fall behind the
+// clock: each is at least the current Unix time in milliseconds times
+// 1000. That lets a store tell, from the ID alone, whether a client has
+// been gone longer than it keeps history.
+type ReplayStore interface {
+	// Append assigns the next ID to event and records it on stream.
+	Append(ctx context.Context, stream string, event SSEEvent) (uint64, error)
+	// Since returns the events on any of streams after lastID.
+	Since(ctx context.Context, streams []string, lastID uint64) (Replay, error)
+}
+
+// Replay is the result of ReplayStore.Since.
+type Replay struct {
+	// Events are the requested streams' events after the requested ID,
+	// oldest first.
+	Events []SSEEvent
+	// Complete is false when events after the requested ID may have been
+	// evicted or expired, or the ID was not issued by this store. The
+	// client must then refetch its state.
+	Complete bool
+	// Latest is the most recently assigned ID.
+	Latest uint64
+}
+
+// idAt is the smallest ID assigned at t.
+func idAt(t time.Time) uint64 {
+	return uint64(t.UnixMilli()) * 1000 //nolint:gosec // Unix time is positive
+}
+
+// replayFloor returns the oldest lastID a store can still serve in full:
+// anything at or after the latest eviction (floor) and no older than ttl.
+func replayFloor(now time.Time, ttl time.Duration, floor uint64) uint64 {
+	return max(floor, idAt(now.Add(-ttl)))
+}
+
+type replayBuffer struct {
+	events  []SSEEvent
+	floor   uint64 // ID of the newest evicted event
+	written time.Time
+}
+
+// MemoryReplayStore holds history in process memory. History does not
+// survive a restart or reach other instances: clients reconnecting with an
+// ID from before this store started are told to reset.
+type MemoryReplayStore struct {
+	mu        sync.Mutex
+	size      int
+	ttl       time.Duration
+	now       func() time.Time
+	start     uint64
+	last      uint64
+	buffers   map[string]*replayBuffer // by stream
+	lastSweep time.Time
+}
+
+// NewMemoryReplayStore keeps up to size events per stream for ttl after
+// the stream's last event.
+func NewMemoryReplayStore(size int, ttl time.Duration) *MemoryReplayStore {
+	return newMemoryReplayStore(size, ttl, time.Now)
+}
+
+func newMemoryReplayStore(size int, ttl time.Duration, now func() time.Time) *MemoryReplayStore {
+	started := now()
+	return &MemoryReplayStore{
+		size:      size,
+		ttl:       ttl,
+		now:       now,
+		start:     idAt(started),
+		buffers:   make(map[string]*replayBuffer),
+		lastSweep: started,
+	}
+}
+
+func (m *MemoryReplayStore) Append(_ context.Context, stream string, event SSEEvent) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweepLocked(now)
+
+	m.last = max(m.last+1, idAt(now))
+	event.ID = m.last
+
+	b := m.buffers[stream]
+	if b == nil {
+		b = &replayBuffer{}
+		m.buffers[stream] = b
+	}
+	b.events = append(b.events, event)
+	if over := len(b.events) - m.size; over > 0 {
+		b.floor = b.events[over-1].ID
+		b.events = slices.Clone(b.events[over:])
+	}
+	b.written = now
+	return event.ID, nil
+}
+
+func (m *MemoryReplayStore) Since(_ context.Context, streams []string, lastID uint64) (Replay, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	r := Replay{Latest: m.last, Complete: lastID >= m.start && lastID <= m.last}
+	for _, stream := range streams {
+		b := m.buffers[stream]
+		if b == nil || now.Sub(b.written) > m.ttl {
+			r.Complete = r.Complete && lastID >= replayFloor(now, m.ttl, 0)
+			continue
+		}
+		r.Complete = r.Complete && lastID >= replayFloor(now, m.ttl, b.floor)
+		for _, e := range b.events {
+			if e.ID > lastID {
+				r.Events = append(r.Events, e)
+			}
+		}
+	}
+	sortByID(r.Events)
+	return r, nil
+}
+
+// sweepLocked drops buffers idle for longer than the TTL, at most once per
+// TTL. Must be called with mu held.
+func (m *MemoryReplayStore) sweepLocked(now time.Time) {
+	if now.Sub(m.lastSweep) < m.ttl {
+		return
+	}
+	m.lastSweep = now
+	for key, b := range m.buffers {
+		if now.Sub(b.written) > m.ttl {
+			delete(m.buffers, key)
+		}
+	}
+}
+
+func sortByID(events []SSEEvent) {
+	slices.SortFunc(events, func(a, b SSEEvent) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+}
+
+// appendScript assigns the next ID and pushes "<id> <payload>" onto the
+// buffer, trimming it to size and recording the newest evicted ID as the
+// floor. IDs are formatted with %.0f because Lua numbers are doubles and
+// tostring would use exponent notation.
+//
+// KEYS: seq, buffer, floor. ARGV: minimum ID, payload, size, TTL in ms.
+var appendScript = redis.NewScript(`
+local last = tonumber(redis.call('GET', KEYS[1]) or '0')
+local id = string.format('%.0f', math.max(last + 1, tonumber(ARGV[1])))
+redis.call('SET', KEYS[1], id)
+redis.call('RPUSH', KEYS[2], id .. ' ' .. ARGV[2])
+local size = tonumber(ARGV[3])
+local n = redis.call('LLEN', KEYS[2])
+if n > size then
+  local evicted = redis.call('LINDEX', KEYS[2], n - size - 1)
+  redis.call('SET', KEYS[3], string.sub(evicted, 1, string.find(evicted, ' ') - 1), 'PX', ARGV[4])
+  redis.call('LTRIM', KEYS[2], n - size, -1)
+end
+redis.call('PEXPIRE', KEYS[2], ARGV[4])
+redis.call('PEXPIRE', KEYS[3], ARGV[4])
+return id
+`)
+
+// RedisReplayStore holds history in Redis, shared by every instance. Each
+// stream's buffer expires ttl after its last event.
+type RedisReplayStore struct {
+	client *redis.Client
+	size   int
+	ttl    time.Duration
+	now    func() time.Time
+}
+
+func NewRedisReplayStore(client *redis.Client, size int, ttl time.Duration) *RedisReplayStore {
+	return &RedisReplayStore{client: client, size: size, ttl: ttl, now: time.Now}
+}
+
+type storedEvent struct {
+	Event string          `json:"event"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// bufferKeys prefixes rather than suffixes the stream, since topic names
+// may contain colons.
+func bufferKeys(stream string) (buffer, floor string) {
+	return replayKeyPrefix + "buf:" + stream, replayKeyPrefix + "floor:" + stream
+}
+
+func (r *RedisReplayStore) Append(ctx context.Context, stream string, event SSEEvent) (uint64, error) {
+	data, err := event.MarshalData()
+	if err != nil {
+		return 0, fmt.Errorf("marshal event: %w", err)
+	}
+	payload, err := json.Marshal(storedEvent{Event: event.Event, Data: data})
+	if err != nil {
+		return 0, fmt.Errorf("marshal event: %w", err)
+	}
+	buffer, floor := bufferKeys(stream)
+	id, err := appendScript.Run(ctx, r.client, []string{replaySeqKey, buffer, floor},
+		idAt(r.now()), payload, r.size, r.ttl.Milliseconds()).Text()
+	if err != nil {
+		return 0, fmt.Errorf("append event: %w", err)
+	}
+	return strconv.ParseUint(id, 10, 64)
+}
+
+func (r *RedisReplayStore) Since(ctx context.Context, streams []string, lastID uint64) (Replay, error) {
+	pipe := r.client.Pipeline()
+	seq := pipe.Get(ctx, replaySeqKey)
+	buffers := make([]*redis.StringSliceCmd, len(streams))
+	floors := make([]*redis.StringCmd, len(streams))
+	for i, stream := range streams {
+		buffer, floor := bufferKeys(stream)
+		buffers[i] = pipe.LRange(ctx, buffer, 0, -1)
+		floors[i] = pipe.Get(ctx, floor)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return Replay{}, fmt.Errorf("read replay: %w", err)
+	}
+
+	var replay Replay
+	if s, err := seq.Result(); err == nil {
+		replay.Latest, _ = strconv.ParseUint(s, 10, 64) //nolint:errcheck // written by appendScript
+	}
+	now := r.now()
+	replay.Complete = lastID <= replay.Latest
+	for i := range buffers {
+		floor, _ := strconv.ParseUint(floors[i].Val(), 10, 64) //nolint:errcheck // missing floor is 0
+		replay.Complete = replay.Complete && lastID >= replayFloor(now, r.ttl, floor)
+		for _, entry := range buffers[i].Val() {
+			idStr, payload, _ := strings.Cut(entry, " ")
+			id, err := strconv.ParseUint(idStr, 10, 64)
+			if err != nil || id <= lastID {
+				continue
+			}
+			var e storedEvent
+			if err := json.Unmarshal([]byte(payload), &e); err != nil {
+				continue
+			}
+			replay.Events = append(replay.Events, SSEEvent{ID: id, Event: e.Event, Data: e.Data})
+		}
+	}
+	sortByID(replay.Events)
+	return replay, nil
+}
@@ -0,0 +1,155 @@
+package sse
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+type fakeClock struct{ t time.Time }
+
+func (c *fakeClock) now() time.Time          { return c.t }
+func (c *fakeClock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+// replayStores returns each ReplayStore implementation, holding 3 events
+// per buffer for a minute, on a shared fake clock.
+func replayStores(t *testing.T) map[string]func(*fakeClock) ReplayStore {
+	return map[string]func(*fakeClock) ReplayStore{
+		"memory": func(c *fakeClock) ReplayStore {
+			return newMemoryReplayStore(3, time.Minute, c.now)
+		},
+		"redis": func(c *fakeClock) ReplayStore {
+			mr := miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			t.Cleanup(func() { _ = client.Close() })
+			s := NewRedisReplayStore(client, 3, time.Minute)
+			s.now = c.now
+			return s
+		},
+	}
+}
+
+func appendAll(t *testing.T, s ReplayStore, stream string, names ...string) []uint64 {
+	t.Helper()
+	var ids []uint64
+	for _, name := range names {
+		id, err := s.Append(context.Background(), stream, SSEEvent{Event: name, Data: map[string]string{"n": name}})
+		if err != nil {
+			t.Fatalf("Append(%s) error = %v", name, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func eventNames(events []SSEEvent) []string {
+	var names []string
+	for _, e := range events {
+		names = append(names, e.Event)
+	}
+	return names
+}
+
+func TestReplayStore_Since(t *testing.T) {
+	for name, newStore := range replayStores(t) {
+		t.Run(name, func(t *testing.T) {
+			clock := &fakeClock{t: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+			s := newStore(clock)
+			ctx := context.Background()
+
+			user := appendAll(t, s, "u:1", "a", "b")
+			appendAll(t, s, "u:2", "other")
+			all := appendAll(t, s, "all", "broadcast")
+			mine := appendAll(t, s, "u:1", "c")
+			streams := []string{"u:1", "all"}
+
+			ids := append(append(user, all...), mine...)
+			for i := 1; i < len(ids); i++ {
+				if ids[i] <= ids[i-1] {
+					t.Fatalf("IDs not increasing: %v", ids)
+				}
+			}
+			if ids[0] < idAt(clock.t) {
+				t.Errorf("ID %d is behind the clock (%d)", ids[0], idAt(clock.t))
+			}
+
+			r, err := s.Since(ctx, streams, user[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := eventNames(r.Events)
+			if !r.Complete || len(got) != 3 || got[0] != "b" || got[1] != "broadcast" || got[2] != "c" {
+				t.Errorf("Since = %v complete=%v, want [b broadcast c]", got, r.Complete)
+			}
+			if r.Latest != mine[0] {
+				t.Errorf("Latest = %d, want %d", r.Latest, mine[0])
+			}
+			var data map[string]string
+			raw, _ := r.Events[0].MarshalData()
+			if err := json.Unmarshal(raw, &data); err != nil || data["n"] != "b" {
+				t.Errorf("replayed data = %s", raw)
+			}
+
+			if r, _ := s.Since(ctx, streams, mine[0]); !r.Complete || len(r.Events) != 0 {
+				t.Errorf("Since(latest) = %+v, want complete and empty", r)
+			}
+			if r, _ := s.Since(ctx, streams, mine[0]+1); r.Complete {
+				t.Error("an ID the store never issued should not be complete")
+			}
+		})
+	}
+}
+
+func TestReplayStore_Incomplete(t *testing.T) {
+	for name, newStore := range replayStores(t) {
+		t.Run(name, func(t *testing.T) {
+			clock := &fakeClock{t: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)}
+			s := newStore(clock)
+			ctx := context.Background()
+
+			// Five events into a buffer of three evicts the first two.
+			streams := []string{"u:1", "all"}
+			ids := appendAll(t, s, "u:1", "a", "b", "c", "d", "e")
+			if r, _ := s.Since(ctx, streams, ids[0]); r.Complete {
+				t.Error("event b was evicted; replay from a should be incomplete")
+			}
+			r, _ := s.Since(ctx, streams, ids[1])
+			if got := eventNames(r.Events); !r.Complete || len(got) != 3 || got[0] != "c" {
+				t.Errorf("Since(b) = %v complete=%v, want [c d e]", got, r.Complete)
+			}
+
+			// A client gone longer than the TTL may have missed events that
+			// expired with their buffer.
+			clock.advance(2 * time.Minute)
+			if r, _ := s.Since(ctx, streams, ids[4]); r.Complete {
+				t.Error("an ID older than the TTL should not be complete")
+			}
+			latest := appendAll(t, s, "u:2", "x")
+			if r, _ := s.Since(ctx, streams, latest[0]); !r.Complete {
+				t.Error("a recent ID with nothing missed should be complete")
+			}
+		})
+	}
+}
+
+func TestSSEHub_SendAssignsIDs(t *testing.T) {
+	hub := NewSSEHub(30 * time.Second)
+	ch, _ := hub.Subscribe("user-1")
+	defer hub.Unsubscribe("user-1", ch)
+
+	hub.Send("user-1", SSEEvent{Event: "first"})
+	hub.Broadcast(SSEEvent{Event: "second"})
+	first, second := <-ch, <-ch
+	if first.ID == 0 || second.ID <= first.ID {
+		t.Fatalf("IDs = %d, %d; want increasing and non-zero", first.ID, second.ID)
+	}
+
+	r, err := hub.Since("user-1", nil, first.ID)
+	if err != nil || !r.Complete || len(r.Events) != 1 || r.Events[0].ID != second.ID {
+		t.Errorf("Since = %+v, %v; want the broadcast", r, err)
+	}
+}
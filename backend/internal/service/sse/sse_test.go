@@ -97,9 +97,9 @@ func TestSSEHub_Broadcast(t *testing.T) {
 
 func TestSSEHub_MaxConnections(t *testing.T) {
 	hub := NewSSEHub(30 * time.Second)
-	channels := make([]chan SSEEvent, 0, sseMaxConnsPerUser)
+	channels := make([]chan SSEEvent, 0, DefaultMaxConnsPerUser)
 
-	for i := 0; i < sseMaxConnsPerUser; i++ {
+	for i := 0; i < DefaultMaxConnsPerUser; i++ {
 		ch, err := hub.Subscribe("user-1")
 		if err != nil {
 			t.Fatalf("Subscribe %d failed: %v", i, err)
@@ -113,8 +113,8 @@ func TestSSEHub_MaxConnections(t *testing.T) {
 		t.Error("expected error when exceeding max connections")
 	}
 
-	if hub.ConnectedClients() != sseMaxConnsPerUser {
-		t.Errorf("ConnectedClients = %d, want %d", hub.ConnectedClients(), sseMaxConnsPerUser)
+	if hub.ConnectedClients() != DefaultMaxConnsPerUser {
+		t.Errorf("ConnectedClients = %d, want %d", hub.ConnectedClients(), DefaultMaxConnsPerUser)
 	}
 
 	for _, ch := range channels {
@@ -127,7 +127,7 @@ func TestSSEHub_DropOnFull(t *testing.T) {
 	ch, _ := hub.Subscribe("user-1")
 
 	// Fill the buffer
-	for i := 0; i < sseChannelBuffer; i++ {
+	for i := 0; i < DefaultClientBuffer; i++ {
 		hub.Send("user-1", SSEEvent{Event: "fill", Data: i})
 	}
 
@@ -160,12 +160,12 @@ func TestSSEHub_Tickets(t *testing.T) {
 	}
 
 	// Validate should succeed and return the user ID
-	userID, err := hub.ValidateTicket(ticket)
+	grant, err := hub.ValidateTicket(ticket)
 	if err != nil {
 		t.Fatalf("ValidateTicket failed: %v", err)
 	}
-	if userID != "user-1" {
-		t.Errorf("userID = %q, want %q", userID, "user-1")
+	if grant.UserID != "user-1" {
+		t.Errorf("userID = %q, want %q", grant.UserID, "user-1")
 	}
 
 	// Second validate should fail (ticket is burned)
@@ -202,6 +202,39 @@ func TestSSEHub_Shutdown(t *testing.T) {
 	}
 }
 
+func TestSSEHub_Disconnect(t *testing.T) {
+	hub := NewSSEHub(30 * time.Second)
+
+	ch1, _ := hub.Subscribe("user-1")
+	ch2, _ := hub.Subscribe("user-1")
+	other, _ := hub.Subscribe("user-2")
+	ticket, _ := hub.CreateTicket("user-1")
+
+	hub.Send("user-1", SSEEvent{Event: "account_suspended"})
+	hub.Disconnect("user-1")
+
+	for _, ch := range []chan SSEEvent{ch1, ch2} {
+		if ev, open := <-ch; !open || ev.Event != "account_suspended" {
+			t.Errorf("first receive = %q (open=%v), want buffered account_suspended", ev.Event, open)
+		}
+		if _, open := <-ch; open {
+			t.Error("channel should be closed after disconnect")
+		}
+	}
+
+	if _, err := hub.ValidateTicket(ticket); err == nil {
+		t.Error("ticket should be burned after disconnect")
+	}
+	if hub.ConnectedUsers() != 1 || hub.ConnectedClients() != 1 {
+		t.Errorf("ConnectedUsers = %d, ConnectedClients = %d, want 1 and 1",
+			hub.ConnectedUsers(), hub.ConnectedClients())
+	}
+
+	// Unsubscribe after Disconnect must not double-close.
+	hub.Unsubscribe("user-1", ch1)
+	hub.Unsubscribe("user-2", other)
+}
+
 func TestSSEEvent_MarshalData(t *testing.T) {
 	event := SSEEvent{
 		Event: "notification",
@@ -0,0 +1,117 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Grant is what a ticket entitles its holder to: a stream of the user's
+// events, broadcasts, and the topics authorized when it was issued.
+type Grant struct {
+	UserID string   `json:"user_id"`
+	Topics []string `json:"topics,omitempty"`
+}
+
+// TicketStore holds one-time connection tickets.
+type TicketStore interface {
+	// Put stores a ticket for grant that expires after ttl.
+	Put(ctx context.Context, ticket string, grant Grant, ttl time.Duration) error
+	// Take burns the ticket and returns its grant, or an error if it is
+	// unknown, already used, or expired.
+	Take(ctx context.Context, ticket string) (Grant, error)
+	// Revoke burns every unused ticket for userID.
+	Revoke(ctx context.Context, userID string) error
+}
+
+type sseTicket struct {
+	Grant     Grant
+	ExpiresAt time.Time
+}
+
+// memoryTicketStore is the default TicketStore: tickets are only valid on
+// the instance that issued them.
+type memoryTicketStore struct {
+	mu      sync.Mutex
+	tickets map[string]sseTicket
+}
+
+func newMemoryTicketStore() *memoryTicketStore {
+	return &memoryTicketStore{tickets: make(map[string]sseTicket)}
+}
+
+func (m *memoryTicketStore) Put(_ context.Context, ticket string, grant Grant, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.cleanExpiredLocked()
+	m.tickets[ticket] = sseTicket{
+		Grant:     grant,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	return nil
+}
+
+func (m *memoryTicketStore) Take(_ context.Context, ticket string) (Grant, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tickets[ticket]
+	if !ok {
+		return Grant{}, fmt.Errorf("invalid ticket")
+	}
+
+	delete(m.tickets, ticket)
+
+	if time.Now().After(t.ExpiresAt) {
+		return Grant{}, fmt.Errorf("ticket expired")
+	}
+
+	return t.Grant, nil
+}
+
+func (m *memoryTicketStore) Revoke(_ context.Context, userID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for ticket, t := range m.tickets {
+		if t.Grant.UserID == userID {
+			delete(m.tickets, ticket)
+		}
+	}
+	return nil
+}
+
+func (m *memoryTicketStore) clear() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tickets = make(map[string]sseTicket)
+}
+
+// cleanExpiredLocked removes expired tickets. Must be called with mu held.
+func (m *memoryTicketStore) cleanExpiredLocked() {
+	now := time.Now()
+	for ticket, t := range m.tickets {
+		if now.After(t.ExpiresAt) {
+			delete(m.tickets, ticket)
+		}
+	}
+}
+
+// cleanupLoop periodically removes expired tickets even when no new
+// tickets are being created. Runs until done is closed.
+func (m *memoryTicketStore) cleanupLoop(interval time.Duration, done <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.mu.Lock()
+			m.cleanExpiredLocked()
+			m.mu.Unlock()
+		case <-done:
+			return
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package sse
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+)
+
+const (
+	// Per-stream cap on topics — bounds fan-out bookkeeping; not environment-specific.
+	sseMaxTopics   = 20
+	sseMaxTopicLen = 200
+)
+
+// topicKindPattern matches a topic kind: the part of "org:123" before the
+// first colon.
+var topicKindPattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Topic declares a kind of topic clients may subscribe to: Kind "org"
+// covers "org:123", "org:456", and so on.
+type Topic struct {
+	Kind string
+	// Authorize returns nil when userID may receive the events published to
+	// "<Kind>:<id>", or the error to send the client instead, typically
+	// apperror.Forbidden or apperror.NotFound. It runs when a ticket is
+	// issued, so access revoked later ends with the current stream.
+	Authorize func(ctx context.Context, userID, id string) error
+}
+
+// TopicDeclarer is implemented by services that publish to topics.
+type TopicDeclarer interface {
+	SSETopics() []Topic
+}
+
+// RegisterTopics declares topic kinds. Called during wiring, before any
+// request is served; panics on an invalid or duplicate declaration.
+func (h *SSEHub) RegisterTopics(topics ...Topic) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, t := range topics {
+		if !topicKindPattern.MatchString(t.Kind) {
+			panic(fmt.Sprintf("sse: invalid topic kind %q", t.Kind))
+		}
+		if t.Authorize == nil {
+			panic(fmt.Sprintf("sse: topic kind %q has no authorizer", t.Kind))
+		}
+		if _, dup := h.kinds[t.Kind]; dup {
+			panic(fmt.Sprintf("sse: duplicate topic kind %q", t.Kind))
+		}
+		h.kinds[t.Kind] = t
+	}
+}
+
+// AuthorizeTopics checks that userID may subscribe to every topic, each of
+// the form "<kind>:<id>" with a registered kind. It returns the first
+// authorizer's error, or a bad request for a malformed or unknown topic.
+func (h *SSEHub) AuthorizeTopics(ctx context.Context, userID string, topics []string) error {
+	if len(topics) > sseMaxTopics {
+		return apperror.BadRequest(fmt.Sprintf("at most %d topics per stream", sseMaxTopics)).WithMessageID("error.sse.too_many_topics", sseMaxTopics)
+	}
+	for _, topic := range topics {
+		kind, id, ok := strings.Cut(topic, ":")
+		if !ok || id == "" || len(topic) > sseMaxTopicLen {
+			return apperror.BadRequest(fmt.Sprintf("invalid topic %q", topic)).WithMessageID("error.sse.invalid_topic", topic)
+		}
+		h.mu.RLock()
+		t, ok := h.kinds[kind]
+		h.mu.RUnlock()
+		if !ok {
+			return apperror.BadRequest(fmt.Sprintf("unknown topic %q", topic)).WithMessageID("error.sse.unknown_topic", topic)
+		}
+		if err := t.Authorize(ctx, userID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Publish delivers an event to every connection subscribed to topic, on
+// every instance, and records it for replay. Non-blocking: slow clients
+// are handled by the event type's overflow policy.
+func (h *SSEHub) Publish(topic string, event SSEEvent) {
+	event.ID = h.record(topicStream(topic), event)
+	h.publish(topic, event)
+	h.fanoutEvent(message{Kind: kindPublish, Topic: topic}, event)
+}
+
+// publish delivers an event to the topic's subscribers on this instance.
+func (h *SSEHub) publish(topic string, event SSEEvent) {
+	var evicted []chan SSEEvent
+	h.mu.RLock()
+	for ch := range h.topics[topic] {
+		if h.deliverLocked(ch, event) {
+			evicted = append(evicted, ch)
+		}
+	}
+	h.mu.RUnlock()
+	h.evict(evicted)
+}
+
+// joinLocked subscribes ch, already registered, to topics. Must be called
+// with mu held.
+func (h *SSEHub) joinLocked(ch chan SSEEvent, topics []string) {
+	for _, topic := range topics {
+		if h.topics[topic] == nil {
+			h.topics[topic] = make(map[chan SSEEvent]struct{})
+		}
+		h.topics[topic][ch] = struct{}{}
+	}
+	h.conns[ch].topics = topics
+}
+
+// leaveLocked removes ch from its topics. Must be called with mu held.
+func (h *SSEHub) leaveLocked(userID string, ch chan SSEEvent) {
+	topics := h.conns[ch].topics
+	h.departLocked(userID, topics)
+	for _, topic := range topics {
+		delete(h.topics[topic], ch)
+		if len(h.topics[topic]) == 0 {
+			delete(h.topics, topic)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package sse
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+)
+
+func newTopicHub() *SSEHub {
+	hub := NewSSEHub(30 * time.Second)
+	hub.RegisterTopics(Topic{
+		Kind: "org",
+		Authorize: func(_ context.Context, userID, id string) error {
+			if userID == "member" && id == "1" {
+				return nil
+			}
+			return apperror.Forbidden("not a member")
+		},
+	})
+	return hub
+}
+
+func TestSSEHub_AuthorizeTopics(t *testing.T) {
+	hub := newTopicHub()
+	ctx := context.Background()
+
+	if err := hub.AuthorizeTopics(ctx, "member", []string{"org:1"}); err != nil {
+		t.Errorf("member: %v", err)
+	}
+	if err := hub.AuthorizeTopics(ctx, "member", nil); err != nil {
+		t.Errorf("no topics: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		topics []string
+		code   apperror.Code
+	}{
+		{"denied", []string{"org:2"}, apperror.CodeForbidden},
+		{"unknown kind", []string{"doc:1"}, apperror.CodeBadRequest},
+		{"no id", []string{"org:"}, apperror.CodeBadRequest},
+		{"no kind", []string{"org"}, apperror.CodeBadRequest},
+		{"too many", make([]string, sseMaxTopics+1), apperror.CodeBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := hub.AuthorizeTopics(ctx, "member", tt.topics)
+			var appErr *apperror.AppError
+			if !errors.As(err, &appErr) || appErr.Code != tt.code {
+				t.Errorf("err = %v, want %s", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestSSEHub_RegisterTopicsPanics(t *testing.T) {
+	allow := func(context.Context, string, string) error { return nil }
+	for name, topic := range map[string]Topic{
+		"duplicate":     {Kind: "org", Authorize: allow},
+		"invalid kind":  {Kind: "Org:x", Authorize: allow},
+		"no authorizer": {Kind: "doc"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			hub := newTopicHub()
+			defer func() {
+				if recover() == nil {
+					t.Error("expected panic")
+				}
+			}()
+			hub.RegisterTopics(topic)
+		})
+	}
+}
+
+// published returns the events in events other than presence changes.
+func published(events []SSEEvent) []SSEEvent {
+	var out []SSEEvent
+	for _, e := range events {
+		if e.Event != EventPresence {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// drain discards the events already buffered on ch.
+func drain(ch chan SSEEvent) {
+	for {
+		select {
+		case <-ch:
+		default:
+			return
+		}
+	}
+}
+
+func TestSSEHub_Publish(t *testing.T) {
+	hub := NewSSEHub(30 * time.Second)
+	both, _ := hub.Subscribe("user-1", "org:1", "doc:a")
+	org, _ := hub.Subscribe("user-2", "org:1")
+	none, _ := hub.Subscribe("user-3")
+	drain(both)
+	drain(org)
+
+	hub.Publish("org:1", SSEEvent{Event: "org_updated"})
+	hub.Publish("doc:a", SSEEvent{Event: "doc_updated"})
+
+	for _, want := range []string{"org_updated", "doc_updated"} {
+		if ev := <-both; ev.Event != want || ev.ID == 0 {
+			t.Errorf("user-1 got %q id=%d, want %s with an ID", ev.Event, ev.ID, want)
+		}
+	}
+	if ev := <-org; ev.Event != "org_updated" {
+		t.Errorf("user-2 got %q, want org_updated", ev.Event)
+	}
+	expectNone(t, org)
+	expectNone(t, none)
+
+	// Topic events are replayed only to streams that subscribed to the topic.
+	if r, _ := hub.Since("user-2", []string{"org:1"}, 0); len(published(r.Events)) != 1 {
+		t.Errorf("replay for org:1 = %d events, want 1", len(published(r.Events)))
+	}
+	if r, _ := hub.Since("user-3", nil, 0); len(r.Events) != 0 {
+		t.Errorf("replay without topics = %d events, want 0", len(r.Events))
+	}
+
+	// Leaving drops the topic once its last subscriber is gone.
+	hub.Unsubscribe("user-1", both)
+	hub.Disconnect("user-2")
+	hub.Unsubscribe("user-3", none)
+	hub.mu.RLock()
+	remaining := len(hub.topics) + len(hub.conns)
+	hub.mu.RUnlock()
+	if remaining != 0 {
+		t.Errorf("topic bookkeeping left %d entries", remaining)
+	}
+}
@@ -4,22 +4,45 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"golang.org/x/sync/singleflight"
 
 	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/models"
 )
 
 // UserService handles user profile operations.
 type UserService struct {
-	pool *pgxpool.Pool
+	pool              *pgxpool.Pool
+	paginationDefault int
+	paginationMax     int
+
+	statusCache    map[string]cachedStatus
+	statusCacheMu  sync.RWMutex
+	statusCacheTTL time.Duration
+	statusFlight   singleflight.Group
+	queryStatus    func(ctx context.Context, userID string) (models.UserStatus, *time.Time, error)
 }
 
-// NewUserService creates a new user service.
-func NewUserService(pool *pgxpool.Pool) *UserService {
-	return &UserService{pool: pool}
+// NewUserService creates a new user service. statusCacheTTL bounds how long
+// CheckAccountStatus may serve a cached account status (default 30s).
+func NewUserService(pool *pgxpool.Pool, paginationDefault, paginationMax int, statusCacheTTL time.Duration) *UserService {
+	if statusCacheTTL == 0 {
+		statusCacheTTL = 30 * time.Second
+	}
+	s := &UserService{
+		pool:              pool,
+		paginationDefault: paginationDefault,
+		paginationMax:     paginationMax,
+		statusCache:       make(map[string]cachedStatus),
+		statusCacheTTL:    statusCacheTTL,
+	}
+	s.queryStatus = s.queryStatusDB
+	return s
 }
 
 // UserProfile represents a user with their full profile.
@@ -32,21 +55,38 @@ type UserProfile struct {
 	LastName      *string   `json:"last_name"`
 	AvatarURL     *string   `json:"avatar_url"`
 	CreatedAt     time.Time `json:"created_at"`
+
+	// Status is the effective account status: a suspension whose
+	// suspended_until has passed reads as "active".
+	Status          string     `json:"status"`
+	SuspendedUntil  *time.Time `json:"suspended_until,omitempty"`
+	StatusReason    *string    `json:"status_reason,omitempty"`
+	StatusChangedBy *string    `json:"status_changed_by,omitempty"`
+	StatusChangedAt *time.Time `json:"status_changed_at,omitempty"`
+}
+
+// profileColumns is the SELECT list scanned by scanProfile.
+const profileColumns = `id, email, type, COALESCE(email_verified, FALSE),
+	first_name, last_name, avatar_url, created_at,
+	` + statusExpr + `, suspended_until, status_reason, status_changed_by::text, status_changed_at`
+
+// scanProfile scans a row selected with profileColumns.
+func scanProfile(row pgx.Row, p *UserProfile) error {
+	return row.Scan(&p.ID, &p.Email, &p.Type, &p.EmailVerified,
+		&p.FirstName, &p.LastName, &p.AvatarURL, &p.CreatedAt,
+		&p.Status, &p.SuspendedUntil, &p.StatusReason, &p.StatusChangedBy, &p.StatusChangedAt)
 }
 
 // GetByID retrieves a user by ID with their full profile.
 func (s *UserService) GetByID(ctx context.Context, userID string) (*UserProfile, error) {
 	var profile UserProfile
-	err := s.pool.QueryRow(ctx,
-		`SELECT id, email, type, email_verified,
-		 first_name, last_name, avatar_url, created_at
-		 FROM users WHERE id = $1`,
+	err := scanProfile(s.pool.QueryRow(ctx,
+		`SELECT `+profileColumns+` FROM users WHERE id = $1`,
 		userID,
-	).Scan(&profile.ID, &profile.Email, &profile.Type, &profile.EmailVerified,
-		&profile.FirstName, &profile.LastName, &profile.AvatarURL, &profile.CreatedAt)
+	), &profile)
 
 	if errors.Is(err, pgx.ErrNoRows) {
-		return nil, apperror.NotFound("User not found")
+		return nil, apperror.NotFound("User")
 	}
 	if err != nil {
 		return nil, apperror.Internal(fmt.Errorf("get user: %w", err))
@@ -55,12 +95,11 @@ func (s *UserService) GetByID(ctx context.Context, userID string) (*UserProfile,
 	return &profile, nil
 }
 
-// ProfileUpdate holds profile update fields.
+// ProfileUpdate holds profile update fields. Avatars are managed by the
+// avatar service, not through profile updates.
 type ProfileUpdate struct {
-	FirstName    string
-	LastName     string
-	AvatarURL    string
-	AvatarURLSet bool
+	FirstName string
+	LastName  string
 }
 
 // UpdateProfile updates a user's profile.
@@ -69,11 +108,9 @@ func (s *UserService) UpdateProfile(ctx context.Context, userID string, update *
 		`UPDATE users SET
 			first_name = COALESCE(NULLIF($2, ''), first_name),
 			last_name = COALESCE(NULLIF($3, ''), last_name),
-			avatar_url = CASE WHEN $4 THEN $5 ELSE avatar_url END,
 			updated_at = NOW()
 		 WHERE id = $1`,
 		userID, update.FirstName, update.LastName,
-		update.AvatarURLSet, nilIfEmpty(update.AvatarURL),
 	)
 	if err != nil {
 		return nil, apperror.Internal(fmt.Errorf("update profile: %w", err))
@@ -0,0 +1,219 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/models"
+	"github.com/golid-ai/golid/backend/internal/pagination"
+	"github.com/golid-ai/golid/backend/internal/validate"
+)
+
+// ============================================================================
+// ADMIN: LIST / SEARCH
+// ============================================================================
+
+// nameExpr matches the expression indexed by idx_users_name_trgm (000006).
+const nameExpr = `(COALESCE(first_name, '') || ' ' || COALESCE(last_name, ''))`
+
+// ValidUserTypes are the values of the user_type enum.
+var ValidUserTypes = map[string]bool{"user": true, "admin": true}
+
+// ListUsersInput filters the admin user list. Zero values mean "no filter".
+type ListUsersInput struct {
+	Page          int
+	PerPage       int
+	Search        string
+	Type          string
+	Status        string
+	EmailVerified *bool
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+}
+
+// UserListResult is a page of users for the admin list.
+type UserListResult struct {
+	Users      []UserProfile `json:"users"`
+	Total      int           `json:"total"`
+	Page       int           `json:"page"`
+	PerPage    int           `json:"per_page"`
+	TotalPages int           `json:"total_pages"`
+}
+
+// ListUsers returns a filtered, paginated list of users. Search matches
+// email and full name by substring or trigram similarity (pg_trgm) and
+// ranks results by similarity; without a search term, newest users first.
+func (s *UserService) ListUsers(ctx context.Context, input *ListUsersInput) (*UserListResult, error) {
+	page, perPage := pagination.NormalizePagination(input.Page, input.PerPage, s.paginationDefault, s.paginationMax)
+	offset := (page - 1) * perPage
+
+	var conds []string
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	search := strings.TrimSpace(input.Search)
+	var searchArg string
+	if search != "" {
+		searchArg = arg(search)
+		like := arg(validate.WrapLike(search))
+		conds = append(conds, fmt.Sprintf(
+			`(email ILIKE %[2]s OR %[3]s ILIKE %[2]s OR email %% %[1]s OR %[3]s %% %[1]s)`,
+			searchArg, like, nameExpr,
+		))
+	}
+	if input.Type != "" {
+		if !ValidUserTypes[input.Type] {
+			return nil, apperror.Validation().WithDetail("type", "error.user.type")
+		}
+		conds = append(conds, "type = "+arg(input.Type))
+	}
+	if input.Status != "" {
+		if !models.UserStatus(input.Status).Valid() {
+			return nil, apperror.Validation().WithDetail("status", "error.user.status")
+		}
+		conds = append(conds, statusExpr+" = "+arg(input.Status))
+	}
+	if input.EmailVerified != nil {
+		conds = append(conds, "COALESCE(email_verified, FALSE) = "+arg(*input.EmailVerified))
+	}
+	if input.CreatedAfter != nil {
+		conds = append(conds, "created_at >= "+arg(*input.CreatedAfter))
+	}
+	if input.CreatedBefore != nil {
+		conds = append(conds, "created_at < "+arg(*input.CreatedBefore))
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = " WHERE " + strings.Join(conds, " AND ")
+	}
+
+	var total int
+	if err := s.pool.QueryRow(ctx, `SELECT COUNT(*) FROM users`+where, args...).Scan(&total); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("count users: %w", err))
+	}
+
+	orderBy := " ORDER BY created_at DESC, id"
+	if search != "" {
+		orderBy = fmt.Sprintf(" ORDER BY GREATEST(similarity(email, %[1]s), similarity(%[2]s, %[1]s)) DESC, created_at DESC, id",
+			searchArg, nameExpr)
+	}
+	query := `SELECT ` + profileColumns + ` FROM users` + where + orderBy +
+		" LIMIT " + arg(perPage) + " OFFSET " + arg(offset)
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("list users: %w", err))
+	}
+	defer rows.Close()
+
+	var users []UserProfile
+	for rows.Next() {
+		var u UserProfile
+		if err := scanProfile(rows, &u); err != nil {
+			return nil, apperror.Internal(fmt.Errorf("scan user: %w", err))
+		}
+		users = append(users, u)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("iterate users: %w", err))
+	}
+
+	if users == nil {
+		users = []UserProfile{}
+	}
+
+	totalPages := (total + perPage - 1) / perPage
+	return &UserListResult{
+		Users:      users,
+		Total:      total,
+		Page:       page,
+		PerPage:    perPage,
+		TotalPages: totalPages,
+	}, nil
+}
+
+// ============================================================================
+// ADMIN: UPDATE
+// ============================================================================
+
+// AdminUpdate holds admin-only changes to a user. Nil fields are left
+// unchanged.
+type AdminUpdate struct {
+	Type          *string
+	EmailVerified *bool
+}
+
+// AdminUpdate applies an admin change to a user. Changing the type revokes
+// the user's refresh tokens so the new role takes effect at the next login
+// instead of surviving in rotated tokens. Forcing verification clears any
+// pending verification token.
+func (s *UserService) AdminUpdate(ctx context.Context, userID string, update *AdminUpdate) (*UserProfile, error) {
+	if update.Type != nil && !ValidUserTypes[*update.Type] {
+		return nil, apperror.Validation().WithDetail("type", "error.user.type")
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("begin tx: %w", err))
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var currentType string
+	err = tx.QueryRow(ctx,
+		"SELECT type FROM users WHERE id = $1 FOR UPDATE",
+		userID,
+	).Scan(&currentType)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperror.NotFound("User")
+	}
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("lock user: %w", err))
+	}
+
+	if update.Type != nil && *update.Type != currentType {
+		if _, err := tx.Exec(ctx,
+			"UPDATE users SET type = $2, updated_at = NOW() WHERE id = $1",
+			userID, *update.Type,
+		); err != nil {
+			return nil, apperror.Internal(fmt.Errorf("update user type: %w", err))
+		}
+		if _, err := tx.Exec(ctx,
+			"UPDATE refresh_tokens SET revoked = TRUE WHERE user_id = $1 AND revoked = FALSE",
+			userID,
+		); err != nil {
+			return nil, apperror.Internal(fmt.Errorf("revoke refresh tokens: %w", err))
+		}
+	}
+
+	if update.EmailVerified != nil {
+		if _, err := tx.Exec(ctx,
+			`UPDATE users SET
+				email_verified = $2,
+				verification_selector = CASE WHEN $2 THEN NULL ELSE verification_selector END,
+				verification_verifier_hash = CASE WHEN $2 THEN NULL ELSE verification_verifier_hash END,
+				updated_at = NOW()
+			 WHERE id = $1`,
+			userID, *update.EmailVerified,
+		); err != nil {
+			return nil, apperror.Internal(fmt.Errorf("update email verification: %w", err))
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("commit tx: %w", err))
+	}
+
+	return s.GetByID(ctx, userID)
+}
@@ -0,0 +1,291 @@
+//go:build integration
+
+package user
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/models"
+	"github.com/golid-ai/golid/backend/internal/service/auth"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
+	"github.com/golid-ai/golid/backend/internal/testutil"
+)
+
+func registerTestUser(t *testing.T, ctx context.Context, authSvc *auth.AuthService, email, first, last string) string {
+	t.Helper()
+	result, err := authSvc.Register(ctx, &auth.RegisterInput{
+		Email:     email,
+		Password:  "password123",
+		FirstName: first,
+		LastName:  last,
+	})
+	if err != nil {
+		t.Fatalf("Register(%s) error = %v", email, err)
+	}
+	return result.User.ID
+}
+
+func TestListUsers_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		ctx := context.Background()
+		authSvc := auth.NewAuthService(pool, "test-jwt-secret-that-is-at-least-32-characters-long!", "test-issuer", 15*time.Minute, 7*24*time.Hour, 1*time.Hour)
+		userSvc := NewUserService(pool, 20, 100, time.Minute)
+
+		aliceID := registerTestUser(t, ctx, authSvc, "alice@example.com", "Alice", "Anderson")
+		registerTestUser(t, ctx, authSvc, "bob@example.com", "Bob", "Brown")
+		registerTestUser(t, ctx, authSvc, "carol@sample.org", "Carol", "Anders")
+
+		if _, err := pool.Exec(ctx, "UPDATE users SET type = 'admin', email_verified = TRUE WHERE id = $1", aliceID); err != nil {
+			t.Fatalf("promote alice: %v", err)
+		}
+
+		t.Run("no filters", func(t *testing.T) {
+			result, err := userSvc.ListUsers(ctx, &ListUsersInput{Page: 1, PerPage: 20})
+			if err != nil {
+				t.Fatalf("ListUsers() error = %v", err)
+			}
+			if result.Total != 3 || len(result.Users) != 3 {
+				t.Errorf("Total = %d, len = %d, want 3", result.Total, len(result.Users))
+			}
+		})
+
+		t.Run("search by name", func(t *testing.T) {
+			result, err := userSvc.ListUsers(ctx, &ListUsersInput{Search: "anders"})
+			if err != nil {
+				t.Fatalf("ListUsers() error = %v", err)
+			}
+			if result.Total != 2 {
+				t.Errorf("Total = %d, want 2 (Anderson, Anders)", result.Total)
+			}
+		})
+
+		t.Run("search by email", func(t *testing.T) {
+			result, err := userSvc.ListUsers(ctx, &ListUsersInput{Search: "sample.org"})
+			if err != nil {
+				t.Fatalf("ListUsers() error = %v", err)
+			}
+			if result.Total != 1 || result.Users[0].Email != "carol@sample.org" {
+				t.Errorf("unexpected result: %+v", result)
+			}
+		})
+
+		t.Run("search treats wildcards literally", func(t *testing.T) {
+			result, err := userSvc.ListUsers(ctx, &ListUsersInput{Search: "%"})
+			if err != nil {
+				t.Fatalf("ListUsers() error = %v", err)
+			}
+			if result.Total != 0 {
+				t.Errorf("Total = %d, want 0", result.Total)
+			}
+		})
+
+		t.Run("type and verified filters", func(t *testing.T) {
+			verified := true
+			result, err := userSvc.ListUsers(ctx, &ListUsersInput{Type: "admin", EmailVerified: &verified})
+			if err != nil {
+				t.Fatalf("ListUsers() error = %v", err)
+			}
+			if result.Total != 1 || result.Users[0].ID != aliceID {
+				t.Errorf("unexpected result: %+v", result)
+			}
+
+			unverified := false
+			result, err = userSvc.ListUsers(ctx, &ListUsersInput{EmailVerified: &unverified})
+			if err != nil {
+				t.Fatalf("ListUsers() error = %v", err)
+			}
+			if result.Total != 2 {
+				t.Errorf("unverified Total = %d, want 2", result.Total)
+			}
+		})
+
+		t.Run("created date range", func(t *testing.T) {
+			future := time.Now().Add(time.Hour)
+			result, err := userSvc.ListUsers(ctx, &ListUsersInput{CreatedAfter: &future})
+			if err != nil {
+				t.Fatalf("ListUsers() error = %v", err)
+			}
+			if result.Total != 0 {
+				t.Errorf("Total = %d, want 0", result.Total)
+			}
+		})
+
+		t.Run("pagination", func(t *testing.T) {
+			result, err := userSvc.ListUsers(ctx, &ListUsersInput{Page: 2, PerPage: 2})
+			if err != nil {
+				t.Fatalf("ListUsers() error = %v", err)
+			}
+			if len(result.Users) != 1 || result.TotalPages != 2 {
+				t.Errorf("len = %d, TotalPages = %d, want 1 and 2", len(result.Users), result.TotalPages)
+			}
+		})
+
+		t.Run("invalid type", func(t *testing.T) {
+			_, err := userSvc.ListUsers(ctx, &ListUsersInput{Type: "superuser"})
+			if !apperror.Is(err, apperror.CodeValidation) {
+				t.Errorf("err = %v, want Validation", err)
+			}
+		})
+	})
+}
+
+func TestAdminUpdate_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		ctx := context.Background()
+		authSvc := auth.NewAuthService(pool, "test-jwt-secret-that-is-at-least-32-characters-long!", "test-issuer", 15*time.Minute, 7*24*time.Hour, 1*time.Hour)
+		userSvc := NewUserService(pool, 20, 100, time.Minute)
+
+		userID := registerTestUser(t, ctx, authSvc, "target@example.com", "Target", "User")
+
+		admin := "admin"
+		verified := true
+		profile, err := userSvc.AdminUpdate(ctx, userID, &AdminUpdate{Type: &admin, EmailVerified: &verified})
+		if err != nil {
+			t.Fatalf("AdminUpdate() error = %v", err)
+		}
+		if profile.Type != "admin" || !profile.EmailVerified {
+			t.Errorf("profile = %+v, want admin + verified", profile)
+		}
+
+		var active int
+		if err := pool.QueryRow(ctx,
+			"SELECT COUNT(*) FROM refresh_tokens WHERE user_id = $1 AND revoked = FALSE", userID,
+		).Scan(&active); err != nil {
+			t.Fatalf("count tokens: %v", err)
+		}
+		if active != 0 {
+			t.Errorf("active refresh tokens = %d, want 0 after type change", active)
+		}
+
+		var selector *string
+		if err := pool.QueryRow(ctx,
+			"SELECT verification_selector FROM users WHERE id = $1", userID,
+		).Scan(&selector); err != nil {
+			t.Fatalf("get selector: %v", err)
+		}
+		if selector != nil {
+			t.Error("verification_selector should be cleared after forced verification")
+		}
+
+		_, err = userSvc.AdminUpdate(ctx, "00000000-0000-0000-0000-000000000000", &AdminUpdate{EmailVerified: &verified})
+		if !apperror.Is(err, apperror.CodeNotFound) {
+			t.Errorf("err = %v, want NotFound", err)
+		}
+	})
+}
+
+func TestSetStatus_Integration(t *testing.T) {
+	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
+		ctx := context.Background()
+		authSvc := auth.NewAuthService(pool, "test-jwt-secret-that-is-at-least-32-characters-long!", "test-issuer", 15*time.Minute, 7*24*time.Hour, 1*time.Hour)
+		userSvc := NewUserService(pool, 20, 100, time.Minute)
+
+		adminID := registerTestUser(t, ctx, authSvc, "admin@example.com", "Admin", "User")
+		userID := registerTestUser(t, ctx, authSvc, "target@example.com", "Target", "User")
+
+		if err := userSvc.CheckAccountStatus(ctx, userID); err != nil {
+			t.Fatalf("CheckAccountStatus() before suspension = %v", err)
+		}
+
+		t.Run("validation", func(t *testing.T) {
+			past := time.Now().Add(-time.Hour)
+			_, err := userSvc.SetStatus(ctx, userID, &SetStatusInput{
+				Status: models.UserStatusSuspended, SuspendedUntil: &past, ActorID: adminID,
+			})
+			var appErr *apperror.AppError
+			if !errors.As(err, &appErr) || appErr.Code != apperror.CodeValidation {
+				t.Fatalf("err = %v, want Validation", err)
+			}
+			for _, field := range []string{"suspended_until", "reason"} {
+				if _, ok := appErr.Details[field]; !ok {
+					t.Errorf("missing detail for %q", field)
+				}
+			}
+		})
+
+		t.Run("suspend", func(t *testing.T) {
+			until := time.Now().Add(24 * time.Hour)
+			var emailedTo string
+			var emailedUntil *time.Time
+			profile, err := userSvc.SetStatus(ctx, userID, &SetStatusInput{
+				Status: models.UserStatusSuspended, SuspendedUntil: &until, Reason: "spam", ActorID: adminID,
+				StatusEmail: func(to string, suspendedUntil *time.Time) (outbox.Message, error) {
+					emailedTo, emailedUntil = to, suspendedUntil
+					return outbox.Task(asynq.NewTask("email:account_status", []byte(`{}`))), nil
+				},
+			})
+			if err != nil {
+				t.Fatalf("SetStatus() error = %v", err)
+			}
+			if emailedTo != "target@example.com" || emailedUntil == nil || !emailedUntil.Equal(until) {
+				t.Errorf("StatusEmail got %q, %v", emailedTo, emailedUntil)
+			}
+			var name string
+			if err := pool.QueryRow(ctx, "SELECT name FROM outbox").Scan(&name); err != nil || name != "email:account_status" {
+				t.Errorf("outbox row = %q, %v; want the status email", name, err)
+			}
+			if profile.Status != "suspended" || profile.StatusReason == nil || *profile.StatusReason != "spam" ||
+				profile.StatusChangedBy == nil || *profile.StatusChangedBy != adminID {
+				t.Errorf("profile = %+v", profile)
+			}
+
+			var active int
+			if err := pool.QueryRow(ctx,
+				"SELECT COUNT(*) FROM refresh_tokens WHERE user_id = $1 AND revoked = FALSE", userID,
+			).Scan(&active); err != nil {
+				t.Fatalf("count tokens: %v", err)
+			}
+			if active != 0 {
+				t.Errorf("active refresh tokens = %d, want 0 after suspension", active)
+			}
+
+			// SetStatus invalidates the cached "active" entry.
+			if err := userSvc.CheckAccountStatus(ctx, userID); !apperror.Is(err, apperror.CodeAccountSuspended) {
+				t.Errorf("CheckAccountStatus() = %v, want ACCOUNT_SUSPENDED", err)
+			}
+
+			result, err := userSvc.ListUsers(ctx, &ListUsersInput{Status: "suspended"})
+			if err != nil {
+				t.Fatalf("ListUsers() error = %v", err)
+			}
+			if result.Total != 1 || result.Users[0].ID != userID {
+				t.Errorf("unexpected result: %+v", result)
+			}
+		})
+
+		t.Run("reactivate", func(t *testing.T) {
+			profile, err := userSvc.SetStatus(ctx, userID, &SetStatusInput{
+				Status: models.UserStatusActive, ActorID: adminID,
+			})
+			if err != nil {
+				t.Fatalf("SetStatus() error = %v", err)
+			}
+			if profile.Status != "active" || profile.SuspendedUntil != nil {
+				t.Errorf("profile = %+v", profile)
+			}
+			if err := userSvc.CheckAccountStatus(ctx, userID); err != nil {
+				t.Errorf("CheckAccountStatus() after reactivation = %v", err)
+			}
+		})
+
+		t.Run("not found", func(t *testing.T) {
+			_, err := userSvc.SetStatus(ctx, "00000000-0000-0000-0000-000000000000", &SetStatusInput{
+				Status: models.UserStatusBanned, Reason: "x", ActorID: adminID,
+				StatusEmail: func(string, *time.Time) (outbox.Message, error) {
+					t.Error("StatusEmail should not be called for a missing user")
+					return outbox.Message{}, nil
+				},
+			})
+			if !apperror.Is(err, apperror.CodeNotFound) {
+				t.Errorf("err = %v, want NotFound", err)
+			}
+		})
+	})
+}
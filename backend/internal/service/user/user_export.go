@@ -0,0 +1,12 @@
+package user
+
+import "context"
+
+// ExportName implements export.Exporter.
+func (s *UserService) ExportName() string { return "profile" }
+
+// ExportUserData implements export.Exporter: the user's profile and
+// account status.
+func (s *UserService) ExportUserData(ctx context.Context, userID string) (any, error) {
+	return s.GetByID(ctx, userID)
+}
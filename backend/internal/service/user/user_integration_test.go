@@ -32,7 +32,7 @@ func TestUpdateProfile_Integration(t *testing.T) {
 	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
 		ctx := context.Background()
 		authSvc := auth.NewAuthService(pool, "test-jwt-secret-that-is-at-least-32-characters-long!", "test-issuer", 15*time.Minute, 7*24*time.Hour, 1*time.Hour)
-		userSvc := NewUserService(pool)
+		userSvc := NewUserService(pool, 20, 100, time.Minute)
 
 		result, err := authSvc.Register(ctx, &auth.RegisterInput{
 			Email:     "profile@example.com",
@@ -81,7 +81,7 @@ func TestUpdateProfile_Integration(t *testing.T) {
 func TestGetByID_NotFound_Integration(t *testing.T) {
 	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
 		ctx := context.Background()
-		userSvc := NewUserService(pool)
+		userSvc := NewUserService(pool, 20, 100, time.Minute)
 
 		_, err := userSvc.GetByID(ctx, "00000000-0000-0000-0000-000000000000")
 		if err == nil {
@@ -96,7 +96,7 @@ func TestGetByID_NotFound_Integration(t *testing.T) {
 func TestUpdateProfile_NotFound_Integration(t *testing.T) {
 	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
 		ctx := context.Background()
-		userSvc := NewUserService(pool)
+		userSvc := NewUserService(pool, 20, 100, time.Minute)
 
 		_, err := userSvc.UpdateProfile(ctx, "00000000-0000-0000-0000-000000000000", &ProfileUpdate{
 			FirstName: "Ghost",
@@ -109,43 +109,3 @@ func TestUpdateProfile_NotFound_Integration(t *testing.T) {
 		}
 	})
 }
-
-func TestUpdateProfile_AvatarURL_Integration(t *testing.T) {
-	testutil.WithTestDB(t, func(pool *pgxpool.Pool) {
-		ctx := context.Background()
-		authSvc := auth.NewAuthService(pool, "test-jwt-secret-that-is-at-least-32-characters-long!", "test-issuer", 15*time.Minute, 7*24*time.Hour, 1*time.Hour)
-		userSvc := NewUserService(pool)
-
-		result, err := authSvc.Register(ctx, &auth.RegisterInput{
-			Email:     "avatar@example.com",
-			Password:  "password123",
-			FirstName: "Test",
-			LastName:  "User",
-		})
-		if err != nil {
-			t.Fatalf("Register() error = %v", err)
-		}
-
-		updated, err := userSvc.UpdateProfile(ctx, result.User.ID, &ProfileUpdate{
-			AvatarURL:    "https://example.com/pic.jpg",
-			AvatarURLSet: true,
-		})
-		if err != nil {
-			t.Fatalf("UpdateProfile() set avatar error = %v", err)
-		}
-		if derefStr(updated.AvatarURL) != "https://example.com/pic.jpg" {
-			t.Errorf("AvatarURL = %q, want %q", derefStr(updated.AvatarURL), "https://example.com/pic.jpg")
-		}
-
-		cleared, err := userSvc.UpdateProfile(ctx, result.User.ID, &ProfileUpdate{
-			AvatarURL:    "",
-			AvatarURLSet: true,
-		})
-		if err != nil {
-			t.Fatalf("UpdateProfile() clear avatar error = %v", err)
-		}
-		if updated.AvatarURL != nil && derefStr(cleared.AvatarURL) != "" {
-			t.Errorf("AvatarURL after clear = %q, want empty", derefStr(cleared.AvatarURL))
-		}
-	})
-}
@@ -0,0 +1,46 @@
+package user
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/golid-ai/golid/backend/internal/service/preference"
+)
+
+// localePattern accepts BCP 47 language tags of the form "en" or "pt-BR".
+var localePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Z]{2})?$`)
+
+// PreferenceDefs implements preference.Declarer: the regional settings
+// other modules use to format user-facing text. An empty locale means
+// "follow the browser": requests use Accept-Language and emails sent
+// outside a request use i18n.DefaultLocale.
+func (s *UserService) PreferenceDefs() []preference.Def {
+	return []preference.Def{
+		{
+			Key:     preference.KeyLocale,
+			Kind:    preference.String,
+			Default: "",
+			Validate: func(v any) string {
+				if tag := v.(string); tag != "" && !localePattern.MatchString(tag) {
+					return "error.preference.locale"
+				}
+				return ""
+			},
+		},
+		{
+			Key:     preference.KeyTimezone,
+			Kind:    preference.String,
+			Default: "UTC",
+			Validate: func(v any) string {
+				name := v.(string)
+				if name == "" || name == "Local" {
+					return "error.preference.timezone"
+				}
+				if _, err := time.LoadLocation(name); err != nil {
+					return "error.preference.timezone"
+				}
+				return ""
+			},
+		},
+	}
+}
@@ -0,0 +1,42 @@
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/service/preference"
+)
+
+func TestPreferenceDefs_Validation(t *testing.T) {
+	prefs := preference.NewPreferenceService(nil)
+	prefs.Register((&UserService{}).PreferenceDefs()...)
+
+	tests := map[string]string{
+		preference.KeyLocale:   `"en_US"`,
+		preference.KeyTimezone: `"Mars/Olympus"`,
+	}
+	for key, raw := range tests {
+		_, err := prefs.Update(context.Background(), "user-1", map[string]json.RawMessage{key: json.RawMessage(raw)})
+		var appErr *apperror.AppError
+		if !errors.As(err, &appErr) || appErr.Details[key] == "" {
+			t.Errorf("Update(%s=%s) error = %v, want validation detail", key, raw, err)
+		}
+	}
+
+	for _, d := range prefs.Defs() {
+		if d.Validate != nil && d.Validate(d.Default) != "" {
+			t.Errorf("%s default %v does not validate", d.Key, d.Default)
+		}
+	}
+	for _, valid := range []string{"", "pt-BR", "fil"} {
+		if msg := prefs.Defs()[0].Validate(valid); msg != "" {
+			t.Errorf("locale %q rejected: %s", valid, msg)
+		}
+	}
+	if msg := prefs.Defs()[1].Validate("Europe/Paris"); msg != "" {
+		t.Errorf("timezone Europe/Paris rejected: %s", msg)
+	}
+}
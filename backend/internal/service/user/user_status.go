@@ -0,0 +1,220 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/logger"
+	"github.com/golid-ai/golid/backend/internal/models"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
+)
+
+// ============================================================================
+// ACCOUNT STATUS (SUSPEND / BAN)
+// ============================================================================
+
+// statusExpr is the effective account status. A lapsed suspension is
+// reported as active without needing a job to flip it back.
+const statusExpr = `(CASE WHEN status = 'suspended' AND suspended_until <= NOW() THEN 'active' ELSE status::text END)`
+
+// maxStatusReasonLen bounds the reason shown to the user and stored for audit.
+const maxStatusReasonLen = 500
+
+// statusLoadTimeout bounds a CheckAccountStatus database read, which runs
+// detached from the request that started it.
+const statusLoadTimeout = 5 * time.Second
+
+// statusCacheSweepSize is the cache size above which expired entries are
+// pruned on insert, keeping memory bounded by recently active users.
+const statusCacheSweepSize = 10000
+
+// cachedStatus is an entry in the CheckAccountStatus cache. The raw status
+// is cached so a suspension still lapses on time while cached.
+type cachedStatus struct {
+	status         models.UserStatus
+	suspendedUntil *time.Time
+	missing        bool
+	fetchedAt      time.Time
+}
+
+// SetStatusInput changes a user's account status.
+type SetStatusInput struct {
+	Status         models.UserStatus
+	SuspendedUntil *time.Time // required for suspended, ignored otherwise
+	Reason         string     // required for suspended and banned
+	ActorID        string     // admin making the change
+
+	// StatusEmail, when set, builds the message that tells the user about
+	// the change, given their address and the effective suspended_until.
+	// It is written to the outbox in the status transaction, so the email
+	// goes out once the change commits even if the process stops right
+	// after.
+	StatusEmail func(to string, suspendedUntil *time.Time) (outbox.Message, error)
+}
+
+// SetStatus suspends, bans, or reactivates a user. Suspending or banning
+// revokes every refresh token so the user cannot mint new access tokens;
+// existing access tokens are rejected by JWTAuth via CheckAccountStatus.
+func (s *UserService) SetStatus(ctx context.Context, userID string, input *SetStatusInput) (*UserProfile, error) {
+	verr := apperror.Validation()
+	if !input.Status.Valid() {
+		verr.WithDetail("status", "error.user.status")
+	}
+	if input.Status == models.UserStatusSuspended {
+		if input.SuspendedUntil == nil {
+			verr.WithDetail("suspended_until", "error.user.suspended_until_required")
+		} else if !input.SuspendedUntil.After(time.Now()) {
+			verr.WithDetail("suspended_until", "error.user.suspended_until_past")
+		}
+	}
+	if input.Status != models.UserStatusActive && input.Reason == "" {
+		verr.WithDetail("reason", "error.reason_required")
+	}
+	if len(input.Reason) > maxStatusReasonLen {
+		verr.WithDetail("reason", "error.reason_too_long", maxStatusReasonLen)
+	}
+	if len(verr.Details) > 0 {
+		return nil, verr
+	}
+
+	var suspendedUntil *time.Time
+	if input.Status == models.UserStatusSuspended {
+		suspendedUntil = input.SuspendedUntil
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("begin tx: %w", err))
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var email string
+	err = tx.QueryRow(ctx,
+		`UPDATE users SET
+			status = $2,
+			suspended_until = $3,
+			status_reason = $4,
+			status_changed_by = $5,
+			status_changed_at = NOW(),
+			updated_at = NOW()
+		 WHERE id = $1
+		 RETURNING email`,
+		userID, string(input.Status), suspendedUntil, nilIfEmpty(input.Reason), nilIfEmpty(input.ActorID),
+	).Scan(&email)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, apperror.NotFound("User")
+	}
+	if err != nil {
+		return nil, apperror.Internal(fmt.Errorf("update user status: %w", err))
+	}
+
+	if input.Status != models.UserStatusActive {
+		if _, err := tx.Exec(ctx,
+			"UPDATE refresh_tokens SET revoked = TRUE WHERE user_id = $1 AND revoked = FALSE",
+			userID,
+		); err != nil {
+			return nil, apperror.Internal(fmt.Errorf("revoke refresh tokens: %w", err))
+		}
+	}
+
+	if input.StatusEmail != nil {
+		msg, err := input.StatusEmail(email, suspendedUntil)
+		if err != nil {
+			return nil, apperror.Internal(fmt.Errorf("build account status email: %w", err))
+		}
+		if err := outbox.Enqueue(ctx, tx, msg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, apperror.Internal(fmt.Errorf("commit tx: %w", err))
+	}
+
+	s.statusCacheMu.Lock()
+	delete(s.statusCache, userID)
+	s.statusCacheMu.Unlock()
+
+	return s.GetByID(ctx, userID)
+}
+
+// CheckAccountStatus returns an ACCOUNT_SUSPENDED error if the user is
+// suspended or banned, Unauthorized if the user no longer exists, and nil
+// otherwise. Results are cached per user for the configured TTL; SetStatus
+// invalidates the local entry, other instances converge within the TTL.
+// Database errors fail open so an outage doesn't log everyone out.
+//
+// Concurrent checks for one user share a single read, run without the
+// first caller's cancellation: a cancelled request must not fail the
+// read for the others and so let them through. A caller whose own
+// context is done gets its error rather than failing open.
+func (s *UserService) CheckAccountStatus(ctx context.Context, userID string) error {
+	s.statusCacheMu.RLock()
+	entry, ok := s.statusCache[userID]
+	s.statusCacheMu.RUnlock()
+
+	if !ok || time.Since(entry.fetchedAt) >= s.statusCacheTTL {
+		v, err, _ := s.statusFlight.Do(userID, func() (interface{}, error) {
+			loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), statusLoadTimeout)
+			defer cancel()
+			return s.loadStatus(loadCtx, userID)
+		})
+		if err != nil && ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			logger.Warn("account status check failed, allowing request",
+				slog.String("user_id", userID),
+				slog.String("error", err.Error()),
+			)
+			return nil
+		}
+		entry = v.(cachedStatus)
+	}
+
+	if entry.missing {
+		return apperror.Unauthorized("User not found").WithMessageID("error.not_found.user")
+	}
+	return models.AccountStatusError(entry.status, entry.suspendedUntil, time.Now())
+}
+
+// loadStatus reads a user's raw status and stores it in the cache.
+func (s *UserService) loadStatus(ctx context.Context, userID string) (cachedStatus, error) {
+	entry := cachedStatus{fetchedAt: time.Now()}
+	var err error
+	entry.status, entry.suspendedUntil, err = s.queryStatus(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		entry.missing = true
+	} else if err != nil {
+		return cachedStatus{}, fmt.Errorf("get account status: %w", err)
+	}
+
+	s.statusCacheMu.Lock()
+	if len(s.statusCache) >= statusCacheSweepSize {
+		for id, e := range s.statusCache {
+			if time.Since(e.fetchedAt) >= s.statusCacheTTL {
+				delete(s.statusCache, id)
+			}
+		}
+	}
+	s.statusCache[userID] = entry
+	s.statusCacheMu.Unlock()
+	return entry, nil
+}
+
+// queryStatusDB reads a user's raw status from the database.
+func (s *UserService) queryStatusDB(ctx context.Context, userID string) (models.UserStatus, *time.Time, error) {
+	var status models.UserStatus
+	var suspendedUntil *time.Time
+	err := s.pool.QueryRow(ctx,
+		"SELECT status, suspended_until FROM users WHERE id = $1",
+		userID,
+	).Scan(&status, &suspendedUntil)
+	return status, suspendedUntil, err
+}
@@ -0,0 +1,62 @@
+package user
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golid-ai/golid/backend/internal/apperror"
+	"github.com/golid-ai/golid/backend/internal/models"
+)
+
+func TestCheckAccountStatus_CancelledCallerDoesNotFailOpen(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	var queries atomic.Int32
+	svc := NewUserService(nil, 20, 100, time.Minute)
+	svc.queryStatus = func(ctx context.Context, _ string) (models.UserStatus, *time.Time, error) {
+		if queries.Add(1) == 1 {
+			close(entered)
+		}
+		select {
+		case <-release:
+			return models.UserStatusBanned, nil, nil
+		case <-ctx.Done():
+			return "", nil, ctx.Err()
+		}
+	}
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() { first <- svc.CheckAccountStatus(firstCtx, "user-1") }()
+	<-entered
+
+	second := make(chan error, 1)
+	go func() { second <- svc.CheckAccountStatus(context.Background(), "user-1") }()
+	time.Sleep(20 * time.Millisecond) // let the second caller join the flight
+
+	cancelFirst()
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	if err := <-first; err == nil {
+		t.Error("cancelled caller was let through")
+	}
+	if err := <-second; !apperror.Is(err, apperror.CodeAccountSuspended) {
+		t.Errorf("concurrent caller error = %v, want ACCOUNT_SUSPENDED", err)
+	}
+	if n := queries.Load(); n != 1 {
+		t.Errorf("queries = %d, want one shared read", n)
+	}
+}
+
+func TestCheckAccountStatus_DatabaseErrorFailsOpen(t *testing.T) {
+	svc := NewUserService(nil, 20, 100, time.Minute)
+	svc.queryStatus = func(context.Context, string) (models.UserStatus, *time.Time, error) {
+		return "", nil, context.DeadlineExceeded
+	}
+	if err := svc.CheckAccountStatus(context.Background(), "user-1"); err != nil {
+		t.Errorf("CheckAccountStatus() = %v, want nil on a database error", err)
+	}
+}
@@ -48,48 +48,6 @@ func TestProfileUpdate_PartialUpdatePreservesZeroValues(t *testing.T) {
 	if update.LastName != "" {
 		t.Error("LastName should be empty for partial update")
 	}
-	if update.AvatarURLSet {
-		t.Error("AvatarURLSet should be false for partial update")
-	}
-}
-
-func TestProfileUpdate_AvatarURLSetFlag(t *testing.T) {
-	tests := []struct {
-		name         string
-		update       ProfileUpdate
-		wantURL      string
-		wantURLSet   bool
-	}{
-		{
-			name:       "avatar not provided",
-			update:     ProfileUpdate{FirstName: "John"},
-			wantURL:    "",
-			wantURLSet: false,
-		},
-		{
-			name:       "avatar explicitly set",
-			update:     ProfileUpdate{AvatarURL: "https://example.com/img.png", AvatarURLSet: true},
-			wantURL:    "https://example.com/img.png",
-			wantURLSet: true,
-		},
-		{
-			name:       "avatar explicitly cleared",
-			update:     ProfileUpdate{AvatarURL: "", AvatarURLSet: true},
-			wantURL:    "",
-			wantURLSet: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if tt.update.AvatarURL != tt.wantURL {
-				t.Errorf("AvatarURL = %q, want %q", tt.update.AvatarURL, tt.wantURL)
-			}
-			if tt.update.AvatarURLSet != tt.wantURLSet {
-				t.Errorf("AvatarURLSet = %v, want %v", tt.update.AvatarURLSet, tt.wantURLSet)
-			}
-		})
-	}
 }
 
 func TestNilIfEmpty(t *testing.T) {
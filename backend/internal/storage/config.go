@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"github.com/golid-ai/golid/backend/internal/config"
+)
+
+// FromConfig builds the backend named by STORAGE_BACKEND. Shared by the
+// API and the worker so both address the same objects.
+func FromConfig(cfg *config.Config) (Blob, error) {
+	if cfg.StorageBackend != "s3" {
+		return NewLocal(cfg.StorageLocalDir), nil
+	}
+	return NewS3(S3Config{
+		Endpoint:  cfg.StorageS3Endpoint,
+		Region:    cfg.StorageS3Region,
+		Bucket:    cfg.StorageS3Bucket,
+		AccessKey: cfg.StorageS3AccessKey,
+		SecretKey: cfg.StorageS3SecretKey,
+		PathStyle: cfg.StorageS3PathStyle,
+	})
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Local stores objects as files under a root directory. Writes go to a
+// temp file in the target directory and are renamed into place, so readers
+// never observe a partial object.
+type Local struct {
+	root string
+}
+
+// NewLocal creates a filesystem backend rooted at dir. The directory is
+// created on first write.
+func NewLocal(dir string) *Local {
+	return &Local{root: dir}
+}
+
+func (l *Local) path(key string) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.root, filepath.FromSlash(key)), nil
+}
+
+// Put writes r to key. If size is non-negative, the write fails unless
+// exactly size bytes were read.
+func (l *Local) Put(_ context.Context, key string, r io.Reader, size int64, _ string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0o750); err != nil {
+		return fmt.Errorf("storage: create dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("storage: create temp file: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	n, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("storage: write %s: %w", key, err)
+	}
+	if size >= 0 && n != size {
+		return fmt.Errorf("storage: write %s: got %d bytes, want %d", key, n, size)
+	}
+
+	if err := os.Rename(tmp.Name(), p); err != nil {
+		return fmt.Errorf("storage: rename %s: %w", key, err)
+	}
+	return nil
+}
+
+// Get opens key for reading. The caller must close the reader.
+func (l *Local) Get(_ context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Open(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("storage: open %s: %w", key, err)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, nil, fmt.Errorf("storage: stat %s: %w", key, err)
+	}
+	return f, &ObjectInfo{Key: key, Size: st.Size()}, nil
+}
+
+// Stat returns metadata for key.
+func (l *Local) Stat(_ context.Context, key string) (*ObjectInfo, error) {
+	p, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	st, err := os.Stat(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: stat %s: %w", key, err)
+	}
+	return &ObjectInfo{Key: key, Size: st.Size()}, nil
+}
+
+// Delete removes key. Deleting a missing key is not an error.
+func (l *Local) Delete(_ context.Context, key string) error {
+	p, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("storage: delete %s: %w", key, err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golid-ai/golid/backend/internal/config"
+)
+
+func TestLocal_Contract(t *testing.T) {
+	testBlobContract(t, NewLocal(t.TempDir()))
+}
+
+func TestLocal_SizeMismatchLeavesNothing(t *testing.T) {
+	dir := t.TempDir()
+	l := NewLocal(dir)
+
+	err := l.Put(context.Background(), "a/b", bytes.NewReader([]byte("short")), 100, "")
+	if err == nil {
+		t.Fatal("Put() with wrong size should fail")
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(dir, "a"))
+	if len(entries) != 0 {
+		t.Errorf("left %d files behind after failed put", len(entries))
+	}
+}
+
+func TestFromConfig_SelectsBackend(t *testing.T) {
+	blob, err := FromConfig(&config.Config{StorageBackend: "local", StorageLocalDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("FromConfig(local) error = %v", err)
+	}
+	if _, ok := blob.(*Local); !ok {
+		t.Errorf("FromConfig(local) = %T, want *Local", blob)
+	}
+
+	blob, err = FromConfig(&config.Config{StorageBackend: "s3", StorageS3Endpoint: "http://minio:9000", StorageS3Bucket: "b"})
+	if err != nil {
+		t.Fatalf("FromConfig(s3) error = %v", err)
+	}
+	if _, ok := blob.(*S3); !ok {
+		t.Errorf("FromConfig(s3) = %T, want *S3", blob)
+	}
+
+	if _, err := FromConfig(&config.Config{StorageBackend: "s3"}); err == nil {
+		t.Error("FromConfig(s3) without endpoint should fail")
+	}
+}
@@ -0,0 +1,174 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// S3Config configures the S3-compatible backend.
+type S3Config struct {
+	Endpoint  string // e.g. "https://s3.us-east-1.amazonaws.com" or "http://localhost:9000"
+	Region    string // default "us-east-1"
+	Bucket    string
+	AccessKey string
+	SecretKey string
+	// PathStyle addresses objects as {endpoint}/{bucket}/{key} instead of
+	// {bucket}.{endpoint}/{key}. Required by most local stand-ins (MinIO).
+	PathStyle bool
+	Timeout   time.Duration // per request; default 60s
+}
+
+// S3 stores objects in an S3-compatible bucket, signing requests with
+// SigV4. Uploads use UNSIGNED-PAYLOAD so bodies stream without being
+// hashed twice; integrity is covered by TLS and the checksum we record.
+type S3 struct {
+	endpoint  *url.URL
+	bucket    string
+	pathStyle bool
+	signer    *sigV4Signer
+	client    *http.Client
+	now       func() time.Time
+}
+
+// NewS3 creates an S3 backend. It fails only on an unparseable endpoint;
+// credentials are checked by the server on first use.
+func NewS3(cfg S3Config) (*S3, error) {
+	u, err := url.Parse(cfg.Endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("storage: invalid S3 endpoint %q", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("storage: S3 bucket is required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Timeout == 0 {
+		cfg.Timeout = 60 * time.Second
+	}
+	return &S3{
+		endpoint:  u,
+		bucket:    cfg.Bucket,
+		pathStyle: cfg.PathStyle,
+		signer: &sigV4Signer{
+			accessKey: cfg.AccessKey,
+			secretKey: cfg.SecretKey,
+			region:    cfg.Region,
+		},
+		client: &http.Client{Timeout: cfg.Timeout},
+		now:    time.Now,
+	}, nil
+}
+
+func (s *S3) objectURL(key string) *url.URL {
+	u := *s.endpoint
+	base := strings.TrimSuffix(u.Path, "/")
+	if s.pathStyle {
+		u.Path = base + "/" + s.bucket + "/" + key
+	} else {
+		u.Host = s.bucket + "." + u.Host
+		u.Path = base + "/" + key
+	}
+	u.RawPath = ""
+	return &u
+}
+
+func (s *S3) do(ctx context.Context, method, key string, body io.Reader, size int64, header http.Header) (*http.Response, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key).String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("storage: build request: %w", err)
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	payloadHash := emptyPayloadHash
+	if body != nil {
+		req.ContentLength = size
+		payloadHash = unsignedPayload
+	}
+	s.signer.sign(req, payloadHash, s.now())
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("storage: %s %s: %w", method, key, err)
+	}
+	return resp, nil
+}
+
+// Put uploads r to key. size must be the exact body length; S3 rejects
+// uploads without a Content-Length.
+func (s *S3) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	if size < 0 {
+		return fmt.Errorf("storage: S3 put requires a known size")
+	}
+	header := http.Header{}
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(ctx, http.MethodPut, key, r, size, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // best-effort close
+	return checkS3Response(resp, http.MethodPut, key)
+}
+
+// Get downloads key. The caller must close the reader.
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, 0, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkS3Response(resp, http.MethodGet, key); err != nil {
+		_ = resp.Body.Close()
+		return nil, nil, err
+	}
+	return resp.Body, &ObjectInfo{Key: key, Size: resp.ContentLength, ContentType: resp.Header.Get("Content-Type")}, nil
+}
+
+// Stat issues a HEAD request for key.
+func (s *S3) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	resp, err := s.do(ctx, http.MethodHead, key, nil, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck // best-effort close
+	if err := checkS3Response(resp, http.MethodHead, key); err != nil {
+		return nil, err
+	}
+	return &ObjectInfo{Key: key, Size: resp.ContentLength, ContentType: resp.Header.Get("Content-Type")}, nil
+}
+
+// Delete removes key. S3 reports success for missing keys.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, 0, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck // best-effort close
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	return checkS3Response(resp, http.MethodDelete, key)
+}
+
+// checkS3Response maps non-2xx responses to errors, keeping a bounded
+// excerpt of the XML error body for logs.
+func checkS3Response(resp *http.Response, method, key string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	excerpt, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("storage: %s %s: %s: %s", method, key, resp.Status, strings.TrimSpace(string(excerpt)))
+}
@@ -0,0 +1,139 @@
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeS3 is a minimal in-memory S3 stand-in. It records request hosts and
+// paths so tests can check addressing, and rejects unsigned requests.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+	hosts   []string
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{objects: map[string][]byte{}, types: map[string]string{}}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.hosts = append(f.hosts, r.Host)
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") ||
+		r.Header.Get("X-Amz-Date") == "" || r.Header.Get("X-Amz-Content-Sha256") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	key := r.URL.Path
+	switch r.Method {
+	case http.MethodPut:
+		if r.ContentLength < 0 {
+			w.WriteHeader(http.StatusLengthRequired)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		f.objects[key] = data
+		f.types[key] = r.Header.Get("Content-Type")
+	case http.MethodGet, http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, "<Error><Code>NoSuchKey</Code></Error>")
+			return
+		}
+		w.Header().Set("Content-Type", f.types[key])
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(data)
+		}
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newTestS3(t *testing.T, fake *fakeS3) *S3 {
+	t.Helper()
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	s, err := NewS3(S3Config{
+		Endpoint:  srv.URL,
+		Bucket:    "golid",
+		AccessKey: "AKID",
+		SecretKey: "secret",
+		PathStyle: true,
+	})
+	if err != nil {
+		t.Fatalf("NewS3() error = %v", err)
+	}
+	return s
+}
+
+func TestS3_Contract(t *testing.T) {
+	fake := newFakeS3()
+	testBlobContract(t, newTestS3(t, fake))
+}
+
+func TestS3_PathStyleAddressingAndContentType(t *testing.T) {
+	fake := newFakeS3()
+	s := newTestS3(t, fake)
+
+	if err := s.Put(context.Background(), "a/b.png", strings.NewReader("png"), 3, "image/png"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, ok := fake.objects["/golid/a/b.png"]; !ok {
+		t.Errorf("objects = %v, want key under /golid/", fake.objects)
+	}
+	info, err := s.Stat(context.Background(), "a/b.png")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.ContentType != "image/png" {
+		t.Errorf("ContentType = %q, want image/png", info.ContentType)
+	}
+}
+
+func TestS3_VirtualHostedURL(t *testing.T) {
+	s, err := NewS3(S3Config{Endpoint: "https://s3.us-east-1.amazonaws.com", Bucket: "golid"})
+	if err != nil {
+		t.Fatalf("NewS3() error = %v", err)
+	}
+	if got := s.objectURL("a/b").String(); got != "https://golid.s3.us-east-1.amazonaws.com/a/b" {
+		t.Errorf("objectURL = %q", got)
+	}
+}
+
+func TestS3_ErrorResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, "<Error><Code>SignatureDoesNotMatch</Code></Error>")
+	}))
+	defer srv.Close()
+
+	s, _ := NewS3(S3Config{Endpoint: srv.URL, Bucket: "b", PathStyle: true})
+	err := s.Put(context.Background(), "k", strings.NewReader("x"), 1, "")
+	if err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("err = %v, want SignatureDoesNotMatch excerpt", err)
+	}
+}
+
+func TestNewS3_Validation(t *testing.T) {
+	if _, err := NewS3(S3Config{Endpoint: "not a url", Bucket: "b"}); err == nil {
+		t.Error("expected error for invalid endpoint")
+	}
+	if _, err := NewS3(S3Config{Endpoint: "http://localhost:9000"}); err == nil {
+		t.Error("expected error for missing bucket")
+	}
+}
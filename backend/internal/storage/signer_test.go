@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestURLSigner_RoundTrip(t *testing.T) {
+	s := NewURLSigner("secret")
+	exp := time.Now().Add(time.Minute)
+	sig := s.Sign(OpDownload, "file-1", exp)
+	expires := strconv.FormatInt(exp.Unix(), 10)
+
+	if err := s.Verify(OpDownload, "file-1", expires, sig); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	tests := []struct {
+		name, op, id, expires, sig string
+	}{
+		{"other op", OpUpload, "file-1", expires, sig},
+		{"other id", OpDownload, "file-2", expires, sig},
+		{"extended expiry", OpDownload, "file-1", strconv.FormatInt(exp.Unix()+3600, 10), sig},
+		{"garbage sig", OpDownload, "file-1", expires, "zz"},
+		{"garbage expiry", OpDownload, "file-1", "soon", sig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Verify(tt.op, tt.id, tt.expires, tt.sig); !errors.Is(err, ErrURLSignature) {
+				t.Errorf("Verify() = %v, want ErrURLSignature", err)
+			}
+		})
+	}
+
+	if err := NewURLSigner("other").Verify(OpDownload, "file-1", expires, sig); !errors.Is(err, ErrURLSignature) {
+		t.Errorf("Verify() with other secret = %v, want ErrURLSignature", err)
+	}
+}
+
+func TestURLSigner_Expired(t *testing.T) {
+	s := NewURLSigner("secret")
+	exp := time.Now().Add(time.Minute)
+	sig := s.Sign(OpUpload, "f", exp)
+
+	s.now = func() time.Time { return exp.Add(time.Second) }
+	if err := s.Verify(OpUpload, "f", strconv.FormatInt(exp.Unix(), 10), sig); !errors.Is(err, ErrURLExpired) {
+		t.Errorf("Verify() = %v, want ErrURLExpired", err)
+	}
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// SniffLength is the number of leading bytes DetectContentType inspects.
+const SniffLength = 512
+
+// DetectContentType sniffs the media type of data (only the first
+// SniffLength bytes are considered) and returns it without parameters,
+// e.g. "text/plain" rather than "text/plain; charset=utf-8". The
+// client-declared type is never trusted.
+func DetectContentType(data []byte) string {
+	ct := http.DetectContentType(data)
+	if mediaType, _, err := mime.ParseMediaType(ct); err == nil {
+		return mediaType
+	}
+	return ct
+}
+
+// TypeAllowed reports whether mediaType is in allowed. Entries may be
+// exact types ("image/png") or type wildcards ("image/*").
+func TypeAllowed(mediaType string, allowed []string) bool {
+	for _, a := range allowed {
+		a = strings.ToLower(strings.TrimSpace(a))
+		if a == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
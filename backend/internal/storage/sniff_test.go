@@ -0,0 +1,35 @@
+package storage
+
+import "testing"
+
+func TestDetectContentType(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), "image/png"},
+		{"jpeg", []byte("\xff\xd8\xff\xe0\x00\x10JFIF"), "image/jpeg"},
+		{"pdf", []byte("%PDF-1.7\n"), "application/pdf"},
+		{"text strips charset", []byte("hello world"), "text/plain"},
+		{"html is not trusted as image", []byte("<html><script>x</script>"), "text/html"},
+		{"binary", []byte{0x00, 0x01, 0x02, 0x03}, "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectContentType(tt.data); got != tt.want {
+				t.Errorf("DetectContentType() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTypeAllowed(t *testing.T) {
+	allowed := []string{"image/*", "application/pdf"}
+	if !TypeAllowed("image/png", allowed) || !TypeAllowed("application/pdf", allowed) {
+		t.Error("expected image/png and application/pdf to be allowed")
+	}
+	if TypeAllowed("text/html", allowed) || TypeAllowed("imagex/png", allowed) {
+		t.Error("expected text/html and imagex/png to be rejected")
+	}
+}
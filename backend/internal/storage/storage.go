@@ -0,0 +1,60 @@
+// Package storage provides blob storage behind a small Blob interface with
+// two backends — the local filesystem (Local) and S3-compatible object
+// stores (S3, e.g. AWS S3 or a local MinIO stand-in) — plus HMAC-signed,
+// time-limited URLs (URLSigner) and content-type sniffing for uploads.
+//
+// Keys are slash-separated relative paths such as "uploads/<owner>/<id>".
+// Metadata (owner, size, checksum, content type) lives in the files table,
+// not in the backend; see service/file.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrNotFound is returned when a key does not exist in the backend.
+var ErrNotFound = errors.New("storage: object not found")
+
+// ObjectInfo describes a stored object. ContentType is empty when the
+// backend does not record it (Local).
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+}
+
+// Blob is a minimal object store. Implementations are safe for concurrent
+// use. Put replaces any existing object at key.
+type Blob interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// maxKeyLength matches the S3 limit so keys are portable across backends.
+const maxKeyLength = 1024
+
+// ValidateKey rejects keys that could escape the storage root or behave
+// differently between backends: empty or absolute paths, "." or ".."
+// segments, empty segments, backslashes, and control characters.
+func ValidateKey(key string) error {
+	if key == "" || len(key) > maxKeyLength {
+		return fmt.Errorf("storage: invalid key length")
+	}
+	for _, r := range key {
+		if r < 0x20 || r == 0x7f || r == '\\' {
+			return fmt.Errorf("storage: invalid character in key %q", key)
+		}
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return fmt.Errorf("storage: invalid key %q", key)
+		}
+	}
+	return nil
+}
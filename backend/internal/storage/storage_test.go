@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+// testBlobContract exercises the behavior every Blob backend must share.
+func testBlobContract(t *testing.T, b Blob) {
+	t.Helper()
+	ctx := context.Background()
+	data := []byte("hello, storage")
+
+	if err := b.Put(ctx, "uploads/u1/f1", bytes.NewReader(data), int64(len(data)), "text/plain"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	info, err := b.Stat(ctx, "uploads/u1/f1")
+	if err != nil {
+		t.Fatalf("Stat() error = %v", err)
+	}
+	if info.Size != int64(len(data)) {
+		t.Errorf("Stat().Size = %d, want %d", info.Size, len(data))
+	}
+
+	rc, info, err := b.Get(ctx, "uploads/u1/f1")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	got, _ := io.ReadAll(rc)
+	_ = rc.Close()
+	if !bytes.Equal(got, data) || info.Size != int64(len(data)) {
+		t.Errorf("Get() = %q (size %d), want %q", got, info.Size, data)
+	}
+
+	if _, _, err := b.Get(ctx, "uploads/u1/missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(missing) err = %v, want ErrNotFound", err)
+	}
+	if _, err := b.Stat(ctx, "uploads/u1/missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat(missing) err = %v, want ErrNotFound", err)
+	}
+
+	if err := b.Delete(ctx, "uploads/u1/f1"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := b.Stat(ctx, "uploads/u1/f1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Stat() after delete err = %v, want ErrNotFound", err)
+	}
+	if err := b.Delete(ctx, "uploads/u1/f1"); err != nil {
+		t.Errorf("Delete() of missing key error = %v, want nil", err)
+	}
+
+	if err := b.Put(ctx, "../escape", bytes.NewReader(data), int64(len(data)), ""); err == nil {
+		t.Error("Put() with traversal key should fail")
+	}
+}
+
+func TestValidateKey(t *testing.T) {
+	valid := []string{"a", "uploads/u1/f1", "avatars/x.y-z_1"}
+	for _, k := range valid {
+		if err := ValidateKey(k); err != nil {
+			t.Errorf("ValidateKey(%q) = %v, want nil", k, err)
+		}
+	}
+	invalid := []string{"", "/abs", "a//b", "a/../b", "./a", "a/", `a\b`, "a\x00b", "a\nb"}
+	for _, k := range invalid {
+		if err := ValidateKey(k); err == nil {
+			t.Errorf("ValidateKey(%q) = nil, want error", k)
+		}
+	}
+}
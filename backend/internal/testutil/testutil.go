@@ -137,6 +137,7 @@ func (db *TestDB) CleanAllTables(ctx context.Context) error {
 	tables := []string{
 		"refresh_tokens",
 		"feature_flags",
+		"outbox",
 		"users",
 	}
 	return db.CleanTables(ctx, tables...)
@@ -3,7 +3,6 @@ package validate
 
 import (
 	"regexp"
-	"strconv"
 	"strings"
 
 	"github.com/google/uuid"
@@ -15,10 +14,10 @@ import (
 // Returns a BadRequest error if invalid.
 func UUID(id string, fieldName string) error {
 	if id == "" {
-		return apperror.BadRequest(fieldName + " is required")
+		return apperror.BadRequest(fieldName+" is required").WithMessageID("error.field_required", fieldName)
 	}
 	if _, err := uuid.Parse(id); err != nil {
-		return apperror.BadRequest(fieldName + " must be a valid UUID")
+		return apperror.BadRequest(fieldName+" must be a valid UUID").WithMessageID("error.field_uuid", fieldName)
 	}
 	return nil
 }
@@ -35,12 +34,12 @@ func UUIDOrNil(id string, fieldName string) error {
 // Email validates an email address format.
 func Email(email string) error {
 	if email == "" {
-		return apperror.BadRequest("Email is required")
+		return apperror.BadRequest("Email is required").WithMessageID("error.auth.email_required")
 	}
 	// Basic email regex - not exhaustive but catches obvious issues
 	emailRegex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 	if !emailRegex.MatchString(email) {
-		return apperror.BadRequest("Invalid email format")
+		return apperror.BadRequest("Invalid email format").WithMessageID("error.auth.email_invalid")
 	}
 	return nil
 }
@@ -48,7 +47,7 @@ func Email(email string) error {
 // Password validates password strength.
 func Password(password string) error {
 	if len(password) < 8 {
-		return apperror.BadRequest("Password must be at least 8 characters")
+		return apperror.BadRequest("Password must be at least 8 characters").WithMessageID("error.auth.password_too_short")
 	}
 	return nil
 }
@@ -56,7 +55,7 @@ func Password(password string) error {
 // Required validates that a string is not empty.
 func Required(value string, fieldName string) error {
 	if strings.TrimSpace(value) == "" {
-		return apperror.BadRequest(fieldName + " is required")
+		return apperror.BadRequest(fieldName+" is required").WithMessageID("error.field_required", fieldName)
 	}
 	return nil
 }
@@ -64,9 +63,7 @@ func Required(value string, fieldName string) error {
 // MinLength validates minimum string length.
 func MinLength(value string, minLen int, fieldName string) error {
 	if len(value) < minLen {
-		return apperror.Validation("Validation failed", map[string]string{
-			fieldName: strings.ToLower(fieldName) + " must be at least " + strconv.Itoa(minLen) + " characters",
-		})
+		return apperror.Validation().WithDetail(fieldName, "error.field_min_length", strings.ToLower(fieldName), minLen)
 	}
 	return nil
 }
@@ -9,10 +9,18 @@ import (
 // Handlers bundles every constructed handler. Returned by BuildHandlers
 // and consumed by RegisterRoutes.
 type Handlers struct {
-	Auth    *handler.AuthHandler
-	User    *handler.UserHandler
-	Feature *handler.FeatureHandler
-	SSE     *handler.SSEHandler
+	Auth          *handler.AuthHandler
+	User          *handler.UserHandler
+	Feature       *handler.FeatureHandler
+	SSE           *handler.SSEHandler
+	Challenge     *handler.ChallengeHandler
+	AdminUsers    *handler.AdminUserHandler
+	Files         *handler.FileHandler
+	Avatars       *handler.AvatarHandler
+	Exports       *handler.ExportHandler
+	Prefs         *handler.PreferenceHandler
+	Notifications *handler.NotificationHandler
+	Announcements *handler.AnnouncementHandler
 }
 
 // BuildHandlers constructs every HTTP handler from the already-built
@@ -20,9 +28,18 @@ type Handlers struct {
 // fall back to inline goroutines when queue.IsConfigured() is false.
 func BuildHandlers(svcs *Services, cfg *config.Config, jobQueue *queue.Queue) *Handlers {
 	return &Handlers{
-		Auth:    handler.NewAuthHandler(svcs.Auth, svcs.Email, jobQueue, cfg.RetryAttempts, cfg.RetryDelay),
-		User:    handler.NewUserHandler(svcs.Users),
-		Feature: handler.NewFeatureHandler(svcs.Feature),
-		SSE:     handler.NewSSEHandler(svcs.SSEHub, cfg.SSEKeepaliveInterval),
+		Auth:      handler.NewAuthHandler(svcs.Auth, svcs.Email, jobQueue, cfg.RetryAttempts, cfg.RetryDelay),
+		User:      handler.NewUserHandler(svcs.Users),
+		Feature:   handler.NewFeatureHandler(svcs.Feature, cfg.PaginationDefault, cfg.PaginationMax),
+		SSE:       handler.NewSSEHandler(svcs.SSEHub, svcs.Notifications, cfg.SSEKeepaliveInterval),
+		Challenge: handler.NewChallengeHandler(svcs.PoW),
+		AdminUsers: handler.NewAdminUserHandler(svcs.Users, svcs.Auth, svcs.Email, svcs.Prefs, svcs.SSEHub,
+			cfg.PaginationDefault, cfg.PaginationMax),
+		Files:         handler.NewFileHandler(svcs.Files),
+		Avatars:       handler.NewAvatarHandler(svcs.Avatars, cfg.AvatarMaxUploadSize),
+		Exports:       handler.NewExportHandler(svcs.Exports),
+		Prefs:         handler.NewPreferenceHandler(svcs.Prefs),
+		Notifications: handler.NewNotificationHandler(svcs.Notifications, cfg.PaginationDefault, cfg.PaginationMax),
+		Announcements: handler.NewAnnouncementHandler(svcs.Announcements, cfg.PaginationDefault, cfg.PaginationMax),
 	}
 }
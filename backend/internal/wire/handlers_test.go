@@ -21,4 +21,25 @@ func TestBuildHandlers_ReturnsNonNilHandlers(t *testing.T) {
 	if h.SSE == nil {
 		t.Error("SSE handler is nil")
 	}
+	if h.Challenge == nil {
+		t.Error("Challenge handler is nil")
+	}
+	if h.AdminUsers == nil {
+		t.Error("AdminUsers handler is nil")
+	}
+	if h.Files == nil {
+		t.Error("Files handler is nil")
+	}
+	if h.Exports == nil {
+		t.Error("Exports handler is nil")
+	}
+	if h.Prefs == nil {
+		t.Error("Prefs handler is nil")
+	}
+	if h.Notifications == nil {
+		t.Error("Notifications handler is nil")
+	}
+	if h.Announcements == nil {
+		t.Error("Announcements handler is nil")
+	}
 }
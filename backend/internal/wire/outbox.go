@@ -0,0 +1,22 @@
+package wire
+
+import (
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/golid-ai/golid/backend/internal/config"
+	"github.com/golid-ai/golid/backend/internal/queue"
+	"github.com/golid-ai/golid/backend/internal/service/outbox"
+)
+
+// BuildOutboxRelay constructs the relay that delivers outbox messages.
+// Tasks go to jobQueue when Redis is configured; otherwise the relay runs
+// them in process through the same handlers the worker serves.
+func BuildOutboxRelay(svcs *Services, cfg *config.Config, pool *pgxpool.Pool, jobQueue *queue.Queue) *outbox.Relay {
+	return outbox.NewRelay(pool, jobQueue,
+		queue.NewServeMux(svcs.Email, svcs.Avatars, svcs.Exports), svcs.SSEHub, outbox.Config{
+			MaxAttempts:    cfg.OutboxMaxAttempts,
+			TaskMaxRetry:   queue.TaskMaxRetry,
+			WebhookSecret:  cfg.OutboxWebhookSecret,
+			WebhookTimeout: cfg.OutboxWebhookTimeout,
+		})
+}
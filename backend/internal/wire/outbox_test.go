@@ -0,0 +1,18 @@
+package wire
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golid-ai/golid/backend/internal/queue"
+)
+
+func TestBuildOutboxRelay_ReturnsRelay(t *testing.T) {
+	cfg := testWireConfig()
+	pool := newTestPool(t)
+	svcs := BuildServices(context.Background(), cfg, pool)
+
+	if relay := BuildOutboxRelay(svcs, cfg, pool, queue.New("")); relay == nil {
+		t.Fatal("BuildOutboxRelay returned nil")
+	}
+}
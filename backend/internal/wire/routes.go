@@ -14,13 +14,14 @@ import (
 //
 // jwtMW is the configured JWT middleware. We pass it in rather than
 // constructing it here so main.go retains ownership of the JWT secret.
-func RegisterRoutes(e *echo.Echo, h *Handlers, _ *Services, cfg *config.Config, jwtMW echo.MiddlewareFunc) {
+func RegisterRoutes(e *echo.Echo, h *Handlers, svcs *Services, cfg *config.Config, jwtMW echo.MiddlewareFunc) {
 	api := e.Group("/api/v1")
 	api.Use(middleware.APIVersion("v1"))
+	api.Use(middleware.Locale(svcs.Prefs))
 	api.Use(middleware.CSRF(cfg.CSRFEnforce, logger.Logger()))
 	api.Use(middleware.RateLimiter(cfg.RateLimitRequests, cfg.RateLimitWindow))
 
-	registerPublicRoutes(api, h, cfg)
+	registerPublicRoutes(api, h, svcs, cfg)
 
 	protected := api.Group("")
 	protected.Use(jwtMW)
@@ -28,13 +29,22 @@ func RegisterRoutes(e *echo.Echo, h *Handlers, _ *Services, cfg *config.Config,
 	registerProtectedRoutes(protected, h)
 	registerAdminRoutes(protected, h)
 	registerSSERoutes(api, protected, h, cfg)
+	registerFileRoutes(api, protected, h)
+	registerAvatarRoutes(api, protected, h)
+	registerExportRoutes(api, protected, h)
+	registerAnnouncementRoutes(api, protected, h, jwtMW)
 }
 
-func registerPublicRoutes(api *echo.Group, h *Handlers, cfg *config.Config) {
+func registerPublicRoutes(api *echo.Group, h *Handlers, svcs *Services, cfg *config.Config) {
 	api.GET("/features", h.Feature.ListEnabled)
 
+	// Challenge issuance sits outside authGroup so fetching one neither
+	// spends the strict auth rate limit nor trips the PoW gate itself.
+	api.GET("/auth/challenge", h.Challenge.Challenge)
+
 	authGroup := api.Group("/auth")
 	authGroup.Use(middleware.StrictRateLimiter(cfg.AuthRateLimitRequests))
+	authGroup.Use(middleware.ProofOfWork(svcs.PoW, cfg.PoWFailureThreshold, cfg.PoWFailureWindow))
 	authGroup.POST("/register", h.Auth.Register)
 	authGroup.POST("/login", h.Auth.Login)
 	authGroup.POST("/refresh", h.Auth.Refresh)
@@ -50,21 +60,84 @@ func registerProtectedRoutes(protected *echo.Group, h *Handlers) {
 	protected.PUT("/auth/password", h.Auth.ChangePassword)
 	protected.GET("/me", h.User.Me)
 	protected.PUT("/me", h.User.UpdateProfile)
+	protected.GET("/me/preferences", h.Prefs.Get)
+	protected.PATCH("/me/preferences", h.Prefs.Update)
+	protected.GET("/me/notifications", h.Notifications.List)
+	protected.PATCH("/me/notifications", h.Notifications.Update)
+	protected.GET("/me/features", h.Feature.ListForUser)
 }
 
 func registerAdminRoutes(protected *echo.Group, h *Handlers) {
 	admin := protected.Group("/admin")
 	admin.Use(middleware.RequireRole("admin"))
 	admin.GET("/features", h.Feature.List)
+	admin.POST("/features", h.Feature.Create)
+	admin.GET("/features/:key", h.Feature.Get)
 	admin.PUT("/features/:key", h.Feature.Set)
+	admin.PATCH("/features/:key", h.Feature.Update)
+	admin.DELETE("/features/:key", h.Feature.Delete)
+	admin.PUT("/features/:key/rules", h.Feature.SetRules)
+	admin.PUT("/features/:key/values", h.Feature.SetValues)
+	admin.GET("/features/:key/history", h.Feature.History)
+	admin.POST("/features/:key/rollback", h.Feature.Rollback)
+	admin.POST("/feature-schedules", h.Feature.Schedule)
+	admin.GET("/feature-schedules", h.Feature.ListSchedules)
+	admin.DELETE("/feature-schedules/:id", h.Feature.CancelSchedule)
+	admin.GET("/feature-reports/stale", h.Feature.Stale)
+	admin.GET("/users", h.AdminUsers.List)
+	admin.GET("/users/:id", h.AdminUsers.Get)
+	admin.PATCH("/users/:id", h.AdminUsers.Update)
+	admin.PUT("/users/:id/status", h.AdminUsers.SetStatus)
+	admin.GET("/announcements", h.Announcements.AdminList)
+	admin.POST("/announcements", h.Announcements.AdminCreate)
+	admin.GET("/announcements/:id", h.Announcements.AdminGet)
+	admin.PATCH("/announcements/:id", h.Announcements.AdminUpdate)
+	admin.DELETE("/announcements/:id", h.Announcements.AdminDelete)
 }
 
-// SSE routes — stream endpoint uses ticket auth (EventSource cannot set
-// headers, so JWT-in-header doesn't work). Demo endpoint is dev-only.
+// SSE routes — stream and WebSocket endpoints use ticket auth (EventSource
+// and browser WebSockets cannot set headers, so JWT-in-header doesn't
+// work). Demo endpoint is dev-only.
 func registerSSERoutes(api, protected *echo.Group, h *Handlers, cfg *config.Config) {
 	api.GET("/events/stream", h.SSE.Stream)
+	api.GET("/events/ws", h.SSE.Socket)
 	protected.POST("/events/ticket", h.SSE.Ticket)
+	protected.GET("/presence", h.SSE.Presence)
 	if cfg.IsDevelopment() {
 		protected.POST("/events/demo", h.SSE.Demo)
 	}
 }
+
+// File routes — content endpoints authenticate with a signed URL instead
+// of a JWT so browsers can PUT/GET them directly.
+func registerFileRoutes(api, protected *echo.Group, h *Handlers) {
+	protected.POST("/files", h.Files.CreateUpload)
+	protected.GET("/files/:id", h.Files.Get)
+	protected.DELETE("/files/:id", h.Files.Delete)
+	api.PUT("/files/:id/content", h.Files.Upload)
+	api.GET("/files/:id/content", h.Files.Download)
+}
+
+// Avatar routes — renditions are public and immutable so they can be
+// cached by browsers and CDNs; uploads and removal are JWT-protected.
+func registerAvatarRoutes(api, protected *echo.Group, h *Handlers) {
+	protected.POST("/me/avatar", h.Avatars.Upload)
+	protected.DELETE("/me/avatar", h.Avatars.Delete)
+	api.GET("/avatars/:user_id/:upload_id/:file", h.Avatars.Serve)
+}
+
+// Export routes — the download endpoint authenticates with a signed URL so
+// the link in the "export ready" email works without a session.
+func registerExportRoutes(api, protected *echo.Group, h *Handlers) {
+	protected.POST("/me/export", h.Exports.Request)
+	protected.GET("/me/exports/:id", h.Exports.Get)
+	api.GET("/exports/:id/download", h.Exports.Download)
+}
+
+// Announcement routes — the list is public, so banners show before sign-in,
+// but a token is still honored to pick the caller's audience and hide what
+// they dismissed.
+func registerAnnouncementRoutes(api, protected *echo.Group, h *Handlers, jwtMW echo.MiddlewareFunc) {
+	api.GET("/announcements", h.Announcements.List, middleware.OptionalAuth(jwtMW))
+	protected.POST("/announcements/:id/dismiss", h.Announcements.Dismiss)
+}
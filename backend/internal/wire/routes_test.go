@@ -41,6 +41,7 @@ func TestRegisterRoutes_MountsV1Groups(t *testing.T) {
 	assertRoute(t, routes, http.MethodPost, "/api/v1/auth/reset-password")
 	assertRoute(t, routes, http.MethodGet, "/api/v1/auth/verify-email")
 	assertRoute(t, routes, http.MethodPost, "/api/v1/auth/resend-verification")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/auth/challenge")
 
 	// Protected routes
 	assertRoute(t, routes, http.MethodPost, "/api/v1/auth/logout")
@@ -50,13 +51,56 @@ func TestRegisterRoutes_MountsV1Groups(t *testing.T) {
 
 	// Admin routes
 	assertRoute(t, routes, http.MethodGet, "/api/v1/admin/features")
+	assertRoute(t, routes, http.MethodPost, "/api/v1/admin/features")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/admin/features/:key")
 	assertRoute(t, routes, http.MethodPut, "/api/v1/admin/features/:key")
+	assertRoute(t, routes, http.MethodPatch, "/api/v1/admin/features/:key")
+	assertRoute(t, routes, http.MethodDelete, "/api/v1/admin/features/:key")
+	assertRoute(t, routes, http.MethodPut, "/api/v1/admin/features/:key/rules")
+	assertRoute(t, routes, http.MethodPut, "/api/v1/admin/features/:key/values")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/admin/features/:key/history")
+	assertRoute(t, routes, http.MethodPost, "/api/v1/admin/features/:key/rollback")
+	assertRoute(t, routes, http.MethodPost, "/api/v1/admin/feature-schedules")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/admin/feature-schedules")
+	assertRoute(t, routes, http.MethodDelete, "/api/v1/admin/feature-schedules/:id")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/admin/feature-reports/stale")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/admin/users")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/admin/users/:id")
+	assertRoute(t, routes, http.MethodPatch, "/api/v1/admin/users/:id")
+	assertRoute(t, routes, http.MethodPut, "/api/v1/admin/users/:id/status")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/admin/announcements")
+	assertRoute(t, routes, http.MethodPost, "/api/v1/admin/announcements")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/admin/announcements/:id")
+	assertRoute(t, routes, http.MethodPatch, "/api/v1/admin/announcements/:id")
+	assertRoute(t, routes, http.MethodDelete, "/api/v1/admin/announcements/:id")
 
 	// SSE routes
 	assertRoute(t, routes, http.MethodGet, "/api/v1/events/stream")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/events/ws")
 	assertRoute(t, routes, http.MethodPost, "/api/v1/events/ticket")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/presence")
 	assertRoute(t, routes, http.MethodPost, "/api/v1/events/demo")
 
+	// File routes
+	assertRoute(t, routes, http.MethodPost, "/api/v1/files")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/files/:id")
+	assertRoute(t, routes, http.MethodDelete, "/api/v1/files/:id")
+	assertRoute(t, routes, http.MethodPut, "/api/v1/files/:id/content")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/files/:id/content")
+	assertRoute(t, routes, http.MethodPost, "/api/v1/me/avatar")
+	assertRoute(t, routes, http.MethodDelete, "/api/v1/me/avatar")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/avatars/:user_id/:upload_id/:file")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/me/preferences")
+	assertRoute(t, routes, http.MethodPatch, "/api/v1/me/preferences")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/me/notifications")
+	assertRoute(t, routes, http.MethodPatch, "/api/v1/me/notifications")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/me/features")
+	assertRoute(t, routes, http.MethodPost, "/api/v1/me/export")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/me/exports/:id")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/exports/:id/download")
+	assertRoute(t, routes, http.MethodGet, "/api/v1/announcements")
+	assertRoute(t, routes, http.MethodPost, "/api/v1/announcements/:id/dismiss")
+
 	// No v2 API group
 	for _, r := range routes {
 		if len(r.Path) >= 8 && r.Path[:8] == "/api/v2/" {
@@ -1,16 +1,31 @@
 package wire
 
 import (
+	"cmp"
 	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
 
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/redis/go-redis/v9"
 
 	"github.com/golid-ai/golid/backend/internal/config"
+	"github.com/golid-ai/golid/backend/internal/logger"
+	"github.com/golid-ai/golid/backend/internal/pow"
+	"github.com/golid-ai/golid/backend/internal/queue"
+	"github.com/golid-ai/golid/backend/internal/service/announcement"
 	"github.com/golid-ai/golid/backend/internal/service/auth"
+	"github.com/golid-ai/golid/backend/internal/service/avatar"
 	"github.com/golid-ai/golid/backend/internal/service/email"
+	"github.com/golid-ai/golid/backend/internal/service/export"
 	"github.com/golid-ai/golid/backend/internal/service/feature"
+	"github.com/golid-ai/golid/backend/internal/service/file"
+	"github.com/golid-ai/golid/backend/internal/service/notification"
+	"github.com/golid-ai/golid/backend/internal/service/preference"
 	"github.com/golid-ai/golid/backend/internal/service/sse"
 	"github.com/golid-ai/golid/backend/internal/service/user"
+	"github.com/golid-ai/golid/backend/internal/storage"
 )
 
 // Services bundles every service the API needs. Constructed once at
@@ -20,18 +35,26 @@ import (
 // SSEHub and Auth are exposed back to main.go for shutdown sequencing
 // (sseHub.Shutdown) and the periodic token cleanup goroutine.
 type Services struct {
-	SSEHub  *sse.SSEHub
-	Auth    *auth.AuthService
-	Users   *user.UserService
-	Email   *email.EmailService
-	Feature *feature.FeatureService
+	SSEHub        *sse.SSEHub
+	Auth          *auth.AuthService
+	Users         *user.UserService
+	Email         *email.EmailService
+	Feature       *feature.FeatureService
+	PoW           *pow.Issuer
+	Files         *file.FileService
+	Avatars       *avatar.AvatarService
+	Exports       *export.ExportService
+	Prefs         *preference.PreferenceService
+	Notifications *notification.NotificationService
+	Announcements *announcement.AnnouncementService
 }
 
 // BuildServices constructs every service in dependency order.
 func BuildServices(_ context.Context, cfg *config.Config, pool *pgxpool.Pool) *Services {
-	sseHub := sse.NewSSEHub(cfg.SSETicketTTL)
+	rdb := newRedisClient(cfg)
+	sseHub := newSSEHub(cfg, rdb)
 	authService := auth.NewAuthService(pool, cfg.JWTSecret, cfg.AppName, cfg.JWTAccessDuration, cfg.JWTRefreshDuration, cfg.PasswordResetTTL)
-	userService := user.NewUserService(pool)
+	userService := user.NewUserService(pool, cfg.PaginationDefault, cfg.PaginationMax, cfg.AccountStatusCacheTTL)
 	emailService := email.NewEmailService(email.EmailConfig{
 		APIKey:           cfg.MailgunAPIKey,
 		Domain:           cfg.MailgunDomain,
@@ -42,13 +65,141 @@ func BuildServices(_ context.Context, cfg *config.Config, pool *pgxpool.Pool) *S
 		Timeout:          cfg.EmailTimeout,
 		PasswordResetTTL: cfg.PasswordResetTTL,
 	})
-	featureService := feature.NewFeatureService(pool, cfg.FeatureCacheTTL)
+	featureService := feature.NewFeatureService(pool, cfg.FeatureCacheTTL, newFlagInvalidator(rdb, pool))
+	powIssuer := pow.NewIssuer(cfg.PoWSecret, cfg.PoWDifficulty, cfg.PoWChallengeTTL)
+	blob := newBlobStore(cfg)
+	fileService := file.NewFileService(pool, blob, storage.NewURLSigner(cfg.StorageSigningSecret), file.Config{
+		URLTTL:       cfg.StorageURLTTL,
+		MaxSize:      cfg.StorageMaxUploadSize,
+		AllowedTypes: cfg.StorageAllowedTypes,
+		BaseURL:      cfg.StorageBaseURL,
+	})
+	avatarService := avatar.NewAvatarService(pool, blob, avatar.Config{
+		MaxUploadSize: cfg.AvatarMaxUploadSize,
+		MaxPixels:     cfg.AvatarMaxPixels,
+		BaseURL:       cfg.StorageBaseURL,
+	})
+
+	notificationService := notification.NewNotificationService(pool, sseHub, cfg.PaginationDefault, cfg.PaginationMax)
+	prefService := preference.NewPreferenceService(pool)
+	announcementService := announcement.NewAnnouncementService(pool, sseHub, cfg.PaginationDefault, cfg.PaginationMax)
+
+	// Emailed links need an absolute URL; the frontend proxies /api. A build
+	// gets the first run plus every queue retry before the export fails.
+	exportService := export.NewExportService(pool, blob, storage.NewURLSigner(cfg.StorageSigningSecret), notificationService, emailService, prefService, export.Config{
+		LinkTTL:     cfg.ExportLinkTTL,
+		BaseURL:     cmp.Or(cfg.StorageBaseURL, cfg.FrontendURL),
+		MaxAttempts: queue.TaskMaxRetry + 1,
+	})
+
+	svcs := &Services{
+		SSEHub:        sseHub,
+		Auth:          authService,
+		Users:         userService,
+		Email:         emailService,
+		Feature:       featureService,
+		PoW:           powIssuer,
+		Files:         fileService,
+		Avatars:       avatarService,
+		Exports:       exportService,
+		Prefs:         prefService,
+		Notifications: notificationService,
+		Announcements: announcementService,
+	}
+	for _, d := range implementations[preference.Declarer](svcs) {
+		prefService.Register(d.PreferenceDefs()...)
+	}
+	for _, e := range implementations[export.Exporter](svcs) {
+		exportService.Register(e)
+	}
+	for _, d := range implementations[sse.TopicDeclarer](svcs) {
+		sseHub.RegisterTopics(d.SSETopics()...)
+	}
+	for _, d := range implementations[sse.MessageDeclarer](svcs) {
+		sseHub.RegisterMessages(d.SSEMessages()...)
+	}
+	for _, d := range implementations[sse.EventPolicyDeclarer](svcs) {
+		sseHub.RegisterEventPolicies(d.SSEEventPolicies()...)
+	}
+	return svcs
+}
+
+// implementations returns every service in svcs that implements T, in
+// field order. Adding a service to Services is all a module needs to do
+// to declare preferences, SSE topics, client messages, and event overflow
+// policies, or be included in personal data exports.
+func implementations[T any](svcs *Services) []T {
+	var out []T
+	v := reflect.ValueOf(svcs).Elem()
+	for i := range v.NumField() {
+		f := v.Field(i)
+		if f.Kind() == reflect.Pointer && f.IsNil() {
+			continue
+		}
+		if impl, ok := f.Interface().(T); ok {
+			out = append(out, impl)
+		}
+	}
+	return out
+}
+
+// newBlobStore builds the configured storage backend.
+func newBlobStore(cfg *config.Config) storage.Blob {
+	blob, err := storage.FromConfig(cfg)
+	if err != nil {
+		// config.validate rejects a missing bucket or malformed endpoint,
+		// so this only fires for a hand-built Config.
+		panic(fmt.Sprintf("wire: %v", err))
+	}
+	return blob
+}
+
+// newRedisClient returns a client for REDIS_URL, or nil when it is unset.
+// A malformed REDIS_URL is logged and treated as unset, so every consumer
+// falls back to its single-instance behavior.
+func newRedisClient(cfg *config.Config) *redis.Client {
+	if cfg.RedisURL == "" {
+		return nil
+	}
+	opt, err := redis.ParseURL(cfg.RedisURL)
+	if err != nil {
+		logger.Error("invalid REDIS_URL, using Postgres for feature flag invalidation and in-process SSE",
+			slog.String("error", err.Error()))
+		return nil
+	}
+	return redis.NewClient(opt)
+}
 
-	return &Services{
-		SSEHub:  sseHub,
-		Auth:    authService,
-		Users:   userService,
-		Email:   emailService,
-		Feature: featureService,
+// newSSEHub builds the SSE hub. With Redis, events, tickets, replay
+// history, and presence are shared across instances; otherwise the hub
+// serves its own process only.
+func newSSEHub(cfg *config.Config, rdb *redis.Client) *sse.SSEHub {
+	limits := []sse.Option{
+		sse.WithLimits(cfg.SSEClientBuffer, cfg.SSEMaxConnsPerUser),
+		sse.WithOverflow(sse.OverflowPolicy(cfg.SSEOverflowPolicy), cfg.SSEEvictRetry),
+	}
+	if rdb == nil {
+		return sse.NewSSEHub(cfg.SSETicketTTL, append(limits,
+			sse.WithReplayStore(sse.NewMemoryReplayStore(cfg.SSEReplaySize, cfg.SSEReplayTTL)),
+			sse.WithPresence(sse.NewMemoryPresenceStore(), cfg.SSEPresenceTTL, cfg.SSEPresenceGrace))...)
+	}
+	broker := sse.NewRedisBroker(rdb)
+	return sse.NewSSEHub(cfg.SSETicketTTL, append(limits,
+		sse.WithBroker(broker),
+		sse.WithTicketStore(broker),
+		sse.WithReplayStore(sse.NewRedisReplayStore(rdb, cfg.SSEReplaySize, cfg.SSEReplayTTL)),
+		sse.WithPresence(sse.NewRedisPresenceStore(rdb), cfg.SSEPresenceTTL, cfg.SSEPresenceGrace))...)
+}
+
+// newFlagInvalidator picks the channel that carries feature flag changes
+// between instances: Redis pub/sub when REDIS_URL is set, Postgres
+// LISTEN/NOTIFY otherwise.
+func newFlagInvalidator(rdb *redis.Client, pool *pgxpool.Pool) feature.Invalidator {
+	if rdb != nil {
+		return feature.NewRedisInvalidator(rdb)
+	}
+	if pool == nil {
+		return nil
 	}
+	return feature.NewPostgresInvalidator(pool)
 }
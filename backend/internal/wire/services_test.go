@@ -2,6 +2,7 @@ package wire
 
 import (
 	"context"
+	"slices"
 	"testing"
 )
 
@@ -28,4 +29,47 @@ func TestBuildServices_ReturnsNonNilServices(t *testing.T) {
 	if svcs.Feature == nil {
 		t.Error("Feature is nil")
 	}
+	if svcs.PoW == nil {
+		t.Error("PoW is nil")
+	}
+	if svcs.Files == nil {
+		t.Error("Files is nil")
+	}
+	if svcs.Exports == nil {
+		t.Error("Exports is nil")
+	}
+	if svcs.Prefs == nil {
+		t.Error("Prefs is nil")
+	}
+	if svcs.Notifications == nil {
+		t.Error("Notifications is nil")
+	}
+	if svcs.Announcements == nil {
+		t.Error("Announcements is nil")
+	}
+}
+
+func TestBuildServices_RegistersExporters(t *testing.T) {
+	svcs := BuildServices(context.Background(), testWireConfig(), newTestPool(t))
+
+	got := svcs.Exports.Exporters()
+	for _, want := range []string{"profile", "sessions", "files", "preferences", "notifications", "announcement_dismissals"} {
+		if !slices.Contains(got, want) {
+			t.Errorf("exporters = %v, missing %q", got, want)
+		}
+	}
+}
+
+func TestBuildServices_RegistersPreferences(t *testing.T) {
+	svcs := BuildServices(context.Background(), testWireConfig(), newTestPool(t))
+
+	var keys []string
+	for _, d := range svcs.Prefs.Defs() {
+		keys = append(keys, d.Key)
+	}
+	for _, want := range []string{"locale", "timezone"} {
+		if !slices.Contains(keys, want) {
+			t.Errorf("preference keys = %v, missing %q", keys, want)
+		}
+	}
 }
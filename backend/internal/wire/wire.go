@@ -11,6 +11,7 @@
 //   - services.go: BuildServices(ctx, cfg, pool) → *Services
 //   - handlers.go: BuildHandlers(svcs, cfg, jobQueue) → *Handlers
 //   - routes.go:   RegisterRoutes(e, h, svcs, cfg, jwtMW)
+//   - outbox.go:   BuildOutboxRelay(svcs, cfg, pool, jobQueue) → *outbox.Relay
 //
 // Context ownership: the ctx passed into BuildServices is the bootstrap
 // context constructed in main.go. wire/* never invents or wraps it; it
@@ -0,0 +1,13 @@
+-- Migration: 000006_users_admin_search
+-- Trigram indexes for admin user search (GET /api/v1/admin/users).
+-- pg_trgm is enabled in 000001_init; GIN trgm indexes serve both ILIKE
+-- substring matches and the % similarity operator.
+-- ============================================================================
+
+CREATE INDEX IF NOT EXISTS idx_users_email_trgm
+  ON users USING GIN (email gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_users_name_trgm
+  ON users USING GIN ((COALESCE(first_name, '') || ' ' || COALESCE(last_name, '')) gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users(created_at);
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_users_status_changed_by;
+DROP INDEX IF EXISTS idx_users_status;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_suspended_until_check;
+ALTER TABLE users DROP COLUMN IF EXISTS status_changed_at;
+ALTER TABLE users DROP COLUMN IF EXISTS status_changed_by;
+ALTER TABLE users DROP COLUMN IF EXISTS status_reason;
+ALTER TABLE users DROP COLUMN IF EXISTS suspended_until;
+ALTER TABLE users DROP COLUMN IF EXISTS status;
+DROP TYPE IF EXISTS user_status;
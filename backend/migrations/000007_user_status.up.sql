@@ -0,0 +1,20 @@
+-- Migration: 000007_user_status
+-- Account status for admin suspension and banning.
+-- A suspension is time-boxed by suspended_until; once that passes the
+-- account is treated as active again without a write.
+-- ============================================================================
+
+DO $$ BEGIN CREATE TYPE user_status AS ENUM ('active', 'suspended', 'banned'); EXCEPTION WHEN duplicate_object THEN null; END $$;
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status user_status NOT NULL DEFAULT 'active';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS suspended_until TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_reason TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_changed_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS status_changed_at TIMESTAMPTZ;
+
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_suspended_until_check;
+ALTER TABLE users ADD CONSTRAINT users_suspended_until_check
+  CHECK (status <> 'suspended' OR suspended_until IS NOT NULL);
+
+CREATE INDEX IF NOT EXISTS idx_users_status ON users(status) WHERE status <> 'active';
+CREATE INDEX IF NOT EXISTS idx_users_status_changed_by ON users(status_changed_by);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS files;
+DROP TYPE IF EXISTS file_status;
@@ -0,0 +1,27 @@
+-- Migration: 000008_files
+-- Metadata for objects in blob storage (internal/storage). A row is created
+-- as 'pending' when an upload URL is issued and becomes 'uploaded' once the
+-- body has been sniffed, checksummed, and written to the backend.
+-- ============================================================================
+
+DO $$ BEGIN CREATE TYPE file_status AS ENUM ('pending', 'uploaded'); EXCEPTION WHEN duplicate_object THEN null; END $$;
+
+CREATE TABLE IF NOT EXISTS files (
+    id              UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    owner_id        UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    storage_key     TEXT NOT NULL UNIQUE,
+    filename        TEXT NOT NULL,
+    content_type    TEXT,
+    size_bytes      BIGINT,
+    checksum_sha256 TEXT,
+    status          file_status NOT NULL DEFAULT 'pending',
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    uploaded_at     TIMESTAMPTZ,
+    CONSTRAINT files_uploaded_check CHECK (
+        status <> 'uploaded'
+        OR (content_type IS NOT NULL AND size_bytes IS NOT NULL AND checksum_sha256 IS NOT NULL AND uploaded_at IS NOT NULL)
+    )
+);
+
+CREATE INDEX IF NOT EXISTS idx_files_owner_id ON files(owner_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_files_pending_created_at ON files(created_at) WHERE status = 'pending';
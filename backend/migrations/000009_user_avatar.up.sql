@@ -0,0 +1,7 @@
+-- Migration: 000009_user_avatar
+-- Tracks the storage prefix of the user's processed avatar renditions
+-- (avatars/<user_id>/<upload_id>). Upload IDs are UUIDv7, so the prefix
+-- orders by upload time and a slow job cannot overwrite a newer avatar.
+-- ============================================================================
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_key TEXT;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS data_exports;
+DROP TYPE IF EXISTS data_export_status;
@@ -0,0 +1,28 @@
+-- Migration: 000010_data_exports
+-- Personal data exports (data-subject access requests). A row is 'pending'
+-- while the export job collects the user's data from every registered
+-- exporter and 'ready' once the ZIP archive is in blob storage. Archives
+-- are deleted when expires_at passes.
+-- ============================================================================
+
+DO $$ BEGIN CREATE TYPE data_export_status AS ENUM ('pending', 'ready'); EXCEPTION WHEN duplicate_object THEN null; END $$;
+
+CREATE TABLE IF NOT EXISTS data_exports (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id      UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status       data_export_status NOT NULL DEFAULT 'pending',
+    storage_key  TEXT UNIQUE,
+    size_bytes   BIGINT,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ,
+    expires_at   TIMESTAMPTZ,
+    CONSTRAINT data_exports_ready_check CHECK (
+        status <> 'ready'
+        OR (storage_key IS NOT NULL AND size_bytes IS NOT NULL AND completed_at IS NOT NULL AND expires_at IS NOT NULL)
+    )
+);
+
+-- At most one export in flight per user.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_exports_one_pending ON data_exports(user_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_data_exports_user_id ON data_exports(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_data_exports_expires_at ON data_exports(expires_at) WHERE status = 'ready';
@@ -0,0 +1,12 @@
+-- Migration: 000011_user_preferences
+-- Per-user settings as one JSONB document. Keys, types, defaults, and
+-- allowed values are declared in code (preference registry) and validated
+-- on write; absent keys read as their declared default.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS user_preferences (
+    user_id    UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    prefs      JSONB NOT NULL DEFAULT '{}'::jsonb,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT user_preferences_object_check CHECK (jsonb_typeof(prefs) = 'object')
+);
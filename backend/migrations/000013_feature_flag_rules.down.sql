@@ -0,0 +1 @@
+ALTER TABLE feature_flags DROP COLUMN IF EXISTS rules;
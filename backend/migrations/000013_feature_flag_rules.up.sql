@@ -0,0 +1,6 @@
+-- Migration: 000013_feature_flag_rules
+-- Targeting rules for feature flags (user allow/deny lists, user_type match,
+-- percentage rollout). NULL means the flag applies to everyone.
+-- ============================================================================
+
+ALTER TABLE feature_flags ADD COLUMN IF NOT EXISTS rules JSONB;
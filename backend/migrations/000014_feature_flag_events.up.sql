@@ -0,0 +1,21 @@
+-- Migration: 000014_feature_flag_events
+-- Audit trail for feature flag changes. One row per write with the state
+-- before and after, so any change can be rolled back. old_state is NULL
+-- when the write created the flag. Rows outlive their flag (no FK on key).
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS feature_flag_events (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    flag_key    TEXT NOT NULL,
+    action      TEXT NOT NULL,
+    actor_id    UUID REFERENCES users(id) ON DELETE SET NULL,
+    old_state   JSONB,
+    new_state   JSONB NOT NULL,
+    reason      TEXT NOT NULL,
+    request_id  TEXT,
+    rollback_of UUID REFERENCES feature_flag_events(id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_feature_flag_events_key_created ON feature_flag_events(flag_key, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_feature_flag_events_actor ON feature_flag_events(actor_id);
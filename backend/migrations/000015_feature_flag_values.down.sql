@@ -0,0 +1,5 @@
+ALTER TABLE feature_flags
+    DROP COLUMN IF EXISTS value_schema,
+    DROP COLUMN IF EXISTS variants,
+    DROP COLUMN IF EXISTS value,
+    DROP COLUMN IF EXISTS value_type;
@@ -0,0 +1,13 @@
+-- Migration: 000015_feature_flag_values
+-- Typed values for feature flags. Boolean flags keep using `enabled` alone;
+-- string, number, and json flags serve `value` (or one of `variants`,
+-- chosen by weight and sticky per user) when enabled. `value_schema` is an
+-- optional JSON Schema the value and every variant must satisfy.
+-- ============================================================================
+
+ALTER TABLE feature_flags
+    ADD COLUMN IF NOT EXISTS value_type TEXT NOT NULL DEFAULT 'boolean'
+        CHECK (value_type IN ('boolean', 'string', 'number', 'json')),
+    ADD COLUMN IF NOT EXISTS value JSONB,
+    ADD COLUMN IF NOT EXISTS variants JSONB,
+    ADD COLUMN IF NOT EXISTS value_schema JSONB;
@@ -0,0 +1,2 @@
+ALTER TABLE feature_flag_events DROP COLUMN IF EXISTS schedule_id;
+DROP TABLE IF EXISTS feature_flag_schedules;
@@ -0,0 +1,34 @@
+-- Migration: 000016_feature_flag_schedules
+-- Feature flag changes scheduled for a future time. The scheduler claims
+-- due rows with FOR UPDATE SKIP LOCKED and marks them applied in the same
+-- transaction as the flag write, so each runs exactly once across
+-- instances. An `enable` with revert_at queues a `disable` (revert_of set)
+-- when it is applied.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS feature_flag_schedules (
+    id           UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    flag_key     TEXT NOT NULL,
+    action       TEXT NOT NULL CHECK (action IN ('enable', 'disable', 'values')),
+    flag_values  JSONB,
+    run_at       TIMESTAMPTZ NOT NULL,
+    revert_at    TIMESTAMPTZ,
+    status       TEXT NOT NULL DEFAULT 'pending'
+                 CHECK (status IN ('pending', 'applied', 'cancelled', 'failed')),
+    reason       TEXT NOT NULL,
+    created_by   UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    applied_at   TIMESTAMPTZ,
+    cancelled_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    cancelled_at TIMESTAMPTZ,
+    error        TEXT,
+    revert_of    UUID REFERENCES feature_flag_schedules(id) ON DELETE SET NULL,
+    CHECK ((action = 'values') = (flag_values IS NOT NULL)),
+    CHECK (revert_at IS NULL OR (action = 'enable' AND revert_at > run_at))
+);
+
+CREATE INDEX IF NOT EXISTS idx_feature_flag_schedules_due ON feature_flag_schedules(run_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_feature_flag_schedules_key ON feature_flag_schedules(flag_key, run_at);
+
+ALTER TABLE feature_flag_events
+    ADD COLUMN IF NOT EXISTS schedule_id UUID REFERENCES feature_flag_schedules(id) ON DELETE SET NULL;
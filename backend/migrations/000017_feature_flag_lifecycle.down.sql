@@ -0,0 +1,6 @@
+DELETE FROM feature_flag_events WHERE new_state IS NULL;
+ALTER TABLE feature_flag_events ALTER COLUMN new_state SET NOT NULL;
+DROP TABLE IF EXISTS feature_flag_usage;
+ALTER TABLE feature_flags
+    DROP COLUMN IF EXISTS expires_at,
+    DROP COLUMN IF EXISTS owner;
@@ -0,0 +1,18 @@
+-- Migration: 000017_feature_flag_lifecycle
+-- Ownership and expiry for feature flags, per-flag evaluation counters for
+-- the stale flag report, and nullable new_state so deletions can be
+-- recorded in the flag history. Usage lives in its own table so counter
+-- flushes never contend with flag writes (which lock feature_flags rows).
+-- ============================================================================
+
+ALTER TABLE feature_flags
+    ADD COLUMN IF NOT EXISTS owner TEXT NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+
+CREATE TABLE IF NOT EXISTS feature_flag_usage (
+    flag_key          TEXT PRIMARY KEY,
+    evaluations       BIGINT NOT NULL DEFAULT 0,
+    last_evaluated_at TIMESTAMPTZ NOT NULL
+);
+
+ALTER TABLE feature_flag_events ALTER COLUMN new_state DROP NOT NULL;
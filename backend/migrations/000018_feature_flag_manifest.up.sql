@@ -0,0 +1,9 @@
+-- Migration: 000018_feature_flag_manifest
+-- Flags-as-code: synced_state is the state (evaluation fields plus
+-- description, owner, and expiry) the manifest sync last wrote for a flag.
+-- NULL means the flag is not managed by the manifest. When the current row
+-- no longer matches it, an admin changed the flag at runtime and sync
+-- leaves it alone unless forced.
+-- ============================================================================
+
+ALTER TABLE feature_flags ADD COLUMN IF NOT EXISTS synced_state JSONB;
@@ -0,0 +1,33 @@
+-- Migration: 000019_announcements
+-- Site-wide announcements (maintenance notices, release banners) managed by
+-- admins. One is shown to its audience from starts_at until ends_at (NULL:
+-- until removed). Dismissals are per user; anonymous visitors keep theirs
+-- client-side.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS announcements (
+    id          UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    title       TEXT NOT NULL,
+    body        TEXT NOT NULL DEFAULT '',
+    severity    TEXT NOT NULL DEFAULT 'info' CHECK (severity IN ('info', 'warning', 'critical')),
+    audience    TEXT NOT NULL DEFAULT 'everyone' CHECK (audience IN ('everyone', 'guests', 'users', 'admins')),
+    starts_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ends_at     TIMESTAMPTZ,
+    dismissible BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by  UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT announcements_window_check CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+-- The admin list, newest first; the public list scans the few rows that
+-- are not over yet.
+CREATE INDEX IF NOT EXISTS idx_announcements_created ON announcements(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_announcements_ends_at ON announcements(ends_at);
+
+CREATE TABLE IF NOT EXISTS announcement_dismissals (
+    user_id         UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    announcement_id UUID NOT NULL REFERENCES announcements(id) ON DELETE CASCADE,
+    dismissed_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, announcement_id)
+);
@@ -0,0 +1,27 @@
+-- Migration: 000020_outbox
+-- Transactional outbox. Side effects of a business change (a queued task,
+-- an SSE event, a webhook) are written here in the same transaction as the
+-- change, then delivered at least once by the relay. Delivered and failed
+-- rows are removed after OUTBOX_RETENTION.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS outbox (
+    id            UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    kind          TEXT NOT NULL CHECK (kind IN ('task', 'sse_user', 'sse_topic', 'sse_broadcast', 'webhook')),
+    name          TEXT NOT NULL,
+    target        TEXT NOT NULL DEFAULT '',
+    payload       BYTEA NOT NULL,
+    attempts      INT NOT NULL DEFAULT 0,
+    last_error    TEXT NOT NULL DEFAULT '',
+    available_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    dispatched_at TIMESTAMPTZ,
+    failed_at     TIMESTAMPTZ
+);
+
+-- The relay claims the earliest available undelivered row; delivered and
+-- failed rows only matter to cleanup.
+CREATE INDEX IF NOT EXISTS idx_outbox_pending ON outbox(available_at, id)
+    WHERE dispatched_at IS NULL AND failed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_dispatched ON outbox(dispatched_at) WHERE dispatched_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_failed ON outbox(failed_at) WHERE failed_at IS NOT NULL;
@@ -0,0 +1,22 @@
+DELETE FROM data_exports WHERE status = 'failed';
+ALTER TABLE data_exports DROP COLUMN IF EXISTS attempts;
+
+-- Postgres cannot drop an enum value; rebuild the type without 'failed'.
+-- Objects that compare status to a literal are recreated around the swap.
+DROP INDEX IF EXISTS idx_data_exports_one_pending;
+DROP INDEX IF EXISTS idx_data_exports_expires_at;
+ALTER TABLE data_exports DROP CONSTRAINT IF EXISTS data_exports_ready_check;
+
+ALTER TYPE data_export_status RENAME TO data_export_status_old;
+CREATE TYPE data_export_status AS ENUM ('pending', 'ready');
+ALTER TABLE data_exports ALTER COLUMN status DROP DEFAULT;
+ALTER TABLE data_exports ALTER COLUMN status TYPE data_export_status USING status::text::data_export_status;
+ALTER TABLE data_exports ALTER COLUMN status SET DEFAULT 'pending';
+DROP TYPE data_export_status_old;
+
+ALTER TABLE data_exports ADD CONSTRAINT data_exports_ready_check CHECK (
+    status <> 'ready'
+    OR (storage_key IS NOT NULL AND size_bytes IS NOT NULL AND completed_at IS NOT NULL AND expires_at IS NOT NULL)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_data_exports_one_pending ON data_exports(user_id) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_data_exports_expires_at ON data_exports(expires_at) WHERE status = 'ready';
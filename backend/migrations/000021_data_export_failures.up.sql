@@ -0,0 +1,10 @@
+-- Migration: 000021_data_export_failures
+-- A data export whose build keeps failing is marked 'failed' instead of
+-- staying 'pending', where it would block new requests until the stale
+-- sweep. attempts counts builds started, so the limit holds across job
+-- queue retries and the in-process relay alike.
+-- ============================================================================
+
+ALTER TYPE data_export_status ADD VALUE IF NOT EXISTS 'failed';
+
+ALTER TABLE data_exports ADD COLUMN IF NOT EXISTS attempts INT NOT NULL DEFAULT 0;
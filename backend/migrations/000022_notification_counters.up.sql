@@ -0,0 +1,11 @@
+-- Migration: 000022_notification_counters
+-- One row per user whose notifications have changed. Every change bumps seq
+-- in the same transaction, which serializes changes per user and orders the
+-- unread counts pushed over SSE, so clients can ignore a count older than
+-- one they already have.
+-- ============================================================================
+
+CREATE TABLE IF NOT EXISTS notification_counters (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    seq     BIGINT NOT NULL DEFAULT 0
+);
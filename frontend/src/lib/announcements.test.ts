@@ -0,0 +1,130 @@
+import { describe, it, expect, vi, beforeEach } from "vitest";
+
+vi.mock("./api", () => ({
+  announcementsApi: { list: vi.fn(), dismiss: vi.fn() },
+  tokens: { access: "" },
+}));
+
+import {
+  loadAnnouncements,
+  visibleAnnouncements,
+  upsertAnnouncement,
+  removeAnnouncement,
+  dismissAnnouncement,
+} from "./announcements";
+import { announcementsApi, tokens, type Announcement } from "./api";
+
+const mockApi = vi.mocked(announcementsApi);
+const mockTokens = tokens as unknown as { access: string };
+
+const NOW = Date.parse("2026-10-01T12:00:00Z");
+
+function announcement(overrides: Partial<Announcement> = {}): Announcement {
+  return {
+    id: "a1",
+    title: "Release 2.0",
+    body: "",
+    severity: "info",
+    audience: "everyone",
+    starts_at: "2026-10-01T00:00:00Z",
+    ends_at: null,
+    dismissible: true,
+    created_at: "2026-10-01T00:00:00Z",
+    updated_at: "2026-10-01T00:00:00Z",
+    ...overrides,
+  };
+}
+
+function visibleIds(): string[] {
+  return visibleAnnouncements(NOW).map((a) => a.id);
+}
+
+beforeEach(async () => {
+  vi.clearAllMocks();
+  localStorage.clear();
+  mockTokens.access = "";
+  mockApi.list.mockResolvedValueOnce({ announcements: [] });
+  await loadAnnouncements();
+});
+
+describe("loadAnnouncements", () => {
+  it("shows what the server returns", async () => {
+    mockApi.list.mockResolvedValueOnce({ announcements: [announcement()] });
+    await loadAnnouncements();
+    expect(visibleIds()).toEqual(["a1"]);
+  });
+
+  it("keeps the current list when the API fails", async () => {
+    upsertAnnouncement(announcement());
+    mockApi.list.mockRejectedValueOnce(new Error("Network error"));
+    await expect(loadAnnouncements()).resolves.toBeUndefined();
+    expect(visibleIds()).toEqual(["a1"]);
+  });
+});
+
+describe("live updates", () => {
+  it("orders by severity, then newest", () => {
+    upsertAnnouncement(announcement({ id: "old" }));
+    upsertAnnouncement(announcement({ id: "new", starts_at: "2026-10-01T06:00:00Z" }));
+    upsertAnnouncement(announcement({ id: "crit", severity: "critical" }));
+    expect(visibleIds()).toEqual(["crit", "new", "old"]);
+  });
+
+  it("replaces an announcement by id and removes it", () => {
+    upsertAnnouncement(announcement());
+    upsertAnnouncement(announcement({ title: "Release 2.1" }));
+    expect(visibleAnnouncements(NOW).map((a) => a.title)).toEqual(["Release 2.1"]);
+
+    removeAnnouncement("a1");
+    expect(visibleIds()).toEqual([]);
+  });
+
+  it("hides announcements outside their window", () => {
+    upsertAnnouncement(announcement({ id: "later", starts_at: "2026-10-02T00:00:00Z" }));
+    upsertAnnouncement(announcement({ id: "ended", ends_at: "2026-10-01T06:00:00Z" }));
+    expect(visibleIds()).toEqual([]);
+    expect(visibleAnnouncements(Date.parse("2026-10-02T00:00:00Z")).map((a) => a.id)).toEqual(["later"]);
+  });
+});
+
+describe("dismissAnnouncement", () => {
+  it("records a signed-in dismissal on the server", async () => {
+    mockTokens.access = "token";
+    mockApi.dismiss.mockResolvedValueOnce(undefined);
+    upsertAnnouncement(announcement());
+
+    await dismissAnnouncement("a1");
+    expect(mockApi.dismiss).toHaveBeenCalledWith("a1");
+    expect(visibleIds()).toEqual([]);
+    expect(localStorage.getItem("dismissedAnnouncements")).toBeNull();
+  });
+
+  it("shows the announcement again when the server rejects the dismissal", async () => {
+    mockTokens.access = "token";
+    mockApi.dismiss.mockRejectedValueOnce(new Error("Network error"));
+    upsertAnnouncement(announcement());
+
+    await dismissAnnouncement("a1");
+    expect(visibleIds()).toEqual(["a1"]);
+  });
+
+  it("keeps a guest dismissal in this browser", async () => {
+    upsertAnnouncement(announcement({ id: "g1" }));
+
+    await dismissAnnouncement("g1");
+    expect(mockApi.dismiss).not.toHaveBeenCalled();
+    expect(JSON.parse(localStorage.getItem("dismissedAnnouncements")!)).toEqual(["g1"]);
+
+    // Still hidden when the next load returns it.
+    mockApi.list.mockResolvedValueOnce({ announcements: [announcement({ id: "g1" })] });
+    await loadAnnouncements();
+    expect(visibleIds()).toEqual([]);
+  });
+
+  it("ignores announcements that can't be dismissed", async () => {
+    upsertAnnouncement(announcement({ dismissible: false }));
+
+    await dismissAnnouncement("a1");
+    expect(visibleIds()).toEqual(["a1"]);
+  });
+});
@@ -0,0 +1,98 @@
+import { createSignal } from "solid-js";
+import { announcementsApi, tokens, type Announcement } from "./api";
+
+// Guests have no account to record dismissals against, so theirs are kept
+// in this browser. Signed-in dismissals are recorded server-side and follow
+// the user across devices.
+const GUEST_DISMISSED_KEY = "dismissedAnnouncements";
+
+const SEVERITY_ORDER: Record<Announcement["severity"], number> = {
+  critical: 0,
+  warning: 1,
+  info: 2,
+};
+
+const [items, setItems] = createSignal<Announcement[]>([]);
+const [guestDismissed, setGuestDismissed] = createSignal<string[]>(readGuestDismissed());
+
+/**
+ * Load the announcements showing for the current visitor. The server picks
+ * the audience from the access token, if any.
+ */
+export async function loadAnnouncements(): Promise<void> {
+  try {
+    const { announcements } = await announcementsApi.list();
+    setItems(sortAnnouncements(announcements));
+  } catch {
+    // keep what is shown; the next live event or load corrects it
+  }
+}
+
+/**
+ * Announcements to show now: started, not ended, and not dismissed. Pushed
+ * announcements may be scheduled ahead, so callers re-read this as time
+ * passes.
+ */
+export function visibleAnnouncements(now = Date.now()): Announcement[] {
+  const hidden = tokens.access ? [] : guestDismissed();
+  return items().filter(
+    (a) =>
+      Date.parse(a.starts_at) <= now &&
+      (a.ends_at === null || Date.parse(a.ends_at) > now) &&
+      !hidden.includes(a.id)
+  );
+}
+
+/** Add or replace an announcement, as pushed live by the server. */
+export function upsertAnnouncement(announcement: Announcement): void {
+  setItems((current) =>
+    sortAnnouncements([...current.filter((a) => a.id !== announcement.id), announcement])
+  );
+}
+
+/** Stop showing an announcement that was removed or dismissed elsewhere. */
+export function removeAnnouncement(id: string): void {
+  setItems((current) => current.filter((a) => a.id !== id));
+}
+
+/**
+ * Dismiss an announcement: hidden at once, then recorded on the server for
+ * signed-in users or in this browser for guests.
+ */
+export async function dismissAnnouncement(id: string): Promise<void> {
+  const announcement = items().find((a) => a.id === id);
+  if (!announcement?.dismissible) return;
+
+  removeAnnouncement(id);
+  if (!tokens.access) {
+    const next = [...guestDismissed().filter((d) => d !== id), id];
+    setGuestDismissed(next);
+    if (typeof localStorage !== "undefined") {
+      localStorage.setItem(GUEST_DISMISSED_KEY, JSON.stringify(next));
+    }
+    return;
+  }
+  try {
+    await announcementsApi.dismiss(id);
+  } catch {
+    upsertAnnouncement(announcement);
+  }
+}
+
+function sortAnnouncements(list: Announcement[]): Announcement[] {
+  return [...list].sort(
+    (a, b) =>
+      SEVERITY_ORDER[a.severity] - SEVERITY_ORDER[b.severity] ||
+      Date.parse(b.starts_at) - Date.parse(a.starts_at)
+  );
+}
+
+function readGuestDismissed(): string[] {
+  if (typeof localStorage === "undefined") return [];
+  try {
+    const ids = JSON.parse(localStorage.getItem(GUEST_DISMISSED_KEY) ?? "[]");
+    return Array.isArray(ids) ? ids.filter((id) => typeof id === "string") : [];
+  } catch {
+    return [];
+  }
+}
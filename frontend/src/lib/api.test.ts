@@ -226,6 +226,23 @@ describe("api function", () => {
     expect(callArgs[1].body).toBe('{"name":"test"}');
   });
 
+  it("sends FormData bodies without a JSON content type", async () => {
+    globalThis.fetch = vi.fn().mockResolvedValue({
+      ok: true,
+      status: 202,
+      json: async () => ({ upload_id: "u1", status: "processing" }),
+      headers: new Headers(),
+    });
+
+    const { usersApi } = await import("./api");
+    await usersApi.uploadAvatar(new Blob(["img"], { type: "image/png" }));
+
+    const callArgs = (globalThis.fetch as any).mock.calls[0];
+    expect(callArgs[0]).toContain("/me/avatar");
+    expect(callArgs[1].body).toBeInstanceOf(FormData);
+    expect(callArgs[1].headers["Content-Type"]).toBeUndefined();
+  });
+
   it("omits body for GET requests", async () => {
     globalThis.fetch = vi.fn().mockResolvedValue({
       ok: true,
@@ -387,6 +404,27 @@ describe("401 token refresh", () => {
     await expect(api("/me")).rejects.toBeDefined();
     expect(tokens.access).toBeNull();
   });
+
+  it("clears tokens and dispatches session-expired on 403 ACCOUNT_SUSPENDED", async () => {
+    tokens.set("valid-access", "valid-refresh");
+    const sessionExpired = vi.fn();
+    window.addEventListener("auth:session-expired", sessionExpired);
+
+    globalThis.fetch = vi.fn().mockResolvedValue({
+      ok: false,
+      status: 403,
+      statusText: "Forbidden",
+      headers: new Headers({ "content-type": "application/json" }),
+      text: async () => '{"message":"Your account has been banned","code":"ACCOUNT_SUSPENDED"}',
+    });
+
+    const { api } = await import("./api");
+    await expect(api("/me")).rejects.toMatchObject({ code: "ACCOUNT_SUSPENDED" });
+    expect(tokens.access).toBeNull();
+    expect(sessionExpired).toHaveBeenCalled();
+
+    window.removeEventListener("auth:session-expired", sessionExpired);
+  });
 });
 
 describe("parseError edge cases", () => {
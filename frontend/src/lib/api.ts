@@ -3,6 +3,8 @@
  * Handles authentication, token refresh, and error handling
  */
 
+import { POW_CHALLENGE_HEADER, POW_SOLUTION_HEADER, solveChallenge, type PowChallenge } from "./pow";
+
 // ============================================================================
 // Types
 // ============================================================================
@@ -20,6 +22,57 @@ export interface User {
 
 export type UserProfile = User;
 
+/** Declared preference keys plus any module-specific ones. */
+export interface Preferences {
+  /** Empty means "follow the browser's Accept-Language". */
+  locale: string;
+  timezone: string;
+  [key: string]: string | boolean | number;
+}
+
+export interface AppNotification {
+  id: string;
+  kind: string;
+  /** Producer-defined; `message` is shown as a toast when it arrives live. */
+  payload: { message?: string } & Record<string, unknown>;
+  read_at: string | null;
+  created_at: string;
+}
+
+export interface NotificationListResult {
+  notifications: AppNotification[];
+  total: number;
+  unread_count: number;
+  unread_seq: number;
+  page: number;
+  per_page: number;
+  total_pages: number;
+}
+
+export interface Announcement {
+  id: string;
+  title: string;
+  body: string;
+  severity: "info" | "warning" | "critical";
+  audience: "everyone" | "guests" | "users" | "admins";
+  starts_at: string;
+  /** null means shown until removed. */
+  ends_at: string | null;
+  dismissible: boolean;
+  created_at: string;
+  updated_at: string;
+}
+
+export interface DataExport {
+  id: string;
+  status: "pending" | "ready" | "failed";
+  size_bytes: number | null;
+  created_at: string;
+  completed_at: string | null;
+  expires_at: string | null;
+  download_url?: { url: string; expires_at: string };
+}
+
 export interface AuthResponse {
   access_token: string;
   refresh_token: string;
@@ -42,6 +95,8 @@ export interface ApiError {
   message: string;
   code: string;
   status: number;
+  /** Stable catalog key for `message`, which is already localized. */
+  message_id?: string;
   details?: Record<string, string>;
 }
 
@@ -76,6 +131,7 @@ interface RequestOptions {
   skipAuth?: boolean;
   signal?: AbortSignal;
   _retried?: boolean;
+  _powSolved?: boolean;
 }
 
 // ============================================================================
@@ -126,8 +182,11 @@ export async function api<T>(
 ): Promise<T> {
   const { method = "GET", body, headers = {}, skipAuth = false, signal } = options;
 
+  // FormData bodies are sent as-is so fetch sets the multipart boundary.
+  const isForm = typeof FormData !== "undefined" && body instanceof FormData;
+
   const requestHeaders: Record<string, string> = {
-    "Content-Type": "application/json",
+    ...(isForm ? {} : { "Content-Type": "application/json" }),
     "X-Requested-With": APP_REQUEST_HEADER,
     ...headers,
   };
@@ -139,7 +198,7 @@ export async function api<T>(
   const response = await fetch(`${API_BASE}/api/${API_VERSION}${endpoint}`, {
     method,
     headers: requestHeaders,
-    body: body ? JSON.stringify(body) : undefined,
+    body: isForm ? body : body ? JSON.stringify(body) : undefined,
     signal,
   });
 
@@ -158,6 +217,16 @@ export async function api<T>(
 
   if (!response.ok) {
     const error = await parseError(response);
+    if (error.status === 428 && error.code === "CHALLENGE_REQUIRED" && !options._powSolved) {
+      const powHeaders = await solvePowChallenge(signal);
+      return api(endpoint, { ...options, headers: { ...headers, ...powHeaders }, _powSolved: true });
+    }
+    if (error.status === 403 && error.code === "ACCOUNT_SUSPENDED" && !skipAuth) {
+      tokens.clear();
+      if (typeof window !== "undefined") {
+        window.dispatchEvent(new Event("auth:session-expired"));
+      }
+    }
     throw error;
   }
 
@@ -203,6 +272,13 @@ async function tryRefreshToken(): Promise<boolean> {
   return refreshPromise;
 }
 
+/** Fetch a proof-of-work challenge and return the headers for the retry. */
+async function solvePowChallenge(signal?: AbortSignal): Promise<Record<string, string>> {
+  const ch = await api<PowChallenge>("/auth/challenge", { skipAuth: true, signal });
+  const solution = await solveChallenge(ch, signal);
+  return { [POW_CHALLENGE_HEADER]: ch.challenge, [POW_SOLUTION_HEADER]: solution };
+}
+
 async function parseError(response: Response): Promise<ApiError> {
   const error: ApiError = {
     message: `Request failed: ${response.statusText}`,
@@ -218,6 +294,7 @@ async function parseError(response: Response): Promise<ApiError> {
       const data = JSON.parse(text);
       error.message = data.message || data.error || error.message;
       error.code = data.code || error.code;
+      error.message_id = data.message_id;
       error.details = data.details;
     } else if (text.startsWith("<!DOCTYPE") || text.startsWith("<html")) {
       error.message = "Unable to reach the server. Please try again later.";
@@ -301,9 +378,46 @@ export const authApi = {
 export const usersApi = {
   me: () => get<User>("/me"),
 
-  updateProfile: (data: {
-    first_name?: string;
-    last_name?: string;
-    avatar_url?: string | null;
-  }) => put<User>("/me", data),
+  updateProfile: (data: { first_name?: string; last_name?: string }) => put<User>("/me", data),
+
+  /** Upload a new avatar; avatar_url updates once processing finishes. */
+  uploadAvatar: (file: Blob) => {
+    const form = new FormData();
+    form.append("avatar", file);
+    return post<{ upload_id: string; status: "processing" }>("/me/avatar", form);
+  },
+
+  deleteAvatar: () => del<void>("/me/avatar"),
+
+  getPreferences: () => get<Preferences>("/me/preferences"),
+
+  /** Partial update; pass null to reset a key to its default. */
+  updatePreferences: (values: Record<string, string | boolean | number | null>) =>
+    patch<Preferences>("/me/preferences", values),
+
+  /** Request a personal data export; a data_export.ready notification follows. */
+  requestExport: () => post<DataExport>("/me/export"),
+
+  getExport: (id: string) => get<DataExport>(`/me/exports/${id}`),
+};
+
+export const notificationsApi = {
+  list: (page = 1, perPage = 20, unreadOnly = false) =>
+    get<NotificationListResult>(
+      `/me/notifications?page=${page}&per_page=${perPage}${unreadOnly ? "&unread=true" : ""}`
+    ),
+
+  /** Mark notifications read (or unread with read: false). */
+  markRead: (ids: string[], read = true) =>
+    patch<{ unread_count: number }>("/me/notifications", { ids, read }),
+
+  markAllRead: () => patch<{ unread_count: number }>("/me/notifications", { all: true }),
+};
+
+export const announcementsApi = {
+  /** Announcements showing now for the current visitor, most severe first. */
+  list: () => get<{ announcements: Announcement[] }>("/announcements"),
+
+  /** Hide an announcement on every device; signed-in users only. */
+  dismiss: (id: string) => post<void>(`/announcements/${id}/dismiss`),
 };
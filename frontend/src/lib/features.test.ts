@@ -4,7 +4,7 @@ vi.mock("./api", () => ({
   get: vi.fn(),
 }));
 
-import { loadFeatures, isEnabled } from "./features";
+import { loadFeatures, isEnabled, variant, featureValue } from "./features";
 import { get } from "./api";
 
 const mockedGet = vi.mocked(get);
@@ -25,9 +25,19 @@ describe("isEnabled", () => {
 
 describe("loadFeatures", () => {
   it("loads flags from API", async () => {
-    mockedGet.mockResolvedValueOnce({ dark_mode: true, beta: false } as never);
+    mockedGet.mockResolvedValueOnce({
+      dark_mode: { enabled: true },
+      beta: { enabled: false },
+    } as never);
     await loadFeatures();
-    expect(mockedGet).toHaveBeenCalledWith("/features", { skipAuth: true });
+    expect(mockedGet).toHaveBeenCalledWith("/features?format=variants", { skipAuth: true });
+  });
+
+  it("loads flags evaluated for the signed-in user", async () => {
+    mockedGet.mockResolvedValueOnce({ canary: { enabled: true } } as never);
+    await loadFeatures(true);
+    expect(mockedGet).toHaveBeenCalledWith("/me/features?format=variants");
+    expect(isEnabled("canary")).toBe(true);
   });
 
   it("does not throw when API fails", async () => {
@@ -36,10 +46,25 @@ describe("loadFeatures", () => {
   });
 
   it("updates isEnabled after successful load", async () => {
-    mockedGet.mockResolvedValueOnce({ new_feature: true, old_feature: false } as never);
+    mockedGet.mockResolvedValueOnce({
+      new_feature: { enabled: true },
+      old_feature: { enabled: false },
+    } as never);
     await loadFeatures();
     expect(isEnabled("new_feature")).toBe(true);
     expect(isEnabled("old_feature")).toBe(false);
     expect(isEnabled("missing")).toBe(false);
   });
+
+  it("exposes variants and values", async () => {
+    mockedGet.mockResolvedValueOnce({
+      headline: { enabled: true, variant: "b", value: "Get it" },
+      page_size: { enabled: false },
+    } as never);
+    await loadFeatures(true);
+    expect(variant("headline")).toBe("b");
+    expect(featureValue("headline", "Buy now")).toBe("Get it");
+    expect(featureValue("page_size", 20)).toBe(20);
+    expect(featureValue("missing", "x")).toBe("x");
+  });
 });
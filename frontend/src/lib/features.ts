@@ -1,11 +1,29 @@
 import { createSignal } from "solid-js";
 import { get } from "./api";
 
-const [flags, setFlags] = createSignal<Record<string, boolean>>({});
+/** A flag resolved for the current visitor (`?format=variants`). */
+export interface FeatureEvaluation {
+  enabled: boolean;
+  /** Variant key for multivariate flags; absent for anonymous visitors. */
+  variant?: string;
+  /** Served value for string, number, and json flags. */
+  value?: unknown;
+}
+
+const [flags, setFlags] = createSignal<Record<string, FeatureEvaluation>>({});
 
-export async function loadFeatures(): Promise<void> {
+/**
+ * Load flags. Signed-in callers pass `authenticated` to get flags evaluated
+ * for their account (targeting rules, percentage rollouts, sticky variants);
+ * the public map treats every visitor as anonymous.
+ */
+export async function loadFeatures(authenticated = false): Promise<void> {
   try {
-    const result = await get<Record<string, boolean>>("/features", { skipAuth: true });
+    const result = authenticated
+      ? await get<Record<string, FeatureEvaluation>>("/me/features?format=variants")
+      : await get<Record<string, FeatureEvaluation>>("/features?format=variants", {
+          skipAuth: true,
+        });
     setFlags(result);
   } catch {
     // flags default to false if endpoint unavailable
@@ -13,5 +31,21 @@ export async function loadFeatures(): Promise<void> {
 }
 
 export function isEnabled(key: string): boolean {
-  return flags()[key] ?? false;
+  return flags()[key]?.enabled ?? false;
+}
+
+/** The variant key the current visitor is in, if any. */
+export function variant(key: string): string | undefined {
+  return flags()[key]?.variant;
+}
+
+/**
+ * The flag's value for the current visitor, or `fallback` when the flag is
+ * off, unknown, or carries no value. Values are not type-checked here; the
+ * server validates them against the flag's type and schema.
+ */
+export function featureValue<T>(key: string, fallback: T): T {
+  const flag = flags()[key];
+  if (!flag?.enabled || flag.value === undefined) return fallback;
+  return flag.value as T;
 }
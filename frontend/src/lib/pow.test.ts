@@ -0,0 +1,30 @@
+import { describe, it, expect } from "vitest";
+import { leadingZeroBits, solveChallenge } from "./pow";
+
+describe("leadingZeroBits", () => {
+  it("counts bits across bytes", () => {
+    expect(leadingZeroBits(new Uint8Array([0xff]))).toBe(0);
+    expect(leadingZeroBits(new Uint8Array([0x01]))).toBe(7);
+    expect(leadingZeroBits(new Uint8Array([0x00, 0x10]))).toBe(11);
+    expect(leadingZeroBits(new Uint8Array([0x00, 0x00]))).toBe(16);
+  });
+});
+
+describe("solveChallenge", () => {
+  it("finds a solution meeting the difficulty", async () => {
+    const ch = { challenge: "abc.def", difficulty: 8, algorithm: "sha256", expires_at: "" };
+    const solution = await solveChallenge(ch);
+    const digest = await crypto.subtle.digest(
+      "SHA-256",
+      new TextEncoder().encode(`${ch.challenge}:${solution}`)
+    );
+    expect(leadingZeroBits(new Uint8Array(digest))).toBeGreaterThanOrEqual(8);
+  });
+
+  it("stops when aborted", async () => {
+    const controller = new AbortController();
+    controller.abort();
+    const ch = { challenge: "x", difficulty: 32, algorithm: "sha256", expires_at: "" };
+    await expect(solveChallenge(ch, controller.signal)).rejects.toThrow("Aborted");
+  });
+});
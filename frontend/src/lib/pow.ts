@@ -0,0 +1,54 @@
+/**
+ * Proof-of-work solver for the adaptive auth challenge.
+ *
+ * After repeated failed logins the backend answers /auth/* with
+ * 428 CHALLENGE_REQUIRED. The client fetches a challenge from
+ * GET /auth/challenge, finds a decimal solution such that
+ * sha256(challenge + ":" + solution) has `difficulty` leading zero bits,
+ * and retries with the X-PoW-Challenge / X-PoW-Solution headers.
+ */
+
+export const POW_CHALLENGE_HEADER = "X-PoW-Challenge";
+export const POW_SOLUTION_HEADER = "X-PoW-Solution";
+
+export interface PowChallenge {
+  challenge: string;
+  difficulty: number;
+  algorithm: string;
+  expires_at: string;
+}
+
+/** Count the zero bits at the start of a digest. */
+export function leadingZeroBits(bytes: Uint8Array): number {
+  let n = 0;
+  for (const b of bytes) {
+    if (b === 0) {
+      n += 8;
+      continue;
+    }
+    return n + Math.clz32(b) - 24;
+  }
+  return n;
+}
+
+/**
+ * Brute-force a solution for the challenge. Yields to the event loop every
+ * batch so the UI stays responsive while the hash search runs.
+ */
+export async function solveChallenge(ch: PowChallenge, signal?: AbortSignal): Promise<string> {
+  const encoder = new TextEncoder();
+  const prefix = `${ch.challenge}:`;
+  const batch = 2000;
+
+  for (let n = 0; ; n++) {
+    if (signal?.aborted) throw new DOMException("Aborted", "AbortError");
+    const candidate = String(n);
+    const digest = await crypto.subtle.digest("SHA-256", encoder.encode(prefix + candidate));
+    if (leadingZeroBits(new Uint8Array(digest)) >= ch.difficulty) {
+      return candidate;
+    }
+    if (n % batch === batch - 1) {
+      await new Promise((resolve) => setTimeout(resolve, 0));
+    }
+  }
+}
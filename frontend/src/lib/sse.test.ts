@@ -2,18 +2,28 @@ import { describe, it, expect, vi, beforeEach, afterEach } from "vitest";
 
 vi.mock("./api", () => ({
   post: vi.fn(),
+  notificationsApi: { list: vi.fn() },
   tokens: { access: "", refresh: "", set: vi.fn(), clear: vi.fn() },
 }));
 
+vi.mock("./announcements", () => ({
+  loadAnnouncements: vi.fn(),
+  upsertAnnouncement: vi.fn(),
+  removeAnnouncement: vi.fn(),
+}));
+
 vi.mock("./stores", () => ({
   toast: { info: vi.fn() },
+  notifications: { setUnreadCount: vi.fn(), reset: vi.fn() },
 }));
 
-import { onSSEEvent, connectSSE, disconnectSSE } from "./sse";
-import { post, tokens } from "./api";
-import { toast } from "./stores";
+import { onSSEEvent, connectSSE, disconnectSSE, setSSETopics } from "./sse";
+import { notificationsApi, post, tokens } from "./api";
+import { notifications, toast } from "./stores";
+import { loadAnnouncements, removeAnnouncement, upsertAnnouncement } from "./announcements";
 
 const mockPost = vi.mocked(post);
+const mockNotificationsApi = vi.mocked(notificationsApi);
 const mockTokens = tokens as unknown as {
   access: string;
   refresh: string;
@@ -21,6 +31,7 @@ const mockTokens = tokens as unknown as {
   clear: ReturnType<typeof vi.fn>;
 };
 const mockToast = vi.mocked(toast);
+const mockNotifications = vi.mocked(notifications);
 
 let lastEventSource: MockEventSource | null = null;
 let nativeEventSource: typeof EventSource | undefined;
@@ -62,8 +73,8 @@ class MockEventSource {
     this.readyState = 2;
   }
 
-  emit(eventName: string, data: unknown) {
-    const payload = { data: JSON.stringify(data) } as MessageEvent;
+  emit(eventName: string, data: unknown, lastEventId = "") {
+    const payload = { data: JSON.stringify(data), lastEventId } as MessageEvent;
     for (const fn of this.listeners[eventName] ?? []) {
       fn(payload);
     }
@@ -335,11 +346,113 @@ describe("connectSSE", () => {
     await connectSSE();
     await vi.waitFor(() => expect(lastEventSource).not.toBeNull());
     lastEventSource!.emit("notification", {
-      message: "Hello from SSE",
-      timestamp: "2026-01-01T00:00:00Z",
+      notification: {
+        id: "n-1",
+        kind: "demo",
+        payload: { message: "Hello from SSE" },
+        read_at: null,
+        created_at: "2026-01-01T00:00:00Z",
+      },
+      unread_count: 3,
+      unread_seq: 9,
     });
 
     expect(mockToast.info).toHaveBeenCalledWith("Hello from SSE");
+    expect(mockNotifications.setUnreadCount).toHaveBeenCalledWith(3, 9);
+  });
+
+  it("syncs the unread count when notifications are read in another tab", async () => {
+    mockTokens.access = "token";
+    mockPost.mockResolvedValueOnce({ ticket: "ticket" } as never);
+
+    await connectSSE();
+    await vi.waitFor(() => expect(lastEventSource).not.toBeNull());
+    lastEventSource!.emit("notifications_read", { all: true, read: true, unread_count: 0, unread_seq: 4 });
+
+    expect(mockNotifications.setUnreadCount).toHaveBeenCalledWith(0, 4);
+    expect(mockToast.info).not.toHaveBeenCalled();
+  });
+
+  it("resumes from the last event ID after reconnecting", async () => {
+    vi.useFakeTimers();
+    mockTokens.access = "token";
+    mockPost.mockResolvedValue({ ticket: "ticket" } as never);
+
+    await connectSSE();
+    await vi.waitFor(() => expect(lastEventSource).not.toBeNull());
+    expect(lastEventSource!.url).not.toContain("last_event_id");
+    lastEventSource!.emit("notifications_read", { unread_count: 1 }, "1760000000000000");
+    lastEventSource!.onerror?.();
+
+    await vi.advanceTimersByTimeAsync(1000);
+    expect(lastEventSource!.url).toContain("last_event_id=1760000000000000");
+  });
+
+  it("requests topics on the ticket and reconnects when they change", async () => {
+    mockTokens.access = "token";
+    mockPost.mockResolvedValue({ ticket: "ticket" } as never);
+
+    setSSETopics(["org:1"]);
+    await connectSSE();
+    expect(mockPost).toHaveBeenLastCalledWith("/events/ticket?topic=org%3A1");
+    const first = lastEventSource;
+
+    setSSETopics(["org:1"]);
+    expect(mockPost).toHaveBeenCalledTimes(1);
+
+    setSSETopics(["doc:a", "org:1"]);
+    await vi.waitFor(() => expect(lastEventSource).not.toBe(first));
+    expect(first!.readyState).toBe(2);
+    expect(mockPost).toHaveBeenLastCalledWith("/events/ticket?topic=doc%3Aa&topic=org%3A1");
+  });
+
+  it("refetches the unread count on reset", async () => {
+    mockTokens.access = "token";
+    mockPost.mockResolvedValueOnce({ ticket: "ticket" } as never);
+    mockNotificationsApi.list.mockResolvedValueOnce({ unread_count: 4, unread_seq: 12 } as never);
+
+    await connectSSE();
+    await vi.waitFor(() => expect(lastEventSource).not.toBeNull());
+    lastEventSource!.emit("reset", {}, "1760000000000000");
+
+    expect(mockNotificationsApi.list).toHaveBeenCalledWith(1, 1);
+    expect(loadAnnouncements).toHaveBeenCalled();
+    await vi.waitFor(() => expect(mockNotifications.setUnreadCount).toHaveBeenCalledWith(4, 12));
+  });
+
+  it("keeps announcements current from live events", async () => {
+    mockTokens.access = "token";
+    mockPost.mockResolvedValueOnce({ ticket: "ticket" } as never);
+
+    await connectSSE();
+    await vi.waitFor(() => expect(lastEventSource).not.toBeNull());
+    const announcement = { id: "a1", title: "Maintenance tonight", severity: "warning" };
+    lastEventSource!.emit("announcement", announcement);
+    lastEventSource!.emit("announcement_removed", { id: "a1" });
+    lastEventSource!.emit("announcement_dismissed", { id: "a2" });
+
+    expect(upsertAnnouncement).toHaveBeenCalledWith(announcement);
+    expect(removeAnnouncement).toHaveBeenCalledWith("a1");
+    expect(removeAnnouncement).toHaveBeenCalledWith("a2");
+  });
+
+  it("reconnects after the server's retry hint when dropped for falling behind", async () => {
+    vi.useFakeTimers();
+    mockTokens.access = "token";
+    mockPost.mockResolvedValue({ ticket: "ticket" } as never);
+
+    await connectSSE();
+    await vi.waitFor(() => expect(lastEventSource).not.toBeNull());
+    const first = lastEventSource!;
+    first.emit("notifications_read", { unread_count: 1 }, "1760000000000000");
+    first.emit("reconnect", { retry: 5000 });
+    expect(first.readyState).toBe(2);
+
+    await vi.advanceTimersByTimeAsync(4999);
+    expect(mockPost).toHaveBeenCalledTimes(1);
+    await vi.advanceTimersByTimeAsync(1);
+    expect(mockPost).toHaveBeenCalledTimes(2);
+    expect(lastEventSource!.url).toContain("last_event_id=1760000000000000");
   });
 });
 
@@ -5,9 +5,18 @@
  *   connectSSE()         — called when user authenticates
  *   disconnectSSE()      — called on logout or cleanup
  *   onSSEEvent("name", (data) => { ... })  — register a typed event handler
+ *   setSSETopics(["org:123"])  — join topics (authorized when the ticket is issued)
+ *
+ * Reconnects resume from the last event ID seen, so events sent while the
+ * stream was down are replayed. If the server can no longer replay them it
+ * sends a "reset" event and the client refetches its state instead. A
+ * client that falls too far behind is sent a "reconnect" event and comes
+ * back after the delay it names. Announcement events keep the list loaded
+ * by loadAnnouncements current.
  */
 
-import { post, tokens } from "./api";
+import { notificationsApi, post, tokens, type Announcement } from "./api";
+import { loadAnnouncements, removeAnnouncement, upsertAnnouncement } from "./announcements";
 
 // SSE connects directly to the backend, bypassing the SolidStart/Vite proxy.
 // The proxy doesn't propagate client disconnects, causing zombie connections
@@ -15,7 +24,7 @@ import { post, tokens } from "./api";
 // handles this correctly via Cloud Run VPC.
 const SSE_BASE = import.meta.env.VITE_SSE_URL || import.meta.env.VITE_API_URL || "";
 const API_VERSION = "v1";
-import { toast } from "./stores";
+import { notifications, toast } from "./stores";
 
 // ============================================================================
 // Types
@@ -24,8 +33,28 @@ import { toast } from "./stores";
 type SSEEventHandler<T = unknown> = (data: T) => void;
 
 interface NotificationEvent {
-  message: string;
-  timestamp: string;
+  notification: {
+    id: string;
+    kind: string;
+    payload: { message?: string } & Record<string, unknown>;
+    read_at: string | null;
+    created_at: string;
+  };
+  unread_count: number;
+  unread_seq: number;
+}
+
+interface NotificationsReadEvent {
+  unread_count: number;
+  unread_seq: number;
+}
+
+interface AnnouncementRefEvent {
+  id: string;
+}
+
+interface ReconnectEvent {
+  retry: number;
 }
 
 // ============================================================================
@@ -38,6 +67,8 @@ let backoffMs = 1000;
 const MAX_BACKOFF_MS = 30000;
 const MAX_CONSECUTIVE_ERRORS = 5;
 let consecutiveErrors = 0;
+let lastEventId = "";
+let topics: string[] = [];
 
 const handlers = new Map<string, Set<SSEEventHandler>>();
 
@@ -68,6 +99,21 @@ export function onSSEEvent<T = unknown>(
   };
 }
 
+/**
+ * Set the topics the stream is subscribed to, reconnecting if they changed
+ * while connected. Topics are granted with the ticket, so changing them
+ * needs a new one; the last event ID carries over, so nothing is missed.
+ */
+export function setSSETopics(next: string[]): void {
+  const sorted = [...new Set(next)].sort();
+  if (sorted.join("\n") === topics.join("\n")) return;
+  topics = sorted;
+  if (eventSource) {
+    cleanup();
+    void connectSSE();
+  }
+}
+
 /**
  * Connect to the SSE stream. Gets a one-time ticket via POST, then opens EventSource.
  * Automatically reconnects with exponential backoff on disconnect.
@@ -79,8 +125,14 @@ export async function connectSSE(): Promise<void> {
   if (!tokens.access) return;
 
   try {
-    const { ticket } = await post<{ ticket: string }>("/events/ticket");
-    const url = `${SSE_BASE}/api/${API_VERSION}/events/stream?ticket=${encodeURIComponent(ticket)}`;
+    const query = topics.map((t) => `topic=${encodeURIComponent(t)}`).join("&");
+    const { ticket } = await post<{ ticket: string }>(
+      query ? `/events/ticket?${query}` : "/events/ticket"
+    );
+    let url = `${SSE_BASE}/api/${API_VERSION}/events/stream?ticket=${encodeURIComponent(ticket)}`;
+    if (lastEventId) {
+      url += `&last_event_id=${encodeURIComponent(lastEventId)}`;
+    }
 
     eventSource = new EventSource(url);
 
@@ -118,6 +170,9 @@ export function disconnectSSE(): void {
   cleanup();
   backoffMs = 1000;
   consecutiveErrors = 0;
+  lastEventId = "";
+  topics = [];
+  notifications.reset();
 }
 
 // ============================================================================
@@ -134,6 +189,9 @@ function cleanup() {
 function addSourceListener(eventName: string) {
   if (!eventSource) return;
   eventSource.addEventListener(eventName, (e: MessageEvent) => {
+    if (e.lastEventId) {
+      lastEventId = e.lastEventId;
+    }
     try {
       const data = JSON.parse(e.data);
       const eventHandlers = handlers.get(eventName);
@@ -190,5 +248,45 @@ async function scheduleReconnect() {
 // ============================================================================
 
 onSSEEvent<NotificationEvent>("notification", (data) => {
-  toast.info(data.message);
+  notifications.setUnreadCount(data.unread_count, data.unread_seq);
+  if (data.notification.payload.message) {
+    toast.info(data.notification.payload.message);
+  }
+});
+
+onSSEEvent<NotificationsReadEvent>("notifications_read", (data) => {
+  notifications.setUnreadCount(data.unread_count, data.unread_seq);
+});
+
+onSSEEvent<Announcement>("announcement", (data) => {
+  upsertAnnouncement(data);
+});
+
+// Removed, ended, or moved to an audience this user may not be in.
+onSSEEvent<AnnouncementRefEvent>("announcement_removed", (data) => {
+  removeAnnouncement(data.id);
+});
+
+// Dismissed in another tab or on another device.
+onSSEEvent<AnnouncementRefEvent>("announcement_dismissed", (data) => {
+  removeAnnouncement(data.id);
+});
+
+// Events were missed and can't be replayed — refetch anything driven by them.
+onSSEEvent("reset", async () => {
+  void loadAnnouncements();
+  try {
+    const { unread_count, unread_seq } = await notificationsApi.list(1, 1);
+    notifications.setUnreadCount(unread_count, unread_seq);
+  } catch {
+    // Next notification event will correct the count
+  }
+});
+
+// The server dropped this stream for falling behind — come back after its
+// hint and catch up from the last event ID.
+onSSEEvent<ReconnectEvent>("reconnect", (data) => {
+  cleanup();
+  backoffMs = data.retry;
+  scheduleReconnect();
 });
@@ -1,3 +1,4 @@
 export { toast, type ToastType, type ToastMessage } from "./toast";
 export { snackbar, type SnackbarMessage, type SnackbarOptions } from "./snackbar";
 export { ui, type Theme } from "./ui";
+export { notifications } from "./notifications";
@@ -0,0 +1,34 @@
+import { describe, it, expect, beforeEach } from "vitest";
+import { notifications } from "./notifications";
+
+beforeEach(() => {
+  notifications.reset();
+});
+
+describe("notifications.setUnreadCount", () => {
+  it("stores the server's count", () => {
+    notifications.setUnreadCount(7);
+    expect(notifications.unreadCount).toBe(7);
+    expect(notifications.subscribeUnread()).toBe(7);
+  });
+
+  it("never goes negative", () => {
+    notifications.setUnreadCount(-1);
+    expect(notifications.unreadCount).toBe(0);
+  });
+
+  it("ignores a count older than the one shown", () => {
+    notifications.setUnreadCount(2, 5);
+    notifications.setUnreadCount(1, 4);
+    expect(notifications.unreadCount).toBe(2);
+    notifications.setUnreadCount(3, 6);
+    expect(notifications.unreadCount).toBe(3);
+  });
+
+  it("accepts low seqs again after reset", () => {
+    notifications.setUnreadCount(2, 5);
+    notifications.reset();
+    notifications.setUnreadCount(1, 1);
+    expect(notifications.unreadCount).toBe(1);
+  });
+});
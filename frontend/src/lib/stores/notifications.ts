@@ -0,0 +1,29 @@
+import { createSignal } from "solid-js";
+
+// ============================================================================
+// STORE
+// ============================================================================
+
+// The server sends the authoritative unread count with every notification
+// and read-state change, so each tab just mirrors the latest value. Pushes
+// can arrive out of order, so each count carries the unread_seq of the
+// change it includes, and a count older than the one shown is ignored.
+const [unreadCount, setUnreadCount] = createSignal(0);
+let unreadSeq = 0;
+
+export const notifications = {
+  get unreadCount() { return unreadCount(); },
+  subscribeUnread: unreadCount,
+  setUnreadCount: (count: number, seq?: number) => {
+    if (seq !== undefined) {
+      if (seq < unreadSeq) return;
+      unreadSeq = seq;
+    }
+    setUnreadCount(Math.max(0, count));
+  },
+  /** Forget the count and its seq (on logout, before another user's counts arrive). */
+  reset: () => {
+    unreadSeq = 0;
+    setUnreadCount(0);
+  },
+};
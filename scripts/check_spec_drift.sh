@@ -20,9 +20,12 @@
 #   skipped and why; reviewers can challenge weak reasons in PR review.
 #
 # Module mapping (Golid v0.3.0):
-#   auth, auth_password, auth_verify -> auth
-#   user                               -> users
+#   auth, auth_password, auth_verify, auth_export -> auth
+#   user, user_admin, user_status, admin_user, avatar, export, user_export,
+#   preference, user_preferences, notification,
+#   notification_export -> users
 #   feature                            -> feature
+#   file, file_export                  -> files
 #   Unknown stems (sse, email, pagination, retry, context, wire, etc.) are ignored.
 
 set -u  # unset vars are errors; deliberately not -e (we want to keep accumulating findings)
@@ -41,9 +44,10 @@ fi
 file_to_module() {
   local stem="$1"
   case "$stem" in
-    auth_password|auth_verify) echo auth ;;
-    user)                      echo users ;;
+    auth_password|auth_verify|auth_export) echo auth ;;
+    user|user_admin|user_status|admin_user|avatar|export|user_export|preference|user_preferences|notification|notification_export) echo users ;;
     auth|feature)              echo "$stem" ;;
+    file|file_export)          echo files ;;
     # Unknown — emit empty so the caller can ignore (infra helpers: sse, email, pagination, etc.)
     *)                         echo "" ;;
   esac